@@ -0,0 +1,13 @@
+package main
+
+import "github.com/codeGROOVE-dev/prcost/pkg/cost"
+
+// anonymizer returns the pseudonyms for a report on breakdowns, or nil, which leaves logins
+// unchanged, unless f's results are anonymized (--anonymize); they then show pseudonyms such
+// as author-1 and reviewer-2 instead of GitHub logins.
+func anonymizer(f *formatter, breakdowns []cost.Breakdown) *cost.Anonymizer {
+	if !f.anonymize {
+		return nil
+	}
+	return cost.NewAnonymizer(breakdowns)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+	"github.com/codeGROOVE-dev/prcost/pkg/github"
+)
+
+// checkpointInterval is the least time between checkpoint writes while sampled PRs are fetched.
+const checkpointInterval = 10 * time.Second
+
+// checkpoint records a repo or org scan's progress so an interrupted run can resume:
+// the sampled PRs, and the data fetched for each so far. Breakdowns are recalculated
+// from the saved data, so a resumed run may use a different cost configuration.
+//
+//nolint:govet // fieldalignment: field order matches the file layout
+type checkpoint struct {
+	Scope   string                 `json:"scope"`   // What was scanned; a checkpoint only resumes the same scan
+	Samples []github.PRSummary     `json:"samples"` // The sampled PRs, reused so a resumed run fetches the same ones
+	Fetched map[string]cost.PRData `json:"fetched"` // Fetched PR data by PR URL
+
+	path    string
+	mu      sync.Mutex
+	savedAt time.Time
+}
+
+// checkpointScope describes a scan for matching checkpoints to the run that wrote them.
+func checkpointScope(target string, window analysisWindow, sampleSize int, filter github.PRFilter) string {
+	scope := fmt.Sprintf("%s %s, %d samples", target, window, sampleSize)
+	if !filter.IsZero() {
+		scope += fmt.Sprintf(", filter %+v", filter)
+	}
+	return scope
+}
+
+// openCheckpoint loads the checkpoint at path, or starts a new one if the file doesn't exist.
+// A checkpoint written by a different scan is an error rather than being overwritten.
+func openCheckpoint(path, scope string) (*checkpoint, error) {
+	cp := &checkpoint{Scope: scope, Fetched: make(map[string]cost.PRData), path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("reading checkpoint %s: %w", path, err)
+	}
+	if cp.Scope != scope {
+		return nil, fmt.Errorf("checkpoint %s is for a different scan (%s); remove it or choose another file", path, cp.Scope)
+	}
+	if cp.Fetched == nil {
+		cp.Fetched = make(map[string]cost.PRData)
+	}
+	return cp, nil
+}
+
+// sample returns the checkpoint's sampled PRs when resuming, and otherwise records and returns samples.
+func (c *checkpoint) sample(samples []github.PRSummary) []github.PRSummary {
+	if len(c.Samples) > 0 {
+		slog.Info("Resuming from checkpoint", "path", c.path, "samples", len(c.Samples), "fetched", len(c.Fetched))
+		return c.Samples
+	}
+	c.Samples = samples
+	return samples
+}
+
+// fetcher wraps f so PRs already in the checkpoint aren't fetched again, and newly
+// fetched PRs are saved to it.
+func (c *checkpoint) fetcher(f cost.PRFetcher) cost.PRFetcher {
+	return &checkpointFetcher{checkpoint: c, fetcher: f}
+}
+
+// save writes the checkpoint. It writes a temporary file and renames it into place,
+// so an interruption mid-write leaves the previous checkpoint intact.
+func (c *checkpoint) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.saveLocked()
+}
+
+func (c *checkpoint) saveLocked() error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("encoding checkpoint: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // already renamed on success
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck,gosec // the write error is reported
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	c.savedAt = time.Now()
+	return nil
+}
+
+// checkpointFetcher serves PRs from a checkpoint and records the ones it fetches.
+type checkpointFetcher struct {
+	checkpoint *checkpoint
+	fetcher    cost.PRFetcher
+}
+
+// FetchPRData implements cost.PRFetcher.
+func (f *checkpointFetcher) FetchPRData(ctx context.Context, prURL string, updatedAt time.Time) (cost.PRData, error) {
+	c := f.checkpoint
+	c.mu.Lock()
+	data, ok := c.Fetched[prURL]
+	c.mu.Unlock()
+	if ok {
+		return data, nil
+	}
+
+	data, err := f.fetcher.FetchPRData(ctx, prURL, updatedAt)
+	if err != nil {
+		return data, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Fetched[prURL] = data
+	if time.Since(c.savedAt) >= checkpointInterval {
+		if err := c.saveLocked(); err != nil {
+			slog.Warn("Failed to save checkpoint", "path", c.path, "error", err)
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+	"github.com/codeGROOVE-dev/prcost/pkg/github"
+)
+
+// countingFetcher returns canned PR data, failing for URLs in fail, and counts fetches.
+type countingFetcher struct {
+	fail    map[string]bool
+	fetches int
+}
+
+func (f *countingFetcher) FetchPRData(_ context.Context, prURL string, _ time.Time) (cost.PRData, error) {
+	f.fetches++
+	if f.fail[prURL] {
+		return cost.PRData{}, errors.New("token expired")
+	}
+	return cost.PRData{Author: "alice", Title: prURL, LinesAdded: 10, State: "MERGED"}, nil
+}
+
+func TestCheckpointResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.json")
+	scope := checkpointScope("org myorg", analysisWindow{days: 60}, 2, github.PRFilter{})
+	samples := []github.PRSummary{{Owner: "myorg", Repo: "api", Number: 1}, {Owner: "myorg", Repo: "api", Number: 2}}
+	const first, second = "https://github.com/myorg/api/pull/1", "https://github.com/myorg/api/pull/2"
+
+	// The first run dies partway: PR 2 fails to fetch
+	cp, err := openCheckpoint(path, scope)
+	if err != nil {
+		t.Fatalf("openCheckpoint() error: %v", err)
+	}
+	cp.sample(samples)
+	inner := &countingFetcher{fail: map[string]bool{second: true}}
+	fetcher := cp.fetcher(inner)
+	if _, err := fetcher.FetchPRData(t.Context(), first, time.Time{}); err != nil {
+		t.Fatalf("FetchPRData() error: %v", err)
+	}
+	if _, err := fetcher.FetchPRData(t.Context(), second, time.Time{}); err == nil {
+		t.Fatal("FetchPRData() succeeded, want the inner fetcher's error")
+	}
+
+	// The first fetch was saved without waiting for the run to finish
+	cp, err = openCheckpoint(path, scope)
+	if err != nil {
+		t.Fatalf("reopening checkpoint: %v", err)
+	}
+	if got := cp.sample(nil); len(got) != 2 || got[1].Number != 2 {
+		t.Errorf("resumed samples = %+v, want the original 2", got)
+	}
+	inner = &countingFetcher{}
+	fetcher = cp.fetcher(inner)
+	for _, prURL := range []string{first, second} {
+		data, err := fetcher.FetchPRData(t.Context(), prURL, time.Time{})
+		if err != nil || data.Title != prURL {
+			t.Errorf("resumed FetchPRData(%s) = %q, %v", prURL, data.Title, err)
+		}
+	}
+	if inner.fetches != 1 {
+		t.Errorf("resumed run made %d fetches, want 1 for the PR that failed", inner.fetches)
+	}
+	if err := cp.save(); err != nil {
+		t.Fatalf("save() error: %v", err)
+	}
+	if cp, err = openCheckpoint(path, scope); err != nil || len(cp.Fetched) != 2 {
+		t.Errorf("final checkpoint = %v, %v; want both PRs fetched", cp, err)
+	}
+
+	// A different scan refuses to reuse the file
+	other := checkpointScope("org otherorg", analysisWindow{days: 60}, 2, github.PRFilter{})
+	if _, err := openCheckpoint(path, other); err == nil || !strings.Contains(err.Error(), "different scan") {
+		t.Errorf("openCheckpoint() for another scan error = %v, want a different scan error", err)
+	}
+}
@@ -0,0 +1,828 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cocomo"
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+	"github.com/codeGROOVE-dev/prcost/pkg/github"
+	"golang.org/x/text/language"
+)
+
+// Subcommand names.
+const (
+	cmdPR       = "pr"
+	cmdRepo     = "repo"
+	cmdOrg      = "org"
+	cmdEstimate = "estimate"
+	cmdCompare  = "compare"
+	cmdComment  = "comment"
+)
+
+var subcommands = []string{cmdPR, cmdRepo, cmdOrg, cmdEstimate, cmdCompare, cmdComment}
+
+// errUsage indicates invalid arguments; usage has already been printed.
+var errUsage = errors.New("invalid usage")
+
+// options holds parsed command-line options for all subcommands.
+//
+//nolint:govet // fieldalignment: grouped by purpose for readability
+type options struct {
+	command  string   // One of the cmd* constants
+	args     []string // Positional arguments (PR URLs for pr/compare)
+	fromFile string   // prx JSON dump to cost instead of fetching a PR (pr only)
+
+	// Tracked time per PR URL, compared with the model (pr only)
+	actualsFile string
+	actualHours map[string]float64
+
+	// Cost model
+	profile          string          // Named profile whose settings are used for flags not given
+	configFile       string          // Profiles file (default: ~/.config/prcost/profiles.yaml)
+	profileConfig    *cost.Config    // Loaded profile; nil without --profile
+	setFlags         map[string]bool // Flags given on the command line, recorded with a profile
+	salary           float64
+	maintainerSalary float64
+	contribSalary    float64
+	benefits         float64
+	eventMinutes     float64
+	targetMergeTime  time.Duration
+	minDelayMinutes  float64
+	maxProjectDelay  time.Duration
+	maxCodeDrift     time.Duration
+	maxPRCodeCost    float64
+	fiscalStart      int
+	includeGenerated bool
+	requireWaiting   bool
+	countDraftTime   bool
+	botOverheadOnly  bool
+	noFutureCosts    bool
+	sessionModel     string
+	velocityMedian   bool
+	maxWaiting       float64
+	workCalendar     *cost.WorkingCalendar
+	ignoredEvents    []string
+	cocomo           cocomo.Config
+	compFile         string
+	currency         string
+	exchangeRates    map[string]float64
+	reviewRates      map[string]float64
+
+	// Output and data source
+	format       string
+	output       string  // File to write results to instead of stdout
+	roundUnit    float64 // Unit currency amounts are rounded to in output (0 = cents)
+	roundJSON    bool
+	locale       language.Tag // Sets the thousands separator and decimal mark of amounts
+	dataSource   string
+	githubHost   string
+	maxRetries   int
+	githubRate   float64            // Shared GitHub API requests per second (0 = unlimited)
+	api          *github.APIOptions // GitHub API retries and rate budget, set up in main from the flags above
+	verbose      bool
+	quiet        bool
+	anonymize    bool
+	failUnder    float64 // Efficiency percentage a PR must reach, or exit with exitGateFailed (0 = off)
+	failOverCost float64 // Total cost a PR must not exceed, or exit with exitGateFailed (0 = off)
+	noCallout    bool
+
+	// PR comment
+	commentTemplate string
+
+	// Org/repo sampling
+	org         string
+	repo        string
+	samples     int
+	sampling    github.SamplingMode
+	days        int
+	sinceFlag   string
+	untilFlag   string
+	since       time.Time // Start of an absolute window set with --since; zero for the last --days
+	until       time.Time // End of an absolute window set with --until; zero for now
+	scenarios   scenarioFlags
+	filter      github.PRFilter
+	changeTypes map[string]string
+	dryRun      bool
+	inclSamples bool // Include per-sample breakdowns in the results
+	checkpoint  string
+	concurrency int
+
+	// Estimate
+	linesAdded   int
+	linesDeleted int
+	openTime     time.Duration
+	reviewers    int
+}
+
+// costFlags maps each flag that sets cost configuration to how it sets it. Without a profile every
+// entry applies, so flag defaults are the defaults; with one, only flags given on the command
+// line apply, overriding the profile's values. Repeatable map flags add to or replace the
+// profile's entries key by key.
+var costFlags = map[string]func(o *options, cfg *cost.Config){
+	"salary":             func(o *options, cfg *cost.Config) { cfg.AnnualSalary = o.salary },
+	"maintainer-salary":  func(o *options, cfg *cost.Config) { cfg.MaintainerSalary = o.maintainerSalary },
+	"contributor-salary": func(o *options, cfg *cost.Config) { cfg.ContributorSalary = o.contribSalary },
+	"benefits":           func(o *options, cfg *cost.Config) { cfg.BenefitsMultiplier = o.benefits },
+	"event-minutes": func(o *options, cfg *cost.Config) {
+		cfg.EventDuration = time.Duration(o.eventMinutes * float64(time.Minute))
+	},
+	"target-merge-time":        func(o *options, cfg *cost.Config) { cfg.TargetMergeTimeHours = o.targetMergeTime.Hours() },
+	"velocity-by-median":       func(o *options, cfg *cost.Config) { cfg.GradeVelocityByMedian = o.velocityMedian },
+	"min-delay-minutes":        func(o *options, cfg *cost.Config) { cfg.MinDelayThresholdMinutes = o.minDelayMinutes },
+	"max-project-delay":        func(o *options, cfg *cost.Config) { cfg.MaxProjectDelay = o.maxProjectDelay },
+	"max-code-drift":           func(o *options, cfg *cost.Config) { cfg.MaxCodeDrift = o.maxCodeDrift },
+	"max-pr-code-cost":         func(o *options, cfg *cost.Config) { cfg.MaxPRCodeCost = o.maxPRCodeCost },
+	"fiscal-year-start":        func(o *options, cfg *cost.Config) { cfg.FiscalYearStartMonth = o.fiscalStart },
+	"include-generated":        func(o *options, cfg *cost.Config) { cfg.ExcludeGeneratedFromCost = !o.includeGenerated },
+	"require-waiting-evidence": func(o *options, cfg *cost.Config) { cfg.RequireWaitingEvidence = o.requireWaiting },
+	"count-draft-time":         func(o *options, cfg *cost.Config) { cfg.CountDraftTime = o.countDraftTime },
+	"bot-overhead-only":        func(o *options, cfg *cost.Config) { cfg.CountBotParticipantCosts = !o.botOverheadOnly },
+	"no-future-costs":          func(o *options, cfg *cost.Config) { cfg.IncludeFutureCosts = !o.noFutureCosts },
+	"session-model":            func(o *options, cfg *cost.Config) { cfg.SessionModel = o.sessionModel },
+	"max-waiting-multiplier":   func(o *options, cfg *cost.Config) { cfg.MaxWaitingMultiplier = o.maxWaiting },
+	"working-calendar":         func(o *options, cfg *cost.Config) { cfg.WorkingCalendar = o.workCalendar },
+	"ignore-event":             func(o *options, cfg *cost.Config) { cfg.IgnoredEventKinds = o.ignoredEvents },
+	"cocomo-multiplier":        func(o *options, cfg *cost.Config) { cfg.COCOMO.Multiplier = o.cocomo.Multiplier },
+	"cocomo-exponent":          func(o *options, cfg *cost.Config) { cfg.COCOMO.Exponent = o.cocomo.Exponent },
+	"cocomo-min-effort":        func(o *options, cfg *cost.Config) { cfg.COCOMO.MinimumEffort = o.cocomo.MinimumEffort },
+	"currency":                 func(o *options, cfg *cost.Config) { cfg.ReportingCurrency = strings.ToUpper(o.currency) },
+	"exchange-rate": func(o *options, cfg *cost.Config) {
+		cfg.ExchangeRates = mergeRates(cfg.ExchangeRates, o.exchangeRates)
+	},
+	"review-rate": func(o *options, cfg *cost.Config) {
+		cfg.ReviewInspectionRates = mergeRates(cfg.ReviewInspectionRates, o.reviewRates)
+	},
+	"change-type": func(o *options, cfg *cost.Config) {
+		if len(o.changeTypes) == 0 {
+			return
+		}
+		base := cfg.ChangeTypes
+		if base == nil {
+			base = cost.DefaultChangeTypes
+		}
+		cfg.ChangeTypes = maps.Clone(base)
+		maps.Copy(cfg.ChangeTypes, o.changeTypes)
+	},
+}
+
+// mergeRates returns base with overrides added or replaced, leaving base unchanged.
+func mergeRates(base, overrides map[string]float64) map[string]float64 {
+	if len(overrides) == 0 {
+		return base
+	}
+	merged := maps.Clone(base)
+	if merged == nil {
+		merged = make(map[string]float64, len(overrides))
+	}
+	maps.Copy(merged, overrides)
+	return merged
+}
+
+// config builds a cost configuration from the parsed options: the profile if one was loaded,
+// overridden by the cost flags that apply (see costFlags).
+func (o *options) config() cost.Config {
+	cfg := cost.DefaultConfig()
+	if o.profileConfig != nil {
+		cfg = *o.profileConfig
+	}
+	for name, apply := range costFlags {
+		if o.profileConfig == nil || o.setFlags[name] {
+			apply(o, &cfg)
+		}
+	}
+	return cfg
+}
+
+// addCostFlags registers flags shared by every subcommand.
+func addCostFlags(fs *flag.FlagSet, o *options) {
+	fs.StringVar(&o.profile, "profile", "",
+		"Use this named profile of cost settings from the --config file; flags override its values")
+	fs.StringVar(&o.configFile, "config", "", "Profiles file for --profile (default: ~/.config/prcost/profiles.yaml)")
+	fs.Float64Var(&o.salary, "salary", 249000, "Annual salary for cost calculation")
+	fs.Float64Var(&o.maintainerSalary, "maintainer-salary", 0,
+		"Annual salary for PR authors with write access to the repository (default: --salary)")
+	fs.Float64Var(&o.contribSalary, "contributor-salary", 0,
+		"Annual salary for PR authors without write access, such as external contributors (default: --salary)")
+	fs.Float64Var(&o.benefits, "benefits", 1.3, "Benefits multiplier (1.3 = 30% benefits)")
+	fs.Float64Var(&o.eventMinutes, "event-minutes", 10, "Minutes per GitHub event (commits, comments, etc.)")
+	fs.StringVar(&o.format, "format", "human", "Output format: human, json, csv, or markdown")
+	fs.StringVar(&o.output, "output", "", "Write results to this file, creating or truncating it, instead of stdout")
+	fs.Func("round", "Round currency amounts in human output: none (cents, the default), dollar, hundred or thousand",
+		func(value string) error {
+			unit, err := parseRounding(value)
+			if err != nil {
+				return err
+			}
+			o.roundUnit = unit
+			return nil
+		})
+	fs.BoolVar(&o.roundJSON, "round-json", false, "Apply --round to cost fields in JSON output too")
+	o.locale = language.MustParse(defaultLocale)
+	fs.Func("locale",
+		"Locale for thousands separators and decimal marks in amounts, e.g. de-DE for 1.234,56 (default: "+defaultLocale+")",
+		func(value string) error {
+			tag, err := parseLocale(value)
+			if err != nil {
+				return err
+			}
+			o.locale = tag
+			return nil
+		})
+	fs.StringVar(&o.output, "o", "", "Shorthand for --output")
+	fs.BoolVar(&o.verbose, "verbose", false, "Show verbose logging output")
+	fs.BoolVar(&o.anonymize, "anonymize", false,
+		"Replace author and participant logins with stable pseudonyms such as author-1 and reviewer-2 in the results")
+	fs.BoolVar(&o.noCallout, "no-callout", false,
+		"Omit the merge time modeling callout and R2R savings for a neutral report")
+	fs.DurationVar(&o.targetMergeTime, "target-merge-time", 90*time.Minute,
+		"Target merge time for efficiency modeling (default: 90 minutes / 1.5 hours)")
+	fs.Float64Var(&o.minDelayMinutes, "min-delay-minutes", 30,
+		"PRs open less than this many minutes incur no delay cost")
+	fs.DurationVar(&o.maxProjectDelay, "max-project-delay", 90*24*time.Hour,
+		"Absolute cap on delivery delay per PR (does not affect code churn)")
+	fs.DurationVar(&o.maxCodeDrift, "max-code-drift", 90*24*time.Hour,
+		"Cap on code drift since the author's last commit, used for code churn (does not affect delivery delay)")
+	fs.Float64Var(&o.maxPRCodeCost, "max-pr-code-cost", 0,
+		"Cap each PR's new development plus adaptation cost at this amount, so huge generated PRs don't dominate; 0 disables")
+	fs.Func("fiscal-year-start",
+		"Month (1-12) the fiscal year starts in; projects waste per fiscal quarter/year instead of per calendar year",
+		func(value string) error {
+			month, err := strconv.Atoi(value)
+			if err != nil || month < 1 || month > 12 {
+				return fmt.Errorf("invalid month %q: must be 1-12", value)
+			}
+			o.fiscalStart = month
+			return nil
+		})
+	fs.BoolVar(&o.requireWaiting, "require-waiting-evidence", false,
+		"Only charge delivery delay from a PR's first review request or reviewer activity; none if nobody engaged")
+	fs.BoolVar(&o.countDraftTime, "count-draft-time", false,
+		"Charge delivery delay for time a PR spent as a draft before it was first ready for review")
+	fs.BoolVar(&o.botOverheadOnly, "bot-overhead-only", false,
+		"Charge bot-authored PRs only the automated updates overhead, not the reviews, merges and tracking of humans on them")
+	fs.BoolVar(&o.noFutureCosts, "no-future-costs", false,
+		"Leave out code churn and the review, merge and context switching still ahead for open PRs, so totals cover only incurred cost")
+	o.sessionModel = cost.SessionModelGapAware
+	fs.Func("session-model",
+		"How context switching is charged across sessions: gap-aware (capped by the gap between sessions, the default)\n"+
+			"or flat (a full switch in and out per session)",
+		func(value string) error {
+			model := strings.ToLower(value)
+			if model == "" || !cost.ValidSessionModel(model) {
+				return fmt.Errorf("invalid session model %q: must be %s or %s", value, cost.SessionModelGapAware, cost.SessionModelFlat)
+			}
+			o.sessionModel = model
+			return nil
+		})
+	fs.Float64Var(&o.maxWaiting, "max-waiting-multiplier", 1,
+		"Scale delivery delay by the number of people waiting on a PR (reviewers and commenters who then went idle), up to this cap; 1 disables")
+	fs.Func("working-calendar",
+		"Charge delivery delay only for working hours, as days, hours and an optional time zone,\n"+
+			"e.g. \"mon-fri 9-17 America/New_York\" (default: every hour counts)",
+		func(value string) error {
+			calendar, err := cost.ParseWorkingCalendar(value)
+			if err != nil {
+				return err
+			}
+			o.workCalendar = calendar
+			return nil
+		})
+	fs.Func("ignore-event",
+		"Leave this event kind out of GitHub activity and session costs, e.g. labeled or subscribed (repeatable)",
+		func(value string) error {
+			kind := strings.ToLower(strings.TrimSpace(value))
+			if !slices.Contains(cost.EventKinds, kind) {
+				return fmt.Errorf("unknown event kind %q: want one of %s", value, strings.Join(cost.EventKinds, ", "))
+			}
+			o.ignoredEvents = append(o.ignoredEvents, kind)
+			return nil
+		})
+	o.cocomo = cocomo.DefaultConfig()
+	fs.Func("cocomo-multiplier",
+		fmt.Sprintf("COCOMO effort coefficient in person-months per KLOC^exponent (default %v)", o.cocomo.Multiplier),
+		func(value string) error {
+			return o.setCOCOMOFloat(&o.cocomo.Multiplier, value)
+		})
+	fs.Func("cocomo-exponent",
+		fmt.Sprintf("COCOMO scale exponent applied to KLOC, at most %v (default %v)", cocomo.MaxExponent, o.cocomo.Exponent),
+		func(value string) error {
+			return o.setCOCOMOFloat(&o.cocomo.Exponent, value)
+		})
+	fs.Func("cocomo-min-effort",
+		fmt.Sprintf("Minimum COCOMO effort for any non-empty change (default %v)", o.cocomo.MinimumEffort),
+		func(value string) error {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid duration %q", value)
+			}
+			o.cocomo.MinimumEffort = d
+			return o.cocomo.Validate()
+		})
+	fs.BoolVar(&o.includeGenerated, "include-generated", false,
+		"Cost lines added to generated and vendored files (.pb.go, vendor/, ...) like hand-written code")
+	fs.StringVar(&o.compFile, "comp-file", "",
+		"CSV (login,annual_salary[,currency]) or .json ({\"login\": salary}) file of per-author annual salaries;\n"+
+			"unlisted people use --salary")
+	fs.StringVar(&o.currency, "currency", cost.DefaultReportingCurrency,
+		"Reporting currency for all costs; --salary is in this currency")
+	fs.Func("exchange-rate",
+		"Value of one unit of a --comp-file currency in the reporting currency, as CUR=rate, e.g. EUR=1.08 (repeatable)",
+		func(value string) error {
+			currency, rate, err := cost.ParseExchangeRate(value)
+			if err != nil {
+				return err
+			}
+			if o.exchangeRates == nil {
+				o.exchangeRates = make(map[string]float64)
+			}
+			o.exchangeRates[currency] = rate
+			return nil
+		})
+	fs.Func("review-rate",
+		"Review inspection rate for a file category, as category=LOC/hour, e.g. config=1000; categories are\n"+
+			"code, config, docs and generated, and the rest use the default rate (repeatable; needs repo or org file data)",
+		func(value string) error {
+			category, rate, err := cost.ParseInspectionRate(value)
+			if err != nil {
+				return err
+			}
+			if o.reviewRates == nil {
+				o.reviewRates = make(map[string]float64)
+			}
+			o.reviewRates[category] = rate
+			return nil
+		})
+}
+
+// setCOCOMOFloat parses value into one of o's COCOMO parameters, rejecting nonsensical models.
+func (o *options) setCOCOMOFloat(field *float64, value string) error {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", value)
+	}
+	*field = f
+	return o.cocomo.Validate()
+}
+
+// addFetchFlags registers flags for subcommands that fetch PR data.
+func addFetchFlags(fs *flag.FlagSet, o *options) {
+	fs.StringVar(&o.dataSource, "data-source", "prx",
+		"Data source for PR data: prx (direct GitHub API), turnserver, or gitlab (GitLab merge requests; pr and compare only)")
+	fs.StringVar(&o.githubHost, "github-host", "",
+		"GitHub Enterprise Server host, e.g. github.mycorp.com (default: $GITHUB_HOST or github.com)")
+	fs.IntVar(&o.maxRetries, "max-retries", github.DefaultMaxRetries,
+		"Retries for rate-limited (403/429) or failed (5xx) GitHub API calls, with exponential backoff")
+	fs.Func("github-rate",
+		"Average GitHub API requests per second shared by all fetches, searches and counts, e.g. 10 (default: unlimited)",
+		func(value string) error {
+			r, err := strconv.ParseFloat(value, 64)
+			if err != nil || r <= 0 {
+				return fmt.Errorf("invalid rate %q: must be a positive number of requests per second", value)
+			}
+			o.githubRate = r
+			return nil
+		})
+}
+
+// addSamplingFlags registers flags for org/repo sampling subcommands.
+func addSamplingFlags(fs *flag.FlagSet, o *options) {
+	fs.IntVar(&o.samples, "samples", 50, "Number of PRs to sample for extrapolation (30=fast/±18%, 50=slower/±14%)")
+	o.sampling = github.SamplingTime
+	fs.Func("sampling",
+		"How to pick PRs to sample: time (default) spreads them evenly over the period; weighted favors\n"+
+			"long-lived PRs and weights each by the PRs it stands for, for a tighter estimate when a few old PRs dominate",
+		func(value string) error {
+			mode, err := github.ParseSamplingMode(value)
+			if err != nil {
+				return err
+			}
+			o.sampling = mode
+			return nil
+		})
+	fs.IntVar(&o.days, "days", 60, "Number of days to look back for PR modifications")
+	fs.StringVar(&o.sinceFlag, "since", "",
+		"Analyze PRs modified from this date (RFC 3339 or YYYY-MM-DD) instead of the last --days")
+	fs.StringVar(&o.untilFlag, "until", "",
+		"Analyze PRs modified up to this date (RFC 3339 or YYYY-MM-DD, inclusive; requires --since; default: now)")
+	fs.Var(&o.scenarios, "scenario",
+		"What-if scenario as name:key=value[,key=value] (repeatable).\n"+
+			"Keys: salary, benefits, event-minutes, churn-rate, delivery-delay-factor, review-rate, target-merge-time")
+	fs.BoolVar(&o.velocityMedian, "velocity-by-median", false,
+		"Grade merge velocity on the sampled PRs' median (p50) open time instead of the average, so a few stuck PRs don't sink the grade")
+	fs.Func("path", "Only analyze PRs modifying files under this glob, e.g. auth/ or services/*/payments (repeatable)",
+		func(value string) error {
+			if err := cost.ValidatePathPatterns([]string{value}); err != nil {
+				return err
+			}
+			o.filter.Paths = append(o.filter.Paths, value)
+			return nil
+		})
+	fs.Func("label", "Only analyze PRs carrying this label, e.g. team/payments (repeatable; PRs must carry every label)",
+		func(value string) error {
+			if strings.TrimSpace(value) == "" {
+				return errors.New("label must not be empty")
+			}
+			o.filter.Labels = append(o.filter.Labels, value)
+			return nil
+		})
+	fs.Func("change-type",
+		"Classify PRs with this label or title prefix as a change type, as key=type, e.g. kind/cleanup=chore\n"+
+			"(repeatable; adds to the built-in conventional-commit prefixes feat, fix, chore, docs, ...)",
+		func(value string) error {
+			key, changeType, ok := strings.Cut(value, "=")
+			key, changeType = strings.TrimSpace(key), strings.TrimSpace(changeType)
+			if !ok || key == "" || changeType == "" {
+				return fmt.Errorf("invalid change type %q: want key=type", value)
+			}
+			if o.changeTypes == nil {
+				o.changeTypes = make(map[string]string)
+			}
+			o.changeTypes[strings.ToLower(key)] = changeType
+			return nil
+		})
+	fs.Func("author", "Only analyze PRs opened by this GitHub login. With --org, the search itself is narrowed to this author",
+		func(value string) error {
+			if err := github.ValidateAuthor(value); err != nil {
+				return err
+			}
+			o.filter.Author = value
+			return nil
+		})
+	fs.Func("state", "Only analyze PRs in this state: all (default), open, merged or closed (closed without merging).\n"+
+		"merged and closed count PRs merged or closed within --days (or --since/--until)",
+		func(value string) error {
+			state, err := github.ParsePRState(value)
+			if err != nil {
+				return err
+			}
+			o.filter.State = state
+			return nil
+		})
+	fs.BoolVar(&o.filter.ExcludeBots, "exclude-bots", false, "Exclude bot-authored PRs from sampling and extrapolation")
+	fs.BoolVar(&o.dryRun, "dry-run", false,
+		"List the PRs that would be sampled and the time window, without fetching PR data or calculating costs")
+	fs.BoolVar(&o.inclSamples, "include-samples", false,
+		"Include each sampled PR's URL and unextrapolated cost breakdown in the results, most expensive first")
+	fs.StringVar(&o.checkpoint, "checkpoint", "",
+		"Save progress to this JSON file while fetching sampled PRs; re-running the same scan with it resumes where it stopped")
+	o.concurrency = cost.DefaultConcurrency
+	fs.Func("concurrency",
+		fmt.Sprintf("Number of sampled PRs to fetch at once, 1-%d (default %d); lower it to avoid rate limits",
+			cost.MaxConcurrency, cost.DefaultConcurrency),
+		func(value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid concurrency %q: must be a number", value)
+			}
+			if err := cost.ValidateConcurrency(n); err != nil {
+				return err
+			}
+			o.concurrency = n
+			return nil
+		})
+}
+
+// addFromFileFlag registers the flag for costing a saved PR instead of fetching one.
+func addFromFileFlag(fs *flag.FlagSet, o *options) {
+	fs.StringVar(&o.fromFile, "from-file", "",
+		"Cost a prx-format PR JSON dump instead of fetching a PR (no network or GitHub token needed)")
+}
+
+// addQuietFlag registers the flag for printing only a single PR's total cost.
+func addQuietFlag(fs *flag.FlagSet, o *options) {
+	fs.BoolVar(&o.quiet, "quiet", false,
+		"Print only the total cost, for scripts: a bare number, or {\"total_cost\":...} with --format json")
+}
+
+// addGateFlags registers the flags that fail a single PR's run for CI gating.
+func addGateFlags(fs *flag.FlagSet, o *options) {
+	fs.Func("fail-under",
+		"Exit with status 2 if the PR's efficiency percentage is below this, e.g. 80 fails grades below B-\n"+
+			"(A+ 97, A 93, A- 90, B+ 87, B 83, B- 80, C 70, D 60; lower is F)",
+		func(value string) error {
+			pct, err := parseEfficiencyGate(value)
+			if err != nil {
+				return err
+			}
+			o.failUnder = pct
+			return nil
+		})
+	fs.Func("fail-over-cost", "Exit with status 2 if the PR's total cost, in the reporting currency, is above this",
+		func(value string) error {
+			amount, err := parseCostGate(value)
+			if err != nil {
+				return err
+			}
+			o.failOverCost = amount
+			return nil
+		})
+}
+
+// addActualsFlag registers the flag for comparing modeled hours with tracked time.
+func addActualsFlag(fs *flag.FlagSet, o *options) {
+	fs.StringVar(&o.actualsFile, "actuals", "",
+		"CSV file (pr_url,hours) of time actually tracked per PR; reports modeled vs. actual hours")
+}
+
+// parseArgs parses command-line arguments into options.
+// The first argument selects a subcommand; anything else is parsed using the
+// original flag-based interface (bare PR URL, or --org [--repo]) for backward compatibility.
+func parseArgs(args []string, stderr io.Writer) (*options, error) {
+	if len(args) > 0 && slices.Contains(subcommands, args[0]) {
+		return parseSubcommand(args[0], args[1:], stderr)
+	}
+	return parseLegacy(args, stderr)
+}
+
+// parseSubcommand parses arguments for a single subcommand.
+//
+//nolint:revive // cyclomatic: one case per subcommand
+func parseSubcommand(command string, args []string, stderr io.Writer) (*options, error) {
+	o := &options{command: command}
+	fs := flag.NewFlagSet("prcost "+command, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	addCostFlags(fs, o)
+
+	var usage string
+	switch command {
+	case cmdPR:
+		addFetchFlags(fs, o)
+		addFromFileFlag(fs, o)
+		addActualsFlag(fs, o)
+		addQuietFlag(fs, o)
+		addGateFlags(fs, o)
+		usage = "prcost pr [options] <PR_URL>\n       prcost pr [options] --from-file <pr.json>"
+	case cmdRepo:
+		addFetchFlags(fs, o)
+		addSamplingFlags(fs, o)
+		usage = "prcost repo [options] <owner/repo>"
+	case cmdOrg:
+		addFetchFlags(fs, o)
+		addSamplingFlags(fs, o)
+		usage = "prcost org [options] <org>"
+	case cmdEstimate:
+		fs.IntVar(&o.linesAdded, "lines-added", 0, "Lines added by the hypothetical PR")
+		fs.IntVar(&o.linesDeleted, "lines-deleted", 0, "Lines deleted by the hypothetical PR")
+		fs.DurationVar(&o.openTime, "open-time", 24*time.Hour, "How long the PR stays open before merging")
+		fs.IntVar(&o.reviewers, "reviewers", 1, "Number of reviewers")
+		addQuietFlag(fs, o)
+		usage = "prcost estimate [options]"
+	case cmdCompare:
+		addFetchFlags(fs, o)
+		usage = "prcost compare [options] <PR_URL> <PR_URL>"
+	case cmdComment:
+		addFetchFlags(fs, o)
+		fs.StringVar(&o.commentTemplate, "comment-template", "",
+			"Go text/template file for the comment body (default: built-in Markdown breakdown)")
+		usage = "prcost comment [options] <PR_URL>"
+	default:
+		return nil, fmt.Errorf("unknown command %q", command)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: %s\n\nOptions:\n", usage)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	o.args = fs.Args()
+
+	var err error
+	switch command {
+	case cmdPR:
+		switch {
+		case o.fromFile != "" && len(o.args) != 0:
+			err = errors.New("cannot use both --from-file and a PR URL")
+		case o.fromFile == "" && len(o.args) != 1:
+			err = errors.New("pr requires exactly one PR URL")
+		case o.fromFile != "" && o.actualsFile != "":
+			err = errors.New("--actuals requires a PR URL, not --from-file")
+		default:
+		}
+	case cmdRepo:
+		owner, repo, ok := strings.Cut(fs.Arg(0), "/")
+		if fs.NArg() != 1 || !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+			err = errors.New("repo requires exactly one owner/repo argument")
+		}
+		o.org, o.repo = owner, repo
+	case cmdOrg:
+		if fs.NArg() != 1 || fs.Arg(0) == "" {
+			err = errors.New("org requires exactly one organization argument")
+		}
+		o.org = fs.Arg(0)
+	case cmdEstimate:
+		switch {
+		case fs.NArg() != 0:
+			err = errors.New("estimate does not take positional arguments")
+		case o.linesAdded < 0 || o.linesDeleted < 0 || o.reviewers < 0:
+			err = errors.New("estimate values must not be negative")
+		case o.linesAdded == 0 && o.linesDeleted == 0:
+			err = errors.New("estimate requires --lines-added or --lines-deleted")
+		case o.openTime < 0:
+			err = errors.New("--open-time must not be negative")
+		default:
+		}
+	case cmdCompare:
+		if len(o.args) != 2 {
+			err = errors.New("compare requires exactly two PR URLs")
+		}
+	case cmdComment:
+		if len(o.args) != 1 {
+			err = errors.New("comment requires exactly one PR URL")
+		}
+	default:
+	}
+	if err == nil && o.quiet && o.verbose {
+		err = errors.New("cannot use both --quiet and --verbose")
+	}
+	if err == nil && (command == cmdRepo || command == cmdOrg) {
+		err = parseWindowFlags(fs, o)
+	}
+	if err == nil {
+		err = loadProfile(fs, o)
+	}
+	if err == nil && o.dataSource == "gitlab" && command != cmdPR && command != cmdCompare {
+		err = fmt.Errorf("--data-source gitlab only supports the pr and compare commands, not %s", command)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n\n", err)
+		fs.Usage()
+		return nil, errUsage
+	}
+	return o, nil
+}
+
+// parseWindowFlags resolves --since and --until into an absolute analysis window,
+// setting o.days to its length. They cannot be combined with an explicit --days.
+func parseWindowFlags(fs *flag.FlagSet, o *options) error {
+	if o.sinceFlag == "" && o.untilFlag == "" {
+		return nil
+	}
+	daysSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "days" {
+			daysSet = true
+		}
+	})
+	if daysSet {
+		return errors.New("--days cannot be combined with --since or --until")
+	}
+	since, until, err := github.ParseWindow(o.sinceFlag, o.untilFlag, time.Now())
+	if err != nil {
+		return fmt.Errorf("invalid --since/--until window: %w", err)
+	}
+	o.since, o.until = since, until
+	o.days = github.WindowDays(since, until)
+	return nil
+}
+
+// loadProfile loads the --profile named in the --config file and records which flags were given
+// on the command line, so options.config uses the profile for every other setting: flags override
+// the profile, which overrides defaults.
+func loadProfile(fs *flag.FlagSet, o *options) error {
+	if o.profile == "" {
+		if o.configFile != "" {
+			return errors.New("--config requires --profile")
+		}
+		return nil
+	}
+	path := o.configFile
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("locating profiles file: %w", err)
+		}
+		path = filepath.Join(home, ".config", "prcost", "profiles.yaml")
+	}
+	profiles, err := cost.LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	cfg, ok := profiles[o.profile]
+	if !ok {
+		return fmt.Errorf("profile %q not found in %s; available: %s",
+			o.profile, path, strings.Join(slices.Sorted(maps.Keys(profiles)), ", "))
+	}
+
+	o.setFlags = make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { o.setFlags[f.Name] = true })
+	o.profileConfig = &cfg
+	if err := o.config().COCOMO.Validate(); err != nil {
+		return fmt.Errorf("profile %q: %w", o.profile, err)
+	}
+	return nil
+}
+
+// parseLegacy parses the original flag-based interface:
+// a bare PR URL, or --org with an optional --repo.
+func parseLegacy(args []string, stderr io.Writer) (*options, error) {
+	o := &options{}
+	fs := flag.NewFlagSet("prcost", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	addCostFlags(fs, o)
+	addFetchFlags(fs, o)
+	addFromFileFlag(fs, o)
+	addActualsFlag(fs, o)
+	addQuietFlag(fs, o)
+	addGateFlags(fs, o)
+	fs.StringVar(&o.org, "org", "", "GitHub organization to analyze (optionally with --repo for single repo)")
+	fs.StringVar(&o.repo, "repo", "", "GitHub repository to analyze (requires --org)")
+	addSamplingFlags(fs, o)
+	fs.Usage = func() { printUsage(stderr, fs) }
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	o.args = fs.Args()
+
+	orgMode := o.org != ""
+	singlePRMode := fs.NArg() == 1
+	fromFileMode := o.fromFile != ""
+
+	var err error
+	switch {
+	case o.repo != "" && o.org == "":
+		err = errors.New("--repo requires --org to be specified")
+	case orgMode && singlePRMode:
+		err = errors.New("cannot use both --org and PR URL. Choose one mode")
+	case fromFileMode && (orgMode || fs.NArg() != 0):
+		err = errors.New("--from-file cannot be combined with --org or a PR URL")
+	case o.actualsFile != "" && (orgMode || fromFileMode):
+		err = errors.New("--actuals requires a PR URL")
+	case o.quiet && orgMode:
+		err = errors.New("--quiet requires a PR URL or --from-file")
+	case o.quiet && o.verbose:
+		err = errors.New("cannot use both --quiet and --verbose")
+	case (o.failUnder > 0 || o.failOverCost > 0) && orgMode:
+		err = errors.New("--fail-under and --fail-over-cost require a PR URL or --from-file")
+	case !orgMode && !singlePRMode && !fromFileMode:
+		fs.Usage()
+		return nil, errUsage
+	case len(o.scenarios) > 0 && !orgMode:
+		err = errors.New("--scenario requires --org")
+	case !o.filter.IsZero() && !orgMode:
+		err = errors.New("--path, --label, --author, --state and --exclude-bots require --org")
+	case (o.sinceFlag != "" || o.untilFlag != "") && !orgMode:
+		err = errors.New("--since and --until require --org")
+	case orgMode && o.repo != "":
+		o.command = cmdRepo
+	case orgMode:
+		o.command = cmdOrg
+	default:
+		o.command = cmdPR
+	}
+	if err == nil && orgMode {
+		err = parseWindowFlags(fs, o)
+	}
+	if err == nil {
+		err = loadProfile(fs, o)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n\n", err)
+		fs.Usage()
+		return nil, errUsage
+	}
+	return o, nil
+}
+
+// printUsage prints top-level usage, listing subcommands and legacy flags.
+func printUsage(w io.Writer, fs *flag.FlagSet) {
+	name := os.Args[0]
+	fmt.Fprintf(w, "Usage: %s <command> [options] [arguments]\n", name)
+	fmt.Fprintf(w, "       %s [options] <PR_URL>\n", name)
+	fmt.Fprintf(w, "       %s --org <org> [--repo <repo>] [options]\n\n", name)
+	fmt.Fprint(w, "Calculate the real-world cost of GitHub pull requests.\n\n")
+	fmt.Fprint(w, "Commands:\n")
+	fmt.Fprint(w, "  pr <PR_URL>             Analyze a single PR\n")
+	fmt.Fprint(w, "  repo <owner/repo>       Analyze one repository by sampling PRs\n")
+	fmt.Fprint(w, "  org <org>               Analyze an entire organization by sampling PRs\n")
+	fmt.Fprint(w, "  estimate                Estimate the cost of a hypothetical PR\n")
+	fmt.Fprint(w, "  compare <URL> <URL>     Compare the cost of two PRs\n")
+	fmt.Fprint(w, "  comment <PR_URL>        Post or update a PR comment with the PR's cost\n\n")
+	fmt.Fprintf(w, "Run '%s <command> -h' for command options.\n\n", name)
+	fmt.Fprint(w, "Options (without a command):\n")
+	fs.PrintDefaults()
+	fmt.Fprint(w, "\nExamples:\n")
+	fmt.Fprintf(w, "  %s pr https://github.com/owner/repo/pull/123\n", name)
+	fmt.Fprintf(w, "  %s pr --salary 300000 https://github.com/owner/repo/pull/123\n", name)
+	fmt.Fprintf(w, "  %s pr --from-file pr.json\n", name)
+	fmt.Fprintf(w, "  %s repo --samples 50 --days 30 kubernetes/kubernetes\n", name)
+	fmt.Fprintf(w, "  %s org --scenario half-churn:churn-rate=0.0115 chainguard-dev\n", name)
+	fmt.Fprintf(w, "  %s org --path payments/ --path auth/ chainguard-dev\n", name)
+	fmt.Fprintf(w, "  %s org --fiscal-year-start 10 chainguard-dev\n", name)
+	fmt.Fprintf(w, "  %s org --dry-run --samples 30 chainguard-dev\n", name)
+	fmt.Fprintf(w, "  %s estimate --lines-added 400 --lines-deleted 50 --open-time 48h\n", name)
+	fmt.Fprintf(w, "  %s compare https://github.com/owner/repo/pull/1 https://github.com/owner/repo/pull/2\n", name)
+	fmt.Fprintf(w, "  %s comment https://github.com/owner/repo/pull/123\n", name)
+}
@@ -0,0 +1,677 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cocomo"
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+	"github.com/codeGROOVE-dev/prcost/pkg/github"
+	"golang.org/x/text/language"
+)
+
+func TestParseArgsSubcommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		command string
+		org     string
+		repo    string
+		posArgs []string
+	}{
+		{
+			name:    "pr",
+			args:    []string{"pr", "--salary", "300000", "https://github.com/owner/repo/pull/1"},
+			command: cmdPR,
+			posArgs: []string{"https://github.com/owner/repo/pull/1"},
+		},
+		{
+			name:    "repo",
+			args:    []string{"repo", "--samples", "30", "kubernetes/kubernetes"},
+			command: cmdRepo,
+			org:     "kubernetes",
+			repo:    "kubernetes",
+			posArgs: []string{"kubernetes/kubernetes"},
+		},
+		{
+			name:    "org",
+			args:    []string{"org", "--days", "30", "chainguard-dev"},
+			command: cmdOrg,
+			org:     "chainguard-dev",
+			posArgs: []string{"chainguard-dev"},
+		},
+		{
+			name:    "estimate",
+			args:    []string{"estimate", "--lines-added", "100"},
+			command: cmdEstimate,
+		},
+		{
+			name:    "compare",
+			args:    []string{"compare", "https://github.com/o/r/pull/1", "https://github.com/o/r/pull/2"},
+			command: cmdCompare,
+			posArgs: []string{"https://github.com/o/r/pull/1", "https://github.com/o/r/pull/2"},
+		},
+		{
+			name:    "comment",
+			args:    []string{"comment", "--comment-template", "cost.tmpl", "https://github.com/o/r/pull/1"},
+			command: cmdComment,
+			posArgs: []string{"https://github.com/o/r/pull/1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseArgs(tt.args, io.Discard)
+			if err != nil {
+				t.Fatalf("parseArgs(%v) unexpected error: %v", tt.args, err)
+			}
+			if opts.command != tt.command {
+				t.Errorf("command = %q, want %q", opts.command, tt.command)
+			}
+			if opts.org != tt.org || opts.repo != tt.repo {
+				t.Errorf("org/repo = %q/%q, want %q/%q", opts.org, opts.repo, tt.org, tt.repo)
+			}
+			if !slices.Equal(opts.args, tt.posArgs) {
+				t.Errorf("args = %v, want %v", opts.args, tt.posArgs)
+			}
+		})
+	}
+}
+
+func TestParseArgsSubcommandFlags(t *testing.T) {
+	opts, err := parseArgs([]string{"pr", "--salary", "300000", "--format", "json", "https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.salary != 300000 || opts.format != "json" {
+		t.Errorf("salary/format = %v/%q, want 300000/json", opts.salary, opts.format)
+	}
+	if cfg := opts.config(); cfg.AnnualSalary != 300000 || cfg.MinDelayThresholdMinutes != 30 {
+		t.Errorf("config salary/min delay = %v/%v, want 300000/30", cfg.AnnualSalary, cfg.MinDelayThresholdMinutes)
+	}
+	if cfg := opts.config(); cfg.COCOMO != cocomo.DefaultConfig() {
+		t.Errorf("config COCOMO = %+v, want defaults", cfg.COCOMO)
+	}
+
+	opts, err = parseArgs([]string{"pr", "--min-delay-minutes", "10", "https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg := opts.config(); cfg.MinDelayThresholdMinutes != 10 {
+		t.Errorf("config min delay = %v, want 10", cfg.MinDelayThresholdMinutes)
+	}
+
+	opts, err = parseArgs([]string{"pr", "--max-code-drift", "4320h", "https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg := opts.config(); cfg.MaxCodeDrift != 180*24*time.Hour || cfg.MaxProjectDelay != 90*24*time.Hour {
+		t.Errorf("config drift/project caps = %v/%v, want 4320h/2160h", cfg.MaxCodeDrift, cfg.MaxProjectDelay)
+	}
+
+	opts, err = parseArgs([]string{"org", "--max-pr-code-cost", "25000", "myorg"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg := opts.config(); cfg.MaxPRCodeCost != 25000 {
+		t.Errorf("config MaxPRCodeCost = %v, want 25000", cfg.MaxPRCodeCost)
+	}
+
+	opts, err = parseArgs([]string{"org", "--currency", "usd", "--exchange-rate", "EUR=1.08", "--exchange-rate", "gbp=1.27", "myorg"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg := opts.config(); cfg.ReportingCurrency != "USD" || cfg.ExchangeRates["EUR"] != 1.08 || cfg.ExchangeRates["GBP"] != 1.27 {
+		t.Errorf("config currency/rates = %q/%v, want USD with EUR and GBP rates", cfg.ReportingCurrency, cfg.ExchangeRates)
+	}
+	opts, err = parseArgs([]string{"org", "--review-rate", "config=1000", "--review-rate", "Docs=2000", "myorg"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rates := opts.config().ReviewInspectionRates; rates[cost.FileCategoryConfig] != 1000 || rates[cost.FileCategoryDocs] != 2000 {
+		t.Errorf("config ReviewInspectionRates = %v, want config=1000, docs=2000", rates)
+	}
+
+	if _, err := parseArgs([]string{"org", "--exchange-rate", "EUR", "myorg"}, io.Discard); err == nil {
+		t.Error("Expected error for --exchange-rate without a rate")
+	}
+
+	opts, err = parseArgs([]string{"repo", "--dry-run", "o/r"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.dryRun || opts.org != "o" || opts.repo != "r" {
+		t.Errorf("dryRun/org/repo = %v/%q/%q, want true/o/r", opts.dryRun, opts.org, opts.repo)
+	}
+	if opts.inclSamples {
+		t.Error("inclSamples = true, want false by default")
+	}
+
+	opts, err = parseArgs([]string{"org", "--include-samples", "myorg"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.inclSamples {
+		t.Error("inclSamples = false, want true with --include-samples")
+	}
+
+	opts, err = parseArgs([]string{"org", "--fiscal-year-start", "10", "myorg"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg := opts.config(); cfg.FiscalYearStartMonth != 10 {
+		t.Errorf("config fiscal year start = %d, want 10", cfg.FiscalYearStartMonth)
+	}
+	if _, err := parseArgs([]string{"org", "--fiscal-year-start", "13", "myorg"}, io.Discard); err == nil {
+		t.Error("Expected error for --fiscal-year-start 13")
+	}
+
+	opts, err = parseArgs([]string{"pr", "--include-generated", "https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg := opts.config(); cfg.ExcludeGeneratedFromCost {
+		t.Error("--include-generated should disable ExcludeGeneratedFromCost")
+	}
+
+	opts, err = parseArgs([]string{"org", "--samples", "30", "--days", "14", "--scenario", "a:salary=1", "--scenario", "b:benefits=2", "myorg"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.samples != 30 || opts.days != 14 {
+		t.Errorf("samples/days = %d/%d, want 30/14", opts.samples, opts.days)
+	}
+	if len(opts.scenarios) != 2 {
+		t.Errorf("expected 2 scenarios, got %d", len(opts.scenarios))
+	}
+	if opts.concurrency != cost.DefaultConcurrency {
+		t.Errorf("concurrency = %d, want default %d", opts.concurrency, cost.DefaultConcurrency)
+	}
+
+	opts, err = parseArgs([]string{"--org", "myorg", "--concurrency", "16"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.concurrency != 16 {
+		t.Errorf("concurrency = %d, want 16", opts.concurrency)
+	}
+	for _, n := range []string{"0", "33", "many"} {
+		if _, err := parseArgs([]string{"repo", "--concurrency", n, "o/r"}, io.Discard); err == nil {
+			t.Errorf("Expected error for --concurrency %s", n)
+		}
+	}
+
+	opts, err = parseArgs([]string{"repo", "--path", "auth/", "--path", "services/*/payments", "o/r"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(opts.filter.Paths, []string{"auth/", "services/*/payments"}) {
+		t.Errorf("paths = %v, want [auth/ services/*/payments]", opts.filter.Paths)
+	}
+
+	opts, err = parseArgs([]string{"pr", "--require-waiting-evidence", "https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.config().RequireWaitingEvidence || opts.config().CountDraftTime {
+		t.Error("--require-waiting-evidence not applied to the config")
+	}
+
+	opts, err = parseArgs([]string{"pr", "--count-draft-time", "https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.config().CountDraftTime {
+		t.Error("--count-draft-time not applied to the config")
+	}
+	if opts.config().MaxWaitingMultiplier != 1 {
+		t.Errorf("MaxWaitingMultiplier = %v, want 1 by default", opts.config().MaxWaitingMultiplier)
+	}
+	if !opts.config().CountBotParticipantCosts {
+		t.Error("CountBotParticipantCosts = false, want true by default")
+	}
+	if !opts.config().IncludeFutureCosts {
+		t.Error("IncludeFutureCosts = false, want true by default")
+	}
+
+	opts, err = parseArgs([]string{"org", "--github-rate", "12.5", "myorg"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.githubRate != 12.5 {
+		t.Errorf("githubRate = %v, want 12.5", opts.githubRate)
+	}
+
+	opts, err = parseArgs([]string{"pr", "--bot-overhead-only", "https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.config().CountBotParticipantCosts {
+		t.Error("--bot-overhead-only not applied to the config")
+	}
+
+	opts, err = parseArgs([]string{"pr", "--no-future-costs", "https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.config().IncludeFutureCosts {
+		t.Error("--no-future-costs not applied to the config")
+	}
+
+	opts, err = parseArgs([]string{"pr", "--session-model", "flat", "https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := opts.config().SessionModel; got != cost.SessionModelFlat {
+		t.Errorf("SessionModel = %q, want %q", got, cost.SessionModelFlat)
+	}
+
+	opts, err = parseArgs([]string{"pr", "--max-waiting-multiplier", "4", "https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.config().MaxWaitingMultiplier != 4 {
+		t.Errorf("MaxWaitingMultiplier = %v, want 4", opts.config().MaxWaitingMultiplier)
+	}
+
+	opts, err = parseArgs([]string{"pr", "--working-calendar", "mon-fri 9-17 UTC", "https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cal := opts.config().WorkingCalendar; cal == nil || cal.StartHour != 9 || cal.EndHour != 17 || len(cal.Days) != 5 {
+		t.Errorf("WorkingCalendar = %+v, want mon-fri 9-17", cal)
+	}
+	if _, err := parseArgs([]string{"pr", "--working-calendar", "weekdays", "https://github.com/o/r/pull/1"}, io.Discard); err == nil {
+		t.Error("parseArgs() accepted an invalid --working-calendar")
+	}
+
+	mrURL := "https://gitlab.com/group/project/-/merge_requests/7"
+	opts, err = parseArgs([]string{"pr", "--data-source", "gitlab", mrURL}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := validatePRURL(t.Context(), mrURL, opts.dataSource); err != nil {
+		t.Errorf("validatePRURL() rejected a GitLab merge request URL: %v", err)
+	}
+	if err := validatePRURL(t.Context(), "https://github.com/o/r/pull/1", opts.dataSource); err == nil {
+		t.Error("validatePRURL() accepted a GitHub PR URL for the gitlab data source")
+	}
+	if _, err := parseArgs([]string{"repo", "--data-source", "gitlab", "o/r"}, io.Discard); err == nil {
+		t.Error("parseArgs() accepted repo sampling with --data-source gitlab")
+	}
+
+	for _, flagName := range []string{"--output", "-o"} {
+		opts, err = parseArgs([]string{"repo", flagName, "report.json", "--format", "json", "o/r"}, io.Discard)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if opts.output != "report.json" {
+			t.Errorf("%s: output = %q, want report.json", flagName, opts.output)
+		}
+	}
+
+	opts, err = parseArgs([]string{"org", "--maintainer-salary", "300000", "--contributor-salary", "120000", "myorg"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg := opts.config(); cfg.MaintainerSalary != 300000 || cfg.ContributorSalary != 120000 {
+		t.Errorf("salary tiers = %v/%v, want 300000/120000", cfg.MaintainerSalary, cfg.ContributorSalary)
+	}
+
+	opts, err = parseArgs([]string{"pr", "--no-callout", "https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.noCallout {
+		t.Error("--no-callout not set")
+	}
+
+	opts, err = parseArgs([]string{"pr", "--from-file", "pr.json"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.command != cmdPR || opts.fromFile != "pr.json" || len(opts.args) != 0 {
+		t.Errorf("pr --from-file parsed as %+v", opts)
+	}
+
+	opts, err = parseArgs([]string{"org", "--label", "team/payments", "--label", "bug", "--author", "alice", "--exclude-bots", "myorg"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(opts.filter.Labels, []string{"team/payments", "bug"}) || opts.filter.Author != "alice" || !opts.filter.ExcludeBots {
+		t.Errorf("filter = %+v, want labels [team/payments bug], author alice, exclude bots", opts.filter)
+	}
+
+	opts, err = parseArgs([]string{"pr", "--ignore-event", "Labeled", "--ignore-event", "subscribed", "https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if kinds := opts.config().IgnoredEventKinds; !slices.Equal(kinds, []string{"labeled", "subscribed"}) {
+		t.Errorf("IgnoredEventKinds = %v, want [labeled subscribed]", kinds)
+	}
+
+	opts, err = parseArgs([]string{"pr", "--cocomo-multiplier", "3.2", "--cocomo-exponent", "1.05", "--cocomo-min-effort", "30m", "https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c := opts.config().COCOMO; c.Multiplier != 3.2 || c.Exponent != 1.05 || c.MinimumEffort != 30*time.Minute {
+		t.Errorf("COCOMO = %+v, want multiplier 3.2, exponent 1.05, minimum effort 30m", c)
+	}
+
+	opts, err = parseArgs([]string{"pr", "--actuals", "harvest.csv", "https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.actualsFile != "harvest.csv" {
+		t.Errorf("actualsFile = %q, want harvest.csv", opts.actualsFile)
+	}
+
+	opts, err = parseArgs([]string{"repo", "--state", "Merged", "o/r"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.filter.State != github.PRStateMerged {
+		t.Errorf("filter state = %q, want merged", opts.filter.State)
+	}
+	if _, err := parseArgs([]string{"repo", "--state", "draft", "o/r"}, io.Discard); err == nil {
+		t.Error("Expected error for --state draft")
+	}
+	if _, err := parseArgs([]string{"org", "--author", "alice is:open", "myorg"}, io.Discard); err == nil {
+		t.Error("Expected error for an --author that isn't a GitHub login")
+	}
+
+	opts, err = parseArgs([]string{"repo", "--round", "Thousand", "--round-json", "o/r"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.roundUnit != 1000 || !opts.roundJSON {
+		t.Errorf("rounding = %v (JSON %v), want 1000 for JSON too", opts.roundUnit, opts.roundJSON)
+	}
+	if _, err := parseArgs([]string{"repo", "--round", "cents", "o/r"}, io.Discard); err == nil {
+		t.Error("Expected error for --round cents")
+	}
+
+	opts, err = parseArgs([]string{"repo", "o/r"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.locale != language.AmericanEnglish {
+		t.Errorf("default locale = %v, want en-US", opts.locale)
+	}
+	opts, err = parseArgs([]string{"repo", "--locale", "de_DE", "--currency", "eur", "o/r"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.locale != language.MustParse("de-DE") || opts.config().ReportingCurrency != "EUR" {
+		t.Errorf("--locale de_DE --currency eur = %v in %s, want de-DE in EUR", opts.locale, opts.config().ReportingCurrency)
+	}
+	if _, err := parseArgs([]string{"repo", "--locale", "xx-YY", "o/r"}, io.Discard); err == nil {
+		t.Error("Expected error for --locale xx-YY")
+	}
+
+	opts, err = parseArgs([]string{"org", "--sampling", "weighted", "myorg"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.sampling != github.SamplingWeighted {
+		t.Errorf("sampling = %q, want weighted", opts.sampling)
+	}
+	opts, err = parseArgs([]string{"repo", "o/r"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.sampling != github.SamplingTime {
+		t.Errorf("default sampling = %q, want time", opts.sampling)
+	}
+	if _, err := parseArgs([]string{"repo", "--sampling", "random", "o/r"}, io.Discard); err == nil {
+		t.Error("Expected error for --sampling random")
+	}
+
+	opts, err = parseArgs([]string{"org", "--velocity-by-median", "myorg"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.config().GradeVelocityByMedian {
+		t.Error("--velocity-by-median not applied to the config")
+	}
+
+	opts, err = parseArgs([]string{"org", "--since", "2025-01-01", "--until", "2025-03-31", "myorg"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.since.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) || opts.until.Format("2006-01-02") != "2025-03-31" || opts.days != 90 {
+		t.Errorf("window = %v to %v (%d days), want 2025-01-01 to 2025-03-31 (90 days)", opts.since, opts.until, opts.days)
+	}
+	if w := newAnalysisWindow(opts); w.String() != "between 2025-01-01 and 2025-03-31" {
+		t.Errorf("window description = %q", w)
+	}
+
+	opts, err = parseArgs([]string{"repo", "--change-type", "Kind/Cleanup=chore", "--change-type", "feat=product", "o/r"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if types := opts.config().ChangeTypes; types["kind/cleanup"] != "chore" || types["feat"] != "product" || types["fix"] != "fix" {
+		t.Errorf("ChangeTypes = %v, want overrides merged into the defaults", types)
+	}
+
+	opts, err = parseArgs([]string{"estimate", "--lines-added", "400", "--lines-deleted", "50", "--open-time", "48h", "--reviewers", "2"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.linesAdded != 400 || opts.linesDeleted != 50 || opts.openTime != 48*time.Hour || opts.reviewers != 2 {
+		t.Errorf("estimate options = %+v", opts)
+	}
+}
+
+func TestParseArgsProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.yaml")
+	profiles := `payments:
+  annual_salary: 310000
+  event_duration: 15m
+  weekly_churn_rate: 0.03
+  ignored_event_kinds: [labeled]
+  exchange_rates:
+    EUR: 1.1
+`
+	if err := os.WriteFile(path, []byte(profiles), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := parseArgs([]string{"pr", "--config", path, "--profile", "payments", "--benefits", "1.5",
+		"--exchange-rate", "GBP=1.3", "https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg := opts.config()
+	if cfg.AnnualSalary != 310000 || cfg.EventDuration != 15*time.Minute || cfg.WeeklyChurnRate != 0.03 {
+		t.Errorf("salary/event/churn = %v/%v/%v, want the profile's 310000/15m/0.03",
+			cfg.AnnualSalary, cfg.EventDuration, cfg.WeeklyChurnRate)
+	}
+	if cfg.BenefitsMultiplier != 1.5 || cfg.MinDelayThresholdMinutes != 30 {
+		t.Errorf("benefits/min delay = %v/%v, want the flag's 1.5 and the default 30", cfg.BenefitsMultiplier, cfg.MinDelayThresholdMinutes)
+	}
+	if !slices.Equal(cfg.IgnoredEventKinds, []string{"labeled"}) || cfg.ExchangeRates["EUR"] != 1.1 || cfg.ExchangeRates["GBP"] != 1.3 {
+		t.Errorf("ignored events/rates = %v/%v, want the profile's plus GBP from the flag", cfg.IgnoredEventKinds, cfg.ExchangeRates)
+	}
+
+	opts, err = parseArgs([]string{"--config", path, "--profile", "payments", "--salary", "200000",
+		"https://github.com/o/r/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg := opts.config(); cfg.AnnualSalary != 200000 || cfg.WeeklyChurnRate != 0.03 {
+		t.Errorf("legacy salary/churn = %v/%v, want the flag's 200000 and the profile's 0.03", cfg.AnnualSalary, cfg.WeeklyChurnRate)
+	}
+
+	for _, args := range [][]string{
+		{"pr", "--config", path, "--profile", "platform", "https://github.com/o/r/pull/1"},
+		{"pr", "--config", path, "https://github.com/o/r/pull/1"},
+		{"pr", "--config", filepath.Join(t.TempDir(), "missing.yaml"), "--profile", "payments", "https://github.com/o/r/pull/1"},
+	} {
+		if _, err := parseArgs(args, io.Discard); !errors.Is(err, errUsage) {
+			t.Errorf("parseArgs(%q) error = %v, want usage error", args, err)
+		}
+	}
+}
+
+func TestCostFlagsCoverCostSettings(t *testing.T) {
+	// Flags addCostFlags registers that don't set cost configuration
+	notConfig := []string{"profile", "config", "format", "output", "o", "round", "round-json", "locale",
+		"verbose", "anonymize", "no-callout", "comp-file"}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	addCostFlags(fs, &options{})
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, ok := costFlags[f.Name]; !ok && !slices.Contains(notConfig, f.Name) {
+			t.Errorf("flag --%s is neither in costFlags nor known not to set cost configuration", f.Name)
+		}
+	})
+}
+
+func TestParseArgsSubcommandErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"pr without URL", []string{"pr"}},
+		{"pr with two URLs", []string{"pr", "https://github.com/o/r/pull/1", "https://github.com/o/r/pull/2"}},
+		{"repo without slash", []string{"repo", "kubernetes"}},
+		{"repo with empty name", []string{"repo", "kubernetes/"}},
+		{"org without name", []string{"org"}},
+		{"estimate without lines", []string{"estimate"}},
+		{"estimate with negative lines", []string{"estimate", "--lines-added", "-1"}},
+		{"compare with one URL", []string{"compare", "https://github.com/o/r/pull/1"}},
+		{"scenario not allowed for pr", []string{"pr", "--scenario", "a:salary=1", "https://github.com/o/r/pull/1"}},
+		{"sampling flags not allowed for estimate", []string{"estimate", "--samples", "10", "--lines-added", "1"}},
+		{"path not allowed for pr", []string{"pr", "--path", "auth/", "https://github.com/o/r/pull/1"}},
+		{"malformed path glob", []string{"org", "--path", "[auth", "myorg"}},
+		{"label not allowed for pr", []string{"pr", "--label", "bug", "https://github.com/o/r/pull/1"}},
+		{"empty label", []string{"org", "--label", " ", "myorg"}},
+		{"malformed change type", []string{"org", "--change-type", "feat", "myorg"}},
+		{"comment without URL", []string{"comment"}},
+		{"comment-template not allowed for pr", []string{"pr", "--comment-template", "cost.tmpl", "https://github.com/o/r/pull/1"}},
+		{"from-file with PR URL", []string{"pr", "--from-file", "pr.json", "https://github.com/o/r/pull/1"}},
+		{"from-file not allowed for repo", []string{"repo", "--from-file", "pr.json", "o/r"}},
+		{"zero COCOMO multiplier", []string{"pr", "--cocomo-multiplier", "0", "https://github.com/o/r/pull/1"}},
+		{"negative COCOMO exponent", []string{"pr", "--cocomo-exponent", "-1", "https://github.com/o/r/pull/1"}},
+		{"negative COCOMO minimum effort", []string{"pr", "--cocomo-min-effort", "-5m", "https://github.com/o/r/pull/1"}},
+		{"actuals with from-file", []string{"pr", "--actuals", "harvest.csv", "--from-file", "pr.json"}},
+		{"actuals not allowed for repo", []string{"repo", "--actuals", "harvest.csv", "o/r"}},
+		{"legacy actuals with org", []string{"--org", "myorg", "--actuals", "harvest.csv"}},
+		{"unknown event kind", []string{"pr", "--ignore-event", "labelled", "https://github.com/o/r/pull/1"}},
+		{"zero GitHub rate", []string{"org", "--github-rate", "0", "myorg"}},
+		{"non-numeric GitHub rate", []string{"org", "--github-rate", "fast", "myorg"}},
+		{"unknown review rate category", []string{"org", "--review-rate", "tests=500", "myorg"}},
+		{"quiet not allowed for org", []string{"org", "--quiet", "myorg"}},
+		{"legacy quiet with org", []string{"--org", "myorg", "--quiet"}},
+		{"quiet with verbose", []string{"pr", "--quiet", "--verbose", "https://github.com/o/r/pull/1"}},
+		{"fail-under above 100", []string{"pr", "--fail-under", "120", "https://github.com/o/r/pull/1"}},
+		{"non-numeric fail-under", []string{"pr", "--fail-under", "B", "https://github.com/o/r/pull/1"}},
+		{"zero fail-over-cost", []string{"pr", "--fail-over-cost", "0", "https://github.com/o/r/pull/1"}},
+		{"fail-under not allowed for org", []string{"org", "--fail-under", "80", "myorg"}},
+		{"legacy fail-over-cost with org", []string{"--org", "myorg", "--fail-over-cost", "5000"}},
+		{"unknown session model", []string{"pr", "--session-model", "hourly", "https://github.com/o/r/pull/1"}},
+		{"since not allowed for pr", []string{"pr", "--since", "2025-01-01", "https://github.com/o/r/pull/1"}},
+		{"since with days", []string{"repo", "--days", "30", "--since", "2025-01-01", "o/r"}},
+		{"until without since", []string{"repo", "--until", "2025-01-01", "o/r"}},
+		{"since after until", []string{"org", "--since", "2025-03-01", "--until", "2025-02-01", "myorg"}},
+		{"malformed since", []string{"org", "--since", "last week", "myorg"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseArgs(tt.args, io.Discard); err == nil {
+				t.Errorf("parseArgs(%v) expected error", tt.args)
+			}
+		})
+	}
+}
+
+func TestParseArgsLegacy(t *testing.T) {
+	opts, err := parseArgs([]string{"--salary", "200000", "https://github.com/owner/repo/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.command != cmdPR || opts.args[0] != "https://github.com/owner/repo/pull/1" || opts.salary != 200000 {
+		t.Errorf("bare URL parsed as %+v", opts)
+	}
+
+	opts, err = parseArgs([]string{"--quiet", "--format", "json", "https://github.com/owner/repo/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.command != cmdPR || !opts.quiet || opts.format != "json" {
+		t.Errorf("--quiet URL parsed as %+v", opts)
+	}
+
+	opts, err = parseArgs([]string{"--fail-under", "80%", "--fail-over-cost", "5000", "https://github.com/owner/repo/pull/1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.command != cmdPR || opts.failUnder != 80 || opts.failOverCost != 5000 {
+		t.Errorf("--fail-under/--fail-over-cost URL parsed as %+v", opts)
+	}
+
+	opts, err = parseArgs([]string{"--anonymize", "--org", "myorg"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.anonymize {
+		t.Error("--anonymize not set")
+	}
+
+	opts, err = parseArgs([]string{"--org", "myorg", "--repo", "myrepo"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.command != cmdRepo || opts.org != "myorg" || opts.repo != "myrepo" {
+		t.Errorf("--org --repo parsed as %+v", opts)
+	}
+
+	opts, err = parseArgs([]string{"--org", "myorg", "--scenario", "a:salary=1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.command != cmdOrg || len(opts.scenarios) != 1 {
+		t.Errorf("--org parsed as %+v", opts)
+	}
+
+	opts, err = parseArgs([]string{"--from-file", "pr.json"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.command != cmdPR || opts.fromFile != "pr.json" {
+		t.Errorf("--from-file parsed as %+v", opts)
+	}
+
+	for _, args := range [][]string{
+		{},
+		{"--repo", "myrepo"},
+		{"--org", "myorg", "https://github.com/o/r/pull/1"},
+		{"--scenario", "a:salary=1", "https://github.com/o/r/pull/1"},
+		{"--path", "auth/", "https://github.com/o/r/pull/1"},
+		{"--exclude-bots", "https://github.com/o/r/pull/1"},
+		{"--state", "merged", "https://github.com/o/r/pull/1"},
+		{"--since", "2025-01-01", "https://github.com/o/r/pull/1"},
+		{"--org", "myorg", "--days", "30", "--until", "2025-01-01"},
+		{"--from-file", "pr.json", "https://github.com/o/r/pull/1"},
+		{"--from-file", "pr.json", "--org", "myorg"},
+	} {
+		if _, err := parseArgs(args, io.Discard); !errors.Is(err, errUsage) {
+			t.Errorf("parseArgs(%v) error = %v, want errUsage", args, err)
+		}
+	}
+}
+
+func TestParseArgsHelp(t *testing.T) {
+	for _, args := range [][]string{{"-h"}, {"pr", "-h"}, {"org", "--help"}} {
+		if _, err := parseArgs(args, io.Discard); !errors.Is(err, flag.ErrHelp) {
+			t.Errorf("parseArgs(%v) error = %v, want flag.ErrHelp", args, err)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+	"github.com/codeGROOVE-dev/prcost/pkg/github"
+	"github.com/codeGROOVE-dev/prcost/pkg/gitlab"
+)
+
+// validatePRURL checks that a URL looks like a pull request on ctx's GitHub host,
+// or like a GitLab merge request for the gitlab data source.
+func validatePRURL(ctx context.Context, prURL, dataSource string) error {
+	if dataSource == "gitlab" {
+		_, _, _, err := gitlab.ParseMRURL(prURL)
+		return err
+	}
+	host := github.Host(ctx)
+	if !strings.HasPrefix(prURL, "https://"+host+"/") || !strings.Contains(prURL, "/pull/") {
+		return fmt.Errorf("invalid PR URL %q. Expected format: https://%s/owner/repo/pull/123", prURL, host)
+	}
+	return nil
+}
+
+// fetchPR fetches PR data using the configured data source, making GitHub API calls as api configures.
+func fetchPR(ctx context.Context, prURL, token, dataSource string, api *github.APIOptions) (cost.PRData, error) {
+	slog.Info("Fetching PR data", "source", dataSource, "pr_url", prURL)
+	var prData cost.PRData
+	var err error
+	switch dataSource {
+	case "turnserver":
+		// Use turnserver - pass time.Now() since we don't have updatedAt for single PR requests
+		prData, err = github.FetchPRDataViaTurnserver(ctx, prURL, token, time.Now())
+	case "gitlab":
+		prData, err = gitlab.FetchMRData(ctx, prURL, token)
+	default:
+		// Use prx - pass time.Now() since we don't have updatedAt for single PR requests
+		prData, err = (&github.SimpleFetcher{Token: token, API: api}).FetchPRData(ctx, prURL, time.Now())
+	}
+	if err != nil {
+		slog.Error("Failed to fetch PR data", "source", dataSource, "error", err)
+		return cost.PRData{}, fmt.Errorf("failed to fetch PR data: %w", err)
+	}
+	slog.Info("Successfully fetched PR data",
+		"lines_added", prData.LinesAdded,
+		"author", prData.Author,
+		"events", len(prData.Events))
+	return prData, nil
+}
+
+// applyActualHours sets prData's tracked hours from the --actuals file, if it lists prURL.
+func applyActualHours(opts *options, prURL string, prData *cost.PRData) {
+	if hours, ok := opts.actualHours[cost.NormalizePRURL(prURL)]; ok {
+		prData.ActualHours = hours
+	} else if opts.actualHours != nil {
+		slog.Warn("PR not in actuals file, skipping actual vs. estimated comparison", "pr_url", prURL)
+	}
+}
+
+// writeJSON writes v to f's output as indented JSON, with cost fields rounded to f's unit if
+// --round-json is set.
+func writeJSON(f *formatter, v any) error {
+	if f.roundJSON {
+		v = roundedForJSON(v, f.rounding)
+	}
+	encoder := json.NewEncoder(f.w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to output results: %w", err)
+	}
+	return nil
+}
+
+// runPR analyzes a single PR.
+func runPR(ctx context.Context, opts *options, f *formatter, cfg cost.Config, token string) error {
+	prURL := opts.args[0]
+	if err := validatePRURL(ctx, prURL, opts.dataSource); err != nil {
+		return err
+	}
+
+	slog.Info("Starting PR cost analysis", "pr_url", prURL, "format", opts.format)
+
+	prData, err := fetchPR(ctx, prURL, token, opts.dataSource, opts.api)
+	if err != nil {
+		return err
+	}
+	applyActualHours(opts, prURL, &prData)
+
+	// Calculate costs
+	slog.Info("Calculating PR costs")
+	breakdown := cost.Calculate(prData, cfg)
+	slog.Info("Cost calculation complete", "total_cost", breakdown.TotalCost)
+
+	if err := outputBreakdown(&breakdown, prURL, opts, f, cfg); err != nil {
+		return err
+	}
+	return checkGates(&breakdown, opts, f)
+}
+
+// runFromFile analyzes a PR saved as a prx-format JSON dump, without network access.
+func runFromFile(opts *options, f *formatter, cfg cost.Config) error {
+	file, err := os.Open(opts.fromFile)
+	if err != nil {
+		return fmt.Errorf("failed to open PR data: %w", err)
+	}
+	defer func() { _ = file.Close() }() //nolint:errcheck // best effort close
+
+	slog.Info("Starting offline PR cost analysis", "file", opts.fromFile, "format", opts.format)
+
+	prData, err := github.ParsePRXJSON(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", opts.fromFile, err)
+	}
+
+	breakdown := cost.Calculate(prData, cfg)
+	slog.Info("Cost calculation complete", "total_cost", breakdown.TotalCost)
+
+	if err := outputBreakdown(&breakdown, opts.fromFile, opts, f, cfg); err != nil {
+		return err
+	}
+	return checkGates(&breakdown, opts, f)
+}
+
+// outputBreakdown prints a single breakdown in the requested format, or only its total with --quiet.
+func outputBreakdown(breakdown *cost.Breakdown, title string, opts *options, f *formatter, cfg cost.Config) error {
+	if opts.quiet {
+		return outputTotal(f, breakdown.TotalCost, opts.format)
+	}
+	anonymizer(f, []cost.Breakdown{*breakdown}).Breakdown(breakdown)
+	switch format := opts.format; format {
+	case "human":
+		printHumanReadable(f, breakdown, title, cfg, !opts.noCallout)
+		return nil
+	case "json":
+		return writeJSON(f, &breakdownReport{SchemaVersion: cost.SchemaVersion, Breakdown: breakdown})
+	case "csv":
+		return writeBreakdownsCSV(f.w, []string{title}, []cost.Breakdown{*breakdown}, nil)
+	case "markdown":
+		return writeBreakdownMarkdown(f.w, f, breakdown, title)
+	default:
+		return fmt.Errorf("unknown format: %s (must be human, json, csv, or markdown)", format)
+	}
+}
+
+// outputTotal prints only a total cost (--quiet): {"total_cost":...} for JSON, and otherwise a
+// bare number without currency symbol or commas, so scripts can capture it directly. It is
+// rounded to f's --round unit, in JSON only with --round-json.
+func outputTotal(f *formatter, total float64, format string) error {
+	switch format {
+	case "json":
+		if f.roundJSON {
+			total = roundTo(total, f.rounding)
+		}
+		if err := json.NewEncoder(f.w).Encode(struct {
+			TotalCost float64 `json:"total_cost"`
+		}{total}); err != nil {
+			return fmt.Errorf("failed to output results: %w", err)
+		}
+		return nil
+	case "human", "csv", "markdown":
+		_, err := fmt.Fprintln(f.w, formatTotal(total, f.rounding))
+		return err
+	default:
+		return fmt.Errorf("unknown format: %s (must be human, json, csv, or markdown)", format)
+	}
+}
+
+// formatTotal formats a --quiet total to the cent, or rounded to rounding, the --round unit, if set.
+func formatTotal(total, rounding float64) string {
+	if rounding > 0 {
+		return fmt.Sprintf("%.0f", roundTo(total, rounding))
+	}
+	return fmt.Sprintf("%.2f", total)
+}
+
+// runEstimate costs a hypothetical PR described entirely by flags.
+// The PR is modeled as one author commit at creation, each reviewer reviewing
+// halfway through, and a merge after the requested open time.
+func runEstimate(opts *options, f *formatter, cfg cost.Config) error {
+	now := time.Now()
+	created := now.Add(-opts.openTime)
+
+	events := []cost.ParticipantEvent{{Timestamp: created, Actor: "author", Kind: "commit"}}
+	for i := range opts.reviewers {
+		events = append(events, cost.ParticipantEvent{
+			Timestamp: created.Add(opts.openTime / 2),
+			Actor:     fmt.Sprintf("reviewer%d", i+1),
+			Kind:      "review",
+		})
+	}
+
+	breakdown := cost.Calculate(cost.PRData{
+		CreatedAt:    created,
+		ClosedAt:     now,
+		Author:       "author",
+		State:        "MERGED",
+		Merged:       true,
+		Events:       events,
+		LinesAdded:   opts.linesAdded,
+		LinesDeleted: opts.linesDeleted,
+	}, cfg)
+
+	title := fmt.Sprintf("Estimate: +%d/-%d LOC, open %s, %d reviewer(s)",
+		opts.linesAdded, opts.linesDeleted, formatTimeUnit(opts.openTime.Hours()), opts.reviewers)
+	return outputBreakdown(&breakdown, title, opts, f, cfg)
+}
+
+// breakdownReport is the JSON output for a single PR: its breakdown, tagged with the schema version.
+type breakdownReport struct {
+	SchemaVersion string `json:"schema_version"` // cost.SchemaVersion
+
+	*cost.Breakdown
+}
+
+// comparison is the JSON output of the compare command.
+type comparison struct {
+	SchemaVersion string            `json:"schema_version"` // cost.SchemaVersion
+	URLs          [2]string         `json:"urls"`
+	Breakdowns    [2]cost.Breakdown `json:"breakdowns"`
+	Delta         float64           `json:"delta"` // Second PR total cost minus first
+}
+
+// runCompare analyzes two PRs and shows their costs side by side.
+func runCompare(ctx context.Context, opts *options, f *formatter, cfg cost.Config, token string) error {
+	cmp := comparison{SchemaVersion: cost.SchemaVersion}
+	for i, prURL := range opts.args {
+		if err := validatePRURL(ctx, prURL, opts.dataSource); err != nil {
+			return err
+		}
+		prData, err := fetchPR(ctx, prURL, token, opts.dataSource, opts.api)
+		if err != nil {
+			return err
+		}
+		cmp.URLs[i] = prURL
+		cmp.Breakdowns[i] = cost.Calculate(prData, cfg)
+	}
+	cmp.Delta = cmp.Breakdowns[1].TotalCost - cmp.Breakdowns[0].TotalCost
+	names := anonymizer(f, cmp.Breakdowns[:])
+	for i := range cmp.Breakdowns {
+		names.Breakdown(&cmp.Breakdowns[i])
+	}
+
+	switch opts.format {
+	case "human":
+		printComparison(f, &cmp)
+		return nil
+	case "json":
+		return writeJSON(f, &cmp)
+	case "csv":
+		return writeBreakdownsCSV(f.w, cmp.URLs[:], cmp.Breakdowns[:], nil)
+	default:
+		return fmt.Errorf("unknown format: %s (must be human, json, or csv)", opts.format)
+	}
+}
+
+// printComparison prints two breakdowns side by side with the difference.
+func printComparison(f *formatter, cmp *comparison) {
+	a, b := &cmp.Breakdowns[0], &cmp.Breakdowns[1]
+	participantCost := func(bd *cost.Breakdown) float64 {
+		var total float64
+		for _, p := range bd.Participants {
+			total += p.TotalCost
+		}
+		return total
+	}
+	row := func(label string, x, y float64) {
+		fmt.Fprintf(f.w, "    %-22s %s  %s  %s\n", label, f.column(x), f.column(y), formatDelta(f, y-x))
+	}
+
+	fmt.Fprintln(f.w)
+	fmt.Fprintf(f.w, "  A: %s\n", cmp.URLs[0])
+	fmt.Fprintf(f.w, "  B: %s\n", cmp.URLs[1])
+	fmt.Fprintln(f.w)
+	fmt.Fprintf(f.w, "    %-22s %15s  %15s  %s\n", "", "A", "B", "B - A")
+	fmt.Fprintln(f.w, "  ──────────────────────────────────────────────────────────────────────")
+	fmt.Fprintf(f.w, "    %-22s %15s  %15s\n", "Open time", formatTimeUnit(a.PRDuration), formatTimeUnit(b.PRDuration))
+	row("Development", a.Author.TotalCost, b.Author.TotalCost)
+	row("Participants", participantCost(a), participantCost(b))
+	row("Delay", a.DelayCost, b.DelayCost)
+	fmt.Fprintln(f.w, "  ══════════════════════════════════════════════════════════════════════")
+	row("Total", a.TotalCost, b.TotalCost)
+	fmt.Fprintln(f.w)
+}
+
+// formatDelta formats a signed currency difference.
+func formatDelta(f *formatter, delta float64) string {
+	if delta < 0 {
+		return "-" + f.currency(-delta)
+	}
+	return "+" + f.currency(delta)
+}
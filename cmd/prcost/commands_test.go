@@ -0,0 +1,129 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+	"golang.org/x/text/language"
+)
+
+// captureOutput points f's results at a buffer and returns it.
+func captureOutput(f *formatter) *strings.Builder {
+	var out strings.Builder
+	f.w = &out
+	return &out
+}
+
+func TestOutputTotal(t *testing.T) {
+	tests := []struct {
+		format   string
+		rounding float64
+		want     string
+	}{
+		{"human", 0, "1234.56\n"},
+		{"markdown", 0, "1234.56\n"},
+		{"csv", 100, "1200\n"},
+		{"json", 0, "{\"total_cost\":1234.56}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			f := newFormatter(cost.DefaultReportingCurrency, language.MustParse(defaultLocale), tt.rounding)
+			out := captureOutput(f)
+			if err := outputTotal(f, 1234.56, tt.format); err != nil {
+				t.Fatalf("outputTotal() error: %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("outputTotal(%s) printed %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+
+	if err := outputTotal(testFormatter(), 1, "xml"); err == nil {
+		t.Error("outputTotal() with an unknown format succeeded, want error")
+	}
+}
+
+func TestOutputBreakdownZeroCost(t *testing.T) {
+	// A bot PR merged the moment it opened costs nothing; its percentages must not be NaN
+	now := time.Now()
+	cfg := cost.DefaultConfig()
+	cfg.CountBotParticipantCosts = false
+	breakdown := cost.Calculate(cost.PRData{
+		Author:    "dependabot[bot]",
+		AuthorBot: true,
+		CreatedAt: now,
+		ClosedAt:  now,
+		Merged:    true,
+		State:     "MERGED",
+	}, cfg)
+
+	f := testFormatter()
+	out := captureOutput(f)
+	if err := outputBreakdown(&breakdown, "https://github.com/o/r/pull/1", &options{format: "human"}, f, cfg); err != nil {
+		t.Errorf("outputBreakdown() error: %v", err)
+	}
+	if got := out.String(); strings.Contains(got, "NaN") || strings.Contains(got, "Inf") {
+		t.Errorf("zero-cost PR output contains NaN or Inf:\n%s", got)
+	}
+}
+
+func TestPrintExtrapolatedResultsNoPRs(t *testing.T) {
+	var ext cost.ExtrapolatedBreakdown
+	f := testFormatter()
+	out := captureOutput(f)
+	printExtrapolatedResults(f, "empty/repo", 0, 0, &ext, cost.DefaultConfig(), true)
+	if got := out.String(); strings.Contains(got, "NaN") || strings.Contains(got, "Inf") {
+		t.Errorf("output for a period without PRs contains NaN or Inf:\n%s", got)
+	}
+}
+
+func TestOutputBreakdownAnonymized(t *testing.T) {
+	now := time.Now()
+	breakdown := cost.Calculate(cost.PRData{
+		Author:     "alice",
+		CreatedAt:  now.Add(-time.Hour),
+		ClosedAt:   now,
+		Merged:     true,
+		LinesAdded: 40,
+		Events: []cost.ParticipantEvent{
+			{Timestamp: now.Add(-time.Hour), Actor: "alice", Kind: "commit"},
+			{Timestamp: now.Add(-30 * time.Minute), Actor: "bob", Kind: "review"},
+		},
+	}, cost.DefaultConfig())
+
+	f := testFormatter()
+	f.anonymize = true
+	out := captureOutput(f)
+	if err := outputBreakdown(&breakdown, "https://github.com/o/r/pull/1", &options{format: "json"}, f, cost.DefaultConfig()); err != nil {
+		t.Errorf("outputBreakdown() error: %v", err)
+	}
+	got := out.String()
+	if strings.Contains(got, "alice") || strings.Contains(got, "bob") {
+		t.Errorf("anonymized output contains a login:\n%s", got)
+	}
+	if !strings.Contains(got, `"pr_author": "author-1"`) || !strings.Contains(got, `"actor": "reviewer-1"`) {
+		t.Errorf("anonymized output lacks the author-1 and reviewer-1 pseudonyms:\n%s", got)
+	}
+}
+
+func TestPrintExtrapolatedEfficiencyFiscalHeadcount(t *testing.T) {
+	// A year's waste worth four people is about one person for a quarter
+	cfg := cost.DefaultConfig()
+	cfg.FiscalYearStartMonth = 1
+	waste := 4 * cfg.AnnualSalary * cfg.BenefitsMultiplier
+	ext := cost.ExtrapolatedBreakdown{TotalHours: 100000, TotalCost: 10 * waste, DeliveryDelayCost: waste}
+
+	f := testFormatter()
+	out := captureOutput(f)
+	printExtrapolatedEfficiency(f, &ext, 365, cfg, false)
+	got := out.String()
+	quarter, year := cost.ProjectFiscalPeriods(waste, 365, time.Now(), cfg.FiscalYearStartMonth)
+	for _, want := range []string{quarter.Label + ":", "1.0 headcount", year.Label + ":", "4.0 headcount"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("fiscal projection output doesn't contain %q:\n%s", want, got)
+		}
+	}
+}
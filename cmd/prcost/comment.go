@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+	"github.com/codeGROOVE-dev/prcost/pkg/github"
+)
+
+// commentMarker identifies prcost's sticky PR comment so later runs update it instead of adding another.
+const commentMarker = "<!-- prcost:cost-comment -->"
+
+// defaultCommentTemplate renders the cost breakdown as a Markdown PR comment.
+const defaultCommentTemplate = `### PR cost: {{ currency .Breakdown.TotalCost }}
+
+- **Efficiency:** {{ .EfficiencyGrade }} ({{ printf "%.1f" .EfficiencyPct }}%) - {{ .EfficiencyMessage }}
+- **Merge velocity:** {{ .MergeVelocityGrade }} ({{ duration .Breakdown.PRDuration }}) - {{ .MergeVelocityMessage }}
+
+| Component | Cost | Time |
+|---|---:|---:|
+| Development ({{ .Breakdown.PRAuthor }}) | {{ currency .Breakdown.Author.TotalCost }} | {{ duration .Breakdown.Author.TotalHours }} |
+{{- range .Breakdown.Participants }}
+| Participant: {{ .Actor }} | {{ currency .TotalCost }} | {{ duration .TotalHours }} |
+{{- end }}
+| Delay costs | {{ currency .Breakdown.DelayCost }} | {{ duration .Breakdown.DelayCostDetail.TotalDelayHours }} |
+| **Total** | **{{ currency .Breakdown.TotalCost }}** | |
+
+Preventable waste: {{ currency .PreventableCost }} ({{ duration .PreventableHours }})
+`
+
+// commentData is the data available to PR comment templates.
+//
+//nolint:govet // fieldalignment: template fields grouped by purpose for readability
+type commentData struct {
+	URL       string
+	Breakdown *cost.Breakdown
+
+	EfficiencyPct        float64
+	EfficiencyGrade      string
+	EfficiencyMessage    string
+	MergeVelocityGrade   string
+	MergeVelocityMessage string
+	PreventableCost      float64
+	PreventableHours     float64
+}
+
+// parseCommentTemplate parses a PR comment template, providing the currency and duration
+// formatting functions used by the human-readable output.
+func parseCommentTemplate(text string, f *formatter) (*template.Template, error) {
+	tmpl, err := template.New("comment").Funcs(template.FuncMap{
+		"currency": f.currency,
+		"duration": formatTimeUnit,
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid comment template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderComment renders a breakdown with a PR comment template. The marker is always
+// prepended so the comment can be found again, even with a custom template.
+func renderComment(tmpl *template.Template, breakdown *cost.Breakdown, prURL string) (string, error) {
+	data := commentData{
+		URL:                  prURL,
+		Breakdown:            breakdown,
+		EfficiencyPct:        breakdown.EfficiencyPct,
+		EfficiencyGrade:      breakdown.EfficiencyGrade,
+		EfficiencyMessage:    breakdown.EfficiencyMessage,
+		MergeVelocityGrade:   breakdown.MergeVelocityGrade,
+		MergeVelocityMessage: breakdown.MergeVelocityMessage,
+		PreventableCost:      breakdown.PreventableCost(),
+		PreventableHours:     breakdown.PreventableHours(),
+	}
+
+	var sb strings.Builder
+	sb.WriteString(commentMarker + "\n")
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render comment template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// runComment analyzes a single PR and posts the result as a sticky PR comment.
+func runComment(ctx context.Context, opts *options, f *formatter, cfg cost.Config, token string) error {
+	prURL := opts.args[0]
+	if err := validatePRURL(ctx, prURL, opts.dataSource); err != nil {
+		return err
+	}
+
+	text := defaultCommentTemplate
+	if opts.commentTemplate != "" {
+		b, err := os.ReadFile(opts.commentTemplate)
+		if err != nil {
+			return fmt.Errorf("failed to read comment template: %w", err)
+		}
+		text = string(b)
+	}
+	// Reject a broken template before spending API calls on the PR
+	tmpl, err := parseCommentTemplate(text, f)
+	if err != nil {
+		return err
+	}
+
+	prData, err := fetchPR(ctx, prURL, token, opts.dataSource, opts.api)
+	if err != nil {
+		return err
+	}
+	breakdown := cost.Calculate(prData, cfg)
+	slog.Info("Cost calculation complete", "total_cost", breakdown.TotalCost)
+	anonymizer(f, []cost.Breakdown{breakdown}).Breakdown(&breakdown)
+
+	body, err := renderComment(tmpl, &breakdown, prURL)
+	if err != nil {
+		return err
+	}
+
+	commentURL, updated, err := github.UpsertPRComment(ctx, prURL, token, commentMarker, body, opts.api)
+	if err != nil {
+		return fmt.Errorf("failed to post PR comment: %w", err)
+	}
+	verb := "Posted"
+	if updated {
+		verb = "Updated"
+	}
+	fmt.Fprintf(f.w, "%s cost comment: %s\n", verb, commentURL)
+	return nil
+}
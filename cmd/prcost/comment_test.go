@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+func TestRenderComment(t *testing.T) {
+	now := time.Now()
+	breakdown := cost.Calculate(cost.PRData{
+		LinesAdded: 100,
+		Author:     "alice",
+		CreatedAt:  now.Add(-3 * time.Hour),
+		ClosedAt:   now,
+		Events: []cost.ParticipantEvent{
+			{Timestamp: now.Add(-2 * time.Hour), Actor: "alice", Kind: "commit"},
+			{Timestamp: now.Add(-time.Hour), Actor: "bob", Kind: "review"},
+		},
+	}, cost.DefaultConfig())
+
+	f := testFormatter()
+	tmpl, err := parseCommentTemplate(defaultCommentTemplate, f)
+	if err != nil {
+		t.Fatalf("default template: %v", err)
+	}
+	body, err := renderComment(tmpl, &breakdown, "https://github.com/o/r/pull/1")
+	if err != nil {
+		t.Fatalf("renderComment: %v", err)
+	}
+	if !strings.HasPrefix(body, commentMarker+"\n") {
+		t.Errorf("comment does not start with the marker:\n%s", body)
+	}
+	for _, want := range []string{f.currency(breakdown.TotalCost), "Development (alice)", "Participant: bob", "**Efficiency:**"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("comment missing %q:\n%s", want, body)
+		}
+	}
+
+	// Custom templates still carry the marker so the comment can be updated
+	tmpl, err = parseCommentTemplate("{{ .URL }} costs {{ currency .Breakdown.TotalCost }} ({{ .EfficiencyGrade }})", f)
+	if err != nil {
+		t.Fatalf("custom template: %v", err)
+	}
+	body, err = renderComment(tmpl, &breakdown, "https://github.com/o/r/pull/1")
+	if err != nil {
+		t.Fatalf("renderComment: %v", err)
+	}
+	grade, _ := cost.EfficiencyGrade(cost.BreakdownEfficiency(&breakdown))
+	want := commentMarker + "\nhttps://github.com/o/r/pull/1 costs " + f.currency(breakdown.TotalCost) + " (" + grade + ")"
+	if body != want {
+		t.Errorf("custom comment = %q, want %q", body, want)
+	}
+
+	if _, err := parseCommentTemplate("{{ .Missing", f); err == nil {
+		t.Error("expected error for malformed template")
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+// csvHeader lists the columns written by the csv output format.
+var csvHeader = []string{
+	"pr_url",
+	"author",
+	"author_bot",
+	"pr_duration_hours",
+	"author_total_cost",
+	"participant_total_cost",
+	"delay_cost",
+	"total_cost",
+	"efficiency_pct",
+	"velocity_grade",
+}
+
+// formatCSVFloat formats a number for CSV with two decimal places and no separators.
+func formatCSVFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// breakdownCSVRow converts a single PR breakdown into a CSV row.
+func breakdownCSVRow(prURL string, breakdown *cost.Breakdown) []string {
+	var participantCost float64
+	for _, p := range breakdown.Participants {
+		participantCost += p.TotalCost
+	}
+	return []string{
+		prURL,
+		breakdown.PRAuthor,
+		strconv.FormatBool(breakdown.AuthorBot),
+		formatCSVFloat(breakdown.PRDuration),
+		formatCSVFloat(breakdown.Author.TotalCost),
+		formatCSVFloat(participantCost),
+		formatCSVFloat(breakdown.DelayCost),
+		formatCSVFloat(breakdown.TotalCost),
+		formatCSVFloat(breakdown.EfficiencyPct),
+		breakdown.MergeVelocityGrade,
+	}
+}
+
+// extrapolatedCSVRow converts extrapolated totals into an aggregate CSV row.
+// The author columns are left empty and pr_duration_hours is the average across all PRs.
+func extrapolatedCSVRow(label string, ext *cost.ExtrapolatedBreakdown) []string {
+	efficiencyPct := 100.0
+	if ext.TotalHours > 0 {
+		efficiencyPct = 100.0 * (ext.TotalHours - ext.PreventableHours() - ext.AbandonedHours) / ext.TotalHours
+	}
+	return []string{
+		label,
+		"",
+		"",
+		formatCSVFloat(ext.AvgPRDurationHours),
+		formatCSVFloat(ext.AuthorTotalCost),
+		formatCSVFloat(ext.ParticipantTotalCost),
+		formatCSVFloat(ext.DelayTotalCost),
+		formatCSVFloat(ext.TotalCost),
+		formatCSVFloat(efficiencyPct),
+		ext.MergeVelocityGrade,
+	}
+}
+
+// writeBreakdownsCSV writes a header, one row per PR, and an optional aggregate row.
+// urls must be aligned with breakdowns.
+func writeBreakdownsCSV(w io.Writer, urls []string, breakdowns []cost.Breakdown, aggregate []string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for i := range breakdowns {
+		if err := cw.Write(breakdownCSVRow(urls[i], &breakdowns[i])); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	if aggregate != nil {
+		if err := cw.Write(aggregate); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+func TestWriteBreakdownsCSV(t *testing.T) {
+	now := time.Now()
+	cfg := cost.DefaultConfig()
+	breakdown := cost.Calculate(cost.PRData{
+		LinesAdded: 100,
+		Author:     "alice",
+		CreatedAt:  now.Add(-3 * time.Hour),
+		ClosedAt:   now,
+		Events: []cost.ParticipantEvent{
+			{Timestamp: now.Add(-2 * time.Hour), Actor: "alice", Kind: "commit"},
+			{Timestamp: now.Add(-time.Hour), Actor: "bob", Kind: "review"},
+		},
+	}, cfg)
+	ext := cost.ExtrapolateFromSamples([]cost.Breakdown{breakdown}, 10, 3, 0, 30, cfg, nil, nil)
+
+	var buf bytes.Buffer
+	err := writeBreakdownsCSV(&buf, []string{"https://github.com/o/r/pull/1"}, []cost.Breakdown{breakdown},
+		extrapolatedCSVRow("total: o/r", &ext))
+	if err != nil {
+		t.Fatalf("writeBreakdownsCSV() error: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected header, PR row, and aggregate row, got %d rows", len(records))
+	}
+	if !slices.Equal(records[0], csvHeader) {
+		t.Errorf("Header = %v, want %v", records[0], csvHeader)
+	}
+
+	row := records[1]
+	if row[0] != "https://github.com/o/r/pull/1" || row[1] != "alice" || row[2] != "false" {
+		t.Errorf("PR row identity columns = %v", row[:3])
+	}
+	if row[7] != formatCSVFloat(breakdown.TotalCost) {
+		t.Errorf("total_cost = %s, want %s", row[7], formatCSVFloat(breakdown.TotalCost))
+	}
+	if grade, _ := cost.MergeVelocityGrade(breakdown.PRDuration); row[9] != grade {
+		t.Errorf("velocity_grade = %s, want %s", row[9], grade)
+	}
+
+	agg := records[2]
+	if agg[0] != "total: o/r" || agg[1] != "" {
+		t.Errorf("Aggregate row label columns = %v", agg[:2])
+	}
+	if agg[7] != formatCSVFloat(ext.TotalCost) {
+		t.Errorf("Aggregate total_cost = %s, want %s", agg[7], formatCSVFloat(ext.TotalCost))
+	}
+}
+
+func TestWriteBreakdownsCSVWithoutAggregate(t *testing.T) {
+	var buf bytes.Buffer
+	breakdowns := []cost.Breakdown{{PRAuthor: "a"}, {PRAuthor: "b"}}
+	if err := writeBreakdownsCSV(&buf, []string{"u1", "u2"}, breakdowns, nil); err != nil {
+		t.Fatalf("writeBreakdownsCSV() error: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	if len(records) != 3 || records[1][0] != "u1" || records[2][0] != "u2" {
+		t.Errorf("Unexpected rows: %v", records)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+// exitGateFailed is the exit status when a PR fails --fail-under or --fail-over-cost, so CI
+// can tell a policy failure from an error (status 1).
+const exitGateFailed = 2
+
+// errGateFailed is wrapped by checkGates errors.
+var errGateFailed = errors.New("policy gate failed")
+
+// parseEfficiencyGate parses a --fail-under efficiency percentage, such as 80 or 80%.
+func parseEfficiencyGate(value string) (float64, error) {
+	pct, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "%"), 64)
+	if err != nil || pct <= 0 || pct > 100 {
+		return 0, fmt.Errorf("invalid efficiency %q: must be a percentage above 0 and at most 100", value)
+	}
+	return pct, nil
+}
+
+// parseCostGate parses a --fail-over-cost amount in the reporting currency.
+func parseCostGate(value string) (float64, error) {
+	amount, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || amount <= 0 {
+		return 0, fmt.Errorf("invalid cost %q: must be a positive amount", value)
+	}
+	return amount, nil
+}
+
+// checkGates returns an error wrapping errGateFailed if breakdown's efficiency is below
+// --fail-under or its total cost is above --fail-over-cost. Both gates are off when unset.
+func checkGates(breakdown *cost.Breakdown, o *options, f *formatter) error {
+	var failures []string
+	if o.failUnder > 0 && breakdown.EfficiencyPct < o.failUnder {
+		failures = append(failures, fmt.Sprintf("efficiency %.1f%% (grade %s) is below --fail-under %g%%",
+			breakdown.EfficiencyPct, breakdown.EfficiencyGrade, o.failUnder))
+	}
+	if o.failOverCost > 0 && breakdown.TotalCost > o.failOverCost {
+		failures = append(failures, fmt.Sprintf("total cost %s is above --fail-over-cost %s",
+			f.currency(breakdown.TotalCost), f.currency(o.failOverCost)))
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", errGateFailed, strings.Join(failures, "; "))
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+func TestCheckGates(t *testing.T) {
+	breakdown := &cost.Breakdown{EfficiencyPct: 75, EfficiencyGrade: "C", TotalCost: 1200}
+
+	tests := []struct {
+		name         string
+		failUnder    float64
+		failOverCost float64
+		want         []string // Substrings of the error; nil when the gates pass
+	}{
+		{"gates off", 0, 0, nil},
+		{"efficiency at threshold passes", 75, 0, nil},
+		{"efficiency below threshold", 80, 0, []string{"efficiency 75.0% (grade C) is below --fail-under 80%"}},
+		{"cost under limit passes", 0, 1500, nil},
+		{"cost over limit", 0, 1000, []string{"total cost $1,200.00 is above --fail-over-cost $1,000.00"}},
+		{"both fail", 80, 1000, []string{"--fail-under", "--fail-over-cost"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkGates(breakdown, &options{failUnder: tt.failUnder, failOverCost: tt.failOverCost}, testFormatter())
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("checkGates() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, errGateFailed) {
+				t.Fatalf("checkGates() = %v, want errGateFailed", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("checkGates() = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
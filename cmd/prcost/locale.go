@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// defaultLocale is the --locale used unless another is given.
+const defaultLocale = "en-US"
+
+// currencySymbols maps reporting currencies to the symbol shown before amounts. Other
+// currencies are shown by their code.
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"CNY": "¥",
+	"INR": "₹",
+	"KRW": "₩",
+	"BRL": "R$",
+	"CAD": "CA$",
+	"AUD": "A$",
+}
+
+// ledgerWidth is the width of the amount column in the human-readable ledgers.
+const ledgerWidth = 15
+
+// parseLocale returns the language tag for a --locale value such as en-US, de-DE or fr_FR.
+func parseLocale(value string) (language.Tag, error) {
+	tag, err := language.Parse(strings.ReplaceAll(strings.TrimSpace(value), "_", "-"))
+	if err != nil {
+		return language.Und, fmt.Errorf("unsupported locale %q: use a tag such as en-US, de-DE or fr-FR", value)
+	}
+	return tag, nil
+}
+
+// symbolFor returns the symbol shown before amounts in currency: "$" for USD, "€" for EUR,
+// and the code followed by a space for currencies without a well-known symbol.
+func symbolFor(currency string) string {
+	currency = strings.ToUpper(currency)
+	if symbol, ok := currencySymbols[currency]; ok {
+		return symbol
+	}
+	return currency + " "
+}
+
+// formatter writes results and formats their amounts for human-readable output: in the
+// reporting currency, with the thousands separators and decimal mark of --locale (following
+// CLDR), rounded as --round says.
+type formatter struct {
+	w         io.Writer // Where results are printed: stdout, or the --output file
+	toFile    bool      // Results go to a file (--output)
+	roundJSON bool      // Round cost fields in JSON output too (--round-json)
+	anonymize bool      // Show pseudonyms such as author-1 instead of logins (--anonymize)
+	printer   *message.Printer
+	symbol    string  // Shown before amounts; see symbolFor
+	rounding  float64 // Unit amounts are rounded to (0 = cents)
+}
+
+// newFormatter returns a formatter printing to stdout, for amounts in currency, written as in
+// locale and rounded to rounding.
+func newFormatter(currency string, locale language.Tag, rounding float64) *formatter {
+	return &formatter{
+		w:        os.Stdout,
+		printer:  message.NewPrinter(locale),
+		symbol:   symbolFor(currency),
+		rounding: rounding,
+	}
+}
+
+// number formats amount with thousands separators, to the cent or in whole units when rounding.
+func (f *formatter) number(amount float64) string {
+	if f.rounding > 0 {
+		return f.printer.Sprint(number.Decimal(roundTo(amount, f.rounding), number.Scale(0)))
+	}
+	return f.printer.Sprint(number.Decimal(amount, number.Scale(2)))
+}
+
+// currency formats amount with the currency symbol and the locale's separators.
+func (f *formatter) currency(amount float64) string {
+	return f.symbol + f.number(amount)
+}
+
+// column formats amount for a ledger's amount column: the currency symbol at the left edge
+// and the number right-aligned, so columns line up whatever the symbol's length.
+func (f *formatter) column(amount float64) string {
+	return fmt.Sprintf("%s%*s", f.symbol, max(ledgerWidth-utf8.RuneCountInString(f.symbol), 0), f.number(amount))
+}
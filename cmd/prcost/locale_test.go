@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+	"golang.org/x/text/language"
+)
+
+// testFormatter returns the formatter for the default --currency, --locale and --round.
+func testFormatter() *formatter {
+	return newFormatter(cost.DefaultReportingCurrency, language.MustParse(defaultLocale), 0)
+}
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"en-US", "en-US"},
+		{"de-DE", "de-DE"},
+		{"de_AT", "de-AT"},
+		{"FR", "fr"},
+	}
+	for _, tt := range tests {
+		got, err := parseLocale(tt.value)
+		if err != nil {
+			t.Errorf("parseLocale(%q) error: %v", tt.value, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("parseLocale(%q) = %v, want %s", tt.value, got, tt.want)
+		}
+	}
+	if _, err := parseLocale("tlh-KX"); err == nil {
+		t.Error("parseLocale(tlh-KX) succeeded, want an error")
+	}
+}
+
+func TestSymbolFor(t *testing.T) {
+	for currency, want := range map[string]string{"USD": "$", "eur": "€", "GBP": "£", "CHF": "CHF "} {
+		if got := symbolFor(currency); got != want {
+			t.Errorf("symbolFor(%q) = %q, want %q", currency, got, want)
+		}
+	}
+}
+
+func TestFormatterLocale(t *testing.T) {
+	tests := []struct {
+		currency, locale string
+		rounding         float64
+		amount           float64
+		want             string
+	}{
+		{"USD", "en-US", 0, 1234567.891, "$1,234,567.89"},
+		{"EUR", "de-DE", 0, 1234.56, "€1.234,56"},
+		{"EUR", "de-DE", 1, 1234.56, "€1.235"},
+		{"CHF", "de-CH", 0, 98765.4, "CHF 98’765.40"},
+		{"EUR", "fr-FR", 0, 4200, "€4 200,00"},
+	}
+	for _, tt := range tests {
+		f := newFormatter(tt.currency, language.MustParse(tt.locale), tt.rounding)
+		if got := f.currency(tt.amount); got != tt.want {
+			t.Errorf("currency(%v) in %s %s = %q, want %q", tt.amount, tt.currency, tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestLedgerColumnsAlign(t *testing.T) {
+	// Amounts line up whatever the length of the currency symbol and the separators
+	for _, currency := range []string{"USD", "CHF", "BRL"} {
+		for _, locale := range []string{"en-US", "de-CH", "fr-FR"} {
+			f := newFormatter(currency, language.MustParse(locale), 0)
+			for _, amount := range []float64{0.5, 4321, 98765.4} {
+				if got := f.column(amount); utf8.RuneCountInString(got) != ledgerWidth {
+					t.Errorf("column(%v) in %s %s = %q, want %d characters", amount, currency, locale, got, ledgerWidth)
+				}
+			}
+		}
+	}
+
+	f := newFormatter("CHF", language.MustParse("de-CH"), 0)
+	if got, want := formatSummaryLine(f, "Total", 98765.4, "3.2d", ""), "  Total                          CHF   98’765.40    3.2d    \n"; got != want {
+		t.Errorf("formatSummaryLine() in de-CH = %q, want %q", got, want)
+	}
+}
@@ -3,14 +3,15 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -20,52 +21,19 @@ import (
 )
 
 func main() {
-	// Define command-line flags
-	salary := flag.Float64("salary", 249000, "Annual salary for cost calculation")
-	benefits := flag.Float64("benefits", 1.3, "Benefits multiplier (1.3 = 30% benefits)")
-	eventMinutes := flag.Float64("event-minutes", 10, "Minutes per GitHub event (commits, comments, etc.)")
-	format := flag.String("format", "human", "Output format: human or json")
-	verbose := flag.Bool("verbose", false, "Show verbose logging output")
-	dataSource := flag.String("data-source", "prx", "Data source for PR data: prx (direct GitHub API) or turnserver")
-
-	// Org/Repo sampling flags
-	org := flag.String("org", "", "GitHub organization to analyze (optionally with --repo for single repo)")
-	repo := flag.String("repo", "", "GitHub repository to analyze (requires --org)")
-	samples := flag.Int("samples", 50, "Number of PRs to sample for extrapolation (30=fast/±18%, 50=slower/±14%)")
-	days := flag.Int("days", 60, "Number of days to look back for PR modifications")
-
-	// Modeling flags
-	targetMergeTime := flag.Duration("target-merge-time", 90*time.Minute,
-		"Target merge time for efficiency modeling (default: 90 minutes / 1.5 hours)")
-
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [options] <PR_URL>\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "       %s --org <org> [--repo <repo>] [options]\n\n", os.Args[0])
-		fmt.Fprint(os.Stderr, "Calculate the real-world cost of GitHub pull requests.\n\n")
-		fmt.Fprint(os.Stderr, "Modes:\n")
-		fmt.Fprint(os.Stderr, "  Single PR:   Provide a PR URL as argument\n")
-		fmt.Fprint(os.Stderr, "  Single Repo: Use --org and --repo to analyze one repository\n")
-		fmt.Fprint(os.Stderr, "  Org-wide:    Use --org to analyze entire organization\n\n")
-		fmt.Fprint(os.Stderr, "Options:\n")
-		flag.PrintDefaults()
-		fmt.Fprint(os.Stderr, "\nExamples:\n")
-		fmt.Fprint(os.Stderr, "  Single PR:\n")
-		fmt.Fprintf(os.Stderr, "    %s https://github.com/owner/repo/pull/123\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "    %s --salary 300000 https://github.com/owner/repo/pull/123\n\n", os.Args[0])
-		fmt.Fprint(os.Stderr, "  Repository analysis:\n")
-		fmt.Fprintf(os.Stderr, "    %s --org kubernetes --repo kubernetes\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "    %s --org myorg --repo myrepo --samples 50 --days 30\n\n", os.Args[0])
-		fmt.Fprint(os.Stderr, "  Organization-wide analysis:\n")
-		fmt.Fprintf(os.Stderr, "    %s --org chainguard-dev\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "    %s --org myorg --samples 50 --days 60\n", os.Args[0])
-	}
-
-	flag.Parse()
+	opts, err := parseArgs(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		// Usage and the error have already been printed by the parser
+		os.Exit(1)
+	}
 
 	// Setup structured logging to stderr (stdout is for results)
 	// Only show errors by default, show info/debug with --verbose
 	logLevel := slog.LevelError
-	if *verbose {
+	if opts.verbose {
 		logLevel = slog.LevelInfo
 	}
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
@@ -73,132 +41,227 @@ func main() {
 	}))
 	slog.SetDefault(logger)
 
-	// Determine mode: single PR or org/repo sampling
-	orgMode := *org != ""
-	singlePRMode := flag.NArg() == 1
+	// Create cost configuration from flags
+	cfg := opts.config()
 
-	// Validate mode selection
-	// First check if --repo is specified without --org
-	if *repo != "" && *org == "" {
-		fmt.Fprint(os.Stderr, "Error: --repo requires --org to be specified\n\n")
-		flag.Usage()
-		os.Exit(1)
+	// Per-author salaries; only the entry count is logged since compensation is sensitive
+	if opts.compFile != "" {
+		salaries, currencies, err := loadCompensationFile(opts.compFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.SalaryOverrides = salaries
+		cfg.SalaryCurrencies = currencies
+		slog.Info("Loaded compensation file", "entries", len(salaries), "non_reporting_currency_entries", len(currencies))
 	}
-
-	if orgMode && singlePRMode {
-		fmt.Fprint(os.Stderr, "Error: Cannot use both --org and PR URL. Choose one mode.\n\n")
-		flag.Usage()
-		os.Exit(1)
+	if opts.actualsFile != "" {
+		actuals, err := loadActualHoursFile(opts.actualsFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts.actualHours = actuals
+		slog.Info("Loaded actuals file", "prs", len(actuals))
 	}
-
-	if !orgMode && !singlePRMode {
-		flag.Usage()
+	if err := cost.ValidateCurrencies(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v (pass --exchange-rate CUR=rate)\n", err)
 		os.Exit(1)
 	}
 
-	// Create cost configuration from flags
-	cfg := cost.DefaultConfig()
-	cfg.AnnualSalary = *salary
-	cfg.BenefitsMultiplier = *benefits
-	cfg.EventDuration = time.Duration(*eventMinutes) * time.Minute
-	cfg.TargetMergeTimeHours = targetMergeTime.Hours()
-
 	slog.Debug("Configuration",
+		"command", opts.command,
+		"profile", opts.profile,
 		"salary", cfg.AnnualSalary,
 		"benefits_multiplier", cfg.BenefitsMultiplier,
-		"event_minutes", *eventMinutes,
+		"event_minutes", opts.eventMinutes,
 		"target_merge_time_hours", cfg.TargetMergeTimeHours,
+		"min_delay_minutes", cfg.MinDelayThresholdMinutes,
+		"max_project_delay", cfg.MaxProjectDelay,
+		"max_code_drift", cfg.MaxCodeDrift,
+		"fiscal_year_start_month", cfg.FiscalYearStartMonth,
+		"reporting_currency", cfg.ReportingCurrency,
 		"delivery_delay_factor", cfg.DeliveryDelayFactor)
 
-	// Retrieve GitHub token from gh CLI
-	ctx := context.Background()
-	token, err := authToken(ctx)
-	if err != nil {
-		slog.Error("Failed to get GitHub token", "error", err)
-		log.Fatalf("Failed to get GitHub token: %v\nPlease ensure 'gh' is installed and authenticated (run 'gh auth login')", err)
+	// Parse what-if scenarios (applied on top of the flag-derived configuration)
+	scenarios := make([]cost.Scenario, 0, len(opts.scenarios))
+	for _, spec := range opts.scenarios {
+		sc, err := parseScenario(spec, cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		scenarios = append(scenarios, sc)
 	}
-	slog.Debug("Successfully retrieved GitHub token")
 
-	// Execute based on mode
-	if orgMode {
-		// Org/Repo sampling mode
-		if *repo != "" {
-			// Single repository mode
+	f := newFormatter(cfg.ReportingCurrency, opts.locale, opts.roundUnit)
+	f.roundJSON, f.anonymize = opts.roundJSON, opts.anonymize
 
-			err := analyzeRepository(ctx, *org, *repo, *samples, *days, cfg, token, *dataSource)
-			if err != nil {
-				log.Fatalf("Repository analysis failed: %v", err)
-			}
-		} else {
-			// Organization-wide mode
-			slog.Info("Starting organization-wide analysis",
-				"org", *org,
-				"samples", *samples,
-				"days", *days)
-
-			err := analyzeOrganization(ctx, *org, *samples, *days, cfg, token, *dataSource)
-			if err != nil {
-				log.Fatalf("Organization analysis failed: %v", err)
-			}
+	// A failed --fail-under or --fail-over-cost gate sets a nonzero exit status, applied once
+	// the results are written
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
 		}
-	} else {
-		// Single PR mode
-		prURL := flag.Arg(0)
+	}()
 
-		// Validate PR URL format
-		if !strings.HasPrefix(prURL, "https://github.com/") || !strings.Contains(prURL, "/pull/") {
-			log.Fatal("Invalid PR URL. Expected format: https://github.com/owner/repo/pull/123")
+	if opts.output != "" {
+		closeOutput, err := redirectOutput(f, opts.output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
+		defer func() {
+			closeOutput()
+			if !opts.quiet {
+				fmt.Fprintf(os.Stderr, "Wrote results to %s\n", opts.output)
+			}
+		}()
+	}
 
-		slog.Info("Starting PR cost analysis", "pr_url", prURL, "format", *format)
+	// Estimates don't touch GitHub
+	if opts.command == cmdEstimate {
+		if err := runEstimate(opts, f, cfg); err != nil {
+			log.Fatalf("Estimate failed: %v", err)
+		}
+		return
+	}
 
-		// Fetch PR data using configured data source
-		slog.Info("Fetching PR data", "source", *dataSource)
-		var prData cost.PRData
-		var err error
-		if *dataSource == "turnserver" {
-			// Use turnserver - pass time.Now() since we don't have updatedAt for single PR requests
-			prData, err = github.FetchPRDataViaTurnserver(ctx, prURL, token, time.Now())
-		} else {
-			// Use prx - pass time.Now() since we don't have updatedAt for single PR requests
-			prData, err = github.FetchPRData(ctx, prURL, token, time.Now())
+	// Saved PR data is costed offline, without a host or token
+	if opts.fromFile != "" {
+		switch err := runFromFile(opts, f, cfg); {
+		case errors.Is(err, errGateFailed):
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exitCode = exitGateFailed
+		case err != nil:
+			log.Fatalf("PR analysis failed: %v", err)
+		default:
 		}
+		return
+	}
+
+	// Point PR URLs and API calls at GitHub Enterprise Server if requested
+	githubHost := opts.githubHost
+	if githubHost == "" {
+		githubHost = os.Getenv("GITHUB_HOST")
+	}
+	githubHost, err = github.ParseHost(githubHost)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if opts.maxRetries < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --max-retries must not be negative")
+		os.Exit(1)
+	}
+	opts.api = &github.APIOptions{MaxRetries: opts.maxRetries}
+	if opts.githubRate > 0 {
+		opts.api.RateBudget = github.NewRateBudget(opts.githubRate)
+	}
+
+	// Retrieve GitHub token from gh CLI; GitLab merge requests use $GITLAB_TOKEN instead,
+	// which may be empty for public projects
+	ctx := github.WithHost(context.Background(), githubHost)
+	// With --verbose, report the GitHub API quota left, so large scans can be scheduled
+	rateLimits := github.NewRateLimitTracker()
+	ctx = github.WithRateLimitTracker(ctx, rateLimits)
+	defer func() {
+		if limit := rateLimits.Lowest(); limit != nil {
+			slog.Info("GitHub API rate limit", "resource", limit.Resource, "remaining", limit.Remaining,
+				"limit", limit.Limit, "resets_at", limit.Reset.Local().Format("15:04"))
+		}
+	}()
+	token := os.Getenv("GITLAB_TOKEN")
+	if opts.dataSource != "gitlab" {
+		token, err = authToken(ctx, githubHost)
 		if err != nil {
-			slog.Error("Failed to fetch PR data", "source", *dataSource, "error", err)
-			log.Fatalf("Failed to fetch PR data: %v", err)
+			slog.Error("Failed to get GitHub token", "error", err)
+			log.Fatalf("Failed to get GitHub token: %v\nPlease ensure 'gh' is installed and authenticated (run 'gh auth login')", err)
 		}
-		slog.Info("Successfully fetched PR data",
-			"lines_added", prData.LinesAdded,
-			"author", prData.Author,
-			"events", len(prData.Events))
-
-		// Calculate costs
-		slog.Info("Calculating PR costs")
-		breakdown := cost.Calculate(prData, cfg)
-		slog.Info("Cost calculation complete", "total_cost", breakdown.TotalCost)
-
-		// Output in requested format
-		switch *format {
-		case "human":
-			printHumanReadable(&breakdown, prURL, cfg)
-		case "json":
-			encoder := json.NewEncoder(os.Stdout)
-			encoder.SetIndent("", "  ")
-			if err := encoder.Encode(&breakdown); err != nil {
-				log.Fatalf("Failed to output results: %v", err)
-			}
+		slog.Debug("Successfully retrieved GitHub token")
+	}
+
+	// Execute based on command
+	analysis := analysisOptions{
+		sampleSize:     opts.samples,
+		sampling:       opts.sampling,
+		window:         newAnalysisWindow(opts),
+		concurrency:    opts.concurrency,
+		cfg:            cfg,
+		scenarios:      scenarios,
+		filter:         opts.filter,
+		token:          token,
+		api:            opts.api,
+		dataSource:     opts.dataSource,
+		format:         opts.format,
+		formatter:      f,
+		checkpointPath: opts.checkpoint,
+		dryRun:         opts.dryRun,
+		callout:        !opts.noCallout,
+		includeSamples: opts.inclSamples,
+	}
+	switch opts.command {
+	case cmdRepo:
+		err := analyzeRepository(ctx, opts.org, opts.repo, analysis)
+		if err != nil {
+			log.Fatalf("Repository analysis failed: %v", err)
+		}
+	case cmdOrg:
+		slog.Info("Starting organization-wide analysis",
+			"org", opts.org,
+			"samples", opts.samples,
+			"days", opts.days)
+
+		err := analyzeOrganization(ctx, opts.org, analysis)
+		if err != nil {
+			log.Fatalf("Organization analysis failed: %v", err)
+		}
+	case cmdCompare:
+		if err := runCompare(ctx, opts, f, cfg, token); err != nil {
+			log.Fatalf("Comparison failed: %v", err)
+		}
+	case cmdComment:
+		if err := runComment(ctx, opts, f, cfg, token); err != nil {
+			log.Fatalf("PR comment failed: %v", err)
+		}
+	default:
+		switch err := runPR(ctx, opts, f, cfg, token); {
+		case errors.Is(err, errGateFailed):
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exitCode = exitGateFailed
+		case err != nil:
+			log.Fatalf("PR analysis failed: %v", err)
 		default:
-			log.Fatalf("Unknown format: %s (must be human or json)", *format)
 		}
 	}
 }
 
-// authToken retrieves a GitHub token using the gh CLI.
-func authToken(ctx context.Context) (string, error) {
+// redirectOutput points f, which prints results, at the named file, creating or truncating it,
+// so the file holds exactly the formatted results. The returned function points f back at its
+// previous output and closes the file.
+func redirectOutput(f *formatter, path string) (func(), error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating output file: %w", err)
+	}
+	w, toFile := f.w, f.toFile
+	f.w, f.toFile = file, true
+	return func() {
+		f.w, f.toFile = w, toFile
+		if err := file.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: writing %s: %v\n", path, err)
+			os.Exit(1)
+		}
+	}, nil
+}
+
+// authToken retrieves a GitHub token for host using the gh CLI.
+func authToken(ctx context.Context, host string) (string, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "gh", "auth", "token")
+	cmd := exec.CommandContext(ctx, "gh", "auth", "token", "--hostname", host)
 	output, err := cmd.Output()
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
@@ -212,53 +275,68 @@ func authToken(ctx context.Context) (string, error) {
 }
 
 // printHumanReadable outputs a detailed itemized bill in human-readable format.
-func printHumanReadable(breakdown *cost.Breakdown, prURL string, cfg cost.Config) {
-	// Helper to format currency with commas
-	formatCurrency := func(amount float64) string {
-		return fmt.Sprintf("$%s", formatWithCommas(amount))
-	}
-
+// The merge time modeling callout is printed only when callout is set.
+func printHumanReadable(f *formatter, breakdown *cost.Breakdown, prURL string, cfg cost.Config, callout bool) {
 	// Header with PR info
-	fmt.Println()
-	fmt.Printf("  %s\n", prURL)
+	fmt.Fprintln(f.w)
+	fmt.Fprintf(f.w, "  %s\n", prURL)
 
 	// Show author and duration
 	authorLabel := breakdown.PRAuthor
 	if breakdown.AuthorBot {
 		authorLabel += " (bot)"
 	}
-	fmt.Printf("  Author: %s  •  Open: %s\n", authorLabel, formatTimeUnit(breakdown.PRDuration))
-	fmt.Printf("  Rate: %s/hr  •  Benefits multiplier: %.1fx\n",
-		formatCurrency(breakdown.HourlyRate),
+	fmt.Fprintf(f.w, "  Author: %s  •  Open: %s  •  %s\n", authorLabel, formatTimeUnit(breakdown.PRDuration),
+		formatLineChanges(breakdown.Author.LinesAdded, breakdown.Author.LinesDeleted))
+	fmt.Fprintf(f.w, "  Rate: %s/hr  •  Benefits multiplier: %.1fx\n",
+		f.currency(breakdown.HourlyRate),
 		breakdown.BenefitsMultiplier)
-	fmt.Println()
+	fmt.Fprintln(f.w)
 
 	// Author Costs (skip entire section if no costs)
 	if breakdown.Author.TotalCost > 0 {
-		fmt.Println("  Development Costs")
-		fmt.Println("  ─────────────────")
+		fmt.Fprintln(f.w, "  Development Costs")
+		fmt.Fprintln(f.w, "  ─────────────────")
 		// Show development and adaptation separately (only if there are actual lines of code)
 		if breakdown.Author.NewLines > 0 {
-			fmt.Printf("    New Development           %12s    %d LOC • %s\n",
-				formatCurrency(breakdown.Author.NewCodeCost), breakdown.Author.NewLines, formatTimeUnit(breakdown.Author.NewCodeHours))
+			fmt.Fprintf(f.w, "    New Development           %12s    %d LOC • %s%s\n",
+				f.currency(breakdown.Author.NewCodeCost), breakdown.Author.NewLines, formatTimeUnit(breakdown.Author.NewCodeHours),
+				capSuffix(breakdown.CapAppliedTo.CodeCost))
+		}
+		if breakdown.Author.GeneratedLines > 0 {
+			fmt.Fprintf(f.w, "    Generated/Vendored        %12s    %d LOC excluded\n", "—", breakdown.Author.GeneratedLines)
 		}
 		if breakdown.Author.ModifiedLines > 0 {
-			fmt.Printf("    Adaptation                %12s    %d LOC • %s\n",
-				formatCurrency(breakdown.Author.AdaptationCost), breakdown.Author.ModifiedLines, formatTimeUnit(breakdown.Author.AdaptationHours))
+			fmt.Fprintf(f.w, "    Adaptation                %12s    %d LOC • %s%s\n",
+				f.currency(breakdown.Author.AdaptationCost), breakdown.Author.ModifiedLines, formatTimeUnit(breakdown.Author.AdaptationHours),
+				capSuffix(breakdown.CapAppliedTo.CodeCost))
 		}
 		if breakdown.Author.GitHubHours > 0 {
-			fmt.Printf("    GitHub Activity           %12s    %d sessions • %s\n",
-				formatCurrency(breakdown.Author.GitHubCost), breakdown.Author.Sessions, formatTimeUnit(breakdown.Author.GitHubHours))
+			fmt.Fprintf(f.w, "    GitHub Activity           %12s    %d sessions • %s\n",
+				f.currency(breakdown.Author.GitHubCost), breakdown.Author.Sessions, formatTimeUnit(breakdown.Author.GitHubHours))
 		}
 		if breakdown.Author.GitHubContextHours > 0 {
-			fmt.Printf("    GitHub Context Switching  %12s    %s\n",
-				formatCurrency(breakdown.Author.GitHubContextCost), formatTimeUnit(breakdown.Author.GitHubContextHours))
+			fmt.Fprintf(f.w, "    GitHub Context Switching  %12s    %s\n",
+				f.currency(breakdown.Author.GitHubContextCost), formatTimeUnit(breakdown.Author.GitHubContextHours))
+		}
+		if breakdown.Author.ConflictResolutions > 0 {
+			fmt.Fprintf(f.w, "    Conflict Resolution       %12s    %d conflicts • %s\n",
+				f.currency(breakdown.Author.ConflictResolutionCost), breakdown.Author.ConflictResolutions,
+				formatTimeUnit(breakdown.Author.ConflictResolutionHours))
+		}
+		fmt.Fprintln(f.w, "                              ────────────")
+		pct := percentOf(breakdown.Author.TotalCost, breakdown.TotalCost)
+		fmt.Fprintf(f.w, "    Subtotal                  %12s    %s  (%.1f%%)\n",
+			f.currency(breakdown.Author.TotalCost), formatTimeUnit(breakdown.Author.TotalHours), pct)
+		if breakdown.AbandonedCost > 0 {
+			fmt.Fprintf(f.w, "      Closed without merging: %s of code (%s) abandoned\n",
+				f.currency(breakdown.AbandonedCost), formatTimeUnit(breakdown.AbandonedHours))
+		}
+		if breakdown.IsRevert {
+			fmt.Fprintf(f.w, "      Revert: %s (%s) spent undoing earlier work\n",
+				f.currency(breakdown.RevertCost), formatTimeUnit(breakdown.RevertHours))
 		}
-		fmt.Println("                              ────────────")
-		pct := (breakdown.Author.TotalCost / breakdown.TotalCost) * 100
-		fmt.Printf("    Subtotal                  %12s    %s  (%.1f%%)\n",
-			formatCurrency(breakdown.Author.TotalCost), formatTimeUnit(breakdown.Author.TotalHours), pct)
-		fmt.Println()
+		fmt.Fprintln(f.w)
 	}
 
 	// Participant Costs
@@ -271,36 +349,51 @@ func printHumanReadable(breakdown *cost.Breakdown, prURL string, cfg cost.Config
 			totalParticipantHours += p.TotalHours
 		}
 
-		fmt.Println("  Participant Costs")
-		fmt.Println("  ─────────────────")
+		fmt.Fprintln(f.w, "  Participant Costs")
+		fmt.Fprintln(f.w, "  ─────────────────")
 		for _, p := range breakdown.Participants {
-			fmt.Printf("    %s\n", p.Actor)
+			fmt.Fprintf(f.w, "    %s\n", p.Actor)
+			// Only show co-authored development if they co-authored commits
+			if p.CoAuthoredHours > 0 {
+				fmt.Fprintf(f.w, "      Co-authored Code        %12s    %s\n",
+					f.currency(p.CoAuthoredCost), formatTimeUnit(p.CoAuthoredHours))
+			}
 			// Only show review activity if they reviewed (LOC-based)
-			if p.ReviewHours > 0 {
-				fmt.Printf("      Review Activity         %12s    %s\n",
-					formatCurrency(p.ReviewCost), formatTimeUnit(p.ReviewHours))
+			switch {
+			case p.ReviewHours > 0 && p.ReviewRounds > 1:
+				fmt.Fprintf(f.w, "      Review Activity         %12s    %d rounds • %s\n",
+					f.currency(p.ReviewCost), p.ReviewRounds, formatTimeUnit(p.ReviewHours))
+			case p.ReviewHours > 0:
+				fmt.Fprintf(f.w, "      Review Activity         %12s    %s\n",
+					f.currency(p.ReviewCost), formatTimeUnit(p.ReviewHours))
+			default:
 			}
 			// Only show other events if they had non-review events
 			if p.GitHubHours > 0 {
-				fmt.Printf("      GitHub Activity         %12s    %d sessions • %s\n",
-					formatCurrency(p.GitHubCost), p.Sessions, formatTimeUnit(p.GitHubHours))
+				fmt.Fprintf(f.w, "      GitHub Activity         %12s    %d sessions • %s\n",
+					f.currency(p.GitHubCost), p.Sessions, formatTimeUnit(p.GitHubHours))
 			}
 			// Always show context switching if there were sessions
 			if p.Sessions > 0 {
-				fmt.Printf("      Context Switching       %12s    %s\n",
-					formatCurrency(p.GitHubContextCost), formatTimeUnit(p.GitHubContextHours))
+				fmt.Fprintf(f.w, "      Context Switching       %12s    %s\n",
+					f.currency(p.GitHubContextCost), formatTimeUnit(p.GitHubContextHours))
+			}
+			// Only show review wait if the author waited on them after requesting a review
+			if p.ReviewWaitHours > 0 {
+				fmt.Fprintf(f.w, "      Review Wait             %12s    %s waiting • %s\n",
+					f.currency(p.ReviewWaitCost), formatTimeUnit(p.ReviewLatencyHours), formatTimeUnit(p.ReviewWaitHours))
 			}
 		}
-		fmt.Println("                              ────────────")
-		pct := (totalParticipantCost / breakdown.TotalCost) * 100
-		fmt.Printf("    Subtotal                  %12s    %s  (%.1f%%)\n",
-			formatCurrency(totalParticipantCost), formatTimeUnit(totalParticipantHours), pct)
-		fmt.Println()
+		fmt.Fprintln(f.w, "                              ────────────")
+		pct := percentOf(totalParticipantCost, breakdown.TotalCost)
+		fmt.Fprintf(f.w, "    Subtotal                  %12s    %s  (%.1f%%)\n",
+			f.currency(totalParticipantCost), formatTimeUnit(totalParticipantHours), pct)
+		fmt.Fprintln(f.w)
 	}
 
 	// Delay and Future Costs - only show if there are any delay costs
 	if breakdown.DelayCost > 0 {
-		printDelayCosts(breakdown, formatCurrency)
+		printDelayCosts(f, breakdown)
 	}
 
 	// Grand Total
@@ -308,35 +401,61 @@ func printHumanReadable(breakdown *cost.Breakdown, prURL string, cfg cost.Config
 	for _, p := range breakdown.Participants {
 		totalHours += p.TotalHours
 	}
-	fmt.Println("  ═══════════════════════════════════════════════════════════════")
-	fmt.Printf("  Total                       %12s    %s\n",
-		formatCurrency(breakdown.TotalCost), formatTimeUnit(totalHours))
-	fmt.Println()
+	fmt.Fprintln(f.w, "  ═══════════════════════════════════════════════════════════════")
+	fmt.Fprintf(f.w, "  Total                       %12s    %s\n",
+		f.currency(breakdown.TotalCost), formatTimeUnit(totalHours))
+	if breakdown.CostPerLOC > 0 {
+		fmt.Fprintf(f.w, "  Per line of code            %12s    (%d lines added)\n",
+			f.currency(breakdown.CostPerLOC), breakdown.Author.LinesAdded)
+	}
+	fmt.Fprintln(f.w)
+
+	// Compare with tracked time from --actuals
+	if breakdown.Variance != nil {
+		printVariance(f.w, breakdown.Variance)
+	}
 
 	// Print efficiency score
-	printEfficiency(breakdown)
+	printEfficiency(f, breakdown)
 
 	// Print modeling callout if PR duration exceeds target merge time
-	if breakdown.PRDuration > cfg.TargetMergeTimeHours {
-		printMergeTimeModelingCallout(breakdown, cfg)
+	if callout && breakdown.PRDuration > cfg.TargetMergeTimeHours {
+		printMergeTimeModelingCallout(f, breakdown, cfg)
 	}
 }
 
+// printVariance prints how far the modeled hands-on hours are from the hours actually tracked.
+func printVariance(w io.Writer, v *cost.Variance) {
+	direction := "overestimates"
+	if v.ErrorPct < 0 {
+		direction = "underestimates"
+	}
+	fmt.Fprintln(w, "  Actual vs. Estimated")
+	fmt.Fprintln(w, "  ────────────────────")
+	fmt.Fprintf(w, "    Modeled (author + participants)   %s\n", formatTimeUnit(v.ModeledHours))
+	fmt.Fprintf(w, "    Actual (tracked)                  %s\n", formatTimeUnit(v.ActualHours))
+	fmt.Fprintf(w, "    Error                             %+.1f%% (model %s)\n", v.ErrorPct, direction)
+	fmt.Fprintln(w)
+}
+
 // printDelayCosts prints delay and future costs section.
-func printDelayCosts(breakdown *cost.Breakdown, formatCurrency func(float64) string) {
+func printDelayCosts(f *formatter, breakdown *cost.Breakdown) {
 	// Merge Delay Costs
-	fmt.Println("  Delay Costs")
-	fmt.Println("  ───────────")
+	fmt.Fprintln(f.w, "  Delay Costs")
+	fmt.Fprintln(f.w, "  ───────────")
 
 	if breakdown.DelayCostDetail.DeliveryDelayHours > 0 {
-		cappedSuffix := ""
-		if breakdown.DelayCapped {
-			cappedSuffix = " (capped)"
-		}
-		fmt.Printf("    Workstream blockage       %12s    %s%s\n",
-			formatCurrency(breakdown.DelayCostDetail.DeliveryDelayCost),
+		fmt.Fprintf(f.w, "    Workstream blockage       %12s    %s%s\n",
+			f.currency(breakdown.DelayCostDetail.DeliveryDelayCost),
 			formatTimeUnit(breakdown.DelayCostDetail.DeliveryDelayHours),
-			cappedSuffix)
+			capSuffix(breakdown.CapAppliedTo.DeliveryDelay))
+	}
+	for _, a := range breakdown.DelayAttribution {
+		fmt.Fprintf(f.w, "      %-24s%12s    %s • %.0f%% of the wait\n",
+			delayHolderLabel(a), f.currency(a.Cost), formatTimeUnit(a.Hours), a.Share*100)
+	}
+	if breakdown.DelayCostDetail.DeliveryDelayBasis == cost.DelayBasisNoWaitingEvidence {
+		fmt.Fprintf(f.w, "    Workstream blockage       %12s    nobody was waiting (no review requests or reviewer activity)\n", "—")
 	}
 
 	// Calculate merge delay subtotal (all non-future delay costs)
@@ -349,11 +468,11 @@ func printDelayCosts(breakdown *cost.Breakdown, formatCurrency func(float64) str
 		breakdown.DelayCostDetail.AutomatedUpdatesHours +
 		breakdown.DelayCostDetail.PRTrackingHours
 
-	fmt.Println("                              ────────────")
-	pct := (mergeDelayCost / breakdown.TotalCost) * 100
-	fmt.Printf("    Subtotal                  %12s    %s  (%.1f%%)\n",
-		formatCurrency(mergeDelayCost), formatTimeUnit(mergeDelayHours), pct)
-	fmt.Println()
+	fmt.Fprintln(f.w, "                              ────────────")
+	pct := percentOf(mergeDelayCost, breakdown.TotalCost)
+	fmt.Fprintf(f.w, "    Subtotal                  %12s    %s  (%.1f%%)\n",
+		f.currency(mergeDelayCost), formatTimeUnit(mergeDelayHours), pct)
+	fmt.Fprintln(f.w)
 
 	// Future Costs
 	hasFutureCosts := breakdown.DelayCostDetail.ReworkPercentage > 0 ||
@@ -362,41 +481,77 @@ func printDelayCosts(breakdown *cost.Breakdown, formatCurrency func(float64) str
 		breakdown.DelayCostDetail.FutureContextCost > 0
 
 	if hasFutureCosts {
-		printFutureCosts(breakdown, formatCurrency)
+		printFutureCosts(f, breakdown)
 	}
 }
 
+// delayHolderLabel names who had the ball for a share of delivery delay, e.g. "bob (reviewer)".
+func delayHolderLabel(a cost.DelayAttribution) string {
+	if a.Role == cost.DelayHolderUnassigned {
+		return "no reviewer yet"
+	}
+	return fmt.Sprintf("%s (%s)", a.Actor, a.Role)
+}
+
+// capSuffix describes the cap that bound a delay component, or "" if none did.
+func capSuffix(capName string) string {
+	switch capName {
+	case cost.CapMaxDelayAfterLastEvent:
+		return " (capped: time since last event)"
+	case cost.CapMaxProjectDelay:
+		return " (capped: max project delay)"
+	case cost.CapMaxCodeDrift:
+		return " (capped: max code drift)"
+	case cost.CapMaxPRCodeCost:
+		return " (capped: max PR code cost)"
+	default:
+		return ""
+	}
+}
+
+// formatLineChanges describes a PR's diff size, e.g. "26 additions, 4 deletions".
+func formatLineChanges(added, deleted int) string {
+	plural := func(n int, word string) string {
+		if n == 1 {
+			return "1 " + word
+		}
+		return fmt.Sprintf("%d %ss", n, word)
+	}
+	return plural(added, "addition") + ", " + plural(deleted, "deletion")
+}
+
 // printFutureCosts prints future costs subsection.
-func printFutureCosts(breakdown *cost.Breakdown, formatCurrency func(float64) string) {
-	fmt.Println("  Future Costs")
-	fmt.Println("  ────────────")
+func printFutureCosts(f *formatter, breakdown *cost.Breakdown) {
+	fmt.Fprintln(f.w, "  Future Costs")
+	fmt.Fprintln(f.w, "  ────────────")
 
 	if breakdown.DelayCostDetail.ReworkPercentage > 0 {
 		label := fmt.Sprintf("Code Churn (%.0f%% drift)", breakdown.DelayCostDetail.ReworkPercentage)
-		fmt.Printf("    %-26s%12s    %s\n",
+		fmt.Fprintf(f.w, "    %-26s%12s    %s%s\n",
 			label,
-			formatCurrency(breakdown.DelayCostDetail.CodeChurnCost),
-			formatTimeUnit(breakdown.DelayCostDetail.CodeChurnHours))
+			f.currency(breakdown.DelayCostDetail.CodeChurnCost),
+			formatTimeUnit(breakdown.DelayCostDetail.CodeChurnHours),
+			capSuffix(breakdown.CapAppliedTo.CodeChurn))
 	}
 
 	if breakdown.DelayCostDetail.FutureReviewCost > 0 {
-		fmt.Printf("    %-26s%12s    %s\n",
+		fmt.Fprintf(f.w, "    %-26s%12s    %s\n",
 			"Review",
-			formatCurrency(breakdown.DelayCostDetail.FutureReviewCost),
+			f.currency(breakdown.DelayCostDetail.FutureReviewCost),
 			formatTimeUnit(breakdown.DelayCostDetail.FutureReviewHours))
 	}
 
 	if breakdown.DelayCostDetail.FutureMergeCost > 0 {
-		fmt.Printf("    %-26s%12s    %s\n",
+		fmt.Fprintf(f.w, "    %-26s%12s    %s\n",
 			"Merge",
-			formatCurrency(breakdown.DelayCostDetail.FutureMergeCost),
+			f.currency(breakdown.DelayCostDetail.FutureMergeCost),
 			formatTimeUnit(breakdown.DelayCostDetail.FutureMergeHours))
 	}
 
 	if breakdown.DelayCostDetail.FutureContextCost > 0 {
-		fmt.Printf("    %-26s%12s    %s\n",
+		fmt.Fprintf(f.w, "    %-26s%12s    %s\n",
 			"Context Switching",
-			formatCurrency(breakdown.DelayCostDetail.FutureContextCost),
+			f.currency(breakdown.DelayCostDetail.FutureContextCost),
 			formatTimeUnit(breakdown.DelayCostDetail.FutureContextHours))
 	}
 
@@ -408,33 +563,20 @@ func printFutureCosts(breakdown *cost.Breakdown, formatCurrency func(float64) st
 		breakdown.DelayCostDetail.FutureReviewHours +
 		breakdown.DelayCostDetail.FutureMergeHours +
 		breakdown.DelayCostDetail.FutureContextHours
-	fmt.Println("                              ────────────")
-	pct := (futureCost / breakdown.TotalCost) * 100
-	fmt.Printf("    Subtotal                  %12s    %s  (%.1f%%)\n",
-		formatCurrency(futureCost), formatTimeUnit(futureHours), pct)
-	fmt.Println()
+	fmt.Fprintln(f.w, "                              ────────────")
+	pct := percentOf(futureCost, breakdown.TotalCost)
+	fmt.Fprintf(f.w, "    Subtotal                  %12s    %s  (%.1f%%)\n",
+		f.currency(futureCost), formatTimeUnit(futureHours), pct)
+	fmt.Fprintln(f.w)
 }
 
-// formatWithCommas formats a float with commas for thousands separators.
-func formatWithCommas(amount float64) string {
-	// Format with 2 decimal places
-	s := fmt.Sprintf("%.2f", amount)
-
-	// Split into integer and decimal parts
-	parts := strings.Split(s, ".")
-	intPart := parts[0]
-	decPart := parts[1]
-
-	// Add commas to integer part
-	var result []rune
-	for i, r := range intPart {
-		if i > 0 && (len(intPart)-i)%3 == 0 {
-			result = append(result, ',')
-		}
-		result = append(result, r)
+// percentOf returns part as a percentage of total, or 0 if total is zero, so a PR that
+// costs nothing, such as a bot PR with no code or delay, prints 0.0% rather than NaN%.
+func percentOf(part, total float64) float64 {
+	if total == 0 {
+		return 0
 	}
-
-	return string(result) + "." + decPart
+	return part / total * 100
 }
 
 // formatLOC formats lines of code in kilo format with appropriate precision and commas for large values.
@@ -482,54 +624,20 @@ func formatLOC(kloc float64) string {
 }
 
 // printMergeTimeModelingCallout prints a callout showing potential savings from reduced merge time.
-func printMergeTimeModelingCallout(breakdown *cost.Breakdown, cfg cost.Config) {
+func printMergeTimeModelingCallout(f *formatter, breakdown *cost.Breakdown, cfg cost.Config) {
 	targetHours := cfg.TargetMergeTimeHours
 	currentHours := breakdown.PRDuration
+	savingsPerPR := breakdown.PotentialSavings
 
-	// Calculate hourly rate
-	hourlyRate := (cfg.AnnualSalary * cfg.BenefitsMultiplier) / cfg.HoursPerYear
-
-	// Recalculate delivery delay with target merge time
-	remodelDeliveryDelayCost := hourlyRate * cfg.DeliveryDelayFactor * targetHours
-
-	// Code churn: target time is too short for meaningful code churn (< 1 day)
-	remodelCodeChurnCost := 0.0
-
-	// Automated updates: only applies to PRs open > 1 day
-	remodelAutomatedUpdatesCost := 0.0
-
-	// PR tracking: scales with open time (already minimal for short PRs)
-	remodelPRTrackingCost := 0.0
-	if targetHours >= 1.0 { // Minimal tracking for PRs open >= 1 hour
-		daysOpen := targetHours / 24.0
-		remodelPRTrackingHours := (cfg.PRTrackingMinutesPerDay / 60.0) * daysOpen
-		remodelPRTrackingCost = remodelPRTrackingHours * hourlyRate
-	}
-
-	// Calculate savings for this PR
-	currentPreventable := breakdown.DelayCostDetail.DeliveryDelayCost +
-		breakdown.DelayCostDetail.CodeChurnCost +
-		breakdown.DelayCostDetail.AutomatedUpdatesCost +
-		breakdown.DelayCostDetail.PRTrackingCost
-
-	remodelPreventable := remodelDeliveryDelayCost + remodelCodeChurnCost +
-		remodelAutomatedUpdatesCost + remodelPRTrackingCost
-
-	savingsPerPR := currentPreventable - remodelPreventable
-
-	// Calculate efficiency improvement
-	// Current efficiency: (total hours - preventable hours) / total hours
-	// Modeled efficiency: (total hours - remodeled preventable hours) / total hours
+	// Calculate efficiency improvement: the saved hours are no longer preventable waste
 	totalHours := breakdown.Author.TotalHours + breakdown.DelayCostDetail.TotalDelayHours
 	for _, p := range breakdown.Participants {
 		totalHours += p.TotalHours
 	}
 
-	var currentEfficiency, modeledEfficiency, efficiencyDelta float64
-	if totalHours > 0 {
-		currentEfficiency = 100.0 * (totalHours - (currentPreventable / hourlyRate)) / totalHours
-		modeledEfficiency = 100.0 * (totalHours - (remodelPreventable / hourlyRate)) / totalHours
-		efficiencyDelta = modeledEfficiency - currentEfficiency
+	var efficiencyDelta float64
+	if totalHours > 0 && breakdown.HourlyRate > 0 {
+		efficiencyDelta = 100.0 * (savingsPerPR / breakdown.HourlyRate) / totalHours
 	}
 
 	// Estimate annual savings assuming similar PR frequency
@@ -543,70 +651,82 @@ func printMergeTimeModelingCallout(breakdown *cost.Breakdown, cfg cost.Config) {
 		}
 		annualSavings := savingsPerPR * (52.0 / weeksOpen)
 
-		fmt.Println("  ┌─────────────────────────────────────────────────────────────┐")
-		fmt.Printf("  │ %-60s│\n", "MERGE TIME MODELING")
-		fmt.Println("  └─────────────────────────────────────────────────────────────┘")
+		fmt.Fprintln(f.w, "  ┌─────────────────────────────────────────────────────────────┐")
+		fmt.Fprintf(f.w, "  │ %-60s│\n", "MERGE TIME MODELING")
+		fmt.Fprintln(f.w, "  └─────────────────────────────────────────────────────────────┘")
+		fmt.Fprintf(f.w, "  Merging in %s instead of %s would have saved %s.\n",
+			formatTimeUnit(targetHours), formatTimeUnit(currentHours), f.currency(savingsPerPR))
 		if efficiencyDelta > 0 {
-			fmt.Printf("  Reduce merge time to %s to boost team throughput by %.1f%%\n", formatTimeUnit(targetHours), efficiencyDelta)
-			fmt.Printf("  and save ~$%s/yr in engineering overhead.\n", formatWithCommas(annualSavings))
+			fmt.Fprintf(f.w, "  Reduce merge time to %s to boost team throughput by %.1f%%\n", formatTimeUnit(targetHours), efficiencyDelta)
+			fmt.Fprintf(f.w, "  and save ~%s/yr in engineering overhead.\n", f.currency(annualSavings))
 		} else {
-			fmt.Printf("  If you lowered your average merge time to %s, you would save\n", formatTimeUnit(targetHours))
-			fmt.Printf("  ~$%s/yr in engineering overhead.\n", formatWithCommas(annualSavings))
+			fmt.Fprintf(f.w, "  If you lowered your average merge time to %s, you would save\n", formatTimeUnit(targetHours))
+			fmt.Fprintf(f.w, "  ~%s/yr in engineering overhead.\n", f.currency(annualSavings))
 		}
-		fmt.Println()
+		fmt.Fprintln(f.w)
 	}
 }
 
 // printEfficiency prints the workflow efficiency section for a single PR.
-func printEfficiency(breakdown *cost.Breakdown) {
-	// Calculate preventable waste: Code Churn + All Delay Costs + Automated Updates + PR Tracking
-	preventableHours := breakdown.DelayCostDetail.CodeChurnHours +
-		breakdown.DelayCostDetail.DeliveryDelayHours +
-		breakdown.DelayCostDetail.AutomatedUpdatesHours +
-		breakdown.DelayCostDetail.PRTrackingHours
-	preventableCost := breakdown.DelayCostDetail.CodeChurnCost +
-		breakdown.DelayCostDetail.DeliveryDelayCost +
-		breakdown.DelayCostDetail.AutomatedUpdatesCost +
-		breakdown.DelayCostDetail.PRTrackingCost
-
-	// Calculate total hours
-	totalHours := breakdown.Author.TotalHours + breakdown.DelayCostDetail.TotalDelayHours
-	for _, p := range breakdown.Participants {
-		totalHours += p.TotalHours
-	}
-
-	// Calculate efficiency
-	var efficiencyPct float64
-	if totalHours > 0 {
-		efficiencyPct = 100.0 * (totalHours - preventableHours) / totalHours
-	} else {
-		efficiencyPct = 100.0
-	}
-
-	grade, message := cost.EfficiencyGrade(efficiencyPct)
+func printEfficiency(f *formatter, breakdown *cost.Breakdown) {
+	preventableHours, preventableCost := breakdown.PreventableHours(), breakdown.PreventableCost()
 
-	// Calculate merge velocity grade based on PR duration (in hours)
-	velocityGrade, velocityMessage := cost.MergeVelocityGrade(breakdown.PRDuration)
+	efficiencyPct := breakdown.EfficiencyPct
+	grade, message := breakdown.EfficiencyGrade, breakdown.EfficiencyMessage
+	velocityGrade, velocityMessage := breakdown.MergeVelocityGrade, breakdown.MergeVelocityMessage
 
-	fmt.Println("  ┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(f.w, "  ┌─────────────────────────────────────────────────────────────┐")
 	headerText := fmt.Sprintf("DEVELOPMENT EFFICIENCY: %s (%.1f%%) - %s", grade, efficiencyPct, message)
 	padding := 60 - len(headerText)
 	if padding < 0 {
 		padding = 0
 	}
-	fmt.Printf("  │ %s%*s│\n", headerText, padding, "")
-	fmt.Println("  └─────────────────────────────────────────────────────────────┘")
+	fmt.Fprintf(f.w, "  │ %s%*s│\n", headerText, padding, "")
+	fmt.Fprintln(f.w, "  └─────────────────────────────────────────────────────────────┘")
 
-	fmt.Println("  ┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(f.w, "  ┌─────────────────────────────────────────────────────────────┐")
 	velocityHeader := fmt.Sprintf("MERGE VELOCITY: %s (%s) - %s", velocityGrade, formatTimeUnit(breakdown.PRDuration), velocityMessage)
 	velPadding := 60 - len(velocityHeader)
 	if velPadding < 0 {
 		velPadding = 0
 	}
-	fmt.Printf("  │ %s%*s│\n", velocityHeader, velPadding, "")
-	fmt.Println("  └─────────────────────────────────────────────────────────────┘")
+	fmt.Fprintf(f.w, "  │ %s%*s│\n", velocityHeader, velPadding, "")
+	fmt.Fprintln(f.w, "  └─────────────────────────────────────────────────────────────┘")
 
-	fmt.Printf("  Preventable Waste:         $%12s    %s\n",
-		formatWithCommas(preventableCost), formatTimeUnit(preventableHours))
-	fmt.Println()
+	fmt.Fprintf(f.w, "  Preventable Waste:         %13s    %s\n",
+		f.currency(preventableCost), formatTimeUnit(preventableHours))
+	fmt.Fprintln(f.w)
+}
+
+// loadActualHoursFile reads tracked hours per PR URL from a "pr_url,hours" CSV file.
+func loadActualHoursFile(path string) (map[string]float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open actuals file: %w", err)
+	}
+	defer func() { _ = f.Close() }() //nolint:errcheck // best effort close
+	actuals, err := cost.ParseActualHoursCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse actuals file: %w", err)
+	}
+	return actuals, nil
+}
+
+// loadCompensationFile reads per-author salaries, and any salary currencies, from a CSV file,
+// or from a JSON object of login to salary when the file name ends in .json.
+func loadCompensationFile(path string) (salaries map[string]float64, currencies map[string]string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open compensation file: %w", err)
+	}
+	defer func() { _ = f.Close() }() //nolint:errcheck // best effort close
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		salaries, err = cost.ParseCompensationJSON(f)
+	} else {
+		salaries, currencies, err = cost.ParseCompensationCSV(f)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse compensation file: %w", err)
+	}
+	return salaries, currencies, nil
 }
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+// markdownTable accumulates the rows of a Markdown cost table, skipping zero-cost items
+// so the output matches what the human-readable ledger shows.
+type markdownTable struct {
+	f           *formatter
+	rows        []string
+	cost, hours float64
+}
+
+// item adds a cost row, unless amount is zero.
+func (t *markdownTable) item(label string, amount, hours float64, detail string) {
+	if amount == 0 {
+		return
+	}
+	t.rows = append(t.rows, fmt.Sprintf("| %s | %s | %s | %s |",
+		markdownEscape(label), t.f.currency(amount), formatTimeUnit(hours), markdownEscape(detail)))
+	t.cost += amount
+	t.hours += hours
+}
+
+// write writes the table under heading, with a subtotal row; empty tables are omitted.
+func (t *markdownTable) write(sb *strings.Builder, heading string) {
+	if len(t.rows) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "### %s\n\n", heading)
+	sb.WriteString("| Item | Cost | Time | Detail |\n|---|---:|---:|---|\n")
+	for _, row := range t.rows {
+		sb.WriteString(row + "\n")
+	}
+	fmt.Fprintf(sb, "| **Subtotal** | **%s** | **%s** | |\n\n", t.f.currency(t.cost), formatTimeUnit(t.hours))
+}
+
+// markdownEscape escapes text for a Markdown table cell.
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// writeBreakdownMarkdown writes a single PR's breakdown as Markdown (--format markdown),
+// with a table per cost section and the grades in the summary.
+func writeBreakdownMarkdown(w io.Writer, f *formatter, breakdown *cost.Breakdown, title string) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## PR cost: %s\n\n", title)
+
+	totalHours := breakdown.Author.TotalHours + breakdown.DelayCostDetail.TotalDelayHours
+	for _, p := range breakdown.Participants {
+		totalHours += p.TotalHours
+	}
+	fmt.Fprintf(&sb, "**Total: %s** (%s) • Efficiency: %s (%.1f%%) • Merge velocity: %s (%s)\n\n",
+		f.currency(breakdown.TotalCost), formatTimeUnit(totalHours),
+		breakdown.EfficiencyGrade, breakdown.EfficiencyPct,
+		breakdown.MergeVelocityGrade, formatTimeUnit(breakdown.PRDuration))
+
+	author := breakdown.PRAuthor
+	if breakdown.AuthorBot {
+		author += " (bot)"
+	}
+	fmt.Fprintf(&sb, "Author: %s • Open: %s • %s • Rate: %s/hr\n\n",
+		author, formatTimeUnit(breakdown.PRDuration),
+		formatLineChanges(breakdown.Author.LinesAdded, breakdown.Author.LinesDeleted), f.currency(breakdown.HourlyRate))
+
+	a := breakdown.Author
+	dev := markdownTable{f: f}
+	codeCap := capSuffix(breakdown.CapAppliedTo.CodeCost)
+	dev.item("New development", a.NewCodeCost, a.NewCodeHours, fmt.Sprintf("%d LOC%s", a.NewLines, codeCap))
+	dev.item("Adaptation", a.AdaptationCost, a.AdaptationHours, fmt.Sprintf("%d LOC%s", a.ModifiedLines, codeCap))
+	dev.item("GitHub activity", a.GitHubCost, a.GitHubHours, fmt.Sprintf("%d sessions", a.Sessions))
+	dev.item("GitHub context switching", a.GitHubContextCost, a.GitHubContextHours, "")
+	dev.item("Conflict resolution", a.ConflictResolutionCost, a.ConflictResolutionHours, fmt.Sprintf("%d conflicts", a.ConflictResolutions))
+	dev.write(&sb, "Development costs")
+
+	participants := markdownTable{f: f}
+	for _, p := range breakdown.Participants {
+		var details []string
+		if p.ReviewRounds > 1 {
+			details = append(details, fmt.Sprintf("%d review rounds", p.ReviewRounds))
+		}
+		if p.Sessions > 0 {
+			details = append(details, fmt.Sprintf("%d sessions", p.Sessions))
+		}
+		if p.ReviewWaitCost > 0 {
+			details = append(details, fmt.Sprintf("kept author waiting %s (%s)", formatTimeUnit(p.ReviewLatencyHours), f.currency(p.ReviewWaitCost)))
+		}
+		participants.item(p.Actor, p.TotalCost, p.TotalHours, strings.Join(details, ", "))
+	}
+	participants.write(&sb, "Participant costs")
+
+	d := breakdown.DelayCostDetail
+	delay := markdownTable{f: f}
+	blockage := strings.TrimPrefix(capSuffix(breakdown.CapAppliedTo.DeliveryDelay), " ")
+	var holders []string
+	for _, a := range breakdown.DelayAttribution {
+		holders = append(holders, fmt.Sprintf("%s %.0f%%", delayHolderLabel(a), a.Share*100))
+	}
+	if len(holders) > 0 {
+		blockage = strings.TrimPrefix(blockage+"; on the ball: "+strings.Join(holders, ", "), "; ")
+	}
+	delay.item("Workstream blockage", d.DeliveryDelayCost, d.DeliveryDelayHours, blockage)
+	delay.item("Automated updates", d.AutomatedUpdatesCost, d.AutomatedUpdatesHours, "")
+	delay.item("PR tracking", d.PRTrackingCost, d.PRTrackingHours, "")
+	delay.write(&sb, "Delay costs")
+
+	future := markdownTable{f: f}
+	future.item(fmt.Sprintf("Code churn (%.0f%% drift)", d.ReworkPercentage), d.CodeChurnCost, d.CodeChurnHours,
+		strings.TrimPrefix(capSuffix(breakdown.CapAppliedTo.CodeChurn), " "))
+	future.item("Review", d.FutureReviewCost, d.FutureReviewHours, "")
+	future.item("Merge", d.FutureMergeCost, d.FutureMergeHours, "")
+	future.item("Context switching", d.FutureContextCost, d.FutureContextHours, "")
+	future.write(&sb, "Future costs")
+
+	if breakdown.CostPerLOC > 0 {
+		fmt.Fprintf(&sb, "Per line of code: %s (%d lines added)\n\n", f.currency(breakdown.CostPerLOC), a.LinesAdded)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeExtrapolatedMarkdown writes a repo or org analysis as Markdown (--format markdown):
+// a summary with the grades, and a table of the extrapolated cost components.
+func writeExtrapolatedMarkdown(w io.Writer, f *formatter, title string, days, requestedDays int, ext *cost.ExtrapolatedBreakdown,
+	scenarios []cost.ScenarioResult,
+) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## %s\n\n", title)
+
+	fmt.Fprintf(&sb, "Period: %s • Total PRs: %d (%d human, %d bot) • Authors: %d • Sampled: %d\n\n",
+		formatPeriod(days, requestedDays), ext.TotalPRs, ext.HumanPRs, ext.BotPRs, ext.TotalAuthors, ext.SuccessfulSamples)
+	velocityBasis := formatTimeUnit(ext.AvgPRDurationHours) + " avg"
+	if ext.MergeVelocityBasis == "p50" {
+		velocityBasis = formatTimeUnit(ext.P50PRDurationHours) + " p50"
+	}
+	fmt.Fprintf(&sb, "**Total: %s** (%s) • Efficiency: %s (%.1f%%) • Merge velocity: %s (%s) • Merge rate: %s (%.1f%%)\n\n",
+		f.currency(ext.TotalCost), formatTimeUnit(ext.TotalHours),
+		ext.EfficiencyGrade, ext.EfficiencyPct,
+		ext.MergeVelocityGrade, velocityBasis,
+		ext.MergeRateGrade, ext.MergeRate)
+
+	sb.WriteString("| Component | Cost | Time |\n|---|---:|---:|\n")
+	row := func(label string, amount, hours float64) {
+		if amount != 0 {
+			fmt.Fprintf(&sb, "| %s | %s | %s |\n", label, f.currency(amount), formatTimeUnit(hours))
+		}
+	}
+	subtotal := func(label string, amount, hours float64) {
+		fmt.Fprintf(&sb, "| **%s** | **%s** | **%s** |\n", label, f.currency(amount), formatTimeUnit(hours))
+	}
+	row("New development", ext.AuthorNewCodeCost, ext.AuthorNewCodeHours)
+	row("Adaptation", ext.AuthorAdaptationCost, ext.AuthorAdaptationHours)
+	row("GitHub activity", ext.AuthorGitHubCost, ext.AuthorGitHubHours)
+	row("GitHub context switching", ext.AuthorGitHubContextCost, ext.AuthorGitHubContextHours)
+	row("Conflict resolution", ext.AuthorConflictResolutionCost, ext.AuthorConflictResolutionHours)
+	subtotal("Development", ext.AuthorTotalCost, ext.AuthorTotalHours)
+	row("Review", ext.ParticipantReviewCost, ext.ParticipantReviewHours)
+	row("Participant GitHub activity", ext.ParticipantGitHubCost, ext.ParticipantGitHubHours)
+	row("Participant context switching", ext.ParticipantContextCost, ext.ParticipantContextHours)
+	row("Review wait", ext.ParticipantReviewWaitCost, ext.ParticipantReviewWaitHours)
+	subtotal("Participants", ext.ParticipantTotalCost, ext.ParticipantTotalHours)
+	row("Workstream blockage", ext.DeliveryDelayCost, ext.DeliveryDelayHours)
+	row("Code churn", ext.CodeChurnCost, ext.CodeChurnHours)
+	row("Automated updates", ext.AutomatedUpdatesCost, ext.AutomatedUpdatesHours)
+	row("PR tracking", ext.PRTrackingCost, ext.PRTrackingHours)
+	row("Future review", ext.FutureReviewCost, ext.FutureReviewHours)
+	row("Future merge", ext.FutureMergeCost, ext.FutureMergeHours)
+	row("Future context switching", ext.FutureContextCost, ext.FutureContextHours)
+	subtotal("Delay and future costs", ext.DelayTotalCost, ext.DelayTotalHours)
+	subtotal("Total", ext.TotalCost, ext.TotalHours)
+	sb.WriteString("\n")
+
+	if ext.WasteCostPerWeek > 0 {
+		fmt.Fprintf(&sb, "Preventable waste: %s/week (%s per author)\n\n",
+			f.currency(ext.WasteCostPerWeek), f.currency(ext.WasteCostPerAuthorPerWeek))
+	}
+
+	if len(scenarios) > 0 {
+		baseline := scenarios[0].Extrapolated.TotalCost
+		sb.WriteString("### Scenarios\n\n| Scenario | Total cost | Change |\n|---|---:|---:|\n")
+		for i, s := range scenarios {
+			change := "—"
+			if i > 0 && baseline > 0 {
+				diff := s.Extrapolated.TotalCost - baseline
+				change = fmt.Sprintf("%+.1f%%", diff/baseline*100)
+			}
+			fmt.Fprintf(&sb, "| %s | %s | %s |\n", markdownEscape(s.Name), f.currency(s.Extrapolated.TotalCost), change)
+		}
+		sb.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+func TestWriteBreakdownMarkdown(t *testing.T) {
+	now := time.Now()
+	breakdown := cost.Calculate(cost.PRData{
+		LinesAdded:   100,
+		LinesDeleted: 1,
+		Author:       "alice",
+		CreatedAt:    now.Add(-3 * time.Hour),
+		ClosedAt:     now,
+		Events: []cost.ParticipantEvent{
+			{Timestamp: now.Add(-2 * time.Hour), Actor: "alice", Kind: "commit"},
+			{Timestamp: now.Add(-time.Hour), Actor: "bob", Kind: "review"},
+		},
+	}, cost.DefaultConfig())
+
+	var sb strings.Builder
+	if err := writeBreakdownMarkdown(&sb, testFormatter(), &breakdown, "https://github.com/o/r/pull/1"); err != nil {
+		t.Fatalf("writeBreakdownMarkdown: %v", err)
+	}
+	out := sb.String()
+	for _, want := range []string{
+		"## PR cost: https://github.com/o/r/pull/1",
+		"**Total: " + testFormatter().currency(breakdown.TotalCost) + "**",
+		"Efficiency: " + breakdown.EfficiencyGrade,
+		"Merge velocity: " + breakdown.MergeVelocityGrade,
+		"100 additions, 1 deletion",
+		"### Development costs",
+		"| Item | Cost | Time | Detail |",
+		"| New development | " + testFormatter().currency(breakdown.Author.NewCodeCost),
+		"### Participant costs",
+		"| bob | $",
+		"### Delay costs",
+		"on the ball: no reviewer yet 67%, alice (author) 33%",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown missing %q:\n%s", want, out)
+		}
+	}
+	// Closed PRs have no future costs, and no box-drawing characters leak in from the ledger
+	if strings.Contains(out, "### Future costs") || strings.ContainsAny(out, "─═") {
+		t.Errorf("unexpected section or box drawing in markdown:\n%s", out)
+	}
+}
+
+func TestWriteExtrapolatedMarkdown(t *testing.T) {
+	ext := cost.ExtrapolatedBreakdown{
+		TotalPRs: 120, HumanPRs: 100, BotPRs: 20, TotalAuthors: 12, SuccessfulSamples: 30,
+		AuthorNewCodeCost: 3000, AuthorTotalCost: 3000, AuthorNewCodeHours: 20, AuthorTotalHours: 20,
+		ParticipantReviewCost: 800, ParticipantTotalCost: 800, ParticipantReviewHours: 5, ParticipantTotalHours: 5,
+		DeliveryDelayCost: 400, DelayTotalCost: 400, DeliveryDelayHours: 3, DelayTotalHours: 3,
+		TotalCost: 4200, TotalHours: 28,
+		EfficiencyGrade: "B", EfficiencyPct: 88, MergeVelocityGrade: "A", AvgPRDurationHours: 6,
+		MergeRateGrade: "A", MergeRate: 92,
+	}
+	scenarios := []cost.ScenarioResult{
+		{Name: "baseline", Extrapolated: ext},
+		{Name: "senior|team", Extrapolated: cost.ExtrapolatedBreakdown{TotalCost: 4620}},
+	}
+
+	var sb strings.Builder
+	if err := writeExtrapolatedMarkdown(&sb, testFormatter(), "myorg (organization)", 21, 90, &ext, scenarios); err != nil {
+		t.Fatalf("writeExtrapolatedMarkdown: %v", err)
+	}
+	out := sb.String()
+	for _, want := range []string{
+		"## myorg (organization)",
+		"Period: Last 21 days (truncated from 90 by API limits) • Total PRs: 120 (100 human, 20 bot)",
+		"**Total: $4,200.00**",
+		"Efficiency: B (88.0%) • Merge velocity: A (6.0h avg) • Merge rate: A (92.0%)",
+		"| Component | Cost | Time |",
+		"| New development | $3,000.00 |",
+		"| **Development** | **$3,000.00** |",
+		"| Review | $800.00 |",
+		"| Workstream blockage | $400.00 |",
+		"| **Total** | **$4,200.00** |",
+		"| senior\\|team | $4,620.00 | +10.0% |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown missing %q:\n%s", want, out)
+		}
+	}
+	// Zero-cost components are left out
+	if strings.Contains(out, "| Adaptation |") {
+		t.Errorf("markdown includes a zero-cost component:\n%s", out)
+	}
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
 	"strings"
 	"time"
 
@@ -11,15 +13,91 @@ import (
 	"github.com/codeGROOVE-dev/prcost/pkg/github"
 )
 
+// analysisWindow is the period a repo or org analysis samples PRs from: the most recent days,
+// or an absolute window set with --since and --until (a zero until means now).
+type analysisWindow struct {
+	since    time.Time
+	until    time.Time
+	days     int
+	absolute bool
+}
+
+// newAnalysisWindow returns the window for o: its --since/--until range if set, else the last o.days days.
+func newAnalysisWindow(o *options) analysisWindow {
+	if !o.since.IsZero() {
+		return analysisWindow{since: o.since, until: o.until, days: o.days, absolute: true}
+	}
+	return analysisWindow{since: time.Now().AddDate(0, 0, -o.days), days: o.days}
+}
+
+// String describes the window for messages, e.g. "in the last 60 days".
+func (w analysisWindow) String() string {
+	switch {
+	case !w.absolute:
+		return fmt.Sprintf("in the last %d days", w.days)
+	case w.until.IsZero():
+		return "since " + w.since.Format("2006-01-02")
+	default:
+		return fmt.Sprintf("between %s and %s", w.since.Format("2006-01-02"), w.until.Format("2006-01-02"))
+	}
+}
+
+// title adds an absolute window's dates to a report title; relative windows already show as "Last N days".
+func (w analysisWindow) title(title string) string {
+	if !w.absolute {
+		return title
+	}
+	end := "now"
+	if !w.until.IsZero() {
+		end = w.until.Format("2006-01-02")
+	}
+	return fmt.Sprintf("%s, %s to %s", title, w.since.Format("2006-01-02"), end)
+}
+
+// analysisOptions holds the settings of a repository or organization analysis.
+//
+//nolint:govet // fieldalignment: grouped by purpose for readability
+type analysisOptions struct {
+	sampleSize     int
+	sampling       github.SamplingMode
+	window         analysisWindow
+	concurrency    int
+	cfg            cost.Config
+	scenarios      []cost.Scenario
+	filter         github.PRFilter
+	token          string
+	api            *github.APIOptions // How GitHub API calls are retried and rate limited
+	dataSource     string
+	format         string
+	formatter      *formatter // Formats amounts in human-readable and Markdown output
+	checkpointPath string     // Checkpoint file to resume from and save to ("" = none)
+	dryRun         bool       // Print the sample plan instead of fetching
+	callout        bool       // Include the merge time modeling callout
+	includeSamples bool       // Include per-sample breakdowns in the results
+}
+
 // analyzeRepository performs repository-wide cost analysis by sampling PRs.
 // Uses library functions from pkg/github and pkg/cost for fetching, sampling,
 // and extrapolation - all functionality is available to external clients.
-func analyzeRepository(ctx context.Context, owner, repo string, sampleSize, days int, cfg cost.Config, token, dataSource string) error {
-	// Calculate since date
-	since := time.Now().AddDate(0, 0, -days)
+func analyzeRepository(ctx context.Context, owner, repo string, opts analysisOptions) error {
+	// Progress messages go to stderr when stdout carries CSV or JSON
+	progress := progressWriter(opts.formatter, opts.format)
+
+	since, until, days := opts.window.since, opts.window.until, opts.window.days
+
+	// Open the checkpoint first, so one left by a different scan fails before any fetching
+	var cp *checkpoint
+	if opts.checkpointPath != "" && !opts.dryRun {
+		var err error
+		if cp, err = openCheckpoint(opts.checkpointPath, checkpointScope(owner+"/"+repo, opts.window, opts.sampleSize, opts.filter)); err != nil {
+			return err
+		}
+	}
 
 	// Fetch all PRs modified since the date using library function
-	prs, err := github.FetchPRsFromRepo(ctx, owner, repo, since, token, nil)
+	prs, err := github.FetchPRsFromRepo(ctx, owner, repo, github.PRQuery{
+		Since: since, Until: until, State: opts.filter.State, Token: opts.token, API: opts.api,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to fetch PRs: %w", err)
 	}
@@ -28,51 +106,72 @@ func analyzeRepository(ctx context.Context, owner, repo string, sampleSize, days
 		"total_prs", len(prs),
 		"since", since.Format("2006-01-02"))
 
+	// Restrict the pool to PRs matching the filter (paths, labels, author, ...);
+	// sampling and extrapolation follow
+	prs = github.FilterPRs(prs, opts.filter)
+
 	if len(prs) == 0 {
-		fmt.Printf("\nNo PRs modified in the last %d days\n", days)
+		switch {
+		case len(opts.filter.Paths) > 0:
+			fmt.Fprintf(progress, "\nNo PRs touching %s modified %s\n", strings.Join(opts.filter.Paths, ", "), opts.window)
+		case !opts.filter.IsZero():
+			fmt.Fprintf(progress, "\nNo PRs matching the label/author filter modified %s\n", opts.window)
+		default:
+			fmt.Fprintf(progress, "\nNo PRs modified %s\n", opts.window)
+		}
 		return nil
 	}
 
-	// Validate time coverage (logs statistics, always uses requested period)
-	actualDays, _ := github.CalculateActualTimeWindow(prs, days)
+	if opts.dryRun {
+		return printSamplePlan(opts.formatter, owner+"/"+repo, opts.window, github.PlanSample(prs, opts.sampleSize, days, until, opts.sampling), opts.format)
+	}
+
+	// Validate time coverage (busy repos can exhaust API limits before covering the full period)
+	actualDays, truncated := github.CalculateActualTimeWindow(prs, days, until)
+	if truncated {
+		printTruncationWarning(progress, days, actualDays)
+	}
 
 	// Count bot PRs before sampling
 	botPRCount := github.CountBotPRs(prs)
 	humanPRCount := len(prs) - botPRCount
 
-	// Sample PRs using time-bucket strategy (includes all PRs)
-	samples := github.SamplePRs(prs, sampleSize)
+	// Sample PRs using the time-bucket or weighted strategy (includes all PRs)
+	samples := opts.sampling.Sample(prs, opts.sampleSize)
+	if cp != nil {
+		samples = cp.sample(samples)
+	}
 
 	slog.Info("Sampled PRs for analysis",
 		"total_prs", len(prs),
 		"human_prs", humanPRCount,
 		"bot_prs", botPRCount,
 		"sample_size", len(samples),
-		"requested_samples", sampleSize)
+		"requested_samples", opts.sampleSize)
 
 	if botPRCount > 0 {
-		fmt.Printf("\nAnalyzing %d sampled PRs from %d total PRs (%d human, %d bot) modified in the last %d days...\n\n",
+		fmt.Fprintf(progress, "\nAnalyzing %d sampled PRs from %d total PRs (%d human, %d bot) modified in the last %d days...\n\n",
 			len(samples), len(prs), humanPRCount, botPRCount, actualDays)
 	} else {
-		fmt.Printf("\nAnalyzing %d sampled PRs from %d total PRs modified in the last %d days...\n\n",
+		fmt.Fprintf(progress, "\nAnalyzing %d sampled PRs from %d total PRs modified in the last %d days...\n\n",
 			len(samples), len(prs), actualDays)
 	}
 
 	// Convert samples to PRSummaryInfo format
 	var summaries []cost.PRSummaryInfo
 	for _, pr := range samples {
-		summaries = append(summaries, cost.PRSummaryInfo{
-			Owner:     pr.Owner,
-			Repo:      pr.Repo,
-			Number:    pr.Number,
-			UpdatedAt: pr.UpdatedAt,
-		})
+		summaries = append(summaries, pr.Info())
 	}
 
-	// Create fetcher
-	fetcher := &github.SimpleFetcher{
-		Token:      token,
-		DataSource: dataSource,
+	// Create fetcher, serving already-fetched PRs from the checkpoint when resuming
+	prFetcher := &github.SimpleFetcher{
+		Token:      opts.token,
+		API:        opts.api,
+		DataSource: opts.dataSource,
+	}
+	var fetcher cost.PRFetcher = prFetcher
+	if cp != nil {
+		fetcher = cp.fetcher(fetcher)
 	}
 
 	// Analyze PRs using shared code path
@@ -80,9 +179,16 @@ func analyzeRepository(ctx context.Context, owner, repo string, sampleSize, days
 		Samples:     summaries,
 		Logger:      slog.Default(),
 		Fetcher:     fetcher,
-		Concurrency: 8, // Process up to 8 PRs concurrently
-		Config:      cfg,
+		Concurrency: opts.concurrency,
+		Config:      opts.cfg,
+		Host:        github.Host(ctx),
 	})
+	logCacheStats(prFetcher)
+	if cp != nil {
+		if err := cp.save(); err != nil {
+			slog.Warn("Failed to save checkpoint", "path", opts.checkpointPath, "error", err)
+		}
+	}
 	if err != nil {
 		return err
 	}
@@ -93,33 +199,65 @@ func analyzeRepository(ctx context.Context, owner, repo string, sampleSize, days
 	totalAuthors := github.CountUniqueAuthors(prs)
 
 	// Query for actual count of open PRs (not extrapolated from samples)
-	openPRCount, err := github.CountOpenPRsInRepo(ctx, owner, repo, token)
-	if err != nil {
-		slog.Warn("Failed to count open PRs, using 0", "error", err)
-		openPRCount = 0
+	// With a PR filter, only open PRs in the filtered pool are relevant
+	var openPRCount int
+	if !opts.filter.IsZero() {
+		openPRCount = github.CountOpenPRs(prs, until)
+	} else {
+		openPRCount, err = github.CountOpenPRsInRepo(ctx, owner, repo, github.PRQuery{Until: until, Token: opts.token, API: opts.api})
+		if err != nil {
+			slog.Warn("Failed to count open PRs, using 0", "error", err)
+			openPRCount = 0
+		}
 	}
 
 	// Convert PRSummary to PRSummaryInfo for extrapolation
 	prSummaryInfos := make([]cost.PRSummaryInfo, len(prs))
 	for i, pr := range prs {
-		prSummaryInfos[i] = cost.PRSummaryInfo{
-			Owner:      pr.Owner,
-			Repo:       pr.Repo,
-			Author:     pr.Author,
-			AuthorType: pr.AuthorType,
-			CreatedAt:  pr.CreatedAt,
-			UpdatedAt:  pr.UpdatedAt,
-			ClosedAt:   pr.ClosedAt,
-			Merged:     pr.Merged,
-			State:      pr.State,
-		}
+		prSummaryInfos[i] = pr.Info()
 	}
 
 	// Extrapolate costs from samples using library function (pass nil for visibility since single-repo = public)
-	extrapolated := cost.ExtrapolateFromSamples(breakdowns, len(prs), totalAuthors, openPRCount, actualDays, cfg, prSummaryInfos, nil)
+	extrapolated := cost.ExtrapolateFromSamplesUntil(breakdowns, len(prs), totalAuthors, openPRCount, actualDays, opts.cfg, prSummaryInfos, nil, opts.window.until)
+	if !opts.callout {
+		extrapolated.R2RSavings = 0
+	}
+	if opts.includeSamples {
+		extrapolated.Samples = cost.SampleBreakdowns(result.URLs, breakdowns)
+	}
+	names := anonymizer(opts.formatter, breakdowns)
+	names.Extrapolated(&extrapolated)
+
+	if opts.format == "csv" {
+		for i := range breakdowns {
+			names.Breakdown(&breakdowns[i])
+		}
+		label := fmt.Sprintf("total: %s (extrapolated, %d days)", owner+"/"+repo, actualDays)
+		return writeBreakdownsCSV(opts.formatter.w, result.URLs, breakdowns, extrapolatedCSVRow(label, &extrapolated))
+	}
+	// Re-cost the same samples under each what-if scenario (no re-fetch needed)
+	var scenarioResults []cost.ScenarioResult
+	if len(opts.scenarios) > 0 {
+		all := append([]cost.Scenario{{Name: "baseline", Config: opts.cfg}}, opts.scenarios...)
+		scenarioResults = cost.ExtrapolateScenarios(result.Data, all, len(prs), totalAuthors, openPRCount, actualDays, prSummaryInfos, nil)
+	}
+	for i := range scenarioResults {
+		names.Extrapolated(&scenarioResults[i].Extrapolated)
+	}
+
+	title := opts.window.title(fmt.Sprintf("%s/%s", owner, repo))
+	if opts.format == "json" {
+		return writeJSON(opts.formatter, newExtrapolatedReport(title, actualDays, days, &extrapolated, scenarioResults))
+	}
+	if opts.format == "markdown" {
+		return writeExtrapolatedMarkdown(opts.formatter.w, opts.formatter, title, actualDays, days, &extrapolated, scenarioResults)
+	}
 
 	// Display results in itemized format
-	printExtrapolatedResults(fmt.Sprintf("%s/%s", owner, repo), actualDays, &extrapolated, cfg)
+	printExtrapolatedResults(opts.formatter, title, actualDays, days, &extrapolated, opts.cfg, opts.callout)
+	if len(scenarioResults) > 0 {
+		printScenarioComparison(opts.formatter, scenarioResults)
+	}
 
 	return nil
 }
@@ -127,14 +265,27 @@ func analyzeRepository(ctx context.Context, owner, repo string, sampleSize, days
 // analyzeOrganization performs organization-wide cost analysis by sampling PRs across all repos.
 // Uses library functions from pkg/github and pkg/cost for fetching, sampling,
 // and extrapolation - all functionality is available to external clients.
-func analyzeOrganization(ctx context.Context, org string, sampleSize, days int, cfg cost.Config, token, dataSource string) error {
+func analyzeOrganization(ctx context.Context, org string, opts analysisOptions) error {
 	slog.Info("Fetching PR list from organization")
 
-	// Calculate since date
-	since := time.Now().AddDate(0, 0, -days)
+	// Progress messages go to stderr when stdout carries CSV or JSON
+	progress := progressWriter(opts.formatter, opts.format)
+
+	since, until, days := opts.window.since, opts.window.until, opts.window.days
+
+	// Open the checkpoint first, so one left by a different scan fails before any fetching
+	var cp *checkpoint
+	if opts.checkpointPath != "" && !opts.dryRun {
+		var err error
+		if cp, err = openCheckpoint(opts.checkpointPath, checkpointScope("org "+org, opts.window, opts.sampleSize, opts.filter)); err != nil {
+			return err
+		}
+	}
 
 	// Fetch all PRs across the org modified since the date using library function
-	prs, err := github.FetchPRsFromOrg(ctx, org, since, token, nil)
+	prs, err := github.FetchPRsFromOrg(ctx, org, github.PRQuery{
+		Since: since, Until: until, State: opts.filter.State, Author: opts.filter.Author, Token: opts.token, API: opts.api,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to fetch PRs: %w", err)
 	}
@@ -143,51 +294,88 @@ func analyzeOrganization(ctx context.Context, org string, sampleSize, days int,
 		"total_prs", len(prs),
 		"since", since.Format("2006-01-02"))
 
+	// Restrict the pool to PRs matching the filter (paths, labels, author, ...);
+	// sampling and extrapolation follow
+	prs = github.FilterPRs(prs, opts.filter)
+
 	if len(prs) == 0 {
-		fmt.Printf("\nNo PRs modified in the last %d days\n", days)
+		switch {
+		case len(opts.filter.Paths) > 0:
+			fmt.Fprintf(progress, "\nNo PRs touching %s modified %s\n", strings.Join(opts.filter.Paths, ", "), opts.window)
+		case !opts.filter.IsZero():
+			fmt.Fprintf(progress, "\nNo PRs matching the label/author filter modified %s\n", opts.window)
+		default:
+			fmt.Fprintf(progress, "\nNo PRs modified %s\n", opts.window)
+		}
 		return nil
 	}
 
-	// Validate time coverage (logs statistics, always uses requested period)
-	actualDays, _ := github.CalculateActualTimeWindow(prs, days)
+	if opts.dryRun {
+		return printSamplePlan(opts.formatter, org, opts.window, github.PlanSample(prs, opts.sampleSize, days, until, opts.sampling), opts.format)
+	}
+
+	// Validate time coverage (busy repos can exhaust API limits before covering the full period)
+	actualDays, truncated := github.CalculateActualTimeWindow(prs, days, until)
+	if truncated {
+		printTruncationWarning(progress, days, actualDays)
+	}
 
 	// Count bot PRs before sampling
 	botPRCount := github.CountBotPRs(prs)
 	humanPRCount := len(prs) - botPRCount
 
-	// Sample PRs using time-bucket strategy (includes all PRs)
-	samples := github.SamplePRs(prs, sampleSize)
+	// Sample PRs using the time-bucket or weighted strategy (includes all PRs)
+	samples := opts.sampling.Sample(prs, opts.sampleSize)
+	if cp != nil {
+		samples = cp.sample(samples)
+	}
 
 	slog.Info("Sampled PRs for analysis",
 		"total_prs", len(prs),
 		"human_prs", humanPRCount,
 		"bot_prs", botPRCount,
 		"sample_size", len(samples),
-		"requested_samples", sampleSize)
+		"requested_samples", opts.sampleSize)
 
 	if botPRCount > 0 {
-		fmt.Printf("\nAnalyzing %d sampled PRs from %d total PRs (%d human, %d bot) across %s (last %d days)...\n\n",
+		fmt.Fprintf(progress, "\nAnalyzing %d sampled PRs from %d total PRs (%d human, %d bot) across %s (last %d days)...\n\n",
 			len(samples), len(prs), humanPRCount, botPRCount, org, actualDays)
 	} else {
-		fmt.Printf("\nAnalyzing %d sampled PRs from %d total PRs across %s (last %d days)...\n\n",
+		fmt.Fprintf(progress, "\nAnalyzing %d sampled PRs from %d total PRs across %s (last %d days)...\n\n",
 			len(samples), len(prs), org, actualDays)
 	}
 
 	// Convert samples to PRSummaryInfo format
 	var summaries []cost.PRSummaryInfo
 	for _, pr := range samples {
-		summaries = append(summaries, cost.PRSummaryInfo{
-			Owner:     pr.Owner,
-			Repo:      pr.Repo,
-			Number:    pr.Number,
-			UpdatedAt: pr.UpdatedAt,
-		})
+		summaries = append(summaries, pr.Info())
 	}
 
-	// Create fetcher
-	fetcher := &github.SimpleFetcher{
-		Token:      token,
-		DataSource: dataSource,
+	// Count open PRs across the entire organization with a single query, falling back to
+	// counting repo-by-repo if it fails. With a PR filter, only open PRs in the filtered pool
+	// are relevant. Counting runs alongside PR fetching; with --github-rate, both draw from
+	// the same rate budget
+	countCtx, cancelCount := context.WithCancel(ctx)
+	defer cancelCount() // Stops counting if the analysis fails
+	openCounted := make(chan github.OpenPRCount, 1)
+	go func() {
+		if !opts.filter.IsZero() {
+			openCounted <- github.OpenPRCount{Count: github.CountOpenPRs(prs, until)}
+			return
+		}
+		openCounted <- github.CountOpenPRsAcrossOrg(countCtx, org, prs, opts.concurrency,
+			github.PRQuery{Until: until, Token: opts.token, API: opts.api})
+	}()
+
+	// Create fetcher, serving already-fetched PRs from the checkpoint when resuming
+	prFetcher := &github.SimpleFetcher{
+		Token:      opts.token,
+		API:        opts.api,
+		DataSource: opts.dataSource,
+	}
+	var fetcher cost.PRFetcher = prFetcher
+	if cp != nil {
+		fetcher = cp.fetcher(fetcher)
 	}
 
 	// Analyze PRs using shared code path
@@ -195,9 +383,16 @@ func analyzeOrganization(ctx context.Context, org string, sampleSize, days int,
 		Samples:     summaries,
 		Logger:      slog.Default(),
 		Fetcher:     fetcher,
-		Concurrency: 8, // Process up to 8 PRs concurrently
-		Config:      cfg,
+		Concurrency: opts.concurrency,
+		Config:      opts.cfg,
+		Host:        github.Host(ctx),
 	})
+	logCacheStats(prFetcher)
+	if cp != nil {
+		if err := cp.save(); err != nil {
+			slog.Warn("Failed to save checkpoint", "path", opts.checkpointPath, "error", err)
+		}
+	}
 	if err != nil {
 		return err
 	}
@@ -207,57 +402,197 @@ func analyzeOrganization(ctx context.Context, org string, sampleSize, days int,
 	// Count unique authors across all PRs (not just samples)
 	totalAuthors := github.CountUniqueAuthors(prs)
 
-	// Count open PRs across the entire organization with a single query
-	totalOpenPRs, err := github.CountOpenPRsInOrg(ctx, org, token)
-	if err != nil {
-		slog.Warn("Failed to count open PRs in organization, using 0", "error", err)
-		totalOpenPRs = 0
-	}
-	slog.Info("Counted total open PRs across organization", "org", org, "open_prs", totalOpenPRs)
+	openCount := <-openCounted
+	totalOpenPRs := openCount.Count
+	slog.Info("Counted total open PRs across organization", "org", org, "open_prs", totalOpenPRs, "failed_repos", openCount.FailedRepos, "partial", openCount.Partial)
 
 	// Convert PRSummary to PRSummaryInfo for extrapolation
 	prSummaryInfos := make([]cost.PRSummaryInfo, len(prs))
 	for i, pr := range prs {
-		prSummaryInfos[i] = cost.PRSummaryInfo{
-			Owner:      pr.Owner,
-			Repo:       pr.Repo,
-			Author:     pr.Author,
-			AuthorType: pr.AuthorType,
-			CreatedAt:  pr.CreatedAt,
-			UpdatedAt:  pr.UpdatedAt,
-			ClosedAt:   pr.ClosedAt,
-			Merged:     pr.Merged,
-			State:      pr.State,
-		}
+		prSummaryInfos[i] = pr.Info()
 	}
 
 	// Extrapolate costs from samples using library function (CLI doesn't fetch visibility, assume public)
-	extrapolated := cost.ExtrapolateFromSamples(breakdowns, len(prs), totalAuthors, totalOpenPRs, actualDays, cfg, prSummaryInfos, nil)
+	extrapolated := cost.ExtrapolateFromSamplesUntil(breakdowns, len(prs), totalAuthors, totalOpenPRs, actualDays, opts.cfg, prSummaryInfos, nil, opts.window.until)
+	extrapolated.OpenPRsFailedRepos = openCount.FailedRepos
+	extrapolated.OpenPRsPartial = openCount.Partial
+	if !opts.callout {
+		extrapolated.R2RSavings = 0
+	}
+	if opts.includeSamples {
+		extrapolated.Samples = cost.SampleBreakdowns(result.URLs, breakdowns)
+	}
+	names := anonymizer(opts.formatter, breakdowns)
+	names.Extrapolated(&extrapolated)
+
+	if opts.format == "csv" {
+		for i := range breakdowns {
+			names.Breakdown(&breakdowns[i])
+		}
+		label := fmt.Sprintf("total: %s (extrapolated, %d days)", org, actualDays)
+		return writeBreakdownsCSV(opts.formatter.w, result.URLs, breakdowns, extrapolatedCSVRow(label, &extrapolated))
+	}
+	// Re-cost the same samples under each what-if scenario (no re-fetch needed)
+	var scenarioResults []cost.ScenarioResult
+	if len(opts.scenarios) > 0 {
+		all := append([]cost.Scenario{{Name: "baseline", Config: opts.cfg}}, opts.scenarios...)
+		scenarioResults = cost.ExtrapolateScenarios(result.Data, all, len(prs), totalAuthors, totalOpenPRs, actualDays, prSummaryInfos, nil)
+	}
+	for i := range scenarioResults {
+		names.Extrapolated(&scenarioResults[i].Extrapolated)
+	}
+
+	scope := fmt.Sprintf("%s (organization)", org)
+	if opts.filter.Author != "" {
+		scope = fmt.Sprintf("PRs by %s in %s (organization)", opts.filter.Author, org)
+	}
+	title := opts.window.title(scope)
+	if opts.format == "json" {
+		return writeJSON(opts.formatter, newExtrapolatedReport(title, actualDays, days, &extrapolated, scenarioResults))
+	}
+	if opts.format == "markdown" {
+		return writeExtrapolatedMarkdown(opts.formatter.w, opts.formatter, title, actualDays, days, &extrapolated, scenarioResults)
+	}
 
 	// Display results in itemized format
-	printExtrapolatedResults(fmt.Sprintf("%s (organization)", org), actualDays, &extrapolated, cfg)
+	printExtrapolatedResults(opts.formatter, title, actualDays, days, &extrapolated, opts.cfg, opts.callout)
+	if len(scenarioResults) > 0 {
+		printScenarioComparison(opts.formatter, scenarioResults)
+	}
+
+	return nil
+}
+
+// extrapolatedReport is the JSON output of repo and org analysis: every ExtrapolatedBreakdown
+// field at the top level, plus what was analyzed and any what-if scenarios.
+type extrapolatedReport struct {
+	SchemaVersion string `json:"schema_version"` // cost.SchemaVersion
+
+	*cost.ExtrapolatedBreakdown
+
+	Title         string                `json:"title"`
+	RequestedDays int                   `json:"requested_days"`
+	ActualDays    int                   `json:"actual_days"`
+	Truncated     bool                  `json:"truncated"` // API limits shortened the window to ActualDays
+	Scenarios     []cost.ScenarioResult `json:"scenarios,omitempty"`
+}
+
+// newExtrapolatedReport builds the JSON output for an analysis of title over actualDays of requestedDays.
+func newExtrapolatedReport(title string, actualDays, requestedDays int, ext *cost.ExtrapolatedBreakdown,
+	scenarios []cost.ScenarioResult,
+) *extrapolatedReport {
+	return &extrapolatedReport{
+		SchemaVersion:         cost.SchemaVersion,
+		ExtrapolatedBreakdown: ext,
+		Title:                 title,
+		RequestedDays:         requestedDays,
+		ActualDays:            actualDays,
+		Truncated:             actualDays < requestedDays,
+		Scenarios:             scenarios,
+	}
+}
+
+// printSamplePlan prints the PRs a sampled analysis of target over window would fetch (--dry-run).
+func printSamplePlan(f *formatter, target string, window analysisWindow, plan github.SamplePlan, format string) error {
+	if f.anonymize {
+		plan = plan.Anonymized()
+	}
+	if format == "json" {
+		return writeJSON(f, &plan)
+	}
+
+	fmt.Fprintf(f.w, "Dry run: %s\n", target)
+	fmt.Fprintf(f.w, "  %d PRs modified %s (%d human, %d bot)\n",
+		plan.TotalPRs, window, plan.HumanPRs, plan.BotPRs)
+	if plan.Truncated {
+		fmt.Fprintf(f.w, "  ⚠️  GitHub API limits truncated the window to its last %d days\n", plan.ActualDays)
+	}
+	fmt.Fprintf(f.w, "  %d PRs would be sampled; a full run fetches PR data for each (before caching)\n\n", plan.PRFetches)
 
+	fmt.Fprintf(f.w, "  %-40s  %-24s  %s\n", "PR", "Author", "Updated")
+	for _, pr := range plan.Samples {
+		author := pr.Author
+		if pr.Bot {
+			author += " (bot)"
+		}
+		fmt.Fprintf(f.w, "  %-40s  %-24s  %s\n",
+			fmt.Sprintf("%s/%s#%d", pr.Owner, pr.Repo, pr.Number), author, pr.UpdatedAt.Format("2006-01-02 15:04"))
+	}
 	return nil
 }
 
+// openPRsLabel describes ext's open PR count, marking it as a lower bound when some
+// repositories could not be counted.
+func openPRsLabel(ext *cost.ExtrapolatedBreakdown) string {
+	switch {
+	case ext.OpenPRsFailedRepos > 0:
+		return fmt.Sprintf("(at least %d open PRs; %d repos not counted)", ext.OpenPRs, ext.OpenPRsFailedRepos)
+	case ext.OpenPRsPartial:
+		return fmt.Sprintf("(at least %d open PRs; only repos with PRs in the window counted)", ext.OpenPRs)
+	default:
+		return fmt.Sprintf("(%d open PRs)", ext.OpenPRs)
+	}
+}
+
+// printTruncationWarning warns that API limits shortened the analysis window,
+// since every total and annualized projection is based on the shorter window.
+func printTruncationWarning(w io.Writer, requestedDays, actualDays int) {
+	fmt.Fprintf(w, "\n  Warning: GitHub API limits truncated the analysis window to the last %d days (requested %d).\n",
+		actualDays, requestedDays)
+	fmt.Fprint(w, "  Totals and annualized projections are based on the shorter window; use --days to narrow the request.\n")
+}
+
+// formatPeriod describes the analyzed period, noting when it was truncated from the requested one.
+func formatPeriod(days, requestedDays int) string {
+	if days < requestedDays {
+		return fmt.Sprintf("Last %d days (truncated from %d by API limits)", days, requestedDays)
+	}
+	return fmt.Sprintf("Last %d days", days)
+}
+
+// logCacheStats logs how many PR data fetches the disk cache served, for gauging API usage.
+func logCacheStats(f *github.SimpleFetcher) {
+	if hits, misses := f.CacheStats(); hits+misses > 0 {
+		slog.Info("Cache: " + cacheSummary(hits, misses))
+	}
+}
+
+// cacheSummary describes cache effectiveness, e.g. "43 hits, 112 misses, 27.7% hit rate".
+func cacheSummary(hits, misses int) string {
+	rate := 0.0
+	if total := hits + misses; total > 0 {
+		rate = float64(hits) / float64(total) * 100
+	}
+	return fmt.Sprintf("%d hits, %d misses, %.1f%% hit rate", hits, misses, rate)
+}
+
+// progressWriter returns where status messages are printed alongside f's results in the given
+// output format. They stay on the terminal when results are written to a file with --output.
+func progressWriter(f *formatter, format string) io.Writer {
+	if f.toFile || format == "csv" || format == "json" || format == "markdown" {
+		return os.Stderr
+	}
+	return f.w
+}
+
 // Ledger formatting functions - all output must use these for consistency.
 
 // formatItemLine formats a cost breakdown line item with 4-space indent.
-func formatItemLine(label string, amount float64, timeUnit string, detail string) string {
+func formatItemLine(f *formatter, label string, amount float64, timeUnit string, detail string) string {
 	if amount == 0 {
 		return fmt.Sprintf("    %-30s %15s    %-6s  %s\n", label, "—", timeUnit, detail)
 	}
-	return fmt.Sprintf("    %-30s $%14s    %-6s  %s\n", label, formatWithCommas(amount), timeUnit, detail)
+	return fmt.Sprintf("    %-30s %s    %-6s  %s\n", label, f.column(amount), timeUnit, detail)
 }
 
 // formatSubtotalLine formats a subtotal line with 4-space indent.
-func formatSubtotalLine(amount float64, timeUnit string, detail string) string {
-	return fmt.Sprintf("    %-30s $%14s    %-6s  %s\n", "Subtotal", formatWithCommas(amount), timeUnit, detail)
+func formatSubtotalLine(f *formatter, amount float64, timeUnit string, detail string) string {
+	return fmt.Sprintf("    %-30s %s    %-6s  %s\n", "Subtotal", f.column(amount), timeUnit, detail)
 }
 
 // formatSummaryLine formats a summary line (like Preventable Loss Total) with 2-space indent.
-func formatSummaryLine(label string, amount float64, timeUnit string, detail string) string {
-	return fmt.Sprintf("  %-30s $%14s    %-6s  %s\n", label, formatWithCommas(amount), timeUnit, detail)
+func formatSummaryLine(f *formatter, label string, amount float64, timeUnit string, detail string) string {
+	return fmt.Sprintf("  %-30s %s    %-6s  %s\n", label, f.column(amount), timeUnit, detail)
 }
 
 // formatSectionDivider formats the divider line under subtotals (4-space indent, 32 chars + 14 dashes).
@@ -303,62 +638,97 @@ func formatTimeUnit(hours float64) string {
 	return fmt.Sprintf("%.1fy", years)
 }
 
+// printDurationHistogram draws an ASCII bar chart of sampled PR open times, scaled to the busiest
+// bucket. Bars are weighted by each sample's weight, so they show the population's distribution.
+func printDurationHistogram(w io.Writer, buckets []cost.DurationBucket) {
+	const maxBarWidth = 30
+	var most float64
+	for _, b := range buckets {
+		most = max(most, b.Weighted)
+	}
+	if most == 0 {
+		return
+	}
+	fmt.Fprintln(w, "  Open Time Distribution (sampled PRs):")
+	for _, b := range buckets {
+		bar := strings.Repeat("█", int(b.Weighted*maxBarWidth/most))
+		if bar == "" && b.Count > 0 {
+			bar = "▏" // Keep small non-zero buckets visible
+		}
+		fmt.Fprintf(w, "    %-6s %-*s %d\n", b.Label, maxBarWidth, bar, b.Count)
+	}
+}
+
 // printExtrapolatedResults displays extrapolated cost breakdown in itemized format.
 //
 //nolint:maintidx,revive // acceptable complexity/length for comprehensive display function
-func printExtrapolatedResults(title string, days int, ext *cost.ExtrapolatedBreakdown, cfg cost.Config) {
-	fmt.Println()
-	fmt.Printf("  %s\n", title)
+func printExtrapolatedResults(f *formatter, title string, days, requestedDays int, ext *cost.ExtrapolatedBreakdown, cfg cost.Config, callout bool) {
+	fmt.Fprintln(f.w)
+	fmt.Fprintf(f.w, "  %s\n", title)
 	avgOpenTime := formatTimeUnit(ext.AvgPRDurationHours)
+	// Percentiles come from the sample, so they're omitted when nothing was sampled
+	var percentiles string
+	if ext.SuccessfulSamples > 0 {
+		percentiles = fmt.Sprintf("  •  p50: %s, p90: %s", formatTimeUnit(ext.P50PRDurationHours), formatTimeUnit(ext.P90PRDurationHours))
+	}
 
 	// Show human/bot breakdown if there are bot PRs
 	if ext.BotPRs > 0 {
 		avgHumanOpenTime := formatTimeUnit(ext.AvgHumanPRDurationHours)
 		avgBotOpenTime := formatTimeUnit(ext.AvgBotPRDurationHours)
-		fmt.Printf("  Period: Last %d days  •  Total PRs: %d (%d human, %d bot)  •  Authors: %d  •  Sampled: %d\n",
-			days, ext.TotalPRs, ext.HumanPRs, ext.BotPRs, ext.TotalAuthors, ext.SuccessfulSamples)
-		fmt.Printf("  Avg Open Time: %s (human: %s, bot: %s)\n", avgOpenTime, avgHumanOpenTime, avgBotOpenTime)
+		fmt.Fprintf(f.w, "  Period: %s  •  Total PRs: %d (%d human, %d bot)  •  Authors: %d  •  Sampled: %d\n",
+			formatPeriod(days, requestedDays), ext.TotalPRs, ext.HumanPRs, ext.BotPRs, ext.TotalAuthors, ext.SuccessfulSamples)
+		fmt.Fprintf(f.w, "  Avg Open Time: %s (human: %s, bot: %s)%s\n", avgOpenTime, avgHumanOpenTime, avgBotOpenTime, percentiles)
 	} else {
-		fmt.Printf("  Period: Last %d days  •  Total PRs: %d  •  Authors: %d  •  Sampled: %d  •  Avg Open Time: %s\n",
-			days, ext.TotalPRs, ext.TotalAuthors, ext.SuccessfulSamples, avgOpenTime)
-	}
-	fmt.Println()
-
-	// Calculate average per PR
-	avgAuthorNewCodeCost := ext.AuthorNewCodeCost / float64(ext.TotalPRs)
-	avgAuthorAdaptationCost := ext.AuthorAdaptationCost / float64(ext.TotalPRs)
-	avgAuthorGitHubCost := ext.AuthorGitHubCost / float64(ext.TotalPRs)
-	avgAuthorGitHubContextCost := ext.AuthorGitHubContextCost / float64(ext.TotalPRs)
-	avgAuthorTotalCost := ext.AuthorTotalCost / float64(ext.TotalPRs)
-	avgAuthorNewCodeHours := ext.AuthorNewCodeHours / float64(ext.TotalPRs)
-	avgAuthorAdaptationHours := ext.AuthorAdaptationHours / float64(ext.TotalPRs)
-	avgAuthorGitHubHours := ext.AuthorGitHubHours / float64(ext.TotalPRs)
-	avgAuthorGitHubContextHours := ext.AuthorGitHubContextHours / float64(ext.TotalPRs)
-	avgAuthorTotalHours := ext.AuthorTotalHours / float64(ext.TotalPRs)
-
-	avgParticipantReviewCost := ext.ParticipantReviewCost / float64(ext.TotalPRs)
-	avgParticipantGitHubCost := ext.ParticipantGitHubCost / float64(ext.TotalPRs)
-	avgParticipantContextCost := ext.ParticipantContextCost / float64(ext.TotalPRs)
-	avgParticipantTotalCost := ext.ParticipantTotalCost / float64(ext.TotalPRs)
-	avgParticipantReviewHours := ext.ParticipantReviewHours / float64(ext.TotalPRs)
-	avgParticipantGitHubHours := ext.ParticipantGitHubHours / float64(ext.TotalPRs)
-	avgParticipantContextHours := ext.ParticipantContextHours / float64(ext.TotalPRs)
-	avgParticipantTotalHours := ext.ParticipantTotalHours / float64(ext.TotalPRs)
-
-	avgDeliveryDelayCost := ext.DeliveryDelayCost / float64(ext.TotalPRs)
-	avgCodeChurnCost := ext.CodeChurnCost / float64(ext.TotalPRs)
-	avgAutomatedUpdatesCost := ext.AutomatedUpdatesCost / float64(ext.TotalPRs)
-	avgPRTrackingCost := ext.PRTrackingCost / float64(ext.TotalPRs)
-	avgDeliveryDelayHours := ext.DeliveryDelayHours / float64(ext.TotalPRs)
-	avgCodeChurnHours := ext.CodeChurnHours / float64(ext.TotalPRs)
-	avgAutomatedUpdatesHours := ext.AutomatedUpdatesHours / float64(ext.TotalPRs)
-	avgPRTrackingHours := ext.PRTrackingHours / float64(ext.TotalPRs)
-
-	avgTotalCost := ext.TotalCost / float64(ext.TotalPRs)
-	avgTotalHours := ext.TotalHours / float64(ext.TotalPRs)
+		fmt.Fprintf(f.w, "  Period: %s  •  Total PRs: %d  •  Authors: %d  •  Sampled: %d  •  Avg Open Time: %s%s\n",
+			formatPeriod(days, requestedDays), ext.TotalPRs, ext.TotalAuthors, ext.SuccessfulSamples, avgOpenTime, percentiles)
+	}
+	printDurationHistogram(f.w, ext.DurationHistogram)
+	fmt.Fprintln(f.w)
+
+	// Calculate average per PR; a period without PRs averages to zero rather than NaN
+	perPR := func(total float64) float64 {
+		if ext.TotalPRs == 0 {
+			return 0
+		}
+		return total / float64(ext.TotalPRs)
+	}
+	avgAuthorNewCodeCost := perPR(ext.AuthorNewCodeCost)
+	avgAuthorAdaptationCost := perPR(ext.AuthorAdaptationCost)
+	avgAuthorGitHubCost := perPR(ext.AuthorGitHubCost)
+	avgAuthorGitHubContextCost := perPR(ext.AuthorGitHubContextCost)
+	avgAuthorTotalCost := perPR(ext.AuthorTotalCost)
+	avgAuthorNewCodeHours := perPR(ext.AuthorNewCodeHours)
+	avgAuthorAdaptationHours := perPR(ext.AuthorAdaptationHours)
+	avgAuthorGitHubHours := perPR(ext.AuthorGitHubHours)
+	avgAuthorGitHubContextHours := perPR(ext.AuthorGitHubContextHours)
+	avgAuthorTotalHours := perPR(ext.AuthorTotalHours)
+
+	avgParticipantReviewCost := perPR(ext.ParticipantReviewCost)
+	avgParticipantGitHubCost := perPR(ext.ParticipantGitHubCost)
+	avgParticipantContextCost := perPR(ext.ParticipantContextCost)
+	avgParticipantTotalCost := perPR(ext.ParticipantTotalCost)
+	avgParticipantReviewHours := perPR(ext.ParticipantReviewHours)
+	avgParticipantGitHubHours := perPR(ext.ParticipantGitHubHours)
+	avgParticipantContextHours := perPR(ext.ParticipantContextHours)
+	avgParticipantTotalHours := perPR(ext.ParticipantTotalHours)
+	avgParticipantReviewWaitCost := perPR(ext.ParticipantReviewWaitCost)
+	avgParticipantReviewWaitHours := perPR(ext.ParticipantReviewWaitHours)
+
+	avgDeliveryDelayCost := perPR(ext.DeliveryDelayCost)
+	avgCodeChurnCost := perPR(ext.CodeChurnCost)
+	avgAutomatedUpdatesCost := perPR(ext.AutomatedUpdatesCost)
+	avgPRTrackingCost := perPR(ext.PRTrackingCost)
+	avgDeliveryDelayHours := perPR(ext.DeliveryDelayHours)
+	avgCodeChurnHours := perPR(ext.CodeChurnHours)
+	avgAutomatedUpdatesHours := perPR(ext.AutomatedUpdatesHours)
+	avgPRTrackingHours := perPR(ext.PRTrackingHours)
+
+	avgTotalCost := perPR(ext.TotalCost)
+	avgTotalHours := perPR(ext.TotalHours)
 
 	// Show average PR breakdown with improved visual hierarchy
-	fmt.Println("  ┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(f.w, "  ┌─────────────────────────────────────────────────────────────┐")
 	headerText := fmt.Sprintf("Average PR (sampled over %d day period)", days)
 
 	// Box has 61 dashes, inner content area is 60 chars (1 space + 60 chars content)
@@ -366,62 +736,71 @@ func printExtrapolatedResults(title string, days int, ext *cost.ExtrapolatedBrea
 	if len(headerText) > innerWidth {
 		headerText = headerText[:innerWidth]
 	}
-	fmt.Printf("  │ %-60s│\n", headerText)
-	fmt.Println("  └─────────────────────────────────────────────────────────────┘")
-	fmt.Println()
+	fmt.Fprintf(f.w, "  │ %-60s│\n", headerText)
+	fmt.Fprintln(f.w, "  └─────────────────────────────────────────────────────────────┘")
+	fmt.Fprintln(f.w)
 
 	// Authors section
 	// Calculate total LOC for header
-	avgNewLOC := float64(ext.TotalNewLines) / float64(ext.TotalPRs) / 1000.0
-	avgModifiedLOC := float64(ext.TotalModifiedLines) / float64(ext.TotalPRs) / 1000.0
+	avgNewLOC := perPR(float64(ext.TotalNewLines)) / 1000.0
+	avgModifiedLOC := perPR(float64(ext.TotalModifiedLines)) / 1000.0
 	avgTotalLOC := avgNewLOC + avgModifiedLOC
 	totalLOCStr := formatLOC(avgTotalLOC)
 	newLOCStr := formatLOC(avgNewLOC)
 	modifiedLOCStr := formatLOC(avgModifiedLOC)
 
-	fmt.Printf("  Development Costs (%d PRs, %s)\n", ext.HumanPRs, totalLOCStr)
-	fmt.Println("  ────────────────────────────────────────")
+	fmt.Fprintf(f.w, "  Development Costs (%d PRs, %s)\n", ext.HumanPRs, totalLOCStr)
+	fmt.Fprintln(f.w, "  ────────────────────────────────────────")
 
 	// Calculate average events and sessions
-	avgAuthorEvents := float64(ext.AuthorEvents) / float64(ext.TotalPRs)
-	avgAuthorSessions := float64(ext.AuthorSessions) / float64(ext.TotalPRs)
-
-	fmt.Print(formatItemLine("New Development", avgAuthorNewCodeCost, formatTimeUnit(avgAuthorNewCodeHours), fmt.Sprintf("(%s)", newLOCStr)))
-	fmt.Print(formatItemLine("Adaptation", avgAuthorAdaptationCost, formatTimeUnit(avgAuthorAdaptationHours), fmt.Sprintf("(%s)", modifiedLOCStr)))
-	fmt.Print(formatItemLine("GitHub Activity", avgAuthorGitHubCost, formatTimeUnit(avgAuthorGitHubHours), fmt.Sprintf("(%.1f events)", avgAuthorEvents)))
-	fmt.Print(formatItemLine("Context Switching", avgAuthorGitHubContextCost, formatTimeUnit(avgAuthorGitHubContextHours), fmt.Sprintf("(%.1f sessions)", avgAuthorSessions)))
+	avgAuthorEvents := perPR(float64(ext.AuthorEvents))
+	avgAuthorSessions := perPR(float64(ext.AuthorSessions))
+
+	fmt.Fprint(f.w, formatItemLine(f, "New Development", avgAuthorNewCodeCost, formatTimeUnit(avgAuthorNewCodeHours), fmt.Sprintf("(%s)", newLOCStr)))
+	fmt.Fprint(f.w, formatItemLine(f, "Adaptation", avgAuthorAdaptationCost, formatTimeUnit(avgAuthorAdaptationHours), fmt.Sprintf("(%s)", modifiedLOCStr)))
+	fmt.Fprint(f.w, formatItemLine(f, "GitHub Activity", avgAuthorGitHubCost, formatTimeUnit(avgAuthorGitHubHours), fmt.Sprintf("(%.1f events)", avgAuthorEvents)))
+	fmt.Fprint(f.w, formatItemLine(f, "Context Switching", avgAuthorGitHubContextCost, formatTimeUnit(avgAuthorGitHubContextHours), fmt.Sprintf("(%.1f sessions)", avgAuthorSessions)))
+	if ext.AuthorConflictResolutionCost > 0 {
+		avgConflictCost := perPR(ext.AuthorConflictResolutionCost)
+		avgConflictHours := perPR(ext.AuthorConflictResolutionHours)
+		avgConflicts := perPR(float64(ext.ConflictResolutions))
+		fmt.Fprint(f.w, formatItemLine(f, "Conflict Resolution", avgConflictCost, formatTimeUnit(avgConflictHours), fmt.Sprintf("(%.1f conflicts)", avgConflicts)))
+	}
 
 	// Show bot PR LOC even though cost is $0
 	if ext.BotPRs > 0 {
-		avgBotTotalLOC := float64(ext.BotNewLines+ext.BotModifiedLines) / float64(ext.TotalPRs) / 1000.0
+		avgBotTotalLOC := perPR(float64(ext.BotNewLines+ext.BotModifiedLines)) / 1000.0
 		botLOCStr := formatLOC(avgBotTotalLOC)
-		fmt.Print(formatItemLine("Automated Updates", 0, formatTimeUnit(0.0), fmt.Sprintf("(%d PRs, %s)", ext.BotPRs, botLOCStr)))
+		fmt.Fprint(f.w, formatItemLine(f, "Automated Updates", 0, formatTimeUnit(0.0), fmt.Sprintf("(%d PRs, %s)", ext.BotPRs, botLOCStr)))
 	}
-	fmt.Print(formatSectionDivider())
-	pct := (avgAuthorTotalCost / avgTotalCost) * 100
-	fmt.Print(formatSubtotalLine(avgAuthorTotalCost, formatTimeUnit(avgAuthorTotalHours), fmt.Sprintf("(%.1f%%)", pct)))
-	fmt.Println()
+	fmt.Fprint(f.w, formatSectionDivider())
+	pct := percentOf(avgAuthorTotalCost, avgTotalCost)
+	fmt.Fprint(f.w, formatSubtotalLine(f, avgAuthorTotalCost, formatTimeUnit(avgAuthorTotalHours), fmt.Sprintf("(%.1f%%)", pct)))
+	fmt.Fprintln(f.w)
 
 	// Participants section (if any participants)
 	if ext.ParticipantTotalCost > 0 {
-		avgParticipantEvents := float64(ext.ParticipantEvents) / float64(ext.TotalPRs)
-		avgParticipantSessions := float64(ext.ParticipantSessions) / float64(ext.TotalPRs)
+		avgParticipantEvents := perPR(float64(ext.ParticipantEvents))
+		avgParticipantSessions := perPR(float64(ext.ParticipantSessions))
 
-		avgParticipantReviews := float64(ext.ParticipantReviews) / float64(ext.TotalPRs)
+		avgParticipantReviews := perPR(float64(ext.ParticipantReviews))
 
-		fmt.Println("  Participant Costs")
-		fmt.Println("  ─────────────────")
+		fmt.Fprintln(f.w, "  Participant Costs")
+		fmt.Fprintln(f.w, "  ─────────────────")
 		if avgParticipantReviewCost > 0 {
-			fmt.Print(formatItemLine("Review Activity", avgParticipantReviewCost, formatTimeUnit(avgParticipantReviewHours), fmt.Sprintf("(%.1f reviews)", avgParticipantReviews)))
+			fmt.Fprint(f.w, formatItemLine(f, "Review Activity", avgParticipantReviewCost, formatTimeUnit(avgParticipantReviewHours), fmt.Sprintf("(%.1f reviews)", avgParticipantReviews)))
 		}
 		if avgParticipantGitHubCost > 0 {
-			fmt.Print(formatItemLine("GitHub Activity", avgParticipantGitHubCost, formatTimeUnit(avgParticipantGitHubHours), fmt.Sprintf("(%.1f events)", avgParticipantEvents)))
+			fmt.Fprint(f.w, formatItemLine(f, "GitHub Activity", avgParticipantGitHubCost, formatTimeUnit(avgParticipantGitHubHours), fmt.Sprintf("(%.1f events)", avgParticipantEvents)))
+		}
+		if avgParticipantReviewWaitCost > 0 {
+			fmt.Fprint(f.w, formatItemLine(f, "Review Wait", avgParticipantReviewWaitCost, formatTimeUnit(avgParticipantReviewWaitHours), "(author waiting on reviewers)"))
 		}
-		fmt.Print(formatItemLine("Context Switching", avgParticipantContextCost, formatTimeUnit(avgParticipantContextHours), fmt.Sprintf("(%.1f sessions)", avgParticipantSessions)))
-		fmt.Print(formatSectionDivider())
-		participantPct := (avgParticipantTotalCost / avgTotalCost) * 100
-		fmt.Print(formatSubtotalLine(avgParticipantTotalCost, formatTimeUnit(avgParticipantTotalHours), fmt.Sprintf("(%.1f%%)", participantPct)))
-		fmt.Println()
+		fmt.Fprint(f.w, formatItemLine(f, "Context Switching", avgParticipantContextCost, formatTimeUnit(avgParticipantContextHours), fmt.Sprintf("(%.1f sessions)", avgParticipantSessions)))
+		fmt.Fprint(f.w, formatSectionDivider())
+		participantPct := percentOf(avgParticipantTotalCost, avgTotalCost)
+		fmt.Fprint(f.w, formatSubtotalLine(f, avgParticipantTotalCost, formatTimeUnit(avgParticipantTotalHours), fmt.Sprintf("(%.1f%%)", participantPct)))
+		fmt.Fprintln(f.w)
 	}
 
 	// Delay Costs section
@@ -432,91 +811,91 @@ func printExtrapolatedResults(title string, days int, ext *cost.ExtrapolatedBrea
 		delayCostsHeader += fmt.Sprintf(", bot PRs avg %s", avgBotOpenTime)
 	}
 	delayCostsHeader += ")"
-	fmt.Println(delayCostsHeader)
-	fmt.Println("  " + strings.Repeat("─", len(delayCostsHeader)-2))
+	fmt.Fprintln(f.w, delayCostsHeader)
+	fmt.Fprintln(f.w, "  "+strings.Repeat("─", len(delayCostsHeader)-2))
 	if avgDeliveryDelayCost > 0 {
-		fmt.Print(formatItemLine("Workstream blockage", avgDeliveryDelayCost, formatTimeUnit(avgDeliveryDelayHours), fmt.Sprintf("(%d PRs)", ext.HumanPRs)))
+		fmt.Fprint(f.w, formatItemLine(f, "Workstream blockage", avgDeliveryDelayCost, formatTimeUnit(avgDeliveryDelayHours), fmt.Sprintf("(%d PRs)", ext.HumanPRs)))
 	}
 	if avgAutomatedUpdatesCost > 0 {
-		fmt.Print(formatItemLine("Automated Updates", avgAutomatedUpdatesCost, formatTimeUnit(avgAutomatedUpdatesHours), fmt.Sprintf("(%d PRs)", ext.BotPRs)))
+		fmt.Fprint(f.w, formatItemLine(f, "Automated Updates", avgAutomatedUpdatesCost, formatTimeUnit(avgAutomatedUpdatesHours), fmt.Sprintf("(%d PRs)", ext.BotPRs)))
 	}
 	if avgPRTrackingCost > 0 {
-		fmt.Print(formatItemLine("PR Tracking", avgPRTrackingCost, formatTimeUnit(avgPRTrackingHours), fmt.Sprintf("(%d open PRs)", ext.OpenPRs)))
+		fmt.Fprint(f.w, formatItemLine(f, "PR Tracking", avgPRTrackingCost, formatTimeUnit(avgPRTrackingHours), openPRsLabel(ext)))
 	}
 	avgMergeDelayCost := avgDeliveryDelayCost + avgAutomatedUpdatesCost + avgPRTrackingCost
 	avgMergeDelayHours := avgDeliveryDelayHours + avgAutomatedUpdatesHours + avgPRTrackingHours
-	fmt.Print(formatSectionDivider())
-	pct = (avgMergeDelayCost / avgTotalCost) * 100
-	fmt.Print(formatSubtotalLine(avgMergeDelayCost, formatTimeUnit(avgMergeDelayHours), fmt.Sprintf("(%.1f%%)", pct)))
-	fmt.Println()
+	fmt.Fprint(f.w, formatSectionDivider())
+	pct = percentOf(avgMergeDelayCost, avgTotalCost)
+	fmt.Fprint(f.w, formatSubtotalLine(f, avgMergeDelayCost, formatTimeUnit(avgMergeDelayHours), fmt.Sprintf("(%.1f%%)", pct)))
+	fmt.Fprintln(f.w)
 
 	// Preventable Future Costs section
 	if avgCodeChurnCost > 0 {
-		fmt.Println("  Preventable Future Costs")
-		fmt.Println("  ────────────────────────")
-		fmt.Print(formatItemLine("Rework due to churn", avgCodeChurnCost, formatTimeUnit(avgCodeChurnHours), fmt.Sprintf("(%d PRs)", ext.CodeChurnPRCount)))
-		fmt.Print(formatSectionDivider())
-		pct = (avgCodeChurnCost / avgTotalCost) * 100
-		fmt.Print(formatSubtotalLine(avgCodeChurnCost, formatTimeUnit(avgCodeChurnHours), fmt.Sprintf("(%.1f%%)", pct)))
-		fmt.Println()
+		fmt.Fprintln(f.w, "  Preventable Future Costs")
+		fmt.Fprintln(f.w, "  ────────────────────────")
+		fmt.Fprint(f.w, formatItemLine(f, "Rework due to churn", avgCodeChurnCost, formatTimeUnit(avgCodeChurnHours), fmt.Sprintf("(%d PRs)", ext.CodeChurnPRCount)))
+		fmt.Fprint(f.w, formatSectionDivider())
+		pct = percentOf(avgCodeChurnCost, avgTotalCost)
+		fmt.Fprint(f.w, formatSubtotalLine(f, avgCodeChurnCost, formatTimeUnit(avgCodeChurnHours), fmt.Sprintf("(%.1f%%)", pct)))
+		fmt.Fprintln(f.w)
 	}
 
 	// Future Costs section
-	avgFutureReviewCost := ext.FutureReviewCost / float64(ext.TotalPRs)
-	avgFutureMergeCost := ext.FutureMergeCost / float64(ext.TotalPRs)
-	avgFutureContextCost := ext.FutureContextCost / float64(ext.TotalPRs)
-	avgFutureReviewHours := ext.FutureReviewHours / float64(ext.TotalPRs)
-	avgFutureMergeHours := ext.FutureMergeHours / float64(ext.TotalPRs)
-	avgFutureContextHours := ext.FutureContextHours / float64(ext.TotalPRs)
+	avgFutureReviewCost := perPR(ext.FutureReviewCost)
+	avgFutureMergeCost := perPR(ext.FutureMergeCost)
+	avgFutureContextCost := perPR(ext.FutureContextCost)
+	avgFutureReviewHours := perPR(ext.FutureReviewHours)
+	avgFutureMergeHours := perPR(ext.FutureMergeHours)
+	avgFutureContextHours := perPR(ext.FutureContextHours)
 
 	hasFutureCosts := ext.FutureReviewCost > 0.01 ||
 		ext.FutureMergeCost > 0.01 || ext.FutureContextCost > 0.01
 
 	if hasFutureCosts {
-		fmt.Println("  Future Costs")
-		fmt.Println("  ────────────")
+		fmt.Fprintln(f.w, "  Future Costs")
+		fmt.Fprintln(f.w, "  ────────────")
 		if ext.FutureReviewCost > 0.01 {
-			fmt.Print(formatItemLine("Review", avgFutureReviewCost, formatTimeUnit(avgFutureReviewHours), fmt.Sprintf("(%d PRs)", ext.FutureReviewPRCount)))
+			fmt.Fprint(f.w, formatItemLine(f, "Review", avgFutureReviewCost, formatTimeUnit(avgFutureReviewHours), fmt.Sprintf("(%d PRs)", ext.FutureReviewPRCount)))
 		}
 		if ext.FutureMergeCost > 0.01 {
-			fmt.Print(formatItemLine("Merge", avgFutureMergeCost, formatTimeUnit(avgFutureMergeHours), fmt.Sprintf("(%d PRs)", ext.FutureMergePRCount)))
+			fmt.Fprint(f.w, formatItemLine(f, "Merge", avgFutureMergeCost, formatTimeUnit(avgFutureMergeHours), fmt.Sprintf("(%d PRs)", ext.FutureMergePRCount)))
 		}
 		if ext.FutureContextCost > 0.01 {
-			avgFutureContextSessions := float64(ext.FutureContextSessions) / float64(ext.TotalPRs)
-			fmt.Print(formatItemLine("Context Switching", avgFutureContextCost, formatTimeUnit(avgFutureContextHours), fmt.Sprintf("(%.1f sessions)", avgFutureContextSessions)))
+			avgFutureContextSessions := perPR(float64(ext.FutureContextSessions))
+			fmt.Fprint(f.w, formatItemLine(f, "Context Switching", avgFutureContextCost, formatTimeUnit(avgFutureContextHours), fmt.Sprintf("(%.1f sessions)", avgFutureContextSessions)))
 		}
 		avgFutureCost := avgFutureReviewCost + avgFutureMergeCost + avgFutureContextCost
 		avgFutureHours := avgFutureReviewHours + avgFutureMergeHours + avgFutureContextHours
-		fmt.Print(formatSectionDivider())
-		pct = (avgFutureCost / avgTotalCost) * 100
-		fmt.Print(formatSubtotalLine(avgFutureCost, formatTimeUnit(avgFutureHours), fmt.Sprintf("(%.1f%%)", pct)))
-		fmt.Println()
+		fmt.Fprint(f.w, formatSectionDivider())
+		pct = percentOf(avgFutureCost, avgTotalCost)
+		fmt.Fprint(f.w, formatSubtotalLine(f, avgFutureCost, formatTimeUnit(avgFutureHours), fmt.Sprintf("(%.1f%%)", pct)))
+		fmt.Fprintln(f.w)
 	}
 
 	// Average Preventable Loss Total (before grand total)
-	avgPreventableCost := avgCodeChurnCost + avgDeliveryDelayCost + avgAutomatedUpdatesCost + avgPRTrackingCost
-	avgPreventableHours := avgCodeChurnHours + avgDeliveryDelayHours + avgAutomatedUpdatesHours + avgPRTrackingHours
-	avgPreventablePct := (avgPreventableCost / avgTotalCost) * 100
-	fmt.Print(formatSummaryLine("Preventable Loss Total", avgPreventableCost, formatTimeUnit(avgPreventableHours), fmt.Sprintf("(%.1f%%)", avgPreventablePct)))
+	avgPreventableCost := perPR(ext.PreventableCost())
+	avgPreventableHours := perPR(ext.PreventableHours())
+	avgPreventablePct := percentOf(avgPreventableCost, avgTotalCost)
+	fmt.Fprint(f.w, formatSummaryLine(f, "Preventable Loss Total", avgPreventableCost, formatTimeUnit(avgPreventableHours), fmt.Sprintf("(%.1f%%)", avgPreventablePct)))
 
 	// Average total
-	fmt.Println("  ════════════════════════════════════════════════════")
-	fmt.Printf("  Average Total                $%14s    %s\n",
-		formatWithCommas(avgTotalCost), formatTimeUnit(avgTotalHours))
-	fmt.Println()
-	fmt.Println()
+	fmt.Fprintln(f.w, "  ════════════════════════════════════════════════════")
+	fmt.Fprintf(f.w, "  Average Total                %s    %s\n",
+		f.column(avgTotalCost), formatTimeUnit(avgTotalHours))
+	fmt.Fprintln(f.w)
+	fmt.Fprintln(f.w)
 
 	// Extrapolated total section with improved visual hierarchy
-	fmt.Println("  ┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(f.w, "  ┌─────────────────────────────────────────────────────────────┐")
 	headerText = fmt.Sprintf("Estimated costs within a %d day period (extrapolated)", days)
 
 	// Box has 61 dashes, inner content area is 60 chars (1 space + 60 chars content)
 	if len(headerText) > 60 {
 		headerText = headerText[:60]
 	}
-	fmt.Printf("  │ %-60s│\n", headerText)
-	fmt.Println("  └─────────────────────────────────────────────────────────────┘")
-	fmt.Println()
+	fmt.Fprintf(f.w, "  │ %-60s│\n", headerText)
+	fmt.Fprintln(f.w, "  └─────────────────────────────────────────────────────────────┘")
+	fmt.Fprintln(f.w)
 
 	// Authors section (extrapolated)
 	// Calculate kLOC for display
@@ -529,40 +908,63 @@ func printExtrapolatedResults(title string, days int, ext *cost.ExtrapolatedBrea
 	totalTotalLOC := totalNewLOC + totalModifiedLOC
 	totalTotalLOCStr := formatLOC(totalTotalLOC)
 
-	fmt.Printf("  Development Costs (%d PRs, %s)\n", ext.HumanPRs, totalTotalLOCStr)
-	fmt.Println("  ────────────────────────────────────────")
+	fmt.Fprintf(f.w, "  Development Costs (%d PRs, %s)\n", ext.HumanPRs, totalTotalLOCStr)
+	fmt.Fprintln(f.w, "  ────────────────────────────────────────")
 
-	fmt.Print(formatItemLine("New Development", ext.AuthorNewCodeCost, formatTimeUnit(ext.AuthorNewCodeHours), fmt.Sprintf("(%s)", totalNewLOCStr)))
-	fmt.Print(formatItemLine("Adaptation", ext.AuthorAdaptationCost, formatTimeUnit(ext.AuthorAdaptationHours), fmt.Sprintf("(%s)", totalModifiedLOCStr)))
-	fmt.Print(formatItemLine("GitHub Activity", ext.AuthorGitHubCost, formatTimeUnit(ext.AuthorGitHubHours), fmt.Sprintf("(%d events)", ext.AuthorEvents)))
-	fmt.Print(formatItemLine("Context Switching", ext.AuthorGitHubContextCost, formatTimeUnit(ext.AuthorGitHubContextHours), fmt.Sprintf("(%d sessions)", ext.AuthorSessions)))
+	// Net LOC change (additions - deletions) is what actually grew the codebase
+	netSign := "+"
+	netLOC := float64(ext.NetLinesChanged) / 1000.0
+	if netLOC < 0 {
+		netSign = "-"
+		netLOC = -netLOC
+	}
+	fmt.Fprintf(f.w, "  Net codebase change: %s%s (%s deleted)\n", netSign, formatLOC(netLOC), formatLOC(float64(ext.TotalDeletedLines)/1000.0))
+
+	fmt.Fprint(f.w, formatItemLine(f, "New Development", ext.AuthorNewCodeCost, formatTimeUnit(ext.AuthorNewCodeHours), fmt.Sprintf("(%s)", totalNewLOCStr)))
+	fmt.Fprint(f.w, formatItemLine(f, "Adaptation", ext.AuthorAdaptationCost, formatTimeUnit(ext.AuthorAdaptationHours), fmt.Sprintf("(%s)", totalModifiedLOCStr)))
+	fmt.Fprint(f.w, formatItemLine(f, "GitHub Activity", ext.AuthorGitHubCost, formatTimeUnit(ext.AuthorGitHubHours), fmt.Sprintf("(%d events)", ext.AuthorEvents)))
+	fmt.Fprint(f.w, formatItemLine(f, "Context Switching", ext.AuthorGitHubContextCost, formatTimeUnit(ext.AuthorGitHubContextHours), fmt.Sprintf("(%d sessions)", ext.AuthorSessions)))
+	if ext.AuthorConflictResolutionCost > 0 {
+		fmt.Fprint(f.w, formatItemLine(f, "Conflict Resolution", ext.AuthorConflictResolutionCost, formatTimeUnit(ext.AuthorConflictResolutionHours), fmt.Sprintf("(%d conflicts)", ext.ConflictResolutions)))
+	}
 
 	// Show bot PR LOC even though cost is $0
 	if ext.BotPRs > 0 {
 		totalBotLOC := float64(ext.BotNewLines+ext.BotModifiedLines) / 1000.0
 		botTotalLOCStr := formatLOC(totalBotLOC)
-		fmt.Print(formatItemLine("Automated Updates", 0, formatTimeUnit(0.0), fmt.Sprintf("(%d PRs, %s)", ext.BotPRs, botTotalLOCStr)))
+		fmt.Fprint(f.w, formatItemLine(f, "Automated Updates", 0, formatTimeUnit(0.0), fmt.Sprintf("(%d PRs, %s)", ext.BotPRs, botTotalLOCStr)))
 	}
-	fmt.Print(formatSectionDivider())
-	pct = (ext.AuthorTotalCost / ext.TotalCost) * 100
-	fmt.Print(formatSubtotalLine(ext.AuthorTotalCost, formatTimeUnit(ext.AuthorTotalHours), fmt.Sprintf("(%.1f%%)", pct)))
-	fmt.Println()
+	fmt.Fprint(f.w, formatSectionDivider())
+	pct = percentOf(ext.AuthorTotalCost, ext.TotalCost)
+	fmt.Fprint(f.w, formatSubtotalLine(f, ext.AuthorTotalCost, formatTimeUnit(ext.AuthorTotalHours), fmt.Sprintf("(%.1f%%)", pct)))
+	if ext.AbandonedPRs > 0 {
+		fmt.Fprintf(f.w, "      %d PRs closed without merging abandoned %s of code (%s)\n",
+			ext.AbandonedPRs, f.currency(ext.AbandonedCost), formatTimeUnit(ext.AbandonedHours))
+	}
+	if ext.RevertPRs > 0 {
+		fmt.Fprintf(f.w, "      %d revert PRs spent %s (%s) undoing earlier work\n",
+			ext.RevertPRs, f.currency(ext.RevertCost), formatTimeUnit(ext.RevertHours))
+	}
+	fmt.Fprintln(f.w)
 
 	// Participants section (extrapolated, if any participants)
 	if ext.ParticipantTotalCost > 0 {
-		fmt.Println("  Participant Costs")
-		fmt.Println("  ─────────────────")
+		fmt.Fprintln(f.w, "  Participant Costs")
+		fmt.Fprintln(f.w, "  ─────────────────")
 		if ext.ParticipantReviewCost > 0 {
-			fmt.Print(formatItemLine("Review Activity", ext.ParticipantReviewCost, formatTimeUnit(ext.ParticipantReviewHours), fmt.Sprintf("(%d reviews)", ext.ParticipantReviews)))
+			fmt.Fprint(f.w, formatItemLine(f, "Review Activity", ext.ParticipantReviewCost, formatTimeUnit(ext.ParticipantReviewHours), fmt.Sprintf("(%d reviews)", ext.ParticipantReviews)))
 		}
 		if ext.ParticipantGitHubCost > 0 {
-			fmt.Print(formatItemLine("GitHub Activity", ext.ParticipantGitHubCost, formatTimeUnit(ext.ParticipantGitHubHours), fmt.Sprintf("(%d events)", ext.ParticipantEvents)))
+			fmt.Fprint(f.w, formatItemLine(f, "GitHub Activity", ext.ParticipantGitHubCost, formatTimeUnit(ext.ParticipantGitHubHours), fmt.Sprintf("(%d events)", ext.ParticipantEvents)))
+		}
+		if ext.ParticipantReviewWaitCost > 0 {
+			fmt.Fprint(f.w, formatItemLine(f, "Review Wait", ext.ParticipantReviewWaitCost, formatTimeUnit(ext.ParticipantReviewWaitHours), "(author waiting on reviewers)"))
 		}
-		fmt.Print(formatItemLine("Context Switching", ext.ParticipantContextCost, formatTimeUnit(ext.ParticipantContextHours), fmt.Sprintf("(%d sessions)", ext.ParticipantSessions)))
-		fmt.Print(formatSectionDivider())
-		pct = (ext.ParticipantTotalCost / ext.TotalCost) * 100
-		fmt.Print(formatSubtotalLine(ext.ParticipantTotalCost, formatTimeUnit(ext.ParticipantTotalHours), fmt.Sprintf("(%.1f%%)", pct)))
-		fmt.Println()
+		fmt.Fprint(f.w, formatItemLine(f, "Context Switching", ext.ParticipantContextCost, formatTimeUnit(ext.ParticipantContextHours), fmt.Sprintf("(%d sessions)", ext.ParticipantSessions)))
+		fmt.Fprint(f.w, formatSectionDivider())
+		pct = percentOf(ext.ParticipantTotalCost, ext.TotalCost)
+		fmt.Fprint(f.w, formatSubtotalLine(f, ext.ParticipantTotalCost, formatTimeUnit(ext.ParticipantTotalHours), fmt.Sprintf("(%.1f%%)", pct)))
+		fmt.Fprintln(f.w)
 	}
 
 	// Delay Costs section (extrapolated)
@@ -573,36 +975,43 @@ func printExtrapolatedResults(title string, days int, ext *cost.ExtrapolatedBrea
 		extDelayCostsHeader += fmt.Sprintf(", bot PRs avg %s", extAvgBotOpenTime)
 	}
 	extDelayCostsHeader += ")"
-	fmt.Println(extDelayCostsHeader)
-	fmt.Println("  " + strings.Repeat("─", len(extDelayCostsHeader)-2))
+	fmt.Fprintln(f.w, extDelayCostsHeader)
+	fmt.Fprintln(f.w, "  "+strings.Repeat("─", len(extDelayCostsHeader)-2))
 
 	if ext.DeliveryDelayCost > 0 {
-		fmt.Print(formatItemLine("Workstream blockage", ext.DeliveryDelayCost, formatTimeUnit(ext.DeliveryDelayHours), fmt.Sprintf("(%d PRs)", ext.HumanPRs)))
+		fmt.Fprint(f.w, formatItemLine(f, "Workstream blockage", ext.DeliveryDelayCost, formatTimeUnit(ext.DeliveryDelayHours), fmt.Sprintf("(%d PRs)", ext.HumanPRs)))
+		if ext.DeliveryDelayCapped {
+			fmt.Fprintf(f.w, "      Capped at org capacity (per-PR sum was %s)\n", f.currency(ext.UncappedDeliveryDelayCost))
+		}
 	}
 	if ext.AutomatedUpdatesCost > 0 {
-		fmt.Print(formatItemLine("Automated Updates", ext.AutomatedUpdatesCost, formatTimeUnit(ext.AutomatedUpdatesHours), fmt.Sprintf("(%d PRs)", ext.BotPRs)))
+		fmt.Fprint(f.w, formatItemLine(f, "Automated Updates", ext.AutomatedUpdatesCost, formatTimeUnit(ext.AutomatedUpdatesHours), fmt.Sprintf("(%d PRs)", ext.BotPRs)))
 	}
 	if ext.PRTrackingCost > 0 {
-		fmt.Print(formatItemLine("PR Tracking", ext.PRTrackingCost, formatTimeUnit(ext.PRTrackingHours), fmt.Sprintf("(%d open PRs)", ext.OpenPRs)))
+		fmt.Fprint(f.w, formatItemLine(f, "PR Tracking", ext.PRTrackingCost, formatTimeUnit(ext.PRTrackingHours), openPRsLabel(ext)))
+	}
+	if ext.ZombiePRs > 0 {
+		fmt.Fprintf(f.w, "      %d zombie PRs (poked but not progressing) carry %s of tracking\n",
+			ext.ZombiePRs, f.currency(ext.ZombieTrackingCost))
 	}
 	extMergeDelayCost := ext.DeliveryDelayCost + ext.AutomatedUpdatesCost + ext.PRTrackingCost
 	extMergeDelayHours := ext.DeliveryDelayHours + ext.AutomatedUpdatesHours + ext.PRTrackingHours
-	fmt.Print(formatSectionDivider())
-	pct = (extMergeDelayCost / ext.TotalCost) * 100
-	fmt.Print(formatSubtotalLine(extMergeDelayCost, formatTimeUnit(extMergeDelayHours), fmt.Sprintf("(%.1f%%)", pct)))
-	fmt.Println()
+	fmt.Fprint(f.w, formatSectionDivider())
+	pct = percentOf(extMergeDelayCost, ext.TotalCost)
+	fmt.Fprint(f.w, formatSubtotalLine(f, extMergeDelayCost, formatTimeUnit(extMergeDelayHours), fmt.Sprintf("(%.1f%%)", pct)))
+	fmt.Fprintln(f.w)
 
 	// Preventable Future Costs section (extrapolated)
 	if ext.CodeChurnCost > 0 {
-		fmt.Println("  Preventable Future Costs")
-		fmt.Println("  ────────────────────────")
+		fmt.Fprintln(f.w, "  Preventable Future Costs")
+		fmt.Fprintln(f.w, "  ────────────────────────")
 		totalKLOC := float64(ext.TotalNewLines+ext.TotalModifiedLines) / 1000.0
 		churnLOCStr := formatLOC(totalKLOC)
-		fmt.Print(formatItemLine("Rework due to churn", ext.CodeChurnCost, formatTimeUnit(ext.CodeChurnHours), fmt.Sprintf("(%d PRs, ~%s)", ext.CodeChurnPRCount, churnLOCStr)))
-		fmt.Print(formatSectionDivider())
-		pct = (ext.CodeChurnCost / ext.TotalCost) * 100
-		fmt.Print(formatSubtotalLine(ext.CodeChurnCost, formatTimeUnit(ext.CodeChurnHours), fmt.Sprintf("(%.1f%%)", pct)))
-		fmt.Println()
+		fmt.Fprint(f.w, formatItemLine(f, "Rework due to churn", ext.CodeChurnCost, formatTimeUnit(ext.CodeChurnHours), fmt.Sprintf("(%d PRs, ~%s)", ext.CodeChurnPRCount, churnLOCStr)))
+		fmt.Fprint(f.w, formatSectionDivider())
+		pct = percentOf(ext.CodeChurnCost, ext.TotalCost)
+		fmt.Fprint(f.w, formatSubtotalLine(f, ext.CodeChurnCost, formatTimeUnit(ext.CodeChurnHours), fmt.Sprintf("(%.1f%%)", pct)))
+		fmt.Fprintln(f.w)
 	}
 
 	// Future Costs section (extrapolated)
@@ -610,51 +1019,134 @@ func printExtrapolatedResults(title string, days int, ext *cost.ExtrapolatedBrea
 		ext.FutureMergeCost > 0.01 || ext.FutureContextCost > 0.01
 
 	if extHasFutureCosts {
-		fmt.Println("  Future Costs")
-		fmt.Println("  ────────────")
+		fmt.Fprintln(f.w, "  Future Costs")
+		fmt.Fprintln(f.w, "  ────────────")
 		if ext.FutureReviewCost > 0.01 {
-			fmt.Print(formatItemLine("Review", ext.FutureReviewCost, formatTimeUnit(ext.FutureReviewHours), fmt.Sprintf("(%d PRs)", ext.FutureReviewPRCount)))
+			fmt.Fprint(f.w, formatItemLine(f, "Review", ext.FutureReviewCost, formatTimeUnit(ext.FutureReviewHours), fmt.Sprintf("(%d PRs)", ext.FutureReviewPRCount)))
 		}
 		if ext.FutureMergeCost > 0.01 {
-			fmt.Print(formatItemLine("Merge", ext.FutureMergeCost, formatTimeUnit(ext.FutureMergeHours), fmt.Sprintf("(%d PRs)", ext.FutureMergePRCount)))
+			fmt.Fprint(f.w, formatItemLine(f, "Merge", ext.FutureMergeCost, formatTimeUnit(ext.FutureMergeHours), fmt.Sprintf("(%d PRs)", ext.FutureMergePRCount)))
 		}
 		if ext.FutureContextCost > 0.01 {
-			fmt.Print(formatItemLine("Context Switching", ext.FutureContextCost, formatTimeUnit(ext.FutureContextHours), fmt.Sprintf("(%d sessions)", ext.FutureContextSessions)))
+			fmt.Fprint(f.w, formatItemLine(f, "Context Switching", ext.FutureContextCost, formatTimeUnit(ext.FutureContextHours), fmt.Sprintf("(%d sessions)", ext.FutureContextSessions)))
 		}
 		extFutureCost := ext.FutureReviewCost + ext.FutureMergeCost + ext.FutureContextCost
 		extFutureHours := ext.FutureReviewHours + ext.FutureMergeHours + ext.FutureContextHours
-		fmt.Print(formatSectionDivider())
-		pct = (extFutureCost / ext.TotalCost) * 100
-		fmt.Print(formatSubtotalLine(extFutureCost, formatTimeUnit(extFutureHours), fmt.Sprintf("(%.1f%%)", pct)))
-		fmt.Println()
+		fmt.Fprint(f.w, formatSectionDivider())
+		pct = percentOf(extFutureCost, ext.TotalCost)
+		fmt.Fprint(f.w, formatSubtotalLine(f, extFutureCost, formatTimeUnit(extFutureHours), fmt.Sprintf("(%.1f%%)", pct)))
+		fmt.Fprintln(f.w)
 	}
 
 	// Preventable Loss Total (before grand total)
-	preventableCost := ext.CodeChurnCost + ext.DeliveryDelayCost + ext.AutomatedUpdatesCost + ext.PRTrackingCost
-	preventableHours := ext.CodeChurnHours + ext.DeliveryDelayHours + ext.AutomatedUpdatesHours + ext.PRTrackingHours
-	preventablePct := (preventableCost / ext.TotalCost) * 100
-	fmt.Print(formatSummaryLine("Preventable Loss Total", preventableCost, formatTimeUnit(preventableHours), fmt.Sprintf("(%.1f%%)", preventablePct)))
+	preventableCost, preventableHours := ext.PreventableCost(), ext.PreventableHours()
+	preventablePct := percentOf(preventableCost, ext.TotalCost)
+	fmt.Fprint(f.w, formatSummaryLine(f, "Preventable Loss Total", preventableCost, formatTimeUnit(preventableHours), fmt.Sprintf("(%.1f%%)", preventablePct)))
 
 	// Extrapolated grand total
-	fmt.Println("  ════════════════════════════════════════════════════")
-	fmt.Printf("  Total                        $%14s    %s\n",
-		formatWithCommas(ext.TotalCost), formatTimeUnit(ext.TotalHours))
-	fmt.Println()
+	fmt.Fprintln(f.w, "  ════════════════════════════════════════════════════")
+	fmt.Fprintf(f.w, "  Total                        %s    %s\n",
+		f.column(ext.TotalCost), formatTimeUnit(ext.TotalHours))
+	if ext.TotalCostStdErr > 0 {
+		fmt.Fprintf(f.w, "                               ± %s (95%% CI: %s - %s)\n",
+			f.currency(ext.TotalCostCI95High-ext.TotalCost),
+			f.currency(ext.TotalCostCI95Low), f.currency(ext.TotalCostCI95High))
+	}
+	if ext.CostPerMergedPR > 0 {
+		fmt.Fprintf(f.w, "  Per merged PR                %s\n", f.column(ext.CostPerMergedPR))
+	}
+	if ext.CostPerOpenedPR > 0 {
+		fmt.Fprintf(f.w, "  Per opened PR                %s    (%d opened)\n", f.column(ext.CostPerOpenedPR), ext.OpenedPRs)
+	}
+	if ext.CostPerLOC > 0 {
+		fmt.Fprintf(f.w, "  Per line of code             %s    (human PRs)\n", f.column(ext.CostPerLOC))
+	}
+	fmt.Fprintln(f.w)
+
+	printCostRanges(f, ext)
+
+	printTopAuthors(f, ext.AuthorRollups, 10)
+
+	printChangeTypes(f, ext.ChangeTypeRollups)
+
+	printSampledPRs(f, ext.Samples)
 
 	// Print extrapolated efficiency score + annual waste
-	printExtrapolatedEfficiency(ext, days, cfg)
+	printExtrapolatedEfficiency(f, ext, days, cfg, callout)
+}
+
+// printCostRanges prints 95% confidence ranges for the major line items, showing which are noisy in the sample.
+func printCostRanges(f *formatter, ext *cost.ExtrapolatedBreakdown) {
+	if ext.Ranges.Total.StdErr == 0 {
+		return // Fewer than two samples, or the whole population was sampled
+	}
+	fmt.Fprintln(f.w, "  Confidence Ranges (95%)")
+	fmt.Fprintln(f.w, "  ───────────────────────")
+	rangeLine := func(label string, estimate float64, r cost.CostRange) {
+		var spread float64
+		if estimate > 0 {
+			spread = 100 * (r.High - estimate) / estimate
+		}
+		fmt.Fprintf(f.w, "    %-22s %s - %-15s  (±%.0f%%)\n", label, f.column(r.Low), f.currency(r.High), spread)
+	}
+	rangeLine("Development", ext.AuthorTotalCost, ext.Ranges.Author)
+	if ext.ParticipantTotalCost > 0 {
+		rangeLine("Participants", ext.ParticipantTotalCost, ext.Ranges.Participant)
+	}
+	rangeLine("Delay", ext.DelayTotalCost, ext.Ranges.Delay)
+	rangeLine("Total", ext.TotalCost, ext.Ranges.Total)
+	fmt.Fprintln(f.w)
+}
+
+// printTopAuthors prints the highest-cost authors from the per-author rollup.
+func printTopAuthors(f *formatter, rollups []cost.AuthorRollup, limit int) {
+	if len(rollups) == 0 {
+		return
+	}
+	fmt.Fprintf(f.w, "  Top Authors by Cost (%d of %d)\n", min(limit, len(rollups)), len(rollups))
+	fmt.Fprintln(f.w, "  ─────────────────────────────")
+	for _, r := range rollups[:min(limit, len(rollups))] {
+		fmt.Fprint(f.w, formatItemLine(f, r.Author, r.TotalCost, "",
+			fmt.Sprintf("(%d sampled PRs, %.1f%% efficient)", r.SampledPRs, r.AvgEfficiency)))
+	}
+	fmt.Fprintln(f.w)
+}
+
+// printChangeTypes prints extrapolated cost per change type (feature, fix, chore, ...).
+func printChangeTypes(f *formatter, rollups []cost.ChangeTypeRollup) {
+	if len(rollups) == 0 {
+		return
+	}
+	fmt.Fprintln(f.w, "  Cost by Change Type")
+	fmt.Fprintln(f.w, "  ───────────────────")
+	for _, r := range rollups {
+		fmt.Fprint(f.w, formatItemLine(f, r.Type, r.TotalCost, "",
+			fmt.Sprintf("(%.1f%% of cost, %d sampled PRs)", r.CostPct, r.SampledPRs)))
+	}
+	fmt.Fprintln(f.w)
+}
+
+// printSampledPRs lists each sampled PR's own, unextrapolated cost, most expensive first.
+func printSampledPRs(f *formatter, samples []cost.SampleBreakdown) {
+	if len(samples) == 0 {
+		return
+	}
+	fmt.Fprintf(f.w, "  Sampled PRs by Cost (%d)\n", len(samples))
+	fmt.Fprintln(f.w, "  ───────────────────────")
+	for _, s := range samples {
+		fmt.Fprintf(f.w, "    %s    %-6s  %s\n", f.column(s.Breakdown.TotalCost), formatTimeUnit(s.Breakdown.PRDuration), s.URL)
+	}
+	fmt.Fprintln(f.w)
 }
 
 // printExtrapolatedEfficiency prints the workflow efficiency + annual waste section for extrapolated totals.
-func printExtrapolatedEfficiency(ext *cost.ExtrapolatedBreakdown, days int, cfg cost.Config) {
-	// Calculate preventable waste: Code Churn + All Delay Costs + Automated Updates + PR Tracking
-	preventableHours := ext.CodeChurnHours + ext.DeliveryDelayHours + ext.AutomatedUpdatesHours + ext.PRTrackingHours
-	preventableCost := ext.CodeChurnCost + ext.DeliveryDelayCost + ext.AutomatedUpdatesCost + ext.PRTrackingCost
+func printExtrapolatedEfficiency(f *formatter, ext *cost.ExtrapolatedBreakdown, days int, cfg cost.Config, callout bool) {
+	preventableHours, preventableCost := ext.PreventableHours(), ext.PreventableCost()
 
-	// Calculate efficiency (for display purposes - grade comes from backend)
+	// Calculate efficiency (for display purposes - grade comes from backend); abandoned code counts against it
 	var efficiencyPct float64
 	if ext.TotalHours > 0 {
-		efficiencyPct = 100.0 * (ext.TotalHours - preventableHours) / ext.TotalHours
+		efficiencyPct = 100.0 * (ext.TotalHours - preventableHours - ext.AbandonedHours) / ext.TotalHours
 	} else {
 		efficiencyPct = 100.0
 	}
@@ -666,10 +1158,13 @@ func printExtrapolatedEfficiency(ext *cost.ExtrapolatedBreakdown, days int, cfg
 	velocityMessage := ext.MergeVelocityMessage
 
 	// Calculate annual waste
-	annualMultiplier := 365.0 / float64(days)
+	var annualMultiplier float64
+	if days > 0 {
+		annualMultiplier = 365.0 / float64(days)
+	}
 	annualWasteCost := preventableCost * annualMultiplier
 
-	fmt.Println("  ┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(f.w, "  ┌─────────────────────────────────────────────────────────────┐")
 	headerText := fmt.Sprintf("DEVELOPMENT EFFICIENCY: %s (%.1f%%) - %s", grade, efficiencyPct, message)
 
 	// Box has 61 dashes, inner content area is 60 chars (1 space + 60 chars content)
@@ -677,52 +1172,78 @@ func printExtrapolatedEfficiency(ext *cost.ExtrapolatedBreakdown, days int, cfg
 	if len(headerText) > innerWidth {
 		headerText = headerText[:innerWidth]
 	}
-	fmt.Printf("  │ %-60s│\n", headerText)
-	fmt.Println("  └─────────────────────────────────────────────────────────────┘")
+	fmt.Fprintf(f.w, "  │ %-60s│\n", headerText)
+	fmt.Fprintln(f.w, "  └─────────────────────────────────────────────────────────────┘")
 
-	fmt.Println("  ┌─────────────────────────────────────────────────────────────┐")
-	velocityHeader := fmt.Sprintf("MERGE VELOCITY: %s (%s) - %s", velocityGrade, formatTimeUnit(ext.AvgPRDurationHours), velocityMessage)
+	fmt.Fprintln(f.w, "  ┌─────────────────────────────────────────────────────────────┐")
+	velocityTime := formatTimeUnit(ext.AvgPRDurationHours)
+	if ext.MergeVelocityBasis == "p50" {
+		velocityTime = "p50 " + formatTimeUnit(ext.P50PRDurationHours)
+	}
+	velocityHeader := fmt.Sprintf("MERGE VELOCITY: %s (%s) - %s", velocityGrade, velocityTime, velocityMessage)
 	if len(velocityHeader) > innerWidth {
 		velocityHeader = velocityHeader[:innerWidth]
 	}
-	fmt.Printf("  │ %-60s│\n", velocityHeader)
-	fmt.Println("  └─────────────────────────────────────────────────────────────┘")
+	fmt.Fprintf(f.w, "  │ %-60s│\n", velocityHeader)
+	fmt.Fprintln(f.w, "  └─────────────────────────────────────────────────────────────┘")
 
 	// Merge Success Rate box (if data available)
 	if ext.MergedPRs+ext.UnmergedPRs > 0 {
 		// Use grade computed by backend (single source of truth)
-		fmt.Println("  ┌─────────────────────────────────────────────────────────────┐")
+		fmt.Fprintln(f.w, "  ┌─────────────────────────────────────────────────────────────┐")
 		mergeRateHeader := fmt.Sprintf("MERGE SUCCESS RATE: %s (%.1f%%) - %s", ext.MergeRateGrade, ext.MergeRate, ext.MergeRateGradeMessage)
 		if len(mergeRateHeader) > innerWidth {
 			mergeRateHeader = mergeRateHeader[:innerWidth]
 		}
-		fmt.Printf("  │ %-60s│\n", mergeRateHeader)
-		fmt.Println("  └─────────────────────────────────────────────────────────────┘")
+		fmt.Fprintf(f.w, "  │ %-60s│\n", mergeRateHeader)
+		fmt.Fprintln(f.w, "  └─────────────────────────────────────────────────────────────┘")
 	}
 
 	// Weekly waste per PR author
 	if ext.WasteHoursPerAuthorPerWeek > 0 && ext.TotalAuthors > 0 {
-		fmt.Printf("  Weekly waste per PR author:     $%14s    %s  (%d authors)\n",
-			formatWithCommas(ext.WasteCostPerAuthorPerWeek),
+		fmt.Fprintf(f.w, "  Weekly waste per PR author:     %s    %s  (%d authors)\n",
+			f.column(ext.WasteCostPerAuthorPerWeek),
 			formatTimeUnit(ext.WasteHoursPerAuthorPerWeek),
 			ext.TotalAuthors)
 	}
 
-	// Calculate headcount from annual waste
+	// Express waste as headcount: the staff-years a period's waste would pay for
 	annualCostPerHead := cfg.AnnualSalary * cfg.BenefitsMultiplier
-	headcount := annualWasteCost / annualCostPerHead
-	fmt.Printf("  If Sustained for 1 Year:        $%14s    %.1f headcount\n",
-		formatWithCommas(annualWasteCost), headcount)
-	fmt.Println()
+	headcount := func(wasteCost float64) float64 {
+		if annualCostPerHead <= 0 {
+			return 0
+		}
+		return wasteCost / annualCostPerHead
+	}
+	if cfg.FiscalYearStartMonth > 0 {
+		// Project onto the current fiscal quarter and year so finance can use the numbers directly
+		quarter, year := cost.ProjectFiscalPeriods(preventableCost, days, time.Now(), cfg.FiscalYearStartMonth)
+		fmt.Fprintf(f.w, "  %-32s%s    %.1f headcount\n", "If Sustained for "+quarter.Label+":",
+			f.column(quarter.Cost), headcount(quarter.Cost))
+		fmt.Fprintf(f.w, "  %-32s%s    %.1f headcount\n", "If Sustained for "+year.Label+":",
+			f.column(year.Cost), headcount(year.Cost))
+	} else {
+		fmt.Fprintf(f.w, "  If Sustained for 1 Year:        %s    %.1f headcount\n",
+			f.column(annualWasteCost), headcount(annualWasteCost))
+	}
+	fmt.Fprintln(f.w)
 
-	// Print merge time modeling callout if average PR duration exceeds model merge time
-	if ext.AvgPRDurationHours > cfg.TargetMergeTimeHours {
-		printExtrapolatedMergeTimeModelingCallout(ext, days, cfg)
+	// Print merge time modeling callout if average PR duration exceeds model merge time;
+	// without the callout, report the same savings as a plain figure
+	if ext.AvgPRDurationHours <= cfg.TargetMergeTimeHours {
+		return
+	}
+	if callout {
+		printExtrapolatedMergeTimeModelingCallout(f, ext, days, cfg)
+	} else if ext.PotentialSavings > 0 {
+		fmt.Fprintf(f.w, "  %-32s%s/yr (merge within %s)\n", "Potential Savings:",
+			f.column(ext.PotentialSavings), formatTimeUnit(cfg.TargetMergeTimeHours))
+		fmt.Fprintln(f.w)
 	}
 }
 
 // printExtrapolatedMergeTimeModelingCallout prints a callout showing potential savings from reduced merge time.
-func printExtrapolatedMergeTimeModelingCallout(ext *cost.ExtrapolatedBreakdown, days int, cfg cost.Config) {
+func printExtrapolatedMergeTimeModelingCallout(f *formatter, ext *cost.ExtrapolatedBreakdown, days int, cfg cost.Config) {
 	targetHours := cfg.TargetMergeTimeHours
 
 	// Calculate hourly rate
@@ -744,7 +1265,7 @@ func printExtrapolatedMergeTimeModelingCallout(ext *cost.ExtrapolatedBreakdown,
 	remodelPRTrackingPerPR := 0.0
 	if targetHours >= 1.0 { // Minimal tracking for PRs open >= 1 hour
 		daysOpen := targetHours / 24.0
-		remodelPRTrackingHours := (cfg.PRTrackingMinutesPerDay / 60.0) * daysOpen
+		remodelPRTrackingHours := cost.PRTrackingHours(1, ext.UniqueNonBotUsers, daysOpen, cfg)
 		remodelPRTrackingPerPR = remodelPRTrackingHours * hourlyRate
 	}
 
@@ -765,7 +1286,10 @@ func printExtrapolatedMergeTimeModelingCallout(ext *cost.ExtrapolatedBreakdown,
 	// Modeled efficiency: (total hours - remodeled preventable hours) / total hours
 	currentPreventableHours := ext.CodeChurnHours + ext.DeliveryDelayHours +
 		ext.AutomatedUpdatesHours + ext.PRTrackingHours
-	remodelPreventableHours := remodelPreventablePerPeriod / hourlyRate
+	var remodelPreventableHours float64
+	if hourlyRate > 0 {
+		remodelPreventableHours = remodelPreventablePerPeriod / hourlyRate
+	}
 
 	var currentEfficiency, modeledEfficiency, efficiencyDelta float64
 	if ext.TotalHours > 0 {
@@ -774,21 +1298,21 @@ func printExtrapolatedMergeTimeModelingCallout(ext *cost.ExtrapolatedBreakdown,
 		efficiencyDelta = modeledEfficiency - currentEfficiency
 	}
 
-	if savingsPerPeriod > 0 {
+	if savingsPerPeriod > 0 && days > 0 {
 		// Annualize the savings
 		weeksInPeriod := float64(days) / 7.0
 		annualSavings := savingsPerPeriod * (52.0 / weeksInPeriod)
 
-		fmt.Println("  ┌─────────────────────────────────────────────────────────────┐")
-		fmt.Printf("  │ %-60s│\n", "MERGE TIME MODELING")
-		fmt.Println("  └─────────────────────────────────────────────────────────────┘")
+		fmt.Fprintln(f.w, "  ┌─────────────────────────────────────────────────────────────┐")
+		fmt.Fprintf(f.w, "  │ %-60s│\n", "MERGE TIME MODELING")
+		fmt.Fprintln(f.w, "  └─────────────────────────────────────────────────────────────┘")
 		if efficiencyDelta > 0 {
-			fmt.Printf("  Reduce merge time to %s to boost team throughput by %.1f%%\n", formatTimeUnit(targetHours), efficiencyDelta)
-			fmt.Printf("  and save ~$%s/yr in engineering overhead.\n", formatWithCommas(annualSavings))
+			fmt.Fprintf(f.w, "  Reduce merge time to %s to boost team throughput by %.1f%%\n", formatTimeUnit(targetHours), efficiencyDelta)
+			fmt.Fprintf(f.w, "  and save ~%s/yr in engineering overhead.\n", f.currency(annualSavings))
 		} else {
-			fmt.Printf("  If you lowered your average merge time to %s, you would save\n", formatTimeUnit(targetHours))
-			fmt.Printf("  ~$%s/yr in engineering overhead.\n", formatWithCommas(annualSavings))
+			fmt.Fprintf(f.w, "  If you lowered your average merge time to %s, you would save\n", formatTimeUnit(targetHours))
+			fmt.Fprintf(f.w, "  ~%s/yr in engineering overhead.\n", f.currency(annualSavings))
 		}
-		fmt.Println()
+		fmt.Fprintln(f.w)
 	}
 }
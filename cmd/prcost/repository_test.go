@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+func TestPrintTruncationWarning(t *testing.T) {
+	var buf bytes.Buffer
+	printTruncationWarning(&buf, 90, 21)
+
+	out := buf.String()
+	if !strings.Contains(out, "Warning") || !strings.Contains(out, "last 21 days (requested 90)") {
+		t.Errorf("printTruncationWarning() = %q, want warning naming actual and requested days", out)
+	}
+}
+
+func TestFormatPeriod(t *testing.T) {
+	if got := formatPeriod(60, 60); got != "Last 60 days" {
+		t.Errorf("formatPeriod(60, 60) = %q, want %q", got, "Last 60 days")
+	}
+	want := "Last 21 days (truncated from 90 by API limits)"
+	if got := formatPeriod(21, 90); got != want {
+		t.Errorf("formatPeriod(21, 90) = %q, want %q", got, want)
+	}
+}
+
+func TestPrintVariance(t *testing.T) {
+	var buf bytes.Buffer
+	printVariance(&buf, &cost.Variance{ModeledHours: 10, ActualHours: 20, ErrorPct: -50})
+
+	out := buf.String()
+	if !strings.Contains(out, "Actual vs. Estimated") || !strings.Contains(out, "-50.0% (model underestimates)") {
+		t.Errorf("printVariance() = %q, want modeled vs. actual with a -50%% error", out)
+	}
+}
+
+func TestOpenPRsLabel(t *testing.T) {
+	ext := &cost.ExtrapolatedBreakdown{OpenPRs: 42}
+	if got := openPRsLabel(ext); got != "(42 open PRs)" {
+		t.Errorf("openPRsLabel() = %q, want %q", got, "(42 open PRs)")
+	}
+	ext.OpenPRsFailedRepos = 3
+	if got, want := openPRsLabel(ext), "(at least 42 open PRs; 3 repos not counted)"; got != want {
+		t.Errorf("openPRsLabel() with failed repos = %q, want %q", got, want)
+	}
+	ext.OpenPRsFailedRepos, ext.OpenPRsPartial = 0, true
+	if got, want := openPRsLabel(ext), "(at least 42 open PRs; only repos with PRs in the window counted)"; got != want {
+		t.Errorf("openPRsLabel() of a partial count = %q, want %q", got, want)
+	}
+}
+
+func TestExtrapolatedReportJSON(t *testing.T) {
+	ext := cost.ExtrapolatedBreakdown{TotalPRs: 120, SampledPRs: 30, TotalCost: 4200}
+	scenarios := []cost.ScenarioResult{{Name: "baseline", Extrapolated: ext}}
+	data, err := json.Marshal(newExtrapolatedReport("o/r", 21, 90, &ext, scenarios))
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	// Breakdown fields stay at the top level, alongside the metadata
+	want := map[string]any{
+		"total_prs": 120.0, "sampled_prs": 30.0, "total_cost": 4200.0,
+		"title": "o/r", "requested_days": 90.0, "actual_days": 21.0, "truncated": true,
+		"schema_version": cost.SchemaVersion,
+	}
+	for key, w := range want {
+		if got[key] != w {
+			t.Errorf("%s = %v, want %v", key, got[key], w)
+		}
+	}
+	if s, ok := got["scenarios"].([]any); !ok || len(s) != 1 {
+		t.Errorf("scenarios = %v, want one scenario", got["scenarios"])
+	}
+
+	data, err = json.Marshal(newExtrapolatedReport("o/r", 60, 60, &ext, nil))
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	if strings.Contains(string(data), `"scenarios"`) || !strings.Contains(string(data), `"truncated":false`) {
+		t.Errorf("report without scenarios = %s, want no scenarios and truncated false", data)
+	}
+}
+
+func TestPrintDurationHistogram(t *testing.T) {
+	var buf bytes.Buffer
+	printDurationHistogram(&buf, []cost.DurationBucket{
+		{Label: "<1h", MaxHours: 1, Count: 60, Weighted: 60},
+		{Label: "1-4h", MaxHours: 4, Count: 30, Weighted: 30},
+		{Label: "4-24h", MaxHours: 24, Count: 0},
+		{Label: ">7d", Count: 1, Weighted: 1},
+	})
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("printDurationHistogram() printed %d lines, want a title and 4 buckets:\n%s", len(lines), buf.String())
+	}
+	if got := strings.Count(lines[1], "█"); got != 30 {
+		t.Errorf("busiest bucket bar = %d blocks, want 30", got)
+	}
+	if got := strings.Count(lines[2], "█"); got != 15 {
+		t.Errorf("half-size bucket bar = %d blocks, want 15", got)
+	}
+	if !strings.Contains(lines[4], "▏") || !strings.HasSuffix(lines[4], " 1") {
+		t.Errorf("small bucket line = %q, want a visible marker and its count", lines[4])
+	}
+
+	buf.Reset()
+	printDurationHistogram(&buf, []cost.DurationBucket{{Label: "<1h", MaxHours: 1}})
+	if buf.Len() != 0 {
+		t.Errorf("printDurationHistogram() with no samples printed %q, want nothing", buf.String())
+	}
+}
+
+func TestRedirectOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(path, []byte("stale results\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f := testFormatter()
+
+	closeOutput, err := redirectOutput(f, path)
+	if err != nil {
+		t.Fatalf("redirectOutput() error: %v", err)
+	}
+	fmt.Fprintln(f.w, "Total $42.00")
+	if progressWriter(f, "human") != os.Stderr {
+		t.Error("progressWriter() = the results while they go to a file, want stderr")
+	}
+	closeOutput()
+
+	if f.w != os.Stdout || f.toFile {
+		t.Error("output not restored to stdout")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "Total $42.00\n" {
+		t.Errorf("output file = %q, want only the new results", data)
+	}
+
+	if _, err := redirectOutput(testFormatter(), filepath.Join(t.TempDir(), "missing", "report.txt")); err == nil {
+		t.Error("redirectOutput() into a missing directory succeeded, want error")
+	}
+}
+
+func TestCacheSummary(t *testing.T) {
+	if got, want := cacheSummary(43, 112), "43 hits, 112 misses, 27.7% hit rate"; got != want {
+		t.Errorf("cacheSummary(43, 112) = %q, want %q", got, want)
+	}
+	if got, want := cacheSummary(0, 0), "0 hits, 0 misses, 0.0% hit rate"; got != want {
+		t.Errorf("cacheSummary(0, 0) = %q, want %q", got, want)
+	}
+}
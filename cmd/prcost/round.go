@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+// roundingUnits maps --round values to the unit currency amounts are rounded to; 0 keeps cents.
+var roundingUnits = map[string]float64{
+	"none":     0,
+	"dollar":   1,
+	"hundred":  100,
+	"thousand": 1000,
+}
+
+// parseRounding returns the rounding unit for a --round value.
+func parseRounding(value string) (float64, error) {
+	unit, ok := roundingUnits[strings.ToLower(value)]
+	if !ok {
+		return 0, fmt.Errorf("invalid rounding %q: must be none, dollar, hundred or thousand", value)
+	}
+	return unit, nil
+}
+
+// roundTo rounds amount to the nearest multiple of unit; a unit of 0 leaves it unchanged.
+func roundTo(amount, unit float64) float64 {
+	if unit <= 0 {
+		return amount
+	}
+	return math.Round(amount/unit)*unit + 0 // + 0 turns -0 into 0
+}
+
+var costRangeType = reflect.TypeFor[cost.CostRange]()
+
+// roundedJSONFields are the JSON names of the currency amounts --round-json rounds, besides
+// every figure in a cost.CostRange. Per-PR, per-author and per-line averages such as
+// cost_per_merged_pr are left alone: rounded to thousands, most of them would read as 0.
+var roundedJSONFields = map[string]bool{
+	// Totals and savings
+	"total_cost":                   true,
+	"total_cost_ci95_low":          true,
+	"total_cost_ci95_high":         true,
+	"total_cost_std_err":           true,
+	"delay_cost":                   true,
+	"delay_total_cost":             true,
+	"total_delay_cost":             true,
+	"potential_savings":            true,
+	"r2r_savings":                  true,
+	"waste_cost_per_week":          true,
+	"delta":                        true,
+	"cost":                         true, // cost.DelayAttribution
+	"abandoned_cost":               true,
+	"revert_cost":                  true,
+	"zombie_tracking_cost":         true,
+	"uncapped_code_cost":           true,
+	"uncapped_delivery_delay_cost": true,
+
+	// Author costs
+	"new_code_cost":                   true,
+	"adaptation_cost":                 true,
+	"github_cost":                     true,
+	"github_context_cost":             true,
+	"conflict_resolution_cost":        true,
+	"author_new_code_cost":            true,
+	"author_adaptation_cost":          true,
+	"author_github_cost":              true,
+	"author_github_context_cost":      true,
+	"author_conflict_resolution_cost": true,
+	"author_total_cost":               true,
+
+	// Participant costs
+	"review_cost":                  true,
+	"review_wait_cost":             true,
+	"co_authored_cost":             true,
+	"participant_review_cost":      true,
+	"participant_github_cost":      true,
+	"participant_context_cost":     true,
+	"participant_review_wait_cost": true,
+	"participant_total_cost":       true,
+
+	// Delay and future costs
+	"delivery_delay_cost":    true,
+	"code_churn_cost":        true,
+	"automated_updates_cost": true,
+	"pr_tracking_cost":       true,
+	"future_review_cost":     true,
+	"future_merge_cost":      true,
+	"future_context_cost":    true,
+}
+
+// roundedForJSON returns a copy of v with every currency amount rounded to unit: the
+// float64 fields named in roundedJSONFields and every figure in a cost.CostRange.
+func roundedForJSON(v any, unit float64) any {
+	if unit <= 0 || v == nil {
+		return v
+	}
+	src := reflect.ValueOf(v)
+	if src.Kind() == reflect.Pointer {
+		if src.IsNil() {
+			return v
+		}
+		src = src.Elem()
+	}
+	dst := reflect.New(src.Type())
+	dst.Elem().Set(src)
+	roundCosts(dst.Elem(), unit, false)
+	return dst.Interface()
+}
+
+// roundCosts rounds the currency amounts in v, which must be settable. Slices and maps
+// are copied before rounding, so the value roundedForJSON copied from is left unchanged.
+func roundCosts(v reflect.Value, unit float64, isCost bool) {
+	switch v.Kind() { //nolint:exhaustive // only these kinds hold costs
+	case reflect.Float64:
+		if isCost {
+			v.SetFloat(roundTo(v.Float(), unit))
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			elem := reflect.New(v.Type().Elem())
+			elem.Elem().Set(v.Elem())
+			roundCosts(elem.Elem(), unit, isCost)
+			v.Set(elem)
+		}
+	case reflect.Struct:
+		if v.Type() == costRangeType {
+			isCost = true
+		}
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			roundCosts(v.Field(i), unit, isCost || (field.Type.Kind() == reflect.Float64 && isCostField(field)))
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		items := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(items, v)
+		for i := range items.Len() {
+			roundCosts(items.Index(i), unit, isCost)
+		}
+		v.Set(items)
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		entries := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			roundCosts(elem, unit, isCost)
+			entries.SetMapIndex(iter.Key(), elem)
+		}
+		v.Set(entries)
+	default:
+	}
+}
+
+// isCostField reports whether a float64 struct field is a currency amount to round (see roundedJSONFields).
+func isCostField(field reflect.StructField) bool {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return roundedJSONFields[name]
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+	"golang.org/x/text/language"
+)
+
+func TestFormatterRounding(t *testing.T) {
+	tests := []struct {
+		unit float64
+		want string
+	}{
+		{0, "155,624.73"},
+		{1, "155,625"},
+		{100, "155,600"},
+		{1000, "156,000"},
+	}
+	for _, tt := range tests {
+		f := newFormatter("USD", language.AmericanEnglish, tt.unit)
+		if got := f.number(155624.73); got != tt.want {
+			t.Errorf("number() rounding to %v = %q, want %q", tt.unit, got, tt.want)
+		}
+	}
+	if got := newFormatter("USD", language.AmericanEnglish, 1000).number(-0.4); got != "0" {
+		t.Errorf("number(-0.4) rounding to thousands = %q, want 0", got)
+	}
+}
+
+func TestRoundedForJSON(t *testing.T) {
+	ext := &cost.ExtrapolatedBreakdown{
+		TotalCost:          155624.73,
+		TotalHours:         812.34,
+		EfficiencyPct:      71.25,
+		R2RSavings:         48210.5,
+		Ranges:             cost.ComponentRanges{Total: cost.CostRange{Low: 120456.7, High: 190792.8}},
+		AuthorRollups:      []cost.AuthorRollup{{TotalCost: 1499.99}},
+		AvgPRDurationHours: 30.5,
+		CostPerMergedPR:    1234.56,
+		CostPerLOC:         12.34,
+	}
+
+	got, ok := roundedForJSON(ext, 1000).(*cost.ExtrapolatedBreakdown)
+	if !ok {
+		t.Fatalf("roundedForJSON() returned %T", got)
+	}
+	if got.TotalCost != 156000 || got.R2RSavings != 48000 || got.Ranges.Total.Low != 120000 || got.AuthorRollups[0].TotalCost != 1000 {
+		t.Errorf("rounded costs = %v/%v/%v/%v, want 156000/48000/120000/1000",
+			got.TotalCost, got.R2RSavings, got.Ranges.Total.Low, got.AuthorRollups[0].TotalCost)
+	}
+	if got.TotalHours != 812.34 || got.EfficiencyPct != 71.25 || got.AvgPRDurationHours != 30.5 {
+		t.Errorf("non-currency fields changed: hours %v, efficiency %v, duration %v", got.TotalHours, got.EfficiencyPct, got.AvgPRDurationHours)
+	}
+	if got.CostPerMergedPR != 1234.56 || got.CostPerLOC != 12.34 {
+		t.Errorf("per-PR and per-line costs changed: %v, %v", got.CostPerMergedPR, got.CostPerLOC)
+	}
+	if ext.TotalCost != 155624.73 || ext.AuthorRollups[0].TotalCost != 1499.99 {
+		t.Error("roundedForJSON() modified its argument")
+	}
+
+	if same := roundedForJSON(ext, 0); same != any(ext) {
+		t.Error("roundedForJSON() without rounding should return its argument")
+	}
+}
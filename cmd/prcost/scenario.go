@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+// scenarioFlags collects repeated --scenario values.
+type scenarioFlags []string
+
+func (s *scenarioFlags) String() string {
+	return strings.Join(*s, "; ")
+}
+
+func (s *scenarioFlags) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// parseScenario parses a scenario spec of the form "name:key=value,key=value"
+// into a cost.Scenario, applying the overrides on top of base.
+//
+// Supported keys: salary, benefits, event-minutes, churn-rate,
+// delivery-delay-factor, review-rate, target-merge-time (Go duration).
+func parseScenario(spec string, base cost.Config) (cost.Scenario, error) {
+	name, overrides, found := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+	if !found || name == "" {
+		return cost.Scenario{}, fmt.Errorf("invalid scenario %q: expected name:key=value[,key=value]", spec)
+	}
+
+	cfg := base
+	for override := range strings.SplitSeq(overrides, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(override), "=")
+		if !ok {
+			return cost.Scenario{}, fmt.Errorf("invalid scenario %q: override %q is not key=value", name, override)
+		}
+
+		if key == "target-merge-time" {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return cost.Scenario{}, fmt.Errorf("invalid scenario %q: bad target-merge-time %q", name, value)
+			}
+			cfg.TargetMergeTimeHours = d.Hours()
+			continue
+		}
+
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f < 0 {
+			return cost.Scenario{}, fmt.Errorf("invalid scenario %q: bad value for %s: %q", name, key, value)
+		}
+		switch key {
+		case "salary":
+			cfg.AnnualSalary = f
+		case "benefits":
+			cfg.BenefitsMultiplier = f
+		case "event-minutes":
+			cfg.EventDuration = time.Duration(f * float64(time.Minute))
+		case "churn-rate":
+			cfg.WeeklyChurnRate = f
+		case "delivery-delay-factor":
+			cfg.DeliveryDelayFactor = f
+		case "review-rate":
+			if f == 0 {
+				return cost.Scenario{}, fmt.Errorf("invalid scenario %q: review-rate must be positive", name)
+			}
+			cfg.ReviewInspectionRate = f
+		default:
+			return cost.Scenario{}, fmt.Errorf("invalid scenario %q: unknown key %q", name, key)
+		}
+	}
+
+	return cost.Scenario{Name: name, Config: cfg}, nil
+}
+
+// printScenarioComparison displays extrapolated totals for each scenario side by side.
+// The first result is treated as the baseline for deltas.
+func printScenarioComparison(f *formatter, results []cost.ScenarioResult) {
+	if len(results) == 0 {
+		return
+	}
+	baseline := results[0].Extrapolated.TotalCost
+
+	fmt.Fprintln(f.w, "  Scenario Comparison")
+	fmt.Fprintln(f.w, "  ───────────────────")
+	for _, r := range results {
+		delta := ""
+		if r.Name != results[0].Name && baseline > 0 {
+			diff := r.Extrapolated.TotalCost - baseline
+			sign := "+"
+			if diff < 0 {
+				sign = "-"
+				diff = -diff
+			}
+			delta = fmt.Sprintf("(%s%s, %s%.1f%%)", sign, f.currency(diff), sign, diff/baseline*100)
+		}
+		fmt.Fprint(f.w, formatItemLine(f, r.Name, r.Extrapolated.TotalCost, formatTimeUnit(r.Extrapolated.TotalHours), delta))
+	}
+	fmt.Fprintln(f.w)
+}
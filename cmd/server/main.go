@@ -11,10 +11,13 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/codeGROOVE-dev/prcost/internal/server"
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+	"github.com/codeGROOVE-dev/prcost/pkg/github"
 )
 
 const (
@@ -72,9 +75,24 @@ func main() {
 		rateLimit      = flag.Int("rate-limit", 100, "Requests per second rate limit")
 		rateBurst      = flag.Int("rate-burst", 100, "Rate limit burst size")
 		validateTokens = flag.Bool("validate-tokens", false, "Validate GitHub tokens server-side")
-		githubAppID    = flag.String("github-app-id", "", "GitHub App ID for token validation")
+		githubAppID    = flag.String("github-app-id", "", "GitHub App ID for token validation and --github-app-tokens")
 		githubAppKey   = flag.String("github-app-key-file", "", "Path to GitHub App private key file")
-		dataSource     = flag.String("data-source", "prx", "Data source for PR data (prx or turnserver)")
+		appTokens      = flag.Bool("github-app-tokens", false, "Mint GitHub App installation tokens for requests without an Authorization header, instead of a static GITHUB_TOKEN")
+		installationID = flag.Int64("github-app-installation-id", 0, "GitHub App installation to mint tokens for with --github-app-tokens (default: the installation on each request's owner)")
+		dataSource     = flag.String("data-source", "prx", "Data source for PR data (prx, turnserver, or gitlab for GitLab merge requests)")
+		requireToken   = flag.Bool("require-token", false, "Fail startup if no fallback GitHub token is available")
+		githubHost     = flag.String("github-host", "", "GitHub Enterprise Server host, e.g. github.mycorp.com (default: $GITHUB_HOST or github.com)")
+		gitlabHost     = flag.String("gitlab-host", "", "GitLab host merge request URLs must be on with --data-source gitlab (default: $GITLAB_HOST or gitlab.com)")
+		maxRetries     = flag.Int("max-retries", github.DefaultMaxRetries, "Retries for rate-limited (403/429) or failed (5xx) GitHub API calls")
+		githubRate     = flag.Float64("github-rate", 0, "Average GitHub API requests per second shared by all requests, retries included (0 = unlimited)")
+		concurrency    = flag.Int("concurrency", 0, "PRs each repo/org request fetches at once, 1-32 (default: $CONCURRENCY or 8)")
+		maxFetches     = flag.Int("max-concurrent-fetches", server.DefaultMaxConcurrentFetches, "Maximum PR data fetches in flight across all requests; extra fetches wait for a free slot")
+		publishTopic   = flag.String("publish-topic", "", "Pub/Sub topic to publish computed results to (name or projects/P/topics/T)")
+		alertWebhook   = flag.String("budget-alert-webhook", "", "Webhook URL to POST a JSON alert to when org waste exceeds a threshold")
+		alertWeekly    = flag.Float64("budget-alert-weekly", 0, "Alert when org preventable waste per week exceeds this many dollars (0 disables)")
+		alertAnnual    = flag.Float64("budget-alert-annual", 0, "Alert when annualized org preventable waste exceeds this many dollars (0 disables)")
+		cacheTTL       = flag.Duration("cache-ttl", 0, "Expire in-memory cache entries this long after they are written, e.g. 6h (0 keeps them until restart)")
+		redisAddr      = flag.String("redis-addr", "", "Redis server (host:port or redis:// URL) to persist cached PR data in across restarts and instances (default: $REDIS_ADDR)")
 	)
 	flag.Parse()
 
@@ -102,6 +120,32 @@ func main() {
 		dataSourceValue = envDataSource
 	}
 
+	// Determine GitHub host (flag overrides environment variable)
+	githubHostValue := *githubHost
+	if githubHostValue == "" {
+		githubHostValue = os.Getenv("GITHUB_HOST")
+	}
+
+	// Determine GitLab host (flag overrides environment variable)
+	gitlabHostValue := *gitlabHost
+	if gitlabHostValue == "" {
+		gitlabHostValue = os.Getenv("GITLAB_HOST")
+	}
+
+	// Determine per-request concurrency (flag overrides environment variable)
+	concurrencyValue := *concurrency
+	if concurrencyValue == 0 {
+		concurrencyValue = cost.DefaultConcurrency
+		if envConcurrency := os.Getenv("CONCURRENCY"); envConcurrency != "" {
+			n, err := strconv.Atoi(envConcurrency)
+			if err != nil {
+				logger.ErrorContext(ctx, "invalid CONCURRENCY", "value", envConcurrency, "error", err)
+				os.Exit(1)
+			}
+			concurrencyValue = n
+		}
+	}
+
 	// Check R2R_CALLOUT environment variable
 	r2rCallout := os.Getenv("R2R_CALLOUT") == "1"
 
@@ -112,7 +156,71 @@ func main() {
 	prcostServer.SetRateLimit(*rateLimit, *rateBurst)
 	prcostServer.SetDataSource(dataSourceValue)
 	prcostServer.SetR2RCallout(r2rCallout)
+	if err := prcostServer.SetGitHubHost(githubHostValue); err != nil {
+		logger.ErrorContext(ctx, "invalid GitHub host", "error", err)
+		os.Exit(1)
+	}
+	if err := prcostServer.SetGitLabHost(gitlabHostValue); err != nil {
+		logger.ErrorContext(ctx, "invalid GitLab host", "error", err)
+		os.Exit(1)
+	}
+	if err := prcostServer.SetGitHubMaxRetries(*maxRetries); err != nil {
+		logger.ErrorContext(ctx, "invalid max retries", "error", err)
+		os.Exit(1)
+	}
+	if err := prcostServer.SetGitHubRateBudget(*githubRate); err != nil {
+		logger.ErrorContext(ctx, "invalid GitHub rate", "error", err)
+		os.Exit(1)
+	}
+	if err := prcostServer.SetConcurrency(concurrencyValue); err != nil {
+		logger.ErrorContext(ctx, "invalid concurrency", "error", err)
+		os.Exit(1)
+	}
+	if err := prcostServer.SetMaxConcurrentFetches(*maxFetches); err != nil {
+		logger.ErrorContext(ctx, "invalid max concurrent fetches", "error", err)
+		os.Exit(1)
+	}
+	if err := prcostServer.SetCacheTTL(*cacheTTL); err != nil {
+		logger.ErrorContext(ctx, "invalid cache TTL", "error", err)
+		os.Exit(1)
+	}
+	redisAddrValue := *redisAddr
+	if redisAddrValue == "" {
+		redisAddrValue = os.Getenv("REDIS_ADDR")
+	}
+	if redisAddrValue != "" {
+		redisCache, err := server.NewRedisCache(ctx, redisAddrValue)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to connect to Redis cache", "error", err)
+			os.Exit(1)
+		}
+		prcostServer.SetPersistentCache(redisCache)
+	}
+	if *appTokens {
+		if dataSourceValue == "gitlab" {
+			logger.ErrorContext(ctx, "GitHub App tokens cannot be used with the gitlab data source")
+			os.Exit(1)
+		}
+		if *githubAppID == "" || *githubAppKey == "" {
+			logger.ErrorContext(ctx, "github app ID and key file are required for GitHub App tokens")
+			os.Exit(1)
+		}
+		if err := prcostServer.SetGitHubAppTokens(*githubAppID, *githubAppKey, *installationID); err != nil {
+			logger.ErrorContext(ctx, "failed to configure GitHub App tokens", "error", err)
+			os.Exit(1)
+		}
+	}
+	if *requireToken {
+		if err := prcostServer.RequireToken(ctx); err != nil {
+			logger.ErrorContext(ctx, "fallback token required but none found (tried GITHUB_TOKEN env, gh auth token, and GSM)", "error", err)
+			os.Exit(1)
+		}
+	}
 	if *validateTokens {
+		if dataSourceValue == "gitlab" {
+			logger.ErrorContext(ctx, "token validation checks GitHub tokens and cannot be used with the gitlab data source")
+			os.Exit(1)
+		}
 		if *githubAppID == "" || *githubAppKey == "" {
 			logger.ErrorContext(ctx, "github app ID and key file are required when token validation is enabled")
 			os.Exit(1)
@@ -122,6 +230,23 @@ func main() {
 			os.Exit(1)
 		}
 	}
+	if *publishTopic != "" {
+		publisher, err := server.NewPubSubPublisher(ctx, *publishTopic)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to configure result publishing", "error", err)
+			os.Exit(1)
+		}
+		prcostServer.SetPublisher(publisher)
+		logger.InfoContext(ctx, "publishing results to Pub/Sub", "topic", *publishTopic)
+	}
+	if *alertWebhook != "" {
+		alert := server.BudgetAlert{WebhookURL: *alertWebhook, WeeklyThreshold: *alertWeekly, AnnualThreshold: *alertAnnual}
+		if err := prcostServer.SetBudgetAlert(alert); err != nil {
+			logger.ErrorContext(ctx, "failed to configure budget alert", "error", err)
+			os.Exit(1)
+		}
+		logger.InfoContext(ctx, "budget alerts enabled", "weekly_threshold", *alertWeekly, "annual_threshold", *alertAnnual)
+	}
 	srv := &http.Server{
 		Addr:              ":" + serverPort,
 		Handler:           prcostServer,
@@ -3,11 +3,20 @@ module github.com/codeGROOVE-dev/prcost
 go 1.25.3
 
 require (
+	github.com/alicebob/miniredis/v2 v2.37.0
 	github.com/codeGROOVE-dev/ds9 v0.6.0
 	github.com/codeGROOVE-dev/gsm v0.0.0-20251019065141-833fe2363d22
 	github.com/codeGROOVE-dev/prx v0.0.0-20251030022101-ff906928a1e4
 	github.com/codeGROOVE-dev/turnclient v0.0.0-20251030022425-bc3b14acf75e
+	github.com/redis/go-redis/v9 v9.9.0
+	golang.org/x/text v0.30.0
 	golang.org/x/time v0.14.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
-require github.com/codeGROOVE-dev/retry v1.3.0 // indirect
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/codeGROOVE-dev/retry v1.3.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+)
@@ -46,10 +46,12 @@ func main() {
 
 	fmt.Printf("\nAuthor Events: %d\n", len(authorEvents))
 
-	// Manually trace through session logic
+	// Manually trace through session logic, using the flat session model so the trace
+	// matches Calculate: every session pays a full context switch in and out.
 	fmt.Println("\nSession Breakdown:")
 
 	cfg := cost.DefaultConfig()
+	cfg.SessionModel = cost.SessionModelFlat
 	gapThreshold := cfg.SessionGapThreshold
 	contextIn := cfg.ContextSwitchInDuration
 	contextOut := cfg.ContextSwitchOutDuration
@@ -98,26 +100,23 @@ func main() {
 		totalContext += contextIn
 		fmt.Printf("  Context In: %v\n", contextIn)
 
-		// First event
-		totalGitHub += eventDur
-		fmt.Printf("  Event %d: %v (default duration)\n", 0, eventDur)
-
-		// Gaps between events
-		for j := start; j < end; j++ {
-			gap := events[j+1].Timestamp.Sub(events[j].Timestamp)
-			counted := gap
-			if gap > eventDur {
-				counted = eventDur
+		// Events: eventDur each, except review events which have no duration
+		var sessionGitHub time.Duration
+		for j := start; j <= end; j++ {
+			dur := eventDur
+			if !cfg.ReviewEventsHaveDuration && (events[j].Kind == "review" || events[j].Kind == "review_comment") {
+				dur = 0
 			}
-			totalGitHub += counted
-			fmt.Printf("  Gap %d->%d: %v (actual: %v)\n", j-start, j-start+1, counted, gap)
+			sessionGitHub += dur
+			fmt.Printf("  Event %d (%s): %v\n", j-start, events[j].Kind, dur)
 		}
+		totalGitHub += sessionGitHub
 
 		// Context out
 		totalContext += contextOut
 		fmt.Printf("  Context Out: %v\n", contextOut)
 		fmt.Printf("  Session Total - GitHub: %v, Context: %v\n\n",
-			(time.Duration(eventsInSession) * eventDur),
+			sessionGitHub,
 			contextIn+contextOut)
 
 		i = end + 1
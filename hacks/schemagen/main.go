@@ -0,0 +1,18 @@
+// Package main prints the JSON Schema for prcost's JSON output, published as schema/prcost.schema.json.
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/codeGROOVE-dev/prcost/internal/server"
+)
+
+func main() {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(server.Schema()); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,19 @@
+// Package hostname normalizes the bare hostnames prcost accepts for self-hosted GitHub and
+// GitLab instances.
+package hostname
+
+import "strings"
+
+// Parse trims and lowercases h, returning def if h is empty. ok is false if h is anything other
+// than a bare hostname (a URL, host:port, or userinfo), so PR URLs and tokens can't be
+// redirected to arbitrary servers.
+func Parse(h, def string) (host string, ok bool) {
+	h = strings.ToLower(strings.TrimSpace(h))
+	if h == "" {
+		return def, true
+	}
+	if strings.ContainsAny(h, "/:@?#") {
+		return "", false
+	}
+	return h, true
+}
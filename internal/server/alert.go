@@ -0,0 +1,186 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+const (
+	// budgetAlertTimeout bounds one webhook delivery, which runs after the response is sent.
+	budgetAlertTimeout = 10 * time.Second
+	// budgetAlertCooldown is how long an org stays quiet after an alert, so that repeated
+	// analyses don't page again within the weekly threshold's period.
+	budgetAlertCooldown = 7 * 24 * time.Hour
+)
+
+// BudgetAlert configures a webhook notified when an org analysis finds preventable waste
+// above a threshold. A zero threshold disables that check.
+type BudgetAlert struct {
+	WebhookURL      string
+	WeeklyThreshold float64 // Preventable cost per week (WasteCostPerWeek)
+	AnnualThreshold float64 // Annualized preventable cost (WasteCostPerWeek * 52)
+}
+
+// BudgetBreach describes one threshold that was exceeded.
+type BudgetBreach struct {
+	Metric    string  `json:"metric"` // "waste_cost_per_week" or "annual_waste_cost"
+	Threshold float64 `json:"threshold"`
+	Actual    float64 `json:"actual"`
+}
+
+// BudgetAlertPayload is the JSON body POSTed to the budget alert webhook.
+// Text is a one-line summary, so chat webhooks (e.g. Slack) can display it directly.
+//
+//nolint:govet // fieldalignment: API struct field order optimized for readability
+type BudgetAlertPayload struct {
+	Text              string         `json:"text"`
+	Org               string         `json:"org"`
+	Breaches          []BudgetBreach `json:"breaches"`
+	WasteCostPerWeek  float64        `json:"waste_cost_per_week"`
+	WasteHoursPerWeek float64        `json:"waste_hours_per_week"`
+	AnnualWasteCost   float64        `json:"annual_waste_cost"`
+	TotalPRs          int            `json:"total_prs"`
+	SampledPRs        int            `json:"sampled_prs"`
+	Days              int            `json:"days"`
+	Timestamp         time.Time      `json:"timestamp"`
+	Commit            string         `json:"commit"`
+}
+
+// SetBudgetAlert enables the budget alert webhook for org analyses.
+func (s *Server) SetBudgetAlert(alert BudgetAlert) error {
+	u, err := url.Parse(alert.WebhookURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("invalid budget alert webhook URL %q", alert.WebhookURL)
+	}
+	if alert.WeeklyThreshold < 0 || alert.AnnualThreshold < 0 {
+		return errors.New("budget alert thresholds must not be negative")
+	}
+	if alert.WeeklyThreshold == 0 && alert.AnnualThreshold == 0 {
+		return errors.New("budget alert requires a weekly or annual threshold")
+	}
+	s.budgetAlert = &alert
+	return nil
+}
+
+// budgetBreaches returns the thresholds exceeded by an extrapolated org analysis.
+func budgetBreaches(alert *BudgetAlert, ext *cost.ExtrapolatedBreakdown) []BudgetBreach {
+	var breaches []BudgetBreach
+	if alert.WeeklyThreshold > 0 && ext.WasteCostPerWeek > alert.WeeklyThreshold {
+		breaches = append(breaches, BudgetBreach{Metric: "waste_cost_per_week", Threshold: alert.WeeklyThreshold, Actual: ext.WasteCostPerWeek})
+	}
+	if annual := ext.WasteCostPerWeek * 52; alert.AnnualThreshold > 0 && annual > alert.AnnualThreshold {
+		breaches = append(breaches, BudgetBreach{Metric: "annual_waste_cost", Threshold: alert.AnnualThreshold, Actual: annual})
+	}
+	return breaches
+}
+
+// budgetAlertApplies reports whether an org request measures what the operator's thresholds
+// are about: the server's own cost config, every PR, and the default window. Callers can't
+// trigger an alert with a tuned salary, a narrow filter or an odd window.
+func budgetAlertApplies(req *OrgSampleRequest) bool {
+	return req.Config == nil && req.filter().IsZero() && req.Since == "" && req.Until == "" && req.Days == defaultSampleDays
+}
+
+// claimBudgetAlert reports whether org may be alerted now, and if so starts its cooldown.
+func (s *Server) claimBudgetAlert(org string, now time.Time) bool {
+	s.budgetAlertsMu.Lock()
+	defer s.budgetAlertsMu.Unlock()
+	key := strings.ToLower(org)
+	if last, ok := s.budgetAlertedAt[key]; ok && now.Sub(last) < budgetAlertCooldown {
+		return false
+	}
+	if s.budgetAlertedAt == nil {
+		s.budgetAlertedAt = make(map[string]time.Time)
+	}
+	s.budgetAlertedAt[key] = now
+	return true
+}
+
+// releaseBudgetAlert ends org's cooldown after a failed delivery, so the next analysis retries.
+func (s *Server) releaseBudgetAlert(org string) {
+	s.budgetAlertsMu.Lock()
+	defer s.budgetAlertsMu.Unlock()
+	delete(s.budgetAlertedAt, strings.ToLower(org))
+}
+
+// checkBudgetAlert POSTs to the budget alert webhook in the background if a default org
+// analysis (see budgetAlertApplies) exceeds a configured threshold. Each org is alerted at most
+// once per budgetAlertCooldown. A failed delivery is only logged.
+func (s *Server) checkBudgetAlert(ctx context.Context, req *OrgSampleRequest, days int, ext *cost.ExtrapolatedBreakdown) {
+	if s.budgetAlert == nil || !budgetAlertApplies(req) {
+		return
+	}
+	breaches := budgetBreaches(s.budgetAlert, ext)
+	if len(breaches) == 0 {
+		return
+	}
+	org := req.Org
+	if !s.claimBudgetAlert(org, time.Now()) {
+		s.logger.InfoContext(ctx, "Budget alert already sent recently", "org", org)
+		return
+	}
+
+	payload := BudgetAlertPayload{
+		Text: fmt.Sprintf("prcost: %s preventable waste is $%.0f/week ($%.0f/year), above the $%.0f %s threshold",
+			org, ext.WasteCostPerWeek, ext.WasteCostPerWeek*52, breaches[0].Threshold, breaches[0].Metric),
+		Org:               org,
+		Breaches:          breaches,
+		WasteCostPerWeek:  ext.WasteCostPerWeek,
+		WasteHoursPerWeek: ext.WasteHoursPerWeek,
+		AnnualWasteCost:   ext.WasteCostPerWeek * 52,
+		TotalPRs:          ext.TotalPRs,
+		SampledPRs:        ext.SuccessfulSamples,
+		Days:              days,
+		Timestamp:         time.Now(),
+		Commit:            s.serverCommit,
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		s.releaseBudgetAlert(org)
+		s.logger.WarnContext(ctx, "Failed to serialize budget alert", "org", org, errorKey, err)
+		return
+	}
+
+	// The webhook may be slow; the org's result is already on its way to the caller
+	alertCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), budgetAlertTimeout)
+	webhookURL := s.budgetAlert.WebhookURL
+	go func() {
+		defer cancel()
+		if err := postBudgetAlert(alertCtx, webhookURL, data); err != nil {
+			s.releaseBudgetAlert(org)
+			s.logger.WarnContext(alertCtx, "Failed to send budget alert", "org", org, errorKey, err)
+			return
+		}
+		s.logger.InfoContext(alertCtx, "Sent budget alert", "org", org,
+			"waste_cost_per_week", payload.WasteCostPerWeek, "breaches", len(breaches))
+	}()
+}
+
+// postBudgetAlert sends a JSON payload to the webhook.
+func postBudgetAlert(ctx context.Context, webhookURL string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // best effort close
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
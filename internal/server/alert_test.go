@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+func TestSetBudgetAlert(t *testing.T) {
+	tests := []struct {
+		name    string
+		alert   BudgetAlert
+		wantErr bool
+	}{
+		{"weekly threshold", BudgetAlert{WebhookURL: "https://hooks.example.com/x", WeeklyThreshold: 1000}, false},
+		{"annual threshold", BudgetAlert{WebhookURL: "https://hooks.example.com/x", AnnualThreshold: 50000}, false},
+		{"no threshold", BudgetAlert{WebhookURL: "https://hooks.example.com/x"}, true},
+		{"negative threshold", BudgetAlert{WebhookURL: "https://hooks.example.com/x", WeeklyThreshold: -1}, true},
+		{"missing URL", BudgetAlert{WeeklyThreshold: 1000}, true},
+		{"non-HTTP URL", BudgetAlert{WebhookURL: "ftp://hooks.example.com/x", WeeklyThreshold: 1000}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := New().SetBudgetAlert(tt.alert); (err != nil) != tt.wantErr {
+				t.Errorf("SetBudgetAlert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBudgetBreaches(t *testing.T) {
+	ext := &cost.ExtrapolatedBreakdown{WasteCostPerWeek: 2000} // $104,000/year
+
+	tests := []struct {
+		name  string
+		alert BudgetAlert
+		want  []string
+	}{
+		{"below weekly", BudgetAlert{WeeklyThreshold: 2500}, nil},
+		{"equal to weekly", BudgetAlert{WeeklyThreshold: 2000}, nil},
+		{"above weekly", BudgetAlert{WeeklyThreshold: 1500}, []string{"waste_cost_per_week"}},
+		{"above annual only", BudgetAlert{WeeklyThreshold: 2500, AnnualThreshold: 100000}, []string{"annual_waste_cost"}},
+		{"above both", BudgetAlert{WeeklyThreshold: 1000, AnnualThreshold: 100000}, []string{"waste_cost_per_week", "annual_waste_cost"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := budgetBreaches(&tt.alert, ext)
+			if len(got) != len(tt.want) {
+				t.Fatalf("budgetBreaches() = %+v, want metrics %v", got, tt.want)
+			}
+			for i, b := range got {
+				if b.Metric != tt.want[i] {
+					t.Errorf("breach %d metric = %q, want %q", i, b.Metric, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCheckBudgetAlert(t *testing.T) {
+	received := make(chan []byte, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil || r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected webhook request: %s %s (%v)", r.Method, r.Header.Get("Content-Type"), err)
+		}
+		received <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	s := New()
+	s.SetCommit("abc123")
+	if err := s.SetBudgetAlert(BudgetAlert{WebhookURL: ts.URL, WeeklyThreshold: 1000}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Below threshold: no webhook
+	s.checkBudgetAlert(context.Background(), &OrgSampleRequest{Org: "quietorg", Days: defaultSampleDays}, 30, &cost.ExtrapolatedBreakdown{WasteCostPerWeek: 999})
+	expectNoAlert := func(why string) {
+		t.Helper()
+		select {
+		case body := <-received:
+			t.Fatalf("Webhook fired %s: %s", why, body)
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+	expectNoAlert("below threshold")
+
+	// Requests that don't measure the whole org with the server's config never alert
+	noisy := &cost.ExtrapolatedBreakdown{WasteCostPerWeek: 1500, TotalPRs: 400, SuccessfulSamples: 50}
+	for name, req := range map[string]*OrgSampleRequest{
+		"config override": {Org: "noisyorg", Days: defaultSampleDays, Config: &ConfigOverride{}},
+		"label filter":    {Org: "noisyorg", Days: defaultSampleDays, Labels: []string{"team/payments"}},
+		"author filter":   {Org: "noisyorg", Days: defaultSampleDays, Author: "alice"},
+		"custom window":   {Org: "noisyorg", Days: 30},
+		"since":           {Org: "noisyorg", Days: defaultSampleDays, Since: "2025-01-01"},
+	} {
+		s.checkBudgetAlert(context.Background(), req, 30, noisy)
+		expectNoAlert("for a request with a " + name)
+	}
+
+	// Above threshold: webhook fires even if the request context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	s.checkBudgetAlert(ctx, &OrgSampleRequest{Org: "noisyorg", Days: defaultSampleDays}, 30, noisy)
+	cancel()
+	select {
+	case body := <-received:
+		var got BudgetAlertPayload
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatalf("Webhook payload is not valid JSON: %v", err)
+		}
+		if got.Org != "noisyorg" || got.Commit != "abc123" || got.Days != 30 || got.AnnualWasteCost != 1500*52 || got.Text == "" {
+			t.Errorf("Unexpected payload: %+v", got)
+		}
+		if len(got.Breaches) != 1 || got.Breaches[0].Threshold != 1000 || got.Breaches[0].Actual != 1500 {
+			t.Errorf("Breaches = %+v, want weekly threshold 1000 with actual 1500", got.Breaches)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for budget alert webhook")
+	}
+
+	// The same org isn't alerted again within the cooldown
+	s.checkBudgetAlert(context.Background(), &OrgSampleRequest{Org: "NoisyOrg", Days: defaultSampleDays}, 30, noisy)
+	expectNoAlert("twice within the cooldown")
+}
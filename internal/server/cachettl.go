@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// errInvalidCacheTTL is returned by SetCacheTTL for negative durations.
+var errInvalidCacheTTL = errors.New("cache TTL must not be negative")
+
+// SetCacheTTL makes in-memory PR query, PR data and calculation cache entries expire ttl after
+// they are written. Zero, the default, keeps entries until the process exits, which suits
+// ephemeral deployments such as Cloud Run. Long-lived servers should set a TTL so stale data is
+// refetched and expired entries are swept instead of accumulating. Call before serving requests.
+func (s *Server) SetCacheTTL(ttl time.Duration) error {
+	if ttl < 0 {
+		return errInvalidCacheTTL
+	}
+	if s.stopCacheSweep != nil {
+		s.stopCacheSweep()
+		s.stopCacheSweep = nil
+	}
+	s.cacheTTL = ttl
+	if ttl > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopCacheSweep = cancel
+		go s.sweepCachesPeriodically(ctx, ttl)
+	}
+	s.logger.InfoContext(context.Background(), "In-memory cache TTL configured", "ttl", ttl)
+	return nil
+}
+
+// newCacheEntry wraps data in an in-memory cache entry that expires after the configured cache TTL.
+func (s *Server) newCacheEntry(data any) *cacheEntry {
+	entry := &cacheEntry{data: data}
+	if s.cacheTTL > 0 {
+		entry.expiresAt = time.Now().Add(s.cacheTTL)
+	}
+	return entry
+}
+
+// expired reports whether e has passed its expiry; entries written without a TTL never expire.
+func (e *cacheEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// sweepCachesPeriodically drops expired in-memory cache entries every interval until ctx is done.
+func (s *Server) sweepCachesPeriodically(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.sweepExpiredCacheEntries(ctx, now)
+		}
+	}
+}
+
+// sweepExpiredCacheEntries drops every in-memory cache entry that expired by now.
+func (s *Server) sweepExpiredCacheEntries(ctx context.Context, now time.Time) {
+	removed := sweepExpired(&s.prQueryCacheMu, s.prQueryCache, now) +
+		sweepExpired(&s.prDataCacheMu, s.prDataCache, now) +
+		sweepExpired(&s.calcResultCacheMu, s.calcResultCache, now)
+	if removed > 0 {
+		s.logger.DebugContext(ctx, "[sweepCachesPeriodically] Dropped expired cache entries", "count", removed)
+	}
+}
+
+// sweepExpired deletes expired entries from cache under mu and returns how many were deleted.
+func sweepExpired(mu *sync.RWMutex, cache map[string]*cacheEntry, now time.Time) int {
+	mu.Lock()
+	defer mu.Unlock()
+	removed := 0
+	for key, entry := range cache {
+		if entry.expired(now) {
+			delete(cache, key)
+			removed++
+		}
+	}
+	return removed
+}
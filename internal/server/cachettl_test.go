@@ -0,0 +1,44 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+func TestSetCacheTTL(t *testing.T) {
+	s := New()
+	defer s.Shutdown()
+	if err := s.SetCacheTTL(-time.Minute); !errors.Is(err, errInvalidCacheTTL) {
+		t.Errorf("SetCacheTTL(-1m) = %v, want %v", err, errInvalidCacheTTL)
+	}
+
+	// Without a TTL, entries never expire
+	s.cachePRData(t.Context(), "pr:a", cost.PRData{Author: "alice"})
+	if entry := s.prDataCache["pr:a"]; entry.expired(time.Now().Add(24 * 365 * time.Hour)) {
+		t.Error("entry written without a TTL expired")
+	}
+
+	if err := s.SetCacheTTL(time.Hour); err != nil {
+		t.Fatalf("SetCacheTTL(1h) = %v", err)
+	}
+	s.cachePRData(t.Context(), "pr:b", cost.PRData{Author: "bob"})
+	if _, ok := s.cachedPRData(t.Context(), "pr:b"); !ok {
+		t.Fatal("cachedPRData() missed a fresh entry")
+	}
+
+	// Expired entries are cache misses and are dropped by the sweep
+	s.prDataCache["pr:b"].expiresAt = time.Now().Add(-time.Second)
+	if _, ok := s.cachedPRData(t.Context(), "pr:b"); ok {
+		t.Error("cachedPRData() returned an expired entry")
+	}
+	s.sweepExpiredCacheEntries(t.Context(), time.Now())
+	if _, exists := s.prDataCache["pr:b"]; exists {
+		t.Error("sweep kept an expired entry")
+	}
+	if _, exists := s.prDataCache["pr:a"]; !exists {
+		t.Error("sweep dropped an entry without a TTL")
+	}
+}
@@ -0,0 +1,308 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+// Layout of the SVG cost card, in pixels.
+const (
+	cardWidth     = 640
+	cardPadding   = 24
+	cardLabelW    = 170 // Width of the bar labels column
+	cardAmountW   = 100 // Width of the amounts column
+	cardBarHeight = 18
+	cardRowHeight = 30
+	cardGradeW    = 120
+)
+
+// ExportRequest is the result to render as an SVG card: the breakdown of a CalculateResponse
+// or the extrapolated breakdown of a SampleResponse. Only the fields the card draws are needed,
+// and the web UI sends just those, so large organization results stay under the size limit.
+//
+//nolint:govet // fieldalignment: API struct field order optimized for readability
+type ExportRequest struct {
+	Title        string                      `json:"title,omitempty"` // e.g. the PR URL or "owner/repo"; shown as the heading
+	Breakdown    *cost.Breakdown             `json:"breakdown,omitempty"`
+	Extrapolated *cost.ExtrapolatedBreakdown `json:"extrapolated,omitempty"`
+}
+
+// costCard is the content of an SVG cost card, from either kind of result.
+type costCard struct {
+	title    string
+	subtitle string
+	currency string
+	total    float64
+	bars     []cardBar
+	grades   []cardGrade
+}
+
+// cardBar is one cost line item, drawn as a bar proportional to the largest.
+type cardBar struct {
+	label string
+	cost  float64
+}
+
+// cardGrade is one letter grade shown at the top of the card.
+type cardGrade struct {
+	label  string
+	grade  string
+	detail string
+}
+
+// handleExportSVG renders a result the client already has as a shareable SVG card with its
+// cost bars and grades. It fetches nothing, so it needs no GitHub token.
+func (s *Server) handleExportSVG(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+
+	if !s.allowRequest(writer, request, "handleExportSVG") {
+		return
+	}
+
+	req, err := s.parseExportRequest(ctx, request)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var card costCard
+	if req.Breakdown != nil {
+		card = breakdownCard(req.Title, req.Breakdown)
+	} else {
+		card = extrapolatedCard(req.Title, req.Extrapolated)
+	}
+
+	writer.Header().Set("Content-Type", "image/svg+xml")
+	writer.Header().Set("Content-Disposition", `inline; filename="prcost.svg"`)
+	writer.WriteHeader(http.StatusOK)
+	if _, err := writer.Write(renderCardSVG(&card)); err != nil {
+		s.logger.ErrorContext(ctx, "[handleExportSVG] Error writing response", errorKey, err)
+	}
+}
+
+// parseExportRequest parses an export request, which must carry exactly one result.
+func (s *Server) parseExportRequest(ctx context.Context, r *http.Request) (*ExportRequest, error) {
+	var req ExportRequest
+
+	// SECURITY: Limit request body size to prevent memory exhaustion DoS.
+	const maxRequestSize = 1 << 20 // 1MB
+	r.Body = http.MaxBytesReader(nil, r.Body, maxRequestSize)
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.logger.ErrorContext(ctx, "[parseExportRequest] Failed to decode JSON", errorKey, sanitizeError(err))
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if (req.Breakdown == nil) == (req.Extrapolated == nil) {
+		return nil, errors.New("exactly one of breakdown or extrapolated is required")
+	}
+	const maxTitleLength = 200
+	if len(req.Title) > maxTitleLength {
+		return nil, fmt.Errorf("title is longer than %d characters", maxTitleLength)
+	}
+	return &req, nil
+}
+
+// breakdownCard summarizes a single PR's costs.
+func breakdownCard(title string, b *cost.Breakdown) costCard {
+	if title == "" {
+		title = "Pull request cost"
+	}
+	var participants float64
+	for _, p := range b.Participants {
+		participants += p.TotalCost
+	}
+	d := &b.DelayCostDetail
+	subtitle := fmt.Sprintf("PR by %s, open %s", b.PRAuthor, cardDuration(b.PRDuration))
+	if b.Merged {
+		subtitle = fmt.Sprintf("PR by %s, merged after %s", b.PRAuthor, cardDuration(b.PRDuration))
+	}
+	return costCard{
+		title:    title,
+		subtitle: subtitle,
+		currency: b.Currency,
+		total:    b.TotalCost,
+		bars: []cardBar{
+			{"Development", b.Author.TotalCost},
+			{"Review & collaboration", participants},
+			{"Delivery delay", d.DeliveryDelayCost},
+			{"Code churn", d.CodeChurnCost},
+			{"Tracking & updates", d.AutomatedUpdatesCost + d.PRTrackingCost},
+			{"Future costs", d.FutureReviewCost + d.FutureMergeCost + d.FutureContextCost},
+		},
+		grades: []cardGrade{
+			{"Efficiency", b.EfficiencyGrade, fmt.Sprintf("%.0f%%", b.EfficiencyPct)},
+			{"Merge velocity", b.MergeVelocityGrade, b.MergeVelocityMessage},
+		},
+	}
+}
+
+// extrapolatedCard summarizes a repository's or organization's extrapolated costs.
+func extrapolatedCard(title string, e *cost.ExtrapolatedBreakdown) costCard {
+	if title == "" {
+		title = "Pull request costs"
+	}
+	return costCard{
+		title:    title,
+		subtitle: fmt.Sprintf("%d PRs by %d authors, estimated from %d samples", e.TotalPRs, e.TotalAuthors, e.SuccessfulSamples),
+		currency: e.Currency,
+		total:    e.TotalCost,
+		bars: []cardBar{
+			{"Development", e.AuthorTotalCost},
+			{"Review & collaboration", e.ParticipantTotalCost},
+			{"Delivery delay", e.DeliveryDelayCost},
+			{"Code churn", e.CodeChurnCost},
+			{"Tracking & updates", e.AutomatedUpdatesCost + e.PRTrackingCost},
+			{"Future costs", e.FutureReviewCost + e.FutureMergeCost + e.FutureContextCost},
+		},
+		grades: []cardGrade{
+			{"Efficiency", e.EfficiencyGrade, fmt.Sprintf("%.0f%%", e.EfficiencyPct)},
+			{"Merge velocity", e.MergeVelocityGrade, e.MergeVelocityMessage},
+			{"Merge rate", e.MergeRateGrade, fmt.Sprintf("%.0f%%", e.MergeRate)},
+		},
+	}
+}
+
+// renderCardSVG draws card as a standalone SVG document. All text is escaped, and the
+// document has no scripts or external references, so it is safe to serve and embed.
+func renderCardSVG(card *costCard) []byte {
+	var bars []cardBar
+	maxCost := 0.0
+	for _, bar := range card.bars {
+		if bar.cost <= 0 {
+			continue
+		}
+		bars = append(bars, bar)
+		maxCost = math.Max(maxCost, bar.cost)
+	}
+	gradesTop := 96
+	barsTop := gradesTop + 70 + 16
+	height := barsTop + len(bars)*cardRowHeight + 36
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" `+
+		`font-family="-apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif">`+"\n",
+		cardWidth, height, cardWidth, height)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" rx="12" fill="#ffffff" stroke="#d0d7de"/>`+"\n", cardWidth, height)
+	fmt.Fprintf(&buf, `<text x="%d" y="40" font-size="20" font-weight="600" fill="#1f2328">%s</text>`+"\n",
+		cardPadding, html.EscapeString(truncateCardText(card.title, 40)))
+	fmt.Fprintf(&buf, `<text x="%d" y="64" font-size="13" fill="#656d76">%s</text>`+"\n",
+		cardPadding, html.EscapeString(truncateCardText(card.subtitle, 80)))
+	fmt.Fprintf(&buf, `<text x="%d" y="40" font-size="22" font-weight="700" fill="#1f2328" text-anchor="end">%s</text>`+"\n",
+		cardWidth-cardPadding, html.EscapeString(cardMoney(card.total, card.currency)))
+	fmt.Fprintf(&buf, `<text x="%d" y="64" font-size="13" fill="#656d76" text-anchor="end">total cost</text>`+"\n",
+		cardWidth-cardPadding)
+
+	for i, g := range card.grades {
+		if g.grade == "" {
+			continue
+		}
+		x := cardPadding + i*(cardGradeW+12)
+		fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="70" rx="8" fill="%s" fill-opacity="0.12" stroke="%s"/>`+"\n",
+			x, gradesTop, cardGradeW, gradeColor(g.grade), gradeColor(g.grade))
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" font-size="28" font-weight="700" fill="%s">%s</text>`+"\n",
+			x+12, gradesTop+36, gradeColor(g.grade), html.EscapeString(g.grade))
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" font-size="12" fill="#1f2328">%s</text>`+"\n",
+			x+64, gradesTop+30, html.EscapeString(g.label))
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" font-size="11" fill="#656d76">%s</text>`+"\n",
+			x+12, gradesTop+58, html.EscapeString(truncateCardText(g.detail, 18)))
+	}
+
+	barMaxW := float64(cardWidth - 2*cardPadding - cardLabelW - cardAmountW)
+	for i, bar := range bars {
+		y := barsTop + i*cardRowHeight
+		width := math.Max(1, barMaxW*bar.cost/maxCost)
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" font-size="13" fill="#1f2328">%s</text>`+"\n",
+			cardPadding, y+13, html.EscapeString(bar.label))
+		fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%.1f" height="%d" rx="3" fill="%s"/>`+"\n",
+			cardPadding+cardLabelW, y, width, cardBarHeight, barColor(bar.label))
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" font-size="13" fill="#1f2328" text-anchor="end">%s</text>`+"\n",
+			cardWidth-cardPadding, y+13, html.EscapeString(cardMoney(bar.cost, card.currency)))
+	}
+	fmt.Fprintf(&buf, `<text x="%d" y="%d" font-size="11" fill="#8c959f">Generated by prcost</text>`+"\n",
+		cardPadding, height-14)
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
+
+// gradeColor returns the color for a letter grade, green for A through red for F.
+func gradeColor(grade string) string {
+	switch {
+	case strings.HasPrefix(grade, "A"):
+		return "#1a7f37"
+	case strings.HasPrefix(grade, "B"):
+		return "#4d8c1f"
+	case strings.HasPrefix(grade, "C"):
+		return "#9a6700"
+	case strings.HasPrefix(grade, "D"):
+		return "#bc4c00"
+	default:
+		return "#cf222e"
+	}
+}
+
+// barColors are the colors of the cost bars by label: blues for work, oranges for delay. Each
+// category keeps its color when bars without cost are left out.
+var barColors = map[string]string{
+	"Development":            "#0969da",
+	"Review & collaboration": "#54aeff",
+	"Delivery delay":         "#fb8f44",
+	"Code churn":             "#e16f24",
+	"Tracking & updates":     "#bc4c00",
+	"Future costs":           "#d4a72c",
+}
+
+// barColor returns the color of the cost bar with the given label, gray for an unknown one.
+func barColor(label string) string {
+	if color, ok := barColors[label]; ok {
+		return color
+	}
+	return "#8c959f"
+}
+
+// cardMoney formats amount rounded to whole units with thousands separators: "$12,345" for
+// US dollars, or prefixed with its ISO 4217 code ("EUR 12,345") for other currencies.
+func cardMoney(amount float64, currency string) string {
+	digits := strconv.FormatFloat(math.Abs(math.Round(amount)), 'f', 0, 64)
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	sign := ""
+	if math.Round(amount) < 0 {
+		sign = "-"
+	}
+	if currency == "" || currency == "USD" {
+		return sign + "$" + grouped.String()
+	}
+	return sign + currency + " " + grouped.String()
+}
+
+// cardDuration formats hours as hours under two days, and days otherwise.
+func cardDuration(hours float64) string {
+	if hours < 48 {
+		return fmt.Sprintf("%.1f hours", hours)
+	}
+	return fmt.Sprintf("%.1f days", hours/24)
+}
+
+// truncateCardText shortens s to at most n characters, so long titles don't overflow the card.
+func truncateCardText(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+func TestHandleExportSVG(t *testing.T) {
+	s := New()
+	created := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	breakdown := cost.Calculate(cost.NewPRData("alice", created, created.Add(30*time.Hour), true, 400, 20, []cost.ParticipantEvent{
+		{Timestamp: created, Actor: "alice", Kind: "commit"},
+		{Timestamp: created.Add(20 * time.Hour), Actor: "bob", Kind: "review", State: "approved"},
+	}), cost.DefaultConfig())
+	extrapolated := cost.ExtrapolateFromSamples([]cost.Breakdown{breakdown}, 10, 3, 0, 30, cost.DefaultConfig(), nil, nil)
+
+	export := func(body any) *httptest.ResponseRecorder {
+		t.Helper()
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/export/svg", strings.NewReader(string(data))))
+		return w
+	}
+
+	w := export(CalculateResponse{Breakdown: breakdown})
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/svg+xml" {
+		t.Fatalf("export breakdown = %d %q, want 200 image/svg+xml", w.Code, w.Header().Get("Content-Type"))
+	}
+	svg := w.Body.String()
+	for _, want := range []string{"Pull request cost", "PR by alice, merged after 30.0 hours", breakdown.EfficiencyGrade,
+		"Development", "Delivery delay", cardMoney(breakdown.TotalCost, "")} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("breakdown SVG doesn't contain %q:\n%s", want, svg)
+		}
+	}
+	if strings.Contains(svg, "Future costs") {
+		t.Error("merged PR's SVG has a future costs bar, want only non-zero costs")
+	}
+	checkWellFormed(t, svg)
+
+	// Titles are escaped, so a hostile title can't inject markup
+	w = export(map[string]any{"title": `<script>alert("x")</script> & co`, "extrapolated": extrapolated})
+	svg = w.Body.String()
+	if w.Code != http.StatusOK || strings.Contains(svg, "<script>") || !strings.Contains(svg, "&lt;script&gt;") {
+		t.Errorf("export with markup in title = %d:\n%s", w.Code, svg)
+	}
+	if !strings.Contains(svg, "Merge rate") || !strings.Contains(svg, "10 PRs by 3 authors") {
+		t.Errorf("extrapolated SVG lacks the merge rate grade or sample summary:\n%s", svg)
+	}
+	checkWellFormed(t, svg)
+
+	// Bars keep their category's color when the bars before them have no cost, and a request
+	// with only the fields the card draws is enough
+	w = export(map[string]any{"extrapolated": map[string]any{"total_cost": 100, "delivery_delay_cost": 100}})
+	svg = w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(svg, `fill="`+barColors["Delivery delay"]+`"`) ||
+		strings.Contains(svg, `fill="`+barColors["Development"]+`"`) {
+		t.Errorf("delivery delay bar isn't colored %s:\n%s", barColors["Delivery delay"], svg)
+	}
+
+	for name, body := range map[string]any{
+		"no result":   map[string]any{"title": "x"},
+		"two results": map[string]any{"breakdown": breakdown, "extrapolated": extrapolated},
+		"long title":  map[string]any{"title": strings.Repeat("x", 201), "breakdown": breakdown},
+	} {
+		if w := export(body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: export = %d, want 400", name, w.Code)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/export/svg", http.NoBody))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /v1/export/svg = %d, want 405", w.Code)
+	}
+}
+
+// checkWellFormed fails the test if svg isn't well-formed XML.
+func checkWellFormed(t *testing.T, svg string) {
+	t.Helper()
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		_, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			t.Fatalf("SVG isn't well-formed: %v\n%s", err, svg)
+		}
+	}
+}
+
+func TestCardMoney(t *testing.T) {
+	for _, tc := range []struct {
+		amount   float64
+		currency string
+		want     string
+	}{
+		{0, "", "$0"},
+		{999.6, "USD", "$1,000"},
+		{1234567.4, "", "$1,234,567"},
+		{-4321, "", "-$4,321"},
+		{12345, "EUR", "EUR 12,345"},
+	} {
+		if got := cardMoney(tc.amount, tc.currency); got != tc.want {
+			t.Errorf("cardMoney(%v, %q) = %q, want %q", tc.amount, tc.currency, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+// CompareRequest represents a request to compare the costs of two PRs.
+//
+//nolint:govet // fieldalignment: API struct field order optimized for readability
+type CompareRequest struct {
+	URLA      string          `json:"url_a"`
+	URLB      string          `json:"url_b"`
+	Config    *ConfigOverride `json:"config,omitempty"`
+	Anonymize bool            `json:"anonymize,omitempty"` // Replace logins with pseudonyms, consistently across both PRs
+}
+
+// CompareDelta holds per-component differences between two PRs (B minus A).
+//
+//nolint:govet // fieldalignment: API struct field order optimized for readability
+type CompareDelta struct {
+	AuthorCost      float64 `json:"author_cost"`
+	ParticipantCost float64 `json:"participant_cost"`
+	DelayCost       float64 `json:"delay_cost"`
+	TotalCost       float64 `json:"total_cost"`
+	PRDuration      float64 `json:"pr_duration"` // Hours
+	EfficiencyA     float64 `json:"efficiency_a"`
+	EfficiencyB     float64 `json:"efficiency_b"`
+	MoreEfficient   string  `json:"more_efficient"` // "a", "b", or "" when equal
+}
+
+// CompareResponse represents the response from a PR comparison.
+//
+//nolint:govet // fieldalignment: API struct field order optimized for readability
+type CompareResponse struct {
+	A         cost.Breakdown `json:"a"`
+	B         cost.Breakdown `json:"b"`
+	Delta     CompareDelta   `json:"delta"`
+	Timestamp time.Time      `json:"timestamp"`
+	Commit    string         `json:"commit"`
+}
+
+// handleCompare processes requests comparing the costs of two PRs.
+func (s *Server) handleCompare(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+
+	if !s.allowRequest(writer, request, "handleCompare") {
+		return
+	}
+
+	req, err := s.parseCompareRequest(ctx, request)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "[handleCompare] Failed to parse request", "remote_addr", request.RemoteAddr, errorKey, sanitizeError(err))
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Both PRs are fetched with one token, so the first PR's owner chooses the installation
+	owner, repo := prOwnerRepo(req.URLA)
+	token, ok := s.requestToken(writer, request, "handleCompare", owner, repo)
+	if !ok {
+		return
+	}
+
+	response, err := s.processCompare(ctx, req, token)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "[handleCompare] Error processing request",
+			"remote_addr", request.RemoteAddr, "url_a", req.URLA, "url_b", req.URLB, errorKey, sanitizeError(err))
+		http.Error(writer, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(writer).Encode(response); err != nil {
+		s.logger.ErrorContext(ctx, "[handleCompare] Error encoding response", errorKey, err)
+		return
+	}
+
+	s.logger.InfoContext(ctx, "[handleCompare] Request completed",
+		"url_a", req.URLA, "url_b", req.URLB, "total_cost_delta", response.Delta.TotalCost)
+}
+
+// parseCompareRequest parses and validates a comparison request.
+func (s *Server) parseCompareRequest(ctx context.Context, r *http.Request) (*CompareRequest, error) {
+	var req CompareRequest
+
+	// SECURITY: Limit request body size to prevent memory exhaustion DoS.
+	const maxRequestSize = 1 << 20 // 1MB
+	r.Body = http.MaxBytesReader(nil, r.Body, maxRequestSize)
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.logger.ErrorContext(ctx, "[parseCompareRequest] Failed to decode JSON", errorKey, sanitizeError(err))
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if req.URLA == "" || req.URLB == "" {
+		s.logger.ErrorContext(ctx, "[parseCompareRequest] Missing required field: url_a or url_b")
+		return nil, errors.New("missing required fields: url_a and url_b")
+	}
+
+	for _, prURL := range []string{req.URLA, req.URLB} {
+		if err := s.validatePRURL(prURL); err != nil {
+			s.logger.ErrorContext(ctx, "[parseCompareRequest] Invalid URL", "url", prURL, errorKey, err.Error())
+			return nil, err
+		}
+	}
+	if err := validateConfigOverride(req.Config); err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// processCompare calculates both PRs through processRequest, sharing its caching.
+func (s *Server) processCompare(ctx context.Context, req *CompareRequest, token string) (*CompareResponse, error) {
+	a, err := s.processRequest(ctx, &CalculateRequest{URL: req.URLA, Config: req.Config}, token)
+	if err != nil {
+		return nil, fmt.Errorf("url_a: %w", err)
+	}
+	b, err := s.processRequest(ctx, &CalculateRequest{URL: req.URLB, Config: req.Config}, token)
+	if err != nil {
+		return nil, fmt.Errorf("url_b: %w", err)
+	}
+	if req.Anonymize {
+		names := cost.NewAnonymizer([]cost.Breakdown{a.Breakdown, b.Breakdown})
+		names.Breakdown(&a.Breakdown)
+		names.Breakdown(&b.Breakdown)
+	}
+
+	return &CompareResponse{
+		A:         a.Breakdown,
+		B:         b.Breakdown,
+		Delta:     compareBreakdowns(&a.Breakdown, &b.Breakdown),
+		Timestamp: time.Now(),
+		Commit:    s.serverCommit,
+	}, nil
+}
+
+// compareBreakdowns computes the per-component differences between two breakdowns (B minus A).
+func compareBreakdowns(a, b *cost.Breakdown) CompareDelta {
+	participantCost := func(bd *cost.Breakdown) float64 {
+		var total float64
+		for _, p := range bd.Participants {
+			total += p.TotalCost
+		}
+		return total
+	}
+
+	delta := CompareDelta{
+		AuthorCost:      b.Author.TotalCost - a.Author.TotalCost,
+		ParticipantCost: participantCost(b) - participantCost(a),
+		DelayCost:       b.DelayCost - a.DelayCost,
+		TotalCost:       b.TotalCost - a.TotalCost,
+		PRDuration:      b.PRDuration - a.PRDuration,
+		EfficiencyA:     cost.BreakdownEfficiency(a),
+		EfficiencyB:     cost.BreakdownEfficiency(b),
+	}
+	switch {
+	case delta.EfficiencyA > delta.EfficiencyB:
+		delta.MoreEfficient = "a"
+	case delta.EfficiencyB > delta.EfficiencyA:
+		delta.MoreEfficient = "b"
+	default:
+	}
+	return delta
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+func TestParseCompareRequest(t *testing.T) {
+	s := New()
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{
+			name:    "valid request",
+			body:    `{"url_a":"https://github.com/owner/repo/pull/1","url_b":"https://github.com/owner/repo/pull/2"}`,
+			wantErr: false,
+		},
+		{
+			name:    "valid request with config",
+			body:    `{"url_a":"https://github.com/owner/repo/pull/1","url_b":"https://github.com/owner/repo/pull/2","config":{"AnnualSalary":300000}}`,
+			wantErr: false,
+		},
+		{
+			name:    "missing url_b",
+			body:    `{"url_a":"https://github.com/owner/repo/pull/1"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid url_b",
+			body:    `{"url_a":"https://github.com/owner/repo/pull/1","url_b":"https://gitlab.com/owner/repo/pull/2"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			body:    `{invalid`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/compare", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			_, err := s.parseCompareRequest(req.Context(), req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseCompareRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleCompareRouting(t *testing.T) {
+	s := New()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/compare", http.NoBody)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /v1/compare status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/compare", strings.NewReader(`{"url_a":"https://github.com/owner/repo/pull/1"}`))
+	req.Header.Set("Authorization", "Bearer ghp_test")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("POST /v1/compare with missing url_b status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestCompareBreakdowns(t *testing.T) {
+	a := cost.Breakdown{
+		Author:       cost.AuthorCostDetail{TotalCost: 1000, TotalHours: 10},
+		Participants: []cost.ParticipantCostDetail{{TotalCost: 200, TotalHours: 2}},
+		DelayCost:    100,
+		DelayCostDetail: cost.DelayCostDetail{
+			DeliveryDelayHours: 1,
+			TotalDelayHours:    1,
+		},
+		TotalCost:  1300,
+		PRDuration: 4,
+	}
+	b := cost.Breakdown{
+		Author: cost.AuthorCostDetail{TotalCost: 1200, TotalHours: 12},
+		Participants: []cost.ParticipantCostDetail{
+			{TotalCost: 300, TotalHours: 3},
+			{TotalCost: 100, TotalHours: 1},
+		},
+		DelayCost: 2000,
+		DelayCostDetail: cost.DelayCostDetail{
+			DeliveryDelayHours: 20,
+			TotalDelayHours:    20,
+		},
+		TotalCost:  3600,
+		PRDuration: 96,
+	}
+
+	delta := compareBreakdowns(&a, &b)
+	if delta.AuthorCost != 200 || delta.ParticipantCost != 200 || delta.DelayCost != 1900 || delta.TotalCost != 2300 {
+		t.Errorf("compareBreakdowns() cost deltas = %+v, want author 200, participants 200, delay 1900, total 2300", delta)
+	}
+	if delta.PRDuration != 92 {
+		t.Errorf("PRDuration delta = %v, want 92", delta.PRDuration)
+	}
+	if delta.MoreEfficient != "a" || delta.EfficiencyA <= delta.EfficiencyB {
+		t.Errorf("MoreEfficient = %q (A %.1f%%, B %.1f%%), want a", delta.MoreEfficient, delta.EfficiencyA, delta.EfficiencyB)
+	}
+
+	if same := compareBreakdowns(&a, &a); same.MoreEfficient != "" || same.TotalCost != 0 {
+		t.Errorf("comparing a PR with itself = %+v, want zero delta and no winner", same)
+	}
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+// ConfigResponse is the body of the /v1/config endpoint: the default cost configuration
+// requests are calculated with, and what each of its fields means.
+type ConfigResponse struct {
+	Defaults cost.Config        `json:"defaults"` // Field names match the request "config" object; durations are nanoseconds
+	Fields   []cost.ConfigField `json:"fields"`
+	Fixed    []string           `json:"fixed"` // Fields a request's config cannot override; they always use the default
+}
+
+// fixedConfigFields are the Config fields mergeConfig ignores in a request's config: they are
+// set by whoever runs the server. Keep it in sync with mergeConfig: a test fails when it isn't.
+var fixedConfigFields = []string{
+	"AutomatedUpdatesFactor",
+	"WeeklyChurnRate",
+	"TargetMergeTimeHours",
+	"EstimateMissingEvents",
+	"CountBotParticipantCosts",
+	"ChangeTypes",
+	"SalaryCurrencies",
+	"ExchangeRates",
+	"ReportingCurrency",
+}
+
+// ConfigOverride is a request's "config" object: the cost.Config fields to change from the
+// server's defaults. Most fields apply only when positive or true, so omitting one and sending
+// its zero value mean the same. Fields whose zero value is meaningful, like ReviewWaitFactor,
+// also apply when the request sets them explicitly. Fields in fixedConfigFields are ignored.
+type ConfigOverride struct {
+	cost.Config
+
+	set map[string]bool // Lowercased names of the fields present in the JSON object
+}
+
+// UnmarshalJSON decodes the config and records which fields it sets.
+func (o *ConfigOverride) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &o.Config); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	o.set = make(map[string]bool, len(fields))
+	for name := range fields {
+		// encoding/json matches field names case-insensitively, so this does too
+		o.set[strings.ToLower(name)] = true
+	}
+	return nil
+}
+
+// isSet reports whether the request's JSON config sets the named field, even to its zero value.
+func (o *ConfigOverride) isSet(name string) bool {
+	return o.set[strings.ToLower(name)]
+}
+
+// handleConfig returns the effective default configuration and per-field metadata, so clients
+// can build tuning forms without hardcoding defaults that drift from the server's.
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := ConfigResponse{Defaults: cost.DefaultConfig(), Fields: cost.ConfigFields(), Fixed: slices.Clone(fixedConfigFields)}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		s.logger.ErrorContext(ctx, "[handleConfig] Error encoding response", errorKey, err)
+	}
+}
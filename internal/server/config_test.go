@@ -0,0 +1,80 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+func TestHandleConfig(t *testing.T) {
+	s := New()
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/config", http.NoBody))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /v1/config = %d, want 200", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var resp ConfigResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding config response: %v", err)
+	}
+	want := cost.DefaultConfig()
+	if resp.Defaults.AnnualSalary != want.AnnualSalary || resp.Defaults.EventDuration != want.EventDuration ||
+		resp.Defaults.COCOMO != want.COCOMO {
+		t.Errorf("defaults = %+v, want %+v", resp.Defaults, want)
+	}
+	if len(resp.Fields) != len(cost.ConfigFields()) {
+		t.Fatalf("got %d fields, want %d", len(resp.Fields), len(cost.ConfigFields()))
+	}
+	var salary *cost.ConfigField
+	for i := range resp.Fields {
+		if resp.Fields[i].Name == "AnnualSalary" {
+			salary = &resp.Fields[i]
+		}
+	}
+	if salary == nil || salary.Description == "" || salary.Unit == "" || salary.Min == nil || *salary.Min != 0 {
+		t.Errorf("AnnualSalary metadata = %+v, want a description, unit and min 0", salary)
+	}
+
+	if !slices.Equal(resp.Fixed, fixedConfigFields) {
+		t.Errorf("fixed = %v, want %v", resp.Fixed, fixedConfigFields)
+	}
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/config", http.NoBody))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /v1/config = %d, want 405", w.Code)
+	}
+}
+
+// TestFixedConfigFields fails when fixedConfigFields disagrees with what mergeConfig applies
+// from a request's config, so /v1/config never advertises a setting that is silently ignored.
+func TestFixedConfigFields(t *testing.T) {
+	s := New()
+	base := cost.DefaultConfig()
+	for _, field := range cost.ConfigFields() {
+		override := ConfigOverride{set: map[string]bool{strings.ToLower(field.Name): true}}
+		value := reflect.ValueOf(&override.Config).Elem()
+		for name := range strings.SplitSeq(field.Name, ".") {
+			value = value.FieldByName(name)
+		}
+		setOverrideValue(t, value, field)
+		if value.Kind() == reflect.Bool {
+			value.SetBool(!reflect.ValueOf(base).FieldByName(field.Name).Bool())
+		}
+
+		applied := !reflect.DeepEqual(s.mergeConfig(base, &override), base)
+		if fixed := slices.Contains(fixedConfigFields, field.Name); applied == fixed {
+			t.Errorf("%s: mergeConfig applies it = %v, but listed as fixed = %v", field.Name, applied, fixed)
+		}
+	}
+}
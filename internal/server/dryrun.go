@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/github"
+)
+
+// SamplePlanResponse is the response to a dry-run repo or org sampling request.
+//
+//nolint:govet // fieldalignment: API struct field order optimized for readability
+type SamplePlanResponse struct {
+	Target    string            `json:"target"` // "owner/repo" or "org"
+	Plan      github.SamplePlan `json:"plan"`
+	Timestamp time.Time         `json:"timestamp"`
+	Commit    string            `json:"commit"`
+}
+
+// planRepoSample lists the PRs a repository sample would fetch, without fetching PR data.
+func (s *Server) planRepoSample(ctx context.Context, req *RepoSampleRequest, token string) (*SamplePlanResponse, error) {
+	prs, err := s.repoPRs(ctx, req, token)
+	if err != nil {
+		return nil, err
+	}
+	plan := github.PlanSample(prs, req.SampleSize, req.Days, req.until, github.SamplingMode(req.Sampling))
+	if req.Anonymize {
+		plan = plan.Anonymized()
+	}
+	return s.samplePlanResponse(ctx, req.Owner+"/"+req.Repo, plan), nil
+}
+
+// planOrgSample lists the PRs an organization sample would fetch, without fetching PR data.
+func (s *Server) planOrgSample(ctx context.Context, req *OrgSampleRequest, token string) (*SamplePlanResponse, error) {
+	prs, err := s.orgPRs(ctx, req, token)
+	if err != nil {
+		return nil, err
+	}
+	plan := github.PlanSample(prs, req.SampleSize, req.Days, req.until, github.SamplingMode(req.Sampling))
+	if req.Anonymize {
+		plan = plan.Anonymized()
+	}
+	return s.samplePlanResponse(ctx, req.Org, plan), nil
+}
+
+// samplePlanResponse wraps a sample plan for the API response.
+func (s *Server) samplePlanResponse(ctx context.Context, target string, plan github.SamplePlan) *SamplePlanResponse {
+	s.logger.InfoContext(ctx, "Planned sample (dry run)",
+		"target", target, "total_prs", plan.TotalPRs, "sample_size", len(plan.Samples), "actual_days", plan.ActualDays)
+	return &SamplePlanResponse{
+		Target:    target,
+		Plan:      plan,
+		Timestamp: time.Now(),
+		Commit:    s.serverCommit,
+	}
+}
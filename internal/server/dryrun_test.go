@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseSampleRequestDryRun(t *testing.T) {
+	s := New()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/calculate/repo?owner=o&repo=r&dry_run=true", http.NoBody)
+	repoReq, err := s.parseRepoSampleRequest(req.Context(), req)
+	if err != nil {
+		t.Fatalf("parseRepoSampleRequest() unexpected error: %v", err)
+	}
+	if !repoReq.DryRun {
+		t.Error("Expected dry_run=true query parameter to set DryRun")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/calculate/org", strings.NewReader(`{"org":"myorg","dry_run":true}`))
+	orgReq, err := s.parseOrgSampleRequest(req.Context(), req)
+	if err != nil {
+		t.Fatalf("parseOrgSampleRequest() unexpected error: %v", err)
+	}
+	if !orgReq.DryRun {
+		t.Error("Expected dry_run JSON field to set DryRun")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/calculate/org?org=myorg", http.NoBody)
+	orgReq, err = s.parseOrgSampleRequest(req.Context(), req)
+	if err != nil {
+		t.Fatalf("parseOrgSampleRequest() unexpected error: %v", err)
+	}
+	if orgReq.DryRun {
+		t.Error("Expected DryRun to default to false")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/gitlab"
 )
 
 // Error types.
@@ -12,6 +14,7 @@ var (
 	ErrAccessDenied   = errors.New("access denied")
 	ErrNotFound       = errors.New("not found")
 	ErrInvalidRequest = errors.New("invalid request")
+	ErrNoToken        = errors.New("no fallback GitHub token available")
 	ErrRateLimit      = errors.New("rate limit exceeded")
 	ErrTimeout        = errors.New("request timeout")
 )
@@ -34,6 +37,12 @@ func IsAccessError(err error) bool {
 			accessErr.StatusCode == http.StatusNotFound ||
 			accessErr.StatusCode == http.StatusUnauthorized
 	}
+	var gitlabErr *gitlab.APIError
+	if errors.As(err, &gitlabErr) {
+		return gitlabErr.StatusCode == http.StatusForbidden ||
+			gitlabErr.StatusCode == http.StatusNotFound ||
+			gitlabErr.StatusCode == http.StatusUnauthorized
+	}
 	if errors.Is(err, ErrAccessDenied) || errors.Is(err, ErrNotFound) {
 		return true
 	}
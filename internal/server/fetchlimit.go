@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+	"github.com/codeGROOVE-dev/prcost/pkg/github"
+	"github.com/codeGROOVE-dev/prcost/pkg/gitlab"
+)
+
+// DefaultMaxConcurrentFetches is the default cap on PR data fetches in flight across all requests.
+const DefaultMaxConcurrentFetches = 32
+
+// errInvalidMaxConcurrentFetches is returned by SetMaxConcurrentFetches for limits below 1.
+var errInvalidMaxConcurrentFetches = errors.New("max concurrent fetches must be at least 1")
+
+// SetMaxConcurrentFetches caps how many PR data fetches may be in flight across all requests.
+// SetConcurrency bounds the PRs each streaming request fetches at once; this bounds the total
+// when many run at once. Fetches beyond the cap wait for a free slot. Call before serving requests.
+func (s *Server) SetMaxConcurrentFetches(n int) error {
+	if n < 1 {
+		return errInvalidMaxConcurrentFetches
+	}
+	s.fetchSlots = make(chan struct{}, n)
+	s.logger.InfoContext(context.Background(), "Global fetch concurrency configured", "max_concurrent_fetches", n)
+	return nil
+}
+
+// SetConcurrency sets how many PRs a single streaming repo or org request fetches at once,
+// from 1 to cost.MaxConcurrency (default cost.DefaultConcurrency).
+func (s *Server) SetConcurrency(n int) error {
+	if err := cost.ValidateConcurrency(n); err != nil {
+		return err
+	}
+	s.concurrency = n
+	s.logger.InfoContext(context.Background(), "Per-request fetch concurrency configured", "concurrency", n)
+	return nil
+}
+
+// acquireFetchSlot blocks until a server-wide fetch slot is free or ctx is done.
+// On success, the caller must call release once its fetch finishes.
+func (s *Server) acquireFetchSlot(ctx context.Context) (release func(), err error) {
+	release = func() { <-s.fetchSlots }
+	select {
+	case s.fetchSlots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	// All slots busy: wait, applying backpressure to the request instead of piling on more goroutines
+	start := time.Now()
+	s.logger.DebugContext(ctx, "[acquireFetchSlot] Global fetch limit reached, waiting", "limit", cap(s.fetchSlots))
+	select {
+	case s.fetchSlots <- struct{}{}:
+		s.logger.DebugContext(ctx, "[acquireFetchSlot] Acquired fetch slot", "waited", time.Since(start))
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetchPRData fetches a PR from the configured data source while holding a global fetch slot.
+// secondsInState is only populated by the turnserver data source.
+func (s *Server) fetchPRData(ctx context.Context, prURL, token string, updatedAt time.Time) (
+	prData cost.PRData, secondsInState map[string]int, err error,
+) {
+	release, err := s.acquireFetchSlot(ctx)
+	if err != nil {
+		return cost.PRData{}, nil, err
+	}
+	defer release()
+
+	if s.dataSource == "gitlab" {
+		prData, err = gitlab.FetchMRData(ctx, prURL, token)
+		return prData, nil, err
+	}
+	if s.dataSource == "turnserver" {
+		prDataWithAnalysis, err := github.FetchPRDataWithAnalysisViaTurnserver(ctx, prURL, token, updatedAt)
+		if err != nil {
+			return cost.PRData{}, nil, err
+		}
+		return prDataWithAnalysis.PRData, prDataWithAnalysis.Analysis.SecondsInState, nil
+	}
+	prData, err = (&github.SimpleFetcher{Token: token, API: &s.githubAPI}).FetchPRData(ctx, prURL, updatedAt)
+	return prData, nil, err
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+func TestAcquireFetchSlotCapsConcurrentStreams(t *testing.T) {
+	s := New()
+	const limit = 5
+	if err := s.SetMaxConcurrentFetches(limit); err != nil {
+		t.Fatalf("SetMaxConcurrentFetches(%d) = %v", limit, err)
+	}
+
+	// Simulate several streaming requests, each fetching up to 8 PRs at a time like processPRsInParallel
+	var inFlight, peak, fetched atomic.Int64
+	var wg sync.WaitGroup
+	const streams, prsPerStream = 6, 20
+	for range streams {
+		wg.Go(func() {
+			perRequest := make(chan struct{}, 8)
+			var prs sync.WaitGroup
+			for range prsPerStream {
+				prs.Go(func() {
+					perRequest <- struct{}{}
+					defer func() { <-perRequest }()
+
+					release, err := s.acquireFetchSlot(t.Context())
+					if err != nil {
+						t.Errorf("acquireFetchSlot() = %v", err)
+						return
+					}
+					defer release()
+					n := inFlight.Add(1)
+					for {
+						p := peak.Load()
+						if n <= p || peak.CompareAndSwap(p, n) {
+							break
+						}
+					}
+					time.Sleep(time.Millisecond)
+					inFlight.Add(-1)
+					fetched.Add(1)
+				})
+			}
+			prs.Wait()
+		})
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got > limit {
+		t.Errorf("peak in-flight fetches = %d, want <= %d", got, limit)
+	}
+	if got := fetched.Load(); got != streams*prsPerStream {
+		t.Errorf("completed fetches = %d, want %d", got, streams*prsPerStream)
+	}
+}
+
+func TestAcquireFetchSlotHonorsCancellation(t *testing.T) {
+	s := New()
+	if err := s.SetMaxConcurrentFetches(1); err != nil {
+		t.Fatal(err)
+	}
+	release, err := s.acquireFetchSlot(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.acquireFetchSlot(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquireFetchSlot() with all slots busy = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	release()
+	release, err = s.acquireFetchSlot(t.Context())
+	if err != nil {
+		t.Fatalf("acquireFetchSlot() after release = %v", err)
+	}
+	release()
+}
+
+func TestSetMaxConcurrentFetchesRejectsInvalid(t *testing.T) {
+	s := New()
+	for _, n := range []int{0, -1} {
+		if err := s.SetMaxConcurrentFetches(n); err == nil {
+			t.Errorf("SetMaxConcurrentFetches(%d) succeeded, want error", n)
+		}
+	}
+	if cap(s.fetchSlots) != DefaultMaxConcurrentFetches {
+		t.Errorf("fetch limit = %d after invalid values, want default %d", cap(s.fetchSlots), DefaultMaxConcurrentFetches)
+	}
+}
+
+func TestSetConcurrency(t *testing.T) {
+	s := New()
+	if s.concurrency != cost.DefaultConcurrency {
+		t.Errorf("default concurrency = %d, want %d", s.concurrency, cost.DefaultConcurrency)
+	}
+	if err := s.SetConcurrency(16); err != nil || s.concurrency != 16 {
+		t.Errorf("SetConcurrency(16) = %v, concurrency %d; want nil, 16", err, s.concurrency)
+	}
+	for _, n := range []int{0, cost.MaxConcurrency + 1} {
+		if err := s.SetConcurrency(n); err == nil {
+			t.Errorf("SetConcurrency(%d) succeeded, want error", n)
+		}
+	}
+	if s.concurrency != 16 {
+		t.Errorf("concurrency = %d after invalid values, want 16", s.concurrency)
+	}
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/gitlab"
+)
+
+// errGitLabSampling is returned for repo and org sampling requests when the data source is gitlab.
+var errGitLabSampling = errors.New("repo and org sampling are not supported for GitLab; calculate single merge requests instead")
+
+// SetGitLabHost configures the GitLab host merge request URLs must be on when the data source
+// is gitlab (default gitlab.com), for example "gitlab.mycorp.com" for a self-managed instance.
+func (s *Server) SetGitLabHost(host string) error {
+	host, err := gitlab.ParseHost(host)
+	if err != nil {
+		return err
+	}
+	s.gitlabHost = host
+	s.logger.InfoContext(context.Background(), "GitLab host configured", "host", host)
+	return nil
+}
+
+// validatePRURL validates a PR URL for the configured data source: a GitLab merge request on the
+// configured GitLab host for gitlab, otherwise a GitHub PR.
+func (s *Server) validatePRURL(prURL string) error {
+	if s.dataSource != "gitlab" {
+		return s.validateGitHubPRURL(prURL)
+	}
+	// Length check prevents DoS attacks with extremely long URLs.
+	if len(prURL) > maxURLLength {
+		return errors.New("URL too long")
+	}
+	host, _, _, err := gitlab.ParseMRURL(prURL)
+	if err != nil {
+		return err
+	}
+	// Only accept the configured GitLab host (prevents SSRF). The URL's host is normalized the
+	// same way as the configured one, so a mixed-case host isn't rejected.
+	if host, err := gitlab.ParseHost(host); err != nil || host != s.gitlabHost {
+		return fmt.Errorf("only https://%s URLs allowed", s.gitlabHost)
+	}
+	return nil
+}
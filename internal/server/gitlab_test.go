@@ -0,0 +1,43 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidatePRURLGitLab(t *testing.T) {
+	s := New()
+	s.SetDataSource("gitlab")
+	if err := s.SetGitLabHost("gitlab.mycorp.com"); err != nil {
+		t.Fatalf("SetGitLabHost() error: %v", err)
+	}
+	if err := s.SetGitLabHost("https://gitlab.mycorp.com"); err == nil {
+		t.Error("SetGitLabHost() accepted a URL, want a bare hostname")
+	}
+
+	for _, good := range []string{
+		"https://gitlab.mycorp.com/group/sub/project/-/merge_requests/12",
+		"https://GitLab.MyCorp.com/group/project/-/merge_requests/12",
+	} {
+		if err := s.validatePRURL(good); err != nil {
+			t.Errorf("validatePRURL(%q) rejected a merge request on the configured host: %v", good, err)
+		}
+	}
+	for _, bad := range []string{
+		"https://gitlab.com/group/project/-/merge_requests/12",
+		"https://gitlab.mycorp.com:8443/group/project/-/merge_requests/12",
+		"https://github.com/owner/repo/pull/12",
+		"https://gitlab.mycorp.com/group/project/-/issues/12",
+	} {
+		if err := s.validatePRURL(bad); err == nil {
+			t.Errorf("validatePRURL(%q) succeeded, want error", bad)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/calculate/repo?owner=o&repo=r", http.NoBody)
+	if _, err := s.parseRepoSampleRequest(t.Context(), req); !errors.Is(err, errGitLabSampling) {
+		t.Errorf("parseRepoSampleRequest() error = %v, want %v", err, errGitLabSampling)
+	}
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// gradeSampleSize is the number of PRs sampled for a grade; grades need far fewer than full breakdowns.
+	gradeSampleSize = 25
+	// gradeCacheTTL is how long grades are cached; badges and dashboards tolerate stale data.
+	gradeCacheTTL = 24 * time.Hour
+)
+
+// GradeResponse is the lightweight response for badges and scorecards.
+//
+//nolint:govet // fieldalignment: API struct field order optimized for readability
+type GradeResponse struct {
+	Target               string    `json:"target"` // "owner/repo" or "org"
+	EfficiencyPct        float64   `json:"efficiency_pct"`
+	EfficiencyGrade      string    `json:"efficiency_grade"`
+	EfficiencyMessage    string    `json:"efficiency_message"`
+	MergeVelocityGrade   string    `json:"merge_velocity_grade"`
+	MergeVelocityMessage string    `json:"merge_velocity_message"`
+	SampledPRs           int       `json:"sampled_prs"`
+	Days                 int       `json:"days"`
+	Timestamp            time.Time `json:"timestamp"`
+	Commit               string    `json:"commit"`
+}
+
+// gradeCacheEntry holds a cached grade until it expires.
+type gradeCacheEntry struct {
+	expiresAt time.Time
+	response  GradeResponse
+}
+
+// cachedGrade returns an unexpired cached grade.
+func (s *Server) cachedGrade(key string) (GradeResponse, bool) {
+	s.gradeCacheMu.RLock()
+	entry, exists := s.gradeCache[key]
+	s.gradeCacheMu.RUnlock()
+	if !exists || time.Now().After(entry.expiresAt) {
+		return GradeResponse{}, false
+	}
+	return entry.response, true
+}
+
+// cacheGrade stores a grade for gradeCacheTTL.
+func (s *Server) cacheGrade(key string, resp GradeResponse) {
+	s.gradeCacheMu.Lock()
+	s.gradeCache[key] = &gradeCacheEntry{response: resp, expiresAt: time.Now().Add(gradeCacheTTL)}
+	s.gradeCacheMu.Unlock()
+}
+
+// handleGrade returns only the efficiency and merge velocity grades for a repo or org,
+// using a small sample and the default cost model.
+func (s *Server) handleGrade(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+
+	if !s.allowRequest(writer, request, "handleGrade") {
+		return
+	}
+
+	// processGrade parses the request, so take the owner from the raw query
+	query := request.URL.Query()
+	owner, repo := query.Get("owner"), query.Get("repo")
+	if owner == "" {
+		owner = query.Get("org")
+	}
+	token, ok := s.requestToken(writer, request, "handleGrade", owner, repo)
+	if !ok {
+		return
+	}
+
+	response, err := s.processGrade(ctx, request, token)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "[handleGrade] Error processing request",
+			"remote_addr", request.RemoteAddr, "query", request.URL.RawQuery, errorKey, sanitizeError(err))
+		if errors.Is(err, ErrInvalidRequest) {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(writer, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(gradeCacheTTL.Seconds())))
+	if err := json.NewEncoder(writer).Encode(response); err != nil {
+		s.logger.ErrorContext(ctx, "[handleGrade] Error encoding response", errorKey, err)
+		return
+	}
+
+	s.logger.InfoContext(ctx, "[handleGrade] Request completed",
+		"target", response.Target, "efficiency_grade", response.EfficiencyGrade)
+}
+
+// processGrade parses a grade request and returns a cached grade, or samples the repo or org.
+// Request config overrides and PR filters are ignored so every caller shares the same cached grade.
+func (s *Server) processGrade(ctx context.Context, request *http.Request, token string) (*GradeResponse, error) {
+	var target, cacheKey string
+	var sample func() (*SampleResponse, error)
+	var days int
+
+	if request.URL.Path == "/v1/grade/repo" {
+		req, err := s.parseRepoSampleRequest(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+		}
+		req.SampleSize, req.Config = gradeSampleSize, nil
+		req.Labels, req.Author, req.ExcludeBots, req.Paths = nil, "", false, nil
+		target, days = req.Owner+"/"+req.Repo, req.Days
+		cacheKey = fmt.Sprintf("grade:repo:%s/%s:days=%d", req.Owner, req.Repo, req.Days)
+		sample = func() (*SampleResponse, error) { return s.processRepoSample(ctx, req, token) }
+	} else {
+		req, err := s.parseOrgSampleRequest(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+		}
+		req.SampleSize, req.Config = gradeSampleSize, nil
+		req.Labels, req.Author, req.ExcludeBots, req.Paths = nil, "", false, nil
+		target, days = req.Org, req.Days
+		cacheKey = fmt.Sprintf("grade:org:%s:days=%d", req.Org, req.Days)
+		sample = func() (*SampleResponse, error) { return s.processOrgSample(ctx, req, token) }
+	}
+
+	if cached, ok := s.cachedGrade(cacheKey); ok {
+		s.logger.DebugContext(ctx, "Grade cache hit", "key", cacheKey)
+		return &cached, nil
+	}
+
+	result, err := sample()
+	if err != nil {
+		return nil, err
+	}
+	ext := &result.Extrapolated
+	resp := GradeResponse{
+		Target:               target,
+		EfficiencyPct:        ext.EfficiencyPct,
+		EfficiencyGrade:      ext.EfficiencyGrade,
+		EfficiencyMessage:    ext.EfficiencyMessage,
+		MergeVelocityGrade:   ext.MergeVelocityGrade,
+		MergeVelocityMessage: ext.MergeVelocityMessage,
+		SampledPRs:           ext.SuccessfulSamples,
+		Days:                 days,
+		Timestamp:            result.Timestamp,
+		Commit:               s.serverCommit,
+	}
+	s.cacheGrade(cacheKey, resp)
+	return &resp, nil
+}
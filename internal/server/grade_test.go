@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandleGradeCached(t *testing.T) {
+	s := New()
+	want := GradeResponse{
+		Target:             "myorg",
+		EfficiencyPct:      91.5,
+		EfficiencyGrade:    "A-",
+		MergeVelocityGrade: "B",
+		SampledPRs:         25,
+		Days:               60,
+	}
+	s.cacheGrade("grade:org:myorg:days=60", want)
+
+	// No GitHub API is reachable in tests, so a 200 proves the cached grade was served
+	req := httptest.NewRequest(http.MethodGet, "/v1/grade/org?org=myorg", http.NoBody)
+	req.Header.Set("Authorization", "Bearer ghp_test")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /v1/grade/org status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if cc := w.Header().Get("Cache-Control"); cc == "" {
+		t.Error("Expected Cache-Control header on grade response")
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &fields); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	for _, key := range []string{"target", "efficiency_pct", "efficiency_grade", "merge_velocity_grade", "sampled_prs", "days"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("response missing %q", key)
+		}
+	}
+	if _, ok := fields["extrapolated"]; ok {
+		t.Error("grade response should not include the full breakdown")
+	}
+
+	var got GradeResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if got.EfficiencyGrade != "A-" || got.EfficiencyPct != 91.5 || got.MergeVelocityGrade != "B" {
+		t.Errorf("grade = %+v, want cached %+v", got, want)
+	}
+}
+
+func TestGradeCacheExpiry(t *testing.T) {
+	s := New()
+	s.gradeCache["grade:repo:o/r:days=60"] = &gradeCacheEntry{
+		response:  GradeResponse{EfficiencyGrade: "A"},
+		expiresAt: time.Now().Add(-time.Minute),
+	}
+	if _, ok := s.cachedGrade("grade:repo:o/r:days=60"); ok {
+		t.Error("Expected expired grade not to be served")
+	}
+
+	s.cacheGrade("grade:repo:o/r:days=60", GradeResponse{EfficiencyGrade: "B"})
+	if got, ok := s.cachedGrade("grade:repo:o/r:days=60"); !ok || got.EfficiencyGrade != "B" {
+		t.Errorf("cachedGrade() = %+v, %v; want B, true", got, ok)
+	}
+}
+
+func TestHandleGradeInvalid(t *testing.T) {
+	s := New()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{"wrong method", http.MethodPost, "/v1/grade/org?org=myorg", http.StatusMethodNotAllowed},
+		{"missing org", http.MethodGet, "/v1/grade/org", http.StatusBadRequest},
+		{"missing repo", http.MethodGet, "/v1/grade/repo?owner=o", http.StatusBadRequest},
+		{"invalid days", http.MethodGet, "/v1/grade/org?org=myorg&days=400", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, http.NoBody)
+			req.Header.Set("Authorization", "Bearer ghp_test")
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -51,7 +51,7 @@ func TestOrgSampleStreamIntegration(t *testing.T) {
 	// Run the handler in a goroutine since it's a streaming endpoint
 	done := make(chan bool)
 	go func() {
-		s.handleOrgSampleStream(w, req)
+		s.handleOrgSampleStream(w, req, sseEncoder{})
 		close(done)
 	}()
 
@@ -217,7 +217,7 @@ func TestOrgSampleStreamNoTimeout(t *testing.T) {
 	var receivedEvents int
 
 	go func() {
-		s.handleOrgSampleStream(w, req)
+		s.handleOrgSampleStream(w, req, sseEncoder{})
 
 		// Count events
 		scanner := bufio.NewScanner(strings.NewReader(w.Body.String()))
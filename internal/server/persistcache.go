@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/codeGROOVE-dev/ds9/pkg/datastore"
+	"github.com/redis/go-redis/v9"
+)
+
+// Kinds of persisted cache entries, named after the DataStore kinds that hold them.
+const (
+	prQueryCacheKind    = "PRQueryCache"
+	prDataCacheKind     = "PRDataCache"
+	calcResultCacheKind = "CalcResultCache"
+)
+
+// PersistentCache stores JSON-encoded cache entries outside the process, so they survive
+// restarts and are shared between server instances. The in-memory caches stay in front of it.
+type PersistentCache interface {
+	// Get returns the entry stored under kind and key; found is false if there is none or it expired.
+	Get(ctx context.Context, kind, key string) (data []byte, found bool, err error)
+	// Put stores an entry under kind and key that expires after ttl.
+	Put(ctx context.Context, kind, key string, data []byte, ttl time.Duration) error
+}
+
+// SetPersistentCache puts cache behind the in-memory caches, replacing the DataStore cache
+// enabled by DATASTORE_DB, if any. A nil cache turns persistent caching off. Call before
+// serving requests.
+func (s *Server) SetPersistentCache(cache PersistentCache) {
+	s.persistentCache = cache
+	s.logger.InfoContext(context.Background(), "Persistent cache configured", "enabled", cache != nil)
+}
+
+// persisted decodes the persistent cache entry under kind and key into out, reporting whether
+// there was one. Read failures are logged and treated as misses.
+func (s *Server) persisted(ctx context.Context, kind, key string, out any) bool {
+	if s.persistentCache == nil {
+		return false
+	}
+	data, found, err := s.persistentCache.Get(ctx, kind, key)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Persistent cache read failed", "kind", kind, "key", key, "error", err)
+		return false
+	}
+	if !found {
+		return false
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		s.logger.WarnContext(ctx, "Failed to deserialize persisted cache entry", "kind", kind, "key", key, "error", err)
+		return false
+	}
+	return true
+}
+
+// persist writes v to the persistent cache under kind and key, expiring after ttl.
+// Write failures are logged; the in-memory cache still holds the entry.
+func (s *Server) persist(ctx context.Context, kind, key string, v any, ttl time.Duration) {
+	if s.persistentCache == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to serialize cache entry", "kind", kind, "key", key, "error", err)
+		return
+	}
+	if err := s.persistentCache.Put(ctx, kind, key, data, ttl); err != nil {
+		s.logger.WarnContext(ctx, "Persistent cache write failed", "kind", kind, "key", key, "error", err)
+		return
+	}
+	s.logger.DebugContext(ctx, "Cache entry persisted", "kind", kind, "key", key, "ttl", ttl)
+}
+
+// datastoreCacheEntity is a persisted cache entry in DataStore.
+type datastoreCacheEntity struct {
+	Data      string    `datastore:"data,noindex"` // JSON-encoded entry
+	CachedAt  time.Time `datastore:"cached_at"`    // When this was cached
+	ExpiresAt time.Time `datastore:"expires_at"`   // When this expires
+}
+
+// datastoreCache is a PersistentCache in Cloud DataStore, with an entity kind per kind of entry.
+type datastoreCache struct {
+	client *datastore.Client
+}
+
+// Get implements PersistentCache.
+func (c *datastoreCache) Get(ctx context.Context, kind, key string) (data []byte, found bool, err error) {
+	var entity datastoreCacheEntity
+	if err := c.client.Get(ctx, datastore.NameKey(kind, key, nil), &entity); err != nil {
+		if errors.Is(err, datastore.ErrNoSuchEntity) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	// DataStore doesn't expire entities itself
+	if time.Now().After(entity.ExpiresAt) {
+		return nil, false, nil
+	}
+	return []byte(entity.Data), true, nil
+}
+
+// Put implements PersistentCache.
+func (c *datastoreCache) Put(ctx context.Context, kind, key string, data []byte, ttl time.Duration) error {
+	now := time.Now()
+	entity := datastoreCacheEntity{
+		Data:      string(data),
+		CachedAt:  now,
+		ExpiresAt: now.Add(ttl),
+	}
+	_, err := c.client.Put(ctx, datastore.NameKey(kind, key, nil), &entity)
+	return err
+}
+
+// RedisCache is a PersistentCache in Redis. Redis expires entries itself.
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache returns a RedisCache for the server at addr (host:port), checking that it
+// responds. A redis:// or rediss:// URL may be given instead, to set a password or database.
+func NewRedisCache(ctx context.Context, addr string) (*RedisCache, error) {
+	opts := &redis.Options{Addr: addr}
+	if u, err := redis.ParseURL(addr); err == nil {
+		opts = u
+	}
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close() //nolint:errcheck // already failing
+		return nil, fmt.Errorf("connect to Redis at %s: %w", opts.Addr, err)
+	}
+	return &RedisCache{client: client}, nil
+}
+
+// redisKey namespaces key by kind, as DataStore kinds do.
+func redisKey(kind, key string) string {
+	return "prcost:" + kind + ":" + key
+}
+
+// Get implements PersistentCache.
+func (c *RedisCache) Get(ctx context.Context, kind, key string) (data []byte, found bool, err error) {
+	data, err = c.client.Get(ctx, redisKey(kind, key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Put implements PersistentCache.
+func (c *RedisCache) Put(ctx context.Context, kind, key string, data []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, redisKey(kind, key), data, ttl).Err()
+}
+
+// Close closes the connection to Redis.
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+	"github.com/codeGROOVE-dev/prcost/pkg/github"
+)
+
+func TestRedisCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cache, err := NewRedisCache(t.Context(), mr.Addr())
+	if err != nil {
+		t.Fatalf("NewRedisCache() error: %v", err)
+	}
+	defer func() { _ = cache.Close() }() //nolint:errcheck // test
+
+	if _, found, err := cache.Get(t.Context(), prDataCacheKind, "pr:a"); found || err != nil {
+		t.Errorf("Get() of a missing entry = found %v, error %v; want a miss", found, err)
+	}
+	if err := cache.Put(t.Context(), prDataCacheKind, "pr:a", []byte(`{"a":1}`), time.Hour); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	// Kinds are separate namespaces
+	if _, found, _ := cache.Get(t.Context(), calcResultCacheKind, "pr:a"); found { //nolint:errcheck // checked below
+		t.Error("Get() found an entry of another kind")
+	}
+	data, found, err := cache.Get(t.Context(), prDataCacheKind, "pr:a")
+	if !found || err != nil || string(data) != `{"a":1}` {
+		t.Errorf("Get() = %q, %v, %v; want the stored entry", data, found, err)
+	}
+
+	mr.FastForward(2 * time.Hour)
+	if _, found, _ := cache.Get(t.Context(), prDataCacheKind, "pr:a"); found { //nolint:errcheck // a miss is what matters
+		t.Error("Get() found an expired entry")
+	}
+
+	if _, err := NewRedisCache(t.Context(), "127.0.0.1:1"); err == nil {
+		t.Error("NewRedisCache() of an unreachable server succeeded")
+	}
+}
+
+func TestPersistentCacheSurvivesRestart(t *testing.T) {
+	mr := miniredis.RunT(t)
+	start := func() *Server {
+		t.Helper()
+		cache, err := NewRedisCache(t.Context(), mr.Addr())
+		if err != nil {
+			t.Fatalf("NewRedisCache() error: %v", err)
+		}
+		s := New()
+		s.SetPersistentCache(cache)
+		return s
+	}
+
+	first := start()
+	first.cachePRData(t.Context(), "pr:a", cost.PRData{Author: "alice"})
+	first.cachePRQuery(t.Context(), "org:o", []github.PRSummary{{Owner: "o", Repo: "r", Number: 1}})
+	first.Shutdown()
+
+	// A new instance starts with empty in-memory caches but finds the persisted entries
+	second := start()
+	defer second.Shutdown()
+	if data, ok := second.cachedPRData(t.Context(), "pr:a"); !ok || data.Author != "alice" {
+		t.Errorf("cachedPRData() = %+v, %v; want alice's persisted PR", data, ok)
+	}
+	if prs, ok := second.cachedPRQuery(t.Context(), "org:o"); !ok || len(prs) != 1 || prs[0].Number != 1 {
+		t.Errorf("cachedPRQuery() = %+v, %v; want the persisted query", prs, ok)
+	}
+	if _, exists := second.prDataCache["pr:a"]; !exists {
+		t.Error("persistent cache hit didn't populate the in-memory cache")
+	}
+	if _, ok := second.cachedPRData(t.Context(), "pr:b"); ok {
+		t.Error("cachedPRData() hit an entry that was never cached")
+	}
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+const (
+	// publishTimeout bounds a single publish; publishing never delays the user-facing response.
+	publishTimeout = 10 * time.Second
+	// gcpMetadataURL is the GCP metadata server, the same credential source used for GSM.
+	gcpMetadataURL = "http://metadata.google.internal/computeMetadata/v1" //nolint:revive // metadata server only accessible via HTTP
+	// pubSubURL is the Pub/Sub REST API endpoint.
+	pubSubURL = "https://pubsub.googleapis.com/v1"
+)
+
+// Publisher sends computed results to a message queue.
+// The key identifies the repository ("owner/repo") or organization the result describes.
+type Publisher interface {
+	Publish(ctx context.Context, key string, data []byte) error
+}
+
+// PublishedResult is the JSON message published for each computed result.
+// Exactly one of Breakdown (single PR) or Extrapolated (repo/org sample) is set.
+//
+//nolint:govet // fieldalignment: API struct field order optimized for readability
+type PublishedResult struct {
+	Kind         string                      `json:"kind"` // "pr", "repo", or "org"
+	Key          string                      `json:"key"`
+	URL          string                      `json:"url,omitempty"` // PR URL for single PR results
+	Breakdown    *cost.Breakdown             `json:"breakdown,omitempty"`
+	Extrapolated *cost.ExtrapolatedBreakdown `json:"extrapolated,omitempty"`
+	Timestamp    time.Time                   `json:"timestamp"`
+	Commit       string                      `json:"commit"`
+}
+
+// SetPublisher enables publishing computed results to a message queue.
+func (s *Server) SetPublisher(p Publisher) {
+	s.publisher = p
+}
+
+// publishResult publishes a result in the background if a publisher is configured.
+// Failures are logged and never affect the user-facing response.
+func (s *Server) publishResult(ctx context.Context, result *PublishedResult) {
+	if s.publisher == nil {
+		return
+	}
+	result.Timestamp = time.Now()
+	result.Commit = s.serverCommit
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to serialize result for publishing", "kind", result.Kind, "key", result.Key, errorKey, err)
+		return
+	}
+
+	// Detach from the request so publishing outlives the response.
+	pubCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), publishTimeout)
+	go func() {
+		defer cancel()
+		if err := s.publisher.Publish(pubCtx, result.Key, data); err != nil {
+			s.logger.WarnContext(pubCtx, "Failed to publish result", "kind", result.Kind, "key", result.Key, errorKey, err)
+			return
+		}
+		s.logger.InfoContext(pubCtx, "Published result", "kind", result.Kind, "key", result.Key, "bytes", len(data))
+	}()
+}
+
+// prRepoKey returns "owner/repo" for a GitHub PR URL, or the URL itself if it can't be parsed.
+func prRepoKey(prURL string) string {
+	u, err := url.Parse(prURL)
+	if err != nil {
+		return prURL
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 {
+		return prURL
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+// PubSubPublisher publishes messages to a Google Cloud Pub/Sub topic via the REST API.
+// Credentials and the project ID come from the GCP metadata server, as with GSM.
+type PubSubPublisher struct {
+	httpClient  *http.Client
+	topic       string // Fully qualified: projects/{project}/topics/{topic}
+	metadataURL string
+	pubSubURL   string
+
+	tokenMu     sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewPubSubPublisher creates a publisher for the given topic.
+// The topic may be fully qualified ("projects/p/topics/t") or a bare topic name,
+// in which case the project is detected from the GCP metadata server.
+func NewPubSubPublisher(ctx context.Context, topic string) (*PubSubPublisher, error) {
+	p := &PubSubPublisher{
+		httpClient:  &http.Client{Timeout: publishTimeout},
+		metadataURL: gcpMetadataURL,
+		pubSubURL:   pubSubURL,
+	}
+	if err := p.setTopic(ctx, topic); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// setTopic resolves and stores the fully qualified topic name.
+func (p *PubSubPublisher) setTopic(ctx context.Context, topic string) error {
+	if topic == "" {
+		return errors.New("empty Pub/Sub topic")
+	}
+	if strings.HasPrefix(topic, "projects/") {
+		p.topic = topic
+		return nil
+	}
+	project, err := p.metadata(ctx, "/project/project-id")
+	if err != nil {
+		return fmt.Errorf("failed to detect project for Pub/Sub topic: %w", err)
+	}
+	p.topic = fmt.Sprintf("projects/%s/topics/%s", strings.TrimSpace(string(project)), topic)
+	return nil
+}
+
+// Publish sends a single message with the key as a "key" attribute.
+func (p *PubSubPublisher) Publish(ctx context.Context, key string, data []byte) error {
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"messages": []map[string]any{{
+			"data":       base64.StdEncoding.EncodeToString(data),
+			"attributes": map[string]string{"key": key},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode Pub/Sub request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.pubSubURL+"/"+p.topic+":publish", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Pub/Sub request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("pub/sub request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // best effort close
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("pub/sub publish returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// accessToken returns a cached access token, refreshing it from the metadata server when near expiry.
+func (p *PubSubPublisher) accessToken(ctx context.Context) (string, error) {
+	p.tokenMu.Lock()
+	defer p.tokenMu.Unlock()
+
+	if p.token != "" && time.Now().Before(p.tokenExpiry) {
+		return p.token, nil
+	}
+
+	data, err := p.metadata(ctx, "/instance/service-accounts/default/token")
+	if err != nil {
+		return "", fmt.Errorf("failed to get access token: %w", err)
+	}
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(data, &tok); err != nil {
+		return "", fmt.Errorf("failed to parse access token: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return "", errors.New("empty access token")
+	}
+
+	p.token = tok.AccessToken
+	// Refresh a minute early to avoid using a token as it expires
+	p.tokenExpiry = time.Now().Add(time.Duration(tok.ExpiresIn)*time.Second - time.Minute)
+	return p.token, nil
+}
+
+// metadata fetches a value from the GCP metadata server.
+func (p *PubSubPublisher) metadata(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.metadataURL+path, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // best effort close
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metadata server status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+type publishedMessage struct {
+	key  string
+	data []byte
+}
+
+type mockPublisher struct {
+	messages chan publishedMessage
+	err      error
+}
+
+func (m *mockPublisher) Publish(_ context.Context, key string, data []byte) error {
+	m.messages <- publishedMessage{key: key, data: data}
+	return m.err
+}
+
+func TestPublishResult(t *testing.T) {
+	s := New()
+	s.SetCommit("abc123")
+	pub := &mockPublisher{messages: make(chan publishedMessage, 1)}
+	s.SetPublisher(pub)
+
+	breakdown := cost.Breakdown{PRAuthor: "alice", TotalCost: 123.45}
+	s.publishResult(context.Background(), &PublishedResult{
+		Kind:      "pr",
+		Key:       prRepoKey("https://github.com/owner/repo/pull/1"),
+		URL:       "https://github.com/owner/repo/pull/1",
+		Breakdown: &breakdown,
+	})
+
+	select {
+	case msg := <-pub.messages:
+		if msg.key != "owner/repo" {
+			t.Errorf("Expected key owner/repo, got %q", msg.key)
+		}
+		var got PublishedResult
+		if err := json.Unmarshal(msg.data, &got); err != nil {
+			t.Fatalf("Published message is not valid JSON: %v", err)
+		}
+		if got.Kind != "pr" || got.Commit != "abc123" || got.Breakdown == nil || got.Breakdown.TotalCost != 123.45 {
+			t.Errorf("Unexpected published result: %+v", got)
+		}
+		if got.Extrapolated != nil {
+			t.Error("Expected no extrapolated breakdown for a PR result")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for publish")
+	}
+}
+
+func TestPublishResultFailureIsNotFatal(t *testing.T) {
+	s := New()
+	pub := &mockPublisher{messages: make(chan publishedMessage, 1), err: errors.New("queue unavailable")}
+	s.SetPublisher(pub)
+
+	// Request context cancellation must not abort the publish
+	ctx, cancel := context.WithCancel(context.Background())
+	s.publishResult(ctx, &PublishedResult{Kind: "org", Key: "myorg", Extrapolated: &cost.ExtrapolatedBreakdown{}})
+	cancel()
+
+	select {
+	case msg := <-pub.messages:
+		if msg.key != "myorg" {
+			t.Errorf("Expected key myorg, got %q", msg.key)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for publish")
+	}
+}
+
+func TestPubSubPublisher(t *testing.T) {
+	var gotAuth, gotPath, gotKey string
+	var gotData []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/metadata/project/project-id":
+			_, _ = w.Write([]byte("my-project")) //nolint:errcheck // test server
+		case "/metadata/instance/service-accounts/default/token":
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token":"tok","expires_in":3600}`)) //nolint:errcheck // test server
+		default:
+			gotAuth = r.Header.Get("Authorization")
+			gotPath = r.URL.Path
+			var body struct {
+				Messages []struct {
+					Data       string            `json:"data"`
+					Attributes map[string]string `json:"attributes"`
+				} `json:"messages"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Messages) != 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			gotKey = body.Messages[0].Attributes["key"]
+			gotData, _ = base64.StdEncoding.DecodeString(body.Messages[0].Data) //nolint:errcheck // checked below
+			_, _ = w.Write([]byte(`{"messageIds":["1"]}`))                      //nolint:errcheck // test server
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	p := &PubSubPublisher{httpClient: ts.Client(), metadataURL: ts.URL + "/metadata", pubSubURL: ts.URL + "/v1"}
+	if err := p.setTopic(ctx, "prcost-results"); err != nil {
+		t.Fatalf("setTopic() error: %v", err)
+	}
+	if p.topic != "projects/my-project/topics/prcost-results" {
+		t.Errorf("Expected topic resolved with project, got %q", p.topic)
+	}
+
+	if err := p.Publish(ctx, "owner/repo", []byte(`{"kind":"repo"}`)); err != nil {
+		t.Fatalf("Publish() error: %v", err)
+	}
+	if gotAuth != "Bearer tok" {
+		t.Errorf("Expected bearer token from metadata server, got %q", gotAuth)
+	}
+	if gotPath != "/v1/projects/my-project/topics/prcost-results:publish" {
+		t.Errorf("Unexpected publish path %q", gotPath)
+	}
+	if gotKey != "owner/repo" || string(gotData) != `{"kind":"repo"}` {
+		t.Errorf("Unexpected message key=%q data=%q", gotKey, gotData)
+	}
+}
+
+func TestPrRepoKey(t *testing.T) {
+	if got := prRepoKey("https://github.com/owner/repo/pull/42"); got != "owner/repo" {
+		t.Errorf("prRepoKey() = %q, want owner/repo", got)
+	}
+	if got := prRepoKey("not-a-url"); got != "not-a-url" {
+		t.Errorf("prRepoKey() = %q, want input unchanged", got)
+	}
+}
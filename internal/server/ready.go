@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/github"
+)
+
+const (
+	// readyCacheTTL is how long a readiness check result is reused, so frequent load balancer
+	// probes don't each call the upstream API. Failures are cached too, to avoid hammering an outage.
+	readyCacheTTL = 30 * time.Second
+	// readyTimeout bounds the upstream connectivity check.
+	readyTimeout = 5 * time.Second
+)
+
+// ReadyResponse is the body of the /health/ready readiness endpoint.
+//
+//nolint:govet // fieldalignment: API struct field order optimized for readability
+type ReadyResponse struct {
+	Status     string    `json:"status"` // "ready" or "unavailable"
+	DataSource string    `json:"data_source"`
+	Commit     string    `json:"commit"`
+	CheckedAt  time.Time `json:"checked_at"`      // When the upstream check ran (results are cached briefly)
+	Error      string    `json:"error,omitempty"` // Why the server isn't ready
+}
+
+// handleReady reports whether the server can serve requests: a fallback token is available and
+// the upstream API accepts it. It returns 503 otherwise. Unlike /health, it calls the upstream API,
+// though at most once per readyCacheTTL.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	checkedAt, err := s.checkReady(ctx)
+
+	resp := ReadyResponse{Status: "ready", DataSource: s.dataSource, Commit: s.serverCommit, CheckedAt: checkedAt}
+	status := http.StatusOK
+	if err != nil {
+		resp.Status, resp.Error = "unavailable", sanitizeError(err)
+		status = http.StatusServiceUnavailable
+		s.logger.WarnContext(ctx, "[handleReady] Not ready", "data_source", s.dataSource, errorKey, resp.Error)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		s.logger.ErrorContext(ctx, "[handleReady] Error encoding response", errorKey, err)
+	}
+}
+
+// checkReady returns the cached readiness result, running the check again once it is older
+// than readyCacheTTL. Concurrent probes wait for a single check rather than each running one.
+func (s *Server) checkReady(ctx context.Context) (time.Time, error) {
+	s.readyMu.Lock()
+	defer s.readyMu.Unlock()
+	if !s.readyCheckedAt.IsZero() && time.Since(s.readyCheckedAt) < readyCacheTTL {
+		return s.readyCheckedAt, s.readyErr
+	}
+
+	// The result is shared with later probes, so a probe disconnecting must not cancel the check
+	checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), readyTimeout)
+	defer cancel()
+	s.readyErr = s.checkUpstream(checkCtx)
+	s.readyCheckedAt = time.Now()
+	return s.readyCheckedAt, s.readyErr
+}
+
+// checkUpstream makes a lightweight authenticated call to the data source's API with the fallback token.
+func (s *Server) checkUpstream(ctx context.Context) error {
+	token := s.token(ctx, "", "")
+	if token == "" {
+		return ErrNoToken
+	}
+
+	// GitLab tokens only ever go to the GitLab host; everything else uses GitHub's
+	// rate limit endpoint, which doesn't count against the rate limit.
+	apiURL := github.APIBaseURL(s.withGitHubHost(ctx)) + "/rate_limit"
+	if s.dataSource == "gitlab" {
+		apiURL = "https://" + s.gitlabHost + "/api/v4/user"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	if s.dataSource == "gitlab" {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	} else {
+		req.Header.Set("Authorization", "token "+token)
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("API request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.logger.ErrorContext(ctx, "[checkUpstream] Error closing response body", errorKey, err)
+		}
+	}()
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		s.logger.ErrorContext(ctx, "[checkUpstream] Error discarding response body", errorKey, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", req.URL.Host, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleReady(t *testing.T) {
+	s := New()
+	s.SetCommit("abc123")
+	s.fallbackToken = "ghp_test"
+	calls, status := 0, http.StatusOK
+	var gotURL, gotAuth string
+	s.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		gotURL, gotAuth = req.URL.String(), req.Header.Get("Authorization")
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader("{}")), Request: req}, nil
+	})}
+
+	probe := func() (int, ReadyResponse) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", http.NoBody))
+		var resp ReadyResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("decoding ready response: %v", err)
+		}
+		return w.Code, resp
+	}
+
+	code, resp := probe()
+	if code != http.StatusOK || resp.Status != "ready" || resp.DataSource != "turnserver" || resp.Commit != "abc123" {
+		t.Errorf("ready = %d %+v, want 200 ready with data source turnserver and commit abc123", code, resp)
+	}
+	if gotURL != "https://api.github.com/rate_limit" || gotAuth != "token ghp_test" {
+		t.Errorf("upstream call = %s with %q, want the authenticated GitHub rate limit endpoint", gotURL, gotAuth)
+	}
+
+	// Results are cached, so a GitHub outage shows up once the cache expires
+	status = http.StatusServiceUnavailable
+	if code, _ := probe(); code != http.StatusOK || calls != 1 {
+		t.Errorf("second probe = %d after %d GitHub calls, want the cached 200 after 1", code, calls)
+	}
+	s.readyCheckedAt = s.readyCheckedAt.Add(-readyCacheTTL)
+	code, resp = probe()
+	if code != http.StatusServiceUnavailable || resp.Status != "unavailable" || !strings.Contains(resp.Error, "503") || calls != 2 {
+		t.Errorf("probe during outage = %d %+v after %d calls, want 503 unavailable after 2", code, resp, calls)
+	}
+
+	// /health stays a cheap liveness check
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", http.NoBody))
+	if w.Code != http.StatusOK || calls != 2 {
+		t.Errorf("/health = %d after %d GitHub calls, want 200 without calling GitHub", w.Code, calls)
+	}
+}
+
+func TestHandleReadyGitLab(t *testing.T) {
+	s := New()
+	s.SetDataSource("gitlab")
+	s.fallbackToken = "glpat-test"
+	var gotURL, gotToken, gotAuth string
+	s.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		gotURL, gotToken, gotAuth = req.URL.String(), req.Header.Get("PRIVATE-TOKEN"), req.Header.Get("Authorization")
+		return &http.Response{StatusCode: http.StatusUnauthorized, Body: io.NopCloser(strings.NewReader("{}")), Request: req}, nil
+	})}
+
+	w := httptest.NewRecorder()
+	s.handleReady(w, httptest.NewRequest(http.MethodGet, "/health/ready", http.NoBody))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("ready with a rejected GitLab token = %d, want 503", w.Code)
+	}
+	if gotURL != "https://gitlab.com/api/v4/user" || gotToken != "glpat-test" || gotAuth != "" {
+		t.Errorf("upstream call = %s (PRIVATE-TOKEN %q, Authorization %q), want the GitLab user endpoint with only the GitLab token",
+			gotURL, gotToken, gotAuth)
+	}
+}
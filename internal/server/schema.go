@@ -0,0 +1,40 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+// Schema returns the JSON Schema document for the /v1/calculate and sampling responses, whose
+// schema_version is its "version". The published copy in schema/prcost.schema.json is
+// regenerated with `go run ./hacks/schemagen > schema/prcost.schema.json`.
+func Schema() map[string]any {
+	return map[string]any{
+		"$schema": cost.JSONSchemaDraft,
+		"title":   "prcost API response",
+		"version": cost.SchemaVersion,
+		"description": "Responses from /v1/calculate (CalculateResponse) and the repository and organization " +
+			"sampling endpoints (SampleResponse). The CLI's --format json output is a Breakdown for a single PR, " +
+			"or an ExtrapolatedBreakdown for a repository or organization, with schema_version added.",
+		"anyOf": []any{
+			map[string]any{"$ref": "#/$defs/CalculateResponse"},
+			map[string]any{"$ref": "#/$defs/SampleResponse"},
+		},
+		"$defs": cost.JSONSchemaDefs(CalculateResponse{}, SampleResponse{}),
+	}
+}
+
+// handleSchema returns the JSON Schema for the API's responses, so clients can validate them
+// and compare their schema_version against the one they were built for.
+func (s *Server) handleSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(Schema()); err != nil {
+		s.logger.ErrorContext(ctx, "[handleSchema] Error encoding response", errorKey, err)
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+func TestHandleSchema(t *testing.T) {
+	s := New()
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/schema", http.NoBody))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /v1/schema = %d, want 200", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/schema+json" {
+		t.Errorf("Content-Type = %q, want application/schema+json", ct)
+	}
+	var schema struct {
+		Version string `json:"version"`
+		Defs    map[string]struct {
+			Required []string `json:"required"`
+		} `json:"$defs"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&schema); err != nil {
+		t.Fatalf("decoding schema: %v", err)
+	}
+	if schema.Version != cost.SchemaVersion {
+		t.Errorf("version = %q, want %q", schema.Version, cost.SchemaVersion)
+	}
+	for _, name := range []string{"CalculateResponse", "SampleResponse"} {
+		def, ok := schema.Defs[name]
+		if !ok || len(def.Required) == 0 || def.Required[0] != "schema_version" {
+			t.Errorf("%s required = %v, want schema_version first", name, def.Required)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/schema", http.NoBody))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /v1/schema = %d, want 405", w.Code)
+	}
+}
+
+// TestPublishedSchemaUpToDate fails when a response struct changes without regenerating the
+// published schema, which is also the reminder to bump cost.SchemaVersion.
+func TestPublishedSchemaUpToDate(t *testing.T) {
+	published, err := os.ReadFile("../../schema/prcost.schema.json")
+	if err != nil {
+		t.Fatalf("reading published schema: %v", err)
+	}
+	var want bytes.Buffer
+	encoder := json.NewEncoder(&want)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(Schema()); err != nil {
+		t.Fatalf("encoding schema: %v", err)
+	}
+	if !bytes.Equal(published, want.Bytes()) {
+		t.Error("schema/prcost.schema.json is out of date: run `go run ./hacks/schemagen > schema/prcost.schema.json`, " +
+			"and bump cost.SchemaVersion if fields were removed or changed")
+	}
+}
@@ -3,18 +3,23 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"math"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,8 +27,10 @@ import (
 
 	"github.com/codeGROOVE-dev/ds9/pkg/datastore"
 	"github.com/codeGROOVE-dev/gsm"
+	"github.com/codeGROOVE-dev/prcost/pkg/cocomo"
 	"github.com/codeGROOVE-dev/prcost/pkg/cost"
 	"github.com/codeGROOVE-dev/prcost/pkg/github"
+	"github.com/codeGROOVE-dev/prcost/pkg/gitlab"
 	"golang.org/x/time/rate"
 )
 
@@ -56,35 +63,11 @@ var tokenPattern = regexp.MustCompile(
 var staticFS embed.FS
 
 // cacheEntry holds cached data for in-memory cache.
-// No TTL needed - Cloud Run kills processes frequently, providing natural cache invalidation.
+// Entries never expire unless SetCacheTTL is called: Cloud Run kills processes frequently,
+// providing natural cache invalidation, but long-lived servers need a TTL.
 type cacheEntry struct {
-	data any
-}
-
-// prDataCacheEntity represents a cached PR data entry in DataStore with TTL.
-type prDataCacheEntity struct {
-	Data      string    `datastore:"data,noindex"` // JSON-encoded cost.PRData
-	CachedAt  time.Time `datastore:"cached_at"`    // When this was cached
-	ExpiresAt time.Time `datastore:"expires_at"`   // When this expires (1 hour from CachedAt)
-	URL       string    `datastore:"url"`          // PR URL for debugging
-}
-
-// prQueryCacheEntity represents a cached PR query result in DataStore with TTL.
-type prQueryCacheEntity struct {
-	Data      string    `datastore:"data,noindex"` // JSON-encoded []github.PRSummary
-	CachedAt  time.Time `datastore:"cached_at"`    // When this was cached
-	ExpiresAt time.Time `datastore:"expires_at"`   // When this expires (varies by type)
-	QueryType string    `datastore:"query_type"`   // "repo" or "org"
-	QueryKey  string    `datastore:"query_key"`    // Full query key for debugging
-}
-
-// calcResultCacheEntity represents a cached calculation result in DataStore with TTL.
-type calcResultCacheEntity struct {
-	Data      string    `datastore:"data,noindex"` // JSON-encoded cost.Breakdown
-	CachedAt  time.Time `datastore:"cached_at"`    // When this was cached
-	ExpiresAt time.Time `datastore:"expires_at"`   // When this expires
-	URL       string    `datastore:"url"`          // PR URL for debugging
-	ConfigKey string    `datastore:"config_key"`   // Config hash for debugging
+	expiresAt time.Time // Zero if the entry never expires
+	data      any
 }
 
 // Server handles HTTP requests for the PR Cost API.
@@ -104,11 +87,22 @@ type Server struct {
 	serverCommit     string
 	githubAppID      string
 	dataSource       string
+	githubHost       string
+	gitlabHost       string
 	rateLimit        int
 	rateBurst        int
+	concurrency      int
 	allowAllCors     bool
 	validateTokens   bool
 	r2rCallout       bool
+	// Mints GitHub App installation tokens as the fallback token (nil if not enabled; see SetGitHubAppTokens).
+	appTokens *github.AppTokenSource
+	// How GitHub API calls are retried and rate limited (see SetGitHubMaxRetries and SetGitHubRateBudget).
+	githubAPI github.APIOptions
+	// Sources of the static fallback token after GITHUB_TOKEN: the gh CLI and Google Secret
+	// Manager. Tests replace them so nothing outside the process is consulted.
+	ghAuthToken func(ctx context.Context, host string) (string, error)
+	gsmToken    func(ctx context.Context, name string) (string, error)
 	// In-memory caching for PR queries and data.
 	prQueryCache      map[string]*cacheEntry
 	prDataCache       map[string]*cacheEntry
@@ -116,22 +110,46 @@ type Server struct {
 	prQueryCacheMu    sync.RWMutex
 	prDataCacheMu     sync.RWMutex
 	calcResultCacheMu sync.RWMutex
-	// DataStore client for persistent caching (nil if not enabled).
-	dsClient *datastore.Client
+	// Lifetime of in-memory cache entries (zero: no expiry; see SetCacheTTL).
+	cacheTTL       time.Duration
+	stopCacheSweep context.CancelFunc
+	// Grade responses expire (see gradeCacheTTL), unlike the other in-memory caches.
+	gradeCache   map[string]*gradeCacheEntry
+	gradeCacheMu sync.RWMutex
+	// Last /health/ready check and its result (see readyCacheTTL).
+	readyCheckedAt time.Time
+	readyErr       error
+	readyMu        sync.Mutex
+	// Hashed tokens that recently passed validateGitHubToken, with their expiry (see tokenValidationTTL).
+	validatedTokens   map[string]time.Time
+	validatedTokensMu sync.Mutex
+	// Server-wide PR fetch slots, bounding concurrent fetches across all requests.
+	fetchSlots chan struct{}
+	// Persistent cache behind the in-memory caches (nil if not enabled; see SetPersistentCache).
+	persistentCache PersistentCache
+	// Message queue sink for computed results (nil if not enabled).
+	publisher Publisher
+	// Webhook notified when org waste exceeds a threshold (nil if not enabled).
+	budgetAlert *BudgetAlert
+	// When each org (lowercased) was last alerted, for the budget alert cooldown.
+	budgetAlertedAt map[string]time.Time
+	budgetAlertsMu  sync.Mutex
 }
 
 // CalculateRequest represents a request to calculate PR costs.
 //
 //nolint:govet // fieldalignment: API struct field order optimized for readability
 type CalculateRequest struct {
-	URL    string       `json:"url"`
-	Config *cost.Config `json:"config,omitempty"`
+	URL       string          `json:"url"`
+	Config    *ConfigOverride `json:"config,omitempty"`
+	Anonymize bool            `json:"anonymize,omitempty"` // Replace logins with pseudonyms such as author-1 (see cost.Anonymizer)
 }
 
 // CalculateResponse represents the response from a cost calculation.
 //
 //nolint:govet // fieldalignment: API struct field order optimized for readability
 type CalculateResponse struct {
+	SchemaVersion  string         `json:"schema_version"` // cost.SchemaVersion; see /v1/schema
 	Breakdown      cost.Breakdown `json:"breakdown"`
 	Timestamp      time.Time      `json:"timestamp"`
 	Commit         string         `json:"commit"`
@@ -142,31 +160,114 @@ type CalculateResponse struct {
 //
 //nolint:govet // fieldalignment: API struct field order optimized for readability
 type RepoSampleRequest struct {
-	Owner      string       `json:"owner"`
-	Repo       string       `json:"repo"`
-	SampleSize int          `json:"sample_size,omitempty"` // Default: 250
-	Days       int          `json:"days,omitempty"`        // Default: 60
-	Config     *cost.Config `json:"config,omitempty"`
+	Owner       string          `json:"owner"`
+	Repo        string          `json:"repo"`
+	SampleSize  int             `json:"sample_size,omitempty"` // Default: 250
+	Days        int             `json:"days,omitempty"`        // Default: 60
+	Config      *ConfigOverride `json:"config,omitempty"`
+	DryRun      bool            `json:"dry_run,omitempty"`      // List the PRs that would be sampled without fetching them
+	Labels      []string        `json:"labels,omitempty"`       // Only PRs carrying every label (AND-ed)
+	Author      string          `json:"author,omitempty"`       // Only PRs opened by this login
+	State       string          `json:"state,omitempty"`        // all (default), open, merged or closed
+	ExcludeBots bool            `json:"exclude_bots,omitempty"` // Drop bot-authored PRs before sampling
+	Paths       []string        `json:"paths,omitempty"`        // Only PRs modifying files under one of these globs
+	Since       string          `json:"since,omitempty"`        // RFC 3339 or YYYY-MM-DD; replaces days
+	Until       string          `json:"until,omitempty"`        // RFC 3339 or YYYY-MM-DD, inclusive (default: now)
+	// Include each sampled PR's URL and breakdown in the result (off by default to keep responses small)
+	IncludeSamples bool `json:"include_samples,omitempty"`
+	// Replace logins in the result with pseudonyms such as author-1 (see cost.Anonymizer)
+	Anonymize bool `json:"anonymize,omitempty"`
+	// How PRs are sampled: time (default) or weighted (see github.SamplingMode)
+	Sampling string `json:"sampling,omitempty"`
+
+	since, until time.Time // Parsed Since and Until
 }
 
 // OrgSampleRequest represents a request to sample and calculate costs for an organization.
 //
 //nolint:govet // fieldalignment: API struct field order optimized for readability
 type OrgSampleRequest struct {
-	Org        string       `json:"org"`
-	SampleSize int          `json:"sample_size,omitempty"` // Default: 250
-	Days       int          `json:"days,omitempty"`        // Default: 60
-	Config     *cost.Config `json:"config,omitempty"`
+	Org         string          `json:"org"`
+	SampleSize  int             `json:"sample_size,omitempty"` // Default: 250
+	Days        int             `json:"days,omitempty"`        // Default: 60
+	Config      *ConfigOverride `json:"config,omitempty"`
+	DryRun      bool            `json:"dry_run,omitempty"`      // List the PRs that would be sampled without fetching them
+	Labels      []string        `json:"labels,omitempty"`       // Only PRs carrying every label (AND-ed)
+	Author      string          `json:"author,omitempty"`       // Only PRs opened by this login
+	State       string          `json:"state,omitempty"`        // all (default), open, merged or closed
+	ExcludeBots bool            `json:"exclude_bots,omitempty"` // Drop bot-authored PRs before sampling
+	Paths       []string        `json:"paths,omitempty"`        // Only PRs modifying files under one of these globs
+	Since       string          `json:"since,omitempty"`        // RFC 3339 or YYYY-MM-DD; replaces days
+	Until       string          `json:"until,omitempty"`        // RFC 3339 or YYYY-MM-DD, inclusive (default: now)
+	// Include each sampled PR's URL and breakdown in the result (off by default to keep responses small)
+	IncludeSamples bool `json:"include_samples,omitempty"`
+	// Replace logins in the result with pseudonyms such as author-1 (see cost.Anonymizer)
+	Anonymize bool `json:"anonymize,omitempty"`
+	// How PRs are sampled: time (default) or weighted (see github.SamplingMode)
+	Sampling string `json:"sampling,omitempty"`
+
+	since, until time.Time // Parsed Since and Until
+	historical   bool      // A past trend window: not published and not checked against the budget
+}
+
+// filter returns the PR filter for the request.
+func (r *RepoSampleRequest) filter() github.PRFilter {
+	return github.PRFilter{
+		Labels: r.Labels, Author: r.Author, State: github.PRState(r.State), ExcludeBots: r.ExcludeBots, Paths: r.Paths,
+	}
+}
+
+// filter returns the PR filter for the request.
+func (r *OrgSampleRequest) filter() github.PRFilter {
+	return github.PRFilter{
+		Labels: r.Labels, Author: r.Author, State: github.PRState(r.State), ExcludeBots: r.ExcludeBots, Paths: r.Paths,
+	}
+}
+
+// repoOpenPRCount counts the repository's open PRs for extrapolation. With a filter, only open
+// PRs in the filtered pool count, so PR tracking and future costs reflect the filter.
+func (s *Server) repoOpenPRCount(ctx context.Context, req *RepoSampleRequest, prs []github.PRSummary, token string) int {
+	if !req.filter().IsZero() {
+		return github.CountOpenPRs(prs, req.until)
+	}
+	count, err := github.CountOpenPRsInRepo(ctx, req.Owner, req.Repo, github.PRQuery{Until: req.until, Token: token, API: &s.githubAPI})
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to count open PRs, using 0", errorKey, err)
+		return 0
+	}
+	return count
+}
+
+// orgOpenPRCount counts the organization's open PRs for extrapolation with a single query,
+// falling back to counting repo-by-repo if it fails. With a filter, only open PRs in the
+// filtered pool count, as in repoOpenPRCount.
+func (s *Server) orgOpenPRCount(ctx context.Context, req *OrgSampleRequest, prs []github.PRSummary, token string) github.OpenPRCount {
+	if !req.filter().IsZero() {
+		return github.OpenPRCount{Count: github.CountOpenPRs(prs, req.until)}
+	}
+	return github.CountOpenPRsAcrossOrg(ctx, req.Org, prs, s.concurrency, github.PRQuery{Until: req.until, Token: token, API: &s.githubAPI})
+}
+
+// window returns the bounds to fetch PRs within; a zero until means now.
+func (r *RepoSampleRequest) window() (since, until time.Time) {
+	return sampleWindow(r.Days, r.since, r.until)
+}
+
+// window returns the bounds to fetch PRs within; a zero until means now.
+func (r *OrgSampleRequest) window() (since, until time.Time) {
+	return sampleWindow(r.Days, r.since, r.until)
 }
 
 // SampleResponse represents the response from a sampling operation.
 //
 //nolint:govet // fieldalignment: API struct field order optimized for readability
 type SampleResponse struct {
+	SchemaVersion  string                     `json:"schema_version"` // cost.SchemaVersion; see /v1/schema
 	Extrapolated   cost.ExtrapolatedBreakdown `json:"extrapolated"`
 	Timestamp      time.Time                  `json:"timestamp"`
 	Commit         string                     `json:"commit"`
 	SecondsInState map[string]int             `json:"seconds_in_state,omitempty"` // Aggregated across all sampled PRs
+	RateLimit      *github.RateLimit          `json:"rate_limit,omitempty"`       // GitHub API quota left after the scan, if any call reported it
 }
 
 // ProgressUpdate represents a progress update for streaming responses.
@@ -181,8 +282,10 @@ type ProgressUpdate struct {
 	Error          string                      `json:"error,omitempty"`
 	Result         *cost.ExtrapolatedBreakdown `json:"result,omitempty"`
 	Commit         string                      `json:"commit,omitempty"`
+	SchemaVersion  string                      `json:"schema_version,omitempty"` // Only in "done" messages
 	R2RCallout     bool                        `json:"r2r_callout,omitempty"`
 	SecondsInState map[string]int              `json:"seconds_in_state,omitempty"` // Only in "done" messages
+	RateLimit      *github.RateLimit           `json:"rate_limit,omitempty"`       // Only in "done" messages
 }
 
 // New creates a new Server instance.
@@ -212,30 +315,39 @@ func New() *Server {
 		logger:          logger,
 		serverCommit:    "", // Will be set via build flags
 		dataSource:      "turnserver",
+		githubHost:      github.DefaultHost,
+		githubAPI:       github.APIOptions{MaxRetries: github.DefaultMaxRetries},
+		gitlabHost:      gitlab.DefaultHost,
 		httpClient:      httpClient,
 		csrfProtection:  csrfProtection,
 		ipLimiters:      make(map[string]*rate.Limiter),
 		rateLimit:       DefaultRateLimit,
 		rateBurst:       DefaultRateBurst,
+		concurrency:     cost.DefaultConcurrency,
 		prQueryCache:    make(map[string]*cacheEntry),
 		prDataCache:     make(map[string]*cacheEntry),
 		calcResultCache: make(map[string]*cacheEntry),
+		gradeCache:      make(map[string]*gradeCacheEntry),
+		validatedTokens: make(map[string]time.Time),
+		fetchSlots:      make(chan struct{}, DefaultMaxConcurrentFetches),
+		ghAuthToken:     ghAuthToken,
+		gsmToken:        gsm.Fetch,
 	}
 
 	// Load GitHub token at startup and cache in memory for performance and billing.
 	// This avoids repeated GSM API calls which cost money.
-	token := server.token(ctx)
+	token := server.token(ctx, "", "")
 	if token != "" {
 		logger.InfoContext(ctx, "GitHub fallback token loaded at startup")
 	} else {
 		logger.InfoContext(ctx, "No fallback token available - requests must provide Authorization header")
 	}
 
-	// Note: We don't clear caches periodically because:
+	// Note: By default we don't expire in-memory cache entries because:
 	// - PR data is immutable (closed PRs don't change)
 	// - Memory usage is bounded by request patterns
 	// - Cloud Run instances are ephemeral and restart frequently anyway
-	// If needed in the future, implement LRU eviction with size limits instead of time-based clearing
+	// Long-lived deployments can opt into expiry and periodic sweeping with SetCacheTTL.
 
 	// Initialize DataStore client if DATASTORE_DB is set (persistent caching across restarts).
 	if dbID := os.Getenv("DATASTORE_DB"); dbID != "" {
@@ -244,7 +356,7 @@ func New() *Server {
 			logger.WarnContext(ctx, "Failed to initialize DataStore client - persistent caching disabled",
 				"database_id", dbID, "error", err)
 		} else {
-			server.dsClient = dsClient
+			server.persistentCache = &datastoreCache{client: dsClient}
 			logger.InfoContext(ctx, "DataStore persistent caching enabled",
 				"database_id", dbID)
 		}
@@ -255,6 +367,25 @@ func New() *Server {
 	return server
 }
 
+// RequireToken returns ErrNoToken if no fallback GitHub token could be loaded.
+// Deployments that depend on the fallback token call this at startup to fail fast
+// instead of serving 401s for every request without an Authorization header.
+// With a GitHub App, its credentials are checked instead, since an App installed on
+// several owners only mints tokens for a given owner.
+func (s *Server) RequireToken(ctx context.Context) error {
+	if s.appTokens != nil && s.dataSource != "gitlab" {
+		err := s.appTokens.Check(ctx)
+		if err == nil {
+			return nil
+		}
+		s.logger.WarnContext(ctx, "GitHub App credentials check failed; trying static tokens", errorKey, err)
+	}
+	if s.staticToken(ctx) == "" {
+		return ErrNoToken
+	}
+	return nil
+}
+
 // SetCommit sets the server commit hash.
 func (s *Server) SetCommit(commit string) {
 	s.serverCommit = commit
@@ -304,7 +435,7 @@ func (s *Server) SetRateLimit(rps int, burst int) {
 // SetDataSource sets the data source for PR data fetching.
 func (s *Server) SetDataSource(source string) {
 	ctx := context.Background()
-	if source != "turnserver" && source != "prx" {
+	if source != "turnserver" && source != "prx" && source != "gitlab" {
 		s.logger.WarnContext(ctx, "Invalid data source, using default", "requested", source, "default", "prx")
 		s.dataSource = "prx"
 		return
@@ -313,11 +444,63 @@ func (s *Server) SetDataSource(source string) {
 	s.logger.InfoContext(ctx, "Data source configured", "source", source)
 }
 
+// SetGitHubHost configures a GitHub Enterprise Server host (e.g. github.mycorp.com).
+// Only PR URLs on this host are accepted; the default is github.com. Call before
+// SetGitHubAppTokens and before serving requests.
+func (s *Server) SetGitHubHost(host string) error {
+	host, err := github.ParseHost(host)
+	if err != nil {
+		return err
+	}
+	s.githubHost = host
+	s.logger.InfoContext(context.Background(), "GitHub host configured", "host", s.githubHost)
+	return nil
+}
+
+// withGitHubHost returns ctx with the server's GitHub host, which GitHub API calls and PR URLs use.
+func (s *Server) withGitHubHost(ctx context.Context) context.Context {
+	return github.WithHost(ctx, s.githubHost)
+}
+
+// SetGitHubMaxRetries configures how many times rate-limited or failed GitHub API calls are retried.
+// Call before SetGitHubAppTokens and before serving requests.
+func (s *Server) SetGitHubMaxRetries(n int) error {
+	if n < 0 {
+		return errors.New("max retries must not be negative")
+	}
+	s.githubAPI.MaxRetries = n
+	s.logger.InfoContext(context.Background(), "GitHub API retries configured", "max_retries", n)
+	return nil
+}
+
+// SetGitHubRateBudget limits GitHub API calls, retries included, to an average of requestsPerSecond
+// shared across all requests. Zero leaves calls unlimited.
+// Call before SetGitHubAppTokens and before serving requests.
+func (s *Server) SetGitHubRateBudget(requestsPerSecond float64) error {
+	if requestsPerSecond < 0 || math.IsNaN(requestsPerSecond) || math.IsInf(requestsPerSecond, 0) {
+		return errors.New("GitHub rate must be a non-negative number of requests per second")
+	}
+	s.githubAPI.RateBudget = nil
+	if requestsPerSecond > 0 {
+		s.githubAPI.RateBudget = github.NewRateBudget(requestsPerSecond)
+	}
+	s.logger.InfoContext(context.Background(), "GitHub API rate budget configured", "requests_per_second", requestsPerSecond)
+	return nil
+}
+
 // SetR2RCallout enables or disables the Ready to Review promotional callout.
+// When disabled, responses carry only the neutral potential_savings figure.
 func (s *Server) SetR2RCallout(enabled bool) {
 	s.r2rCallout = enabled
 }
 
+// applyCallout drops the R2R-specific savings from ext unless the callout is enabled.
+func (s *Server) applyCallout(ext *cost.ExtrapolatedBreakdown) {
+	if !s.r2rCallout {
+		ext.R2RSavings = 0
+	}
+}
+
 // limiter returns a rate limiter for the given IP address.
 func (s *Server) limiter(ctx context.Context, ip string) *rate.Limiter {
 	s.ipLimitersMu.RLock()
@@ -357,14 +540,29 @@ func (s *Server) limiter(ctx context.Context, ip string) *rate.Limiter {
 	return limiter
 }
 
-// cachedPRQuery retrieves cached PR query results from memory first, then DataStore as fallback.
+// writeRateLimited rejects a request that limiter did not allow with 429 Too Many Requests.
+// Retry-After says how many seconds until the limiter has a token again, and X-RateLimit-Limit
+// and X-RateLimit-Remaining give the burst size and the requests left in it.
+func writeRateLimited(writer http.ResponseWriter, limiter *rate.Limiter) {
+	tokens := limiter.Tokens()
+	header := writer.Header()
+	header.Set("X-RateLimit-Limit", strconv.Itoa(limiter.Burst()))
+	header.Set("X-RateLimit-Remaining", strconv.Itoa(max(int(tokens), 0)))
+	if limit := limiter.Limit(); limit > 0 && limit != rate.Inf {
+		wait := (1 - tokens) / float64(limit)
+		header.Set("Retry-After", strconv.Itoa(max(int(math.Ceil(wait)), 1)))
+	}
+	http.Error(writer, "Rate limit exceeded", http.StatusTooManyRequests)
+}
+
+// cachedPRQuery retrieves cached PR query results from memory first, then the persistent cache as fallback.
 func (s *Server) cachedPRQuery(ctx context.Context, key string) ([]github.PRSummary, bool) {
 	// Check in-memory cache first (fast path).
 	s.prQueryCacheMu.RLock()
 	entry, exists := s.prQueryCache[key]
 	s.prQueryCacheMu.RUnlock()
 
-	if exists {
+	if exists && !entry.expired(time.Now()) {
 		prs, ok := entry.data.([]github.PRSummary)
 		if ok {
 			s.logger.DebugContext(ctx, "PR query cache hit (memory)", "key", key)
@@ -372,107 +570,42 @@ func (s *Server) cachedPRQuery(ctx context.Context, key string) ([]github.PRSumm
 		}
 	}
 
-	// Memory miss - try DataStore if available.
-	if s.dsClient == nil {
-		return nil, false
-	}
-
-	dsKey := datastore.NameKey("PRQueryCache", key, nil)
-	var entity prQueryCacheEntity
-	err := s.dsClient.Get(ctx, dsKey, &entity)
-	if err != nil {
-		if !errors.Is(err, datastore.ErrNoSuchEntity) {
-			s.logger.WarnContext(ctx, "DataStore cache read failed", "key", key, "error", err)
-		}
-		return nil, false
-	}
-
-	// Check if expired (TTL varies by query type).
-	if time.Now().After(entity.ExpiresAt) {
-		s.logger.DebugContext(ctx, "DataStore cache entry expired", "key", key, "expires_at", entity.ExpiresAt)
-		return nil, false
-	}
-
-	// Deserialize the cached data.
+	// Memory miss - try the persistent cache if available.
 	var prs []github.PRSummary
-	if err := json.Unmarshal([]byte(entity.Data), &prs); err != nil {
-		s.logger.WarnContext(ctx, "Failed to deserialize cached PR query", "key", key, "error", err)
+	if !s.persisted(ctx, prQueryCacheKind, key, &prs) {
 		return nil, false
 	}
-
-	s.logger.InfoContext(ctx, "PR query cache hit (DataStore)",
-		"key", key, "query_type", entity.QueryType, "cached_at", entity.CachedAt, "pr_count", len(prs))
+	s.logger.InfoContext(ctx, "PR query cache hit (persistent)", "key", key, "pr_count", len(prs))
 
 	// Populate in-memory cache for faster subsequent access.
 	s.prQueryCacheMu.Lock()
-	s.prQueryCache[key] = &cacheEntry{data: prs}
+	s.prQueryCache[key] = s.newCacheEntry(prs)
 	s.prQueryCacheMu.Unlock()
 
 	return prs, true
 }
 
-// cachePRQuery stores PR query results in both memory and DataStore caches.
+// cachePRQuery stores PR query results in both memory and persistent caches.
 func (s *Server) cachePRQuery(ctx context.Context, key string, prs []github.PRSummary) {
 	// Write to in-memory cache first (fast path).
 	s.prQueryCacheMu.Lock()
-	s.prQueryCache[key] = &cacheEntry{data: prs}
+	s.prQueryCache[key] = s.newCacheEntry(prs)
 	s.prQueryCacheMu.Unlock()
 
-	// Write to DataStore if available (persistent cache).
-	if s.dsClient == nil {
-		return
-	}
-
-	// Serialize the PR query results.
-	dataJSON, err := json.Marshal(prs)
-	if err != nil {
-		s.logger.WarnContext(ctx, "Failed to serialize PR query for DataStore", "key", key, "error", err)
-		return
-	}
-
-	// Determine query type and TTL from key format.
-	var queryType string
-	var ttl time.Duration
-	switch {
-	case strings.HasPrefix(key, "repo:"):
-		queryType = "repo"
-		ttl = 60 * time.Hour // 60 hours for repo queries
-	case strings.HasPrefix(key, "org:"):
-		queryType = "org"
-		ttl = 60 * time.Hour // 60 hours for org queries
-	default:
+	if !strings.HasPrefix(key, "repo:") && !strings.HasPrefix(key, "org:") {
 		s.logger.WarnContext(ctx, "Unknown query type for key, using default TTL", "key", key)
-		queryType = "unknown"
-		ttl = 60 * time.Hour // Default to 60 hours
-	}
-
-	now := time.Now()
-	entity := prQueryCacheEntity{
-		Data:      string(dataJSON),
-		CachedAt:  now,
-		ExpiresAt: now.Add(ttl),
-		QueryType: queryType,
-		QueryKey:  key,
 	}
-
-	dsKey := datastore.NameKey("PRQueryCache", key, nil)
-	if _, err := s.dsClient.Put(ctx, dsKey, &entity); err != nil {
-		s.logger.WarnContext(ctx, "Failed to write PR query to DataStore", "key", key, "error", err)
-		return
-	}
-
-	s.logger.DebugContext(ctx, "PR query cached to DataStore",
-		"key", key, "query_type", queryType, "ttl", ttl, "expires_at", entity.ExpiresAt, "pr_count", len(prs))
+	s.persist(ctx, prQueryCacheKind, key, prs, 60*time.Hour) // 60 hours for repo and org queries
 }
 
-// cachedPRData retrieves cached PR data from memory first, then DataStore as fallback.
+// cachedPRData retrieves cached PR data from memory first, then the persistent cache as fallback.
 func (s *Server) cachedPRData(ctx context.Context, key string) (cost.PRData, bool) {
 	// Check in-memory cache first (fast path).
 	s.prDataCacheMu.RLock()
 	entry, exists := s.prDataCache[key]
 	s.prDataCacheMu.RUnlock()
 
-	if exists {
+	if exists && !entry.expired(time.Now()) {
 		prData, ok := entry.data.(cost.PRData)
 		if ok {
 			s.logger.DebugContext(ctx, "PR data cache hit (memory)", "key", key)
@@ -480,78 +613,29 @@ func (s *Server) cachedPRData(ctx context.Context, key string) (cost.PRData, boo
 		}
 	}
 
-	// Memory miss - try DataStore if available.
-	if s.dsClient == nil {
-		return cost.PRData{}, false
-	}
-
-	dsKey := datastore.NameKey("PRDataCache", key, nil)
-	var entity prDataCacheEntity
-	err := s.dsClient.Get(ctx, dsKey, &entity)
-	if err != nil {
-		if !errors.Is(err, datastore.ErrNoSuchEntity) {
-			s.logger.WarnContext(ctx, "DataStore cache read failed", "key", key, "error", err)
-		}
-		return cost.PRData{}, false
-	}
-
-	// Check if expired (1 hour TTL for PRs).
-	if time.Now().After(entity.ExpiresAt) {
-		s.logger.DebugContext(ctx, "DataStore cache entry expired", "key", key, "expires_at", entity.ExpiresAt)
-		return cost.PRData{}, false
-	}
-
-	// Deserialize the cached data.
+	// Memory miss - try the persistent cache if available.
 	var prData cost.PRData
-	if err := json.Unmarshal([]byte(entity.Data), &prData); err != nil {
-		s.logger.WarnContext(ctx, "Failed to deserialize cached PR data", "key", key, "error", err)
+	if !s.persisted(ctx, prDataCacheKind, key, &prData) {
 		return cost.PRData{}, false
 	}
-
-	s.logger.InfoContext(ctx, "PR data cache hit (DataStore)", "key", key, "cached_at", entity.CachedAt)
+	s.logger.InfoContext(ctx, "PR data cache hit (persistent)", "key", key)
 
 	// Populate in-memory cache for faster subsequent access.
 	s.prDataCacheMu.Lock()
-	s.prDataCache[key] = &cacheEntry{data: prData}
+	s.prDataCache[key] = s.newCacheEntry(prData)
 	s.prDataCacheMu.Unlock()
 
 	return prData, true
 }
 
-// cachePRData stores PR data in both memory and DataStore caches.
+// cachePRData stores PR data in both memory and persistent caches.
 func (s *Server) cachePRData(ctx context.Context, key string, prData cost.PRData) {
 	// Write to in-memory cache first (fast path).
 	s.prDataCacheMu.Lock()
-	s.prDataCache[key] = &cacheEntry{data: prData}
+	s.prDataCache[key] = s.newCacheEntry(prData)
 	s.prDataCacheMu.Unlock()
 
-	// Write to DataStore if available (persistent cache).
-	if s.dsClient == nil {
-		return
-	}
-
-	// Serialize the PR data.
-	dataJSON, err := json.Marshal(prData)
-	if err != nil {
-		s.logger.WarnContext(ctx, "Failed to serialize PR data for DataStore", "key", key, "error", err)
-		return
-	}
-
-	now := time.Now()
-	entity := prDataCacheEntity{
-		Data:      string(dataJSON),
-		CachedAt:  now,
-		ExpiresAt: now.Add(1 * time.Hour), // 1 hour TTL for PRs
-		URL:       key,
-	}
-
-	dsKey := datastore.NameKey("PRDataCache", key, nil)
-	if _, err := s.dsClient.Put(ctx, dsKey, &entity); err != nil {
-		s.logger.WarnContext(ctx, "Failed to write PR data to DataStore", "key", key, "error", err)
-		return
-	}
-
-	s.logger.DebugContext(ctx, "PR data cached to DataStore", "key", key, "expires_at", entity.ExpiresAt)
+	s.persist(ctx, prDataCacheKind, key, prData, 1*time.Hour) // 1 hour TTL for PRs
 }
 
 // configHash creates a deterministic hash key for a cost.Config.
@@ -559,16 +643,105 @@ func (s *Server) cachePRData(ctx context.Context, key string, prData cost.PRData
 func configHash(cfg cost.Config) string {
 	// Create a deterministic string representation of the config
 	// Use %.2f for floats to avoid floating point precision issues
-	return fmt.Sprintf("s%.0f_e%.0f_ci%.0f_co%.0f_g%.0f_d%.2f",
+	key := fmt.Sprintf("s%.0f_e%.0f_ci%.0f_co%.0f_g%.0f_d%.2f",
 		cfg.AnnualSalary,
 		cfg.EventDuration.Minutes(),
 		cfg.ContextSwitchInDuration.Minutes(),
 		cfg.ContextSwitchOutDuration.Minutes(),
 		cfg.SessionGapThreshold.Minutes(),
 		cfg.DeliveryDelayFactor)
+	if cfg.MaintainerSalary > 0 || cfg.ContributorSalary > 0 {
+		key += fmt.Sprintf("_st%.0f_%.0f", cfg.MaintainerSalary, cfg.ContributorSalary)
+	}
+	// The other parameters mergeConfig can change, hashed so keys stay short. %g keeps full
+	// precision, so fractional values never share a key.
+	params := fmt.Sprintf("%g_%g_%g_%g_%g_%d_%d_%d_%g_%g_%g_%g_%g_%g_%g_%g_%g_%d_%d_%d_%t",
+		cfg.BenefitsMultiplier,
+		cfg.HoursPerYear,
+		cfg.PRTrackingMinutesPerDay,
+		cfg.MinDelayThresholdMinutes,
+		cfg.ReviewInspectionRate,
+		cfg.MaxDelayAfterLastEvent,
+		cfg.MaxProjectDelay,
+		cfg.MaxCodeDrift,
+		cfg.ReviewerDecayFactor,
+		cfg.ReviewWaitFactor,
+		cfg.MinReviewMinutes,
+		cfg.ConflictResolutionMinutes,
+		cfg.ModificationCostFactor,
+		cfg.DeliveryDelayCapacityFraction,
+		cfg.DeliveryDelayFactor,
+		cfg.ReReviewFactor,
+		cfg.MaxWaitingMultiplier,
+		cfg.FiscalYearStartMonth,
+		cfg.ZombieMinAge,
+		cfg.ZombieStaleAfter,
+		cfg.GradeVelocityByMedian)
+	paramsSum := sha256.Sum256([]byte(params))
+	key += "_mp" + hex.EncodeToString(paramsSum[:4])
+	// Every on/off switch of the model, one bit each, so flipping any of them changes the key
+	var switches strings.Builder
+	for _, on := range []bool{
+		cfg.ReviewEventsHaveDuration,
+		cfg.RequireWaitingEvidence,
+		cfg.CountDraftTime,
+		cfg.ExcludeGeneratedFromCost,
+		cfg.EstimateMissingEvents,
+		cfg.CountBotParticipantCosts,
+		cfg.IncludeFutureCosts,
+	} {
+		if on {
+			switches.WriteByte('1')
+		} else {
+			switches.WriteByte('0')
+		}
+	}
+	key += "_f" + switches.String()
+	if cfg.SessionModel == cost.SessionModelFlat {
+		key += "_sf"
+	}
+	if cfg.MaxPRCodeCost > 0 {
+		key += fmt.Sprintf("_pc%.0f", cfg.MaxPRCodeCost)
+	}
+	if cfg.WorkingCalendar != nil {
+		sum := sha256.Sum256([]byte(cfg.WorkingCalendar.String()))
+		key += "_wc" + hex.EncodeToString(sum[:4])
+	}
+	if cfg.COCOMO != cocomo.DefaultConfig() {
+		key += fmt.Sprintf("_cm%g_%g_%s", cfg.COCOMO.Multiplier, cfg.COCOMO.Exponent, cfg.COCOMO.MinimumEffort)
+	}
+	if cfg.EventBurstWindow > 0 {
+		key += fmt.Sprintf("_eb%.0f", cfg.EventBurstWindow.Seconds())
+	}
+	if len(cfg.IgnoredEventKinds) > 0 {
+		kinds := make([]string, len(cfg.IgnoredEventKinds))
+		for i, kind := range cfg.IgnoredEventKinds {
+			kinds[i] = strings.ToLower(kind)
+		}
+		slices.Sort(kinds)
+		sum := sha256.Sum256([]byte(strings.Join(slices.Compact(kinds), ",")))
+		key += "_ie" + hex.EncodeToString(sum[:4])
+	}
+	if len(cfg.ReviewInspectionRates) > 0 {
+		var rates strings.Builder
+		for _, category := range slices.Sorted(maps.Keys(cfg.ReviewInspectionRates)) {
+			fmt.Fprintf(&rates, "%s=%g;", category, cfg.ReviewInspectionRates[category])
+		}
+		sum := sha256.Sum256([]byte(rates.String()))
+		key += "_ir" + hex.EncodeToString(sum[:4])
+	}
+	if len(cfg.SalaryOverrides) == 0 {
+		return key
+	}
+	// Hash the sorted overrides so keys stay short and never contain logins or salaries
+	h := sha256.New()
+	for _, login := range slices.Sorted(maps.Keys(cfg.SalaryOverrides)) {
+		fmt.Fprintf(h, "%s=%.0f;", login, cfg.SalaryOverrides[login])
+	}
+	return key + "_so" + hex.EncodeToString(h.Sum(nil)[:8])
 }
 
-// cachedCalcResult retrieves cached calculation result from memory first, then DataStore as fallback.
+// cachedCalcResult retrieves cached calculation result from memory first, then the persistent cache as fallback.
 func (s *Server) cachedCalcResult(ctx context.Context, prURL string, cfg cost.Config) (cost.Breakdown, bool) {
 	key := fmt.Sprintf("calc:%s:%s", prURL, configHash(cfg))
 
@@ -577,85 +750,37 @@ func (s *Server) cachedCalcResult(ctx context.Context, prURL string, cfg cost.Co
 	entry, exists := s.calcResultCache[key]
 	s.calcResultCacheMu.RUnlock()
 
-	if exists {
+	if exists && !entry.expired(time.Now()) {
 		breakdown, ok := entry.data.(cost.Breakdown)
 		if ok {
 			return breakdown, true
 		}
 	}
 
-	// Memory miss - try DataStore if available.
-	if s.dsClient == nil {
-		return cost.Breakdown{}, false
-	}
-
-	dsKey := datastore.NameKey("CalcResultCache", key, nil)
-	var entity calcResultCacheEntity
-	err := s.dsClient.Get(ctx, dsKey, &entity)
-	if err != nil {
-		if !errors.Is(err, datastore.ErrNoSuchEntity) {
-			s.logger.WarnContext(ctx, "DataStore calc cache read failed", "key", key, "error", err)
-		}
-		return cost.Breakdown{}, false
-	}
-
-	// Check if expired.
-	if time.Now().After(entity.ExpiresAt) {
-		return cost.Breakdown{}, false
-	}
-
-	// Deserialize the cached data.
+	// Memory miss - try the persistent cache if available.
 	var breakdown cost.Breakdown
-	if err := json.Unmarshal([]byte(entity.Data), &breakdown); err != nil {
-		s.logger.WarnContext(ctx, "Failed to deserialize cached calc result", "key", key, "error", err)
+	if !s.persisted(ctx, calcResultCacheKind, key, &breakdown) {
 		return cost.Breakdown{}, false
 	}
 
 	// Populate in-memory cache for faster subsequent access.
 	s.calcResultCacheMu.Lock()
-	s.calcResultCache[key] = &cacheEntry{data: breakdown}
+	s.calcResultCache[key] = s.newCacheEntry(breakdown)
 	s.calcResultCacheMu.Unlock()
 
 	return breakdown, true
 }
 
-// cacheCalcResult stores calculation result in both memory and DataStore caches.
+// cacheCalcResult stores calculation result in both memory and persistent caches.
 func (s *Server) cacheCalcResult(ctx context.Context, prURL string, cfg cost.Config, b *cost.Breakdown, ttl time.Duration) {
 	key := fmt.Sprintf("calc:%s:%s", prURL, configHash(cfg))
 
 	// Write to in-memory cache first (fast path).
 	s.calcResultCacheMu.Lock()
-	s.calcResultCache[key] = &cacheEntry{data: *b}
+	s.calcResultCache[key] = s.newCacheEntry(*b)
 	s.calcResultCacheMu.Unlock()
 
-	// Write to DataStore if available (persistent cache).
-	if s.dsClient == nil {
-		return
-	}
-
-	// Serialize the calculation result.
-	dataJSON, err := json.Marshal(b)
-	if err != nil {
-		s.logger.WarnContext(ctx, "Failed to serialize calc result for DataStore", "key", key, "error", err)
-		return
-	}
-
-	now := time.Now()
-	entity := calcResultCacheEntity{
-		Data:      string(dataJSON),
-		CachedAt:  now,
-		ExpiresAt: now.Add(ttl),
-		URL:       prURL,
-		ConfigKey: configHash(cfg),
-	}
-
-	dsKey := datastore.NameKey("CalcResultCache", key, nil)
-	if _, err := s.dsClient.Put(ctx, dsKey, &entity); err != nil {
-		s.logger.WarnContext(ctx, "Failed to write calc result to DataStore", "key", key, "error", err)
-		return
-	}
-
-	s.logger.DebugContext(ctx, "Calc result cached to DataStore", "key", key, "ttl", ttl, "expires_at", entity.ExpiresAt)
+	s.persist(ctx, calcResultCacheKind, key, b, ttl)
 }
 
 // SetTokenValidation configures GitHub token validation.
@@ -672,9 +797,39 @@ func (s *Server) SetTokenValidation(appID string, keyFile string) error {
 	return nil
 }
 
-// Shutdown gracefully shuts down the server.
-func (*Server) Shutdown() {
-	// Nothing to do - in-memory structures will be garbage collected.
+// SetGitHubAppTokens makes the server mint its own GitHub App installation tokens for requests
+// without an Authorization header, in place of a static GITHUB_TOKEN. Tokens are minted for
+// installationID, or, if it is 0, for the installation on the owner each request is for, and
+// refreshed before they expire.
+func (s *Server) SetGitHubAppTokens(appID, keyFile string, installationID int64) error {
+	keyData, err := os.ReadFile(keyFile)
+	if err != nil {
+		return fmt.Errorf("read GitHub App key file: %w", err)
+	}
+	source, err := github.NewAppTokenSource(appID, keyData, installationID, s.githubHost, &s.githubAPI)
+	if err != nil {
+		return err
+	}
+	s.githubAppID = appID
+	s.githubAppKeyData = keyData
+	s.appTokens = source
+	s.logger.InfoContext(context.Background(), "GitHub App installation tokens enabled",
+		"github_app_id", appID, "installation_id", installationID)
+	return nil
+}
+
+// Shutdown gracefully shuts down the server, stopping the cache sweeper if SetCacheTTL started one
+// and closing the persistent cache if it is an io.Closer.
+// In-memory structures will be garbage collected.
+func (s *Server) Shutdown() {
+	if s.stopCacheSweep != nil {
+		s.stopCacheSweep()
+	}
+	if closer, ok := s.persistentCache.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			s.logger.WarnContext(context.Background(), "Failed to close persistent cache", "error", err)
+		}
+	}
 }
 
 // sanitizeError removes tokens from error messages before logging.
@@ -688,6 +843,8 @@ func sanitizeError(err error) string {
 
 // ServeHTTP implements http.Handler interface.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r = r.WithContext(s.withGitHubHost(r.Context()))
+
 	// Apply CSRF protection FIRST - blocks cross-origin POST requests.
 	// Uses Sec-Fetch-Site and Origin headers to detect cross-origin requests.
 	// GET, HEAD, and OPTIONS methods are always allowed (safe methods).
@@ -741,6 +898,18 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		s.handleCalculate(w, r)
+	case r.URL.Path == "/v1/compare":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.handleCompare(w, r)
+	case r.URL.Path == "/v1/grade/repo", r.URL.Path == "/v1/grade/org":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.handleGrade(w, r)
 	case r.URL.Path == "/v1/calculate/repo":
 		if r.Method != http.MethodPost && r.Method != http.MethodGet {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -753,20 +922,58 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		s.handleOrgSample(w, r)
+	case r.URL.Path == "/v1/calculate/org/trend":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.handleOrgTrend(w, r)
 	case r.URL.Path == "/v1/calculate/repo/stream":
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
-		s.handleRepoSampleStream(w, r)
+		s.handleRepoSampleStream(w, r, sseEncoder{})
 	case r.URL.Path == "/v1/calculate/org/stream":
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
-		s.handleOrgSampleStream(w, r)
+		s.handleOrgSampleStream(w, r, sseEncoder{})
+	case r.URL.Path == "/v1/calculate/repo/ndjson":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.handleRepoSampleStream(w, r, ndjsonEncoder{})
+	case r.URL.Path == "/v1/calculate/org/ndjson":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.handleOrgSampleStream(w, r, ndjsonEncoder{})
+	case r.URL.Path == "/v1/config":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.handleConfig(w, r)
+	case r.URL.Path == "/v1/schema":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.handleSchema(w, r)
+	case r.URL.Path == "/v1/export/svg":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.handleExportSVG(w, r)
 	case r.URL.Path == "/health":
 		s.handleHealth(w, r)
+	case r.URL.Path == "/health/ready":
+		s.handleReady(w, r)
 	case strings.HasPrefix(r.URL.Path, "/static/"):
 		s.handleStatic(w, r)
 	case r.URL.Path == "/":
@@ -780,29 +987,7 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleCalculate(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 
-	// Extract client IP for rate limiting and logging.
-	// SECURITY: X-Forwarded-For is trusted because Cloud Run (GCP) sanitizes it.
-	// Cloud Run strips client-provided XFF headers and replaces with actual client IP.
-	// For non-Cloud Run deployments, consider validating source or using RemoteAddr only.
-	clientIP := request.RemoteAddr
-	if xff := request.Header.Get("X-Forwarded-For"); xff != "" {
-		if idx := strings.Index(xff, ","); idx > 0 {
-			clientIP = strings.TrimSpace(xff[:idx])
-		} else {
-			clientIP = strings.TrimSpace(xff)
-		}
-	} else if host, _, err := net.SplitHostPort(request.RemoteAddr); err == nil {
-		clientIP = host
-	}
-
-	// Log incoming request.
-	s.logger.InfoContext(ctx, "[handleCalculate] Incoming request", "client_ip", clientIP, "method", request.Method, "path", request.URL.Path)
-
-	// Per-IP rate limiting (SECURITY: Prevents single client from DoS-ing all users).
-	limiter := s.limiter(ctx, clientIP)
-	if !limiter.Allow() {
-		s.logger.WarnContext(ctx, "[handleCalculate] Rate limit exceeded", "client_ip", clientIP, "path", request.URL.Path)
-		http.Error(writer, "Rate limit exceeded", http.StatusTooManyRequests)
+	if !s.allowRequest(writer, request, "handleCalculate") {
 		return
 	}
 
@@ -814,25 +999,10 @@ func (s *Server) handleCalculate(writer http.ResponseWriter, request *http.Reque
 		return
 	}
 
-	// Get auth token - try Authorization header first, then fallback to env/GSM.
-	token := s.extractToken(request)
-	if token == "" {
-		// Try fallback token (GITHUB_TOKEN env var or GITHUB_SECRET from GSM)
-		token = s.token(ctx)
-		if token == "" {
-			s.logger.WarnContext(ctx, "[handleCalculate] No GitHub token available", "remote_addr", request.RemoteAddr)
-			http.Error(writer, "GitHub token required (set GITHUB_TOKEN env var or provide Authorization header)", http.StatusUnauthorized)
-			return
-		}
-	}
-
-	// Validate token if configured.
-	if s.validateTokens {
-		if err := s.validateGitHubToken(ctx, token); err != nil {
-			s.logger.WarnContext(ctx, "[handleCalculate] Token validation failed", "remote_addr", request.RemoteAddr, errorKey, sanitizeError(err))
-			http.Error(writer, "Invalid or expired token", http.StatusUnauthorized)
-			return
-		}
+	owner, repo := prOwnerRepo(req.URL)
+	token, ok := s.requestToken(writer, request, "handleCalculate", owner, repo)
+	if !ok {
+		return
 	}
 
 	// Process request.
@@ -844,6 +1014,10 @@ func (s *Server) handleCalculate(writer http.ResponseWriter, request *http.Reque
 		return
 	}
 
+	if req.Anonymize {
+		cost.NewAnonymizer([]cost.Breakdown{response.Breakdown}).Breakdown(&response.Breakdown)
+	}
+
 	// Send response.
 	writer.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(writer).Encode(response); err != nil {
@@ -867,6 +1041,7 @@ func (s *Server) parseRequest(ctx context.Context, r *http.Request) (*CalculateR
 		query := r.URL.Query()
 		req.URL = query.Get("url")
 		req.Config = parseConfigFromQuery(query)
+		req.Anonymize, _ = strconv.ParseBool(query.Get("anonymize")) //nolint:errcheck // invalid values mean false
 	} else {
 		// Handle POST requests with JSON body
 		// SECURITY: Limit request body size to prevent memory exhaustion DoS.
@@ -884,24 +1059,28 @@ func (s *Server) parseRequest(ctx context.Context, r *http.Request) (*CalculateR
 		return nil, errors.New("missing required field: url")
 	}
 
-	// Validate GitHub PR URL format.
-	if err := s.validateGitHubPRURL(req.URL); err != nil {
+	// Validate PR URL format.
+	if err := s.validatePRURL(req.URL); err != nil {
 		s.logger.ErrorContext(ctx, "[parseRequest] Invalid URL", "url", req.URL, errorKey, err.Error())
 		return nil, err
 	}
+	if err := validateConfigOverride(req.Config); err != nil {
+		return nil, err
+	}
 
 	return &req, nil
 }
 
-// parseConfigFromQuery extracts salary and benefits from query parameters.
-func parseConfigFromQuery(query url.Values) *cost.Config {
+// parseConfigFromQuery extracts salary, benefits, and the minimum delay threshold from query parameters.
+func parseConfigFromQuery(query url.Values) *ConfigOverride {
 	salaryStr := query.Get("salary")
 	benefitsStr := query.Get("benefits")
-	if salaryStr == "" && benefitsStr == "" {
+	minDelayStr := query.Get("min_delay_minutes")
+	if salaryStr == "" && benefitsStr == "" && minDelayStr == "" {
 		return nil
 	}
 
-	cfg := &cost.Config{}
+	cfg := &ConfigOverride{}
 	if salaryStr != "" {
 		if salary, err := strconv.ParseFloat(salaryStr, 64); err == nil {
 			cfg.AnnualSalary = salary
@@ -912,11 +1091,16 @@ func parseConfigFromQuery(query url.Values) *cost.Config {
 			cfg.BenefitsMultiplier = benefits
 		}
 	}
+	if minDelayStr != "" {
+		if minDelay, err := strconv.ParseFloat(minDelayStr, 64); err == nil {
+			cfg.MinDelayThresholdMinutes = minDelay
+		}
+	}
 	return cfg
 }
 
-// validateGitHubPRURL performs strict validation of GitHub PR URLs.
-func (*Server) validateGitHubPRURL(prURL string) error {
+// validateGitHubPRURL performs strict validation of GitHub PR URLs on the configured host.
+func (s *Server) validateGitHubPRURL(prURL string) error {
 	// Length check prevents DoS attacks with extremely long URLs.
 	if len(prURL) > maxURLLength {
 		return errors.New("URL too long")
@@ -927,9 +1111,9 @@ func (*Server) validateGitHubPRURL(prURL string) error {
 		return fmt.Errorf("invalid URL: %w", err)
 	}
 
-	// Only accept https://github.com URLs (prevents SSRF).
-	if u.Scheme != "https" || u.Host != "github.com" {
-		return errors.New("only https://github.com URLs allowed")
+	// Only accept https URLs on the configured GitHub host (prevents SSRF).
+	if u.Scheme != "https" || u.Host != s.githubHost {
+		return fmt.Errorf("only https://%s URLs allowed", s.githubHost)
 	}
 
 	// Reject URLs with credentials, query params, or fragments.
@@ -947,6 +1131,20 @@ func (*Server) validateGitHubPRURL(prURL string) error {
 	return nil
 }
 
+// prOwnerRepo returns the owner and repo of a validated PR URL, to choose the GitHub App
+// installation that fetches it. Both are empty if the URL doesn't have them.
+func prOwnerRepo(prURL string) (owner, repo string) {
+	u, err := url.Parse(prURL)
+	if err != nil {
+		return "", ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
 // extractToken extracts the GitHub token from the Authorization header.
 func (*Server) extractToken(r *http.Request) string {
 	auth := r.Header.Get("Authorization")
@@ -965,10 +1163,86 @@ func (*Server) extractToken(r *http.Request) string {
 	return auth
 }
 
+// clientIP returns the address of the client that sent r, for rate limiting and logging.
+// SECURITY: X-Forwarded-For is trusted because Cloud Run (GCP) sanitizes it.
+// Cloud Run strips client-provided XFF headers and replaces with actual client IP.
+// For non-Cloud Run deployments, consider validating source or using RemoteAddr only.
+func clientIP(r *http.Request) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		if idx := strings.Index(xff, ","); idx > 0 {
+			return strings.TrimSpace(xff[:idx])
+		}
+		return strings.TrimSpace(xff)
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// allowRequest logs an incoming request and applies its client's rate limit
+// (SECURITY: prevents a single client from DoS-ing all users). Over the limit, it writes
+// a 429 response and returns false. handler names the caller in log messages.
+func (s *Server) allowRequest(writer http.ResponseWriter, request *http.Request, handler string) bool {
+	ctx := request.Context()
+	ip := clientIP(request)
+	s.logger.InfoContext(ctx, "["+handler+"] Incoming request", "client_ip", ip, "method", request.Method, "path", request.URL.Path)
+
+	limiter := s.limiter(ctx, ip)
+	if !limiter.Allow() {
+		s.logger.WarnContext(ctx, "["+handler+"] Rate limit exceeded", "client_ip", ip, "path", request.URL.Path)
+		writeRateLimited(writer, limiter)
+		return false
+	}
+	return true
+}
+
+// requestToken returns the GitHub token for a request on owner's repos (repo, if the request is
+// for one): the Authorization header's, or else the server's fallback token (see token). If there is none, or SetTokenValidation is on and the
+// token is rejected, it writes a 401 response and returns false. handler names the caller in
+// log messages.
+func (s *Server) requestToken(writer http.ResponseWriter, request *http.Request, handler, owner, repo string) (string, bool) {
+	ctx := request.Context()
+	token := s.extractToken(request)
+	if token == "" {
+		token = s.token(ctx, owner, repo)
+	}
+	if token == "" {
+		s.logger.WarnContext(ctx, "["+handler+"] No GitHub token available", "remote_addr", request.RemoteAddr)
+		http.Error(writer, "GitHub token required (set GITHUB_TOKEN env var or provide Authorization header)", http.StatusUnauthorized)
+		return "", false
+	}
+
+	if s.validateTokens {
+		if err := s.validateGitHubToken(ctx, token); err != nil {
+			s.logger.WarnContext(ctx, "["+handler+"] Token validation failed", "remote_addr", request.RemoteAddr, errorKey, sanitizeError(err))
+			http.Error(writer, "Invalid or expired token", http.StatusUnauthorized)
+			return "", false
+		}
+	}
+	return token, true
+}
+
 // token retrieves a GitHub token from environment or Google Secret Manager.
 // Results are cached in memory to avoid repeated API calls (performance and billing).
-// Priority: GITHUB_TOKEN env var, then GITHUB_TOKEN from GSM.
-func (s *Server) token(ctx context.Context) string {
+// Priority: a GitHub App installation token for owner's installation (see SetGitHubAppTokens),
+// GITHUB_TOKEN env var, gh auth token, then GITHUB_TOKEN from GSM. Without an owner, the App
+// token is for its configured or only installation.
+func (s *Server) token(ctx context.Context, owner, repo string) string {
+	// App tokens expire, so they are cached by the token source rather than as the fallback token
+	if s.appTokens != nil && s.dataSource != "gitlab" {
+		token, err := s.appTokens.Token(ctx, owner, repo)
+		if err == nil {
+			return token
+		}
+		s.logger.WarnContext(ctx, "Failed to get GitHub App installation token; trying static tokens", errorKey, err)
+	}
+	return s.staticToken(ctx)
+}
+
+// staticToken returns the cached fallback token, loading it on first use from GITHUB_TOKEN
+// (GITLAB_TOKEN for GitLab), the gh CLI or Google Secret Manager.
+func (s *Server) staticToken(ctx context.Context) string {
 	// Check cache first (read lock)
 	s.fallbackTokenMu.RLock()
 	if s.fallbackToken != "" {
@@ -987,6 +1261,12 @@ func (s *Server) token(ctx context.Context) string {
 		return s.fallbackToken
 	}
 
+	// GitLab deployments only use a GitLab token; GitHub credentials must never be sent to GitLab
+	if s.dataSource == "gitlab" {
+		s.fallbackToken = os.Getenv("GITLAB_TOKEN")
+		return s.fallbackToken
+	}
+
 	// Try GITHUB_TOKEN environment variable first (for local development)
 	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
 		s.logger.InfoContext(ctx, "Using GITHUB_TOKEN from environment variable")
@@ -994,25 +1274,18 @@ func (s *Server) token(ctx context.Context) string {
 		return token
 	}
 
-	// Try gh auth token if gh is in PATH
-	if ghPath, err := exec.LookPath("gh"); err == nil {
-		s.logger.InfoContext(ctx, "Found gh CLI in PATH", "path", ghPath)
-		cmd := exec.CommandContext(ctx, "gh", "auth", "token")
-		output, err := cmd.Output()
-		if err == nil {
-			token := strings.TrimSpace(string(output))
-			if token != "" {
-				s.logger.InfoContext(ctx, "Using GITHUB_TOKEN from gh auth token")
-				s.fallbackToken = token
-				return token
-			}
-		} else {
-			s.logger.WarnContext(ctx, "Failed to get token from gh auth token", errorKey, err)
-		}
+	// Try gh auth token if gh is in PATH, for the configured host so a GitHub Enterprise
+	// deployment doesn't pick up a github.com token
+	if token, err := s.ghAuthToken(ctx, s.githubHost); err != nil {
+		s.logger.WarnContext(ctx, "Failed to get token from gh auth token", errorKey, err)
+	} else if token != "" {
+		s.logger.InfoContext(ctx, "Using GITHUB_TOKEN from gh auth token")
+		s.fallbackToken = token
+		return token
 	}
 
 	// Try Google Secret Manager for GITHUB_TOKEN
-	token, err := gsm.Fetch(ctx, "GITHUB_TOKEN")
+	token, err := s.gsmToken(ctx, "GITHUB_TOKEN")
 	if err != nil {
 		s.logger.WarnContext(ctx, "Failed to fetch GITHUB_TOKEN from GSM", errorKey, err)
 		return ""
@@ -1028,6 +1301,18 @@ func (s *Server) token(ctx context.Context) string {
 	return ""
 }
 
+// ghAuthToken returns the gh CLI's token for host, or "" if gh isn't in PATH.
+func ghAuthToken(ctx context.Context, host string) (string, error) {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return "", nil //nolint:nilerr // no gh CLI is not an error
+	}
+	output, err := exec.CommandContext(ctx, "gh", "auth", "token", "--hostname", host).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 // processRequest processes the PR cost calculation request.
 func (s *Server) processRequest(ctx context.Context, req *CalculateRequest, token string) (*CalculateResponse, error) {
 	// Use default config if not provided, otherwise merge with defaults.
@@ -1040,9 +1325,10 @@ func (s *Server) processRequest(ctx context.Context, req *CalculateRequest, toke
 	breakdown, calcCached := s.cachedCalcResult(ctx, req.URL, cfg)
 	if calcCached {
 		return &CalculateResponse{
-			Breakdown: breakdown,
-			Timestamp: time.Now(),
-			Commit:    s.serverCommit,
+			SchemaVersion: cost.SchemaVersion,
+			Breakdown:     breakdown,
+			Timestamp:     time.Now(),
+			Commit:        s.serverCommit,
 		}, nil
 	}
 
@@ -1055,32 +1341,16 @@ func (s *Server) processRequest(ctx context.Context, req *CalculateRequest, toke
 		var err error
 		// For single PR requests, use 1 hour ago as reference time to enable reasonable caching
 		referenceTime := time.Now().Add(-1 * time.Hour)
-		if s.dataSource == "turnserver" {
-			// Use turnserver for PR data with analysis
-			prDataWithAnalysis, err := github.FetchPRDataWithAnalysisViaTurnserver(ctx, req.URL, token, referenceTime)
-			if err != nil {
-				s.logger.ErrorContext(ctx, "[processRequest] Failed to fetch PR data", "url", req.URL, "source", s.dataSource, errorKey, err)
-				// Check if it's an access error (404, 403) - return error to client.
-				if IsAccessError(err) {
-					s.logger.WarnContext(ctx, "[processRequest] Access denied", "url", req.URL)
-					return nil, NewAccessError(http.StatusForbidden, "access denied to PR")
-				}
-				return nil, fmt.Errorf("failed to fetch PR data: %w", err)
-			}
-			prData = prDataWithAnalysis.PRData
-			secondsInState = prDataWithAnalysis.Analysis.SecondsInState
-		} else {
-			// Use prx for PR data
-			prData, err = github.FetchPRData(ctx, req.URL, token, referenceTime)
-			if err != nil {
-				s.logger.ErrorContext(ctx, "[processRequest] Failed to fetch PR data", "url", req.URL, "source", s.dataSource, errorKey, err)
-				// Check if it's an access error (404, 403) - return error to client.
-				if IsAccessError(err) {
-					s.logger.WarnContext(ctx, "[processRequest] Access denied", "url", req.URL)
-					return nil, NewAccessError(http.StatusForbidden, "access denied to PR")
-				}
-				return nil, fmt.Errorf("failed to fetch PR data: %w", err)
+		prData, secondsInState, err = s.fetchPRData(ctx, req.URL, token, referenceTime)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "[processRequest] Failed to fetch PR data", "url", req.URL, "source", s.dataSource, errorKey, err)
+			s.forgetTokenOnAuthError(ctx, token, err)
+			// Check if it's an access error (404, 403) - return error to client.
+			if IsAccessError(err) {
+				s.logger.WarnContext(ctx, "[processRequest] Access denied", "url", req.URL)
+				return nil, NewAccessError(http.StatusForbidden, "access denied to PR")
 			}
+			return nil, fmt.Errorf("failed to fetch PR data: %w", err)
 		}
 
 		s.logger.InfoContext(ctx, "[processRequest] PR data cache miss - fetched from GitHub", "url", req.URL)
@@ -1093,8 +1363,10 @@ func (s *Server) processRequest(ctx context.Context, req *CalculateRequest, toke
 
 	// Cache the calculation result with 1 hour TTL for direct PR requests
 	s.cacheCalcResult(ctx, req.URL, cfg, &breakdown, 1*time.Hour)
+	s.publishResult(ctx, &PublishedResult{Kind: "pr", Key: prRepoKey(req.URL), URL: req.URL, Breakdown: &breakdown})
 
 	return &CalculateResponse{
+		SchemaVersion:  cost.SchemaVersion,
 		Breakdown:      breakdown,
 		Timestamp:      time.Now(),
 		Commit:         s.serverCommit,
@@ -1178,8 +1450,12 @@ func (s *Server) isOriginAllowed(origin string) bool {
 
 // validateGitHubToken validates a GitHub token by making a test API call.
 func (s *Server) validateGitHubToken(ctx context.Context, token string) error {
+	if s.tokenValidated(token) {
+		return nil
+	}
+
 	// Simple validation by checking the user endpoint.
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/user", http.NoBody)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, github.APIBaseURL(s.withGitHubHost(ctx))+"/user", http.NoBody)
 	if err != nil {
 		return fmt.Errorf("create request: %w", err)
 	}
@@ -1206,10 +1482,11 @@ func (s *Server) validateGitHubToken(ctx context.Context, token string) error {
 		return fmt.Errorf("invalid token (status %d)", resp.StatusCode)
 	}
 
+	s.cacheValidatedToken(token)
 	return nil
 }
 
-// handleHealth provides a simple health check endpoint.
+// handleHealth provides a cheap liveness check that never calls GitHub; see handleReady for readiness.
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
@@ -1282,29 +1559,7 @@ func (s *Server) handleStatic(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleRepoSample(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 
-	// Extract client IP for rate limiting and logging.
-	// SECURITY: X-Forwarded-For is trusted because Cloud Run (GCP) sanitizes it.
-	// Cloud Run strips client-provided XFF headers and replaces with actual client IP.
-	// For non-Cloud Run deployments, consider validating source or using RemoteAddr only.
-	clientIP := request.RemoteAddr
-	if xff := request.Header.Get("X-Forwarded-For"); xff != "" {
-		if idx := strings.Index(xff, ","); idx > 0 {
-			clientIP = strings.TrimSpace(xff[:idx])
-		} else {
-			clientIP = strings.TrimSpace(xff)
-		}
-	} else if host, _, err := net.SplitHostPort(request.RemoteAddr); err == nil {
-		clientIP = host
-	}
-
-	// Log incoming request.
-	s.logger.InfoContext(ctx, "[handleRepoSample] Incoming request", "client_ip", clientIP)
-
-	// Per-IP rate limiting.
-	limiter := s.limiter(ctx, clientIP)
-	if !limiter.Allow() {
-		s.logger.WarnContext(ctx, "[handleRepoSample] Rate limit exceeded", "client_ip", clientIP)
-		http.Error(writer, "Rate limit exceeded", http.StatusTooManyRequests)
+	if !s.allowRequest(writer, request, "handleRepoSample") {
 		return
 	}
 
@@ -1316,24 +1571,25 @@ func (s *Server) handleRepoSample(writer http.ResponseWriter, request *http.Requ
 		return
 	}
 
-	// Get auth token - try Authorization header first, then fallback.
-	token := s.extractToken(request)
-	if token == "" {
-		token = s.token(ctx)
-		if token == "" {
-			s.logger.WarnContext(ctx, "[handleRepoSample] No GitHub token available", "remote_addr", request.RemoteAddr)
-			http.Error(writer, "GitHub token required (set GITHUB_TOKEN env var or provide Authorization header)", http.StatusUnauthorized)
-			return
-		}
+	token, ok := s.requestToken(writer, request, "handleRepoSample", req.Owner, req.Repo)
+	if !ok {
+		return
 	}
 
-	// Validate token if configured.
-	if s.validateTokens {
-		if err := s.validateGitHubToken(ctx, token); err != nil {
-			s.logger.WarnContext(ctx, "[handleRepoSample] Token validation failed", "remote_addr", request.RemoteAddr, errorKey, sanitizeError(err))
-			http.Error(writer, "Invalid or expired token", http.StatusUnauthorized)
+	// Dry runs list the PRs that would be sampled without fetching PR data.
+	if req.DryRun {
+		plan, err := s.planRepoSample(ctx, req, token)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "[handleRepoSample] Error planning sample",
+				"remote_addr", request.RemoteAddr, "owner", req.Owner, "repo", req.Repo, errorKey, sanitizeError(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
 			return
 		}
+		writer.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(writer).Encode(plan); err != nil {
+			s.logger.ErrorContext(ctx, "[handleRepoSample] Error encoding response", errorKey, err)
+		}
+		return
 	}
 
 	// Process request.
@@ -1361,29 +1617,7 @@ func (s *Server) handleRepoSample(writer http.ResponseWriter, request *http.Requ
 func (s *Server) handleOrgSample(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 
-	// Extract client IP for rate limiting and logging.
-	// SECURITY: X-Forwarded-For is trusted because Cloud Run (GCP) sanitizes it.
-	// Cloud Run strips client-provided XFF headers and replaces with actual client IP.
-	// For non-Cloud Run deployments, consider validating source or using RemoteAddr only.
-	clientIP := request.RemoteAddr
-	if xff := request.Header.Get("X-Forwarded-For"); xff != "" {
-		if idx := strings.Index(xff, ","); idx > 0 {
-			clientIP = strings.TrimSpace(xff[:idx])
-		} else {
-			clientIP = strings.TrimSpace(xff)
-		}
-	} else if host, _, err := net.SplitHostPort(request.RemoteAddr); err == nil {
-		clientIP = host
-	}
-
-	// Log incoming request.
-	s.logger.InfoContext(ctx, "[handleOrgSample] Incoming request", "client_ip", clientIP)
-
-	// Per-IP rate limiting.
-	limiter := s.limiter(ctx, clientIP)
-	if !limiter.Allow() {
-		s.logger.WarnContext(ctx, "[handleOrgSample] Rate limit exceeded", "client_ip", clientIP)
-		http.Error(writer, "Rate limit exceeded", http.StatusTooManyRequests)
+	if !s.allowRequest(writer, request, "handleOrgSample") {
 		return
 	}
 
@@ -1394,25 +1628,26 @@ func (s *Server) handleOrgSample(writer http.ResponseWriter, request *http.Reque
 		http.Error(writer, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	// Get auth token - try Authorization header first, then fallback.
-	token := s.extractToken(request)
-	if token == "" {
-		token = s.token(ctx)
-		if token == "" {
-			s.logger.WarnContext(ctx, "[handleOrgSample] No GitHub token available", "remote_addr", request.RemoteAddr)
-			http.Error(writer, "GitHub token required (set GITHUB_TOKEN env var or provide Authorization header)", http.StatusUnauthorized)
-			return
-		}
+
+	token, ok := s.requestToken(writer, request, "handleOrgSample", req.Org, "")
+	if !ok {
+		return
 	}
 
-	// Validate token if configured.
-	if s.validateTokens {
-		if err := s.validateGitHubToken(ctx, token); err != nil {
-			s.logger.WarnContext(ctx, "[handleOrgSample] Token validation failed", "remote_addr", request.RemoteAddr, errorKey, sanitizeError(err))
-			http.Error(writer, "Invalid or expired token", http.StatusUnauthorized)
+	// Dry runs list the PRs that would be sampled without fetching PR data.
+	if req.DryRun {
+		plan, err := s.planOrgSample(ctx, req, token)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "[handleOrgSample] Error planning sample",
+				"remote_addr", request.RemoteAddr, "org", req.Org, errorKey, sanitizeError(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
 			return
 		}
+		writer.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(writer).Encode(plan); err != nil {
+			s.logger.ErrorContext(ctx, "[handleOrgSample] Error encoding response", errorKey, err)
+		}
+		return
 	}
 
 	// Process request.
@@ -1438,6 +1673,9 @@ func (s *Server) handleOrgSample(writer http.ResponseWriter, request *http.Reque
 
 // parseRepoSampleRequest parses and validates repository sampling requests.
 func (s *Server) parseRepoSampleRequest(ctx context.Context, r *http.Request) (*RepoSampleRequest, error) {
+	if s.dataSource == "gitlab" {
+		return nil, errGitLabSampling
+	}
 	var req RepoSampleRequest
 
 	// Handle GET requests with query parameters
@@ -1458,6 +1696,17 @@ func (s *Server) parseRepoSampleRequest(ctx context.Context, r *http.Request) (*
 			}
 		}
 		req.Config = parseConfigFromQuery(query)
+		req.DryRun, _ = strconv.ParseBool(query.Get("dry_run")) //nolint:errcheck // invalid values mean false
+		req.Labels = query["label"]
+		req.Author = query.Get("author")
+		req.State = query.Get("state")
+		req.ExcludeBots, _ = strconv.ParseBool(query.Get("exclude_bots")) //nolint:errcheck // invalid values mean false
+		req.Paths = query["path"]
+		req.Since = query.Get("since")
+		req.Until = query.Get("until")
+		req.IncludeSamples, _ = strconv.ParseBool(query.Get("include_samples")) //nolint:errcheck // invalid values mean false
+		req.Anonymize, _ = strconv.ParseBool(query.Get("anonymize"))            //nolint:errcheck // invalid values mean false
+		req.Sampling = query.Get("sampling")
 	} else {
 		// Handle POST requests with JSON body
 		const maxRequestSize = 1 << 20 // 1MB
@@ -1476,12 +1725,18 @@ func (s *Server) parseRepoSampleRequest(ctx context.Context, r *http.Request) (*
 		return nil, errors.New("missing required field: repo")
 	}
 
+	since, until, err := parseSampleWindow(&req.Days, req.Since, req.Until)
+	if err != nil {
+		return nil, err
+	}
+	req.since, req.until = since, until
+
 	// Set defaults
 	if req.SampleSize == 0 {
 		req.SampleSize = 250
 	}
 	if req.Days == 0 {
-		req.Days = 60
+		req.Days = defaultSampleDays
 	}
 
 	// Validate reasonable limits (silently cap at 250)
@@ -1491,15 +1746,39 @@ func (s *Server) parseRepoSampleRequest(ctx context.Context, r *http.Request) (*
 	if req.SampleSize > 250 {
 		req.SampleSize = 250
 	}
-	if req.Days < 1 || req.Days > 365 {
+	if req.Days < 1 || req.Days > maxSampleDays {
 		return nil, errors.New("days must be between 1 and 365")
 	}
+	state, err := github.ParsePRState(req.State)
+	if err != nil {
+		return nil, err
+	}
+	req.State = string(state)
+	sampling, err := github.ParseSamplingMode(req.Sampling)
+	if err != nil {
+		return nil, err
+	}
+	req.Sampling = string(sampling)
+	if req.Author != "" {
+		if err := github.ValidateAuthor(req.Author); err != nil {
+			return nil, err
+		}
+	}
+	if err := cost.ValidatePathPatterns(req.Paths); err != nil {
+		return nil, err
+	}
+	if err := validateConfigOverride(req.Config); err != nil {
+		return nil, err
+	}
 
 	return &req, nil
 }
 
 // parseOrgSampleRequest parses and validates organization sampling requests.
 func (s *Server) parseOrgSampleRequest(ctx context.Context, r *http.Request) (*OrgSampleRequest, error) {
+	if s.dataSource == "gitlab" {
+		return nil, errGitLabSampling
+	}
 	var req OrgSampleRequest
 
 	// Handle GET requests with query parameters
@@ -1519,6 +1798,17 @@ func (s *Server) parseOrgSampleRequest(ctx context.Context, r *http.Request) (*O
 			}
 		}
 		req.Config = parseConfigFromQuery(query)
+		req.DryRun, _ = strconv.ParseBool(query.Get("dry_run")) //nolint:errcheck // invalid values mean false
+		req.Labels = query["label"]
+		req.Author = query.Get("author")
+		req.State = query.Get("state")
+		req.ExcludeBots, _ = strconv.ParseBool(query.Get("exclude_bots")) //nolint:errcheck // invalid values mean false
+		req.Paths = query["path"]
+		req.Since = query.Get("since")
+		req.Until = query.Get("until")
+		req.IncludeSamples, _ = strconv.ParseBool(query.Get("include_samples")) //nolint:errcheck // invalid values mean false
+		req.Anonymize, _ = strconv.ParseBool(query.Get("anonymize"))            //nolint:errcheck // invalid values mean false
+		req.Sampling = query.Get("sampling")
 	} else {
 		// Handle POST requests with JSON body
 		const maxRequestSize = 1 << 20 // 1MB
@@ -1534,12 +1824,18 @@ func (s *Server) parseOrgSampleRequest(ctx context.Context, r *http.Request) (*O
 		return nil, errors.New("missing required field: org")
 	}
 
+	since, until, err := parseSampleWindow(&req.Days, req.Since, req.Until)
+	if err != nil {
+		return nil, err
+	}
+	req.since, req.until = since, until
+
 	// Set defaults
 	if req.SampleSize == 0 {
 		req.SampleSize = 250
 	}
 	if req.Days == 0 {
-		req.Days = 60
+		req.Days = defaultSampleDays
 	}
 
 	// Validate reasonable limits (silently cap at 250)
@@ -1549,36 +1845,70 @@ func (s *Server) parseOrgSampleRequest(ctx context.Context, r *http.Request) (*O
 	if req.SampleSize > 250 {
 		req.SampleSize = 250
 	}
-	if req.Days < 1 || req.Days > 365 {
+	if req.Days < 1 || req.Days > maxSampleDays {
 		return nil, errors.New("days must be between 1 and 365")
 	}
+	state, err := github.ParsePRState(req.State)
+	if err != nil {
+		return nil, err
+	}
+	req.State = string(state)
+	sampling, err := github.ParseSamplingMode(req.Sampling)
+	if err != nil {
+		return nil, err
+	}
+	req.Sampling = string(sampling)
+	if req.Author != "" {
+		if err := github.ValidateAuthor(req.Author); err != nil {
+			return nil, err
+		}
+	}
+	if err := cost.ValidatePathPatterns(req.Paths); err != nil {
+		return nil, err
+	}
+	if err := validateConfigOverride(req.Config); err != nil {
+		return nil, err
+	}
 
 	return &req, nil
 }
 
-// processRepoSample processes a repository sampling request.
-func (s *Server) processRepoSample(ctx context.Context, req *RepoSampleRequest, token string) (*SampleResponse, error) {
-	var actualDays int
-	// Use default config if not provided
-	cfg := cost.DefaultConfig()
-	if req.Config != nil {
-		cfg = s.mergeConfig(cfg, req.Config)
+// stateCacheSuffix distinguishes PR query cache keys for state-filtered queries, which GitHub
+// filters server-side. Unfiltered queries keep their original keys.
+func stateCacheSuffix(state string) string {
+	if github.PRState(state).IsAll() {
+		return ""
 	}
+	return ":state=" + state
+}
 
-	// Calculate since date
-	since := time.Now().AddDate(0, 0, -req.Days)
+// authorCacheSuffix distinguishes org PR query cache keys for author-scoped queries, which
+// GitHub's search narrows to that author.
+func authorCacheSuffix(author string) string {
+	if author == "" {
+		return ""
+	}
+	return ":author=" + strings.ToLower(author)
+}
 
+// repoPRs returns the PRs modified in a repository within the requested window, using the PR query cache.
+func (s *Server) repoPRs(ctx context.Context, req *RepoSampleRequest, token string) ([]github.PRSummary, error) {
 	// Try cache first
-	cacheKey := fmt.Sprintf("repo:%s/%s:days=%d", req.Owner, req.Repo, req.Days)
+	cacheKey := fmt.Sprintf("repo:%s/%s:days=%d%s%s", req.Owner, req.Repo, req.Days,
+		stateCacheSuffix(req.State), windowCacheSuffix(req.since, req.until))
 	prs, cached := s.cachedPRQuery(ctx, cacheKey)
 	if cached {
 		s.logger.InfoContext(ctx, "Using cached PR query results",
 			"owner", req.Owner, "repo", req.Repo, "total_prs", len(prs))
 	} else {
-		// Fetch all PRs modified since the date
+		// Fetch all PRs modified within the window
+		since, until := req.window()
 		var err error
-		prs, err = github.FetchPRsFromRepo(ctx, req.Owner, req.Repo, since, token, nil)
+		prs, err = github.FetchPRsFromRepo(ctx, req.Owner, req.Repo, github.PRQuery{
+			Since: since, Until: until, State: github.PRState(req.State), Token: token, API: &s.githubAPI,
+		})
 		if err != nil {
+			s.forgetTokenOnAuthError(ctx, token, err)
 			return nil, fmt.Errorf("failed to fetch PRs: %w", err)
 		}
 
@@ -1589,22 +1919,81 @@ func (s *Server) processRepoSample(ctx context.Context, req *RepoSampleRequest,
 		s.cachePRQuery(ctx, cacheKey, prs)
 	}
 
+	// Filter after caching so every other filter shares the query results
+	prs = github.FilterPRs(prs, req.filter())
+
+	if len(prs) == 0 {
+		return nil, fmt.Errorf("no PRs found %s", describeWindow(req.Days, req.since, req.until))
+	}
+	return prs, nil
+}
+
+// orgPRs returns the PRs modified across an organization within the requested window, using the PR query cache.
+func (s *Server) orgPRs(ctx context.Context, req *OrgSampleRequest, token string) ([]github.PRSummary, error) {
+	// Try cache first
+	cacheKey := fmt.Sprintf("org:%s:days=%d%s%s%s", req.Org, req.Days,
+		stateCacheSuffix(req.State), authorCacheSuffix(req.Author), windowCacheSuffix(req.since, req.until))
+	prs, cached := s.cachedPRQuery(ctx, cacheKey)
+	if cached {
+		s.logger.InfoContext(ctx, "Using cached PR query results",
+			"org", req.Org, "total_prs", len(prs))
+	} else {
+		// Fetch all PRs across the org modified within the window
+		since, until := req.window()
+		var err error
+		prs, err = github.FetchPRsFromOrg(ctx, req.Org, github.PRQuery{
+			Since: since, Until: until, State: github.PRState(req.State), Author: req.Author, Token: token, API: &s.githubAPI,
+		})
+		if err != nil {
+			s.forgetTokenOnAuthError(ctx, token, err)
+			return nil, fmt.Errorf("failed to fetch PRs: %w", err)
+		}
+
+		s.logger.InfoContext(ctx, "Fetched PRs from organization", "org", req.Org, "total_prs", len(prs))
+
+		// Cache query results
+		s.cachePRQuery(ctx, cacheKey, prs)
+	}
+
+	// Filter after caching so every other filter shares the query results
+	prs = github.FilterPRs(prs, req.filter())
+
 	if len(prs) == 0 {
-		return nil, fmt.Errorf("no PRs found in the last %d days", req.Days)
+		return nil, fmt.Errorf("no PRs found %s", describeWindow(req.Days, req.since, req.until))
+	}
+	return prs, nil
+}
+
+// processRepoSample processes a repository sampling request.
+func (s *Server) processRepoSample(ctx context.Context, req *RepoSampleRequest, token string) (*SampleResponse, error) {
+	// Record the GitHub API quota the scan leaves, for the response
+	rateLimits := github.NewRateLimitTracker()
+	ctx = github.WithRateLimitTracker(ctx, rateLimits)
+	var actualDays int
+	// Use default config if not provided
+	cfg := cost.DefaultConfig()
+	if req.Config != nil {
+		cfg = s.mergeConfig(cfg, req.Config)
+	}
+
+	prs, err := s.repoPRs(ctx, req, token)
+	if err != nil {
+		return nil, err
 	}
 
 	// Calculate actual time window (may be less than requested if we hit API limit)
-	actualDays, _ = github.CalculateActualTimeWindow(prs, req.Days)
+	actualDays, _ = github.CalculateActualTimeWindow(prs, req.Days, req.until)
 
 	// Sample PRs
-	samples := github.SamplePRs(prs, req.SampleSize)
+	samples := github.SamplingMode(req.Sampling).Sample(prs, req.SampleSize)
 	s.logger.InfoContext(ctx, "Sampled PRs", "sample_size", len(samples))
 
 	// Collect breakdowns from each sample and aggregate seconds_in_state
 	var breakdowns []cost.Breakdown
+	var urls []string // Aligned with breakdowns
 	aggregatedSeconds := make(map[string]int)
 	for i, pr := range samples {
-		prURL := fmt.Sprintf("https://github.com/%s/%s/pull/%d", req.Owner, req.Repo, pr.Number)
+		prURL := github.PRURL(ctx, req.Owner, req.Repo, pr.Number)
 		s.logger.InfoContext(ctx, "Processing sample PR",
 			"repo", fmt.Sprintf("%s/%s", req.Owner, req.Repo),
 			"number", pr.Number,
@@ -1617,18 +2006,10 @@ func (s *Server) processRepoSample(ctx context.Context, req *RepoSampleRequest,
 		if !prCached {
 			var err error
 			// Use configured data source with updatedAt for effective caching
-			if s.dataSource == "turnserver" {
-				var prDataWithAnalysis github.PRDataWithAnalysis
-				prDataWithAnalysis, err = github.FetchPRDataWithAnalysisViaTurnserver(ctx, prURL, token, pr.UpdatedAt)
-				if err == nil {
-					prData = prDataWithAnalysis.PRData
-					secondsInState = prDataWithAnalysis.Analysis.SecondsInState
-				}
-			} else {
-				prData, err = github.FetchPRData(ctx, prURL, token, pr.UpdatedAt)
-			}
+			prData, secondsInState, err = s.fetchPRData(ctx, prURL, token, pr.UpdatedAt)
 			if err != nil {
 				s.logger.WarnContext(ctx, "Failed to fetch PR data, skipping", "pr_number", pr.Number, "source", s.dataSource, errorKey, err)
+				s.forgetTokenOnAuthError(ctx, token, err)
 				continue
 			}
 
@@ -1641,8 +2022,11 @@ func (s *Server) processRepoSample(ctx context.Context, req *RepoSampleRequest,
 			aggregatedSeconds[state] += seconds
 		}
 
+		info := pr.Info()
+		info.Fill(&prData)
 		breakdown := cost.Calculate(prData, cfg)
 		breakdowns = append(breakdowns, breakdown)
+		urls = append(urls, prURL)
 	}
 
 	if len(breakdowns) == 0 {
@@ -1653,30 +2037,24 @@ func (s *Server) processRepoSample(ctx context.Context, req *RepoSampleRequest,
 	totalAuthors := github.CountUniqueAuthors(prs)
 
 	// Query for actual count of open PRs (not extrapolated from samples)
-	openPRCount, err := github.CountOpenPRsInRepo(ctx, req.Owner, req.Repo, token)
-	if err != nil {
-		s.logger.WarnContext(ctx, "Failed to count open PRs, using 0", errorKey, err)
-		openPRCount = 0
-	}
+	openPRCount := s.repoOpenPRCount(ctx, req, prs, token)
 
 	// Convert PRSummary to PRSummaryInfo for extrapolation
 	prSummaryInfos := make([]cost.PRSummaryInfo, len(prs))
 	for i, pr := range prs {
-		prSummaryInfos[i] = cost.PRSummaryInfo{
-			Owner:      pr.Owner,
-			Repo:       pr.Repo,
-			Author:     pr.Author,
-			AuthorType: pr.AuthorType,
-			CreatedAt:  pr.CreatedAt,
-			UpdatedAt:  pr.UpdatedAt,
-			ClosedAt:   pr.ClosedAt,
-			Merged:     pr.Merged,
-			State:      pr.State,
-		}
+		prSummaryInfos[i] = pr.Info()
 	}
 
 	// Extrapolate costs from samples
-	extrapolated := cost.ExtrapolateFromSamples(breakdowns, len(prs), totalAuthors, openPRCount, actualDays, cfg, prSummaryInfos, nil)
+	extrapolated := cost.ExtrapolateFromSamplesUntil(breakdowns, len(prs), totalAuthors, openPRCount, actualDays, cfg, prSummaryInfos, nil, req.until)
+	s.applyCallout(&extrapolated)
+	s.publishResult(ctx, &PublishedResult{Kind: "repo", Key: req.Owner + "/" + req.Repo, Extrapolated: &extrapolated})
+	if req.IncludeSamples {
+		extrapolated.Samples = cost.SampleBreakdowns(urls, breakdowns)
+	}
+	if req.Anonymize {
+		cost.NewAnonymizer(breakdowns).Extrapolated(&extrapolated)
+	}
 
 	// Only include seconds_in_state if we have data (turnserver only)
 	var secondsInState map[string]int
@@ -1685,15 +2063,20 @@ func (s *Server) processRepoSample(ctx context.Context, req *RepoSampleRequest,
 	}
 
 	return &SampleResponse{
+		SchemaVersion:  cost.SchemaVersion,
 		Extrapolated:   extrapolated,
 		Timestamp:      time.Now(),
 		Commit:         s.serverCommit,
 		SecondsInState: secondsInState,
+		RateLimit:      s.logRateLimit(ctx, rateLimits),
 	}, nil
 }
 
 // processOrgSample processes an organization sampling request.
 func (s *Server) processOrgSample(ctx context.Context, req *OrgSampleRequest, token string) (*SampleResponse, error) {
+	// Record the GitHub API quota the scan leaves, for the response
+	rateLimits := github.NewRateLimitTracker()
+	ctx = github.WithRateLimitTracker(ctx, rateLimits)
 	var actualDays int
 	// Use default config if not provided
 	cfg := cost.DefaultConfig()
@@ -1701,36 +2084,15 @@ func (s *Server) processOrgSample(ctx context.Context, req *OrgSampleRequest, to
 		cfg = s.mergeConfig(cfg, req.Config)
 	}
 
-	// Calculate since date
-	since := time.Now().AddDate(0, 0, -req.Days)
-
-	// Try cache first
-	cacheKey := fmt.Sprintf("org:%s:days=%d", req.Org, req.Days)
-	prs, cached := s.cachedPRQuery(ctx, cacheKey)
-	if cached {
-		s.logger.InfoContext(ctx, "Using cached PR query results",
-			"org", req.Org, "total_prs", len(prs))
-	} else {
-		// Fetch all PRs across the org modified since the date
-		var err error
-		prs, err = github.FetchPRsFromOrg(ctx, req.Org, since, token, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch PRs: %w", err)
-		}
-
-		s.logger.InfoContext(ctx, "Fetched PRs from organization", "org", req.Org, "total_prs", len(prs))
-
-		// Cache query results
-		s.cachePRQuery(ctx, cacheKey, prs)
-	}
-
-	if len(prs) == 0 {
-		return nil, fmt.Errorf("no PRs found in the last %d days", req.Days)
+	prs, err := s.orgPRs(ctx, req, token)
+	if err != nil {
+		return nil, err
 	}
 
 	// Fetch repository visibility for the organization (2x the time period for comprehensive coverage)
-	reposSince := time.Now().AddDate(0, 0, -req.Days*2)
-	repoVisibilityData, err := github.FetchOrgRepositoriesWithActivity(ctx, req.Org, reposSince, token)
+	since, _ := req.window()
+	reposSince := since.AddDate(0, 0, -req.Days)
+	repoVisibilityData, err := github.FetchOrgRepositoriesWithActivity(ctx, req.Org, github.PRQuery{Since: reposSince, Token: token, API: &s.githubAPI})
 	if err != nil {
 		s.logger.WarnContext(ctx, "Failed to fetch repository visibility, assuming all public", "error", err)
 		repoVisibilityData = nil
@@ -1746,17 +2108,18 @@ func (s *Server) processOrgSample(ctx context.Context, req *OrgSampleRequest, to
 	}
 
 	// Calculate actual time window (may be less than requested if we hit API limit)
-	actualDays, _ = github.CalculateActualTimeWindow(prs, req.Days)
+	actualDays, _ = github.CalculateActualTimeWindow(prs, req.Days, req.until)
 
 	// Sample PRs
-	samples := github.SamplePRs(prs, req.SampleSize)
+	samples := github.SamplingMode(req.Sampling).Sample(prs, req.SampleSize)
 	s.logger.InfoContext(ctx, "Sampled PRs", "sample_size", len(samples))
 
 	// Collect breakdowns from each sample and aggregate seconds_in_state
 	var breakdowns []cost.Breakdown
+	var urls []string // Aligned with breakdowns
 	aggregatedSeconds := make(map[string]int)
 	for i, pr := range samples {
-		prURL := fmt.Sprintf("https://github.com/%s/%s/pull/%d", pr.Owner, pr.Repo, pr.Number)
+		prURL := github.PRURL(ctx, pr.Owner, pr.Repo, pr.Number)
 		s.logger.InfoContext(ctx, "Processing sample PR",
 			"repo", fmt.Sprintf("%s/%s", pr.Owner, pr.Repo),
 			"number", pr.Number,
@@ -1769,18 +2132,10 @@ func (s *Server) processOrgSample(ctx context.Context, req *OrgSampleRequest, to
 		if !prCached {
 			var err error
 			// Use configured data source with updatedAt for effective caching
-			if s.dataSource == "turnserver" {
-				var prDataWithAnalysis github.PRDataWithAnalysis
-				prDataWithAnalysis, err = github.FetchPRDataWithAnalysisViaTurnserver(ctx, prURL, token, pr.UpdatedAt)
-				if err == nil {
-					prData = prDataWithAnalysis.PRData
-					secondsInState = prDataWithAnalysis.Analysis.SecondsInState
-				}
-			} else {
-				prData, err = github.FetchPRData(ctx, prURL, token, pr.UpdatedAt)
-			}
+			prData, secondsInState, err = s.fetchPRData(ctx, prURL, token, pr.UpdatedAt)
 			if err != nil {
 				s.logger.WarnContext(ctx, "Failed to fetch PR data, skipping", "pr_number", pr.Number, "source", s.dataSource, errorKey, err)
+				s.forgetTokenOnAuthError(ctx, token, err)
 				continue
 			}
 
@@ -1793,8 +2148,11 @@ func (s *Server) processOrgSample(ctx context.Context, req *OrgSampleRequest, to
 			aggregatedSeconds[state] += seconds
 		}
 
+		info := pr.Info()
+		info.Fill(&prData)
 		breakdown := cost.Calculate(prData, cfg)
 		breakdowns = append(breakdowns, breakdown)
+		urls = append(urls, prURL)
 	}
 
 	if len(breakdowns) == 0 {
@@ -1804,32 +2162,33 @@ func (s *Server) processOrgSample(ctx context.Context, req *OrgSampleRequest, to
 	// Count unique authors across all PRs (not just samples)
 	totalAuthors := github.CountUniqueAuthors(prs)
 
-	// Count open PRs across the entire organization with a single query
-	totalOpenPRs, err := github.CountOpenPRsInOrg(ctx, req.Org, token)
-	if err != nil {
-		s.logger.WarnContext(ctx, "Failed to count open PRs in organization, using 0", errorKey, err)
-		totalOpenPRs = 0
-	}
-	s.logger.InfoContext(ctx, "Counted total open PRs across organization", "org", req.Org, "open_prs", totalOpenPRs)
+	// Count open PRs across the entire organization, or only the filtered pool's
+	openCount := s.orgOpenPRCount(ctx, req, prs, token)
+	totalOpenPRs := openCount.Count
+	s.logger.InfoContext(ctx, "Counted total open PRs across organization",
+		"org", req.Org, "open_prs", totalOpenPRs, "failed_repos", openCount.FailedRepos, "partial", openCount.Partial)
 
 	// Convert PRSummary to PRSummaryInfo for extrapolation
 	prSummaryInfos := make([]cost.PRSummaryInfo, len(prs))
 	for i, pr := range prs {
-		prSummaryInfos[i] = cost.PRSummaryInfo{
-			Owner:      pr.Owner,
-			Repo:       pr.Repo,
-			Author:     pr.Author,
-			AuthorType: pr.AuthorType,
-			CreatedAt:  pr.CreatedAt,
-			UpdatedAt:  pr.UpdatedAt,
-			ClosedAt:   pr.ClosedAt,
-			Merged:     pr.Merged,
-			State:      pr.State,
-		}
+		prSummaryInfos[i] = pr.Info()
 	}
 
 	// Extrapolate costs from samples
-	extrapolated := cost.ExtrapolateFromSamples(breakdowns, len(prs), totalAuthors, totalOpenPRs, actualDays, cfg, prSummaryInfos, repoVisibility)
+	extrapolated := cost.ExtrapolateFromSamplesUntil(breakdowns, len(prs), totalAuthors, totalOpenPRs, actualDays, cfg, prSummaryInfos, repoVisibility, req.until)
+	extrapolated.OpenPRsFailedRepos = openCount.FailedRepos
+	extrapolated.OpenPRsPartial = openCount.Partial
+	s.applyCallout(&extrapolated)
+	if !req.historical {
+		s.publishResult(ctx, &PublishedResult{Kind: "org", Key: req.Org, Extrapolated: &extrapolated})
+		s.checkBudgetAlert(ctx, req, actualDays, &extrapolated)
+	}
+	if req.IncludeSamples {
+		extrapolated.Samples = cost.SampleBreakdowns(urls, breakdowns)
+	}
+	if req.Anonymize {
+		cost.NewAnonymizer(breakdowns).Extrapolated(&extrapolated)
+	}
 
 	// Only include seconds_in_state if we have data (turnserver only)
 	var secondsInState map[string]int
@@ -1838,21 +2197,59 @@ func (s *Server) processOrgSample(ctx context.Context, req *OrgSampleRequest, to
 	}
 
 	return &SampleResponse{
+		SchemaVersion:  cost.SchemaVersion,
 		Extrapolated:   extrapolated,
 		Timestamp:      time.Now(),
 		Commit:         s.serverCommit,
 		SecondsInState: secondsInState,
+		RateLimit:      s.logRateLimit(ctx, rateLimits),
 	}, nil
 }
 
+// logRateLimit logs the GitHub API quota a scan left and returns it for the response, or nil
+// if no GitHub API call reported one.
+func (s *Server) logRateLimit(ctx context.Context, rateLimits *github.RateLimitTracker) *github.RateLimit {
+	limit := rateLimits.Lowest()
+	if limit != nil {
+		s.logger.InfoContext(ctx, "GitHub API rate limit after scan",
+			"resource", limit.Resource, "remaining", limit.Remaining, "limit", limit.Limit, "reset", limit.Reset)
+	}
+	return limit
+}
+
+// validateConfigOverride rejects a request config whose COCOMO parameters would make a
+// nonsensical model. Zero parameters are unset and keep their defaults.
+func validateConfigOverride(override *ConfigOverride) error {
+	if override == nil || override.COCOMO == (cocomo.Config{}) {
+		return nil
+	}
+	c := cocomo.DefaultConfig()
+	if override.COCOMO.Multiplier != 0 {
+		c.Multiplier = override.COCOMO.Multiplier
+	}
+	if override.COCOMO.Exponent != 0 {
+		c.Exponent = override.COCOMO.Exponent
+	}
+	if override.COCOMO.MinimumEffort != 0 {
+		c.MinimumEffort = override.COCOMO.MinimumEffort
+	}
+	return c.Validate()
+}
+
 // mergeConfig merges a provided config with defaults.
-func (*Server) mergeConfig(base cost.Config, override *cost.Config) cost.Config {
+func (*Server) mergeConfig(base cost.Config, override *ConfigOverride) cost.Config {
 	if override == nil {
 		return base
 	}
 	if override.AnnualSalary > 0 {
 		base.AnnualSalary = override.AnnualSalary
 	}
+	if override.MaintainerSalary > 0 {
+		base.MaintainerSalary = override.MaintainerSalary
+	}
+	if override.ContributorSalary > 0 {
+		base.ContributorSalary = override.ContributorSalary
+	}
 	if override.BenefitsMultiplier > 0 {
 		base.BenefitsMultiplier = override.BenefitsMultiplier
 	}
@@ -1871,9 +2268,21 @@ func (*Server) mergeConfig(base cost.Config, override *cost.Config) cost.Config
 	if override.SessionGapThreshold > 0 {
 		base.SessionGapThreshold = override.SessionGapThreshold
 	}
+	if override.EventBurstWindow > 0 {
+		base.EventBurstWindow = override.EventBurstWindow
+	}
 	if override.DeliveryDelayFactor > 0 {
 		base.DeliveryDelayFactor = override.DeliveryDelayFactor
 	}
+	if override.PRTrackingMinutesPerDay > 0 {
+		base.PRTrackingMinutesPerDay = override.PRTrackingMinutesPerDay
+	}
+	if override.MinDelayThresholdMinutes > 0 {
+		base.MinDelayThresholdMinutes = override.MinDelayThresholdMinutes
+	}
+	if override.FiscalYearStartMonth > 0 && override.FiscalYearStartMonth <= 12 {
+		base.FiscalYearStartMonth = override.FiscalYearStartMonth
+	}
 	if override.MaxDelayAfterLastEvent > 0 {
 		base.MaxDelayAfterLastEvent = override.MaxDelayAfterLastEvent
 	}
@@ -1883,43 +2292,116 @@ func (*Server) mergeConfig(base cost.Config, override *cost.Config) cost.Config
 	if override.MaxCodeDrift > 0 {
 		base.MaxCodeDrift = override.MaxCodeDrift
 	}
+	if override.MaxPRCodeCost > 0 {
+		base.MaxPRCodeCost = override.MaxPRCodeCost
+	}
 	if override.ReviewInspectionRate > 0 {
 		base.ReviewInspectionRate = override.ReviewInspectionRate
 	}
+	if override.ConflictResolutionMinutes > 0 {
+		base.ConflictResolutionMinutes = override.ConflictResolutionMinutes
+	}
 	if override.ModificationCostFactor > 0 {
 		base.ModificationCostFactor = override.ModificationCostFactor
 	}
+	if override.ReviewerDecayFactor > 0 {
+		base.ReviewerDecayFactor = override.ReviewerDecayFactor
+	}
+	if override.ReReviewFactor > 0 {
+		base.ReReviewFactor = override.ReReviewFactor
+	}
+	if override.ReviewWaitFactor > 0 || override.isSet("ReviewWaitFactor") && override.ReviewWaitFactor == 0 {
+		base.ReviewWaitFactor = override.ReviewWaitFactor
+	}
+	if override.MinReviewMinutes > 0 {
+		base.MinReviewMinutes = override.MinReviewMinutes
+	}
+	if override.DeliveryDelayCapacityFraction > 0 || override.isSet("DeliveryDelayCapacityFraction") && override.DeliveryDelayCapacityFraction == 0 {
+		base.DeliveryDelayCapacityFraction = override.DeliveryDelayCapacityFraction
+	}
+	if override.ZombieMinAge > 0 {
+		base.ZombieMinAge = override.ZombieMinAge
+	}
+	if override.ZombieStaleAfter > 0 {
+		base.ZombieStaleAfter = override.ZombieStaleAfter
+	}
+	if override.COCOMO.Multiplier > 0 {
+		base.COCOMO.Multiplier = override.COCOMO.Multiplier
+	}
+	if override.COCOMO.Exponent > 0 {
+		base.COCOMO.Exponent = override.COCOMO.Exponent
+	}
+	if override.COCOMO.MinimumEffort > 0 {
+		base.COCOMO.MinimumEffort = override.COCOMO.MinimumEffort
+	}
+	if override.ReviewEventsHaveDuration {
+		base.ReviewEventsHaveDuration = true
+	}
+	if override.RequireWaitingEvidence {
+		base.RequireWaitingEvidence = true
+	}
+	if override.CountDraftTime {
+		base.CountDraftTime = true
+	}
+	if override.isSet("ExcludeGeneratedFromCost") {
+		base.ExcludeGeneratedFromCost = override.ExcludeGeneratedFromCost
+	}
+	if override.isSet("IncludeFutureCosts") {
+		base.IncludeFutureCosts = override.IncludeFutureCosts
+	}
+	if override.SessionModel != "" && cost.ValidSessionModel(override.SessionModel) {
+		base.SessionModel = override.SessionModel
+	}
+	if override.GradeVelocityByMedian {
+		base.GradeVelocityByMedian = true
+	}
+	if override.MaxWaitingMultiplier > 0 {
+		base.MaxWaitingMultiplier = override.MaxWaitingMultiplier
+	}
+	if override.WorkingCalendar != nil {
+		calendar := *override.WorkingCalendar
+		calendar.Days = slices.Clone(calendar.Days)
+		base.WorkingCalendar = &calendar
+	}
+	if len(override.ReviewInspectionRates) > 0 {
+		// Per-category rates; non-positive entries are ignored.
+		rates := make(map[string]float64, len(override.ReviewInspectionRates))
+		for category, rate := range override.ReviewInspectionRates {
+			if rate > 0 {
+				rates[strings.ToLower(category)] = rate
+			}
+		}
+		if len(rates) > 0 {
+			base.ReviewInspectionRates = rates
+		}
+	}
+	if len(override.IgnoredEventKinds) > 0 {
+		base.IgnoredEventKinds = slices.Clone(override.IgnoredEventKinds)
+	}
+	if len(override.SalaryOverrides) > 0 {
+		// Per-author salaries, in the reporting currency; non-positive entries are ignored.
+		// Copy so the base config's map is never modified.
+		salaries := maps.Clone(base.SalaryOverrides)
+		if salaries == nil {
+			salaries = make(map[string]float64, len(override.SalaryOverrides))
+		}
+		for login, salary := range override.SalaryOverrides {
+			if salary > 0 {
+				salaries[strings.ToLower(login)] = salary
+			}
+		}
+		base.SalaryOverrides = salaries
+	}
 	return base
 }
 
-// handleRepoSampleStream processes repository sampling requests with Server-Sent Events for progress updates.
+// handleRepoSampleStream processes repository sampling requests, streaming progress updates in enc's format.
 //
 //nolint:dupl // Similar to handleOrgSampleStream but with different request types
-func (s *Server) handleRepoSampleStream(writer http.ResponseWriter, request *http.Request) {
+func (s *Server) handleRepoSampleStream(writer http.ResponseWriter, request *http.Request, enc progressEncoder) {
 	ctx := request.Context()
 
-	// Extract client IP for rate limiting and logging.
-	// SECURITY: X-Forwarded-For is trusted because Cloud Run (GCP) sanitizes it.
-	// Cloud Run strips client-provided XFF headers and replaces with actual client IP.
-	// For non-Cloud Run deployments, consider validating source or using RemoteAddr only.
-	clientIP := request.RemoteAddr
-	if xff := request.Header.Get("X-Forwarded-For"); xff != "" {
-		if idx := strings.Index(xff, ","); idx > 0 {
-			clientIP = strings.TrimSpace(xff[:idx])
-		} else {
-			clientIP = strings.TrimSpace(xff)
-		}
-	} else if host, _, err := net.SplitHostPort(request.RemoteAddr); err == nil {
-		clientIP = host
-	}
-
-	s.logger.InfoContext(ctx, "[handleRepoSampleStream] Incoming request", "client_ip", clientIP)
-
-	// Per-IP rate limiting.
-	limiter := s.limiter(ctx, clientIP)
-	if !limiter.Allow() {
-		s.logger.WarnContext(ctx, "[handleRepoSampleStream] Rate limit exceeded", "client_ip", clientIP)
-		http.Error(writer, "Rate limit exceeded", http.StatusTooManyRequests)
+	if !s.allowRequest(writer, request, "handleRepoSampleStream") {
 		return
 	}
 
@@ -1932,70 +2414,25 @@ func (s *Server) handleRepoSampleStream(writer http.ResponseWriter, request *htt
 		return
 	}
 
-	// Get auth token - try Authorization header first, then fallback.
-	token := s.extractToken(request)
-	if token == "" {
-		token = s.token(ctx)
-		if token == "" {
-			s.logger.WarnContext(ctx, "[handleRepoSampleStream] No GitHub token available", "remote_addr", request.RemoteAddr)
-			http.Error(writer, "GitHub token required (set GITHUB_TOKEN env var or provide Authorization header)", http.StatusUnauthorized)
-			return
-		}
-	}
-
-	// Validate token if configured.
-	if s.validateTokens {
-		if err := s.validateGitHubToken(ctx, token); err != nil {
-			//nolint:revive // line-length: acceptable for logging
-			s.logger.WarnContext(ctx, "[handleRepoSampleStream] Token validation failed", "remote_addr", request.RemoteAddr, errorKey, sanitizeError(err))
-			http.Error(writer, "Invalid or expired token", http.StatusUnauthorized)
-			return
-		}
+	token, ok := s.requestToken(writer, request, "handleRepoSampleStream", req.Owner, req.Repo)
+	if !ok {
+		return
 	}
 
-	// Set up SSE headers.
-	writer.Header().Set("Content-Type", "text/event-stream")
-	writer.Header().Set("Cache-Control", "no-cache")
-	writer.Header().Set("Connection", "keep-alive")
-
-	// Flush headers immediately to establish SSE connection before processing starts.
-	// This prevents the browser from closing the connection while waiting for the first event.
-	if flusher, ok := writer.(http.Flusher); ok {
-		flusher.Flush()
-	}
+	stream := progressWriter{ResponseWriter: writer, enc: enc}
+	startStream(stream)
 
 	// Process request with progress updates.
-	s.processRepoSampleWithProgress(ctx, req, token, writer)
+	s.processRepoSampleWithProgress(ctx, req, token, stream)
 }
 
-// handleOrgSampleStream processes organization sampling requests with Server-Sent Events for progress updates.
+// handleOrgSampleStream processes organization sampling requests, streaming progress updates in enc's format.
 //
 //nolint:dupl // Similar to handleRepoSampleStream but with different request types
-func (s *Server) handleOrgSampleStream(writer http.ResponseWriter, request *http.Request) {
+func (s *Server) handleOrgSampleStream(writer http.ResponseWriter, request *http.Request, enc progressEncoder) {
 	ctx := request.Context()
 
-	// Extract client IP for rate limiting and logging.
-	// SECURITY: X-Forwarded-For is trusted because Cloud Run (GCP) sanitizes it.
-	// Cloud Run strips client-provided XFF headers and replaces with actual client IP.
-	// For non-Cloud Run deployments, consider validating source or using RemoteAddr only.
-	clientIP := request.RemoteAddr
-	if xff := request.Header.Get("X-Forwarded-For"); xff != "" {
-		if idx := strings.Index(xff, ","); idx > 0 {
-			clientIP = strings.TrimSpace(xff[:idx])
-		} else {
-			clientIP = strings.TrimSpace(xff)
-		}
-	} else if host, _, err := net.SplitHostPort(request.RemoteAddr); err == nil {
-		clientIP = host
-	}
-
-	s.logger.InfoContext(ctx, "[handleOrgSampleStream] Incoming request", "client_ip", clientIP)
-
-	// Per-IP rate limiting.
-	limiter := s.limiter(ctx, clientIP)
-	if !limiter.Allow() {
-		s.logger.WarnContext(ctx, "[handleOrgSampleStream] Rate limit exceeded", "client_ip", clientIP)
-		http.Error(writer, "Rate limit exceeded", http.StatusTooManyRequests)
+	if !s.allowRequest(writer, request, "handleOrgSampleStream") {
 		return
 	}
 
@@ -2007,66 +2444,90 @@ func (s *Server) handleOrgSampleStream(writer http.ResponseWriter, request *http
 		return
 	}
 
-	// Get auth token - try Authorization header first, then fallback.
-	token := s.extractToken(request)
-	if token == "" {
-		token = s.token(ctx)
-		if token == "" {
-			s.logger.WarnContext(ctx, "[handleOrgSampleStream] No GitHub token available", "remote_addr", request.RemoteAddr)
-			http.Error(writer, "GitHub token required (set GITHUB_TOKEN env var or provide Authorization header)", http.StatusUnauthorized)
-			return
-		}
+	token, ok := s.requestToken(writer, request, "handleOrgSampleStream", req.Org, "")
+	if !ok {
+		return
 	}
 
-	// Validate token if configured.
-	if s.validateTokens {
-		if err := s.validateGitHubToken(ctx, token); err != nil {
-			//nolint:revive // line-length: acceptable for logging
-			s.logger.WarnContext(ctx, "[handleOrgSampleStream] Token validation failed", "remote_addr", request.RemoteAddr, errorKey, sanitizeError(err))
-			http.Error(writer, "Invalid or expired token", http.StatusUnauthorized)
-			return
-		}
-	}
+	stream := progressWriter{ResponseWriter: writer, enc: enc}
+	startStream(stream)
 
-	// Set up SSE headers.
-	writer.Header().Set("Content-Type", "text/event-stream")
-	writer.Header().Set("Cache-Control", "no-cache")
-	writer.Header().Set("Connection", "keep-alive")
+	// Process request with progress updates.
+	s.processOrgSampleWithProgress(ctx, req, token, stream)
+}
 
-	// Flush headers immediately to establish SSE connection before processing starts.
-	// This prevents the browser from closing the connection while waiting for the first event.
-	if flusher, ok := writer.(http.Flusher); ok {
+// progressEncoder frames progress updates in a stream's wire format.
+type progressEncoder interface {
+	// contentType returns the stream's Content-Type.
+	contentType() string
+	// frame wraps one JSON-encoded update for the stream.
+	frame(data []byte) []byte
+	// keepAlive returns what to write to an idle stream to keep the connection open; clients skip it.
+	keepAlive() string
+}
+
+// sseEncoder streams progress updates as Server-Sent Events.
+type sseEncoder struct{}
+
+func (sseEncoder) contentType() string { return "text/event-stream" }
+
+// SSE format: "data: <json>\n\n"
+func (sseEncoder) frame(data []byte) []byte { return fmt.Appendf(nil, "data: %s\n\n", data) }
+
+func (sseEncoder) keepAlive() string { return ": keepalive\n\n" }
+
+// ndjsonEncoder streams progress updates as newline-delimited JSON, for clients such as
+// `curl | jq` that don't speak SSE.
+type ndjsonEncoder struct{}
+
+func (ndjsonEncoder) contentType() string { return "application/x-ndjson" }
+
+func (ndjsonEncoder) frame(data []byte) []byte { return append(data, '\n') }
+
+// JSON parsers skip whitespace between values
+func (ndjsonEncoder) keepAlive() string { return "\n" }
+
+// progressWriter is a response streaming progress updates in enc's format.
+type progressWriter struct {
+	http.ResponseWriter
+	enc progressEncoder
+}
+
+// flush sends everything written so far to the client, if the response supports flushing.
+func (w progressWriter) flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
 		flusher.Flush()
 	}
+}
 
-	// Process request with progress updates.
-	s.processOrgSampleWithProgress(ctx, req, token, writer)
+// startStream sets the headers for a progress stream and flushes them.
+func startStream(w progressWriter) {
+	w.Header().Set("Content-Type", w.enc.contentType())
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	// Flush headers immediately to establish the connection before processing starts.
+	// This prevents the browser from closing the connection while waiting for the first event.
+	w.flush()
 }
 
-// sendSSE sends a Server-Sent Event to the client.
-func sendSSE(w http.ResponseWriter, update ProgressUpdate) error {
+// sendSSE sends a progress update to the client, flushing it immediately.
+func sendSSE(w progressWriter, update ProgressUpdate) error {
 	data, err := json.Marshal(update)
 	if err != nil {
 		return fmt.Errorf("failed to marshal progress update: %w", err)
 	}
-
-	// SSE format: "data: <json>\n\n"
-	if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
-		return fmt.Errorf("failed to write SSE: %w", err)
-	}
-
-	// Flush immediately to ensure client receives the update.
-	if flusher, ok := w.(http.Flusher); ok {
-		flusher.Flush()
+	if _, err := w.Write(w.enc.frame(data)); err != nil {
+		return fmt.Errorf("failed to write progress update: %w", err)
 	}
-
+	w.flush()
 	return nil
 }
 
-// startKeepAlive starts a goroutine that sends SSE keep-alive comments every 2 seconds.
+// startKeepAlive starts a goroutine that writes the stream's keep-alive every 2 seconds.
 // This prevents client-side timeouts during long operations.
 // Returns a stop channel (to stop keep-alive) and an error channel (signals connection failure).
-func startKeepAlive(w http.ResponseWriter) (stop chan struct{}, connErr <-chan error) {
+func startKeepAlive(w progressWriter) (stop chan struct{}, connErr <-chan error) {
 	stopChan := make(chan struct{})
 	errChan := make(chan error, 1)
 	go func() {
@@ -2076,14 +2537,12 @@ func startKeepAlive(w http.ResponseWriter) (stop chan struct{}, connErr <-chan e
 		for {
 			select {
 			case <-ticker.C:
-				// Send SSE comment (keeps connection alive, ignored by client)
-				if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				// Keeps the connection alive; ignored by the client
+				if _, err := fmt.Fprint(w, w.enc.keepAlive()); err != nil {
 					errChan <- fmt.Errorf("keepalive write failed: %w", err)
 					return
 				}
-				if flusher, ok := w.(http.Flusher); ok {
-					flusher.Flush()
-				}
+				w.flush()
 			case <-stopChan:
 				return
 			}
@@ -2101,11 +2560,14 @@ func logSSEError(ctx context.Context, logger *slog.Logger, err error) {
 }
 
 // processRepoSampleWithProgress processes a repository sample with progress updates via SSE.
-func (s *Server) processRepoSampleWithProgress(ctx context.Context, req *RepoSampleRequest, token string, writer http.ResponseWriter) {
+func (s *Server) processRepoSampleWithProgress(ctx context.Context, req *RepoSampleRequest, token string, writer progressWriter) {
 	var actualDays int
 	// Use background context for work to prevent client timeout from canceling operations
 	// The request context (ctx) is only used for SSE writes and logging
-	workCtx := context.Background()
+	workCtx := s.withGitHubHost(context.Background())
+	// Record the GitHub API quota the scan leaves, for the final message
+	rateLimits := github.NewRateLimitTracker()
+	workCtx = github.WithRateLimitTracker(workCtx, rateLimits)
 
 	defer func() {
 		s.logger.InfoContext(ctx, "[processRepoSampleWithProgress] Stream handler completed",
@@ -2128,11 +2590,12 @@ func (s *Server) processRepoSampleWithProgress(ctx context.Context, req *RepoSam
 		cfg = s.mergeConfig(cfg, req.Config)
 	}
 
-	// Calculate since date
-	since := time.Now().AddDate(0, 0, -req.Days)
+	// Calculate the window to fetch PRs within
+	since, until := req.window()
 
 	// Try cache first
-	cacheKey := fmt.Sprintf("repo:%s/%s:days=%d", req.Owner, req.Repo, req.Days)
+	cacheKey := fmt.Sprintf("repo:%s/%s:days=%d%s%s", req.Owner, req.Repo, req.Days,
+		stateCacheSuffix(req.State), windowCacheSuffix(req.since, req.until))
 	prs, cached := s.cachedPRQuery(ctx, cacheKey)
 	if !cached {
 		// Send progress update before GraphQL query
@@ -2141,7 +2604,7 @@ func (s *Server) processRepoSampleWithProgress(ctx context.Context, req *RepoSam
 			PR:       0,
 			Owner:    req.Owner,
 			Repo:     req.Repo,
-			Progress: fmt.Sprintf("Querying GitHub GraphQL API for %s/%s PRs (last %d days)...", req.Owner, req.Repo, req.Days),
+			Progress: fmt.Sprintf("Querying GitHub GraphQL API for %s/%s PRs modified %s...", req.Owner, req.Repo, describeWindow(req.Days, req.since, req.until)),
 		}))
 
 		// Start keep-alive to prevent client timeout during GraphQL query
@@ -2167,8 +2630,11 @@ func (s *Server) processRepoSampleWithProgress(ctx context.Context, req *RepoSam
 			}))
 		}
 		//nolint:contextcheck // Using background context intentionally to prevent client timeout from canceling work
-		prs, err = github.FetchPRsFromRepo(workCtx, req.Owner, req.Repo, since, token, progressCallback)
+		prs, err = github.FetchPRsFromRepo(workCtx, req.Owner, req.Repo, github.PRQuery{
+			Since: since, Until: until, State: github.PRState(req.State), Token: token, API: &s.githubAPI, Progress: progressCallback,
+		})
 		if err != nil {
+			s.forgetTokenOnAuthError(ctx, token, err)
 			logSSEError(ctx, s.logger, sendSSE(writer, ProgressUpdate{
 				Type:  "error",
 				Error: fmt.Sprintf("Failed to fetch PRs: %v", err),
@@ -2180,19 +2646,21 @@ func (s *Server) processRepoSampleWithProgress(ctx context.Context, req *RepoSam
 		s.cachePRQuery(ctx, cacheKey, prs)
 	}
 
+	prs = github.FilterPRs(prs, req.filter())
+
 	if len(prs) == 0 {
 		logSSEError(ctx, s.logger, sendSSE(writer, ProgressUpdate{
 			Type:  "error",
-			Error: fmt.Sprintf("No PRs found in the last %d days", req.Days),
+			Error: "No PRs found " + describeWindow(req.Days, req.since, req.until),
 		}))
 		return
 	}
 
 	// Calculate actual time window (may be less than requested if we hit API limit)
-	actualDays, _ = github.CalculateActualTimeWindow(prs, req.Days)
+	actualDays, _ = github.CalculateActualTimeWindow(prs, req.Days, req.until)
 
 	// Sample PRs
-	samples := github.SamplePRs(prs, req.SampleSize)
+	samples := github.SamplingMode(req.Sampling).Sample(prs, req.SampleSize)
 
 	// Send progress update before processing samples
 	logSSEError(ctx, s.logger, sendSSE(writer, ProgressUpdate{
@@ -2204,7 +2672,7 @@ func (s *Server) processRepoSampleWithProgress(ctx context.Context, req *RepoSam
 	}))
 
 	// Process samples in parallel with progress updates
-	breakdowns, aggregatedSeconds := s.processPRsInParallel(workCtx, ctx, samples, req.Owner, req.Repo, token, cfg, writer)
+	breakdowns, urls, aggregatedSeconds := s.processPRsInParallel(workCtx, ctx, samples, req.Owner, req.Repo, token, cfg, writer)
 
 	if len(breakdowns) == 0 {
 		logSSEError(ctx, s.logger, sendSSE(writer, ProgressUpdate{
@@ -2219,30 +2687,24 @@ func (s *Server) processRepoSampleWithProgress(ctx context.Context, req *RepoSam
 
 	// Query for actual count of open PRs (not extrapolated from samples)
 	//nolint:contextcheck // Using background context intentionally to prevent client timeout from canceling work
-	openPRCount, err := github.CountOpenPRsInRepo(workCtx, req.Owner, req.Repo, token)
-	if err != nil {
-		s.logger.WarnContext(ctx, "Failed to count open PRs, using 0", errorKey, err)
-		openPRCount = 0
-	}
+	openPRCount := s.repoOpenPRCount(workCtx, req, prs, token)
 
 	// Convert PRSummary to PRSummaryInfo for extrapolation
 	prSummaryInfos := make([]cost.PRSummaryInfo, len(prs))
 	for i, pr := range prs {
-		prSummaryInfos[i] = cost.PRSummaryInfo{
-			Owner:      pr.Owner,
-			Repo:       pr.Repo,
-			Author:     pr.Author,
-			AuthorType: pr.AuthorType,
-			CreatedAt:  pr.CreatedAt,
-			UpdatedAt:  pr.UpdatedAt,
-			ClosedAt:   pr.ClosedAt,
-			Merged:     pr.Merged,
-			State:      pr.State,
-		}
+		prSummaryInfos[i] = pr.Info()
 	}
 
 	// Extrapolate costs from samples
-	extrapolated := cost.ExtrapolateFromSamples(breakdowns, len(prs), totalAuthors, openPRCount, actualDays, cfg, prSummaryInfos, nil)
+	extrapolated := cost.ExtrapolateFromSamplesUntil(breakdowns, len(prs), totalAuthors, openPRCount, actualDays, cfg, prSummaryInfos, nil, req.until)
+	s.applyCallout(&extrapolated)
+	s.publishResult(ctx, &PublishedResult{Kind: "repo", Key: req.Owner + "/" + req.Repo, Extrapolated: &extrapolated})
+	if req.IncludeSamples {
+		extrapolated.Samples = cost.SampleBreakdowns(urls, breakdowns)
+	}
+	if req.Anonymize {
+		cost.NewAnonymizer(breakdowns).Extrapolated(&extrapolated)
+	}
 
 	// Only include seconds_in_state if we have data (turnserver only)
 	var secondsInState map[string]int
@@ -2255,17 +2717,22 @@ func (s *Server) processRepoSampleWithProgress(ctx context.Context, req *RepoSam
 		Type:           "done",
 		Result:         &extrapolated,
 		Commit:         s.serverCommit,
+		SchemaVersion:  cost.SchemaVersion,
 		R2RCallout:     s.r2rCallout,
 		SecondsInState: secondsInState,
+		RateLimit:      s.logRateLimit(ctx, rateLimits),
 	}))
 }
 
 // processOrgSampleWithProgress processes an organization sample with progress updates via SSE.
-func (s *Server) processOrgSampleWithProgress(ctx context.Context, req *OrgSampleRequest, token string, writer http.ResponseWriter) {
+func (s *Server) processOrgSampleWithProgress(ctx context.Context, req *OrgSampleRequest, token string, writer progressWriter) {
 	var actualDays int
 	// Use background context for work to prevent client timeout from canceling operations
 	// The request context (ctx) is only used for SSE writes and logging
-	workCtx := context.Background()
+	workCtx := s.withGitHubHost(context.Background())
+	// Record the GitHub API quota the scan leaves, for the final message
+	rateLimits := github.NewRateLimitTracker()
+	workCtx = github.WithRateLimitTracker(workCtx, rateLimits)
 
 	defer func() {
 		s.logger.InfoContext(ctx, "[processOrgSampleWithProgress] Stream handler completed",
@@ -2287,18 +2754,19 @@ func (s *Server) processOrgSampleWithProgress(ctx context.Context, req *OrgSampl
 		cfg = s.mergeConfig(cfg, req.Config)
 	}
 
-	// Calculate since date
-	since := time.Now().AddDate(0, 0, -req.Days)
+	// Calculate the window to fetch PRs within
+	since, until := req.window()
 
 	// Try cache first
-	cacheKey := fmt.Sprintf("org:%s:days=%d", req.Org, req.Days)
+	cacheKey := fmt.Sprintf("org:%s:days=%d%s%s%s", req.Org, req.Days,
+		stateCacheSuffix(req.State), authorCacheSuffix(req.Author), windowCacheSuffix(req.since, req.until))
 	prs, cached := s.cachedPRQuery(ctx, cacheKey)
 	if !cached {
 		// Send progress update before GraphQL query
 		logSSEError(ctx, s.logger, sendSSE(writer, ProgressUpdate{
 			Type:     "fetching",
 			PR:       0,
-			Progress: fmt.Sprintf("Querying GitHub Search API for %s org PRs (last %d days)...", req.Org, req.Days),
+			Progress: fmt.Sprintf("Querying GitHub Search API for %s org PRs modified %s...", req.Org, describeWindow(req.Days, req.since, req.until)),
 		}))
 
 		// Start keep-alive to prevent client timeout during GraphQL query
@@ -2324,8 +2792,12 @@ func (s *Server) processOrgSampleWithProgress(ctx context.Context, req *OrgSampl
 			}))
 		}
 		//nolint:contextcheck // Using background context intentionally to prevent client timeout from canceling work
-		prs, err = github.FetchPRsFromOrg(workCtx, req.Org, since, token, progressCallback)
+		prs, err = github.FetchPRsFromOrg(workCtx, req.Org, github.PRQuery{
+			Since: since, Until: until, State: github.PRState(req.State), Author: req.Author, Token: token, API: &s.githubAPI,
+			Progress: progressCallback,
+		})
 		if err != nil {
+			s.forgetTokenOnAuthError(ctx, token, err)
 			logSSEError(ctx, s.logger, sendSSE(writer, ProgressUpdate{
 				Type:  "error",
 				Error: fmt.Sprintf("Failed to fetch PRs: %v", err),
@@ -2337,19 +2809,21 @@ func (s *Server) processOrgSampleWithProgress(ctx context.Context, req *OrgSampl
 		s.cachePRQuery(ctx, cacheKey, prs)
 	}
 
+	prs = github.FilterPRs(prs, req.filter())
+
 	if len(prs) == 0 {
 		logSSEError(ctx, s.logger, sendSSE(writer, ProgressUpdate{
 			Type:  "error",
-			Error: fmt.Sprintf("No PRs found in the last %d days", req.Days),
+			Error: "No PRs found " + describeWindow(req.Days, req.since, req.until),
 		}))
 		return
 	}
 
 	// Calculate actual time window (may be less than requested if we hit API limit)
-	actualDays, _ = github.CalculateActualTimeWindow(prs, req.Days)
+	actualDays, _ = github.CalculateActualTimeWindow(prs, req.Days, req.until)
 
 	// Sample PRs
-	samples := github.SamplePRs(prs, req.SampleSize)
+	samples := github.SamplingMode(req.Sampling).Sample(prs, req.SampleSize)
 
 	s.logger.InfoContext(ctx, "[processOrgSampleWithProgress] Starting to process sampled PRs",
 		"org", req.Org,
@@ -2364,7 +2838,7 @@ func (s *Server) processOrgSampleWithProgress(ctx context.Context, req *OrgSampl
 	}))
 
 	// Process samples in parallel with progress updates (org mode uses empty owner/repo since it's mixed)
-	breakdowns, aggregatedSeconds := s.processPRsInParallel(workCtx, ctx, samples, "", "", token, cfg, writer)
+	breakdowns, urls, aggregatedSeconds := s.processPRsInParallel(workCtx, ctx, samples, "", "", token, cfg, writer)
 
 	s.logger.InfoContext(ctx, "[processOrgSampleWithProgress] Finished processing samples",
 		"org", req.Org,
@@ -2382,33 +2856,32 @@ func (s *Server) processOrgSampleWithProgress(ctx context.Context, req *OrgSampl
 	// Count unique authors across all PRs (not just samples)
 	totalAuthors := github.CountUniqueAuthors(prs)
 
-	// Count open PRs across the entire organization with a single GraphQL query
+	// Count open PRs across the entire organization, or only the filtered pool's
 	//nolint:contextcheck // Using background context intentionally to prevent client timeout from canceling work
-	totalOpenPRs, err := github.CountOpenPRsInOrg(workCtx, req.Org, token)
-	if err != nil {
-		s.logger.WarnContext(ctx, "Failed to count open PRs for organization", "org", req.Org, errorKey, err)
-		totalOpenPRs = 0 // Continue with 0 if we can't get the count
-	}
-	s.logger.InfoContext(ctx, "Counted total open PRs across organization", "open_prs", totalOpenPRs, "org", req.Org)
+	openCount := s.orgOpenPRCount(workCtx, req, prs, token)
+	totalOpenPRs := openCount.Count
+	s.logger.InfoContext(ctx, "Counted total open PRs across organization",
+		"open_prs", totalOpenPRs, "failed_repos", openCount.FailedRepos, "partial", openCount.Partial, "org", req.Org)
 
 	// Convert PRSummary to PRSummaryInfo for extrapolation
 	prSummaryInfos := make([]cost.PRSummaryInfo, len(prs))
 	for i, pr := range prs {
-		prSummaryInfos[i] = cost.PRSummaryInfo{
-			Owner:      pr.Owner,
-			Repo:       pr.Repo,
-			Author:     pr.Author,
-			AuthorType: pr.AuthorType,
-			CreatedAt:  pr.CreatedAt,
-			UpdatedAt:  pr.UpdatedAt,
-			ClosedAt:   pr.ClosedAt,
-			Merged:     pr.Merged,
-			State:      pr.State,
-		}
+		prSummaryInfos[i] = pr.Info()
 	}
 
 	// Extrapolate costs from samples
-	extrapolated := cost.ExtrapolateFromSamples(breakdowns, len(prs), totalAuthors, totalOpenPRs, actualDays, cfg, prSummaryInfos, nil)
+	extrapolated := cost.ExtrapolateFromSamplesUntil(breakdowns, len(prs), totalAuthors, totalOpenPRs, actualDays, cfg, prSummaryInfos, nil, req.until)
+	extrapolated.OpenPRsFailedRepos = openCount.FailedRepos
+	extrapolated.OpenPRsPartial = openCount.Partial
+	s.applyCallout(&extrapolated)
+	s.publishResult(ctx, &PublishedResult{Kind: "org", Key: req.Org, Extrapolated: &extrapolated})
+	s.checkBudgetAlert(ctx, req, actualDays, &extrapolated)
+	if req.IncludeSamples {
+		extrapolated.Samples = cost.SampleBreakdowns(urls, breakdowns)
+	}
+	if req.Anonymize {
+		cost.NewAnonymizer(breakdowns).Extrapolated(&extrapolated)
+	}
 
 	// Only include seconds_in_state if we have data (turnserver only)
 	var secondsInState map[string]int
@@ -2421,22 +2894,25 @@ func (s *Server) processOrgSampleWithProgress(ctx context.Context, req *OrgSampl
 		Type:           "done",
 		Result:         &extrapolated,
 		Commit:         s.serverCommit,
+		SchemaVersion:  cost.SchemaVersion,
 		R2RCallout:     s.r2rCallout,
 		SecondsInState: secondsInState,
+		RateLimit:      s.logRateLimit(ctx, rateLimits),
 	}))
 }
 
 // processPRsInParallel processes PRs in parallel and sends progress updates via SSE.
+// It returns the breakdowns with their PR URLs (aligned by index) in completion order.
 //
 //nolint:revive // line-length/use-waitgroup-go: long function signature acceptable, standard wg pattern
-func (s *Server) processPRsInParallel(workCtx, reqCtx context.Context, samples []github.PRSummary, defaultOwner, defaultRepo, token string, cfg cost.Config, writer http.ResponseWriter) (breakdowns []cost.Breakdown, aggregatedSeconds map[string]int) {
+func (s *Server) processPRsInParallel(workCtx, reqCtx context.Context, samples []github.PRSummary, defaultOwner, defaultRepo, token string, cfg cost.Config, writer progressWriter) (breakdowns []cost.Breakdown, urls []string, aggregatedSeconds map[string]int) {
 	aggregatedSeconds = make(map[string]int)
 	var mu sync.Mutex
 	var sseMu sync.Mutex // Protects SSE writes to prevent corrupted chunked encoding
 
-	// Use a buffered channel for worker pool pattern
-	concurrency := 8 // Process up to 8 PRs concurrently
-	semaphore := make(chan struct{}, concurrency)
+	// Use a buffered channel for worker pool pattern. This caps a single request (see SetConcurrency);
+	// fetchPRData also enforces the server-wide limit across requests (see SetMaxConcurrentFetches).
+	semaphore := make(chan struct{}, s.concurrency)
 
 	var wg sync.WaitGroup
 	totalSamples := len(samples)
@@ -2473,14 +2949,17 @@ func (s *Server) processPRsInParallel(workCtx, reqCtx context.Context, samples [
 			}))
 			sseMu.Unlock()
 
-			prURL := fmt.Sprintf("https://github.com/%s/%s/pull/%d", owner, repo, prSummary.Number)
+			prURL := github.PRURL(workCtx, owner, repo, prSummary.Number)
 
 			// Try calculation result cache first (includes both PR data + calculation)
 			breakdown, calcCached := s.cachedCalcResult(workCtx, prURL, cfg)
 			if calcCached {
-				// Already have the full calculation result
+				// Already have the full calculation result. Its sample weight is from whichever
+				// sample computed it, so use this sample's.
+				breakdown.SampleWeight = prSummary.SampleWeight
 				mu.Lock()
 				breakdowns = append(breakdowns, breakdown)
+				urls = append(urls, prURL)
 				mu.Unlock()
 
 				// Send "complete" update using request context for SSE
@@ -2502,20 +2981,12 @@ func (s *Server) processPRsInParallel(workCtx, reqCtx context.Context, samples [
 			var secondsInState map[string]int
 			if !prCached {
 				var err error
-				// Use work context for actual API calls (not tied to client connection)
-				// Use configured data source with updatedAt for effective caching
-				if s.dataSource == "turnserver" {
-					var prDataWithAnalysis github.PRDataWithAnalysis
-					prDataWithAnalysis, err = github.FetchPRDataWithAnalysisViaTurnserver(workCtx, prURL, token, prSummary.UpdatedAt)
-					if err == nil {
-						prData = prDataWithAnalysis.PRData
-						secondsInState = prDataWithAnalysis.Analysis.SecondsInState
-					}
-				} else {
-					prData, err = github.FetchPRData(workCtx, prURL, token, prSummary.UpdatedAt)
-				}
+				// Use work context for actual API calls (not tied to client connection).
+				// fetchPRData also waits for a global fetch slot shared with other requests.
+				prData, secondsInState, err = s.fetchPRData(workCtx, prURL, token, prSummary.UpdatedAt)
 				if err != nil {
 					s.logger.WarnContext(reqCtx, "Failed to fetch PR data, skipping", "pr_number", prSummary.Number, "source", s.dataSource, errorKey, err)
+					s.forgetTokenOnAuthError(reqCtx, token, err)
 					sseMu.Lock()
 					logSSEError(reqCtx, s.logger, sendSSE(writer, ProgressUpdate{
 						Type:     "error",
@@ -2553,6 +3024,8 @@ func (s *Server) processPRsInParallel(workCtx, reqCtx context.Context, samples [
 			}))
 			sseMu.Unlock()
 
+			info := prSummary.Info()
+			info.Fill(&prData)
 			breakdown = cost.Calculate(prData, cfg)
 
 			// Cache the calculation result with 1 week TTL for PRs from queries
@@ -2561,6 +3034,7 @@ func (s *Server) processPRsInParallel(workCtx, reqCtx context.Context, samples [
 			// Add to results
 			mu.Lock()
 			breakdowns = append(breakdowns, breakdown)
+			urls = append(urls, prURL)
 			mu.Unlock()
 
 			// Send "complete" update using request context for SSE
@@ -2577,5 +3051,5 @@ func (s *Server) processPRsInParallel(workCtx, reqCtx context.Context, samples [
 	}
 
 	wg.Wait()
-	return breakdowns, aggregatedSeconds
+	return breakdowns, urls, aggregatedSeconds
 }
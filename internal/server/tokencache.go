@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// tokenValidationTTL is how long a successful token validation is trusted before calling GitHub again.
+	tokenValidationTTL = 5 * time.Minute
+	// maxValidatedTokens bounds the validation cache; the entries closest to expiry are evicted first.
+	maxValidatedTokens = 10000
+)
+
+// tokenCacheKey returns the cache key for a token. Tokens are hashed so they are never held in the cache.
+func tokenCacheKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenValidated reports whether token passed validation within tokenValidationTTL.
+func (s *Server) tokenValidated(token string) bool {
+	key := tokenCacheKey(token)
+	s.validatedTokensMu.Lock()
+	defer s.validatedTokensMu.Unlock()
+	expiresAt, ok := s.validatedTokens[key]
+	if ok && time.Now().After(expiresAt) {
+		delete(s.validatedTokens, key)
+		return false
+	}
+	return ok
+}
+
+// cacheValidatedToken remembers that token passed validation. Only successes are cached,
+// so a token that failed validation is always rechecked.
+func (s *Server) cacheValidatedToken(token string) {
+	now := time.Now()
+	s.validatedTokensMu.Lock()
+	defer s.validatedTokensMu.Unlock()
+	if len(s.validatedTokens) >= maxValidatedTokens {
+		// Drop expired entries; if the cache is still full, drop the entry closest to expiry
+		var oldestKey string
+		var oldest time.Time
+		for k, expiresAt := range s.validatedTokens {
+			if now.After(expiresAt) {
+				delete(s.validatedTokens, k)
+				continue
+			}
+			if oldestKey == "" || expiresAt.Before(oldest) {
+				oldestKey, oldest = k, expiresAt
+			}
+		}
+		if len(s.validatedTokens) >= maxValidatedTokens {
+			delete(s.validatedTokens, oldestKey)
+		}
+	}
+	s.validatedTokens[tokenCacheKey(token)] = now.Add(tokenValidationTTL)
+}
+
+// forgetTokenOnAuthError drops token from the validation cache when a GitHub call made with it
+// was rejected as unauthorized, so a revoked token doesn't keep passing validation until it expires.
+// A rejected GitHub App installation token is also replaced on the next request.
+func (s *Server) forgetTokenOnAuthError(ctx context.Context, token string, err error) {
+	if err == nil || !isUnauthorizedError(err) {
+		return
+	}
+	s.validatedTokensMu.Lock()
+	delete(s.validatedTokens, tokenCacheKey(token))
+	s.validatedTokensMu.Unlock()
+	if s.appTokens != nil {
+		s.appTokens.Forget(token)
+	}
+	s.logger.InfoContext(ctx, "[forgetTokenOnAuthError] GitHub rejected token; cleared cached validation")
+}
+
+// isUnauthorizedError reports whether err is GitHub rejecting the token (HTTP 401).
+func isUnauthorizedError(err error) bool {
+	var accessErr *AccessError
+	if errors.As(err, &accessErr) {
+		return accessErr.StatusCode == http.StatusUnauthorized
+	}
+	// Fetch errors from prx and the GraphQL queries only carry the status in their message
+	msg := err.Error()
+	return strings.Contains(msg, "Bad credentials") ||
+		strings.Contains(msg, "status code: 401") ||
+		strings.Contains(msg, "status 401") ||
+		strings.Contains(msg, "401 Unauthorized")
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestValidateGitHubTokenCache(t *testing.T) {
+	s := New()
+	ctx := t.Context()
+	calls := 0
+	s.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		status := http.StatusOK
+		if req.Header.Get("Authorization") == "token revoked" {
+			status = http.StatusUnauthorized
+		}
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader("{}")), Request: req}, nil
+	})}
+
+	// A valid token is checked against GitHub once, then served from the cache
+	for range 3 {
+		if err := s.validateGitHubToken(ctx, "good"); err != nil {
+			t.Fatalf("validateGitHubToken(good) = %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("GitHub calls for 3 validations = %d, want 1", calls)
+	}
+
+	// Failures are not cached
+	for range 2 {
+		if err := s.validateGitHubToken(ctx, "revoked"); err == nil {
+			t.Fatal("validateGitHubToken(revoked) succeeded, want error")
+		}
+	}
+	if calls != 3 {
+		t.Errorf("GitHub calls after 2 failed validations = %d, want 3", calls)
+	}
+
+	// Non-auth errors keep the cached result; a 401 from a downstream fetch clears it
+	s.forgetTokenOnAuthError(ctx, "good", errors.New("unexpected status code: 502"))
+	if !s.tokenValidated("good") {
+		t.Error("token forgotten after a non-auth error")
+	}
+	s.forgetTokenOnAuthError(ctx, "good", fmt.Errorf("failed to fetch PRs: %w", errors.New("unexpected status code: 401")))
+	if s.tokenValidated("good") {
+		t.Error("token still validated after a 401")
+	}
+	if err := s.validateGitHubToken(ctx, "good"); err != nil || calls != 4 {
+		t.Errorf("revalidation: err = %v, GitHub calls = %d; want nil, 4", err, calls)
+	}
+
+	// Expired entries are rechecked
+	s.validatedTokens[tokenCacheKey("good")] = time.Now().Add(-time.Second)
+	if s.tokenValidated("good") {
+		t.Error("expired validation still trusted")
+	}
+
+	// Tokens are stored hashed
+	for key := range s.validatedTokens {
+		if strings.Contains(key, "good") {
+			t.Errorf("cache key %q contains the raw token", key)
+		}
+	}
+}
+
+func TestCacheValidatedTokenEviction(t *testing.T) {
+	s := New()
+	now := time.Now()
+	for i := range maxValidatedTokens {
+		s.validatedTokens[fmt.Sprintf("key-%d", i)] = now.Add(time.Duration(i+1) * time.Second)
+	}
+	s.validatedTokens["key-0"] = now.Add(time.Millisecond) // Closest to expiry
+
+	s.cacheValidatedToken("new")
+	if len(s.validatedTokens) != maxValidatedTokens {
+		t.Errorf("cache size = %d, want %d", len(s.validatedTokens), maxValidatedTokens)
+	}
+	if _, ok := s.validatedTokens["key-0"]; ok {
+		t.Error("entry closest to expiry was not evicted")
+	}
+	if !s.tokenValidated("new") {
+		t.Error("new token not cached")
+	}
+}
+
+func TestIsUnauthorizedError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{NewAccessError(http.StatusUnauthorized, "bad token"), true},
+		{NewAccessError(http.StatusForbidden, "no access"), false},
+		{errors.New("GET /repos/o/r/pulls/1: 401 Bad credentials"), true},
+		{errors.New("unexpected status code: 401"), true},
+		{errors.New("unexpected status code: 403"), false},
+		{errors.New("PR 401 not found"), false},
+	}
+	for _, tt := range tests {
+		if got := isUnauthorizedError(tt.err); got != tt.want {
+			t.Errorf("isUnauthorizedError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
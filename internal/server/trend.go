@@ -0,0 +1,241 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+	"github.com/codeGROOVE-dev/prcost/pkg/github"
+)
+
+const (
+	defaultTrendWindows    = 4
+	maxTrendWindows        = 12
+	defaultTrendWindowDays = 30
+	// maxTrendSamples bounds the PRs analyzed across all of a trend's windows, which are sampled
+	// one after another within a single request
+	maxTrendSamples = 500
+)
+
+// OrgTrendRequest represents a request to compare an organization's costs across consecutive windows.
+//
+//nolint:govet // fieldalignment: API struct field order optimized for readability
+type OrgTrendRequest struct {
+	Org        string          `json:"org"`
+	Windows    int             `json:"windows,omitempty"`     // Default: 4
+	WindowDays int             `json:"window_days,omitempty"` // Default: 30; windows × window_days must not exceed 365
+	SampleSize int             `json:"sample_size,omitempty"` // Per window. Default and maximum: 500 / windows
+	Config     *ConfigOverride `json:"config,omitempty"`
+	Anonymize  bool            `json:"anonymize,omitempty"` // Replace logins with pseudonyms (see cost.Anonymizer)
+	Sampling   string          `json:"sampling,omitempty"`  // time (default) or weighted (see github.SamplingMode)
+}
+
+// TrendWindow is one window of a trend: its bounds, the extrapolated costs within it, and
+// how they changed from the previous window.
+//
+//nolint:govet // fieldalignment: API struct field order optimized for readability
+type TrendWindow struct {
+	Since        time.Time                   `json:"since"`
+	Until        time.Time                   `json:"until"`
+	Extrapolated *cost.ExtrapolatedBreakdown `json:"extrapolated,omitempty"`
+	Delta        *TrendDelta                 `json:"delta,omitempty"` // Change from the previous window, if both were analyzed
+	Error        string                      `json:"error,omitempty"` // Set when no PRs could be analyzed in the window
+}
+
+// TrendDelta holds the change in an organization's costs from one window to the next.
+//
+//nolint:govet // fieldalignment: API struct field order optimized for readability
+type TrendDelta struct {
+	TotalCost        float64 `json:"total_cost"`
+	TotalCostPct     float64 `json:"total_cost_pct"` // Relative to the previous window; 0 if it cost nothing
+	EfficiencyPct    float64 `json:"efficiency_pct"` // Percentage points
+	WasteCostPerWeek float64 `json:"waste_cost_per_week"`
+	MergeRate        float64 `json:"merge_rate"` // Percentage points
+}
+
+// OrgTrendResponse represents the response from a trend analysis, with windows oldest first.
+//
+//nolint:govet // fieldalignment: API struct field order optimized for readability
+type OrgTrendResponse struct {
+	Org       string        `json:"org"`
+	Windows   []TrendWindow `json:"windows"`
+	Timestamp time.Time     `json:"timestamp"`
+	Commit    string        `json:"commit"`
+}
+
+// handleOrgTrend processes requests comparing an organization's costs across windows.
+func (s *Server) handleOrgTrend(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+
+	if !s.allowRequest(writer, request, "handleOrgTrend") {
+		return
+	}
+
+	req, err := s.parseOrgTrendRequest(ctx, request)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "[handleOrgTrend] Failed to parse request", "remote_addr", request.RemoteAddr, errorKey, sanitizeError(err))
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	token, ok := s.requestToken(writer, request, "handleOrgTrend", req.Org, "")
+	if !ok {
+		return
+	}
+
+	response, err := s.processOrgTrend(ctx, req, token)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "[handleOrgTrend] Error processing request",
+			"remote_addr", request.RemoteAddr, "org", req.Org, errorKey, sanitizeError(err))
+		http.Error(writer, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(writer).Encode(response); err != nil {
+		s.logger.ErrorContext(ctx, "[handleOrgTrend] Error encoding response", errorKey, err)
+		return
+	}
+
+	s.logger.InfoContext(ctx, "[handleOrgTrend] Request completed", "org", req.Org, "windows", len(response.Windows))
+}
+
+// parseOrgTrendRequest parses and validates a trend request.
+func (s *Server) parseOrgTrendRequest(ctx context.Context, r *http.Request) (*OrgTrendRequest, error) {
+	if s.dataSource == "gitlab" {
+		return nil, errGitLabSampling
+	}
+	var req OrgTrendRequest
+
+	// SECURITY: Limit request body size to prevent memory exhaustion DoS.
+	const maxRequestSize = 1 << 20 // 1MB
+	r.Body = http.MaxBytesReader(nil, r.Body, maxRequestSize)
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.logger.ErrorContext(ctx, "[parseOrgTrendRequest] Failed to decode JSON", errorKey, sanitizeError(err))
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if req.Org == "" {
+		return nil, errors.New("missing required field: org")
+	}
+
+	// Set defaults
+	if req.Windows == 0 {
+		req.Windows = defaultTrendWindows
+	}
+	if req.WindowDays == 0 {
+		req.WindowDays = defaultTrendWindowDays
+	}
+
+	if req.Windows < 2 || req.Windows > maxTrendWindows {
+		return nil, fmt.Errorf("windows must be between 2 and %d", maxTrendWindows)
+	}
+	if req.WindowDays < 1 || req.Windows*req.WindowDays > maxSampleDays {
+		return nil, fmt.Errorf("window_days must be at least 1, and windows × window_days must not exceed %d", maxSampleDays)
+	}
+	if req.SampleSize < 0 {
+		return nil, errors.New("sample_size must be at least 1")
+	}
+	if perWindow := maxTrendSamples / req.Windows; req.SampleSize == 0 || req.SampleSize > perWindow {
+		req.SampleSize = perWindow
+	}
+	if err := validateConfigOverride(req.Config); err != nil {
+		return nil, err
+	}
+	sampling, err := github.ParseSamplingMode(req.Sampling)
+	if err != nil {
+		return nil, err
+	}
+	req.Sampling = string(sampling)
+
+	return &req, nil
+}
+
+// processOrgTrend samples each window through processOrgSample, oldest first, counting the open
+// PRs as of each window's end. A window with no analyzable PRs is reported with an error rather
+// than failing the whole trend.
+func (s *Server) processOrgTrend(ctx context.Context, req *OrgTrendRequest, token string) (*OrgTrendResponse, error) {
+	// Align windows to the hour so repeated requests share cached PR queries.
+	windows := trendWindows(time.Now().Truncate(time.Hour), req.Windows, req.WindowDays)
+
+	var analyzed int
+	var prev *cost.ExtrapolatedBreakdown
+	for i := range windows {
+		w := &windows[i]
+		latest := i == len(windows)-1
+		orgReq := &OrgSampleRequest{
+			Org:        req.Org,
+			SampleSize: req.SampleSize,
+			Days:       req.WindowDays,
+			Config:     req.Config,
+			Anonymize:  req.Anonymize,
+			Sampling:   req.Sampling,
+			since:      w.Since,
+			historical: !latest,
+		}
+		if !latest {
+			orgReq.until = w.Until
+		}
+
+		s.logger.InfoContext(ctx, "Sampling trend window", "org", req.Org,
+			"window", fmt.Sprintf("%d/%d", i+1, len(windows)), "since", w.Since, "until", w.Until)
+		resp, err := s.processOrgSample(ctx, orgReq, token)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			s.logger.WarnContext(ctx, "Failed to analyze trend window", "org", req.Org, "since", w.Since, errorKey, sanitizeError(err))
+			w.Error = "no PRs could be analyzed in this window"
+			prev = nil
+			continue
+		}
+
+		w.Extrapolated = &resp.Extrapolated
+		if prev != nil {
+			delta := trendDelta(prev, w.Extrapolated)
+			w.Delta = &delta
+		}
+		prev = w.Extrapolated
+		analyzed++
+	}
+
+	if analyzed == 0 {
+		return nil, errors.New("no trend windows could be analyzed")
+	}
+
+	return &OrgTrendResponse{
+		Org:       req.Org,
+		Windows:   windows,
+		Timestamp: time.Now(),
+		Commit:    s.serverCommit,
+	}, nil
+}
+
+// trendWindows returns count consecutive windows of days days ending at end, oldest first.
+func trendWindows(end time.Time, count, days int) []TrendWindow {
+	windows := make([]TrendWindow, count)
+	for i := range count {
+		until := end.AddDate(0, 0, -days*(count-1-i))
+		windows[i] = TrendWindow{Since: until.AddDate(0, 0, -days), Until: until}
+	}
+	return windows
+}
+
+// trendDelta computes the change from prev to cur.
+func trendDelta(prev, cur *cost.ExtrapolatedBreakdown) TrendDelta {
+	delta := TrendDelta{
+		TotalCost:        cur.TotalCost - prev.TotalCost,
+		EfficiencyPct:    cur.EfficiencyPct - prev.EfficiencyPct,
+		WasteCostPerWeek: cur.WasteCostPerWeek - prev.WasteCostPerWeek,
+		MergeRate:        cur.MergeRate - prev.MergeRate,
+	}
+	if prev.TotalCost > 0 {
+		delta.TotalCostPct = delta.TotalCost / prev.TotalCost * 100
+	}
+	return delta
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+func TestParseOrgTrendRequest(t *testing.T) {
+	s := New()
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{name: "defaults", body: `{"org":"myorg"}`},
+		{name: "explicit windows", body: `{"org":"myorg","windows":6,"window_days":14}`},
+		{name: "missing org", body: `{"windows":4}`, wantErr: true},
+		{name: "one window", body: `{"org":"myorg","windows":1}`, wantErr: true},
+		{name: "too many windows", body: `{"org":"myorg","windows":13,"window_days":7}`, wantErr: true},
+		{name: "span over a year", body: `{"org":"myorg","windows":4,"window_days":100}`, wantErr: true},
+		{name: "negative window days", body: `{"org":"myorg","window_days":-1}`, wantErr: true},
+		{name: "negative sample size", body: `{"org":"myorg","sample_size":-1}`, wantErr: true},
+		{name: "invalid json", body: `{invalid`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/calculate/org/trend", strings.NewReader(tt.body))
+			got, err := s.parseOrgTrendRequest(req.Context(), req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOrgTrendRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.name == "defaults" && (got.Windows != 4 || got.WindowDays != 30 || got.SampleSize != 125) {
+				t.Errorf("defaults = %d windows of %d days, %d samples; want 4 of 30, 125",
+					got.Windows, got.WindowDays, got.SampleSize)
+			}
+			if tt.name == "explicit windows" && got.SampleSize*got.Windows > maxTrendSamples {
+				t.Errorf("%d windows of %d samples exceed %d samples in all", got.Windows, got.SampleSize, maxTrendSamples)
+			}
+		})
+	}
+}
+
+func TestHandleOrgTrendRouting(t *testing.T) {
+	s := New()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/calculate/org/trend", http.NoBody)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /v1/calculate/org/trend status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/calculate/org/trend", strings.NewReader(`{"windows":4}`))
+	req.Header.Set("Authorization", "Bearer ghp_test")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("POST /v1/calculate/org/trend without org status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestTrendWindows(t *testing.T) {
+	end := time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC)
+	windows := trendWindows(end, 3, 30)
+	if len(windows) != 3 {
+		t.Fatalf("got %d windows, want 3", len(windows))
+	}
+	if !windows[2].Until.Equal(end) {
+		t.Errorf("latest window ends %v, want %v", windows[2].Until, end)
+	}
+	if want := end.AddDate(0, 0, -90); !windows[0].Since.Equal(want) {
+		t.Errorf("oldest window starts %v, want %v", windows[0].Since, want)
+	}
+	for i := 1; i < len(windows); i++ {
+		if !windows[i].Since.Equal(windows[i-1].Until) {
+			t.Errorf("window %d starts %v, want the end of window %d (%v)", i, windows[i].Since, i-1, windows[i-1].Until)
+		}
+	}
+}
+
+func TestTrendDelta(t *testing.T) {
+	prev := cost.ExtrapolatedBreakdown{TotalCost: 10000, EfficiencyPct: 70, WasteCostPerWeek: 500, MergeRate: 80}
+	cur := cost.ExtrapolatedBreakdown{TotalCost: 8000, EfficiencyPct: 75.5, WasteCostPerWeek: 300, MergeRate: 85}
+
+	delta := trendDelta(&prev, &cur)
+	if delta.TotalCost != -2000 || math.Abs(delta.TotalCostPct+20) > 1e-9 {
+		t.Errorf("cost delta = %v (%v%%), want -2000 (-20%%)", delta.TotalCost, delta.TotalCostPct)
+	}
+	if delta.EfficiencyPct != 5.5 || delta.WasteCostPerWeek != -200 || delta.MergeRate != 5 {
+		t.Errorf("delta = %+v, want efficiency +5.5, waste -200, merge rate +5", delta)
+	}
+
+	if got := trendDelta(&cost.ExtrapolatedBreakdown{}, &cur); got.TotalCostPct != 0 {
+		t.Errorf("TotalCostPct from a zero-cost window = %v, want 0", got.TotalCostPct)
+	}
+}
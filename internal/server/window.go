@@ -0,0 +1,70 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/github"
+)
+
+const (
+	// maxSampleDays is the longest window a repo or org sampling request may cover.
+	maxSampleDays = 365
+	// defaultSampleDays is the window of a repo or org sampling request without days or since.
+	defaultSampleDays = 60
+)
+
+// parseSampleWindow validates a request's optional since/until window, which replaces days.
+// On success with a window, *days is set to its length. A zero since means "the last days days".
+func parseSampleWindow(days *int, sinceStr, untilStr string) (since, until time.Time, err error) {
+	if sinceStr == "" && untilStr == "" {
+		return time.Time{}, time.Time{}, nil
+	}
+	if *days != 0 {
+		return time.Time{}, time.Time{}, errors.New("days cannot be combined with since or until")
+	}
+	since, until, err = github.ParseWindow(sinceStr, untilStr, time.Now())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	*days = github.WindowDays(since, until)
+	if *days > maxSampleDays {
+		return time.Time{}, time.Time{}, fmt.Errorf("since/until window must not exceed %d days", maxSampleDays)
+	}
+	return since, until, nil
+}
+
+// sampleWindow returns the bounds to fetch PRs within: the parsed since/until window if set,
+// else the last days days ending now (a zero until).
+func sampleWindow(days int, since, until time.Time) (start, end time.Time) {
+	if !since.IsZero() {
+		return since, until
+	}
+	return time.Now().AddDate(0, 0, -days), time.Time{}
+}
+
+// windowCacheSuffix distinguishes PR query cache keys for since/until windows.
+// Requests using days keep their original keys.
+func windowCacheSuffix(since, until time.Time) string {
+	if since.IsZero() {
+		return ""
+	}
+	suffix := ":since=" + since.UTC().Format(time.RFC3339)
+	if !until.IsZero() {
+		suffix += ":until=" + until.UTC().Format(time.RFC3339)
+	}
+	return suffix
+}
+
+// describeWindow describes a request's window for messages, e.g. "in the last 60 days".
+func describeWindow(days int, since, until time.Time) string {
+	switch {
+	case since.IsZero():
+		return fmt.Sprintf("in the last %d days", days)
+	case until.IsZero():
+		return "since " + since.Format("2006-01-02")
+	default:
+		return fmt.Sprintf("between %s and %s", since.Format("2006-01-02"), until.Format("2006-01-02"))
+	}
+}
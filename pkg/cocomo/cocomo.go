@@ -3,21 +3,27 @@
 package cocomo
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"time"
 )
 
+// MaxExponent is the largest scale exponent Validate accepts. COCOMO II exponents fall
+// between roughly 0.91 and 1.23; anything near 2 makes effort grow quadratically with size.
+const MaxExponent = 2.0
+
 // Config holds parameters for COCOMO II effort estimation.
 // These defaults are based on the COCOMO II model for organic projects.
 type Config struct {
 	// Multiplier is the base effort coefficient (default: 2.94)
-	Multiplier float64
+	Multiplier float64 `yaml:"multiplier"`
 
 	// Exponent is the scale factor (default: 1.0997)
-	Exponent float64
+	Exponent float64 `yaml:"exponent"`
 
 	// MinimumEffort is the minimum effort in minutes (default: 20)
-	MinimumEffort time.Duration
+	MinimumEffort time.Duration `yaml:"minimum_effort"`
 }
 
 // DefaultConfig returns COCOMO II configuration with standard values.
@@ -29,6 +35,21 @@ func DefaultConfig() Config {
 	}
 }
 
+// Validate returns an error if cfg's parameters are nonsensical: a non-positive multiplier,
+// an exponent outside (0, MaxExponent], or a negative minimum effort.
+func (cfg Config) Validate() error {
+	if cfg.Multiplier <= 0 || math.IsInf(cfg.Multiplier, 0) || math.IsNaN(cfg.Multiplier) {
+		return fmt.Errorf("invalid COCOMO multiplier %v: must be positive", cfg.Multiplier)
+	}
+	if !(cfg.Exponent > 0 && cfg.Exponent <= MaxExponent) {
+		return fmt.Errorf("invalid COCOMO exponent %v: must be greater than 0 and at most %v", cfg.Exponent, MaxExponent)
+	}
+	if cfg.MinimumEffort < 0 {
+		return errors.New("invalid COCOMO minimum effort: must not be negative")
+	}
+	return nil
+}
+
 // EstimateEffort calculates development effort based on lines of code.
 //
 // The formula used is: Effort = Multiplier × (KLOC)^Exponent
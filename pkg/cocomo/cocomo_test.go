@@ -133,3 +133,27 @@ func TestEstimateEffortFormula(t *testing.T) {
 		t.Errorf("100 LOC should yield ~35.5 hours, got %.2f hours", hours)
 	}
 }
+
+func TestConfigValidate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("DefaultConfig().Validate() = %v, want nil", err)
+	}
+	valid := Config{Multiplier: 3.2, Exponent: 1.05}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate(%+v) = %v, want nil", valid, err)
+	}
+
+	invalid := []Config{
+		{Multiplier: 0, Exponent: 1.0997},
+		{Multiplier: -1, Exponent: 1.0997},
+		{Multiplier: 2.94, Exponent: 0},
+		{Multiplier: 2.94, Exponent: -0.5},
+		{Multiplier: 2.94, Exponent: MaxExponent + 0.1},
+		{Multiplier: 2.94, Exponent: 1.0997, MinimumEffort: -time.Minute},
+	}
+	for _, cfg := range invalid {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", cfg)
+		}
+	}
+}
@@ -9,11 +9,27 @@ import (
 	"time"
 )
 
+// Bounds on how many PRs a repo or org analysis fetches at once.
+const (
+	// DefaultConcurrency is the number of PRs fetched concurrently unless configured otherwise.
+	DefaultConcurrency = 8
+	// MaxConcurrency is the most PRs ValidateConcurrency allows to be fetched concurrently.
+	MaxConcurrency = 32
+)
+
 var (
 	errNoSamples = errors.New("no samples provided")
 	errNoFetcher = errors.New("fetcher is required")
 )
 
+// ValidateConcurrency returns an error unless n is between 1 and MaxConcurrency.
+func ValidateConcurrency(n int) error {
+	if n < 1 || n > MaxConcurrency {
+		return fmt.Errorf("invalid concurrency %d: must be 1-%d", n, MaxConcurrency)
+	}
+	return nil
+}
+
 // PRFetcher is an interface for fetching PR data.
 // This allows different implementations (with/without caching, different data sources).
 type PRFetcher interface {
@@ -30,26 +46,58 @@ type AnalysisRequest struct {
 	Samples     []PRSummaryInfo // PRs to analyze
 	Logger      *slog.Logger    // Optional logger for progress
 	Concurrency int             // Number of concurrent fetches (0 = sequential)
+	Host        string          // GitHub host for PR URLs (empty = github.com)
 }
 
 // PRSummaryInfo contains basic PR information needed for fetching and analysis.
 type PRSummaryInfo struct {
-	UpdatedAt  time.Time
-	CreatedAt  time.Time
-	ClosedAt   *time.Time // Nil if still open
-	Owner      string
-	Repo       string
-	Author     string
-	AuthorType string // "Bot", "User", or empty if unknown
-	State      string // "OPEN", "CLOSED", "MERGED"
-	Number     int
-	Merged     bool // Whether the PR was merged
+	UpdatedAt      time.Time
+	CreatedAt      time.Time
+	ClosedAt       *time.Time // Nil if still open
+	Owner          string
+	Repo           string
+	Author         string
+	AuthorType     string   // "Bot", "User", or empty if unknown
+	State          string   // "OPEN", "CLOSED", "MERGED"
+	Title          string   // PR title, if known
+	Labels         []string // Label names, if known
+	Files          []string // Paths of changed files, if known
+	GeneratedLines int      // Added lines in generated or vendored files, if known
+	// LinesByCategory is the added lines by file category (see FileCategory), if known
+	LinesByCategory map[string]int
+	Number          int
+	Merged          bool    // Whether the PR was merged
+	SampleWeight    float64 // Population PRs this sample stands for under weighted sampling (0 = an equal share)
+}
+
+// Fill sets what data, fetched for the PR, lacks from its summary: the changed files, the lines
+// added to generated files and to each file category, the title and the labels. The summary's
+// SampleWeight always applies.
+func (info *PRSummaryInfo) Fill(data *PRData) {
+	if len(data.Files) == 0 {
+		data.Files = info.Files
+	}
+	if data.GeneratedLines == 0 {
+		data.GeneratedLines = info.GeneratedLines
+	}
+	if len(data.LinesByCategory) == 0 {
+		data.LinesByCategory = info.LinesByCategory
+	}
+	if data.Title == "" {
+		data.Title = info.Title
+	}
+	if len(data.Labels) == 0 {
+		data.Labels = info.Labels
+	}
+	data.SampleWeight = info.SampleWeight
 }
 
 // AnalysisResult contains the breakdowns from analyzed PRs.
 type AnalysisResult struct {
 	Breakdowns []Breakdown
-	Skipped    int // Number of PRs that failed to fetch
+	Data       []PRData // Fetched PR data, aligned with Breakdowns (for recalculating under other configs)
+	URLs       []string // PR URLs, aligned with Breakdowns
+	Skipped    int      // Number of PRs that failed to fetch
 }
 
 // AnalyzePRs processes a set of PRs and returns their cost breakdowns.
@@ -63,6 +111,11 @@ func AnalyzePRs(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, err
 		return nil, errNoFetcher
 	}
 
+	host := req.Host
+	if host == "" {
+		host = "github.com"
+	}
+
 	// Default to sequential processing if concurrency not specified
 	concurrency := req.Concurrency
 	if concurrency <= 0 {
@@ -70,13 +123,15 @@ func AnalyzePRs(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, err
 	}
 
 	var breakdowns []Breakdown
+	var data []PRData
+	var urls []string
 	var mu sync.Mutex
 	var skipped int
 
 	// Sequential processing
 	if concurrency == 1 {
 		for i, pr := range req.Samples {
-			prURL := fmt.Sprintf("https://github.com/%s/%s/pull/%d", pr.Owner, pr.Repo, pr.Number)
+			prURL := fmt.Sprintf("https://%s/%s/%s/pull/%d", host, pr.Owner, pr.Repo, pr.Number)
 
 			if req.Logger != nil {
 				req.Logger.InfoContext(ctx, "Processing sample PR",
@@ -95,8 +150,12 @@ func AnalyzePRs(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, err
 				continue
 			}
 
+			pr.Fill(&prData)
+
 			breakdown := Calculate(prData, req.Config)
 			breakdowns = append(breakdowns, breakdown)
+			data = append(data, prData)
+			urls = append(urls, prURL)
 		}
 	} else {
 		// Parallel processing with semaphore
@@ -112,7 +171,7 @@ func AnalyzePRs(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, err
 				semaphore <- struct{}{}
 				defer func() { <-semaphore }()
 
-				prURL := fmt.Sprintf("https://github.com/%s/%s/pull/%d", prInfo.Owner, prInfo.Repo, prInfo.Number)
+				prURL := fmt.Sprintf("https://%s/%s/%s/pull/%d", host, prInfo.Owner, prInfo.Repo, prInfo.Number)
 
 				if req.Logger != nil {
 					req.Logger.InfoContext(ctx, "Processing sample PR",
@@ -133,9 +192,13 @@ func AnalyzePRs(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, err
 					return
 				}
 
+				prInfo.Fill(&prData)
+
 				breakdown := Calculate(prData, req.Config)
 				mu.Lock()
 				breakdowns = append(breakdowns, breakdown)
+				data = append(data, prData)
+				urls = append(urls, prURL)
 				mu.Unlock()
 			}(i, pr)
 		}
@@ -149,6 +212,8 @@ func AnalyzePRs(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, err
 
 	return &AnalysisResult{
 		Breakdowns: breakdowns,
+		Data:       data,
+		URLs:       urls,
 		Skipped:    skipped,
 	}, nil
 }
@@ -0,0 +1,115 @@
+package cost
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Anonymizer replaces GitHub logins in cost results with pseudonyms, so reports can be shared
+// without singling people out. Humans who authored a PR become author-1, author-2, ...;
+// everyone else becomes reviewer-1, reviewer-2, .... Pseudonyms are numbered in the order of
+// each login's SHA-256 hash, so they reveal nothing about the logins and don't depend on the
+// order of the results. Bot logins are kept. Costs are unchanged.
+//
+// One Anonymizer maps a login to the same pseudonym everywhere it is applied, so use a single
+// Anonymizer per report. A nil Anonymizer leaves every login unchanged.
+type Anonymizer struct {
+	names     map[string]string
+	reviewers int
+}
+
+// NewAnonymizer assigns pseudonyms to every author and participant in breakdowns.
+func NewAnonymizer(breakdowns []Breakdown) *Anonymizer {
+	authors := make(map[string]bool)
+	others := make(map[string]bool)
+	for i := range breakdowns {
+		b := &breakdowns[i]
+		if b.PRAuthor != "" && !b.AuthorBot && !isAuthorBot("", b.PRAuthor) {
+			authors[b.PRAuthor] = true
+		}
+		for _, p := range b.Participants {
+			if p.Actor != "" && !isAuthorBot("", p.Actor) {
+				others[p.Actor] = true
+			}
+		}
+	}
+	maps.DeleteFunc(others, func(login string, _ bool) bool { return authors[login] })
+
+	a := &Anonymizer{names: make(map[string]string, len(authors)+len(others))}
+	for i, login := range byHash(authors) {
+		a.names[login] = fmt.Sprintf("author-%d", i+1)
+	}
+	for _, login := range byHash(others) {
+		a.addReviewer(login)
+	}
+	return a
+}
+
+// byHash returns the logins sorted by their SHA-256 hash.
+func byHash(logins map[string]bool) []string {
+	hashes := make(map[string]string, len(logins))
+	for login := range logins {
+		sum := sha256.Sum256([]byte(login))
+		hashes[login] = string(sum[:])
+	}
+	return slices.SortedFunc(maps.Keys(logins), func(x, y string) int {
+		return cmp.Compare(hashes[x], hashes[y])
+	})
+}
+
+// addReviewer assigns login the next reviewer pseudonym.
+func (a *Anonymizer) addReviewer(login string) string {
+	a.reviewers++
+	name := fmt.Sprintf("reviewer-%d", a.reviewers)
+	a.names[login] = name
+	return name
+}
+
+// Name returns login's pseudonym. Empty and bot logins are returned unchanged; a login
+// NewAnonymizer didn't see gets the next reviewer pseudonym.
+func (a *Anonymizer) Name(login string) string {
+	if a == nil || login == "" || isAuthorBot("", login) {
+		return login
+	}
+	if name, ok := a.names[login]; ok {
+		return name
+	}
+	return a.addReviewer(login)
+}
+
+// Breakdown replaces the logins in b. Its participants and delay attributions are copied
+// first, so other breakdowns sharing them keep the logins.
+func (a *Anonymizer) Breakdown(b *Breakdown) {
+	if a == nil {
+		return
+	}
+	if !b.AuthorBot {
+		b.PRAuthor = a.Name(b.PRAuthor)
+	}
+	b.Participants = slices.Clone(b.Participants)
+	for i := range b.Participants {
+		b.Participants[i].Actor = a.Name(b.Participants[i].Actor)
+	}
+	b.DelayAttribution = slices.Clone(b.DelayAttribution)
+	for i := range b.DelayAttribution {
+		b.DelayAttribution[i].Actor = a.Name(b.DelayAttribution[i].Actor)
+	}
+}
+
+// Extrapolated replaces the logins in ext's author rollups and sample breakdowns.
+func (a *Anonymizer) Extrapolated(ext *ExtrapolatedBreakdown) {
+	if a == nil {
+		return
+	}
+	ext.AuthorRollups = slices.Clone(ext.AuthorRollups)
+	for i := range ext.AuthorRollups {
+		ext.AuthorRollups[i].Author = a.Name(ext.AuthorRollups[i].Author)
+	}
+	ext.Samples = slices.Clone(ext.Samples)
+	for i := range ext.Samples {
+		a.Breakdown(&ext.Samples[i].Breakdown)
+	}
+}
@@ -0,0 +1,77 @@
+package cost
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAnonymizer(t *testing.T) {
+	breakdowns := []Breakdown{
+		{PRAuthor: "alice", TotalCost: 100, Participants: []ParticipantCostDetail{
+			{Actor: "bob", TotalCost: 20},
+			{Actor: "carol", TotalCost: 10},
+			{Actor: "github-actions[bot]", TotalCost: 1},
+		}, DelayAttribution: []DelayAttribution{
+			{Actor: "carol", Role: DelayHolderReviewer, Cost: 6},
+			{Role: DelayHolderUnassigned, Cost: 4},
+		}},
+		{PRAuthor: "bob", TotalCost: 50, Participants: []ParticipantCostDetail{{Actor: "alice", TotalCost: 5}}},
+		{PRAuthor: "dependabot[bot]", AuthorBot: true, TotalCost: 2, Participants: []ParticipantCostDetail{{Actor: "carol"}}},
+	}
+	original := slices.Clone(breakdowns[0].Participants)
+
+	names := NewAnonymizer(breakdowns)
+	alice, bob, carol := names.Name("alice"), names.Name("bob"), names.Name("carol")
+	if !strings.HasPrefix(alice, "author-") || !strings.HasPrefix(bob, "author-") || alice == bob {
+		t.Errorf("authors alice and bob = %q and %q, want distinct author-N pseudonyms", alice, bob)
+	}
+	if carol != "reviewer-1" {
+		t.Errorf("carol, who authored nothing = %q, want reviewer-1", carol)
+	}
+	if got := names.Name("github-actions[bot]"); got != "github-actions[bot]" {
+		t.Errorf("bot login = %q, want it unchanged", got)
+	}
+
+	// Pseudonyms don't depend on the order of the breakdowns
+	reversed := slices.Clone(breakdowns)
+	slices.Reverse(reversed)
+	if again := NewAnonymizer(reversed); again.Name("alice") != alice || again.Name("carol") != carol {
+		t.Errorf("pseudonyms changed with breakdown order: alice %q, carol %q", again.Name("alice"), again.Name("carol"))
+	}
+
+	b := breakdowns[0]
+	names.Breakdown(&b)
+	if b.PRAuthor != alice || b.Participants[0].Actor != bob || b.Participants[1].Actor != carol || b.Participants[2].Actor != "github-actions[bot]" {
+		t.Errorf("anonymized breakdown = %q with participants %+v", b.PRAuthor, b.Participants)
+	}
+	if b.TotalCost != 100 || b.Participants[0].TotalCost != 20 {
+		t.Error("anonymizing changed costs")
+	}
+	if b.DelayAttribution[0].Actor != carol || b.DelayAttribution[1].Actor != "" || breakdowns[0].DelayAttribution[0].Actor != "carol" {
+		t.Errorf("anonymized delay attribution = %+v, want carol's pseudonym on a copy", b.DelayAttribution)
+	}
+	if !slices.Equal(breakdowns[0].Participants, original) {
+		t.Error("anonymizing a copy changed the original breakdown's participants")
+	}
+
+	ext := ExtrapolatedBreakdown{
+		AuthorRollups: []AuthorRollup{{Author: "bob", TotalCost: 50}, {Author: "alice", TotalCost: 100}},
+		Samples:       SampleBreakdowns([]string{"u1", "u2", "u3"}, breakdowns),
+	}
+	names.Extrapolated(&ext)
+	if ext.AuthorRollups[0].Author != bob || ext.AuthorRollups[1].Author != alice {
+		t.Errorf("anonymized rollups = %+v", ext.AuthorRollups)
+	}
+	if ext.Samples[0].Breakdown.PRAuthor != alice || ext.Samples[0].Breakdown.Participants[1].Actor != carol {
+		t.Errorf("anonymized sample = %+v", ext.Samples[0].Breakdown)
+	}
+
+	// A nil Anonymizer leaves logins unchanged
+	var none *Anonymizer
+	b = breakdowns[0]
+	none.Breakdown(&b)
+	if none.Name("alice") != "alice" || b.PRAuthor != "alice" {
+		t.Error("nil Anonymizer changed logins")
+	}
+}
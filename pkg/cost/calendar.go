@@ -0,0 +1,159 @@
+package cost
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WorkingCalendar describes when a team works, so delay costs skip nights and weekends.
+// The zero value is Monday through Friday, 9:00 to 17:00 UTC.
+type WorkingCalendar struct {
+	Days      []time.Weekday // Working days (default: Monday through Friday)
+	StartHour int            // Hour the working day starts, 0-23 (default: 9)
+	EndHour   int            // Hour the working day ends, 1-24; if not after StartHour, 9 to 17 is used
+	Timezone  string         // IANA time zone of the working day, e.g. "America/New_York" (default: UTC)
+}
+
+// weekdayNames maps the day names ParseWorkingCalendar accepts to weekdays.
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseWorkingCalendar parses a working calendar such as "mon-fri 9-17 America/New_York":
+// working days as a range or comma-separated list, working hours as start-end on a 24-hour
+// clock, and an optional IANA time zone (default UTC).
+func ParseWorkingCalendar(spec string) (*WorkingCalendar, error) {
+	fields := strings.Fields(spec)
+	if len(fields) < 2 || len(fields) > 3 {
+		return nil, fmt.Errorf("invalid working calendar %q: expected days, hours and an optional time zone, e.g. mon-fri 9-17 UTC", spec)
+	}
+
+	var cal WorkingCalendar
+	for _, part := range strings.Split(strings.ToLower(fields[0]), ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		from, ok := weekdayNames[first]
+		to, ok2 := weekdayNames[last]
+		if !ok || (isRange && !ok2) {
+			return nil, fmt.Errorf("invalid working days %q: use day names like mon-fri or mon,wed,fri", fields[0])
+		}
+		if !isRange {
+			to = from
+		}
+		for d := from; ; d = (d + 1) % 7 {
+			if !slices.Contains(cal.Days, d) {
+				cal.Days = append(cal.Days, d)
+			}
+			if d == to {
+				break
+			}
+		}
+	}
+
+	start, end, ok := strings.Cut(fields[1], "-")
+	var err error
+	if ok {
+		cal.StartHour, err = strconv.Atoi(start)
+		if err == nil {
+			cal.EndHour, err = strconv.Atoi(end)
+		}
+	}
+	if !ok || err != nil || cal.StartHour < 0 || cal.EndHour > 24 || cal.EndHour <= cal.StartHour {
+		return nil, fmt.Errorf("invalid working hours %q: expected start-end on a 24-hour clock, e.g. 9-17", fields[1])
+	}
+
+	if len(fields) == 3 {
+		if _, err := time.LoadLocation(fields[2]); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", fields[2], err)
+		}
+		cal.Timezone = fields[2]
+	}
+	return &cal, nil
+}
+
+// String formats the calendar in the form ParseWorkingCalendar accepts, with defaults filled in.
+func (w *WorkingCalendar) String() string {
+	days := w.workingDays()
+	names := make([]string, len(days))
+	for i, d := range days {
+		names[i] = strings.ToLower(d.String()[:3])
+	}
+	start, end := w.hours()
+	return fmt.Sprintf("%s %d-%d %s", strings.Join(names, ","), start, end, w.location())
+}
+
+// workingDays returns the configured working days, or Monday through Friday if none are set.
+func (w *WorkingCalendar) workingDays() []time.Weekday {
+	if len(w.Days) == 0 {
+		return []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	}
+	return w.Days
+}
+
+// hours returns the working day's start and end hours, or 9 to 17 if they are not a valid range.
+func (w *WorkingCalendar) hours() (start, end int) {
+	if w.StartHour < 0 || w.EndHour > 24 || w.EndHour <= w.StartHour {
+		return 9, 17
+	}
+	return w.StartHour, w.EndHour
+}
+
+// location returns the calendar's time zone, falling back to UTC if it is unset or unknown.
+func (w *WorkingCalendar) location() *time.Location {
+	if w.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		slog.Warn("Unknown working calendar time zone, using UTC", "timezone", w.Timezone, "error", err)
+		return time.UTC
+	}
+	return loc
+}
+
+// WorkingHoursBetween returns the working hours between from and to: the part of that span
+// falling within working hours on working days. It returns 0 if to is not after from.
+func (w *WorkingCalendar) WorkingHoursBetween(from, to time.Time) float64 {
+	if !to.After(from) {
+		return 0
+	}
+	loc := w.location()
+	days := w.workingDays()
+	startHour, endHour := w.hours()
+
+	var total time.Duration
+	from, to = from.In(loc), to.In(loc)
+	for day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !slices.Contains(days, day.Weekday()) {
+			continue
+		}
+		// time.Date normalizes hour 24 to midnight of the next day, and handles DST shifts
+		open := time.Date(day.Year(), day.Month(), day.Day(), startHour, 0, 0, 0, loc)
+		closing := time.Date(day.Year(), day.Month(), day.Day(), endHour, 0, 0, 0, loc)
+		if overlap := earlier(closing, to).Sub(later(open, from)); overlap > 0 {
+			total += overlap
+		}
+	}
+	return total.Hours()
+}
+
+// earlier returns the earlier of a and b.
+func earlier(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+// chargedHours returns the delay hours to charge for a span of hours starting at start:
+// all of them without a working calendar, otherwise only the working hours within the span.
+func (c *Config) chargedHours(start time.Time, hours float64) float64 {
+	if c.WorkingCalendar == nil || hours <= 0 {
+		return hours
+	}
+	return c.WorkingCalendar.WorkingHoursBetween(start, start.Add(time.Duration(hours*float64(time.Hour))))
+}
@@ -0,0 +1,86 @@
+package cost
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWorkingHoursBetween(t *testing.T) {
+	cal := &WorkingCalendar{}
+	friday := time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     float64
+	}{
+		{"within a day", friday.Add(10 * time.Hour), friday.Add(12 * time.Hour), 2},
+		{"friday evening to monday morning", friday.Add(18 * time.Hour), friday.Add(3*24*time.Hour + 10*time.Hour), 1},
+		{"friday afternoon to monday noon", friday.Add(15 * time.Hour), friday.Add(3*24*time.Hour + 12*time.Hour), 5},
+		{"whole week", friday, friday.Add(7 * 24 * time.Hour), 40},
+		{"weekend only", friday.Add(24 * time.Hour), friday.Add(3 * 24 * time.Hour), 0},
+		{"reversed", friday.Add(12 * time.Hour), friday.Add(10 * time.Hour), 0},
+	}
+	for _, tt := range tests {
+		if got := cal.WorkingHoursBetween(tt.from, tt.to); math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("%s: WorkingHoursBetween() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Working hours are in the calendar's time zone
+	tokyo := &WorkingCalendar{Timezone: "Asia/Tokyo"}
+	if got := tokyo.WorkingHoursBetween(friday, friday.Add(8*time.Hour)); math.Abs(got-8) > 0.001 {
+		t.Errorf("Asia/Tokyo: WorkingHoursBetween(00:00-08:00 UTC) = %v, want 8", got)
+	}
+}
+
+func TestParseWorkingCalendar(t *testing.T) {
+	cal, err := ParseWorkingCalendar("sun-thu 8-16 Asia/Jerusalem")
+	if err != nil {
+		t.Fatalf("ParseWorkingCalendar() error: %v", err)
+	}
+	if got, want := cal.String(), "sun,mon,tue,wed,thu 8-16 Asia/Jerusalem"; got != want {
+		t.Errorf("ParseWorkingCalendar() = %q, want %q", got, want)
+	}
+	cal, err = ParseWorkingCalendar("mon,wed,fri 10-14")
+	if err != nil {
+		t.Fatalf("ParseWorkingCalendar() error: %v", err)
+	}
+	if got, want := cal.String(), "mon,wed,fri 10-14 UTC"; got != want {
+		t.Errorf("ParseWorkingCalendar() = %q, want %q", got, want)
+	}
+
+	for _, bad := range []string{"", "mon-fri", "weekdays 9-17", "mon-fri 17-9", "mon-fri 9-25", "mon-fri nine-five", "mon-fri 9-17 Mars/Olympus", "mon-fri 9-17 UTC extra"} {
+		if _, err := ParseWorkingCalendar(bad); err == nil {
+			t.Errorf("ParseWorkingCalendar(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestCalculateWithWorkingCalendar(t *testing.T) {
+	// Opened Friday evening, merged Monday morning: an hour of working time
+	created := time.Date(2025, 3, 7, 18, 0, 0, 0, time.UTC)
+	merged := created.Add(3*24*time.Hour - 8*time.Hour)
+	data := PRData{
+		LinesAdded: 50,
+		Author:     "alice",
+		CreatedAt:  created,
+		ClosedAt:   merged,
+		Merged:     true,
+		Events: []ParticipantEvent{
+			{Timestamp: created, Actor: "alice", Kind: "commit"},
+			{Timestamp: merged.Add(-30 * time.Minute), Actor: "bob", Kind: "review"},
+		},
+	}
+	cfg := DefaultConfig()
+	allHours := Calculate(data, cfg)
+
+	cfg.WorkingCalendar = &WorkingCalendar{}
+	working := Calculate(data, cfg)
+	if want := allHours.DelayCostDetail.DeliveryDelayHours / 64; math.Abs(working.DelayCostDetail.DeliveryDelayHours-want) > 0.001 {
+		t.Errorf("DeliveryDelayHours = %v, want one working hour's worth %v", working.DelayCostDetail.DeliveryDelayHours, want)
+	}
+	if working.PRDuration != allHours.PRDuration {
+		t.Errorf("PRDuration = %v, want elapsed time %v", working.PRDuration, allHours.PRDuration)
+	}
+}
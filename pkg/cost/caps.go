@@ -0,0 +1,110 @@
+package cost
+
+import "log/slog"
+
+// Names of the caps that can bind a cost component, as reported in CapAppliedTo.
+const (
+	CapNone                   = ""
+	CapMinDelayThreshold      = "min_delay_threshold"        // Config.MinDelayThresholdMinutes
+	CapMaxDelayAfterLastEvent = "max_delay_after_last_event" // Config.MaxDelayAfterLastEvent
+	CapMaxProjectDelay        = "max_project_delay"          // Config.MaxProjectDelay
+	CapMaxCodeDrift           = "max_code_drift"             // Config.MaxCodeDrift
+	CapMaxPRCodeCost          = "max_pr_code_cost"           // Config.MaxPRCodeCost
+)
+
+// CapAppliedTo records which cap, if any, bound each time-based delay component, and
+// whether MaxPRCodeCost bound the author's code cost.
+//
+// The caps are independent:
+//   - Delivery delay (and automated updates for bot PRs) is measured from PR creation.
+//     It is zeroed below MinDelayThresholdMinutes, then trimmed to MaxDelayAfterLastEvent
+//     past the last event, then limited to MaxProjectDelay in total.
+//   - Code churn is measured from the author's last commit, not from PR creation, and is
+//     limited only by MaxCodeDrift.
+//
+// For an ancient PR both caps usually bind, but they bound different components and
+// changing one never changes the other. MaxPRCodeCost limits new development plus
+// adaptation, keeping one huge generated or vendored PR from dominating an extrapolation.
+type CapAppliedTo struct {
+	DeliveryDelay      string  `json:"delivery_delay"` // Cap that bound delivery delay; also applies to automated updates
+	CodeChurn          string  `json:"code_churn"`     // Cap that bound code churn drift
+	CodeCost           string  `json:"code_cost"`      // Cap that bound new development plus adaptation cost
+	UncappedDelayHours float64 `json:"uncapped_delay_hours"`
+	CappedDelayHours   float64 `json:"capped_delay_hours"`
+	UncappedDriftDays  float64 `json:"uncapped_drift_days"` // Days since the author's last commit
+	CappedDriftDays    float64 `json:"capped_drift_days"`   // Drift used for code churn (0 for closed PRs)
+	UncappedCodeCost   float64 `json:"uncapped_code_cost"`  // New development plus adaptation cost before MaxPRCodeCost
+}
+
+// capDelayHours applies the delivery delay caps to a PR open for delayHours whose last event
+// was hoursSinceLastEvent ago. It returns the capped hours and the cap that bound them last.
+func capDelayHours(delayHours, hoursSinceLastEvent float64, cfg Config) (hours float64, capName string) {
+	hours = delayHours
+
+	// No delay costs for fast-turnaround PRs: they have no meaningful coordination overhead
+	minDelayThreshold := cfg.MinDelayThresholdMinutes / 60.0
+	if hours < minDelayThreshold {
+		slog.Info("Applied delay minimum threshold - no delay costs for fast turnaround",
+			"delay_hours", delayHours,
+			"threshold_hours", minDelayThreshold)
+		return 0, CapMinDelayThreshold
+	}
+
+	// Only count delay up to MaxDelayAfterLastEvent past the last event
+	maxAfterEvent := cfg.MaxDelayAfterLastEvent.Hours()
+	if hours > 0 && hoursSinceLastEvent > maxAfterEvent {
+		excessHours := hoursSinceLastEvent - maxAfterEvent
+		hours = max(delayHours-excessHours, 0)
+		capName = CapMaxDelayAfterLastEvent
+		slog.Info("Applied delay cap: time since last event",
+			"max_hours_after_event", maxAfterEvent,
+			"actual_hours_since_event", hoursSinceLastEvent,
+			"excess_hours", excessHours,
+			"capped_delay_hours", hours)
+	}
+
+	// Absolute maximum regardless of PR age or activity
+	maxTotal := cfg.MaxProjectDelay.Hours()
+	if hours > maxTotal {
+		slog.Info("Applied delay cap: absolute maximum",
+			"max_total_hours", maxTotal,
+			"delay_before_cap", hours,
+			"capped_delay_hours", maxTotal)
+		hours = maxTotal
+		capName = CapMaxProjectDelay
+	}
+	return hours, capName
+}
+
+// capDriftDays limits code churn drift to MaxCodeDrift.
+func capDriftDays(driftDays float64, cfg Config) (days float64, capName string) {
+	if maxDriftDays := cfg.MaxCodeDrift.Hours() / 24.0; driftDays > maxDriftDays {
+		return maxDriftDays, CapMaxCodeDrift
+	}
+	return driftDays, CapNone
+}
+
+// capCodeCost limits the author's new development plus adaptation cost to MaxPRCodeCost,
+// scaling both (and their hours) by the same factor so their proportions are kept.
+// It returns the cost before capping and the cap that bound it, if any.
+func capCodeCost(author *AuthorCostDetail, cfg Config) (uncapped float64, capName string) {
+	uncapped = author.NewCodeCost + author.AdaptationCost
+	if cfg.MaxPRCodeCost <= 0 || uncapped <= cfg.MaxPRCodeCost {
+		return uncapped, CapNone
+	}
+
+	scale := cfg.MaxPRCodeCost / uncapped
+	hoursBefore := author.NewCodeHours + author.AdaptationHours
+	author.NewCodeCost *= scale
+	author.AdaptationCost *= scale
+	author.NewCodeHours *= scale
+	author.AdaptationHours *= scale
+	author.TotalCost -= uncapped - cfg.MaxPRCodeCost
+	author.TotalHours -= hoursBefore * (1 - scale)
+	slog.Info("Applied code cost cap",
+		"max_pr_code_cost", cfg.MaxPRCodeCost,
+		"uncapped_code_cost", uncapped,
+		"new_lines", author.NewLines,
+		"modified_lines", author.ModifiedLines)
+	return uncapped, CapMaxPRCodeCost
+}
@@ -0,0 +1,160 @@
+package cost
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCapDelayHours(t *testing.T) {
+	cfg := DefaultConfig() // 30 min threshold, 14 days after last event, 90 days total
+	day := 24.0
+
+	tests := []struct {
+		name                string
+		delayHours          float64
+		hoursSinceLastEvent float64
+		wantHours           float64
+		wantCap             string
+	}{
+		{"fast turnaround", 0.25, 0, 0, CapMinDelayThreshold},
+		{"uncapped", 10 * day, 1 * day, 10 * day, CapNone},
+		{"after last event", 60 * day, 30 * day, 44 * day, CapMaxDelayAfterLastEvent},
+		{"absolute maximum", 200 * day, 1 * day, 90 * day, CapMaxProjectDelay},
+		{"both, absolute binds last", 400 * day, 100 * day, 90 * day, CapMaxProjectDelay},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hours, capName := capDelayHours(tt.delayHours, tt.hoursSinceLastEvent, cfg)
+			if hours != tt.wantHours || capName != tt.wantCap {
+				t.Errorf("capDelayHours() = %v, %q; want %v, %q", hours, capName, tt.wantHours, tt.wantCap)
+			}
+		})
+	}
+}
+
+func TestCapDriftDays(t *testing.T) {
+	cfg := DefaultConfig()
+	if days, capName := capDriftDays(30, cfg); days != 30 || capName != CapNone {
+		t.Errorf("capDriftDays(30) = %v, %q; want 30, uncapped", days, capName)
+	}
+	if days, capName := capDriftDays(365, cfg); days != 90 || capName != CapMaxCodeDrift {
+		t.Errorf("capDriftDays(365) = %v, %q; want 90, %q", days, capName, CapMaxCodeDrift)
+	}
+}
+
+// ancientPR is an open PR created a year ago whose author last committed 200 days ago
+// and which last saw activity 5 days ago, so both the delivery and drift caps can bind.
+func ancientPR() PRData {
+	now := time.Now()
+	return PRData{
+		LinesAdded: 500,
+		Author:     "alice",
+		CreatedAt:  now.Add(-365 * 24 * time.Hour),
+		Events: []ParticipantEvent{
+			{Timestamp: now.Add(-200 * 24 * time.Hour), Actor: "alice", Kind: "commit"},
+			{Timestamp: now.Add(-5 * 24 * time.Hour), Actor: "bob", Kind: "comment"},
+		},
+	}
+}
+
+func TestCalculateCapsAreIndependent(t *testing.T) {
+	base := Calculate(ancientPR(), DefaultConfig())
+	if base.CapAppliedTo.DeliveryDelay != CapMaxProjectDelay || base.CapAppliedTo.CodeChurn != CapMaxCodeDrift {
+		t.Fatalf("CapAppliedTo = %+v, want delivery bound by %q and churn by %q",
+			base.CapAppliedTo, CapMaxProjectDelay, CapMaxCodeDrift)
+	}
+	if !base.DelayCapped {
+		t.Error("Expected DelayCapped when the project delay cap binds")
+	}
+	if got := base.CapAppliedTo.CappedDelayHours; got != 90*24 {
+		t.Errorf("CappedDelayHours = %v, want %v", got, 90*24)
+	}
+
+	// Raising only the project delay cap changes delivery delay, not churn
+	cfg := DefaultConfig()
+	cfg.MaxProjectDelay = 180 * 24 * time.Hour
+	longerDelay := Calculate(ancientPR(), cfg)
+	if longerDelay.DelayCostDetail.DeliveryDelayCost <= base.DelayCostDetail.DeliveryDelayCost {
+		t.Errorf("Delivery delay cost %v should grow with MaxProjectDelay (was %v)",
+			longerDelay.DelayCostDetail.DeliveryDelayCost, base.DelayCostDetail.DeliveryDelayCost)
+	}
+	if longerDelay.DelayCostDetail.CodeChurnCost != base.DelayCostDetail.CodeChurnCost {
+		t.Errorf("Code churn cost changed with MaxProjectDelay: %v != %v",
+			longerDelay.DelayCostDetail.CodeChurnCost, base.DelayCostDetail.CodeChurnCost)
+	}
+
+	// Raising only the drift cap changes churn, not delivery delay
+	cfg = DefaultConfig()
+	cfg.MaxCodeDrift = 180 * 24 * time.Hour
+	longerDrift := Calculate(ancientPR(), cfg)
+	if longerDrift.DelayCostDetail.CodeChurnCost <= base.DelayCostDetail.CodeChurnCost {
+		t.Errorf("Code churn cost %v should grow with MaxCodeDrift (was %v)",
+			longerDrift.DelayCostDetail.CodeChurnCost, base.DelayCostDetail.CodeChurnCost)
+	}
+	if longerDrift.DelayCostDetail.DeliveryDelayCost != base.DelayCostDetail.DeliveryDelayCost {
+		t.Errorf("Delivery delay cost changed with MaxCodeDrift: %v != %v",
+			longerDrift.DelayCostDetail.DeliveryDelayCost, base.DelayCostDetail.DeliveryDelayCost)
+	}
+	if got := longerDrift.CapAppliedTo.CappedDriftDays; got != 180 {
+		t.Errorf("CappedDriftDays = %v, want 180 (200 days since last commit)", got)
+	}
+
+	// Closed PRs incur no churn, so the drift cap never binds
+	closed := ancientPR()
+	closed.ClosedAt = time.Now()
+	if got := Calculate(closed, DefaultConfig()).CapAppliedTo; got.CodeChurn != CapNone || got.CappedDriftDays != 0 {
+		t.Errorf("closed PR CapAppliedTo = %+v, want no churn cap", got)
+	}
+}
+
+func TestCalculateMaxPRCodeCost(t *testing.T) {
+	now := time.Now()
+	huge := PRData{
+		LinesAdded:   200000,
+		LinesDeleted: 20000,
+		Author:       "alice",
+		Events:       []ParticipantEvent{{Timestamp: now.Add(-time.Hour), Actor: "alice", Kind: "commit"}},
+		CreatedAt:    now.Add(-2 * time.Hour),
+		ClosedAt:     now,
+	}
+
+	cfg := DefaultConfig()
+	uncapped := Calculate(huge, cfg)
+	if uncapped.CapAppliedTo.CodeCost != CapNone {
+		t.Errorf("CodeCost cap = %q by default, want none", uncapped.CapAppliedTo.CodeCost)
+	}
+	uncappedCode := uncapped.Author.NewCodeCost + uncapped.Author.AdaptationCost
+
+	cfg.MaxPRCodeCost = 50000
+	if uncappedCode <= cfg.MaxPRCodeCost {
+		t.Fatalf("test PR code cost $%.2f doesn't exceed the cap", uncappedCode)
+	}
+	capped := Calculate(huge, cfg)
+	a := capped.Author
+	if got := a.NewCodeCost + a.AdaptationCost; math.Abs(got-cfg.MaxPRCodeCost) > 0.01 {
+		t.Errorf("capped code cost = $%.2f, want $%.2f", got, cfg.MaxPRCodeCost)
+	}
+	if capped.CapAppliedTo.CodeCost != CapMaxPRCodeCost {
+		t.Errorf("CodeCost cap = %q, want %q", capped.CapAppliedTo.CodeCost, CapMaxPRCodeCost)
+	}
+	if math.Abs(capped.CapAppliedTo.UncappedCodeCost-uncappedCode) > 0.01 {
+		t.Errorf("UncappedCodeCost = $%.2f, want $%.2f", capped.CapAppliedTo.UncappedCodeCost, uncappedCode)
+	}
+	// New development and adaptation keep their proportions, and totals drop by the excess
+	if ratio, want := a.AdaptationCost/a.NewCodeCost, uncapped.Author.AdaptationCost/uncapped.Author.NewCodeCost; math.Abs(ratio-want) > 1e-9 {
+		t.Errorf("adaptation/new ratio = %v, want %v", ratio, want)
+	}
+	if want := uncapped.Author.TotalCost - (uncappedCode - cfg.MaxPRCodeCost); math.Abs(a.TotalCost-want) > 0.01 {
+		t.Errorf("author TotalCost = $%.2f, want $%.2f", a.TotalCost, want)
+	}
+	if want := a.NewCodeHours + a.AdaptationHours + a.GitHubHours + a.GitHubContextHours + a.ConflictResolutionHours; math.Abs(a.TotalHours-want) > 1e-9 {
+		t.Errorf("author TotalHours = %v, want %v", a.TotalHours, want)
+	}
+
+	small := huge
+	small.LinesAdded, small.LinesDeleted = 50, 0
+	if b := Calculate(small, cfg); b.CapAppliedTo.CodeCost != CapNone {
+		t.Errorf("small PR code cost capped: %q", b.CapAppliedTo.CodeCost)
+	}
+}
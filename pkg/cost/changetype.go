@@ -0,0 +1,130 @@
+package cost
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ChangeTypeUnclassified is the change type of PRs whose labels and title match no classification.
+const ChangeTypeUnclassified = "unclassified"
+
+// DefaultChangeTypes maps conventional-commit title prefixes and common label names to change types.
+// It is used when Config.ChangeTypes is nil.
+var DefaultChangeTypes = map[string]string{
+	"feat":          "feature",
+	"feature":       "feature",
+	"enhancement":   "feature",
+	"fix":           "fix",
+	"bug":           "fix",
+	"bugfix":        "fix",
+	"hotfix":        "fix",
+	"chore":         "chore",
+	"deps":          "chore",
+	"dependencies":  "chore",
+	"build":         "chore",
+	"ci":            "chore",
+	"style":         "chore",
+	"docs":          "docs",
+	"doc":           "docs",
+	"documentation": "docs",
+	"refactor":      "refactor",
+	"cleanup":       "refactor",
+	"perf":          "perf",
+	"test":          "test",
+	"tests":         "test",
+	"revert":        "revert",
+}
+
+// conventionalPrefix matches a conventional-commit title prefix such as "feat:", "fix(api):" or "feat!:".
+var conventionalPrefix = regexp.MustCompile(`^\s*([A-Za-z]+)(?:\([^)]*\))?!?:`)
+
+// ClassifyChangeType returns the change type of a PR from its labels or title, using types to map
+// label names and conventional-commit prefixes to change types (DefaultChangeTypes when nil).
+// Labels win over the title, since they are usually applied deliberately. A label matches by its
+// full name or by the part after its last "/" or ":", so "kind/bug" and "type: feature" work.
+// Matching is case-insensitive; PRs matching nothing are ChangeTypeUnclassified.
+func ClassifyChangeType(title string, labels []string, types map[string]string) string {
+	if types == nil {
+		types = DefaultChangeTypes
+	}
+	lookup := func(key string) (string, bool) {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if v, ok := types[key]; ok {
+			return v, true
+		}
+		for k, v := range types {
+			if strings.ToLower(k) == key {
+				return v, true
+			}
+		}
+		return "", false
+	}
+
+	for _, label := range labels {
+		if t, ok := lookup(label); ok {
+			return t
+		}
+		if i := strings.LastIndexAny(label, "/:"); i >= 0 {
+			if t, ok := lookup(label[i+1:]); ok {
+				return t
+			}
+		}
+	}
+	if m := conventionalPrefix.FindStringSubmatch(title); m != nil {
+		if t, ok := lookup(m[1]); ok {
+			return t
+		}
+	}
+	return ChangeTypeUnclassified
+}
+
+// ChangeTypeRollup summarizes the sampled PRs of a single change type.
+type ChangeTypeRollup struct {
+	Type       string  `json:"type"`
+	SampledPRs int     `json:"sampled_prs"` // Number of PRs of this type in the sample
+	TotalCost  float64 `json:"total_cost"`  // Sampled PR costs weighted up to the total PR count
+	CostPct    float64 `json:"cost_pct"`    // Share of the extrapolated total cost (0-100)
+}
+
+// changeTypeRollups groups sample breakdowns by change type. Each PR's cost is multiplied
+// by its sample weight and by scale (total PRs / sampled PRs) to extrapolate to the population.
+func changeTypeRollups(breakdowns []Breakdown, weights []float64, scale float64) []ChangeTypeRollup {
+	if len(breakdowns) == 0 {
+		return nil
+	}
+	byType := make(map[string]*ChangeTypeRollup)
+	var total float64
+	for i := range breakdowns {
+		b := &breakdowns[i]
+		t := b.ChangeType
+		if t == "" {
+			t = ChangeTypeUnclassified
+		}
+		r, ok := byType[t]
+		if !ok {
+			r = &ChangeTypeRollup{Type: t}
+			byType[t] = r
+		}
+		r.SampledPRs++
+		r.TotalCost += b.TotalCost * weights[i] * scale
+		total += b.TotalCost * weights[i] * scale
+	}
+
+	rollups := make([]ChangeTypeRollup, 0, len(byType))
+	for _, r := range byType {
+		if total > 0 {
+			r.CostPct = 100 * r.TotalCost / total
+		}
+		rollups = append(rollups, *r)
+	}
+	// Highest cost first; ties broken by type for deterministic output
+	slices.SortFunc(rollups, func(a, b ChangeTypeRollup) int {
+		if c := cmp.Compare(b.TotalCost, a.TotalCost); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Type, b.Type)
+	})
+	return rollups
+}
@@ -0,0 +1,90 @@
+package cost
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestClassifyChangeType(t *testing.T) {
+	tests := []struct {
+		title  string
+		labels []string
+		want   string
+	}{
+		{title: "feat: add SSO login", want: "feature"},
+		{title: "fix(api): handle empty body", want: "fix"},
+		{title: "feat!: drop v1 endpoints", want: "feature"},
+		{title: "Chore: bump deps", want: "chore"},
+		{title: "docs: fix typo in README", want: "docs"},
+		{title: "refactor(cost): split Calculate", want: "refactor"},
+		{title: "Add SSO login", want: ChangeTypeUnclassified},
+		{title: "wip: something", want: ChangeTypeUnclassified},
+		{title: "feat add SSO login", want: ChangeTypeUnclassified},
+		// Labels win over the title, by full name or the part after "/" or ":"
+		{title: "feat: add SSO login", labels: []string{"kind/bug"}, want: "fix"},
+		{title: "Update docs", labels: []string{"needs-review", "Type: Documentation"}, want: "docs"},
+		{title: "chore: tidy", labels: []string{"needs-review"}, want: "chore"},
+	}
+	for _, tt := range tests {
+		if got := ClassifyChangeType(tt.title, tt.labels, nil); got != tt.want {
+			t.Errorf("ClassifyChangeType(%q, %v) = %q, want %q", tt.title, tt.labels, got, tt.want)
+		}
+	}
+
+	custom := map[string]string{"Security": "security", "feat": "product"}
+	if got := ClassifyChangeType("feat: x", nil, custom); got != "product" {
+		t.Errorf("custom map title = %q, want product", got)
+	}
+	if got := ClassifyChangeType("fix: x", []string{"area/security"}, custom); got != "security" {
+		t.Errorf("custom map label = %q, want security", got)
+	}
+	if got := ClassifyChangeType("fix: x", nil, custom); got != ChangeTypeUnclassified {
+		t.Errorf("custom map replaces defaults: got %q, want %q", got, ChangeTypeUnclassified)
+	}
+}
+
+func TestExtrapolateChangeTypeRollups(t *testing.T) {
+	created := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	cfg := DefaultConfig()
+	pr := func(title string, lines int) Breakdown {
+		data := NewPRData("alice", created, created.Add(2*time.Hour), true, lines, 0, []ParticipantEvent{
+			{Timestamp: created, Actor: "alice", Kind: "commit"},
+		})
+		data.Title = title
+		return Calculate(data, cfg)
+	}
+	breakdowns := []Breakdown{
+		pr("feat: big feature", 800),
+		pr("chore: bump deps", 10),
+		pr("chore(ci): pin actions", 20),
+		pr("Update README", 5),
+	}
+	if breakdowns[0].ChangeType != "feature" || breakdowns[3].ChangeType != ChangeTypeUnclassified {
+		t.Fatalf("change types = %q, %q; want feature, unclassified", breakdowns[0].ChangeType, breakdowns[3].ChangeType)
+	}
+
+	ext := ExtrapolateFromSamples(breakdowns, 40, 1, 0, 30, cfg, nil, nil)
+	if len(ext.ChangeTypeRollups) != 3 {
+		t.Fatalf("ChangeTypeRollups = %+v, want feature, chore and unclassified", ext.ChangeTypeRollups)
+	}
+
+	byType := make(map[string]ChangeTypeRollup)
+	var sumCost, sumPct float64
+	for _, r := range ext.ChangeTypeRollups {
+		byType[r.Type] = r
+		sumCost += r.TotalCost
+		sumPct += r.CostPct
+	}
+	if ext.ChangeTypeRollups[0].Type != "feature" {
+		t.Errorf("first rollup = %q, want the costliest type feature", ext.ChangeTypeRollups[0].Type)
+	}
+	// 40 PRs from 4 samples: each sampled PR stands for 10
+	wantChore := 10 * (breakdowns[1].TotalCost + breakdowns[2].TotalCost)
+	if chore := byType["chore"]; chore.SampledPRs != 2 || math.Abs(chore.TotalCost-wantChore) > 1e-6 {
+		t.Errorf("chore rollup = %+v, want 2 sampled PRs costing %v", chore, wantChore)
+	}
+	if math.Abs(sumCost-ext.TotalCost) > 1e-6 || math.Abs(sumPct-100) > 1e-9 {
+		t.Errorf("rollups sum to %v (%v%%), want TotalCost %v (100%%)", sumCost, sumPct, ext.TotalCost)
+	}
+}
@@ -0,0 +1,117 @@
+package cost
+
+import (
+	"cmp"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// coAuthorShares splits a PR's development effort using its commits' Co-authored-by trailers.
+// Each commit carries an equal share of the effort, divided evenly between the PR author and
+// the commit's co-authors. It returns the PR author's share (1 when no commit is co-authored)
+// and each co-author's share.
+func coAuthorShares(data PRData) (authorShare float64, coAuthors map[string]float64) {
+	var commits []ParticipantEvent
+	for _, event := range data.Events {
+		if event.Kind == "commit" {
+			commits = append(commits, event)
+		}
+	}
+
+	authorShare = 1
+	if len(commits) == 0 {
+		return authorShare, nil
+	}
+	perCommit := 1.0 / float64(len(commits))
+
+	// Logins are case-insensitive; spell each co-author as the PR's events do, if they appear there
+	spelling := make(map[string]string)
+	for _, event := range data.Events {
+		if _, ok := spelling[strings.ToLower(event.Actor)]; !ok {
+			spelling[strings.ToLower(event.Actor)] = event.Actor
+		}
+	}
+
+	for _, commit := range commits {
+		var people []string
+		for _, login := range commit.CoAuthors {
+			if known, ok := spelling[strings.ToLower(login)]; ok {
+				login = known
+			} else {
+				spelling[strings.ToLower(login)] = login
+			}
+			if login != "" && !strings.EqualFold(login, data.Author) && !slices.Contains(people, login) {
+				people = append(people, login)
+			}
+		}
+		if len(people) == 0 {
+			continue
+		}
+
+		split := perCommit / float64(len(people)+1)
+		authorShare -= perCommit - split
+		if coAuthors == nil {
+			coAuthors = make(map[string]float64)
+		}
+		for _, login := range people {
+			coAuthors[login] += split
+		}
+	}
+	return authorShare, coAuthors
+}
+
+// splitCoAuthoredEffort moves co-authors' shares of the author's development (COCOMO) effort
+// out of author and onto their participant entries, costed at each co-author's own rate.
+// Co-authors without a participant entry get one. Other author costs are unchanged.
+func splitCoAuthoredEffort(data PRData, cfg Config, author *AuthorCostDetail, participants []ParticipantCostDetail) []ParticipantCostDetail {
+	authorShare, coAuthors := coAuthorShares(data)
+	if data.AuthorBot || len(coAuthors) == 0 {
+		return participants
+	}
+
+	devHours := author.NewCodeHours + author.AdaptationHours
+	devCost := author.NewCodeCost + author.AdaptationCost
+	author.NewCodeHours *= authorShare
+	author.AdaptationHours *= authorShare
+	author.NewCodeCost *= authorShare
+	author.AdaptationCost *= authorShare
+	author.TotalHours -= devHours * (1 - authorShare)
+	author.TotalCost -= devCost * (1 - authorShare)
+
+	for login, share := range coAuthors {
+		hours := devHours * share
+		cost := hours * cfg.hourlyRateFor(login)
+
+		i := slices.IndexFunc(participants, func(p ParticipantCostDetail) bool { return strings.EqualFold(p.Actor, login) })
+		if i < 0 {
+			participants = append(participants, ParticipantCostDetail{Actor: login})
+			i = len(participants) - 1
+		}
+		participants[i].CoAuthoredHours += hours
+		participants[i].CoAuthoredCost += cost
+		participants[i].TotalHours += hours
+		participants[i].TotalCost += cost
+
+		slog.Info("Co-authored development effort",
+			"co_author", login,
+			"share", share,
+			"hours", hours,
+			"cost", cost)
+	}
+
+	// Keep participants sorted by total cost descending, as calculateParticipantCosts does
+	slices.SortFunc(participants, func(a, b ParticipantCostDetail) int {
+		return cmp.Compare(b.TotalCost, a.TotalCost)
+	})
+	return participants
+}
+
+// coAuthoredCost sums the development cost and hours attributed to co-authors.
+func coAuthoredCost(participants []ParticipantCostDetail) (cost, hours float64) {
+	for _, p := range participants {
+		cost += p.CoAuthoredCost
+		hours += p.CoAuthoredHours
+	}
+	return cost, hours
+}
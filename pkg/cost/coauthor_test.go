@@ -0,0 +1,85 @@
+package cost
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCalculateCoAuthoredCommitSplitsEffort(t *testing.T) {
+	created := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	pr := func(coAuthors ...string) PRData {
+		return NewPRData("alice", created, created.Add(2*time.Hour), true, 400, 0, []ParticipantEvent{
+			{Timestamp: created, Actor: "alice", Kind: "commit", CoAuthors: coAuthors},
+		})
+	}
+	cfg := DefaultConfig()
+
+	solo := Calculate(pr(), cfg)
+	paired := Calculate(pr("bob"), cfg)
+
+	devHours := solo.Author.NewCodeHours + solo.Author.AdaptationHours
+	if devHours == 0 {
+		t.Fatal("expected development hours for a 400-line PR")
+	}
+	if got := paired.Author.NewCodeHours + paired.Author.AdaptationHours; math.Abs(got-devHours/2) > 1e-9 {
+		t.Errorf("author development hours = %v, want half of %v", got, devHours)
+	}
+	if len(paired.Participants) != 1 || paired.Participants[0].Actor != "bob" {
+		t.Fatalf("participants = %+v, want co-author bob", paired.Participants)
+	}
+	bob := paired.Participants[0]
+	if math.Abs(bob.CoAuthoredHours-devHours/2) > 1e-9 || bob.TotalHours != bob.CoAuthoredHours {
+		t.Errorf("bob co-authored hours = %v (total %v), want %v", bob.CoAuthoredHours, bob.TotalHours, devHours/2)
+	}
+	// At equal salaries, splitting moves cost between people without changing the total
+	if math.Abs(paired.TotalCost-solo.TotalCost) > 1e-6 {
+		t.Errorf("TotalCost = %v, want unchanged %v", paired.TotalCost, solo.TotalCost)
+	}
+
+	// Each co-author's share is costed at their own salary
+	cfg.SalaryOverrides = map[string]float64{"bob": cfg.AnnualSalary * 2}
+	if got := Calculate(pr("bob"), cfg); math.Abs(got.Participants[0].CoAuthoredCost-2*bob.CoAuthoredCost) > 1e-6 {
+		t.Errorf("bob co-authored cost at double salary = %v, want %v", got.Participants[0].CoAuthoredCost, 2*bob.CoAuthoredCost)
+	}
+}
+
+func TestCoAuthorShares(t *testing.T) {
+	now := time.Now()
+	data := PRData{
+		Author: "alice",
+		Events: []ParticipantEvent{
+			{Timestamp: now, Actor: "alice", Kind: "commit", CoAuthors: []string{"bob", "carol", "Bob", "ALICE"}},
+			{Timestamp: now, Actor: "alice", Kind: "commit"},
+			{Timestamp: now, Actor: "dave", Kind: "review", CoAuthors: []string{"erin"}},
+			{Timestamp: now, Actor: "Carol", Kind: "comment"},
+		},
+	}
+
+	authorShare, coAuthors := coAuthorShares(data)
+	// Two commits: one split three ways, one solo. Logins match case-insensitively, and
+	// co-authors are spelled as their own events are.
+	if math.Abs(authorShare-(0.5/3+0.5)) > 1e-9 {
+		t.Errorf("author share = %v, want %v", authorShare, 0.5/3+0.5)
+	}
+	if len(coAuthors) != 2 || math.Abs(coAuthors["bob"]-0.5/3) > 1e-9 || math.Abs(coAuthors["Carol"]-0.5/3) > 1e-9 {
+		t.Errorf("co-author shares = %v, want bob and Carol at %v", coAuthors, 0.5/3)
+	}
+
+	if share, coAuthors := coAuthorShares(PRData{Author: "alice"}); share != 1 || coAuthors != nil {
+		t.Errorf("no commits: shares = %v, %v; want 1, nil", share, coAuthors)
+	}
+}
+
+func TestExtrapolateCountsCoAuthors(t *testing.T) {
+	created := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	data := NewPRData("alice", created, created.Add(2*time.Hour), true, 400, 0, []ParticipantEvent{
+		{Timestamp: created, Actor: "alice", Kind: "commit", CoAuthors: []string{"bob"}},
+	})
+	cfg := DefaultConfig()
+
+	ext := ExtrapolateFromSamples([]Breakdown{Calculate(data, cfg)}, 10, 2, 0, 30, cfg, nil, nil)
+	if ext.UniqueAuthors != 2 {
+		t.Errorf("UniqueAuthors = %d, want 2 (author and co-author)", ext.UniqueAuthors)
+	}
+}
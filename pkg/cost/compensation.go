@@ -0,0 +1,114 @@
+package cost
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var errEmptyCompensation = errors.New("compensation file has no entries")
+
+// ParseCompensationCSV reads "login,annual_salary[,currency]" rows into maps suitable for
+// Config.SalaryOverrides and Config.SalaryCurrencies. Logins are lowercased and currency codes
+// uppercased; rows without a currency are in Config.ReportingCurrency. A leading header row
+// starting with "login" is skipped, as are blank lines and lines starting with '#'.
+//
+// Compensation data is sensitive: errors identify rows by line number only and
+// never include logins or salaries.
+func ParseCompensationCSV(r io.Reader) (salaries map[string]float64, currencies map[string]string, err error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	salaries = make(map[string]float64)
+	currencies = make(map[string]string)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, nil, fmt.Errorf("compensation file line %d: malformed CSV", parseErr.Line)
+			}
+			return nil, nil, fmt.Errorf("reading compensation file: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if len(record) != 2 && len(record) != 3 {
+			return nil, nil, fmt.Errorf("compensation file line %d: expected 2 or 3 columns (login,annual_salary[,currency]), got %d", line, len(record))
+		}
+		login := strings.ToLower(strings.TrimSpace(record[0]))
+		if line == 1 && login == "login" {
+			continue // Header
+		}
+		if login == "" {
+			return nil, nil, fmt.Errorf("compensation file line %d: empty login", line)
+		}
+		salary, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil || salary <= 0 {
+			return nil, nil, fmt.Errorf("compensation file line %d: annual salary must be a positive number", line)
+		}
+		if _, dup := salaries[login]; dup {
+			return nil, nil, fmt.Errorf("compensation file line %d: duplicate login", line)
+		}
+		salaries[login] = salary
+		if len(record) == 3 {
+			currency, err := normalizeCurrency(record[2])
+			if err != nil {
+				return nil, nil, fmt.Errorf("compensation file line %d: %w", line, err)
+			}
+			currencies[login] = currency
+		}
+	}
+
+	if len(salaries) == 0 {
+		return nil, nil, errEmptyCompensation
+	}
+	return salaries, currencies, nil
+}
+
+// ParseCompensationJSON reads a JSON object mapping login to annual salary, such as
+// {"alice": 300000, "bob": 150000}, into a map suitable for Config.SalaryOverrides.
+// Logins are lowercased; salaries are in Config.ReportingCurrency.
+//
+// As with ParseCompensationCSV, errors never include logins or salaries.
+func ParseCompensationJSON(r io.Reader) (map[string]float64, error) {
+	var raw map[string]float64
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errEmptyCompensation
+		}
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, fmt.Errorf("compensation file offset %d: malformed JSON", syntaxErr.Offset)
+		}
+		return nil, errors.New("compensation file: expected a JSON object mapping login to annual salary")
+	}
+
+	salaries := make(map[string]float64, len(raw))
+	for login, salary := range raw {
+		key := strings.ToLower(strings.TrimSpace(login))
+		if key == "" {
+			return nil, errors.New("compensation file: empty login")
+		}
+		if salary <= 0 {
+			return nil, errors.New("compensation file: annual salaries must be positive numbers")
+		}
+		if _, dup := salaries[key]; dup {
+			return nil, errors.New("compensation file: duplicate login (logins are case-insensitive)")
+		}
+		salaries[key] = salary
+	}
+
+	if len(salaries) == 0 {
+		return nil, errEmptyCompensation
+	}
+	return salaries, nil
+}
@@ -0,0 +1,213 @@
+package cost
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCompensationCSV(t *testing.T) {
+	input := `login,annual_salary
+# contractors
+Alice, 300000
+bob,150000.50
+
+carol,90000
+`
+	salaries, currencies, err := ParseCompensationCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseCompensationCSV() error: %v", err)
+	}
+
+	want := map[string]float64{"alice": 300000, "bob": 150000.50, "carol": 90000}
+	if len(salaries) != len(want) {
+		t.Fatalf("Expected %d entries, got %d", len(want), len(salaries))
+	}
+	for login, salary := range want {
+		if salaries[login] != salary {
+			t.Errorf("salaries[%q] = %v, want %v", login, salaries[login], salary)
+		}
+	}
+	if len(currencies) != 0 {
+		t.Errorf("Expected no currencies without a currency column, got %v", currencies)
+	}
+}
+
+func TestParseCompensationCSVCurrency(t *testing.T) {
+	salaries, currencies, err := ParseCompensationCSV(strings.NewReader("alice,90000,eur\nbob,150000\n"))
+	if err != nil {
+		t.Fatalf("ParseCompensationCSV() error: %v", err)
+	}
+	if salaries["alice"] != 90000 || salaries["bob"] != 150000 {
+		t.Errorf("salaries = %v", salaries)
+	}
+	if len(currencies) != 1 || currencies["alice"] != "EUR" {
+		t.Errorf("currencies = %v, want alice: EUR", currencies)
+	}
+}
+
+func TestParseCompensationCSVErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"header only", "login,annual_salary\n"},
+		{"missing column", "alice\n"},
+		{"extra column", "alice,100000,USD,x\n"},
+		{"invalid currency", "alice,100000,euros\n"},
+		{"non-numeric salary", "alice,lots\n"},
+		{"zero salary", "alice,0\n"},
+		{"negative salary", "alice,-5\n"},
+		{"empty login", ",100000\n"},
+		{"duplicate login", "alice,100000\nALICE,200000\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ParseCompensationCSV(strings.NewReader(tt.input)); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestParseCompensationCSVErrorsOmitContents(t *testing.T) {
+	_, _, err := ParseCompensationCSV(strings.NewReader("secretlogin,123456\nother,notanumber\n"))
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+	if strings.Contains(err.Error(), "secretlogin") || strings.Contains(err.Error(), "notanumber") {
+		t.Errorf("Error leaks compensation data: %v", err)
+	}
+	if !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected error to identify line 2, got: %v", err)
+	}
+}
+
+func TestParseCompensationJSON(t *testing.T) {
+	salaries, err := ParseCompensationJSON(strings.NewReader(`{"Alice": 300000, "bob": 150000.5}`))
+	if err != nil {
+		t.Fatalf("ParseCompensationJSON() error: %v", err)
+	}
+	if len(salaries) != 2 || salaries["alice"] != 300000 || salaries["bob"] != 150000.5 {
+		t.Errorf("salaries = %v, want alice: 300000, bob: 150000.5", salaries)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"empty object", "{}"},
+		{"malformed", `{"alice": 1`},
+		{"not an object", "[300000]"},
+		{"string salary", `{"alice": "lots"}`},
+		{"zero salary", `{"alice": 0}`},
+		{"empty login", `{" ": 100000}`},
+		{"duplicate login", `{"alice": 100000, "ALICE": 200000}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCompensationJSON(strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if strings.Contains(err.Error(), "alice") || strings.Contains(err.Error(), "lots") {
+				t.Errorf("error %q leaks file contents", err)
+			}
+		})
+	}
+}
+
+func TestCalculateWithCompensationFile(t *testing.T) {
+	salaries, _, err := ParseCompensationCSV(strings.NewReader("login,annual_salary\nAuthor,200000\n"))
+	if err != nil {
+		t.Fatalf("ParseCompensationCSV() error: %v", err)
+	}
+
+	now := time.Now()
+	prData := PRData{
+		LinesAdded: 100,
+		Author:     "Author", // Matched case-insensitively
+		CreatedAt:  now.Add(-2 * time.Hour),
+		ClosedAt:   now,
+		Events: []ParticipantEvent{
+			{Timestamp: now.Add(-time.Hour), Actor: "Author", Kind: "commit"},
+			{Timestamp: now.Add(-30 * time.Minute), Actor: "unlisted", Kind: "review"},
+		},
+	}
+
+	cfg := DefaultConfig()
+	cfg.SalaryOverrides = salaries
+	breakdown := Calculate(prData, cfg)
+
+	if breakdown.AnnualSalary != 200000 {
+		t.Errorf("Expected listed author's salary $200,000, got $%.2f", breakdown.AnnualSalary)
+	}
+	wantRate := 200000 * cfg.BenefitsMultiplier / cfg.HoursPerYear
+	if math.Abs(breakdown.HourlyRate-wantRate) > 0.001 {
+		t.Errorf("HourlyRate = %.2f, want %.2f", breakdown.HourlyRate, wantRate)
+	}
+
+	// Unlisted reviewer falls back to the default salary
+	baseline := Calculate(prData, DefaultConfig())
+	if len(breakdown.Participants) != 1 || len(baseline.Participants) != 1 {
+		t.Fatalf("Expected 1 participant, got %d", len(breakdown.Participants))
+	}
+	if math.Abs(breakdown.Participants[0].TotalCost-baseline.Participants[0].TotalCost) > 0.001 {
+		t.Errorf("Unlisted participant cost = $%.2f, want default $%.2f",
+			breakdown.Participants[0].TotalCost, baseline.Participants[0].TotalCost)
+	}
+
+	// Listed author costs scale with their salary
+	ratio := breakdown.Author.TotalCost / baseline.Author.TotalCost
+	if math.Abs(ratio-200000/cfg.AnnualSalary) > 0.001 {
+		t.Errorf("Author cost ratio = %.4f, want %.4f", ratio, 200000/cfg.AnnualSalary)
+	}
+}
+
+func TestCalculateWithSalaryTiers(t *testing.T) {
+	now := time.Now()
+	prData := PRData{
+		LinesAdded: 100,
+		Author:     "alice",
+		CreatedAt:  now.Add(-2 * time.Hour),
+		ClosedAt:   now,
+		Events:     []ParticipantEvent{{Timestamp: now.Add(-time.Hour), Actor: "alice", Kind: "commit"}},
+	}
+	cfg := DefaultConfig()
+	cfg.MaintainerSalary = 300000
+	cfg.ContributorSalary = 100000
+
+	tests := []struct {
+		name        string
+		writeAccess int
+		overrides   map[string]float64
+		want        float64
+	}{
+		{"maintainer", 2, nil, 300000},
+		{"likely maintainer", 1, nil, 300000},
+		{"external contributor", -1, nil, 100000},
+		{"unknown access", 0, nil, cfg.AnnualSalary},
+		{"override wins", 2, map[string]float64{"alice": 200000}, 200000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := prData
+			data.AuthorWriteAccess = tt.writeAccess
+			c := cfg
+			c.SalaryOverrides = tt.overrides
+			if got := Calculate(data, c).AnnualSalary; got != tt.want {
+				t.Errorf("AnnualSalary = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Without tiers, write access doesn't matter
+	prData.AuthorWriteAccess = -1
+	if got := Calculate(prData, DefaultConfig()).AnnualSalary; got != DefaultConfig().AnnualSalary {
+		t.Errorf("AnnualSalary without tiers = %v, want %v", got, DefaultConfig().AnnualSalary)
+	}
+}
@@ -0,0 +1,135 @@
+package cost
+
+import "github.com/codeGROOVE-dev/prcost/pkg/cocomo"
+
+// ConfigField describes a Config field for API consumers, such as a UI generating a tuning form.
+//
+//nolint:govet // fieldalignment: API struct field order optimized for readability
+type ConfigField struct {
+	Name        string   `json:"name"`           // Field name as used in a JSON config, e.g. "AnnualSalary" or "COCOMO.Exponent"
+	Type        string   `json:"type"`           // number, integer, boolean, duration, string, list, map or object
+	Unit        string   `json:"unit,omitempty"` // Unit of the value; durations are nanoseconds in JSON
+	Description string   `json:"description"`
+	Min         *float64 `json:"min,omitempty"` // Smallest meaningful value, if bounded
+	Max         *float64 `json:"max,omitempty"` // Largest meaningful value, if bounded
+}
+
+func bound(v float64) *float64 {
+	return &v
+}
+
+// configFields is the metadata for every Config field, in Config's order. Keep it in sync
+// with Config: a test fails when a field is missing here.
+var configFields = []ConfigField{
+	{Name: "AnnualSalary", Type: "number", Unit: "currency/year", Min: bound(0),
+		Description: "Annual salary used for the hourly rate, in the reporting currency"},
+	{Name: "BenefitsMultiplier", Type: "number", Unit: "multiplier", Min: bound(1),
+		Description: "Multiplier applied to salary for benefits and overhead (1.3 = 30% benefits)"},
+	{Name: "HoursPerYear", Type: "number", Unit: "hours/year", Min: bound(1), Max: bound(8760),
+		Description: "Working hours per year, for the hourly rate"},
+	{Name: "EventDuration", Type: "duration", Unit: "nanoseconds", Min: bound(0),
+		Description: "Time charged per GitHub event"},
+	{Name: "ContextSwitchInDuration", Type: "duration", Unit: "nanoseconds", Min: bound(0),
+		Description: "Time to context switch into a new session"},
+	{Name: "ContextSwitchOutDuration", Type: "duration", Unit: "nanoseconds", Min: bound(0),
+		Description: "Time to context switch out at the end of a session"},
+	{Name: "SessionGapThreshold", Type: "duration", Unit: "nanoseconds", Min: bound(0),
+		Description: "Events closer together than this are part of the same session"},
+	{Name: "EventBurstWindow", Type: "duration", Unit: "nanoseconds", Min: bound(0),
+		Description: "Same-kind events within this window of a charged one are charged once (0 charges every event)"},
+	{Name: "DeliveryDelayFactor", Type: "number", Unit: "fraction of hourly rate", Min: bound(0), Max: bound(1),
+		Description: "Opportunity cost of blocked value delivery while a PR is open"},
+	{Name: "AutomatedUpdatesFactor", Type: "number", Unit: "fraction of hourly rate", Min: bound(0), Max: bound(1),
+		Description: "Overhead of tracking bot-authored PRs while they are open"},
+	{Name: "PRTrackingMinutesPerDay", Type: "number", Unit: "minutes/day", Min: bound(0),
+		Description: "Triage time each effective tracker spends per open PR per day"},
+	{Name: "MinDelayThresholdMinutes", Type: "number", Unit: "minutes", Min: bound(0),
+		Description: "PRs open less than this incur no delay cost"},
+	{Name: "MaxDelayAfterLastEvent", Type: "duration", Unit: "nanoseconds", Min: bound(0),
+		Description: "Delivery delay counts at most this long after a PR's last event"},
+	{Name: "MaxProjectDelay", Type: "duration", Unit: "nanoseconds", Min: bound(0),
+		Description: "Absolute cap on delivery delay and automated updates time"},
+	{Name: "MaxCodeDrift", Type: "duration", Unit: "nanoseconds", Min: bound(0),
+		Description: "Cap on code drift, measured from the author's last commit"},
+	{Name: "MaxPRCodeCost", Type: "number", Unit: "currency", Min: bound(0),
+		Description: "Cap on a PR's new development plus adaptation cost, against huge generated PRs (0 = no cap)"},
+	{Name: "ReviewInspectionRate", Type: "number", Unit: "LOC/hour", Min: bound(1),
+		Description: "Lines of code reviewed per hour, for review and future review costs"},
+	{Name: "ReviewInspectionRates", Type: "map", Unit: "LOC/hour",
+		Description: "Inspection rate by file category: code, config, docs or generated (null = ReviewInspectionRate for every line)"},
+	{Name: "ReviewerDecayFactor", Type: "number", Unit: "multiplier", Min: bound(0), Max: bound(1),
+		Description: "Review cost of each later reviewer relative to the previous one (1 = every reviewer pays in full)"},
+	{Name: "ReReviewFactor", Type: "number", Unit: "multiplier", Min: bound(0), Max: bound(1),
+		Description: "Review cost of each review round after a reviewer's first, relative to the previous round (0 = first round only)"},
+	{Name: "ReviewWaitFactor", Type: "number", Unit: "fraction of hourly rate", Min: bound(0), Max: bound(1),
+		Description: "Fraction of the author's rate charged to a requested reviewer per hour the author waited for them to respond (0 disables)"},
+	{Name: "DeliveryDelayCapacityFraction", Type: "number", Unit: "fraction of payroll", Min: bound(0),
+		Description: "Caps extrapolated delivery delay at this fraction of the authors' payroll (0 disables the cap)"},
+	{Name: "MinReviewMinutes", Type: "number", Unit: "minutes", Min: bound(0),
+		Description: "Floor on each reviewer's LOC-based review time"},
+	{Name: "ConflictResolutionMinutes", Type: "number", Unit: "minutes", Min: bound(0),
+		Description: "Author time per base-branch merge followed by further commits (0 disables)"},
+	{Name: "ModificationCostFactor", Type: "number", Unit: "multiplier", Min: bound(0), Max: bound(1),
+		Description: "Cost of modified code relative to new code"},
+	{Name: "WeeklyChurnRate", Type: "number", Unit: "probability/week", Min: bound(0), Max: bound(1),
+		Description: "Probability that code becomes stale per week, for code churn"},
+	{Name: "TargetMergeTimeHours", Type: "number", Unit: "hours", Min: bound(0),
+		Description: "Target merge time for modeling potential savings"},
+	{Name: "GradeVelocityByMedian", Type: "boolean",
+		Description: "Grade extrapolated merge velocity on the sampled median open time instead of the average"},
+	{Name: "FiscalYearStartMonth", Type: "integer", Unit: "month", Min: bound(0), Max: bound(12),
+		Description: "Month the fiscal year starts in, for fiscal projections (0 = calendar annualization)"},
+	{Name: "ExcludeGeneratedFromCost", Type: "boolean",
+		Description: "Leave generated and vendored lines out of development and review costs"},
+	{Name: "EstimateMissingEvents", Type: "boolean",
+		Description: "Synthesize an author commit for PRs with lines of code but no events"},
+	{Name: "ReviewEventsHaveDuration", Type: "boolean",
+		Description: "Charge review events EventDuration on top of the LOC-based review cost"},
+	{Name: "RequireWaitingEvidence", Type: "boolean",
+		Description: "Charge delivery delay only once someone other than the author engaged"},
+	{Name: "CountDraftTime", Type: "boolean",
+		Description: "Charge delivery delay for time a PR spent as a draft"},
+	{Name: "MaxWaitingMultiplier", Type: "number", Unit: "multiplier", Min: bound(1),
+		Description: "Cap on scaling delivery delay by the number of people waiting (1 disables scaling)"},
+	{Name: "CountBotParticipantCosts", Type: "boolean",
+		Description: "Charge human reviews, merges and tracking on bot-authored PRs; when false, bot PRs cost only the automated updates overhead"},
+	{Name: "IncludeFutureCosts", Type: "boolean",
+		Description: "Charge open PRs for code churn and the review, merge and context switching still ahead; when false, totals cover only incurred cost"},
+	{Name: "SessionModel", Type: "string",
+		Description: "How context switching is charged across sessions: gap-aware (capped by the gap between sessions) or flat"},
+	{Name: "IgnoredEventKinds", Type: "list",
+		Description: "Event kinds left out of activity and session costs, e.g. labeled"},
+	{Name: "WorkingCalendar", Type: "object",
+		Description: "Working days, hours and time zone; delivery delay counts only working hours (null = every hour)"},
+	{Name: "ChangeTypes", Type: "map",
+		Description: "Label or title prefix to change type, for per-type rollups (null = built-in mapping)"},
+	{Name: "ZombieMinAge", Type: "duration", Unit: "nanoseconds", Min: bound(0),
+		Description: "How long a PR must be open before it can be a zombie"},
+	{Name: "ZombieStaleAfter", Type: "duration", Unit: "nanoseconds", Min: bound(0),
+		Description: "Time without commits or reviews after which an old open PR is a zombie"},
+	{Name: "SalaryOverrides", Type: "map", Unit: "currency/year",
+		Description: "Annual salary by GitHub login, for people paid differently from AnnualSalary"},
+	{Name: "MaintainerSalary", Type: "number", Unit: "currency/year", Min: bound(0),
+		Description: "Salary for authors with write access (0 = AnnualSalary)"},
+	{Name: "ContributorSalary", Type: "number", Unit: "currency/year", Min: bound(0),
+		Description: "Salary for authors without write access (0 = AnnualSalary)"},
+	{Name: "SalaryCurrencies", Type: "map",
+		Description: "Currency of each SalaryOverrides entry by GitHub login"},
+	{Name: "ExchangeRates", Type: "map", Unit: "reporting currency per unit",
+		Description: "Value of one unit of each currency in the reporting currency"},
+	{Name: "ReportingCurrency", Type: "string",
+		Description: "Currency costs are computed and reported in"},
+	{Name: "COCOMO.Multiplier", Type: "number", Unit: "multiplier", Min: bound(0),
+		Description: "COCOMO II base effort coefficient for development cost"},
+	{Name: "COCOMO.Exponent", Type: "number", Unit: "exponent", Min: bound(0), Max: bound(cocomo.MaxExponent),
+		Description: "COCOMO II scale exponent for development cost"},
+	{Name: "COCOMO.MinimumEffort", Type: "duration", Unit: "nanoseconds", Min: bound(0),
+		Description: "Minimum development effort charged per PR"},
+}
+
+// ConfigFields returns the name, type, unit, bounds and description of every Config field.
+func ConfigFields() []ConfigField {
+	fields := make([]ConfigField, len(configFields))
+	copy(fields, configFields)
+	return fields
+}
@@ -0,0 +1,102 @@
+package cost
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfigFieldsCoverConfig(t *testing.T) {
+	want := make(map[string]reflect.Type)
+	var collect func(prefix string, typ reflect.Type)
+	collect = func(prefix string, typ reflect.Type) {
+		for i := range typ.NumField() {
+			f := typ.Field(i)
+			if prefix == "" && f.Type.Kind() == reflect.Struct && f.Type != reflect.TypeFor[time.Duration]() {
+				collect(f.Name+".", f.Type)
+				continue
+			}
+			want[prefix+f.Name] = f.Type
+		}
+	}
+	collect("", reflect.TypeFor[Config]())
+
+	seen := make(map[string]bool)
+	for _, field := range ConfigFields() {
+		typ, ok := want[field.Name]
+		if !ok {
+			t.Errorf("ConfigFields has %q, which is not a Config field", field.Name)
+			continue
+		}
+		if seen[field.Name] {
+			t.Errorf("ConfigFields lists %q twice", field.Name)
+		}
+		seen[field.Name] = true
+		if field.Description == "" {
+			t.Errorf("%s has no description", field.Name)
+		}
+		if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+			t.Errorf("%s min %v > max %v", field.Name, *field.Min, *field.Max)
+		}
+		var kind string
+		switch {
+		case typ == reflect.TypeFor[time.Duration]():
+			kind = "duration"
+		case typ.Kind() == reflect.Float64:
+			kind = "number"
+		case typ.Kind() == reflect.Int:
+			kind = "integer"
+		case typ.Kind() == reflect.Bool:
+			kind = "boolean"
+		case typ.Kind() == reflect.String:
+			kind = "string"
+		case typ.Kind() == reflect.Slice:
+			kind = "list"
+		case typ.Kind() == reflect.Map:
+			kind = "map"
+		default:
+			kind = "object"
+		}
+		if field.Type != kind {
+			t.Errorf("%s type = %q, want %q", field.Name, field.Type, kind)
+		}
+	}
+	for name := range want {
+		if !seen[name] {
+			t.Errorf("Config field %s has no ConfigFields entry", name)
+		}
+	}
+}
+
+func TestConfigFieldsDefaultsWithinBounds(t *testing.T) {
+	cfg := reflect.ValueOf(DefaultConfig())
+	for _, field := range ConfigFields() {
+		if field.Type != "number" && field.Type != "integer" && field.Type != "duration" {
+			continue
+		}
+		v := cfg.FieldByName(field.Name)
+		if !v.IsValid() {
+			v = cfg.FieldByName("COCOMO").FieldByName(field.Name[len("COCOMO."):])
+		}
+		var value float64
+		if v.Kind() == reflect.Float64 {
+			value = v.Float()
+		} else {
+			value = float64(v.Int())
+		}
+		if field.Min != nil && value < *field.Min {
+			t.Errorf("default %s = %v, below min %v", field.Name, value, *field.Min)
+		}
+		if field.Max != nil && value > *field.Max {
+			t.Errorf("default %s = %v, above max %v", field.Name, value, *field.Max)
+		}
+	}
+}
+
+func TestConfigFieldsReturnsCopy(t *testing.T) {
+	fields := ConfigFields()
+	fields[0].Name = "changed"
+	if ConfigFields()[0].Name == "changed" {
+		t.Error("ConfigFields returned the shared table")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"math"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/codeGROOVE-dev/prcost/pkg/cocomo"
@@ -17,56 +18,82 @@ type Config struct {
 	// Annual salary used for calculating hourly rate (default: $249,000)
 	// Source: Average Staff Software Engineer salary, 2025 Glassdoor
 	// https://www.glassdoor.com/Salaries/staff-software-engineer-salary-SRCH_KO0,23.htm
-	AnnualSalary float64
+	AnnualSalary float64 `yaml:"annual_salary"`
 
 	// Benefits multiplier applied to salary (default: 1.3 = 30% benefits)
-	BenefitsMultiplier float64
+	BenefitsMultiplier float64 `yaml:"benefits_multiplier"`
 
 	// Hours per year for calculating hourly rate (default: 2080)
-	HoursPerYear float64
+	HoursPerYear float64 `yaml:"hours_per_year"`
 
 	// Time per GitHub event (default: 10 minutes)
-	EventDuration time.Duration
+	EventDuration time.Duration `yaml:"event_duration"`
 
 	// Time for context switching in - starting a new session (default: 3 minutes)
 	// Source: Microsoft Research - Iqbal & Horvitz (2007)
 	// "Disruption and Recovery of Computing Tasks: Field Study, Analysis, and Directions"
 	// https://erichorvitz.com/CHI_2007_Iqbal_Horvitz.pdf
-	ContextSwitchInDuration time.Duration
+	ContextSwitchInDuration time.Duration `yaml:"context_switch_in_duration"`
 
 	// Time for context switching out - ending a session (default: 16 minutes 33 seconds)
 	// Source: Microsoft Research - Iqbal & Horvitz (2007)
 	// "Disruption and Recovery of Computing Tasks: Field Study, Analysis, and Directions"
 	// https://erichorvitz.com/CHI_2007_Iqbal_Horvitz.pdf
-	ContextSwitchOutDuration time.Duration
+	ContextSwitchOutDuration time.Duration `yaml:"context_switch_out_duration"`
 
 	// Session gap threshold (default: 20 minutes)
 	// Events within this gap are considered part of the same session
-	SessionGapThreshold time.Duration
+	SessionGapThreshold time.Duration `yaml:"session_gap_threshold"`
+
+	// EventBurstWindow collapses bursts of same-kind events by one person within a
+	// session into a single charge (default: 0, off). An event is charged EventDuration only
+	// if no event of its kind was charged within this window before it, so thirty inline
+	// comments left in two minutes cost one event, not thirty. The events still count toward
+	// sessions and context switching.
+	EventBurstWindow time.Duration `yaml:"event_burst_window"`
 
 	// Delivery delay factor as percentage of hourly rate (default: 0.15 = 15%)
 	// Represents opportunity cost of blocked value delivery
-	DeliveryDelayFactor float64
+	DeliveryDelayFactor float64 `yaml:"delivery_delay_factor"`
 
 	// Automated updates factor for bot-authored PRs (default: 0.01 = 1%)
 	// Represents overhead of tracking automated dependency updates and bot-driven changes
-	AutomatedUpdatesFactor float64
-
-	// PR tracking cost in minutes per day (default: 1.0 minute per day)
-	// Applied to PRs open >24 hours to represent ongoing triage/tracking overhead
-	PRTrackingMinutesPerDay float64
+	AutomatedUpdatesFactor float64 `yaml:"automated_updates_factor"`
+
+	// PRTrackingMinutesPerDay is the planning/triage time, in minutes, that each effective tracker
+	// spends per open PR per day (default: 0.3 minutes = 18 seconds).
+	// The number of effective trackers grows logarithmically with the number of people involved
+	// (log2(people + 1)), so the same model is used for a single PR and for an organization:
+	//   tracking_hours = openPRs × log2(people + 1) × PRTrackingMinutesPerDay / 60 × days
+	// 0.3 is the 0.005 hours/day constant the organization-wide estimate has always used, so
+	// extrapolated reports are unchanged. Single-PR estimates used a flat 10 seconds/day before;
+	// a PR with only its author now costs 18 seconds/day, and more for each participant.
+	// See PRTrackingHours.
+	PRTrackingMinutesPerDay float64 `yaml:"pr_tracking_minutes_per_day"`
+
+	// MinDelayThresholdMinutes is how long a PR must be open before it incurs any delay cost (default: 30 minutes)
+	// PRs merged faster than this have no meaningful delay or coordination overhead.
+	MinDelayThresholdMinutes float64 `yaml:"min_delay_threshold_minutes"`
 
 	// Maximum time after last event to count for project delay (default: 14 days / 2 weeks)
 	// Only counts delay costs up to this many days after the last event on the PR
-	MaxDelayAfterLastEvent time.Duration
+	MaxDelayAfterLastEvent time.Duration `yaml:"max_delay_after_last_event"`
 
 	// Maximum total project delay duration (default: 90 days / 3 months)
-	// Absolute cap on project delay costs regardless of PR age
-	MaxProjectDelay time.Duration
+	// Absolute cap on delivery delay (and bot automated updates) regardless of PR age.
+	// Applied after MaxDelayAfterLastEvent; it does not affect code churn.
+	MaxProjectDelay time.Duration `yaml:"max_project_delay"`
 
 	// Maximum duration for code drift calculation (default: 90 days / 3 months)
-	// Code drift is capped at this duration (affects rework percentage)
-	MaxCodeDrift time.Duration
+	// Code drift is measured from the author's last commit and capped at this duration
+	// (affects rework percentage). Independent of MaxProjectDelay; see CapAppliedTo.
+	MaxCodeDrift time.Duration `yaml:"max_code_drift"`
+
+	// MaxPRCodeCost caps a PR's new development plus adaptation cost, in the reporting currency
+	// (default: 0 = no cap). COCOMO grows super-linearly with lines of code, so a single huge
+	// vendored or generated PR can otherwise dominate an extrapolated total. Both components
+	// are scaled down together, and CapAppliedTo.CodeCost marks capped PRs.
+	MaxPRCodeCost float64 `yaml:"max_pr_code_cost"`
 
 	// Code review inspection rate in lines per hour (default: 275 LOC/hour)
 	// Based on IEEE/Fagan inspection research showing optimal rates of 150-400 LOC/hour
@@ -76,7 +103,59 @@ type Config struct {
 	// - Average: 275 LOC/hour (midpoint of optimal range)
 	// Used for both past and future review time estimates
 	// Formula: review_hours = LOC / inspection_rate
-	ReviewInspectionRate float64
+	ReviewInspectionRate float64 `yaml:"review_inspection_rate"`
+
+	// ReviewInspectionRates sets inspection rates in lines per hour by file category: "code",
+	// "config", "docs" or "generated" (see FileCategory) (default: nil = ReviewInspectionRate for
+	// every line). 500 lines of YAML review far faster than 500 lines of dense code. It applies
+	// when PRData.LinesByCategory is known; other lines use ReviewInspectionRate.
+	ReviewInspectionRates map[string]float64 `yaml:"review_inspection_rates"`
+
+	// ReviewerDecayFactor scales LOC-based review cost by reviewer order (default: 1.0 = equal treatment)
+	// The first substantive reviewer typically does the heavy lifting while later reviewers do lighter
+	// passes. Reviewers are ordered by their first review timestamp; the Nth reviewer (0-indexed) is
+	// charged ReviewerDecayFactor^N of the full review cost.
+	// - 1.0: every reviewer pays full review cost
+	// - 0.5: first reviewer 100%, second 50%, third 25%, ...
+	// Values <= 0 are treated as 1.0.
+	ReviewerDecayFactor float64 `yaml:"reviewer_decay_factor"`
+
+	// ReReviewFactor scales the review cost of each review round after a reviewer's first
+	// (default: 0 = only the first round is charged). A round is a reviewer's review submissions
+	// between commits; later rounds are faster because the reviewer already knows the code.
+	// Round N (0-indexed) is charged ReReviewFactor^N of the first round's cost.
+	// - 0.5: first round 100%, second 50%, third 25%, ...
+	// - 1.0: every round pays full review cost
+	ReReviewFactor float64 `yaml:"re_review_factor"`
+
+	// ReviewWaitFactor is the fraction of the author's hourly rate charged to a requested reviewer
+	// for each hour the author waited on them (default: 0 = off; 0.05 = 5%). The wait runs from a review
+	// request to the reviewer's first review or comment, or to the request's removal or the end
+	// of the PR if they never responded, capped at MaxProjectDelay and counted in working hours
+	// when WorkingCalendar is set. Each re-request after a response starts a new wait. The cost
+	// is preventable waste, and tells "reviewer slow" apart from "author slow". It is charged on
+	// top of delivery delay, which already covers the same hours, so it is opt-in: turning it on
+	// by default would raise every PR's total. Values <= 0 disable it.
+	ReviewWaitFactor float64 `yaml:"review_wait_factor"`
+
+	// DeliveryDelayCapacityFraction caps extrapolated delivery delay at a fraction of the org's capacity
+	// (default: 0 = no cap; 1.0 = the whole payroll). Summing per-PR delivery delay can exceed what the
+	// team's payroll could absorb when many PRs sit open concurrently. Capacity is authors × HoursPerYear ×
+	// (days / 365) × hourly rate for the analysis window. Only applies to ExtrapolateFromSamples.
+	DeliveryDelayCapacityFraction float64 `yaml:"delivery_delay_capacity_fraction"`
+
+	// MinReviewMinutes floors each reviewer's LOC-based review time (default: 0 = no floor)
+	// Any review carries fixed overhead (opening the PR, reading context, deciding) regardless of size,
+	// so without a floor a 2-line PR gets a near-zero review cost. The floor applies after
+	// ReviewerDecayFactor, and to the future review of open PRs.
+	MinReviewMinutes float64 `yaml:"min_review_minutes"`
+
+	// ConflictResolutionMinutes is the author time charged each time the base branch is merged into
+	// the PR and further changes follow (default: 0 = off; opt in with e.g. 30 minutes).
+	// That sequence is a proxy for resolving merge conflicts: integration rework on long-lived
+	// branches that isn't reflected in the PR's LOC. It is opt-in so that turning it on doesn't
+	// silently change historical totals. Values <= 0 disable the cost.
+	ConflictResolutionMinutes float64 `yaml:"conflict_resolution_minutes"`
 
 	// ModificationCostFactor is the cost multiplier for modified code vs new code (default: 0.4)
 	// Based on COCOMO II research showing that modifying existing code is cheaper than writing new code.
@@ -84,7 +163,7 @@ type Config struct {
 	// - Modified code: 0.2-0.4x (20-40% of new code cost)
 	// Default of 0.4 (40%) represents the upper end of the typical range.
 	// Modification is cheaper because architecture is established and patterns are known.
-	ModificationCostFactor float64
+	ModificationCostFactor float64 `yaml:"modification_cost_factor"`
 
 	// WeeklyChurnRate is the probability that code becomes stale per week (default: 0.0229 = 2.29%)
 	// Used to calculate rework percentage for open PRs based on time since last commit.
@@ -107,80 +186,292 @@ type Config struct {
 	// - 0.030 (3.0%/week) → 78% annual churn - fast-moving projects
 	// - 0.040 (4.0%/week) → 88% annual churn - very high churn
 	// - 0.080+ (8%+/week) → 99%+ annual churn - extremely fast-moving, younger companies
-	WeeklyChurnRate float64
+	WeeklyChurnRate float64 `yaml:"weekly_churn_rate"`
 
 	// TargetMergeTimeHours is the target merge time in hours for efficiency modeling (default: 1.5 hours / 90 minutes)
 	// Used to calculate potential savings if merge times were reduced to this target.
 	// This represents a realistic goal for well-optimized PR workflows.
-	TargetMergeTimeHours float64
+	TargetMergeTimeHours float64 `yaml:"target_merge_time_hours"`
+
+	// GradeVelocityByMedian grades extrapolated merge velocity on the sampled PRs' median (p50)
+	// open time instead of the average open time of all PRs (default: false). A few stuck PRs
+	// can drag the average far above what most PRs take; the median matches percentile SLOs.
+	GradeVelocityByMedian bool `yaml:"grade_velocity_by_median"`
+
+	// FiscalYearStartMonth is the month (1-12) the fiscal year starts in (default: 0 = calendar annualization)
+	// When set, sustained-waste projections are reported per fiscal quarter and fiscal year
+	// (e.g. "FY25 Q2") instead of a flat 365-day annualization. See ProjectFiscalPeriods.
+	FiscalYearStartMonth int `yaml:"fiscal_year_start_month"`
+
+	// ExcludeGeneratedFromCost excludes PRData.GeneratedLines (generated and vendored code) from
+	// development and review costs (default: true). Set to false to cost every added line.
+	ExcludeGeneratedFromCost bool `yaml:"exclude_generated_from_cost"`
+
+	// EstimateMissingEvents synthesizes a single author commit at CreatedAt for PRs that
+	// have lines of code but no events at all (default: false).
+	// Such PRs are almost always the result of a data-fetch gap, and would otherwise be
+	// charged no GitHub activity or session cost. When false, the anomaly is only logged
+	// and flagged on the Breakdown.
+	EstimateMissingEvents bool `yaml:"estimate_missing_events"`
+
+	// ReviewEventsHaveDuration charges review and review_comment events EventDuration of
+	// GitHub activity like any other event (default: false). By default they count toward
+	// sessions and context switching but take no time, since review effort is costed from
+	// lines of code. Enable it when deep reviews of small PRs would otherwise be undercounted;
+	// the LOC-based review cost still applies on top.
+	ReviewEventsHaveDuration bool `yaml:"review_events_have_duration"`
+
+	// RequireWaitingEvidence charges delivery delay only while someone was demonstrably waiting
+	// on the PR: from its first review request or first event by someone other than the author
+	// (default: false). PRs only their author ever touched get no delivery delay, since they
+	// may be speculative work that blocked nobody. Code churn and other delay costs are unchanged.
+	RequireWaitingEvidence bool `yaml:"require_waiting_evidence"`
+
+	// CountDraftTime charges delivery delay for the time a PR opened as a draft spent before it
+	// was first ready for review (default: false). Drafts are still being written and block no
+	// one, so by default delivery delay starts at the first ready_for_review event, and PRs that
+	// are still drafts get none. Code churn and other delay costs are unchanged.
+	CountDraftTime bool `yaml:"count_draft_time"`
+
+	// MaxWaitingMultiplier caps how far delivery delay scales with the number of people waiting
+	// on a PR (default: 1.0 = no scaling). People waiting are participants other than the author
+	// who reviewed or commented and then pushed no commits of their own; a PR blocking five of
+	// them costs more than one nobody is waiting on. Delivery delay is multiplied by the number
+	// of people waiting, at least 1, up to this cap. Values <= 1 disable scaling.
+	MaxWaitingMultiplier float64 `yaml:"max_waiting_multiplier"`
+
+	// CountBotParticipantCosts charges the humans on bot-authored PRs like on any other PR
+	// (default: true). Bots write their code for free, but Dependabot and Renovate PRs still take
+	// real reviewer and merger time: review, comment and context switching costs for participants,
+	// and future review, merge and tracking costs while the PR is open. When false, bot PRs cost
+	// only the AutomatedUpdatesFactor overhead.
+	CountBotParticipantCosts bool `yaml:"count_bot_participant_costs"`
+
+	// IncludeFutureCosts charges open PRs for work that hasn't happened yet (default: true): code
+	// churn from the codebase drifting under them, and the review, merge and context switching
+	// still needed to land them. When false, those costs are 0 and the total covers only cost
+	// already incurred, as finance reconciliation needs. Closed PRs never have future costs.
+	IncludeFutureCosts bool `yaml:"include_future_costs"`
+
+	// SessionModel selects how context switching is charged across sessions (default:
+	// SessionModelGapAware). Gap-aware caps the switch between two sessions at the gap between
+	// them, since nobody spends more time switching than elapsed. SessionModelFlat charges every
+	// session a full ContextSwitchInDuration plus ContextSwitchOutDuration, which is simpler to
+	// reproduce by hand. Empty means gap-aware.
+	SessionModel string `yaml:"session_model"`
+
+	// IgnoredEventKinds lists event kinds (case-insensitive) left out of GitHub activity and
+	// session costs for the author and participants (default: none). Use it for automated noise
+	// that inflates activity costs, such as "labeled", "subscribed" or "mentioned". Ignored events
+	// are dropped before participants are grouped, so someone with only ignored events costs
+	// nothing. See EventKinds for the kinds GitHub data carries.
+	IgnoredEventKinds []string `yaml:"ignored_event_kinds"`
+
+	// WorkingCalendar, when set, charges delivery delay and automated updates overhead only for
+	// working hours (default: nil = every hour counts). A PR opened Friday evening and merged Monday
+	// morning then costs no delay for the weekend. Caps still apply to elapsed time, and PRDuration,
+	// code churn and PR tracking are unchanged. See ParseWorkingCalendar.
+	WorkingCalendar *WorkingCalendar `yaml:"working_calendar"`
+
+	// ChangeTypes maps label names and conventional-commit title prefixes (e.g. "feat", "kind/bug")
+	// to change types such as "feature" or "chore", for per-type cost rollups. Keys are matched
+	// case-insensitively. Nil uses DefaultChangeTypes. See ClassifyChangeType.
+	ChangeTypes map[string]string `yaml:"change_types"`
+
+	// ZombieMinAge is how long a PR must be open before it can be considered a zombie (default: 30 days)
+	ZombieMinAge time.Duration `yaml:"zombie_min_age"`
+
+	// ZombieStaleAfter is how long since the last meaningful event (commit or review) before an
+	// old open PR is considered a zombie, provided it is still being occasionally poked (default: 14 days).
+	// Zombies carry ongoing tracking cost without progressing; PRs with no activity at all are
+	// abandoned rather than zombies.
+	ZombieStaleAfter time.Duration `yaml:"zombie_stale_after"`
+
+	// SalaryOverrides maps GitHub login to annual salary for people whose pay differs from
+	// AnnualSalary (default: empty). Lookups fall back to the lowercased login, then AnnualSalary.
+	// The author's salary is used for author and delay costs; each participant uses their own.
+	SalaryOverrides map[string]float64 `yaml:"salary_overrides"`
+
+	// MaintainerSalary and ContributorSalary approximate seniority from the PR author's write access
+	// (default: 0 = use AnnualSalary). Authors with write access to the repository are charged
+	// MaintainerSalary; authors without it, typically drive-by external contributors, are charged
+	// ContributorSalary. Authors with unknown write access, participants, and anyone listed in
+	// SalaryOverrides are unaffected. Both are in ReportingCurrency.
+	MaintainerSalary  float64 `yaml:"maintainer_salary"`
+	ContributorSalary float64 `yaml:"contributor_salary"`
+
+	// SalaryCurrencies maps GitHub login to the currency (e.g. "EUR") of their SalaryOverrides entry
+	// (default: empty). Salaries without a currency, and AnnualSalary, are in ReportingCurrency.
+	SalaryCurrencies map[string]string `yaml:"salary_currencies"`
+
+	// ExchangeRates maps a currency code to the value of one unit in ReportingCurrency
+	// (e.g. "EUR": 1.08 when reporting in USD). Rates are user-supplied; nothing is fetched.
+	// Salaries are converted before costing, so every cost and total is in ReportingCurrency.
+	ExchangeRates map[string]float64 `yaml:"exchange_rates"`
+
+	// ReportingCurrency is the currency costs are computed, aggregated, and reported in (default: "USD")
+	ReportingCurrency string `yaml:"reporting_currency"`
 
 	// COCOMO configuration for estimating code writing effort
-	COCOMO cocomo.Config
+	COCOMO cocomo.Config `yaml:"cocomo"`
+}
+
+// annualSalaryFor returns the annual salary for a GitHub login, in the reporting currency.
+func (c *Config) annualSalaryFor(login string) float64 {
+	if salary, ok := c.SalaryOverrides[login]; ok {
+		return c.toReportingCurrency(salary, c.currencyFor(login))
+	}
+	if salary, ok := c.SalaryOverrides[strings.ToLower(login)]; ok {
+		return c.toReportingCurrency(salary, c.currencyFor(login))
+	}
+	return c.AnnualSalary
+}
+
+// authorAnnualSalary returns the PR author's annual salary: their SalaryOverrides entry if they
+// have one, else the MaintainerSalary or ContributorSalary tier matching their write access,
+// else AnnualSalary.
+func (c *Config) authorAnnualSalary(data *PRData) float64 {
+	_, overridden := c.SalaryOverrides[data.Author]
+	if _, ok := c.SalaryOverrides[strings.ToLower(data.Author)]; ok {
+		overridden = true
+	}
+	switch {
+	case overridden:
+	case data.AuthorWriteAccess > 0 && c.MaintainerSalary > 0:
+		return c.MaintainerSalary
+	case data.AuthorWriteAccess < 0 && c.ContributorSalary > 0:
+		return c.ContributorSalary
+	}
+	return c.annualSalaryFor(data.Author)
+}
+
+// hourlyRateFor returns the fully-loaded hourly rate for a GitHub login.
+func (c *Config) hourlyRateFor(login string) float64 {
+	return c.hourlyRate(c.annualSalaryFor(login))
+}
+
+// hourlyRate returns the fully-loaded hourly rate for an annual salary.
+func (c *Config) hourlyRate(annualSalary float64) float64 {
+	return (annualSalary * c.BenefitsMultiplier) / c.HoursPerYear
 }
 
 // DefaultConfig returns reasonable defaults for cost calculation.
 func DefaultConfig() Config {
 	return Config{
-		AnnualSalary:             249000.0,                        // Average Staff Software Engineer salary (2025, Glassdoor)
-		BenefitsMultiplier:       1.3,                             // 30% benefits overhead
-		HoursPerYear:             2080.0,                          // Standard full-time hours
-		EventDuration:            10 * time.Minute,                // 10 minutes per GitHub event
-		ContextSwitchInDuration:  3 * time.Minute,                 // 3 min to context switch in (Microsoft Research)
-		ContextSwitchOutDuration: 16*time.Minute + 33*time.Second, // 16m33s to context switch out (Microsoft Research)
-		SessionGapThreshold:      20 * time.Minute,                // Events within 20 min are same session
-		DeliveryDelayFactor:      0.20,                            // 20% opportunity cost
-		AutomatedUpdatesFactor:   0.01,                            // 1% overhead for bot PRs
-		PRTrackingMinutesPerDay:  10.0 / 60.0,                     // 10 seconds/person/day per open PR
-		MaxDelayAfterLastEvent:   14 * 24 * time.Hour,             // 14 days (2 weeks) after last event
-		MaxProjectDelay:          90 * 24 * time.Hour,             // 90 days absolute max
-		MaxCodeDrift:             90 * 24 * time.Hour,             // 90 days
-		ReviewInspectionRate:     275.0,                           // 275 LOC/hour (average of optimal 150-400 range)
-		ModificationCostFactor:   0.4,                             // Modified code costs 40% of new code
-		WeeklyChurnRate:          0.0229,                          // 2.29% per week (70% annual, 60th percentile empirical)
-		TargetMergeTimeHours:     1.5,                             // 1.5 hours (90 minutes) target for efficiency modeling
-		COCOMO:                   cocomo.DefaultConfig(),
+		AnnualSalary:                  249000.0,                        // Average Staff Software Engineer salary (2025, Glassdoor)
+		BenefitsMultiplier:            1.3,                             // 30% benefits overhead
+		HoursPerYear:                  2080.0,                          // Standard full-time hours
+		EventDuration:                 10 * time.Minute,                // 10 minutes per GitHub event
+		ContextSwitchInDuration:       3 * time.Minute,                 // 3 min to context switch in (Microsoft Research)
+		ContextSwitchOutDuration:      16*time.Minute + 33*time.Second, // 16m33s to context switch out (Microsoft Research)
+		SessionGapThreshold:           20 * time.Minute,                // Events within 20 min are same session
+		EventBurstWindow:              0,                               // Every event is charged EventDuration
+		DeliveryDelayFactor:           0.20,                            // 20% opportunity cost
+		AutomatedUpdatesFactor:        0.01,                            // 1% overhead for bot PRs
+		PRTrackingMinutesPerDay:       0.3,                             // 18 seconds/tracker/day per open PR
+		MinDelayThresholdMinutes:      30,                              // No delay cost for PRs open < 30 minutes
+		MaxDelayAfterLastEvent:        14 * 24 * time.Hour,             // 14 days (2 weeks) after last event
+		MaxProjectDelay:               90 * 24 * time.Hour,             // 90 days absolute max
+		MaxCodeDrift:                  90 * 24 * time.Hour,             // 90 days
+		MaxPRCodeCost:                 0,                               // No cap on code cost per PR
+		ReviewInspectionRate:          275.0,                           // 275 LOC/hour (average of optimal 150-400 range)
+		ReviewerDecayFactor:           1.0,                             // Every reviewer pays full review cost
+		ReReviewFactor:                0,                               // Only the first review round is charged
+		ReviewWaitFactor:              0,                               // No review wait charge (delivery delay covers the wait)
+		MinReviewMinutes:              0,                               // No review time floor
+		DeliveryDelayCapacityFraction: 0,                               // No cap on extrapolated delivery delay
+		ConflictResolutionMinutes:     0,                               // No conflict resolution charge
+		ModificationCostFactor:        0.4,                             // Modified code costs 40% of new code
+		WeeklyChurnRate:               0.0229,                          // 2.29% per week (70% annual, 60th percentile empirical)
+		TargetMergeTimeHours:          1.5,                             // 1.5 hours (90 minutes) target for efficiency modeling
+		FiscalYearStartMonth:          0,                               // Calendar annualization
+		ExcludeGeneratedFromCost:      true,                            // Nobody hand-writes or reviews generated code
+		EstimateMissingEvents:         false,                           // Only warn about PRs with LOC but no events
+		ReviewEventsHaveDuration:      false,                           // Review time comes from the LOC-based model
+		RequireWaitingEvidence:        false,                           // Delivery delay covers the whole open time
+		CountDraftTime:                false,                           // Delivery delay starts once a PR is ready for review
+		MaxWaitingMultiplier:          1.0,                             // Delivery delay doesn't scale with people waiting
+		CountBotParticipantCosts:      true,                            // Humans reviewing and merging bot PRs cost real time
+		IncludeFutureCosts:            true,                            // Open PRs are charged for the churn and work still ahead
+		SessionModel:                  SessionModelGapAware,            // Context switches capped by the gap between sessions
+		ZombieMinAge:                  30 * 24 * time.Hour,             // 30 days open
+		ZombieStaleAfter:              14 * 24 * time.Hour,             // 14 days without commits or reviews
+		ReportingCurrency:             DefaultReportingCurrency,        // Salaries and costs in USD
+		COCOMO:                        cocomo.DefaultConfig(),
 	}
 }
 
 // ParticipantEvent represents a single event by a participant.
 type ParticipantEvent struct {
-	Timestamp time.Time
-	Actor     string
-	Kind      string // Event type: "commit", "review", "comment", etc.
+	Timestamp  time.Time
+	Actor      string
+	Kind       string   // Event type: "commit", "review", "comment", etc.
+	State      string   // Review outcome for "review" events: "approved", "changes_requested", "commented" or "dismissed"
+	MergesBase bool     // Commit merges the base branch into the PR branch (e.g. "Merge branch 'main'")
+	CoAuthors  []string // Co-authored-by trailers on a commit; each shares its development effort
+	Target     string   // Requested reviewer for "review_requested" and "review_request_removed" events
 }
 
 // PRData contains all information needed to calculate PR costs.
+// It can be built from any data source (see NewPRData); pkg/github fetches it from GitHub and pkg/gitlab from GitLab.
 type PRData struct {
 	CreatedAt    time.Time
 	ClosedAt     time.Time
 	Author       string
 	State        string
 	Events       []ParticipantEvent
+	Title        string   // PR title, if known (used for change type classification)
+	Labels       []string // Label names, if known (used for change type classification)
+	Files        []string // Paths of changed files, if known (used for --path filtering)
 	LinesAdded   int
 	LinesDeleted int
-	AuthorBot    bool
-	Merged       bool
+	// GeneratedLines is the part of LinesAdded in generated or vendored files (see IsGeneratedPath).
+	// It is excluded from development and review costs when Config.ExcludeGeneratedFromCost is set.
+	GeneratedLines int
+	// LinesByCategory is LinesAdded split by file category (see FileCategory), if known.
+	// It weights review time when Config.ReviewInspectionRates is set.
+	LinesByCategory map[string]int
+	AuthorBot       bool
+	Merged          bool
+	MergedAt        time.Time // Optional; zero if unknown or not merged
+	Draft           bool      // PR is currently a draft
+	// AuthorWriteAccess is the author's write access to the repository, if known: positive with
+	// write access (maintainers), negative without (external contributors), zero if unknown.
+	// It selects Config.MaintainerSalary or Config.ContributorSalary.
+	AuthorWriteAccess int
+	// ActualHours is the time actually tracked against the PR, if known. When set,
+	// Calculate reports how far the modeled hours are from it in Breakdown.Variance.
+	ActualHours float64
+	// SampleWeight is how many PRs in the population this PR stands for when it was picked by
+	// weighted sampling; zero means every sampled PR stands for an equal share. It is copied to
+	// Breakdown.SampleWeight for ExtrapolateFromSamples.
+	SampleWeight float64
 }
 
 // AuthorCostDetail breaks down the author's costs.
 type AuthorCostDetail struct {
-	NewCodeCost       float64 `json:"new_code_cost"`       // COCOMO cost for new development (net new lines)
-	AdaptationCost    float64 `json:"adaptation_cost"`     // COCOMO cost for code adaptation (modified lines)
-	GitHubCost        float64 `json:"github_cost"`         // Cost of GitHub interactions (commits, comments, etc.)
-	GitHubContextCost float64 `json:"github_context_cost"` // Cost of context switching for GitHub sessions
+	NewCodeCost            float64 `json:"new_code_cost"`            // COCOMO cost for new development (net new lines)
+	AdaptationCost         float64 `json:"adaptation_cost"`          // COCOMO cost for code adaptation (modified lines)
+	GitHubCost             float64 `json:"github_cost"`              // Cost of GitHub interactions (commits, comments, etc.)
+	GitHubContextCost      float64 `json:"github_context_cost"`      // Cost of context switching for GitHub sessions
+	ConflictResolutionCost float64 `json:"conflict_resolution_cost"` // Cost of resolving conflicts after base-branch merges
 
 	// Supporting details
-	NewLines           int     `json:"new_lines"`            // Net new lines of code
-	ModifiedLines      int     `json:"modified_lines"`       // Lines modified from existing code
-	LinesAdded         int     `json:"lines_added"`          // Total lines added (new + modified)
-	Events             int     `json:"events"`               // Number of author events
-	Sessions           int     `json:"sessions"`             // Number of GitHub work sessions
-	NewCodeHours       float64 `json:"new_code_hours"`       // Hours for new development (COCOMO)
-	AdaptationHours    float64 `json:"adaptation_hours"`     // Hours for code adaptation (COCOMO)
-	GitHubHours        float64 `json:"github_hours"`         // Hours spent on GitHub interactions
-	GitHubContextHours float64 `json:"github_context_hours"` // Hours spent context switching for GitHub
-	TotalHours         float64 `json:"total_hours"`          // Total hours (sum of above)
-	TotalCost          float64 `json:"total_cost"`           // Total author cost
+	NewLines                int     `json:"new_lines"`                 // Net new lines of code
+	ModifiedLines           int     `json:"modified_lines"`            // Lines modified from existing code
+	LinesAdded              int     `json:"lines_added"`               // Total lines added (new + modified)
+	LinesDeleted            int     `json:"lines_deleted"`             // Total lines deleted
+	GeneratedLines          int     `json:"generated_lines"`           // Added lines in generated/vendored files excluded from cost
+	Events                  int     `json:"events"`                    // Number of author events
+	Sessions                int     `json:"sessions"`                  // Number of GitHub work sessions
+	NewCodeHours            float64 `json:"new_code_hours"`            // Hours for new development (COCOMO)
+	AdaptationHours         float64 `json:"adaptation_hours"`          // Hours for code adaptation (COCOMO)
+	GitHubHours             float64 `json:"github_hours"`              // Hours spent on GitHub interactions
+	GitHubContextHours      float64 `json:"github_context_hours"`      // Hours spent context switching for GitHub
+	ConflictResolutionHours float64 `json:"conflict_resolution_hours"` // Hours spent resolving merge conflicts
+	ConflictResolutions     int     `json:"conflict_resolutions"`      // Base-branch merges followed by more changes
+	TotalHours              float64 `json:"total_hours"`               // Total hours (sum of above)
+	TotalCost               float64 `json:"total_cost"`                // Total author cost
 }
 
 // ParticipantCostDetail breaks down a participant's costs.
@@ -189,13 +480,19 @@ type ParticipantCostDetail struct {
 	ReviewCost        float64 `json:"review_cost"`         // Cost of code review (LOC-based, once per reviewer)
 	GitHubCost        float64 `json:"github_cost"`         // Cost of other GitHub events (non-review)
 	GitHubContextCost float64 `json:"github_context_cost"` // Cost of context switching for GitHub sessions
+	CoAuthoredCost    float64 `json:"co_authored_cost"`    // Share of development cost from co-authored commits
+	ReviewWaitCost    float64 `json:"review_wait_cost"`    // Share of the author's wait for this requested reviewer to respond
 
 	// Supporting details
 	Events             int     `json:"events"`               // Number of participant events
+	ReviewRounds       int     `json:"review_rounds"`        // Review rounds, separated by commits (0 for non-reviewers)
 	Sessions           int     `json:"sessions"`             // Number of GitHub work sessions
 	ReviewHours        float64 `json:"review_hours"`         // Hours spent reviewing code (LOC-based)
 	GitHubHours        float64 `json:"github_hours"`         // Hours spent on other GitHub events
 	GitHubContextHours float64 `json:"github_context_hours"` // Hours spent context switching for GitHub
+	CoAuthoredHours    float64 `json:"co_authored_hours"`    // Share of development hours from co-authored commits
+	ReviewWaitHours    float64 `json:"review_wait_hours"`    // Hours of ReviewWaitCost (ReviewWaitFactor × ReviewLatencyHours)
+	ReviewLatencyHours float64 `json:"review_latency_hours"` // Hours the author waited for this reviewer to respond to review requests
 	TotalHours         float64 `json:"total_hours"`          // Total hours (sum of above)
 	TotalCost          float64 `json:"total_cost"`           // Total participant cost
 }
@@ -208,7 +505,7 @@ type DelayCostDetail struct {
 	PRTrackingCost       float64 `json:"pr_tracking_cost"`       // Daily tracking cost for PRs open >24 hours (1 min/day)
 
 	// Future costs (estimated for open PRs) - split across 2 people
-	FutureReviewCost  float64 `json:"future_review_cost"`  // Cost for future review events (2 events × 20 min)
+	FutureReviewCost  float64 `json:"future_review_cost"`  // Cost for future review events (0 once the PR is approved)
 	FutureMergeCost   float64 `json:"future_merge_cost"`   // Cost for future merge event (1 event × 20 min)
 	FutureContextCost float64 `json:"future_context_cost"` // Cost for future context switching (3 events × 40 min)
 
@@ -223,6 +520,21 @@ type DelayCostDetail struct {
 	ReworkPercentage      float64 `json:"rework_percentage"`       // Percentage of code requiring rework (1%-41%)
 	TotalDelayCost        float64 `json:"total_delay_cost"`        // Total delay cost (sum of above)
 	TotalDelayHours       float64 `json:"total_delay_hours"`       // Total delay hours
+
+	// DeliveryDelayBasis is which time delivery delay was charged for: DelayBasisOpenTime,
+	// DelayBasisReady, DelayBasisDraft, DelayBasisWaiting or DelayBasisNoWaitingEvidence
+	// (see Config.CountDraftTime and Config.RequireWaitingEvidence).
+	// Empty for bot-authored PRs, which have no delivery delay.
+	DeliveryDelayBasis string `json:"delivery_delay_basis"`
+
+	// PeopleWaiting is the inferred number of people blocked on the PR, and WaitingMultiplier
+	// the factor it scaled delivery delay by (see Config.MaxWaitingMultiplier).
+	PeopleWaiting     int     `json:"people_waiting"`
+	WaitingMultiplier float64 `json:"waiting_multiplier"`
+
+	// FutureCostsExcluded is set when Config.IncludeFutureCosts was off, so the future costs and
+	// code churn of an open PR are 0 because they weren't estimated.
+	FutureCostsExcluded bool `json:"future_costs_excluded,omitempty"`
 }
 
 // Breakdown shows fully itemized costs for a pull request.
@@ -231,6 +543,7 @@ type Breakdown struct {
 	Participants       []ParticipantCostDetail `json:"participants"`
 	Author             AuthorCostDetail        `json:"author"`
 	DelayCostDetail    DelayCostDetail         `json:"delay_cost_detail"`
+	CapAppliedTo       CapAppliedTo            `json:"cap_applied_to"` // Which independent cap bound each delay component
 	AnnualSalary       float64                 `json:"annual_salary"`
 	HourlyRate         float64                 `json:"hourly_rate"`
 	DelayHours         float64                 `json:"delay_hours"`
@@ -238,8 +551,43 @@ type Breakdown struct {
 	DelayCost          float64                 `json:"delay_cost"`
 	PRDuration         float64                 `json:"pr_duration"`
 	TotalCost          float64                 `json:"total_cost"`
-	AuthorBot          bool                    `json:"author_bot"`
-	DelayCapped        bool                    `json:"delay_capped"`
+	CostPerLOC         float64                 `json:"cost_per_loc"` // TotalCost / lines added; 0 if no lines were added
+	// PotentialSavings is how much less the PR's preventable cost (see PreventableCost) would
+	// have been had it merged within Config.TargetMergeTimeHours. It is 0 for PRs open no
+	// longer than the target.
+	PotentialSavings float64 `json:"potential_savings"`
+	// DelayAttribution divides DelayCostDetail.DeliveryDelayCost among the people who had the
+	// ball while the PR waited, highest cost first. Empty without delivery delay.
+	DelayAttribution []DelayAttribution `json:"delay_attribution,omitempty"`
+	// AbandonedCost is the code cost (new development + adaptation, including co-authors' shares)
+	// of a PR closed without merging. It is already included in TotalCost; it is reported
+	// separately because that code delivered no value.
+	AbandonedCost  float64 `json:"abandoned_cost"`
+	AbandonedHours float64 `json:"abandoned_hours"`
+	RevertCost     float64 `json:"revert_cost"`  // Author and participant cost of a revert PR (in TotalCost; excludes AbandonedCost)
+	RevertHours    float64 `json:"revert_hours"` // Author and participant hours of a revert PR
+	IsRevert       bool    `json:"is_revert"`    // Title starts with "Revert" or the PR has a revert label
+	AuthorBot      bool    `json:"author_bot"`
+	Merged         bool    `json:"merged"`
+	Abandoned      bool    `json:"abandoned"` // Closed without merging
+	Zombie         bool    `json:"zombie"`    // Old open PR that is poked but not progressing
+	DelayCapped    bool    `json:"delay_capped"`
+	MissingEvents  bool    `json:"missing_events"`     // PR has LOC but no events (likely a data-fetch gap)
+	ChangeType     string  `json:"change_type"`        // feature, fix, chore, ... or "unclassified" (see ClassifyChangeType)
+	Currency       string  `json:"currency,omitempty"` // ISO 4217 code the costs are in (Config.ReportingCurrency)
+
+	// Grading (computed from the costs above)
+	EfficiencyPct        float64 `json:"efficiency_pct"`         // Percentage of hours that were not preventable waste (0-100)
+	EfficiencyGrade      string  `json:"efficiency_grade"`       // Letter grade for development efficiency
+	EfficiencyMessage    string  `json:"efficiency_message"`     // Description of efficiency grade
+	MergeVelocityGrade   string  `json:"merge_velocity_grade"`   // Letter grade for merge velocity (from PRDuration)
+	MergeVelocityMessage string  `json:"merge_velocity_message"` // Description of merge velocity grade
+
+	// Variance compares the modeled hours with PRData.ActualHours; nil unless actual hours were given
+	Variance *Variance `json:"variance,omitempty"`
+
+	// SampleWeight is PRData.SampleWeight: how many population PRs this sample stands for (0 = an equal share)
+	SampleWeight float64 `json:"sample_weight,omitempty"`
 }
 
 // Calculate computes the total cost of a pull request with detailed breakdowns.
@@ -250,13 +598,47 @@ func Calculate(data PRData, cfg Config) Breakdown {
 	if cfg.HoursPerYear == 0 {
 		cfg.HoursPerYear = 2080 // Standard full-time hours per year
 	}
-	hourlyRate := (cfg.AnnualSalary * cfg.BenefitsMultiplier) / cfg.HoursPerYear
+	// Author and delay costs use the author's rate; participants use their own
+	authorSalary := cfg.authorAnnualSalary(&data)
+	hourlyRate := cfg.hourlyRate(authorSalary)
+
+	// A PR with code changes but no events at all is almost always a data-fetch gap.
+	// Left alone, it silently loses all GitHub activity and session costs.
+	missingEvents := len(data.Events) == 0 && (data.LinesAdded > 0 || data.LinesDeleted > 0)
+	if missingEvents {
+		slog.Warn("PR has lines of code but no events - GitHub activity costs will be underestimated",
+			"author", data.Author,
+			"lines_added", data.LinesAdded,
+			"lines_deleted", data.LinesDeleted,
+			"estimate_missing_events", cfg.EstimateMissingEvents)
+		if cfg.EstimateMissingEvents {
+			data.Events = []ParticipantEvent{{Timestamp: data.CreatedAt, Actor: data.Author, Kind: "commit"}}
+		}
+	}
+
+	// Some data sources omit ClosedAt; without it a long-merged PR would look open until now
+	if data.ClosedAt.IsZero() {
+		if closedAt := inferClosedAt(data); !closedAt.IsZero() {
+			slog.Info("PR has no close time; inferred it from its events",
+				"author", data.Author, "merged", data.Merged, "closed_at", closedAt.Format(time.RFC3339))
+			data.ClosedAt = closedAt
+		}
+	}
 
 	// Calculate author costs
 	authorCost := calculateAuthorCost(data, cfg, hourlyRate)
+	uncappedCodeCost, codeCap := capCodeCost(&authorCost, cfg)
+
+	// Calculate participant costs (everyone except author). Bot PRs charged as overhead only
+	// cost nobody's time beyond the automated updates factor.
+	botOverheadOnly := data.AuthorBot && !cfg.CountBotParticipantCosts
+	var participantCosts []ParticipantCostDetail
+	if !botOverheadOnly {
+		participantCosts = calculateParticipantCosts(data, cfg)
+	}
 
-	// Calculate participant costs (everyone except author)
-	participantCosts := calculateParticipantCosts(data, cfg, hourlyRate)
+	// Co-authored commits share the author's development effort with their co-authors
+	participantCosts = splitCoAuthoredEffort(data, cfg, &authorCost, participantCosts)
 
 	// Calculate delay cost with itemized breakdown (always shown)
 	// Use ClosedAt if PR is closed, otherwise use current time
@@ -303,64 +685,42 @@ func Calculate(data PRData, cfg Config) Breakdown {
 		"hours_since_last_event", timeSinceLastEvent,
 		"days_since_last_event", timeSinceLastEvent/24.0)
 
-	// Cap Project Delay in three ways:
-	// 1. Minimum threshold: PRs open < 30 minutes have no delay cost (fast turnaround)
+	// Cap Project Delay in three ways (see CapAppliedTo for how they interact with MaxCodeDrift):
+	// 1. Minimum threshold: PRs open < MinDelayThresholdMinutes (default: 30) have no delay cost (fast turnaround)
 	// 2. Only count up to MaxDelayAfterLastEvent (default: 14 days) after the last event
 	// 3. Absolute maximum of MaxProjectDelay (default: 90 days) total
-	var capped bool
-	var cappedHrs float64
-
-	cappedHrs = delayHours
-
-	// First, apply minimum threshold: no delay costs for PRs open < 30 minutes
-	// Rationale: PRs merged within 30 minutes have no meaningful delay or coordination overhead
-	const minDelayThreshold = 0.5 // 30 minutes in hours
-	if cappedHrs < minDelayThreshold {
-		cappedHrs = 0
-		slog.Info("Applied delay minimum threshold - no delay costs for fast turnaround",
-			"delay_hours", delayHours,
-			"threshold_hours", minDelayThreshold)
-	}
-
-	// Second, apply the "2 weeks after last event" cap
-	maxAfterEvent := cfg.MaxDelayAfterLastEvent.Hours()
-	if cappedHrs > 0 && timeSinceLastEvent > maxAfterEvent {
-		// Reduce delay by the excess time since last event
-		excessHours := timeSinceLastEvent - maxAfterEvent
-		cappedHrs = delayHours - excessHours
-		if cappedHrs < 0 {
-			cappedHrs = 0
-		}
-		capped = true
-		slog.Info("Applied delay cap: time since last event",
-			"max_hours_after_event", maxAfterEvent,
-			"actual_hours_since_event", timeSinceLastEvent,
-			"excess_hours", excessHours,
-			"capped_delay_hours", cappedHrs)
-	}
-
-	// Third, apply the absolute maximum cap
-	maxTotal := cfg.MaxProjectDelay.Hours()
-	if cappedHrs > maxTotal {
-		beforeCap := cappedHrs
-		cappedHrs = maxTotal
-		capped = true
-		slog.Info("Applied delay cap: absolute maximum",
-			"max_total_hours", maxTotal,
-			"delay_before_cap", beforeCap,
-			"capped_delay_hours", cappedHrs)
-	}
+	cappedHrs, delayCap := capDelayHours(delayHours, timeSinceLastEvent, cfg)
+	capped := delayCap == CapMaxDelayAfterLastEvent || delayCap == CapMaxProjectDelay
 
 	// 1a. Delivery Delay: Opportunity cost of blocked value (default 15%)
 	// The 15% represents the percentage of team capacity consumed by this blocked PR
 	// Bot-authored PRs get 0% delivery delay (no human waiting)
 	var deliveryDelayCost, deliveryDelayHours float64
+	var deliveryDelayBasis string
+	var delayAttribution []DelayAttribution
+	peopleWaiting := countPeopleWaiting(data)
+	waitingMultiplier := 1.0
 	if !data.AuthorBot {
-		deliveryDelayCost = hourlyRate * cappedHrs * cfg.DeliveryDelayFactor
-		deliveryDelayHours = cappedHrs * cfg.DeliveryDelayFactor // Productivity-equivalent hours
+		// Time spent as a draft doesn't count, nor, with RequireWaitingEvidence, time before anyone was waiting
+		var blockedSince time.Time
+		var blockedHrs float64
+		blockedSince, blockedHrs, deliveryDelayBasis = waitingDelayHours(data, cfg, cappedHrs, endTime)
+		blockedUntil := blockedSince.Add(time.Duration(blockedHrs * float64(time.Hour)))
+		// With a working calendar, nights and weekends block nobody
+		blockedHrs = cfg.chargedHours(blockedSince, blockedHrs)
+		// A PR blocking several people costs more than one blocking nobody
+		waitingMultiplier = cfg.waitingMultiplier(peopleWaiting)
+		deliveryDelayCost = hourlyRate * blockedHrs * cfg.DeliveryDelayFactor * waitingMultiplier
+		deliveryDelayHours = blockedHrs * cfg.DeliveryDelayFactor * waitingMultiplier // Productivity-equivalent hours
+		// Whoever had the ball while the PR waited is answerable for its share of the delay
+		delayAttribution = attributeDelay(data, cfg, blockedSince, blockedUntil, deliveryDelayCost)
 		slog.Info("Delivery delay calculation",
 			"pr_duration_hours", delayHours,
 			"capped_hours", cappedHrs,
+			"blocked_hours", blockedHrs,
+			"basis", deliveryDelayBasis,
+			"people_waiting", peopleWaiting,
+			"waiting_multiplier", waitingMultiplier,
 			"delay_factor", cfg.DeliveryDelayFactor,
 			"delivery_delay_hours", deliveryDelayHours,
 			"delivery_delay_cost", deliveryDelayCost)
@@ -372,12 +732,13 @@ func Calculate(data PRData, cfg Config) Breakdown {
 
 	if data.AuthorBot {
 		// Bot PRs: Use Automated Updates factor (default 1%)
-		automatedUpdatesCost = hourlyRate * cappedHrs * cfg.AutomatedUpdatesFactor
-		automatedUpdatesHours = cappedHrs * cfg.AutomatedUpdatesFactor
+		botHrs := cfg.chargedHours(data.CreatedAt, cappedHrs)
+		automatedUpdatesCost = hourlyRate * botHrs * cfg.AutomatedUpdatesFactor
+		automatedUpdatesHours = botHrs * cfg.AutomatedUpdatesFactor
 	}
 
 	// 2. Code Churn (Rework): Probability-based drift formula
-	// Only calculated for open PRs - closed PRs won't need future updates
+	// Only calculated for open PRs - closed PRs won't need future updates - with IncludeFutureCosts
 	//
 	// Formula: Probability that a line becomes stale over time
 	//   drift = 1 - (1 - weeklyChurn)^(weeks)
@@ -430,13 +791,11 @@ func Calculate(data PRData, cfg Config) Breakdown {
 		slog.Info("No author commits found for code churn calculation", "pr_closed", isClosed)
 	}
 
-	if !isClosed && driftDays >= 3.0 {
+	var cappedDriftDays float64
+	driftCap := CapNone
+	if cfg.IncludeFutureCosts && !isClosed && driftDays >= 3.0 {
 		// Cap days at configured maximum for drift calculation (default: 90 days)
-		maxDriftDays := cfg.MaxCodeDrift.Hours() / 24.0
-		cappedDriftDays := driftDays
-		if cappedDriftDays > maxDriftDays {
-			cappedDriftDays = maxDriftDays
-		}
+		cappedDriftDays, driftCap = capDriftDays(driftDays, cfg)
 
 		// Probability-based drift using configurable weekly churn rate
 		// Formula: rework = 1 - (1 - weekly_rate)^weeks
@@ -466,7 +825,7 @@ func Calculate(data PRData, cfg Config) Breakdown {
 	}
 
 	// 3. Future GitHub time: split across 2 people (reviewer + author)
-	// Only calculated for open PRs - closed PRs won't have future activity
+	// Only calculated for open PRs - closed PRs won't have future activity - with IncludeFutureCosts
 	//
 	// Research-based approach using IEEE/Fagan inspection rates:
 	//
@@ -491,6 +850,9 @@ func Calculate(data PRData, cfg Config) Breakdown {
 	// - Merge: 0.33 hrs (fixed)
 	// - Context: 1.33 hrs (fixed for 2 sessions)
 	// - Total: 4.1 hrs
+	//
+	// An already-approved PR only needs merging: it has no future review, and only the
+	// author's merge session of context switching.
 	var futureReviewHours float64
 	var futureReviewCost float64
 	var futureMergeHours float64
@@ -498,14 +860,17 @@ func Calculate(data PRData, cfg Config) Breakdown {
 	var futureContextHours float64
 	var futureContextCost float64
 
-	if !isClosed {
-		// Review: Based on inspection rate (LOC / rate)
-		// Defensive check: avoid division by zero
-		if cfg.ReviewInspectionRate <= 0 {
-			cfg.ReviewInspectionRate = 200.0 // Default to industry standard
+	if cfg.IncludeFutureCosts && !isClosed && !botOverheadOnly {
+		approved := isApproved(data.Events)
+		if !approved {
+			// Review: Based on inspection rate (LOC / rate)
+			// Defensive check: avoid division by zero
+			if cfg.ReviewInspectionRate <= 0 {
+				cfg.ReviewInspectionRate = 200.0 // Default to industry standard
+			}
+			futureReviewHours = max(inspectionHours(data, cfg, cfg.ReviewInspectionRate), cfg.MinReviewMinutes/60.0)
+			futureReviewCost = futureReviewHours * hourlyRate
 		}
-		futureReviewHours = float64(data.LinesAdded) / cfg.ReviewInspectionRate
-		futureReviewCost = futureReviewHours * hourlyRate
 
 		// Merge: 1 event × event duration
 		futureMergeDuration := cfg.EventDuration
@@ -513,18 +878,23 @@ func Calculate(data PRData, cfg Config) Breakdown {
 		futureMergeCost = futureMergeHours * hourlyRate
 
 		// Context Switching: 2 sessions × (context in + context out)
-		// 1 session for reviewer, 1 session for author merge
-		futureContextDuration := 2 * (cfg.ContextSwitchInDuration + cfg.ContextSwitchOutDuration)
+		// 1 session for reviewer (unless already approved), 1 session for author merge
+		futureSessions := 2
+		if approved {
+			futureSessions = 1
+		}
+		futureContextDuration := time.Duration(futureSessions) * (cfg.ContextSwitchInDuration + cfg.ContextSwitchOutDuration)
 		futureContextHours = futureContextDuration.Hours()
 		futureContextCost = futureContextHours * hourlyRate
 	}
 
-	// 4. PR Tracking: Daily tracking cost for PRs open >24 hours (default: 1 minute/day)
-	// Applied to PRs open >24 hours to represent ongoing triage/tracking overhead
+	// 4. PR Tracking: Ongoing triage/tracking overhead for open PRs
+	// Uses the same model as the organization-wide extrapolation, scoped to
+	// the people involved in this PR (author + non-bot participants)
 	var prTrackingCost, prTrackingHours float64
-	if !isClosed {
+	if !isClosed && !botOverheadOnly {
 		daysOpen := delayHours / 24.0
-		prTrackingHours = (cfg.PRTrackingMinutesPerDay / 60.0) * daysOpen
+		prTrackingHours = PRTrackingHours(1, 1+len(participantCosts), daysOpen, cfg)
 		prTrackingCost = prTrackingHours * hourlyRate
 	}
 
@@ -552,6 +922,15 @@ func Calculate(data PRData, cfg Config) Breakdown {
 		ReworkPercentage:      reworkPercentage * 100.0, // Store as percentage (0-100 scale, e.g., 41.0 = 41%)
 		TotalDelayCost:        delayCost,
 		TotalDelayHours:       totalDelayHours,
+		DeliveryDelayBasis:    deliveryDelayBasis,
+		PeopleWaiting:         peopleWaiting,
+		WaitingMultiplier:     waitingMultiplier,
+		FutureCostsExcluded:   !cfg.IncludeFutureCosts,
+	}
+
+	// Slow requested reviewers are charged part of the author's wait for them
+	if !data.AuthorBot {
+		participantCosts = chargeReviewWaits(data, cfg, hourlyRate, endTime, participantCosts)
 	}
 
 	// Calculate total cost
@@ -570,21 +949,75 @@ func Calculate(data PRData, cfg Config) Breakdown {
 		"author_cost", authorCost.TotalCost,
 		"delay_cost", delayCost)
 
-	return Breakdown{
-		Author:             authorCost,
-		Participants:       participantCosts,
-		DelayCost:          delayCost,
-		DelayCostDetail:    delayCostDetail,
-		DelayHours:         delayHours,
-		DelayCapped:        capped,
+	// A PR closed without merging delivered nothing: its code cost was spent for no value
+	abandoned := !data.Merged && !data.ClosedAt.IsZero()
+	var abandonedCost, abandonedHours float64
+	if abandoned {
+		coAuthorCost, coAuthorHours := coAuthoredCost(participantCosts)
+		abandonedCost = authorCost.NewCodeCost + authorCost.AdaptationCost + coAuthorCost
+		abandonedHours = authorCost.NewCodeHours + authorCost.AdaptationHours + coAuthorHours
+	}
+
+	// A revert undoes work that was already paid for, so everything spent on it is waste
+	revert := isRevert(data)
+	var revertCost, revertHours float64
+	if revert {
+		revertCost = authorCost.TotalCost - abandonedCost
+		revertHours = authorCost.TotalHours - abandonedHours
+		// Review waits are already counted as waste
+		for _, pc := range participantCosts {
+			revertCost += pc.TotalCost - pc.ReviewWaitCost
+			revertHours += pc.TotalHours - pc.ReviewWaitHours
+		}
+	}
+
+	breakdown := Breakdown{
+		Currency:        cfg.reportingCurrency(),
+		Author:          authorCost,
+		Participants:    participantCosts,
+		DelayCost:       delayCost,
+		DelayCostDetail: delayCostDetail,
+		DelayHours:      delayHours,
+		DelayCapped:     capped,
+		CapAppliedTo: CapAppliedTo{
+			DeliveryDelay:      delayCap,
+			CodeChurn:          driftCap,
+			CodeCost:           codeCap,
+			UncappedDelayHours: delayHours,
+			CappedDelayHours:   cappedHrs,
+			UncappedDriftDays:  driftDays,
+			CappedDriftDays:    cappedDriftDays,
+			UncappedCodeCost:   uncappedCodeCost,
+		},
+		MissingEvents:      missingEvents,
+		DelayAttribution:   delayAttribution,
 		HourlyRate:         hourlyRate,
-		AnnualSalary:       cfg.AnnualSalary,
+		AnnualSalary:       authorSalary,
 		BenefitsMultiplier: cfg.BenefitsMultiplier,
 		PRAuthor:           data.Author,
 		PRDuration:         delayHours,
 		AuthorBot:          data.AuthorBot,
+		Merged:             data.Merged,
+		Abandoned:          abandoned,
+		AbandonedCost:      abandonedCost,
+		AbandonedHours:     abandonedHours,
+		IsRevert:           revert,
+		RevertCost:         revertCost,
+		RevertHours:        revertHours,
+		Zombie:             isZombie(data, cfg, endTime),
+		ChangeType:         ClassifyChangeType(data.Title, data.Labels, cfg.ChangeTypes),
 		TotalCost:          totalCost,
 	}
+	if data.LinesAdded > 0 {
+		breakdown.CostPerLOC = totalCost / float64(data.LinesAdded)
+	}
+	breakdown.PotentialSavings = potentialSavings(data, cfg, &breakdown)
+	breakdown.EfficiencyPct = BreakdownEfficiency(&breakdown)
+	breakdown.EfficiencyGrade, breakdown.EfficiencyMessage = EfficiencyGrade(breakdown.EfficiencyPct)
+	breakdown.MergeVelocityGrade, breakdown.MergeVelocityMessage = MergeVelocityGrade(breakdown.PRDuration)
+	breakdown.Variance = newVariance(&breakdown, data.ActualHours)
+	breakdown.SampleWeight = data.SampleWeight
+	return breakdown
 }
 
 // calculateAuthorCost computes the author's costs broken down by type.
@@ -598,6 +1031,9 @@ func calculateAuthorCost(data PRData, cfg Config, hourlyRate float64) AuthorCost
 	// Modified code costs less because architecture is already in place
 	modifiedLines := min(data.LinesAdded, data.LinesDeleted)
 	newLines := data.LinesAdded - modifiedLines
+	// Generated and vendored lines are not hand-written; drop them from new development
+	generatedLines := data.LinesAdded - costedLinesAdded(data, cfg)
+	newLines = max(newLines-generatedLines, 0)
 
 	var newCodeHours, adaptationHours, newCodeCost, adaptationCost float64
 
@@ -618,6 +1054,9 @@ func calculateAuthorCost(data PRData, cfg Config, hourlyRate float64) AuthorCost
 	// Include all commits (even if Actor != data.Author) plus author's non-commit events
 	var authorEvents []ParticipantEvent
 	for _, event := range data.Events {
+		if cfg.ignoresEventKind(event.Kind) {
+			continue
+		}
 		// All commits go to Author, regardless of Actor
 		// (commits may be attributed to full name instead of GitHub username)
 		// Non-commit events only if from the author
@@ -629,41 +1068,182 @@ func calculateAuthorCost(data PRData, cfg Config, hourlyRate float64) AuthorCost
 	githubCost := githubHours * hourlyRate
 	githubContextCost := githubContextHours * hourlyRate
 
-	totalHours := newCodeHours + adaptationHours + githubHours + githubContextHours
-	totalCost := newCodeCost + adaptationCost + githubCost + githubContextCost
+	// 3. Conflict Resolution Cost: rework after merging the base branch into a long-lived PR
+	var conflictResolutions int
+	var conflictHours float64
+	if !data.AuthorBot && cfg.ConflictResolutionMinutes > 0 {
+		conflictResolutions = countConflictResolutions(data.Events)
+		conflictHours = float64(conflictResolutions) * cfg.ConflictResolutionMinutes / 60.0
+	}
+	conflictCost := conflictHours * hourlyRate
+
+	totalHours := newCodeHours + adaptationHours + githubHours + githubContextHours + conflictHours
+	totalCost := newCodeCost + adaptationCost + githubCost + githubContextCost + conflictCost
 
 	return AuthorCostDetail{
-		NewCodeCost:        newCodeCost,
-		AdaptationCost:     adaptationCost,
-		GitHubCost:         githubCost,
-		GitHubContextCost:  githubContextCost,
-		NewLines:           newLines,
-		ModifiedLines:      modifiedLines,
-		LinesAdded:         data.LinesAdded,
-		Events:             len(authorEvents),
-		Sessions:           sessions,
-		NewCodeHours:       newCodeHours,
-		AdaptationHours:    adaptationHours,
-		GitHubHours:        githubHours,
-		GitHubContextHours: githubContextHours,
-		TotalHours:         totalHours,
-		TotalCost:          totalCost,
+		NewCodeCost:             newCodeCost,
+		AdaptationCost:          adaptationCost,
+		GitHubCost:              githubCost,
+		GitHubContextCost:       githubContextCost,
+		ConflictResolutionCost:  conflictCost,
+		NewLines:                newLines,
+		ModifiedLines:           modifiedLines,
+		LinesAdded:              data.LinesAdded,
+		LinesDeleted:            data.LinesDeleted,
+		GeneratedLines:          generatedLines,
+		Events:                  len(authorEvents),
+		Sessions:                sessions,
+		NewCodeHours:            newCodeHours,
+		AdaptationHours:         adaptationHours,
+		GitHubHours:             githubHours,
+		GitHubContextHours:      githubContextHours,
+		ConflictResolutionHours: conflictHours,
+		ConflictResolutions:     conflictResolutions,
+		TotalHours:              totalHours,
+		TotalCost:               totalCost,
+	}
+}
+
+// countConflictResolutions counts base-branch merge commits that were followed by further
+// commits, a proxy for merge conflicts resolved during the PR. A merge followed only by more
+// merges (or nothing) is a clean sync and isn't counted.
+func countConflictResolutions(events []ParticipantEvent) int {
+	var commits []ParticipantEvent
+	for _, event := range events {
+		if event.Kind == "commit" {
+			commits = append(commits, event)
+		}
+	}
+	slices.SortStableFunc(commits, func(a, b ParticipantEvent) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	count := 0
+	for i := 0; i+1 < len(commits); i++ {
+		if commits[i].MergesBase && !commits[i+1].MergesBase {
+			count++
+		}
+	}
+	return count
+}
+
+// isApproved reports whether a PR has an approval still standing: some reviewer's latest
+// approving or change-requesting review approved it, and no reviewer's latest requested
+// changes. Comment-only reviews don't change a reviewer's verdict; dismissed ones withdraw it.
+func isApproved(events []ParticipantEvent) bool {
+	var reviews []ParticipantEvent
+	for _, event := range events {
+		if event.Kind == "review" && event.State != "" && event.State != "commented" {
+			reviews = append(reviews, event)
+		}
+	}
+	slices.SortStableFunc(reviews, func(a, b ParticipantEvent) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	verdicts := make(map[string]string)
+	for _, review := range reviews {
+		verdicts[review.Actor] = review.State
 	}
+	approved := false
+	for _, state := range verdicts {
+		switch state {
+		case "changes_requested":
+			return false
+		case "approved":
+			approved = true
+		default:
+		}
+	}
+	return approved
+}
+
+// PRTrackingHours returns the planning/triage hours spent tracking openPRs open pull requests
+// over the given number of days, for a group of people (authors and participants):
+//
+//	openPRs × log2(people + 1) × PRTrackingMinutesPerDay / 60 × days
+//
+// Tracking is linear in the number of open PRs and logarithmic in team size, since larger
+// teams develop specialization and distributed ownership. Review time is not included.
+func PRTrackingHours(openPRs, people int, days float64, cfg Config) float64 {
+	if openPRs <= 0 || people <= 0 || days <= 0 {
+		return 0
+	}
+	trackers := math.Log2(float64(people) + 1)
+	return float64(openPRs) * trackers * cfg.PRTrackingMinutesPerDay / 60.0 * days
+}
+
+// inferClosedAt returns when a PR whose data lacks ClosedAt was closed: its MergedAt, or else
+// its latest "merged", "pr_merged" or "closed" event, unless a "reopened" event follows it.
+// It returns the zero time for a PR that is genuinely open.
+func inferClosedAt(data PRData) time.Time {
+	if data.Merged && !data.MergedAt.IsZero() {
+		return data.MergedAt
+	}
+	var closedAt, reopenedAt time.Time
+	for _, event := range data.Events {
+		switch event.Kind {
+		case "merged", "pr_merged", "closed":
+			closedAt = later(closedAt, event.Timestamp)
+		case "reopened":
+			reopenedAt = later(reopenedAt, event.Timestamp)
+		default:
+		}
+	}
+	if !closedAt.After(reopenedAt) {
+		return time.Time{}
+	}
+	return closedAt
+}
+
+// isZombie reports whether an open PR is "stale but active": older than ZombieMinAge,
+// with no meaningful progress (commits or reviews) for ZombieStaleAfter, yet still
+// receiving occasional pokes (comments, labels, etc.) since its last progress.
+func isZombie(data PRData, cfg Config, now time.Time) bool {
+	if !data.ClosedAt.IsZero() || cfg.ZombieMinAge <= 0 || now.Sub(data.CreatedAt) < cfg.ZombieMinAge {
+		return false
+	}
+
+	// Creation counts as progress for PRs that never got a commit or review
+	lastProgress := data.CreatedAt
+	for _, event := range data.Events {
+		switch event.Kind {
+		case "commit", "review", "review_comment":
+			if event.Timestamp.After(lastProgress) {
+				lastProgress = event.Timestamp
+			}
+		default:
+		}
+	}
+	if now.Sub(lastProgress) < cfg.ZombieStaleAfter {
+		return false
+	}
+
+	for _, event := range data.Events {
+		switch event.Kind {
+		case "commit", "review", "review_comment":
+		default:
+			if event.Timestamp.After(lastProgress) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // calculateParticipantCosts computes costs for all participants except the author.
 // Excludes commits (which are attributed to the author).
 //
 // Cost breakdown:
-// 1. Review Cost - LOC-based, once per reviewer (anyone with review/review_comment events)
+// 1. Review Cost - LOC-based per reviewer (anyone with review/review_comment events), per ReReviewFactor-scaled round
 // 2. Other Events - Session-based for non-review events (comments, assignments, etc.)
 // 3. Context Switching - Session-based on ALL events (review events have 0 duration but count for sessions).
-func calculateParticipantCosts(data PRData, cfg Config, hourlyRate float64) []ParticipantCostDetail {
+func calculateParticipantCosts(data PRData, cfg Config) []ParticipantCostDetail {
 	// Group events by actor (excluding author and excluding commits)
 	eventsByActor := make(map[string][]ParticipantEvent)
 	for _, event := range data.Events {
-		// Skip commits (all commits go to Author)
-		if event.Kind == "commit" {
+		// Skip commits (all commits go to Author) and ignored kinds
+		if event.Kind == "commit" || cfg.ignoresEventKind(event.Kind) {
 			continue
 		}
 		// Skip events by the author (already in Author section)
@@ -672,9 +1252,17 @@ func calculateParticipantCosts(data PRData, cfg Config, hourlyRate float64) []Pa
 		}
 	}
 
+	reviewerRank := reviewerOrder(eventsByActor)
+	decay := cfg.ReviewerDecayFactor
+	if decay <= 0 {
+		decay = 1.0
+	}
+
 	var participantCosts []ParticipantCostDetail
 
 	for actor, events := range eventsByActor {
+		hourlyRate := cfg.hourlyRateFor(actor)
+
 		// Check if this person is a reviewer (has review or review_comment events)
 		isReviewer := false
 		for _, event := range events {
@@ -684,15 +1272,22 @@ func calculateParticipantCosts(data PRData, cfg Config, hourlyRate float64) []Pa
 			}
 		}
 
-		// Calculate review cost (LOC-based, once per reviewer)
+		// Calculate review cost (LOC-based per reviewer, plus any re-review rounds)
 		var reviewHours float64
 		var reviewCost float64
+		var rounds int
 		if isReviewer {
 			inspectionRate := cfg.ReviewInspectionRate
 			if inspectionRate <= 0 {
 				inspectionRate = 275.0 // Default to average
 			}
-			reviewHours = float64(data.LinesAdded) / inspectionRate
+			// Later reviewers do lighter passes than the first
+			reviewHours = inspectionHours(data, cfg, inspectionRate) * math.Pow(decay, float64(reviewerRank[actor]))
+			// Even a tiny review has fixed overhead
+			reviewHours = max(reviewHours, cfg.MinReviewMinutes/60.0)
+			// Reviewers who only left review comments still did one round
+			rounds = max(reviewRounds(data, actor), 1)
+			reviewHours *= reReviewMultiplier(rounds, cfg.ReReviewFactor)
 			reviewCost = reviewHours * hourlyRate
 		}
 
@@ -705,6 +1300,8 @@ func calculateParticipantCosts(data PRData, cfg Config, hourlyRate float64) []Pa
 		slog.Info("Participant cost breakdown",
 			"actor", actor,
 			"is_reviewer", isReviewer,
+			"reviewer_rank", reviewerRank[actor],
+			"review_rounds", rounds,
 			"total_events", len(events),
 			"review_hours", reviewHours,
 			"other_events_hours", otherEventsHours,
@@ -720,6 +1317,7 @@ func calculateParticipantCosts(data PRData, cfg Config, hourlyRate float64) []Pa
 			GitHubContextCost:  contextCost,     // Context switching
 			ReviewCost:         reviewCost,      // Review cost (new field)
 			Events:             len(events),
+			ReviewRounds:       rounds,
 			Sessions:           sessions,
 			GitHubHours:        otherEventsHours, // Other Events hours
 			GitHubContextHours: contextHours,     // Context switching hours
@@ -737,6 +1335,78 @@ func calculateParticipantCosts(data PRData, cfg Config, hourlyRate float64) []Pa
 	return participantCosts
 }
 
+// reviewRounds counts actor's review rounds: review submissions separated by commits.
+// Several reviews with no commit between them are one round. Returns 0 if actor never submitted a review.
+func reviewRounds(data PRData, actor string) int {
+	events := slices.Clone(data.Events)
+	slices.SortStableFunc(events, func(a, b ParticipantEvent) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	rounds := 0
+	newCode := true // The first review always starts a round
+	for _, event := range events {
+		switch {
+		case event.Kind == "commit":
+			newCode = true
+		case event.Kind == "review" && event.Actor == actor && newCode:
+			rounds++
+			newCode = false
+		default:
+		}
+	}
+	return rounds
+}
+
+// reReviewMultiplier returns the multiple of one round's review cost charged for rounds review
+// rounds: 1 + factor + factor^2 + ... + factor^(rounds-1).
+func reReviewMultiplier(rounds int, factor float64) float64 {
+	multiplier := 1.0
+	if factor <= 0 {
+		return multiplier
+	}
+	for n := 1; n < rounds; n++ {
+		multiplier += math.Pow(factor, float64(n))
+	}
+	return multiplier
+}
+
+// reviewerOrder ranks reviewers by their first review or review_comment timestamp (0 = first).
+// Ties are broken by actor name so results are deterministic. Non-reviewers are not ranked.
+func reviewerOrder(eventsByActor map[string][]ParticipantEvent) map[string]int {
+	type firstReview struct {
+		at    time.Time
+		actor string
+	}
+	var reviewers []firstReview
+	for actor, events := range eventsByActor {
+		var first time.Time
+		for _, event := range events {
+			if event.Kind != "review" && event.Kind != "review_comment" {
+				continue
+			}
+			if first.IsZero() || event.Timestamp.Before(first) {
+				first = event.Timestamp
+			}
+		}
+		if !first.IsZero() {
+			reviewers = append(reviewers, firstReview{at: first, actor: actor})
+		}
+	}
+
+	slices.SortFunc(reviewers, func(a, b firstReview) int {
+		if c := a.at.Compare(b.at); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.actor, b.actor)
+	})
+
+	rank := make(map[string]int, len(reviewers))
+	for i, r := range reviewers {
+		rank[r.actor] = i
+	}
+	return rank
+}
+
 // calculateSessionCosts computes GitHub and context switching costs based on event sessions.
 //
 // Session Logic:
@@ -745,6 +1415,7 @@ func calculateParticipantCosts(data PRData, cfg Config, hourlyRate float64) []Pa
 //
 // GitHub Time Calculation:
 // - Each event counts as EventDuration (default 10 min)
+// - With EventBurstWindow set, same-kind events within the window of a charged one are free
 // - Gaps between events within a session don't add time (assumed to be part of the work)
 //
 // Context Switching (Microsoft Research: Iqbal & Horvitz 2007):
@@ -802,26 +1473,41 @@ func calculateSessionCosts(events []ParticipantEvent, cfg Config) (githubHours,
 		i = end + 1
 	}
 
-	// Calculate GitHub time (eventDur per event, except review events which have 0 duration)
+	// Calculate GitHub time (eventDur per event, except review events which have 0 duration
+	// unless cfg.ReviewEventsHaveDuration is set, and events in a burst of their kind when
+	// cfg.EventBurstWindow is set)
 	var githubTime time.Duration
 	for _, sess := range sessionGroups {
+		lastCharged := make(map[string]time.Time) // Per event kind, within this session
 		for idx := sess.start; idx <= sess.end; idx++ {
 			event := sorted[idx]
 			// Review and review_comment events have 0 duration (but count for sessions)
-			if event.Kind == "review" || event.Kind == "review_comment" {
+			if !cfg.ReviewEventsHaveDuration && (event.Kind == "review" || event.Kind == "review_comment") {
 				continue
 			}
+			if cfg.EventBurstWindow > 0 {
+				if last, ok := lastCharged[event.Kind]; ok && event.Timestamp.Sub(last) < cfg.EventBurstWindow {
+					continue
+				}
+				lastCharged[event.Kind] = event.Timestamp
+			}
 			githubTime += eventDur
 		}
 	}
 
-	// Calculate context switching with gap awareness
-	var contextTime time.Duration
-
 	if len(sessionGroups) == 0 {
 		return 0, 0, 0
 	}
 
+	// Flat model: a full context switch in and out for every session
+	if cfg.SessionModel == SessionModelFlat {
+		contextTime := time.Duration(len(sessionGroups)) * (contextIn + contextOut)
+		return githubTime.Hours(), contextTime.Hours(), len(sessionGroups)
+	}
+
+	// Calculate context switching with gap awareness
+	var contextTime time.Duration
+
 	// First session: context in
 	contextTime += contextIn
 
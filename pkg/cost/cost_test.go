@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"math"
 	"os"
 	"strings"
 	"sync"
@@ -141,6 +142,134 @@ func TestCalculateMultipleEventsTwoSessions(t *testing.T) {
 	}
 }
 
+func TestCalculateSessionCostsSessionModel(t *testing.T) {
+	now := time.Now()
+	// Three sessions 10 minutes apart: shorter than a full 19m33s context switch out and in.
+	events := []ParticipantEvent{
+		{Timestamp: now, Actor: "author", Kind: "commit"},
+		{Timestamp: now.Add(10 * time.Minute), Actor: "author", Kind: "comment"},
+		{Timestamp: now.Add(20 * time.Minute), Actor: "author", Kind: "review"},
+	}
+	cfg := DefaultConfig()
+	cfg.SessionGapThreshold = 5 * time.Minute
+
+	githubHours, contextHours, sessions := calculateSessionCosts(events, cfg)
+	if sessions != 3 {
+		t.Fatalf("sessions = %d, want 3", sessions)
+	}
+	// In, two 10-minute gaps, out.
+	wantContext := (3*time.Minute + 20*time.Minute + 16*time.Minute + 33*time.Second).Hours()
+	if math.Abs(contextHours-wantContext) > 1e-9 {
+		t.Errorf("gap-aware context = %.4f hrs, want %.4f", contextHours, wantContext)
+	}
+
+	cfg.SessionModel = SessionModelFlat
+	flatGitHub, flatContext, flatSessions := calculateSessionCosts(events, cfg)
+	if flatSessions != 3 {
+		t.Errorf("flat sessions = %d, want 3", flatSessions)
+	}
+	// Every session switches in and out in full.
+	wantFlat := (3 * (3*time.Minute + 16*time.Minute + 33*time.Second)).Hours()
+	if math.Abs(flatContext-wantFlat) > 1e-9 {
+		t.Errorf("flat context = %.4f hrs, want %.4f", flatContext, wantFlat)
+	}
+	// Event time doesn't depend on the model; the review event has no duration.
+	if flatGitHub != githubHours || githubHours != (20*time.Minute).Hours() {
+		t.Errorf("github = %.4f (flat %.4f) hrs, want %.4f", githubHours, flatGitHub, (20 * time.Minute).Hours())
+	}
+}
+
+func TestCalculateEventBurstWindow(t *testing.T) {
+	created := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	// A reviewer leaves 30 inline comments in two minutes, and one more 15 minutes later
+	storm := created.Add(time.Hour)
+	events := []ParticipantEvent{{Timestamp: created, Actor: "alice", Kind: "commit"}}
+	for i := range 30 {
+		events = append(events, ParticipantEvent{Timestamp: storm.Add(time.Duration(i) * 4 * time.Second), Actor: "bob", Kind: "comment"})
+	}
+	events = append(events,
+		ParticipantEvent{Timestamp: storm.Add(time.Minute), Actor: "bob", Kind: "labeled"},
+		ParticipantEvent{Timestamp: storm.Add(15 * time.Minute), Actor: "bob", Kind: "comment"})
+	data := NewPRData("alice", created, created.Add(3*time.Hour), true, 50, 0, events)
+	bob := func(b Breakdown) ParticipantCostDetail {
+		t.Helper()
+		for _, p := range b.Participants {
+			if p.Actor == "bob" {
+				return p
+			}
+		}
+		t.Fatalf("no cost for bob in %+v", b.Participants)
+		return ParticipantCostDetail{}
+	}
+
+	cfg := DefaultConfig()
+	every := bob(Calculate(data, cfg))
+	if want := (32 * 10 * time.Minute).Hours(); math.Abs(every.GitHubHours-want) > 1e-9 {
+		t.Errorf("GitHub hours charging every event = %.2f, want %.2f", every.GitHubHours, want)
+	}
+
+	// The storm is charged once, the label separately, and the comment 15 minutes later again
+	cfg.EventBurstWindow = 10 * time.Minute
+	collapsed := bob(Calculate(data, cfg))
+	if want := (3 * 10 * time.Minute).Hours(); math.Abs(collapsed.GitHubHours-want) > 1e-9 {
+		t.Errorf("GitHub hours with a burst window = %.2f, want %.2f", collapsed.GitHubHours, want)
+	}
+	if collapsed.Sessions != every.Sessions || collapsed.GitHubContextHours != every.GitHubContextHours {
+		t.Errorf("burst window changed sessions: %d sessions, %.2f context hours; want %d, %.2f",
+			collapsed.Sessions, collapsed.GitHubContextHours, every.Sessions, every.GitHubContextHours)
+	}
+	if collapsed.TotalCost >= every.TotalCost/5 {
+		t.Errorf("comment storm costs $%.2f with a burst window, want far less than $%.2f", collapsed.TotalCost, every.TotalCost)
+	}
+}
+
+func TestCalculateReviewEventsHaveDuration(t *testing.T) {
+	now := time.Now()
+	prData := PRData{
+		LinesAdded: 100,
+		Author:     "author",
+		Events: []ParticipantEvent{
+			{Timestamp: now, Actor: "author", Kind: "commit"},
+			{Timestamp: now.Add(time.Hour), Actor: "reviewer", Kind: "review"},
+			{Timestamp: now.Add(time.Hour + 5*time.Minute), Actor: "reviewer", Kind: "review_comment"},
+			{Timestamp: now.Add(time.Hour + 10*time.Minute), Actor: "reviewer", Kind: "comment"},
+		},
+		CreatedAt: now.Add(-time.Hour),
+		ClosedAt:  now.Add(2 * time.Hour),
+	}
+	reviewer := func(b Breakdown) ParticipantCostDetail {
+		t.Helper()
+		if len(b.Participants) != 1 {
+			t.Fatalf("participants = %+v, want only reviewer", b.Participants)
+		}
+		return b.Participants[0]
+	}
+
+	// Default: only the plain comment takes GitHub time; reviews still form the session
+	cfg := DefaultConfig()
+	zeroed := reviewer(Calculate(prData, cfg))
+	if want := cfg.EventDuration.Hours(); math.Abs(zeroed.GitHubHours-want) > 1e-9 {
+		t.Errorf("default GitHubHours = %v, want %v (review events have no duration)", zeroed.GitHubHours, want)
+	}
+	if zeroed.Sessions != 1 || zeroed.Events != 3 {
+		t.Errorf("default sessions/events = %d/%d, want 1/3", zeroed.Sessions, zeroed.Events)
+	}
+
+	// Enabled: every event takes EventDuration; sessions, context and LOC review cost are unchanged
+	cfg.ReviewEventsHaveDuration = true
+	timed := reviewer(Calculate(prData, cfg))
+	if want := 3 * cfg.EventDuration.Hours(); math.Abs(timed.GitHubHours-want) > 1e-9 {
+		t.Errorf("GitHubHours with ReviewEventsHaveDuration = %v, want %v", timed.GitHubHours, want)
+	}
+	if timed.Sessions != zeroed.Sessions || timed.GitHubContextHours != zeroed.GitHubContextHours || timed.ReviewHours != zeroed.ReviewHours {
+		t.Errorf("sessions/context/review = %d/%v/%v, want unchanged %d/%v/%v", timed.Sessions, timed.GitHubContextHours,
+			timed.ReviewHours, zeroed.Sessions, zeroed.GitHubContextHours, zeroed.ReviewHours)
+	}
+	if timed.TotalCost <= zeroed.TotalCost {
+		t.Errorf("TotalCost = %v, want more than default %v", timed.TotalCost, zeroed.TotalCost)
+	}
+}
+
 func TestCalculateWithParticipants(t *testing.T) {
 	now := time.Now()
 	prData := PRData{
@@ -345,6 +474,102 @@ func TestCalculateExternalContributor(t *testing.T) {
 	}
 }
 
+func TestCalculateModifiedLinesFromDeletions(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name                  string
+		added, deleted        int
+		wantNew, wantModified int
+		wantAdaptationCharged bool
+	}{
+		{"additions only", 100, 0, 100, 0, false},
+		{"more additions than deletions", 100, 30, 70, 30, true},
+		{"more deletions than additions", 20, 80, 0, 20, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breakdown := Calculate(PRData{
+				LinesAdded:   tt.added,
+				LinesDeleted: tt.deleted,
+				Author:       "test-author",
+				Events:       []ParticipantEvent{{Timestamp: now.Add(-time.Hour), Actor: "test-author", Kind: "commit"}},
+				CreatedAt:    now.Add(-2 * time.Hour),
+				ClosedAt:     now,
+			}, DefaultConfig())
+
+			a := breakdown.Author
+			if a.NewLines != tt.wantNew || a.ModifiedLines != tt.wantModified {
+				t.Errorf("NewLines, ModifiedLines = %d, %d, want %d, %d", a.NewLines, a.ModifiedLines, tt.wantNew, tt.wantModified)
+			}
+			if a.LinesDeleted != tt.deleted {
+				t.Errorf("LinesDeleted = %d, want %d", a.LinesDeleted, tt.deleted)
+			}
+			if (a.AdaptationCost > 0) != tt.wantAdaptationCharged {
+				t.Errorf("AdaptationCost = $%.2f, want charged = %v", a.AdaptationCost, tt.wantAdaptationCharged)
+			}
+		})
+	}
+}
+
+func TestCalculateNoEventsWithLOC(t *testing.T) {
+	now := time.Now()
+	prData := PRData{
+		LinesAdded:   500,
+		LinesDeleted: 50,
+		Author:       "test-author",
+		CreatedAt:    now.Add(-3 * time.Hour),
+		ClosedAt:     now,
+	}
+
+	cfg := DefaultConfig()
+	breakdown := Calculate(prData, cfg)
+
+	if !breakdown.MissingEvents {
+		t.Error("Expected MissingEvents for PR with LOC but no events")
+	}
+	// Default behavior only flags the anomaly
+	if breakdown.Author.GitHubCost != 0 || breakdown.Author.Sessions != 0 {
+		t.Errorf("Expected no GitHub activity by default, got cost=$%.2f sessions=%d",
+			breakdown.Author.GitHubCost, breakdown.Author.Sessions)
+	}
+
+	cfg.EstimateMissingEvents = true
+	estimated := Calculate(prData, cfg)
+
+	if !estimated.MissingEvents {
+		t.Error("Expected MissingEvents to remain set when estimating activity")
+	}
+	if estimated.Author.Events != 1 || estimated.Author.Sessions != 1 {
+		t.Errorf("Expected 1 estimated event and session, got events=%d sessions=%d",
+			estimated.Author.Events, estimated.Author.Sessions)
+	}
+	if estimated.Author.GitHubCost <= 0 || estimated.Author.GitHubContextCost <= 0 {
+		t.Error("Expected positive GitHub and context cost with estimated activity")
+	}
+	if estimated.TotalCost <= breakdown.TotalCost {
+		t.Errorf("Expected estimated total $%.2f to exceed unestimated $%.2f", estimated.TotalCost, breakdown.TotalCost)
+	}
+}
+
+func TestCalculateNoEventsNoLOC(t *testing.T) {
+	prData := PRData{
+		Author:    "test-author",
+		CreatedAt: time.Now().Add(-1 * time.Hour),
+	}
+
+	cfg := DefaultConfig()
+	cfg.EstimateMissingEvents = true
+	breakdown := Calculate(prData, cfg)
+
+	// An empty PR with no events is not suspicious
+	if breakdown.MissingEvents {
+		t.Error("Expected MissingEvents=false for PR without LOC")
+	}
+	if breakdown.Author.Events != 0 {
+		t.Errorf("Expected no estimated events, got %d", breakdown.Author.Events)
+	}
+}
+
 func TestCalculateDelayComponents(t *testing.T) {
 	// Test PR open for 7 days - should have code drift
 	now := time.Now()
@@ -436,6 +661,84 @@ func TestCalculateShortPRNoRework(t *testing.T) {
 	}
 }
 
+func TestCalculateWithoutFutureCosts(t *testing.T) {
+	now := time.Now()
+	prData := PRData{
+		LinesAdded: 500,
+		Author:     "test-author",
+		Events: []ParticipantEvent{
+			{Timestamp: now.Add(-10 * 24 * time.Hour), Actor: "test-author", Kind: "commit"},
+		},
+		CreatedAt: now.Add(-10 * 24 * time.Hour),
+	}
+
+	cfg := DefaultConfig()
+	with := Calculate(prData, cfg)
+	d := with.DelayCostDetail
+	future := d.CodeChurnCost + d.FutureReviewCost + d.FutureMergeCost + d.FutureContextCost
+	if d.CodeChurnCost <= 0 || d.FutureReviewCost <= 0 || d.FutureCostsExcluded {
+		t.Fatalf("default open PR delay detail = %+v, want code churn and future costs", d)
+	}
+
+	cfg.IncludeFutureCosts = false
+	without := Calculate(prData, cfg)
+	d = without.DelayCostDetail
+	if d.CodeChurnCost != 0 || d.ReworkPercentage != 0 || d.FutureReviewCost != 0 || d.FutureMergeCost != 0 ||
+		d.FutureContextCost != 0 || d.FutureContextHours != 0 || !d.FutureCostsExcluded {
+		t.Errorf("delay detail without future costs = %+v, want none, flagged as excluded", d)
+	}
+	// Both calculations run until now, a moment apart
+	if math.Abs(d.DeliveryDelayCost-with.DelayCostDetail.DeliveryDelayCost) > 0.01 ||
+		math.Abs(d.PRTrackingCost-with.DelayCostDetail.PRTrackingCost) > 0.01 {
+		t.Error("excluding future costs changed incurred delay costs")
+	}
+	if math.Abs(with.TotalCost-future-without.TotalCost) > 0.01 {
+		t.Errorf("TotalCost = %.2f, want %.2f without %.2f of future costs", without.TotalCost, with.TotalCost-future, future)
+	}
+}
+
+func TestCalculateInfersClosedAtFromEvents(t *testing.T) {
+	created := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	merged := created.Add(2 * 24 * time.Hour)
+	events := []ParticipantEvent{
+		{Timestamp: created, Actor: "alice", Kind: "commit"},
+		{Timestamp: created.Add(20 * time.Hour), Actor: "bob", Kind: "review", State: "approved"},
+		{Timestamp: merged, Actor: "bob", Kind: "merged"},
+		{Timestamp: merged.Add(time.Minute), Actor: "alice", Kind: "head_ref_deleted"},
+	}
+	withClosedAt := Calculate(NewPRData("alice", created, merged, true, 200, 10, events), DefaultConfig())
+
+	// The data source omitted the close time: the merge event gives it
+	data := NewPRData("alice", created, time.Time{}, true, 200, 10, events)
+	got := Calculate(data, DefaultConfig())
+	if got.DelayHours != withClosedAt.DelayHours || got.TotalCost != withClosedAt.TotalCost {
+		t.Errorf("without ClosedAt: delay %.1fh costing $%.2f total, want %.1fh and $%.2f as when merged at %s",
+			got.DelayHours, got.TotalCost, withClosedAt.DelayHours, withClosedAt.TotalCost, merged)
+	}
+	if got.DelayCostDetail.FutureReviewCost != 0 {
+		t.Errorf("merged PR without ClosedAt has future review cost %.2f, want none", got.DelayCostDetail.FutureReviewCost)
+	}
+
+	// MergedAt takes precedence over events
+	data.MergedAt = merged.Add(-time.Hour)
+	if got := inferClosedAt(data); !got.Equal(data.MergedAt) {
+		t.Errorf("inferClosedAt() with MergedAt = %s, want %s", got, data.MergedAt)
+	}
+
+	// A PR reopened after it was closed is still open, and costs until now
+	reopened := NewPRData("alice", created, time.Time{}, false, 200, 10, []ParticipantEvent{
+		{Timestamp: created, Actor: "alice", Kind: "commit"},
+		{Timestamp: merged, Actor: "alice", Kind: "closed"},
+		{Timestamp: merged.Add(time.Hour), Actor: "alice", Kind: "reopened"},
+	})
+	if got := inferClosedAt(reopened); !got.IsZero() {
+		t.Errorf("inferClosedAt() for a reopened PR = %s, want zero", got)
+	}
+	if got := Calculate(reopened, DefaultConfig()); got.DelayCostDetail.FutureReviewCost <= 0 {
+		t.Error("reopened PR has no future review cost, want it costed as open")
+	}
+}
+
 func TestCalculateWithRealPR13(t *testing.T) {
 	// Test with PR 13 - a long-lived PR (2136 days from Sep 2019 to Jul 2025)
 	data, err := os.ReadFile("../../testdata/pr_13.json")
@@ -509,6 +812,10 @@ func TestCalculateWithRealPR13(t *testing.T) {
 	if !breakdown.DelayCapped {
 		t.Error("Very long PR should have project delay capped")
 	}
+	if breakdown.CapAppliedTo.DeliveryDelay != CapMaxProjectDelay || breakdown.CapAppliedTo.CodeChurn != CapMaxCodeDrift {
+		t.Errorf("CapAppliedTo = %+v, want delivery bound by %q and churn by %q",
+			breakdown.CapAppliedTo, CapMaxProjectDelay, CapMaxCodeDrift)
+	}
 
 	// 90 days absolute cap = 2160 hours
 	// Delivery: 2160 * 0.15 = 324 hours
@@ -640,44 +947,31 @@ func TestDelayHoursNeverExceedPRAge(t *testing.T) {
 	}
 }
 
-// TestCalculateFastTurnaroundNoDelay verifies that PRs merged within 30 minutes have no delay costs.
+// TestCalculateFastTurnaroundNoDelay verifies that PRs merged within MinDelayThresholdMinutes have no delay costs.
 func TestCalculateFastTurnaroundNoDelay(t *testing.T) {
-	cfg := DefaultConfig()
-
 	testCases := []struct {
-		name        string
-		openMinutes float64
-		wantDelay   float64
+		name             string
+		thresholdMinutes float64
+		openMinutes      float64
+		wantDelay        bool
 	}{
-		{
-			name:        "0 minutes - instant merge",
-			openMinutes: 0,
-			wantDelay:   0,
-		},
-		{
-			name:        "15 minutes - very fast",
-			openMinutes: 15,
-			wantDelay:   0,
-		},
-		{
-			name:        "29 minutes - just under threshold",
-			openMinutes: 29,
-			wantDelay:   0,
-		},
-		{
-			name:        "31 minutes - just over threshold",
-			openMinutes: 31,
-			wantDelay:   0, // Will be > 0 since 31 > 30
-		},
-		{
-			name:        "60 minutes - one hour",
-			openMinutes: 60,
-			wantDelay:   0, // Will be > 0
-		},
+		{name: "0 minutes - instant merge", thresholdMinutes: 30, openMinutes: 0, wantDelay: false},
+		{name: "15 minutes - very fast", thresholdMinutes: 30, openMinutes: 15, wantDelay: false},
+		{name: "29 minutes - just under threshold", thresholdMinutes: 30, openMinutes: 29, wantDelay: false},
+		{name: "31 minutes - just over threshold", thresholdMinutes: 30, openMinutes: 31, wantDelay: true},
+		{name: "60 minutes - one hour", thresholdMinutes: 30, openMinutes: 60, wantDelay: true},
+		{name: "custom 10m threshold - 9 minutes", thresholdMinutes: 10, openMinutes: 9, wantDelay: false},
+		{name: "custom 10m threshold - 11 minutes", thresholdMinutes: 10, openMinutes: 11, wantDelay: true},
+		{name: "custom 10m threshold - 15 minutes", thresholdMinutes: 10, openMinutes: 15, wantDelay: true},
+		{name: "custom 120m threshold - 90 minutes", thresholdMinutes: 120, openMinutes: 90, wantDelay: false},
+		{name: "custom 120m threshold - 121 minutes", thresholdMinutes: 120, openMinutes: 121, wantDelay: true},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.MinDelayThresholdMinutes = tc.thresholdMinutes
+
 			now := time.Now()
 			createdAt := now.Add(-time.Duration(tc.openMinutes) * time.Minute)
 
@@ -693,20 +987,18 @@ func TestCalculateFastTurnaroundNoDelay(t *testing.T) {
 
 			breakdown := Calculate(data, cfg)
 
-			// For PRs < 30 minutes, delay cost should be exactly 0
-			if tc.openMinutes < 30 {
+			if !tc.wantDelay {
 				if breakdown.DelayCost != 0 {
-					t.Errorf("Expected 0 delay cost for %v minute PR, got $%.2f",
-						tc.openMinutes, breakdown.DelayCost)
+					t.Errorf("Expected 0 delay cost for %v minute PR (threshold %v), got $%.2f",
+						tc.openMinutes, tc.thresholdMinutes, breakdown.DelayCost)
 				}
 				if breakdown.DelayCostDetail.DeliveryDelayCost != 0 {
-					t.Errorf("Expected 0 delivery delay cost for %v minute PR, got $%.2f",
-						tc.openMinutes, breakdown.DelayCostDetail.DeliveryDelayCost)
+					t.Errorf("Expected 0 delivery delay cost for %v minute PR (threshold %v), got $%.2f",
+						tc.openMinutes, tc.thresholdMinutes, breakdown.DelayCostDetail.DeliveryDelayCost)
 				}
 			} else if breakdown.DelayCost == 0 {
-				// For PRs >= 30 minutes, delay cost should be > 0
-				t.Errorf("Expected non-zero delay cost for %v minute PR, got $0",
-					tc.openMinutes)
+				t.Errorf("Expected non-zero delay cost for %v minute PR (threshold %v), got $0",
+					tc.openMinutes, tc.thresholdMinutes)
 			}
 		})
 	}
@@ -879,6 +1171,18 @@ func TestAnalyzePRsSequentialSuccess(t *testing.T) {
 	if fetcher.callCount != 2 {
 		t.Errorf("Expected 2 fetcher calls, got %d", fetcher.callCount)
 	}
+
+	if len(result.Data) != len(result.Breakdowns) {
+		t.Fatalf("Expected Data aligned with Breakdowns, got %d data for %d breakdowns", len(result.Data), len(result.Breakdowns))
+	}
+	for i := range result.Data {
+		if result.Data[i].Author != result.Breakdowns[i].PRAuthor {
+			t.Errorf("Data[%d].Author = %q, want %q", i, result.Data[i].Author, result.Breakdowns[i].PRAuthor)
+		}
+	}
+	if len(result.URLs) != len(result.Breakdowns) {
+		t.Errorf("Expected URLs aligned with Breakdowns, got %d URLs for %d breakdowns", len(result.URLs), len(result.Breakdowns))
+	}
 }
 
 func TestAnalyzePRsSequentialPartialFailure(t *testing.T) {
@@ -1221,6 +1525,19 @@ func TestAnalyzePRsConcurrencyDefault(t *testing.T) {
 	}
 }
 
+func TestValidateConcurrency(t *testing.T) {
+	for _, n := range []int{1, DefaultConcurrency, MaxConcurrency} {
+		if err := ValidateConcurrency(n); err != nil {
+			t.Errorf("ValidateConcurrency(%d) = %v, want nil", n, err)
+		}
+	}
+	for _, n := range []int{-1, 0, MaxConcurrency + 1} {
+		if err := ValidateConcurrency(n); err == nil {
+			t.Errorf("ValidateConcurrency(%d) = nil, want error", n)
+		}
+	}
+}
+
 func TestAnalyzePRsContextCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	now := time.Now()
@@ -1339,6 +1656,30 @@ func TestExtrapolateFromSamplesSingle(t *testing.T) {
 	}
 }
 
+func TestSampleBreakdowns(t *testing.T) {
+	breakdowns := []Breakdown{{TotalCost: 100}, {TotalCost: 300}, {TotalCost: 200}}
+	urls := []string{"https://github.com/o/r/pull/1", "https://github.com/o/r/pull/2", "https://github.com/o/r/pull/3"}
+
+	samples := SampleBreakdowns(urls, breakdowns)
+	if len(samples) != 3 {
+		t.Fatalf("SampleBreakdowns() returned %d samples, want 3", len(samples))
+	}
+	for i, want := range []string{urls[1], urls[2], urls[0]} {
+		if samples[i].URL != want {
+			t.Errorf("samples[%d].URL = %q, want %q (most expensive first)", i, samples[i].URL, want)
+		}
+	}
+	if samples[0].Breakdown.TotalCost != 300 {
+		t.Errorf("samples[0] cost = %v, want 300", samples[0].Breakdown.TotalCost)
+	}
+
+	// Samples are only attached on request, so results omit them by default
+	ext := ExtrapolateFromSamples(breakdowns, 3, 1, 0, 30, DefaultConfig(), nil, nil)
+	if ext.Samples != nil {
+		t.Errorf("ExtrapolateFromSamples() Samples = %v, want nil", ext.Samples)
+	}
+}
+
 func TestExtrapolateFromSamplesMultiple(t *testing.T) {
 	now := time.Now()
 	cfg := DefaultConfig()
@@ -1430,6 +1771,85 @@ func TestExtrapolateFromSamplesMultiple(t *testing.T) {
 	}
 }
 
+func TestExtrapolateFromSamplesDeletedLines(t *testing.T) {
+	now := time.Now()
+	cfg := DefaultConfig()
+
+	breakdowns := []Breakdown{
+		Calculate(PRData{
+			LinesAdded:   100,
+			LinesDeleted: 40,
+			Author:       "author1",
+			Events:       []ParticipantEvent{{Timestamp: now, Actor: "author1", Kind: "commit"}},
+			CreatedAt:    now.Add(-2 * time.Hour),
+			ClosedAt:     now,
+		}, cfg),
+		Calculate(PRData{
+			LinesAdded:   20,
+			LinesDeleted: 300,
+			Author:       "author2",
+			Events:       []ParticipantEvent{{Timestamp: now, Actor: "author2", Kind: "commit"}},
+			CreatedAt:    now.Add(-2 * time.Hour),
+			ClosedAt:     now,
+		}, cfg),
+	}
+
+	if breakdowns[0].Author.LinesDeleted != 40 {
+		t.Errorf("Expected LinesDeleted=40 in breakdown, got %d", breakdowns[0].Author.LinesDeleted)
+	}
+
+	// 2 samples extrapolated to 10 PRs: multiplier of 5 per sample average
+	result := ExtrapolateFromSamples(breakdowns, 10, 2, 0, 14, cfg, nil, nil)
+
+	// (40 + 300) / 2 * 10 = 1700
+	if result.TotalDeletedLines != 1700 {
+		t.Errorf("Expected TotalDeletedLines=1700, got %d", result.TotalDeletedLines)
+	}
+
+	// ((100 + 20) - (40 + 300)) / 2 * 10 = -1100
+	if result.NetLinesChanged != -1100 {
+		t.Errorf("Expected NetLinesChanged=-1100, got %d", result.NetLinesChanged)
+	}
+}
+
+func TestExtrapolateFromSamplesSampleWeight(t *testing.T) {
+	now := time.Now()
+	cfg := DefaultConfig()
+
+	data := func(author string, deleted int, weight float64) PRData {
+		return PRData{
+			LinesDeleted: deleted,
+			Author:       author,
+			Events:       []ParticipantEvent{{Timestamp: now, Actor: author, Kind: "commit"}},
+			CreatedAt:    now.Add(-2 * time.Hour),
+			ClosedAt:     now,
+			SampleWeight: weight,
+		}
+	}
+
+	// The first sample stands for 3 PRs, the second for 1: (3*40 + 1*300) / 4 * 10 = 1050
+	weighted := []Breakdown{Calculate(data("author1", 40, 3), cfg), Calculate(data("author2", 300, 1), cfg)}
+	if weighted[0].SampleWeight != 3 {
+		t.Errorf("Breakdown.SampleWeight = %v, want 3", weighted[0].SampleWeight)
+	}
+	result := ExtrapolateFromSamples(weighted, 10, 2, 0, 14, cfg, nil, nil)
+	if result.TotalDeletedLines != 1050 {
+		t.Errorf("weighted TotalDeletedLines = %d, want 1050", result.TotalDeletedLines)
+	}
+	wantCost := (3*weighted[0].TotalCost + weighted[1].TotalCost) / 4 * 10
+	if math.Abs(result.TotalCost-wantCost) > 0.01 {
+		t.Errorf("weighted TotalCost = %.2f, want %.2f", result.TotalCost, wantCost)
+	}
+
+	// Unweighted samples (SampleWeight 0) and equal weights both give the plain average
+	for _, weight := range []float64{0, 2} {
+		equal := []Breakdown{Calculate(data("author1", 40, weight), cfg), Calculate(data("author2", 300, weight), cfg)}
+		if got := ExtrapolateFromSamples(equal, 10, 2, 0, 14, cfg, nil, nil).TotalDeletedLines; got != 1700 {
+			t.Errorf("SampleWeight %v: TotalDeletedLines = %d, want 1700", weight, got)
+		}
+	}
+}
+
 func TestExtrapolateFromSamplesBotVsHuman(t *testing.T) {
 	cfg := DefaultConfig()
 
@@ -1525,6 +1945,58 @@ func TestExtrapolateFromSamplesBotVsHuman(t *testing.T) {
 	}
 }
 
+func TestCalculateBotPRParticipants(t *testing.T) {
+	created := time.Now().Add(-48 * time.Hour)
+	data := PRData{
+		LinesAdded: 200,
+		Author:     "dependabot[bot]",
+		AuthorBot:  true,
+		CreatedAt:  created,
+		Events: []ParticipantEvent{
+			{Timestamp: created, Actor: "dependabot[bot]", Kind: "commit"},
+			{Timestamp: created.Add(2 * time.Hour), Actor: "alice", Kind: "review"},
+			{Timestamp: created.Add(2*time.Hour + 5*time.Minute), Actor: "alice", Kind: "comment"},
+		},
+	}
+
+	cfg := DefaultConfig()
+	counted := Calculate(data, cfg)
+	if counted.Author.NewCodeCost != 0 || counted.Author.AdaptationCost != 0 {
+		t.Errorf("bot author code cost = %.2f, want 0", counted.Author.NewCodeCost+counted.Author.AdaptationCost)
+	}
+	if len(counted.Participants) != 1 || counted.Participants[0].ReviewCost <= 0 {
+		t.Fatalf("participants = %+v, want alice with a review cost", counted.Participants)
+	}
+	detail := counted.DelayCostDetail
+	if detail.FutureReviewCost <= 0 || detail.FutureMergeCost <= 0 || detail.PRTrackingCost <= 0 {
+		t.Errorf("open bot PR future review %.2f, merge %.2f, tracking %.2f, want all positive",
+			detail.FutureReviewCost, detail.FutureMergeCost, detail.PRTrackingCost)
+	}
+
+	cfg.CountBotParticipantCosts = false
+	overhead := Calculate(data, cfg)
+	if len(overhead.Participants) != 0 {
+		t.Errorf("participants = %+v, want none when bot PRs are overhead only", overhead.Participants)
+	}
+	detail = overhead.DelayCostDetail
+	if detail.FutureReviewCost != 0 || detail.FutureMergeCost != 0 || detail.FutureContextCost != 0 || detail.PRTrackingCost != 0 {
+		t.Errorf("overhead-only future and tracking costs = %+v, want 0", detail)
+	}
+	if math.Abs(detail.AutomatedUpdatesCost-counted.DelayCostDetail.AutomatedUpdatesCost) > 0.01 || detail.AutomatedUpdatesCost <= 0 {
+		t.Errorf("automated updates cost = %.2f, want %.2f either way", detail.AutomatedUpdatesCost, counted.DelayCostDetail.AutomatedUpdatesCost)
+	}
+	if overhead.TotalCost >= counted.TotalCost {
+		t.Errorf("overhead-only total %.2f, want less than %.2f", overhead.TotalCost, counted.TotalCost)
+	}
+
+	// Human-authored PRs are unaffected
+	data.Author, data.AuthorBot = "bob", false
+	data.Events[0].Actor = "bob"
+	if got, want := Calculate(data, cfg).TotalCost, Calculate(data, DefaultConfig()).TotalCost; math.Abs(got-want) > 0.01 {
+		t.Errorf("human PR total = %.2f with bot overhead only, want %.2f", got, want)
+	}
+}
+
 func TestExtrapolateFromSamplesWasteCalculation(t *testing.T) {
 	now := time.Now()
 	cfg := DefaultConfig()
@@ -1574,6 +2046,135 @@ func TestExtrapolateFromSamplesWasteCalculation(t *testing.T) {
 	}
 }
 
+func TestExtrapolateFromSamplesDeliveryDelayCapacityCap(t *testing.T) {
+	now := time.Now()
+	cfg := DefaultConfig()
+	cfg.DeliveryDelayCapacityFraction = 1.0
+
+	// A week-long PR: 168h × 20% = 33.6h of delivery delay
+	breakdown := Calculate(PRData{
+		LinesAdded: 100,
+		Author:     "author1",
+		Events: []ParticipantEvent{
+			{Timestamp: now.Add(-168 * time.Hour), Actor: "author1", Kind: "commit"},
+		},
+		CreatedAt: now.Add(-168 * time.Hour),
+		ClosedAt:  now,
+	}, cfg)
+
+	// 100 such PRs from 2 authors in 7 days: 3,360h of delay vs ~80h of capacity
+	uncapped := cfg
+	uncapped.DeliveryDelayCapacityFraction = 0
+	naive := ExtrapolateFromSamples([]Breakdown{breakdown}, 100, 2, 0, 7, uncapped, nil, nil)
+	if naive.DeliveryDelayCapped {
+		t.Fatal("Expected no cap when DeliveryDelayCapacityFraction is 0")
+	}
+
+	capped := ExtrapolateFromSamples([]Breakdown{breakdown}, 100, 2, 0, 7, cfg, nil, nil)
+	hourlyRate := cfg.AnnualSalary * cfg.BenefitsMultiplier / cfg.HoursPerYear
+	wantCap := 2 * cfg.HoursPerYear * 7 / 365 * hourlyRate
+	if !capped.DeliveryDelayCapped {
+		t.Fatal("Expected delivery delay to be capped")
+	}
+	if math.Abs(capped.DeliveryDelayCost-wantCap) > 0.01 {
+		t.Errorf("DeliveryDelayCost = $%.2f, want capacity $%.2f", capped.DeliveryDelayCost, wantCap)
+	}
+	if math.Abs(capped.UncappedDeliveryDelayCost-naive.DeliveryDelayCost) > 0.01 {
+		t.Errorf("UncappedDeliveryDelayCost = $%.2f, want naive $%.2f", capped.UncappedDeliveryDelayCost, naive.DeliveryDelayCost)
+	}
+
+	// Totals shrink by exactly the capped amount
+	reduction := naive.DeliveryDelayCost - capped.DeliveryDelayCost
+	if math.Abs((naive.TotalCost-capped.TotalCost)-reduction) > 0.01 {
+		t.Errorf("TotalCost reduced by $%.2f, want $%.2f", naive.TotalCost-capped.TotalCost, reduction)
+	}
+	if math.Abs((naive.DelayTotalCost-capped.DelayTotalCost)-reduction) > 0.01 {
+		t.Errorf("DelayTotalCost reduced by $%.2f, want $%.2f", naive.DelayTotalCost-capped.DelayTotalCost, reduction)
+	}
+	if capped.DeliveryDelayHours >= naive.DeliveryDelayHours {
+		t.Errorf("Expected capped delivery delay hours < %.1f, got %.1f", naive.DeliveryDelayHours, capped.DeliveryDelayHours)
+	}
+
+	// A lower fraction caps lower
+	cfg.DeliveryDelayCapacityFraction = 0.2
+	if low := ExtrapolateFromSamples([]Breakdown{breakdown}, 100, 2, 0, 7, cfg, nil, nil); math.Abs(low.DeliveryDelayCost-wantCap*0.2) > 0.01 {
+		t.Errorf("DeliveryDelayCost at 20%% capacity = $%.2f, want $%.2f", low.DeliveryDelayCost, wantCap*0.2)
+	}
+}
+
+func TestExtrapolateFromSamplesDeliveryDelayUnderCapacity(t *testing.T) {
+	now := time.Now()
+	cfg := DefaultConfig()
+	cfg.DeliveryDelayCapacityFraction = 1.0
+	breakdown := Calculate(PRData{
+		LinesAdded: 100,
+		Author:     "author1",
+		Events: []ParticipantEvent{
+			{Timestamp: now.Add(-2 * time.Hour), Actor: "author1", Kind: "commit"},
+		},
+		CreatedAt: now.Add(-2 * time.Hour),
+		ClosedAt:  now,
+	}, cfg)
+
+	result := ExtrapolateFromSamples([]Breakdown{breakdown}, 10, 5, 0, 30, cfg, nil, nil)
+	if result.DeliveryDelayCapped {
+		t.Error("Expected no cap for short PRs well under capacity")
+	}
+	if math.Abs(result.DeliveryDelayCost-result.UncappedDeliveryDelayCost) > 1e-9 {
+		t.Errorf("Expected uncapped cost to equal delivery delay cost, got $%.2f vs $%.2f",
+			result.UncappedDeliveryDelayCost, result.DeliveryDelayCost)
+	}
+}
+
+func TestExtrapolateFromSamplesAuthorRollups(t *testing.T) {
+	now := time.Now()
+	cfg := DefaultConfig()
+	pr := func(author string, lines int, bot bool) Breakdown {
+		return Calculate(PRData{
+			LinesAdded: lines,
+			Author:     author,
+			AuthorBot:  bot,
+			Events: []ParticipantEvent{
+				{Timestamp: now.Add(-time.Hour), Actor: author, Kind: "commit"},
+			},
+			CreatedAt: now.Add(-2 * time.Hour),
+			ClosedAt:  now,
+		}, cfg)
+	}
+
+	breakdowns := []Breakdown{
+		pr("alice", 50, false),
+		pr("bob", 1000, false),
+		pr("alice", 60, false),
+		pr("dependabot[bot]", 10, true),
+	}
+	// 40 total PRs from 4 samples: each sampled PR stands in for 10
+	result := ExtrapolateFromSamples(breakdowns, 40, 3, 0, 30, cfg, nil, nil)
+
+	if len(result.AuthorRollups) != 2 {
+		t.Fatalf("Expected 2 human author rollups (bots excluded), got %d: %+v", len(result.AuthorRollups), result.AuthorRollups)
+	}
+	bob, alice := result.AuthorRollups[0], result.AuthorRollups[1]
+	if bob.Author != "bob" || alice.Author != "alice" {
+		t.Fatalf("Expected rollups sorted by cost (bob, alice), got (%s, %s)", bob.Author, alice.Author)
+	}
+	if alice.SampledPRs != 2 || bob.SampledPRs != 1 {
+		t.Errorf("SampledPRs alice=%d bob=%d, want 2 and 1", alice.SampledPRs, bob.SampledPRs)
+	}
+
+	wantAlice := (breakdowns[0].TotalCost + breakdowns[2].TotalCost) * 10
+	if math.Abs(alice.TotalCost-wantAlice) > 0.01 {
+		t.Errorf("alice TotalCost = $%.2f, want $%.2f", alice.TotalCost, wantAlice)
+	}
+	wantEfficiency := (BreakdownEfficiency(&breakdowns[0]) + BreakdownEfficiency(&breakdowns[2])) / 2
+	if math.Abs(alice.AvgEfficiency-wantEfficiency) > 1e-9 {
+		t.Errorf("alice AvgEfficiency = %.2f, want %.2f", alice.AvgEfficiency, wantEfficiency)
+	}
+	if alice.AvgEfficiency <= 0 || alice.AvgEfficiency > 100 {
+		t.Errorf("Expected efficiency in (0, 100], got %.2f", alice.AvgEfficiency)
+	}
+}
+
 func TestExtrapolateFromSamplesR2RSavings(t *testing.T) {
 	now := time.Now()
 	cfg := DefaultConfig()
@@ -1614,6 +2215,11 @@ func TestExtrapolateFromSamplesR2RSavings(t *testing.T) {
 		t.Error("Expected positive R2R savings for long-duration PRs")
 	}
 
+	// Potential savings are the same calculation without the subscription cost
+	if result.PotentialSavings <= result.R2RSavings {
+		t.Errorf("PotentialSavings = %v, want more than R2RSavings %v", result.PotentialSavings, result.R2RSavings)
+	}
+
 	// UniqueNonBotUsers should be tracked
 	if result.UniqueNonBotUsers <= 0 {
 		t.Error("Expected positive unique non-bot users count")
@@ -1710,3 +2316,810 @@ func TestExtrapolateFromSamplesParticipants(t *testing.T) {
 		t.Errorf("Expected at least 2 unique non-bot users (author + reviewers), got %d", result.UniqueNonBotUsers)
 	}
 }
+
+func TestExtrapolateScenarios(t *testing.T) {
+	now := time.Now()
+	samples := []PRData{
+		{
+			LinesAdded: 150,
+			Author:     "author1",
+			Events: []ParticipantEvent{
+				{Timestamp: now.Add(-47 * time.Hour), Actor: "author1", Kind: "commit"},
+				{Timestamp: now.Add(-24 * time.Hour), Actor: "reviewer", Kind: "review"},
+			},
+			CreatedAt: now.Add(-48 * time.Hour),
+			ClosedAt:  now,
+		},
+		{
+			LinesAdded: 40,
+			Author:     "author2",
+			Events:     []ParticipantEvent{{Timestamp: now.Add(-5 * time.Hour), Actor: "author2", Kind: "commit"}},
+			CreatedAt:  now.Add(-6 * time.Hour),
+			ClosedAt:   now,
+		},
+	}
+
+	baseline := DefaultConfig()
+	cheaper := DefaultConfig()
+	cheaper.AnnualSalary = baseline.AnnualSalary / 2
+
+	results := ExtrapolateScenarios(samples, []Scenario{
+		{Name: "baseline", Config: baseline},
+		{Name: "half-salary", Config: cheaper},
+	}, 20, 2, 0, 30, nil, nil)
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 scenario results, got %d", len(results))
+	}
+	if results[0].Name != "baseline" || results[1].Name != "half-salary" {
+		t.Errorf("Expected scenario order preserved, got %q, %q", results[0].Name, results[1].Name)
+	}
+
+	// Baseline scenario must match a direct extrapolation of the same samples
+	direct := make([]Breakdown, len(samples))
+	for i := range samples {
+		direct[i] = Calculate(samples[i], baseline)
+	}
+	want := ExtrapolateFromSamples(direct, 20, 2, 0, 30, baseline, nil, nil)
+	if math.Abs(results[0].Extrapolated.TotalCost-want.TotalCost) > 0.01 {
+		t.Errorf("Baseline TotalCost = $%.2f, want $%.2f", results[0].Extrapolated.TotalCost, want.TotalCost)
+	}
+
+	// Every cost is linear in salary, so halving it halves the total
+	ratio := results[1].Extrapolated.TotalCost / results[0].Extrapolated.TotalCost
+	if math.Abs(ratio-0.5) > 0.001 {
+		t.Errorf("Expected half-salary scenario to cost 50%% of baseline, got %.1f%%", ratio*100)
+	}
+
+	// Both scenarios see the same sample set
+	if results[0].Extrapolated.TotalPRs != results[1].Extrapolated.TotalPRs {
+		t.Errorf("Expected same TotalPRs across scenarios, got %d and %d",
+			results[0].Extrapolated.TotalPRs, results[1].Extrapolated.TotalPRs)
+	}
+}
+
+func TestExtrapolateFromSamplesCostPerMergedAndOpenedPR(t *testing.T) {
+	now := time.Now()
+	cfg := DefaultConfig()
+
+	// 4 samples, 1 merged: a 25% sampled merge rate
+	var breakdowns []Breakdown
+	for i := range 4 {
+		breakdowns = append(breakdowns, Calculate(PRData{
+			LinesAdded: 100,
+			Author:     "author",
+			Merged:     i == 0,
+			Events:     []ParticipantEvent{{Timestamp: now.Add(-time.Hour), Actor: "author", Kind: "commit"}},
+			CreatedAt:  now.Add(-2 * time.Hour),
+			ClosedAt:   now,
+		}, cfg))
+	}
+	if !breakdowns[0].Merged || breakdowns[1].Merged {
+		t.Fatal("Expected Breakdown.Merged to reflect PRData.Merged")
+	}
+
+	// 8 PRs in the population, all opened within the period
+	prs := make([]PRSummaryInfo, 8)
+	for i := range prs {
+		prs[i] = PRSummaryInfo{Owner: "test", Repo: "test", CreatedAt: now.Add(-24 * time.Hour)}
+	}
+	result := ExtrapolateFromSamples(breakdowns, 8, 1, 0, 14, cfg, prs, nil)
+
+	if result.OpenedPRs != 8 {
+		t.Errorf("Expected OpenedPRs=8, got %d", result.OpenedPRs)
+	}
+	wantOpened := result.TotalCost / 8
+	if math.Abs(result.CostPerOpenedPR-wantOpened) > 0.01 {
+		t.Errorf("CostPerOpenedPR = $%.2f, want $%.2f", result.CostPerOpenedPR, wantOpened)
+	}
+	// 25% merge rate: 2 of 8 PRs merged, so each merged PR carries 4x the per-opened cost
+	wantMerged := result.TotalCost / 2
+	if math.Abs(result.CostPerMergedPR-wantMerged) > 0.01 {
+		t.Errorf("CostPerMergedPR = $%.2f, want $%.2f", result.CostPerMergedPR, wantMerged)
+	}
+	if math.Abs(result.CostPerMergedPR/result.CostPerOpenedPR-4) > 0.001 {
+		t.Errorf("Expected per-merged cost to be 4x per-opened, got %.2fx", result.CostPerMergedPR/result.CostPerOpenedPR)
+	}
+
+	// PRs created before the period were modified but not opened in it
+	prs[0].CreatedAt = now.AddDate(0, 0, -30)
+	result = ExtrapolateFromSamples(breakdowns, 8, 1, 0, 14, cfg, prs, nil)
+	if result.OpenedPRs != 7 {
+		t.Errorf("Expected OpenedPRs=7 with one PR created before the period, got %d", result.OpenedPRs)
+	}
+
+	// For a window that ended in the past, the period is measured back from its end, not now
+	until := now.AddDate(0, 0, -60)
+	for i := range prs {
+		prs[i].CreatedAt = until.Add(-24 * time.Hour)
+	}
+	prs[0].CreatedAt = until.AddDate(0, 0, -30)
+	result = ExtrapolateFromSamplesUntil(breakdowns, 8, 1, 0, 14, cfg, prs, nil, until)
+	if result.OpenedPRs != 7 {
+		t.Errorf("OpenedPRs for a past window = %d, want 7", result.OpenedPRs)
+	}
+	if result = ExtrapolateFromSamples(breakdowns, 8, 1, 0, 14, cfg, prs, nil); result.OpenedPRs != 0 {
+		t.Errorf("OpenedPRs for a window ending now = %d, want 0", result.OpenedPRs)
+	}
+}
+
+func TestExtrapolateFromSamplesNoMergedSamples(t *testing.T) {
+	now := time.Now()
+	breakdown := Calculate(PRData{
+		LinesAdded: 10,
+		Author:     "author",
+		Events:     []ParticipantEvent{{Timestamp: now, Actor: "author", Kind: "commit"}},
+		CreatedAt:  now.Add(-time.Hour),
+	}, DefaultConfig())
+
+	result := ExtrapolateFromSamples([]Breakdown{breakdown}, 5, 1, 1, 14, DefaultConfig(), nil, nil)
+	if result.CostPerMergedPR != 0 {
+		t.Errorf("Expected CostPerMergedPR=0 with no merged samples, got $%.2f", result.CostPerMergedPR)
+	}
+	if result.CostPerOpenedPR <= 0 {
+		t.Error("Expected positive CostPerOpenedPR falling back to TotalPRs")
+	}
+}
+
+func TestCostPerLOC(t *testing.T) {
+	now := time.Now()
+	cfg := DefaultConfig()
+	pr := func(author string, lines int) PRData {
+		return PRData{
+			LinesAdded: lines,
+			Author:     author,
+			AuthorBot:  strings.HasSuffix(author, "[bot]"),
+			Events:     []ParticipantEvent{{Timestamp: now.Add(-time.Hour), Actor: author, Kind: "commit"}},
+			CreatedAt:  now.Add(-2 * time.Hour),
+			ClosedAt:   now,
+		}
+	}
+
+	human := Calculate(pr("alice", 200), cfg)
+	if want := human.TotalCost / 200; math.Abs(human.CostPerLOC-want) > 0.0001 {
+		t.Errorf("CostPerLOC = %v, want %v", human.CostPerLOC, want)
+	}
+	if empty := Calculate(pr("alice", 0), cfg); empty.CostPerLOC != 0 {
+		t.Errorf("CostPerLOC with no lines added = %v, want 0", empty.CostPerLOC)
+	}
+
+	// Bot PRs' lines are left out of the denominator, but their cost is not
+	bot := Calculate(pr("dependabot[bot]", 5000), cfg)
+	result := ExtrapolateFromSamples([]Breakdown{human, bot}, 4, 1, 0, 14, cfg, nil, nil)
+	if want := result.TotalCost / 400; math.Abs(result.CostPerLOC-want) > 0.0001 {
+		t.Errorf("extrapolated CostPerLOC = %v, want %v (total cost over 400 human lines)", result.CostPerLOC, want)
+	}
+
+	result = ExtrapolateFromSamples([]Breakdown{bot}, 1, 1, 0, 14, cfg, nil, nil)
+	if result.CostPerLOC != 0 {
+		t.Errorf("extrapolated CostPerLOC with only bot PRs = %v, want 0", result.CostPerLOC)
+	}
+}
+
+func TestCalculateZombiePR(t *testing.T) {
+	now := time.Now()
+	cfg := DefaultConfig()
+
+	// Open 60 days, last commit 40 days ago, but someone commented last week
+	zombie := PRData{
+		LinesAdded: 100,
+		Author:     "author",
+		CreatedAt:  now.Add(-60 * 24 * time.Hour),
+		Events: []ParticipantEvent{
+			{Timestamp: now.Add(-40 * 24 * time.Hour), Actor: "author", Kind: "commit"},
+			{Timestamp: now.Add(-7 * 24 * time.Hour), Actor: "someone", Kind: "comment"},
+		},
+	}
+	if !Calculate(zombie, cfg).Zombie {
+		t.Error("Expected old poked-but-stalled PR to be a zombie")
+	}
+
+	// Equally old, but actively progressing: recent commit and review
+	progressing := PRData{
+		LinesAdded: 100,
+		Author:     "author",
+		CreatedAt:  now.Add(-60 * 24 * time.Hour),
+		Events: []ParticipantEvent{
+			{Timestamp: now.Add(-40 * 24 * time.Hour), Actor: "author", Kind: "commit"},
+			{Timestamp: now.Add(-3 * 24 * time.Hour), Actor: "author", Kind: "commit"},
+			{Timestamp: now.Add(-2 * 24 * time.Hour), Actor: "reviewer", Kind: "review"},
+			{Timestamp: now.Add(-1 * 24 * time.Hour), Actor: "someone", Kind: "comment"},
+		},
+	}
+	if Calculate(progressing, cfg).Zombie {
+		t.Error("Expected actively progressing old PR not to be a zombie")
+	}
+
+	// Abandoned: old and stale, but nobody pokes it
+	abandoned := PRData{
+		LinesAdded: 100,
+		Author:     "author",
+		CreatedAt:  now.Add(-60 * 24 * time.Hour),
+		Events: []ParticipantEvent{
+			{Timestamp: now.Add(-40 * 24 * time.Hour), Actor: "author", Kind: "commit"},
+		},
+	}
+	if Calculate(abandoned, cfg).Zombie {
+		t.Error("Expected abandoned PR without pokes not to be a zombie")
+	}
+
+	// Closed PRs are never zombies
+	closed := zombie
+	closed.ClosedAt = now
+	if Calculate(closed, cfg).Zombie {
+		t.Error("Expected closed PR not to be a zombie")
+	}
+
+	// Thresholds are configurable
+	cfg.ZombieMinAge = 90 * 24 * time.Hour
+	if Calculate(zombie, cfg).Zombie {
+		t.Error("Expected PR younger than ZombieMinAge not to be a zombie")
+	}
+}
+
+func TestExtrapolateFromSamplesZombiePRs(t *testing.T) {
+	now := time.Now()
+	cfg := DefaultConfig()
+
+	zombie := Calculate(PRData{
+		LinesAdded: 100,
+		Author:     "author1",
+		CreatedAt:  now.Add(-60 * 24 * time.Hour),
+		Events: []ParticipantEvent{
+			{Timestamp: now.Add(-40 * 24 * time.Hour), Actor: "author1", Kind: "commit"},
+			{Timestamp: now.Add(-2 * 24 * time.Hour), Actor: "someone", Kind: "comment"},
+		},
+	}, cfg)
+	fresh := Calculate(PRData{
+		LinesAdded: 100,
+		Author:     "author2",
+		CreatedAt:  now.Add(-2 * time.Hour),
+		ClosedAt:   now,
+		Events:     []ParticipantEvent{{Timestamp: now.Add(-time.Hour), Actor: "author2", Kind: "commit"}},
+	}, cfg)
+
+	result := ExtrapolateFromSamples([]Breakdown{zombie, fresh}, 10, 2, 5, 30, cfg, nil, nil)
+
+	if result.ZombiePRs != 5 {
+		t.Errorf("Expected 5 zombie PRs (1 of 2 samples x 10), got %d", result.ZombiePRs)
+	}
+	wantCost := zombie.DelayCostDetail.PRTrackingCost / 2 * 10
+	if result.ZombieTrackingCost <= 0 || math.Abs(result.ZombieTrackingCost-wantCost) > 0.01 {
+		t.Errorf("ZombieTrackingCost = $%.2f, want $%.2f", result.ZombieTrackingCost, wantCost)
+	}
+}
+
+func TestCalculateReviewerDecay(t *testing.T) {
+	now := time.Now()
+	prData := PRData{
+		LinesAdded: 550,
+		Author:     "author",
+		CreatedAt:  now.Add(-4 * time.Hour),
+		ClosedAt:   now,
+		Events: []ParticipantEvent{
+			{Timestamp: now.Add(-3 * time.Hour), Actor: "author", Kind: "commit"},
+			// "third" comments early but reviews last; order is by first review
+			{Timestamp: now.Add(-170 * time.Minute), Actor: "third", Kind: "comment"},
+			{Timestamp: now.Add(-150 * time.Minute), Actor: "first", Kind: "review"},
+			{Timestamp: now.Add(-90 * time.Minute), Actor: "second", Kind: "review_comment"},
+			{Timestamp: now.Add(-30 * time.Minute), Actor: "third", Kind: "review"},
+		},
+	}
+
+	reviewHours := func(b Breakdown) map[string]float64 {
+		hours := make(map[string]float64)
+		for _, p := range b.Participants {
+			hours[p.Actor] = p.ReviewHours
+		}
+		return hours
+	}
+
+	// Default: equal treatment, every reviewer pays full LOC-based cost (550 / 275 = 2h)
+	equal := reviewHours(Calculate(prData, DefaultConfig()))
+	for _, actor := range []string{"first", "second", "third"} {
+		if math.Abs(equal[actor]-2.0) > 0.001 {
+			t.Errorf("Default config: %s review hours = %.3f, want 2.0", actor, equal[actor])
+		}
+	}
+
+	cfg := DefaultConfig()
+	cfg.ReviewerDecayFactor = 0.5
+	decayed := reviewHours(Calculate(prData, cfg))
+	want := map[string]float64{"first": 2.0, "second": 1.0, "third": 0.5}
+	for actor, w := range want {
+		if math.Abs(decayed[actor]-w) > 0.001 {
+			t.Errorf("Decay 0.5: %s review hours = %.3f, want %.3f", actor, decayed[actor], w)
+		}
+	}
+}
+
+func TestCalculateReviewRounds(t *testing.T) {
+	now := time.Now()
+	prData := PRData{
+		LinesAdded: 550,
+		Author:     "author",
+		CreatedAt:  now.Add(-6 * time.Hour),
+		ClosedAt:   now,
+		Events: []ParticipantEvent{
+			{Timestamp: now.Add(-5 * time.Hour), Actor: "author", Kind: "commit"},
+			// Round 1: two reviews with no commit between them
+			{Timestamp: now.Add(-4 * time.Hour), Actor: "reviewer", Kind: "review"},
+			{Timestamp: now.Add(-230 * time.Minute), Actor: "reviewer", Kind: "review"},
+			{Timestamp: now.Add(-3 * time.Hour), Actor: "author", Kind: "commit"},
+			// Round 2
+			{Timestamp: now.Add(-2 * time.Hour), Actor: "reviewer", Kind: "review"},
+			{Timestamp: now.Add(-90 * time.Minute), Actor: "author", Kind: "commit"},
+			// Round 3; review comments alone don't start a round
+			{Timestamp: now.Add(-80 * time.Minute), Actor: "reviewer", Kind: "review_comment"},
+			{Timestamp: now.Add(-time.Hour), Actor: "reviewer", Kind: "review"},
+			// Commented only: counts as one round
+			{Timestamp: now.Add(-30 * time.Minute), Actor: "commenter", Kind: "review_comment"},
+		},
+	}
+
+	participant := func(b Breakdown, actor string) ParticipantCostDetail {
+		t.Helper()
+		for _, p := range b.Participants {
+			if p.Actor == actor {
+				return p
+			}
+		}
+		t.Fatalf("no participant %q in %+v", actor, b.Participants)
+		return ParticipantCostDetail{}
+	}
+
+	// Default: rounds are reported, but only the first is charged (550 / 275 = 2h)
+	base := Calculate(prData, DefaultConfig())
+	if got := participant(base, "reviewer"); got.ReviewRounds != 3 || math.Abs(got.ReviewHours-2.0) > 1e-9 {
+		t.Errorf("default: reviewer rounds/hours = %d/%.3f, want 3/2.0", got.ReviewRounds, got.ReviewHours)
+	}
+	if got := participant(base, "commenter"); got.ReviewRounds != 1 {
+		t.Errorf("commenter rounds = %d, want 1", got.ReviewRounds)
+	}
+
+	// ReReviewFactor 0.5: 2h + 1h + 0.5h
+	cfg := DefaultConfig()
+	cfg.ReReviewFactor = 0.5
+	rereviewed := Calculate(prData, cfg)
+	if got := participant(rereviewed, "reviewer"); math.Abs(got.ReviewHours-3.5) > 1e-9 {
+		t.Errorf("ReReviewFactor 0.5: review hours = %.3f, want 3.5", got.ReviewHours)
+	}
+	if got := participant(rereviewed, "commenter"); math.Abs(got.ReviewHours-2.0) > 1e-9 {
+		t.Errorf("ReReviewFactor 0.5: single-round review hours = %.3f, want 2.0", got.ReviewHours)
+	}
+}
+
+func TestCalculateMinReviewMinutes(t *testing.T) {
+	now := time.Now()
+	prData := PRData{
+		LinesAdded: 2,
+		Author:     "author",
+		CreatedAt:  now.Add(-2 * time.Hour),
+		ClosedAt:   now,
+		Events: []ParticipantEvent{
+			{Timestamp: now.Add(-90 * time.Minute), Actor: "author", Kind: "commit"},
+			{Timestamp: now.Add(-60 * time.Minute), Actor: "reviewer", Kind: "review"},
+		},
+	}
+
+	// Default: no floor, a 2-line review is nearly free (2 / 275 hours)
+	breakdown := Calculate(prData, DefaultConfig())
+	if got, want := breakdown.Participants[0].ReviewHours, 2.0/275.0; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Default config: review hours = %.4f, want %.4f", got, want)
+	}
+
+	cfg := DefaultConfig()
+	cfg.MinReviewMinutes = 15
+	floored := Calculate(prData, cfg)
+	if got := floored.Participants[0].ReviewHours; math.Abs(got-0.25) > 1e-9 {
+		t.Errorf("MinReviewMinutes=15: review hours = %.4f, want 0.25", got)
+	}
+	if floored.Participants[0].ReviewCost <= breakdown.Participants[0].ReviewCost {
+		t.Error("Expected floored review to cost more than unfloored review")
+	}
+
+	// Large PRs are unaffected by the floor (550 / 275 = 2h > 15m)
+	prData.LinesAdded = 550
+	if got := Calculate(prData, cfg).Participants[0].ReviewHours; math.Abs(got-2.0) > 1e-9 {
+		t.Errorf("Large PR: review hours = %.4f, want 2.0", got)
+	}
+
+	// Open PRs floor the future review too
+	prData.LinesAdded = 2
+	prData.ClosedAt = time.Time{}
+	if got := Calculate(prData, cfg).DelayCostDetail.FutureReviewHours; math.Abs(got-0.25) > 1e-9 {
+		t.Errorf("Open PR: future review hours = %.4f, want 0.25", got)
+	}
+}
+
+func TestFutureCostApprovedPR(t *testing.T) {
+	now := time.Now()
+	prData := PRData{
+		LinesAdded: 550,
+		Author:     "author",
+		State:      "OPEN",
+		CreatedAt:  now.Add(-48 * time.Hour),
+		Events: []ParticipantEvent{
+			{Timestamp: now.Add(-40 * time.Hour), Actor: "reviewer", Kind: "review", State: "changes_requested"},
+			{Timestamp: now.Add(-30 * time.Hour), Actor: "author", Kind: "commit"},
+			{Timestamp: now.Add(-20 * time.Hour), Actor: "reviewer", Kind: "review", State: "approved"},
+			{Timestamp: now.Add(-10 * time.Hour), Actor: "other", Kind: "review", State: "commented"},
+		},
+	}
+	cfg := DefaultConfig()
+
+	approved := Calculate(prData, cfg).DelayCostDetail
+	if approved.FutureReviewCost != 0 || approved.FutureReviewHours != 0 {
+		t.Errorf("approved PR future review = $%.2f (%.2fh), want 0", approved.FutureReviewCost, approved.FutureReviewHours)
+	}
+	if approved.FutureMergeCost <= 0 {
+		t.Errorf("approved PR future merge cost = $%.2f, want it kept", approved.FutureMergeCost)
+	}
+	wantContext := (cfg.ContextSwitchInDuration + cfg.ContextSwitchOutDuration).Hours()
+	if math.Abs(approved.FutureContextHours-wantContext) > 1e-9 {
+		t.Errorf("approved PR future context hours = %.4f, want one session (%.4f)", approved.FutureContextHours, wantContext)
+	}
+
+	// A later change request from another reviewer means the PR still needs review
+	prData.Events = append(prData.Events, ParticipantEvent{Timestamp: now.Add(-time.Hour), Actor: "other", Kind: "review", State: "changes_requested"})
+	pending := Calculate(prData, cfg).DelayCostDetail
+	if math.Abs(pending.FutureReviewHours-2.0) > 1e-9 || math.Abs(pending.FutureContextHours-2*wantContext) > 1e-9 {
+		t.Errorf("PR with changes requested future review/context hours = %.4f/%.4f, want 2.0/%.4f",
+			pending.FutureReviewHours, pending.FutureContextHours, 2*wantContext)
+	}
+}
+
+func TestIsApproved(t *testing.T) {
+	now := time.Now()
+	review := func(actor, state string, hoursAgo int) ParticipantEvent {
+		return ParticipantEvent{Timestamp: now.Add(-time.Duration(hoursAgo) * time.Hour), Actor: actor, Kind: "review", State: state}
+	}
+	tests := []struct {
+		name   string
+		events []ParticipantEvent
+		want   bool
+	}{
+		{"no reviews", nil, false},
+		{"review without state", []ParticipantEvent{review("bob", "", 1)}, false},
+		{"approved", []ParticipantEvent{review("bob", "approved", 1)}, true},
+		{"approval after change request", []ParticipantEvent{review("bob", "approved", 1), review("bob", "changes_requested", 2)}, true},
+		{"change request after approval", []ParticipantEvent{review("bob", "approved", 2), review("bob", "changes_requested", 1)}, false},
+		{"comment keeps approval", []ParticipantEvent{review("bob", "approved", 2), review("bob", "commented", 1)}, true},
+		{"dismissed", []ParticipantEvent{review("bob", "approved", 2), review("bob", "dismissed", 1)}, false},
+		{"other reviewer requests changes", []ParticipantEvent{review("bob", "approved", 1), review("carol", "changes_requested", 2)}, false},
+	}
+	for _, tt := range tests {
+		if got := isApproved(tt.events); got != tt.want {
+			t.Errorf("%s: isApproved() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReviewerOrderTies(t *testing.T) {
+	now := time.Now()
+	rank := reviewerOrder(map[string][]ParticipantEvent{
+		"bob":       {{Timestamp: now, Actor: "bob", Kind: "review"}},
+		"alice":     {{Timestamp: now, Actor: "alice", Kind: "review"}},
+		"commenter": {{Timestamp: now.Add(-time.Hour), Actor: "commenter", Kind: "comment"}},
+	})
+
+	if rank["alice"] != 0 || rank["bob"] != 1 {
+		t.Errorf("Expected ties broken by name (alice=0, bob=1), got alice=%d bob=%d", rank["alice"], rank["bob"])
+	}
+	if _, ok := rank["commenter"]; ok {
+		t.Error("Expected non-reviewers to be unranked")
+	}
+}
+
+func TestPRTrackingHours(t *testing.T) {
+	cfg := DefaultConfig()
+
+	// 15 open PRs, 5 people, 30 days: 15 × log2(6) × 0.3/60 × 30
+	want := 15 * math.Log2(6) * 0.005 * 30
+	if got := PRTrackingHours(15, 5, 30, cfg); math.Abs(got-want) > 1e-9 {
+		t.Errorf("PRTrackingHours(15, 5, 30) = %.4f, want %.4f", got, want)
+	}
+
+	// 1 open PR, 1 person: log2(2) = 1 tracker at 18 seconds/day
+	if got := PRTrackingHours(1, 1, 1, cfg); math.Abs(got-0.005) > 1e-9 {
+		t.Errorf("PRTrackingHours(1, 1, 1) = %.4f, want 0.005", got)
+	}
+
+	for _, args := range [][3]int{{0, 5, 30}, {15, 0, 30}, {15, 5, 0}} {
+		if got := PRTrackingHours(args[0], args[1], float64(args[2]), cfg); got != 0 {
+			t.Errorf("PRTrackingHours(%v) = %.4f, want 0", args, got)
+		}
+	}
+}
+
+func TestPRTrackingSingleAndExtrapolatedConsistent(t *testing.T) {
+	now := time.Now()
+	cfg := DefaultConfig()
+
+	// One open PR, 30 days old, with the author and one reviewer (2 people)
+	breakdown := Calculate(PRData{
+		LinesAdded: 50,
+		Author:     "author",
+		CreatedAt:  now.Add(-30 * 24 * time.Hour),
+		Events: []ParticipantEvent{
+			{Timestamp: now.Add(-30 * 24 * time.Hour), Actor: "author", Kind: "commit"},
+			{Timestamp: now.Add(-29 * 24 * time.Hour), Actor: "reviewer", Kind: "review"},
+		},
+	}, cfg)
+
+	want := math.Log2(3) * 0.005 * 30
+	if math.Abs(breakdown.DelayCostDetail.PRTrackingHours-want) > 0.001 {
+		t.Errorf("Single PR tracking hours = %.4f, want %.4f", breakdown.DelayCostDetail.PRTrackingHours, want)
+	}
+
+	// Extrapolating that PR as the only open PR, with the same 2 people, over the same
+	// 30 days must produce the same tracking hours
+	result := ExtrapolateFromSamples([]Breakdown{breakdown}, 1, 1, 1, 30, cfg, nil, nil)
+	if math.Abs(result.PRTrackingHours-breakdown.DelayCostDetail.PRTrackingHours) > 0.001 {
+		t.Errorf("Extrapolated tracking hours = %.4f, single PR = %.4f",
+			result.PRTrackingHours, breakdown.DelayCostDetail.PRTrackingHours)
+	}
+	if math.Abs(result.PRTrackingCost-breakdown.DelayCostDetail.PRTrackingCost) > 0.01 {
+		t.Errorf("Extrapolated tracking cost = $%.2f, single PR = $%.2f",
+			result.PRTrackingCost, breakdown.DelayCostDetail.PRTrackingCost)
+	}
+}
+
+func TestCalculateConflictResolution(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ConflictResolutionMinutes = 30
+	created := time.Now().Add(-72 * time.Hour)
+	commit := func(hours int, mergesBase bool) ParticipantEvent {
+		return ParticipantEvent{
+			Timestamp:  created.Add(time.Duration(hours) * time.Hour),
+			Actor:      "alice",
+			Kind:       "commit",
+			MergesBase: mergesBase,
+		}
+	}
+	pr := func(events ...ParticipantEvent) PRData {
+		return PRData{
+			LinesAdded: 200,
+			Author:     "alice",
+			Events:     events,
+			CreatedAt:  created,
+			ClosedAt:   created.Add(48 * time.Hour),
+		}
+	}
+
+	clean := Calculate(pr(commit(0, false), commit(4, false)), cfg)
+	if clean.Author.ConflictResolutions != 0 || clean.Author.ConflictResolutionCost != 0 {
+		t.Errorf("Clean PR: conflicts = %d, cost = $%.2f; want 0, $0",
+			clean.Author.ConflictResolutions, clean.Author.ConflictResolutionCost)
+	}
+
+	// Merge of main that needed follow-up changes, then a clean final sync
+	conflicted := Calculate(pr(commit(0, false), commit(24, true), commit(25, false), commit(40, true)), cfg)
+	if conflicted.Author.ConflictResolutions != 1 {
+		t.Errorf("Conflicted PR: conflicts = %d, want 1", conflicted.Author.ConflictResolutions)
+	}
+	wantHours := cfg.ConflictResolutionMinutes / 60.0
+	if math.Abs(conflicted.Author.ConflictResolutionHours-wantHours) > 0.001 {
+		t.Errorf("ConflictResolutionHours = %.3f, want %.3f", conflicted.Author.ConflictResolutionHours, wantHours)
+	}
+	wantCost := wantHours * conflicted.HourlyRate
+	if math.Abs(conflicted.Author.ConflictResolutionCost-wantCost) > 0.01 {
+		t.Errorf("ConflictResolutionCost = $%.2f, want $%.2f", conflicted.Author.ConflictResolutionCost, wantCost)
+	}
+	authorSum := conflicted.Author.NewCodeCost + conflicted.Author.AdaptationCost + conflicted.Author.GitHubCost +
+		conflicted.Author.GitHubContextCost + conflicted.Author.ConflictResolutionCost
+	if math.Abs(conflicted.Author.TotalCost-authorSum) > 0.01 {
+		t.Errorf("Author TotalCost = $%.2f, want sum of components $%.2f", conflicted.Author.TotalCost, authorSum)
+	}
+
+	cfg.ConflictResolutionMinutes = 0
+	disabled := Calculate(pr(commit(0, false), commit(24, true), commit(25, false)), cfg)
+	if disabled.Author.ConflictResolutionCost != 0 {
+		t.Errorf("Disabled: ConflictResolutionCost = $%.2f, want $0", disabled.Author.ConflictResolutionCost)
+	}
+}
+
+func TestCalculateAbandoned(t *testing.T) {
+	cfg := DefaultConfig()
+	created := time.Now().Add(-72 * time.Hour)
+	pr := PRData{
+		LinesAdded:   300,
+		LinesDeleted: 50,
+		Author:       "alice",
+		Events:       []ParticipantEvent{{Timestamp: created, Actor: "alice", Kind: "commit"}},
+		CreatedAt:    created,
+		ClosedAt:     created.Add(24 * time.Hour),
+	}
+
+	abandoned := Calculate(pr, cfg)
+	if !abandoned.Abandoned {
+		t.Fatal("Expected closed unmerged PR to be abandoned")
+	}
+	wantCost := abandoned.Author.NewCodeCost + abandoned.Author.AdaptationCost
+	if wantCost <= 0 || math.Abs(abandoned.AbandonedCost-wantCost) > 0.01 {
+		t.Errorf("AbandonedCost = $%.2f, want author code cost $%.2f", abandoned.AbandonedCost, wantCost)
+	}
+
+	pr.Merged = true
+	pr.MergedAt = pr.ClosedAt
+	merged := Calculate(pr, cfg)
+	if merged.Abandoned || merged.AbandonedCost != 0 {
+		t.Errorf("Merged PR: abandoned = %v, cost = $%.2f; want false, $0", merged.Abandoned, merged.AbandonedCost)
+	}
+	if math.Abs(merged.TotalCost-abandoned.TotalCost) > 0.01 {
+		t.Errorf("Abandonment should not change TotalCost: merged $%.2f, abandoned $%.2f", merged.TotalCost, abandoned.TotalCost)
+	}
+	if BreakdownEfficiency(&abandoned) >= BreakdownEfficiency(&merged) {
+		t.Errorf("Abandoned efficiency %.1f%% should be below merged %.1f%%",
+			BreakdownEfficiency(&abandoned), BreakdownEfficiency(&merged))
+	}
+
+	pr.Merged, pr.MergedAt, pr.ClosedAt = false, time.Time{}, time.Time{}
+	if open := Calculate(pr, cfg); open.Abandoned {
+		t.Error("Open PR should not be abandoned")
+	}
+}
+
+func TestCalculateRevert(t *testing.T) {
+	cfg := DefaultConfig()
+	created := time.Now().Add(-72 * time.Hour)
+	pr := PRData{
+		Title:      "Add caching",
+		LinesAdded: 120,
+		Author:     "alice",
+		Events: []ParticipantEvent{
+			{Timestamp: created, Actor: "alice", Kind: "commit"},
+			{Timestamp: created.Add(2 * time.Hour), Actor: "bob", Kind: "review"},
+		},
+		CreatedAt: created,
+		ClosedAt:  created.Add(24 * time.Hour),
+		Merged:    true,
+	}
+
+	normal := Calculate(pr, cfg)
+	if normal.IsRevert || normal.RevertCost != 0 {
+		t.Errorf("Normal PR: revert = %v, cost = $%.2f; want false, $0", normal.IsRevert, normal.RevertCost)
+	}
+
+	pr.Title = `Revert "Add caching"`
+	revert := Calculate(pr, cfg)
+	if !revert.IsRevert {
+		t.Fatal("Expected PR titled Revert to be a revert")
+	}
+	wantCost := revert.Author.TotalCost + revert.Participants[0].TotalCost
+	if math.Abs(revert.RevertCost-wantCost) > 0.01 {
+		t.Errorf("RevertCost = $%.2f, want author and participant cost $%.2f", revert.RevertCost, wantCost)
+	}
+	if math.Abs(revert.TotalCost-normal.TotalCost) > 0.01 {
+		t.Errorf("Reverting should not change TotalCost: normal $%.2f, revert $%.2f", normal.TotalCost, revert.TotalCost)
+	}
+	if revert.EfficiencyPct >= normal.EfficiencyPct {
+		t.Errorf("Revert efficiency %.1f%% should be below normal %.1f%%", revert.EfficiencyPct, normal.EfficiencyPct)
+	}
+
+	ext := ExtrapolateFromSamples([]Breakdown{normal, revert}, 10, 2, 0, 14, cfg, nil, nil)
+	if ext.RevertPRs != 5 || math.Abs(ext.RevertCost-revert.RevertCost*5) > 0.01 {
+		t.Errorf("RevertPRs = %d, RevertCost = $%.2f; want 5, $%.2f", ext.RevertPRs, ext.RevertCost, revert.RevertCost*5)
+	}
+	noRevert := ExtrapolateFromSamples([]Breakdown{normal, normal}, 10, 2, 0, 14, cfg, nil, nil)
+	if ext.EfficiencyPct >= noRevert.EfficiencyPct {
+		t.Errorf("Efficiency with reverts %.1f%% should be below %.1f%% without", ext.EfficiencyPct, noRevert.EfficiencyPct)
+	}
+}
+
+func TestIsRevert(t *testing.T) {
+	tests := []struct {
+		title  string
+		labels []string
+		want   bool
+	}{
+		{title: `Revert "Add caching"`, want: true},
+		{title: "Reverts #123", want: true},
+		{title: "revert: add caching", want: true},
+		{title: "Add caching", labels: []string{"kind/revert"}, want: true},
+		{title: "Revertible migrations"},
+		{title: "Fix revert of cache", labels: []string{"bug"}},
+	}
+	for _, tt := range tests {
+		if got := isRevert(PRData{Title: tt.title, Labels: tt.labels}); got != tt.want {
+			t.Errorf("isRevert(%q, %v) = %v, want %v", tt.title, tt.labels, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateGrades(t *testing.T) {
+	now := time.Now()
+	b := Calculate(PRData{
+		LinesAdded: 200,
+		Author:     "author",
+		CreatedAt:  now.Add(-72 * time.Hour),
+		ClosedAt:   now,
+		Merged:     true,
+		Events: []ParticipantEvent{
+			{Timestamp: now.Add(-72 * time.Hour), Actor: "author", Kind: "commit"},
+			{Timestamp: now.Add(-2 * time.Hour), Actor: "reviewer", Kind: "review"},
+		},
+	}, DefaultConfig())
+
+	if want := BreakdownEfficiency(&b); b.EfficiencyPct != want {
+		t.Errorf("EfficiencyPct = %v, want %v", b.EfficiencyPct, want)
+	}
+	if grade, message := EfficiencyGrade(b.EfficiencyPct); b.EfficiencyGrade != grade || b.EfficiencyMessage != message {
+		t.Errorf("efficiency grade = %q (%q), want %q (%q)", b.EfficiencyGrade, b.EfficiencyMessage, grade, message)
+	}
+	if grade, message := MergeVelocityGrade(b.PRDuration); b.MergeVelocityGrade != grade || b.MergeVelocityMessage != message {
+		t.Errorf("merge velocity grade = %q (%q), want %q (%q)", b.MergeVelocityGrade, b.MergeVelocityMessage, grade, message)
+	}
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"efficiency_pct"`, `"efficiency_grade"`, `"merge_velocity_grade"`} {
+		if !strings.Contains(string(data), key) {
+			t.Errorf("JSON breakdown is missing %s", key)
+		}
+	}
+}
+
+func TestZeroCostBotPR(t *testing.T) {
+	// A bot PR merged the moment it opened, with no code and no delay, costs nothing
+	now := time.Now()
+	cfg := DefaultConfig()
+	cfg.CountBotParticipantCosts = false
+	b := Calculate(PRData{
+		Author:    "dependabot[bot]",
+		AuthorBot: true,
+		CreatedAt: now,
+		ClosedAt:  now,
+		Merged:    true,
+		State:     "MERGED",
+	}, cfg)
+	if b.TotalCost != 0 {
+		t.Fatalf("TotalCost = %v, want 0", b.TotalCost)
+	}
+	if _, err := json.Marshal(b); err != nil {
+		t.Errorf("json.Marshal(breakdown) error: %v", err)
+	}
+
+	// Extrapolating it over an empty period used to annualize waste to NaN, which breaks JSON
+	for _, days := range []int{0, 30} {
+		ext := ExtrapolateFromSamples([]Breakdown{b}, 1, 1, 0, days, cfg, nil, nil)
+		if _, err := json.Marshal(ext); err != nil {
+			t.Errorf("json.Marshal(extrapolated over %d days) error: %v", days, err)
+		}
+		if math.IsNaN(ext.PotentialSavings) || math.IsNaN(ext.EfficiencyPct) {
+			t.Errorf("extrapolated over %d days: savings %v, efficiency %v; want numbers", days, ext.PotentialSavings, ext.EfficiencyPct)
+		}
+	}
+}
+
+func TestPreventableCost(t *testing.T) {
+	b := Breakdown{
+		DelayCostDetail: DelayCostDetail{
+			CodeChurnCost: 1, DeliveryDelayCost: 2, AutomatedUpdatesCost: 4, PRTrackingCost: 8,
+			CodeChurnHours: 1, DeliveryDelayHours: 2, AutomatedUpdatesHours: 4, PRTrackingHours: 8,
+		},
+		Participants:  []ParticipantCostDetail{{ReviewWaitCost: 16, ReviewWaitHours: 16}, {ReviewWaitCost: 32, ReviewWaitHours: 32}},
+		RevertCost:    64,
+		RevertHours:   64,
+		AbandonedCost: 128, // Reported separately
+	}
+	if got := b.PreventableCost(); got != 127 {
+		t.Errorf("PreventableCost() = %v, want 127", got)
+	}
+	if got := b.PreventableHours(); got != 127 {
+		t.Errorf("PreventableHours() = %v, want 127", got)
+	}
+
+	ext := ExtrapolatedBreakdown{
+		CodeChurnCost: 1, DeliveryDelayCost: 2, AutomatedUpdatesCost: 4, PRTrackingCost: 8,
+		ParticipantReviewWaitCost: 48, RevertCost: 64, AbandonedCost: 128,
+		CodeChurnHours: 1, DeliveryDelayHours: 2, AutomatedUpdatesHours: 4, PRTrackingHours: 8,
+		ParticipantReviewWaitHours: 48, RevertHours: 64, AbandonedHours: 128,
+	}
+	if ext.PreventableCost() != b.PreventableCost() || ext.PreventableHours() != b.PreventableHours() {
+		t.Errorf("extrapolated preventable = %v (%v hours), want %v like the breakdown",
+			ext.PreventableCost(), ext.PreventableHours(), b.PreventableCost())
+	}
+}
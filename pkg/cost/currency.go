@@ -0,0 +1,104 @@
+package cost
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultReportingCurrency is the currency costs are reported in unless configured otherwise.
+const DefaultReportingCurrency = "USD"
+
+var errInvalidCurrency = errors.New("currency must be a 3-letter code such as USD or EUR")
+
+// normalizeCurrency uppercases and validates an ISO 4217-style currency code.
+func normalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return "", errInvalidCurrency
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", errInvalidCurrency
+		}
+	}
+	return code, nil
+}
+
+// ParseExchangeRate parses a "CUR=rate" flag value, where rate is the value of one unit of
+// CUR in the reporting currency (e.g. "EUR=1.08" when reporting in USD).
+func ParseExchangeRate(spec string) (currency string, rate float64, err error) {
+	code, value, ok := strings.Cut(spec, "=")
+	if !ok {
+		return "", 0, fmt.Errorf("invalid exchange rate %q: expected CUR=rate", spec)
+	}
+	currency, err = normalizeCurrency(code)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid exchange rate %q: %w", spec, err)
+	}
+	rate, err = strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || rate <= 0 {
+		return "", 0, fmt.Errorf("invalid exchange rate %q: rate must be a positive number", spec)
+	}
+	return currency, rate, nil
+}
+
+// reportingCurrency returns the configured reporting currency, defaulting to USD.
+func (c *Config) reportingCurrency() string {
+	if c.ReportingCurrency == "" {
+		return DefaultReportingCurrency
+	}
+	return strings.ToUpper(c.ReportingCurrency)
+}
+
+// currencyFor returns the currency a login's SalaryOverrides entry is paid in.
+func (c *Config) currencyFor(login string) string {
+	if currency, ok := c.SalaryCurrencies[login]; ok {
+		return strings.ToUpper(currency)
+	}
+	if currency, ok := c.SalaryCurrencies[strings.ToLower(login)]; ok {
+		return strings.ToUpper(currency)
+	}
+	return c.reportingCurrency()
+}
+
+// toReportingCurrency converts an amount in currency to the reporting currency.
+// Unknown currencies are left unconverted; ValidateCurrencies reports them up front.
+func (c *Config) toReportingCurrency(amount float64, currency string) float64 {
+	if currency == c.reportingCurrency() {
+		return amount
+	}
+	if rate, ok := c.ExchangeRates[currency]; ok && rate > 0 {
+		return amount * rate
+	}
+	return amount
+}
+
+// ValidateCurrencies reports salary currencies that have no exchange rate to the reporting currency.
+func ValidateCurrencies(cfg Config) error {
+	reporting := cfg.reportingCurrency()
+	if _, err := normalizeCurrency(reporting); err != nil {
+		return fmt.Errorf("reporting currency: %w", err)
+	}
+	missing := make(map[string]bool)
+	for _, currency := range cfg.SalaryCurrencies {
+		currency = strings.ToUpper(currency)
+		if currency == reporting {
+			continue
+		}
+		if rate, ok := cfg.ExchangeRates[currency]; !ok || rate <= 0 {
+			missing[currency] = true
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	codes := make([]string, 0, len(missing))
+	for code := range missing {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return fmt.Errorf("no exchange rate to %s for: %s", reporting, strings.Join(codes, ", "))
+}
@@ -0,0 +1,106 @@
+package cost
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseExchangeRate(t *testing.T) {
+	currency, rate, err := ParseExchangeRate("eur=1.08")
+	if err != nil || currency != "EUR" || rate != 1.08 {
+		t.Errorf("ParseExchangeRate(eur=1.08) = %q, %v, %v; want EUR, 1.08", currency, rate, err)
+	}
+	for _, spec := range []string{"EUR", "EUR=", "EUR=0", "EUR=-1", "EURO=1.1", "E1R=1.1"} {
+		if _, _, err := ParseExchangeRate(spec); err == nil {
+			t.Errorf("ParseExchangeRate(%q) expected error", spec)
+		}
+	}
+}
+
+func TestValidateCurrencies(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SalaryCurrencies = map[string]string{"alice": "EUR", "bob": "USD", "carol": "GBP", "dave": "INR"}
+	cfg.ExchangeRates = map[string]float64{"EUR": 1.08}
+
+	err := ValidateCurrencies(cfg)
+	if err == nil || err.Error() != "no exchange rate to USD for: GBP, INR" {
+		t.Errorf("ValidateCurrencies() = %v, want missing GBP and INR", err)
+	}
+
+	cfg.ExchangeRates["GBP"] = 1.27
+	cfg.ExchangeRates["INR"] = 0.012
+	if err := ValidateCurrencies(cfg); err != nil {
+		t.Errorf("ValidateCurrencies() unexpected error: %v", err)
+	}
+}
+
+func TestCalculateMultiCurrencyAggregation(t *testing.T) {
+	now := time.Now()
+	pr := func(author string) PRData {
+		return PRData{
+			LinesAdded: 200,
+			Author:     author,
+			CreatedAt:  now.Add(-6 * time.Hour),
+			ClosedAt:   now,
+			Merged:     true,
+			Events: []ParticipantEvent{
+				{Timestamp: now.Add(-5 * time.Hour), Actor: author, Kind: "commit"},
+			},
+		}
+	}
+
+	// Alice is paid €100,000 and Bob $150,000; report in USD at 1 EUR = 1.10 USD
+	cfg := DefaultConfig()
+	cfg.SalaryOverrides = map[string]float64{"alice": 100000, "bob": 150000}
+	cfg.SalaryCurrencies = map[string]string{"alice": "EUR"}
+	cfg.ExchangeRates = map[string]float64{"EUR": 1.10}
+
+	alice := Calculate(pr("alice"), cfg)
+	bob := Calculate(pr("bob"), cfg)
+	if math.Abs(alice.AnnualSalary-110000) > 0.001 {
+		t.Errorf("alice AnnualSalary = %.2f, want 110000 (converted to USD)", alice.AnnualSalary)
+	}
+	if bob.AnnualSalary != 150000 {
+		t.Errorf("bob AnnualSalary = %.2f, want 150000", bob.AnnualSalary)
+	}
+
+	// The same total as costing both in USD directly
+	usd := DefaultConfig()
+	usd.SalaryOverrides = map[string]float64{"alice": 110000, "bob": 150000}
+	want := Calculate(pr("alice"), usd).TotalCost + Calculate(pr("bob"), usd).TotalCost
+	if got := alice.TotalCost + bob.TotalCost; math.Abs(got-want) > 0.01 {
+		t.Errorf("reporting-currency total = %.2f, want %.2f", got, want)
+	}
+
+	// Alice's cost in euros converts to her share of the USD total
+	eur := DefaultConfig()
+	eur.SalaryOverrides = map[string]float64{"alice": 100000}
+	if local := Calculate(pr("alice"), eur).TotalCost; math.Abs(local*1.10-alice.TotalCost) > 0.01 {
+		t.Errorf("alice local cost %.2f EUR × 1.10 = %.2f, want %.2f USD", local, local*1.10, alice.TotalCost)
+	}
+}
+
+func TestBreakdownCurrency(t *testing.T) {
+	now := time.Now()
+	data := PRData{
+		LinesAdded: 50,
+		Author:     "alice",
+		CreatedAt:  now.Add(-2 * time.Hour),
+		ClosedAt:   now,
+		Merged:     true,
+	}
+
+	if got := Calculate(data, DefaultConfig()).Currency; got != "USD" {
+		t.Errorf("Currency = %q, want USD", got)
+	}
+	cfg := DefaultConfig()
+	cfg.ReportingCurrency = "eur"
+	b := Calculate(data, cfg)
+	if b.Currency != "EUR" {
+		t.Errorf("Currency = %q, want EUR", b.Currency)
+	}
+	if ext := ExtrapolateFromSamples([]Breakdown{b}, 1, 1, 0, 30, cfg, nil, nil); ext.Currency != "EUR" {
+		t.Errorf("extrapolated Currency = %q, want EUR", ext.Currency)
+	}
+}
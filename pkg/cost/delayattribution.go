@@ -0,0 +1,138 @@
+package cost
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"time"
+)
+
+// Roles in DelayAttribution: who had the ball while the PR waited.
+const (
+	// DelayHolderAuthor is the PR author, who had the ball after a review or comment.
+	DelayHolderAuthor = "author"
+	// DelayHolderReviewer is a reviewer, who had the ball after a push or review request.
+	DelayHolderReviewer = "reviewer"
+	// DelayHolderUnassigned is time the PR waited for review with no reviewer requested or engaged.
+	DelayHolderUnassigned = "unassigned"
+)
+
+// DelayAttribution is the share of a PR's delivery delay cost attributed to whoever had the
+// ball while it waited (see attributeDelay). It doesn't add to TotalCost: the shares of a
+// PR's attributions add up to its DelayCostDetail.DeliveryDelayCost.
+type DelayAttribution struct {
+	Actor string  `json:"actor"` // Empty for DelayHolderUnassigned
+	Role  string  `json:"role"`  // DelayHolderAuthor, DelayHolderReviewer or DelayHolderUnassigned
+	Hours float64 `json:"hours"` // Charged hours of delivery delay spent waiting on Actor
+	Share float64 `json:"share"` // Fraction of DeliveryDelayCost (0-1)
+	Cost  float64 `json:"cost"`
+}
+
+// attributeDelay divides deliveryDelayCost among the people who had the ball between since and
+// until, the time delivery delay was charged for, in proportion to how long each held it.
+// The ball is with reviewers once the PR is ready for review, after the author pushes or
+// answers a review, and after a review is requested. It is with the author after a review or
+// comment by someone else (or an approval no other requested reviewer is still owed), and while
+// the PR is a draft. Reviewers means the requested reviewers who haven't responded, or, if
+// none are left, everyone who has reviewed; several holders split the time equally. Time
+// waiting for review from nobody in particular is DelayHolderUnassigned.
+func attributeDelay(data PRData, cfg Config, since, until time.Time, deliveryDelayCost float64) []DelayAttribution {
+	if deliveryDelayCost <= 0 || !until.After(since) {
+		return nil
+	}
+	events := slices.Clone(data.Events)
+	slices.SortStableFunc(events, func(a, b ParticipantEvent) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	type holder struct{ actor, role string }
+	hours := make(map[holder]float64)
+	pending := make(map[string]bool)
+	reviewed := make(map[string]bool)
+	withAuthor := false
+	holders := func() []holder {
+		if withAuthor {
+			return []holder{{data.Author, DelayHolderAuthor}}
+		}
+		reviewers := pending
+		if len(reviewers) == 0 {
+			reviewers = reviewed
+		}
+		if len(reviewers) == 0 {
+			return []holder{{"", DelayHolderUnassigned}}
+		}
+		var hs []holder
+		for _, reviewer := range slices.Sorted(maps.Keys(reviewers)) {
+			hs = append(hs, holder{reviewer, DelayHolderReviewer})
+		}
+		return hs
+	}
+	// hold charges the time from start to end within the window to whoever has the ball
+	hold := func(start, end time.Time) {
+		start, end = later(start, since), earlier(end, until)
+		if !end.After(start) {
+			return
+		}
+		charged := cfg.chargedHours(start, end.Sub(start).Hours())
+		hs := holders()
+		for _, h := range hs {
+			hours[h] += charged / float64(len(hs))
+		}
+	}
+
+	last := since
+	for i := range events {
+		event := &events[i]
+		hold(last, event.Timestamp)
+		last = later(last, event.Timestamp)
+
+		byAuthor := event.Actor == data.Author
+		switch event.Kind {
+		case "review_requested":
+			if event.Target != "" && event.Target != data.Author {
+				pending[event.Target] = true
+				withAuthor = false
+			}
+		case "review_request_removed":
+			delete(pending, event.Target)
+		case "commit", "head_ref_force_pushed", "ready_for_review":
+			withAuthor = false
+		case "convert_to_draft":
+			withAuthor = true
+		case "review", "review_comment", "comment":
+			if byAuthor {
+				// The author answering a review hands the ball back
+				withAuthor = false
+				continue
+			}
+			reviewed[event.Actor] = true
+			delete(pending, event.Actor)
+			withAuthor = event.State != "approved" || len(pending) == 0
+		default:
+		}
+	}
+	hold(last, until)
+
+	var total float64
+	for _, h := range hours {
+		total += h
+	}
+	if total <= 0 {
+		return nil
+	}
+	attributions := make([]DelayAttribution, 0, len(hours))
+	for h, held := range hours {
+		share := held / total
+		attributions = append(attributions, DelayAttribution{
+			Actor: h.actor,
+			Role:  h.role,
+			Hours: held,
+			Share: share,
+			Cost:  share * deliveryDelayCost,
+		})
+	}
+	slices.SortFunc(attributions, func(a, b DelayAttribution) int {
+		return cmp.Or(cmp.Compare(b.Cost, a.Cost), cmp.Compare(a.Actor, b.Actor), cmp.Compare(a.Role, b.Role))
+	})
+	return attributions
+}
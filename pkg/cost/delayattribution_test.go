@@ -0,0 +1,103 @@
+package cost
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAttributeDelay(t *testing.T) {
+	created := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	at := func(hours float64) time.Time { return created.Add(time.Duration(hours * float64(time.Hour))) }
+	data := NewPRData("alice", created, at(20), true, 100, 0, []ParticipantEvent{
+		{Timestamp: at(0), Actor: "alice", Kind: "commit"},
+		{Timestamp: at(2), Actor: "alice", Kind: "review_requested", Target: "bob"},
+		{Timestamp: at(2), Actor: "alice", Kind: "review_requested", Target: "carol"},
+		{Timestamp: at(6), Actor: "bob", Kind: "review", State: "changes_requested"},
+		{Timestamp: at(9), Actor: "alice", Kind: "commit"},
+		{Timestamp: at(10), Actor: "carol", Kind: "review", State: "approved"},
+		{Timestamp: at(12), Actor: "bob", Kind: "review", State: "approved"},
+	})
+
+	got := attributeDelay(data, DefaultConfig(), created, data.ClosedAt, 200)
+	// Nobody was asked for 0-2h; bob and carol split 2-6h; alice had it 6-9h; carol, the only
+	// pending reviewer, had it 9-10h; alice again from carol's approval to close, 10-20h
+	want := map[string]float64{"": 2, "bob": 2, "carol": 3, "alice": 13}
+	if len(got) != len(want) {
+		t.Fatalf("attributeDelay() = %+v, want %v", got, want)
+	}
+	var total float64
+	for _, a := range got {
+		if math.Abs(a.Hours-want[a.Actor]) > 1e-9 {
+			t.Errorf("%q held the ball %v hours, want %v", a.Actor, a.Hours, want[a.Actor])
+		}
+		if math.Abs(a.Cost-200*want[a.Actor]/20) > 1e-9 || math.Abs(a.Share-want[a.Actor]/20) > 1e-9 {
+			t.Errorf("%q cost %v (share %v), want %v", a.Actor, a.Cost, a.Share, 200*want[a.Actor]/20)
+		}
+		total += a.Cost
+	}
+	if math.Abs(total-200) > 1e-9 {
+		t.Errorf("attributed costs add up to %v, want the whole 200", total)
+	}
+	if got[0].Actor != "alice" || got[0].Role != DelayHolderAuthor {
+		t.Errorf("first attribution = %+v, want the author, who held the ball longest", got[0])
+	}
+	roles := map[string]string{}
+	for _, a := range got {
+		roles[a.Actor] = a.Role
+	}
+	if roles[""] != DelayHolderUnassigned || roles["bob"] != DelayHolderReviewer {
+		t.Errorf("roles = %v, want unassigned time and bob as a reviewer", roles)
+	}
+
+	// Only time within the charged window counts: from 4h, after bob and carol were requested
+	got = attributeDelay(data, DefaultConfig(), at(4), at(8), 100)
+	want = map[string]float64{"bob": 1, "carol": 1, "alice": 2}
+	for _, a := range got {
+		if math.Abs(a.Hours-want[a.Actor]) > 1e-9 {
+			t.Errorf("windowed: %q held the ball %v hours, want %v", a.Actor, a.Hours, want[a.Actor])
+		}
+	}
+
+	// After the author pushes, the ball goes back to whoever reviewed, even if not re-requested
+	rereview := NewPRData("alice", created, at(6), true, 100, 0, []ParticipantEvent{
+		{Timestamp: at(0), Actor: "dave", Kind: "review", State: "changes_requested"},
+		{Timestamp: at(1), Actor: "alice", Kind: "commit"},
+	})
+	got = attributeDelay(rereview, DefaultConfig(), created, rereview.ClosedAt, 60)
+	if len(got) != 2 || got[0].Actor != "dave" || math.Abs(got[0].Hours-5) > 1e-9 {
+		t.Errorf("attributeDelay() after a push = %+v, want dave holding it for 5 hours", got)
+	}
+
+	if got := attributeDelay(data, DefaultConfig(), created, data.ClosedAt, 0); got != nil {
+		t.Errorf("attributeDelay() without delivery delay = %+v, want nil", got)
+	}
+}
+
+func TestCalculateDelayAttribution(t *testing.T) {
+	created := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	data := NewPRData("alice", created, created.Add(30*time.Hour), true, 100, 0, []ParticipantEvent{
+		{Timestamp: created, Actor: "alice", Kind: "commit"},
+		{Timestamp: created, Actor: "alice", Kind: "review_requested", Target: "bob"},
+		{Timestamp: created.Add(20 * time.Hour), Actor: "bob", Kind: "review", State: "approved"},
+	})
+	breakdown := Calculate(data, DefaultConfig())
+
+	var total float64
+	for _, a := range breakdown.DelayAttribution {
+		total += a.Cost
+	}
+	if len(breakdown.DelayAttribution) != 2 || math.Abs(total-breakdown.DelayCostDetail.DeliveryDelayCost) > 1e-6 {
+		t.Fatalf("DelayAttribution = %+v, want bob and alice sharing delivery delay %v",
+			breakdown.DelayAttribution, breakdown.DelayCostDetail.DeliveryDelayCost)
+	}
+	if bob := breakdown.DelayAttribution[0]; bob.Actor != "bob" || math.Abs(bob.Share-2.0/3) > 1e-9 {
+		t.Errorf("first attribution = %+v, want bob with two thirds", bob)
+	}
+
+	bot := data
+	bot.AuthorBot = true
+	if got := Calculate(bot, DefaultConfig()).DelayAttribution; got != nil {
+		t.Errorf("bot PR DelayAttribution = %+v, want none", got)
+	}
+}
@@ -0,0 +1,43 @@
+package cost
+
+import (
+	"strings"
+
+	"github.com/codeGROOVE-dev/prx/pkg/prx"
+)
+
+// EventKinds lists the ParticipantEvent kinds found in PR data fetched from GitHub through prx,
+// for use with Config.IgnoredEventKinds. Bot events are dropped before costing. Kinds prx emits
+// without exporting a constant, or under a different name than its constant, are spelled out.
+var EventKinds = []string{
+	// Substantive work
+	prx.EventKindCommit, prx.EventKindReview, prx.EventKindReviewComment, prx.EventKindComment,
+	// PR lifecycle
+	"pr_opened", "pr_closed", prx.EventKindPRMerged, prx.EventKindClosed, prx.EventKindReopened, "merged",
+	prx.EventKindReadyForReview, prx.EventKindConvertToDraft, "renamed_title",
+	prx.EventKindLocked, prx.EventKindUnlocked, prx.EventKindPinned, prx.EventKindUnpinned, prx.EventKindTransferred,
+	// Workflow and metadata changes
+	prx.EventKindAssigned, prx.EventKindUnassigned, prx.EventKindLabeled, prx.EventKindUnlabeled,
+	prx.EventKindMilestoned, prx.EventKindDemilestoned,
+	prx.EventKindReviewRequested, prx.EventKindReviewRequestRemoved, prx.EventKindReviewDismissed,
+	prx.EventKindAutoMergeEnabled, prx.EventKindAutoMergeDisabled, "added_to_merge_queue", "removed_from_merge_queue",
+	// Branch updates
+	prx.EventKindHeadRefForcePushed, prx.EventKindHeadRefDeleted, prx.EventKindHeadRefRestored,
+	prx.EventKindBaseRefChanged, prx.EventKindBaseRefForcePushed,
+	"automatic_base_change_succeeded", "automatic_base_change_failed",
+	// Checks and deployments
+	prx.EventKindCheckRun, prx.EventKindStatusCheck, "deployed", prx.EventKindDeploymentEnvironmentChanged,
+	// Notifications and references
+	prx.EventKindMentioned, prx.EventKindSubscribed, prx.EventKindUnsubscribed, "cross_referenced",
+	prx.EventKindReferenced, prx.EventKindConnected, prx.EventKindDisconnected, "user_blocked",
+}
+
+// ignoresEventKind reports whether events of kind are excluded from activity and session costs.
+func (c *Config) ignoresEventKind(kind string) bool {
+	for _, ignored := range c.IgnoredEventKinds {
+		if strings.EqualFold(ignored, kind) {
+			return true
+		}
+	}
+	return false
+}
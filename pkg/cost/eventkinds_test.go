@@ -0,0 +1,39 @@
+package cost
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIgnoredEventKinds(t *testing.T) {
+	created := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	events := []ParticipantEvent{
+		{Timestamp: created, Actor: "author", Kind: "commit"},
+		{Timestamp: created.Add(2 * time.Hour), Actor: "author", Kind: "labeled"},
+		{Timestamp: created.Add(4 * time.Hour), Actor: "reviewer", Kind: "review"},
+		{Timestamp: created.Add(6 * time.Hour), Actor: "reviewer", Kind: "subscribed"},
+		{Timestamp: created.Add(8 * time.Hour), Actor: "watcher", Kind: "subscribed"},
+	}
+	data := NewPRData("author", created, created.Add(24*time.Hour), true, 200, 0, events)
+
+	cfg := DefaultConfig()
+	base := Calculate(data, cfg)
+	cfg.IgnoredEventKinds = []string{"Labeled", "subscribed"}
+	got := Calculate(data, cfg)
+
+	if got.Author.Events != 1 || base.Author.Events != 2 {
+		t.Errorf("author events = %d (base %d), want 1 (base 2)", got.Author.Events, base.Author.Events)
+	}
+	if got.Author.GitHubHours >= base.Author.GitHubHours {
+		t.Errorf("author GitHub hours = %v, want less than %v", got.Author.GitHubHours, base.Author.GitHubHours)
+	}
+	if len(got.Participants) != 1 || got.Participants[0].Actor != "reviewer" {
+		t.Fatalf("participants = %+v, want only reviewer (watcher had only ignored events)", got.Participants)
+	}
+	if got.Participants[0].Events != 1 {
+		t.Errorf("reviewer events = %d, want 1", got.Participants[0].Events)
+	}
+	if got.Participants[0].ReviewCost <= 0 {
+		t.Error("reviewer lost their review cost")
+	}
+}
@@ -1,8 +1,9 @@
 package cost
 
 import (
+	"cmp"
 	"log/slog"
-	"math"
+	"slices"
 	"strings"
 	"time"
 )
@@ -67,7 +68,7 @@ type ExtrapolatedBreakdown struct {
 	BotPRs                     int     `json:"bot_prs"`                         // Number of bot-authored PRs
 	SampledPRs                 int     `json:"sampled_prs"`                     // Number of PRs successfully sampled
 	SuccessfulSamples          int     `json:"successful_samples"`              // Number of samples that processed successfully
-	UniqueAuthors              int     `json:"unique_authors"`                  // Number of unique PR authors (excluding bots) in sample
+	UniqueAuthors              int     `json:"unique_authors"`                  // Number of unique PR authors and co-authors (excluding bots) in sample
 	TotalAuthors               int     `json:"total_authors"`                   // Total unique authors across all PRs (not just samples)
 	UniqueRepositories         int     `json:"unique_repositories"`             // Number of unique repositories with PRs
 	PublicRepositories         int     `json:"public_repositories"`             // Number of public repositories analyzed
@@ -77,33 +78,60 @@ type ExtrapolatedBreakdown struct {
 	WasteHoursPerAuthorPerWeek float64 `json:"waste_hours_per_author_per_week"` // Preventable hours wasted per author per week
 	WasteCostPerAuthorPerWeek  float64 `json:"waste_cost_per_author_per_week"`  // Preventable cost wasted per author per week
 	AvgPRDurationHours         float64 `json:"avg_pr_duration_hours"`           // Average PR open time in hours (all PRs)
+	P50PRDurationHours         float64 `json:"p50_pr_duration_hours"`           // Median open time of sampled PRs in hours
+	P90PRDurationHours         float64 `json:"p90_pr_duration_hours"`           // 90th percentile open time of sampled PRs in hours
 	AvgHumanPRDurationHours    float64 `json:"avg_human_pr_duration_hours"`     // Average human PR open time in hours
 	AvgBotPRDurationHours      float64 `json:"avg_bot_pr_duration_hours"`       // Average bot PR open time in hours
 
 	// Author costs (extrapolated)
-	AuthorNewCodeCost       float64 `json:"author_new_code_cost"`
-	AuthorAdaptationCost    float64 `json:"author_adaptation_cost"`
-	AuthorGitHubCost        float64 `json:"author_github_cost"`
-	AuthorGitHubContextCost float64 `json:"author_github_context_cost"`
-	AuthorTotalCost         float64 `json:"author_total_cost"`
+	AuthorNewCodeCost            float64 `json:"author_new_code_cost"`
+	AuthorAdaptationCost         float64 `json:"author_adaptation_cost"`
+	AuthorGitHubCost             float64 `json:"author_github_cost"`
+	AuthorGitHubContextCost      float64 `json:"author_github_context_cost"`
+	AuthorConflictResolutionCost float64 `json:"author_conflict_resolution_cost"`
+	AuthorTotalCost              float64 `json:"author_total_cost"`
 
 	// Author hours (extrapolated)
-	AuthorNewCodeHours       float64 `json:"author_new_code_hours"`
-	AuthorAdaptationHours    float64 `json:"author_adaptation_hours"`
-	AuthorGitHubHours        float64 `json:"author_github_hours"`
-	AuthorGitHubContextHours float64 `json:"author_github_context_hours"`
-	AuthorTotalHours         float64 `json:"author_total_hours"`
+	AuthorNewCodeHours            float64 `json:"author_new_code_hours"`
+	AuthorAdaptationHours         float64 `json:"author_adaptation_hours"`
+	AuthorGitHubHours             float64 `json:"author_github_hours"`
+	AuthorGitHubContextHours      float64 `json:"author_github_context_hours"`
+	AuthorConflictResolutionHours float64 `json:"author_conflict_resolution_hours"`
+	AuthorTotalHours              float64 `json:"author_total_hours"`
 
 	// Author activity metrics (extrapolated)
-	AuthorEvents   int `json:"author_events"`   // Total GitHub events by authors
-	AuthorSessions int `json:"author_sessions"` // Total GitHub work sessions by authors
+	AuthorEvents        int `json:"author_events"`        // Total GitHub events by authors
+	AuthorSessions      int `json:"author_sessions"`      // Total GitHub work sessions by authors
+	ConflictResolutions int `json:"conflict_resolutions"` // Base-branch merges followed by more changes
 
 	// LOC metrics (extrapolated totals)
 	TotalNewLines      int `json:"total_new_lines"`      // Total net new lines across all PRs
 	TotalModifiedLines int `json:"total_modified_lines"` // Total modified lines across all PRs
+	TotalDeletedLines  int `json:"total_deleted_lines"`  // Total deleted lines across all PRs
+	NetLinesChanged    int `json:"net_lines_changed"`    // Net LOC change (additions - deletions) across all PRs
 	BotNewLines        int `json:"bot_new_lines"`        // Total net new lines from bot PRs
 	BotModifiedLines   int `json:"bot_modified_lines"`   // Total modified lines from bot PRs
 	OpenPRs            int `json:"open_prs"`             // Number of currently open PRs
+	// Repositories whose open PRs could not be counted; if non-zero, OpenPRs is a lower bound
+	OpenPRsFailedRepos int `json:"open_prs_failed_repos,omitempty"`
+	// OpenPRsPartial is true when only repositories with PRs in the analysis window were counted,
+	// so OpenPRs is a lower bound
+	OpenPRsPartial bool `json:"open_prs_partial,omitempty"`
+
+	// Zombie PRs: old open PRs that are still poked but not progressing (extrapolated)
+	ZombiePRs           int     `json:"zombie_prs"`            // Estimated number of zombie PRs
+	ZombieTrackingCost  float64 `json:"zombie_tracking_cost"`  // Tracking cost carried by zombie PRs
+	ZombieTrackingHours float64 `json:"zombie_tracking_hours"` // Tracking hours carried by zombie PRs
+
+	// Abandoned PRs: closed without merging, so their code cost delivered no value (extrapolated)
+	AbandonedPRs   int     `json:"abandoned_prs"`   // Estimated number of PRs closed without merging
+	AbandonedCost  float64 `json:"abandoned_cost"`  // Author code cost of abandoned PRs (included in author costs)
+	AbandonedHours float64 `json:"abandoned_hours"` // Author code hours of abandoned PRs
+
+	// Revert PRs: undo earlier work, so their whole cost is preventable waste (extrapolated)
+	RevertPRs   int     `json:"revert_prs"`   // Estimated number of revert PRs
+	RevertCost  float64 `json:"revert_cost"`  // Author and participant cost of revert PRs (included in their costs)
+	RevertHours float64 `json:"revert_hours"` // Author and participant hours of revert PRs
 
 	// Participant costs (extrapolated, combined across all reviewers)
 	ParticipantReviewCost  float64 `json:"participant_review_cost"`
@@ -111,6 +139,11 @@ type ExtrapolatedBreakdown struct {
 	ParticipantContextCost float64 `json:"participant_context_cost"`
 	ParticipantTotalCost   float64 `json:"participant_total_cost"`
 
+	// ParticipantReviewWaitCost is what requested reviewers were charged for keeping authors
+	// waiting (see Config.ReviewWaitFactor). It is included in ParticipantTotalCost, and is waste.
+	ParticipantReviewWaitCost  float64 `json:"participant_review_wait_cost"`
+	ParticipantReviewWaitHours float64 `json:"participant_review_wait_hours"`
+
 	// Participant hours (extrapolated)
 	ParticipantReviewHours  float64 `json:"participant_review_hours"`
 	ParticipantGitHubHours  float64 `json:"participant_github_hours"`
@@ -132,6 +165,10 @@ type ExtrapolatedBreakdown struct {
 	FutureContextCost    float64 `json:"future_context_cost"`
 	DelayTotalCost       float64 `json:"delay_total_cost"`
 
+	// Delivery delay capacity cap (see Config.DeliveryDelayCapacityFraction)
+	DeliveryDelayCapped       bool    `json:"delivery_delay_capped"`        // Whether delivery delay was capped at org capacity
+	UncappedDeliveryDelayCost float64 `json:"uncapped_delivery_delay_cost"` // Naive per-PR delivery delay sum before capping
+
 	// Delay hours (extrapolated)
 	DeliveryDelayHours    float64 `json:"delivery_delay_hours"`
 	CodeChurnHours        float64 `json:"code_churn_hours"`
@@ -152,6 +189,13 @@ type ExtrapolatedBreakdown struct {
 	// Grand totals
 	TotalCost  float64 `json:"total_cost"`
 	TotalHours float64 `json:"total_hours"`
+	Currency   string  `json:"currency,omitempty"` // ISO 4217 code the costs are in (Config.ReportingCurrency)
+
+	// Unit economics
+	CostPerMergedPR float64 `json:"cost_per_merged_pr"` // Total cost / merged PRs (what each shipped PR costs)
+	CostPerOpenedPR float64 `json:"cost_per_opened_pr"` // Total cost / PRs opened in the period
+	OpenedPRs       int     `json:"opened_prs"`         // PRs created within the period
+	CostPerLOC      float64 `json:"cost_per_loc"`       // Total cost / lines added by human PRs (0 if none)
 
 	// Merge rate statistics
 	MergedPRs     int     `json:"merged_prs"`      // Number of successfully merged PRs
@@ -160,16 +204,90 @@ type ExtrapolatedBreakdown struct {
 	MergeRateNote string  `json:"merge_rate_note"` // Explanation of what counts as merged/unmerged
 
 	// Grading (computed from metrics above)
-	EfficiencyGrade       string `json:"efficiency_grade"`         // Letter grade for development efficiency
-	EfficiencyMessage     string `json:"efficiency_message"`       // Description of efficiency grade
-	MergeVelocityGrade    string `json:"merge_velocity_grade"`     // Letter grade for merge velocity
-	MergeVelocityMessage  string `json:"merge_velocity_message"`   // Description of merge velocity grade
-	MergeRateGrade        string `json:"merge_rate_grade"`         // Letter grade for merge rate
-	MergeRateGradeMessage string `json:"merge_rate_grade_message"` // Description of merge rate grade
-
-	// R2R cost savings calculation
+	EfficiencyPct         float64 `json:"efficiency_pct"`           // Percentage of cost that was productive (0-100)
+	EfficiencyGrade       string  `json:"efficiency_grade"`         // Letter grade for development efficiency
+	EfficiencyMessage     string  `json:"efficiency_message"`       // Description of efficiency grade
+	MergeVelocityGrade    string  `json:"merge_velocity_grade"`     // Letter grade for merge velocity
+	MergeVelocityMessage  string  `json:"merge_velocity_message"`   // Description of merge velocity grade
+	MergeVelocityBasis    string  `json:"merge_velocity_basis"`     // Open time the velocity grade is based on: "mean" or "p50"
+	MergeRateGrade        string  `json:"merge_rate_grade"`         // Letter grade for merge rate
+	MergeRateGradeMessage string  `json:"merge_rate_grade_message"` // Description of merge rate grade
+
+	// 95% confidence interval for TotalCost (same as Ranges.Total), so a single result
+	// carries an honest error bar. Zero StdErr means fewer than two samples or a full census.
+	TotalCostStdErr   float64 `json:"total_cost_std_err"`
+	TotalCostCI95Low  float64 `json:"total_cost_ci95_low"`
+	TotalCostCI95High float64 `json:"total_cost_ci95_high"`
+
+	// 95% confidence ranges for the major line items, from per-PR variation in the sample
+	Ranges ComponentRanges `json:"ranges"`
+
+	// Per-author rollup of sampled human PRs, sorted by extrapolated cost (highest first)
+	AuthorRollups []AuthorRollup `json:"author_rollups"`
+
+	// Per-change-type rollup of sampled PRs (feature, fix, chore, ...), sorted by extrapolated cost
+	ChangeTypeRollups []ChangeTypeRollup `json:"change_type_rollups"`
+
+	// Sampled PRs counted by open time (<1h, 1-4h, 4-24h, 1-3d, 3-7d, >7d)
+	DurationHistogram []DurationBucket `json:"duration_histogram"`
+
+	// Per-PR breakdowns of the sample, most expensive first; only set when requested (see SampleBreakdowns)
+	Samples []SampleBreakdown `json:"samples,omitempty"`
+
+	// Merge time savings calculation
 	UniqueNonBotUsers int     `json:"unique_non_bot_users"` // Count of unique non-bot users (authors + participants)
-	R2RSavings        float64 `json:"r2r_savings"`          // Annual savings if R2R cuts PR time to target merge time
+	PotentialSavings  float64 `json:"potential_savings"`    // Annual savings if PRs merged within the target merge time
+	R2RSavings        float64 `json:"r2r_savings"`          // PotentialSavings net of the R2R subscription; zeroed when the callout is disabled
+}
+
+// AuthorRollup summarizes the sampled PRs of a single human author.
+type AuthorRollup struct {
+	Author        string  `json:"author"`
+	SampledPRs    int     `json:"sampled_prs"`    // Number of this author's PRs in the sample
+	TotalCost     float64 `json:"total_cost"`     // Sampled PR costs weighted up to the total PR count
+	AvgEfficiency float64 `json:"avg_efficiency"` // Average per-PR efficiency percentage (0-100)
+}
+
+// SampleBreakdown is the unextrapolated cost breakdown of one sampled PR.
+type SampleBreakdown struct {
+	URL       string    `json:"url"`
+	Breakdown Breakdown `json:"breakdown"`
+}
+
+// SampleBreakdowns pairs sampled PR URLs with their breakdowns (aligned by index), sorted by
+// TotalCost, highest first, so callers can drill into the PRs that drove an extrapolated cost.
+func SampleBreakdowns(urls []string, breakdowns []Breakdown) []SampleBreakdown {
+	samples := make([]SampleBreakdown, 0, len(breakdowns))
+	for i := range breakdowns {
+		var url string
+		if i < len(urls) {
+			url = urls[i]
+		}
+		samples = append(samples, SampleBreakdown{URL: url, Breakdown: breakdowns[i]})
+	}
+	slices.SortStableFunc(samples, func(a, b SampleBreakdown) int {
+		return cmp.Compare(b.Breakdown.TotalCost, a.Breakdown.TotalCost)
+	})
+	return samples
+}
+
+// sampleWeights returns each breakdown's SampleWeight scaled so the weights sum to the number
+// of breakdowns. Weighted averages over the sample then divide by the sample count as usual,
+// and an unweighted sample (every SampleWeight zero) gets a weight of exactly 1 per PR.
+func sampleWeights(breakdowns []Breakdown) []float64 {
+	weights := make([]float64, len(breakdowns))
+	var sum float64
+	for i := range breakdowns {
+		weights[i] = 1
+		if breakdowns[i].SampleWeight > 0 {
+			weights[i] = breakdowns[i].SampleWeight
+		}
+		sum += weights[i]
+	}
+	for i := range weights {
+		weights[i] *= float64(len(weights)) / sum
+	}
+	return weights
 }
 
 // ExtrapolateFromSamples calculates extrapolated cost estimates from a sample
@@ -189,10 +307,29 @@ type ExtrapolatedBreakdown struct {
 //   - ExtrapolatedBreakdown with averaged costs scaled to total population
 //
 // The function computes the average cost per PR from the samples, then multiplies
-// by the total PR count to estimate population-wide costs.
+// by the total PR count to estimate population-wide costs. Samples picked by weighted
+// sampling count in proportion to their Breakdown.SampleWeight, which corrects for
+// long-lived PRs being over-sampled. Because the weights are normalized by their sum (see
+// sampleWeights), the average is a ratio estimate: consistent, but slightly biased for
+// small samples.
 //
-//nolint:revive,maintidx // Complex calculation function benefits from cohesion
+// The analysis window is taken to end now; use ExtrapolateFromSamplesUntil for a window
+// ending in the past.
 func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actualOpenPRs int, daysInPeriod int, cfg Config, prs []PRSummaryInfo, repoVisibility map[string]bool) ExtrapolatedBreakdown {
+	return ExtrapolateFromSamplesUntil(breakdowns, totalPRs, totalAuthors, actualOpenPRs, daysInPeriod, cfg, prs, repoVisibility, time.Time{})
+}
+
+// ExtrapolateFromSamplesUntil is ExtrapolateFromSamples for an analysis window ending at until
+// (zero means now). Which PRs count as opened in the period, which open PRs are too old to
+// count toward durations, and how long open PRs have been open are all measured from until,
+// so a past window gives the same results whenever it is analyzed.
+//
+//nolint:revive,maintidx // Complex calculation function benefits from cohesion
+func ExtrapolateFromSamplesUntil(breakdowns []Breakdown, totalPRs, totalAuthors, actualOpenPRs int, daysInPeriod int, cfg Config, prs []PRSummaryInfo, repoVisibility map[string]bool, until time.Time) ExtrapolatedBreakdown {
+	if until.IsZero() {
+		until = time.Now()
+	}
+
 	// Count unique repositories and their visibility
 	uniqueRepos := make(map[string]bool)
 	publicCount := 0
@@ -225,7 +362,7 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 		var humanCount, botCount int
 		var humanDuration, botDuration float64
 		var countedPRs int
-		createdCutoff := time.Now().AddDate(0, 0, -daysInPeriod*2) // 2x the analysis period
+		createdCutoff := until.AddDate(0, 0, -daysInPeriod*2) // 2x the analysis period
 
 		var skippedOpen int
 		for i := range prs {
@@ -243,12 +380,12 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 				mergedCount++
 			}
 
-			// Calculate PR duration from CreatedAt to ClosedAt (or now if still open)
+			// Calculate PR duration from CreatedAt to ClosedAt (or the window's end if still open)
 			var duration float64
 			if prs[i].ClosedAt != nil {
 				duration = prs[i].ClosedAt.Sub(prs[i].CreatedAt).Hours()
 			} else {
-				duration = time.Since(prs[i].CreatedAt).Hours()
+				duration = until.Sub(prs[i].CreatedAt).Hours()
 			}
 			sumPRDuration += duration
 
@@ -287,6 +424,7 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 		}
 
 		return ExtrapolatedBreakdown{
+			Currency:                cfg.reportingCurrency(),
 			TotalPRs:                totalPRs,
 			SampledPRs:              0,
 			SuccessfulSamples:       0,
@@ -305,6 +443,7 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 
 	successfulSamples := len(breakdowns)
 	multiplier := float64(totalPRs)
+	weights := sampleWeights(breakdowns)
 
 	// Track unique PR authors (excluding bots)
 	uniqueAuthors := make(map[string]bool)
@@ -312,14 +451,17 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 	uniqueNonBotUsers := make(map[string]bool)
 
 	// Track bot vs human PR metrics
-	var humanPRCount, botPRCount int
+	var humanPRCount, botPRCount float64
 	var sumHumanPRDuration, sumBotPRDuration float64
 
 	// Accumulate costs from all samples
 	var sumAuthorNewCodeCost, sumAuthorAdaptationCost, sumAuthorGitHubCost, sumAuthorGitHubContextCost float64
 	var sumAuthorNewCodeHours, sumAuthorAdaptationHours, sumAuthorGitHubHours, sumAuthorGitHubContextHours float64
+	var sumAuthorConflictCost, sumAuthorConflictHours float64
+	var sumConflictResolutions float64
 	var sumParticipantReviewCost, sumParticipantGitHubCost, sumParticipantContextCost, sumParticipantCost float64
 	var sumParticipantReviewHours, sumParticipantGitHubHours, sumParticipantContextHours, sumParticipantHours float64
+	var sumParticipantReviewWaitCost, sumParticipantReviewWaitHours float64
 	var sumDeliveryDelayCost, sumCodeChurnCost, sumAutomatedUpdatesCost, sumPRTrackingCost float64
 	var sumFutureReviewCost, sumFutureMergeCost, sumFutureContextCost, sumDelayCost float64
 	var sumDeliveryDelayHours, sumCodeChurnHours, sumAutomatedUpdatesHours, sumPRTrackingHours float64
@@ -327,119 +469,175 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 	var sumAuthorHours float64
 	var sumTotalCost float64
 	var sumPRDuration float64
-	var sumNewLines, sumModifiedLines int
-	var sumBotNewLines, sumBotModifiedLines int
-	var sumAuthorEvents, sumAuthorSessions int
-	var sumParticipantEvents, sumParticipantSessions, sumParticipantReviews int
-	var sumFutureContextSessions int
+	var sumNewLines, sumModifiedLines, sumAddedLines, sumDeletedLines float64
+	var sumBotNewLines, sumBotModifiedLines float64
+	var sumHumanAddedLines float64
+	var sumAuthorEvents, sumAuthorSessions float64
+	var sumParticipantEvents, sumParticipantSessions, sumParticipantReviews float64
+	var sumFutureContextSessions float64
 	var sumReworkPercentage float64
-	var countCodeChurn, countFutureReview, countFutureMerge int
+	var countZombie float64
+	var countAbandoned float64
+	var sumAbandonedCost, sumAbandonedHours float64
+	var countRevert float64
+	var sumRevertCost, sumRevertHours float64
+	var sumZombieTrackingCost, sumZombieTrackingHours float64
+	var countCodeChurn, countFutureReview, countFutureMerge float64
 
 	for i := range breakdowns {
 		breakdown := &breakdowns[i]
+		w := weights[i]
 
 		// Track unique PR authors only (excluding bots)
 		if !breakdown.AuthorBot {
 			uniqueAuthors[breakdown.PRAuthor] = true
 			uniqueNonBotUsers[breakdown.PRAuthor] = true
-			humanPRCount++
-			sumHumanPRDuration += breakdown.PRDuration
+			humanPRCount += w
+			sumHumanPRDuration += w * breakdown.PRDuration
+			sumHumanAddedLines += w * float64(breakdown.Author.LinesAdded)
 		} else {
-			botPRCount++
-			sumBotPRDuration += breakdown.PRDuration
+			botPRCount += w
+			sumBotPRDuration += w * breakdown.PRDuration
 			// Track bot PR LOC separately
-			sumBotNewLines += breakdown.Author.NewLines
-			sumBotModifiedLines += breakdown.Author.ModifiedLines
+			sumBotNewLines += w * float64(breakdown.Author.NewLines)
+			sumBotModifiedLines += w * float64(breakdown.Author.ModifiedLines)
 		}
 
 		// Track unique participants (excluding bots)
 		for _, p := range breakdown.Participants {
 			// Participants from the Breakdown struct are already filtered to exclude bots
 			uniqueNonBotUsers[p.Actor] = true
+			// Co-authors wrote part of the code, so they count as authors too
+			if p.CoAuthoredHours > 0 {
+				uniqueAuthors[p.Actor] = true
+			}
 		}
 
 		// Accumulate PR duration (all PRs)
-		sumPRDuration += breakdown.PRDuration
+		sumPRDuration += w * breakdown.PRDuration
 
 		// Accumulate LOC metrics (all PRs)
-		sumNewLines += breakdown.Author.NewLines
-		sumModifiedLines += breakdown.Author.ModifiedLines
+		sumNewLines += w * float64(breakdown.Author.NewLines)
+		sumModifiedLines += w * float64(breakdown.Author.ModifiedLines)
+		sumAddedLines += w * float64(breakdown.Author.LinesAdded)
+		sumDeletedLines += w * float64(breakdown.Author.LinesDeleted)
+
+		if breakdown.Zombie {
+			countZombie += w
+			sumZombieTrackingCost += w * breakdown.DelayCostDetail.PRTrackingCost
+			sumZombieTrackingHours += w * breakdown.DelayCostDetail.PRTrackingHours
+		}
+		if breakdown.Abandoned {
+			countAbandoned += w
+			sumAbandonedCost += w * breakdown.AbandonedCost
+			sumAbandonedHours += w * breakdown.AbandonedHours
+		}
+		if breakdown.IsRevert {
+			countRevert += w
+			sumRevertCost += w * breakdown.RevertCost
+			sumRevertHours += w * breakdown.RevertHours
+		}
 
 		// Accumulate author costs
-		sumAuthorNewCodeCost += breakdown.Author.NewCodeCost
-		sumAuthorAdaptationCost += breakdown.Author.AdaptationCost
-		sumAuthorGitHubCost += breakdown.Author.GitHubCost
-		sumAuthorGitHubContextCost += breakdown.Author.GitHubContextCost
-		sumAuthorNewCodeHours += breakdown.Author.NewCodeHours
-		sumAuthorAdaptationHours += breakdown.Author.AdaptationHours
-		sumAuthorGitHubHours += breakdown.Author.GitHubHours
-		sumAuthorGitHubContextHours += breakdown.Author.GitHubContextHours
-		sumAuthorHours += breakdown.Author.TotalHours
-		sumAuthorEvents += breakdown.Author.Events
-		sumAuthorSessions += breakdown.Author.Sessions
+		sumAuthorNewCodeCost += w * breakdown.Author.NewCodeCost
+		sumAuthorAdaptationCost += w * breakdown.Author.AdaptationCost
+		sumAuthorGitHubCost += w * breakdown.Author.GitHubCost
+		sumAuthorGitHubContextCost += w * breakdown.Author.GitHubContextCost
+		sumAuthorNewCodeHours += w * breakdown.Author.NewCodeHours
+		sumAuthorAdaptationHours += w * breakdown.Author.AdaptationHours
+		sumAuthorGitHubHours += w * breakdown.Author.GitHubHours
+		sumAuthorGitHubContextHours += w * breakdown.Author.GitHubContextHours
+		sumAuthorConflictCost += w * breakdown.Author.ConflictResolutionCost
+		sumAuthorConflictHours += w * breakdown.Author.ConflictResolutionHours
+		sumConflictResolutions += w * float64(breakdown.Author.ConflictResolutions)
+		sumAuthorHours += w * breakdown.Author.TotalHours
+		sumAuthorEvents += w * float64(breakdown.Author.Events)
+		sumAuthorSessions += w * float64(breakdown.Author.Sessions)
 
 		// Accumulate participant costs (combined across all participants)
 		for _, p := range breakdown.Participants {
-			sumParticipantReviewCost += p.ReviewCost
-			sumParticipantGitHubCost += p.GitHubCost
-			sumParticipantContextCost += p.GitHubContextCost
-			sumParticipantCost += p.TotalCost
-			sumParticipantReviewHours += p.ReviewHours
-			sumParticipantGitHubHours += p.GitHubHours
-			sumParticipantContextHours += p.GitHubContextHours
-			sumParticipantHours += p.TotalHours
-			sumParticipantEvents += p.Events
-			sumParticipantSessions += p.Sessions
+			sumParticipantReviewCost += w * p.ReviewCost
+			sumParticipantGitHubCost += w * p.GitHubCost
+			sumParticipantContextCost += w * p.GitHubContextCost
+			sumParticipantCost += w * p.TotalCost
+			sumParticipantReviewHours += w * p.ReviewHours
+			sumParticipantGitHubHours += w * p.GitHubHours
+			sumParticipantContextHours += w * p.GitHubContextHours
+			sumParticipantHours += w * p.TotalHours
+			sumParticipantReviewWaitCost += w * p.ReviewWaitCost
+			sumParticipantReviewWaitHours += w * p.ReviewWaitHours
+			sumParticipantEvents += w * float64(p.Events)
+			sumParticipantSessions += w * float64(p.Sessions)
 			if p.ReviewCost > 0 {
-				sumParticipantReviews++ // Count reviewers (participants who performed reviews)
+				sumParticipantReviews += w // Count reviewers (participants who performed reviews)
 			}
 		}
 
 		// Accumulate delay costs
-		sumDeliveryDelayCost += breakdown.DelayCostDetail.DeliveryDelayCost
-		sumCodeChurnCost += breakdown.DelayCostDetail.CodeChurnCost
-		sumAutomatedUpdatesCost += breakdown.DelayCostDetail.AutomatedUpdatesCost
-		sumPRTrackingCost += breakdown.DelayCostDetail.PRTrackingCost
-		sumFutureReviewCost += breakdown.DelayCostDetail.FutureReviewCost
-		sumFutureMergeCost += breakdown.DelayCostDetail.FutureMergeCost
-		sumFutureContextCost += breakdown.DelayCostDetail.FutureContextCost
+		sumDeliveryDelayCost += w * breakdown.DelayCostDetail.DeliveryDelayCost
+		sumCodeChurnCost += w * breakdown.DelayCostDetail.CodeChurnCost
+		sumAutomatedUpdatesCost += w * breakdown.DelayCostDetail.AutomatedUpdatesCost
+		sumPRTrackingCost += w * breakdown.DelayCostDetail.PRTrackingCost
+		sumFutureReviewCost += w * breakdown.DelayCostDetail.FutureReviewCost
+		sumFutureMergeCost += w * breakdown.DelayCostDetail.FutureMergeCost
+		sumFutureContextCost += w * breakdown.DelayCostDetail.FutureContextCost
 
 		// Count PRs with each future cost type and accumulate rework percentage
 		if breakdown.DelayCostDetail.CodeChurnCost > 0.01 {
-			countCodeChurn++
-			sumReworkPercentage += breakdown.DelayCostDetail.ReworkPercentage
+			countCodeChurn += w
+			sumReworkPercentage += w * breakdown.DelayCostDetail.ReworkPercentage
 		}
 		if breakdown.DelayCostDetail.FutureReviewCost > 0.01 {
-			countFutureReview++
+			countFutureReview += w
 		}
 		if breakdown.DelayCostDetail.FutureMergeCost > 0.01 {
-			countFutureMerge++
+			countFutureMerge += w
 		}
 		if breakdown.DelayCostDetail.FutureContextCost > 0.01 {
-			// Future context cost assumes 3 sessions per open PR (review request, review, merge)
-			sumFutureContextSessions += 3
+			// Future context cost assumes 3 sessions per open PR (review request, review, merge),
+			// and 1 for an approved PR that only needs merging
+			if breakdown.DelayCostDetail.FutureReviewCost > 0.01 {
+				sumFutureContextSessions += 3 * w
+			} else {
+				sumFutureContextSessions += w
+			}
 		}
-		sumDeliveryDelayHours += breakdown.DelayCostDetail.DeliveryDelayHours
-		sumCodeChurnHours += breakdown.DelayCostDetail.CodeChurnHours
-		sumAutomatedUpdatesHours += breakdown.DelayCostDetail.AutomatedUpdatesHours
-		sumPRTrackingHours += breakdown.DelayCostDetail.PRTrackingHours
-		sumFutureReviewHours += breakdown.DelayCostDetail.FutureReviewHours
-		sumFutureMergeHours += breakdown.DelayCostDetail.FutureMergeHours
-		sumFutureContextHours += breakdown.DelayCostDetail.FutureContextHours
-		sumDelayCost += breakdown.DelayCost
-		sumDelayHours += breakdown.DelayCostDetail.TotalDelayHours
-
-		sumTotalCost += breakdown.TotalCost
+		sumDeliveryDelayHours += w * breakdown.DelayCostDetail.DeliveryDelayHours
+		sumCodeChurnHours += w * breakdown.DelayCostDetail.CodeChurnHours
+		sumAutomatedUpdatesHours += w * breakdown.DelayCostDetail.AutomatedUpdatesHours
+		sumPRTrackingHours += w * breakdown.DelayCostDetail.PRTrackingHours
+		sumFutureReviewHours += w * breakdown.DelayCostDetail.FutureReviewHours
+		sumFutureMergeHours += w * breakdown.DelayCostDetail.FutureMergeHours
+		sumFutureContextHours += w * breakdown.DelayCostDetail.FutureContextHours
+		sumDelayCost += w * breakdown.DelayCost
+		sumDelayHours += w * breakdown.DelayCostDetail.TotalDelayHours
+
+		sumTotalCost += w * breakdown.TotalCost
 	}
 
 	// Calculate averages and extrapolate to total PRs
 	samples := float64(successfulSamples)
 
 	// Extrapolate LOC metrics
-	extTotalNewLines := int(float64(sumNewLines) / samples * multiplier)
-	extTotalModifiedLines := int(float64(sumModifiedLines) / samples * multiplier)
-	extBotNewLines := int(float64(sumBotNewLines) / samples * multiplier)
-	extBotModifiedLines := int(float64(sumBotModifiedLines) / samples * multiplier)
+	extTotalNewLines := int(sumNewLines / samples * multiplier)
+	extTotalModifiedLines := int(sumModifiedLines / samples * multiplier)
+	extTotalDeletedLines := int(sumDeletedLines / samples * multiplier)
+	// Net change is what actually grew (or shrank) the codebase
+	extNetLinesChanged := int((sumAddedLines - sumDeletedLines) / samples * multiplier)
+	extBotNewLines := int(sumBotNewLines / samples * multiplier)
+	extBotModifiedLines := int(sumBotModifiedLines / samples * multiplier)
+
+	extZombiePRs := int(countZombie / samples * multiplier)
+	extZombieTrackingCost := sumZombieTrackingCost / samples * multiplier
+	extZombieTrackingHours := sumZombieTrackingHours / samples * multiplier
+
+	extAbandonedPRs := int(countAbandoned / samples * multiplier)
+	extAbandonedCost := sumAbandonedCost / samples * multiplier
+	extAbandonedHours := sumAbandonedHours / samples * multiplier
+
+	extRevertPRs := int(countRevert / samples * multiplier)
+	extRevertCost := sumRevertCost / samples * multiplier
+	extRevertHours := sumRevertHours / samples * multiplier
 
 	extAuthorNewCodeCost := sumAuthorNewCodeCost / samples * multiplier
 	extAuthorAdaptationCost := sumAuthorAdaptationCost / samples * multiplier
@@ -449,10 +647,13 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 	extAuthorAdaptationHours := sumAuthorAdaptationHours / samples * multiplier
 	extAuthorGitHubHours := sumAuthorGitHubHours / samples * multiplier
 	extAuthorGitHubContextHours := sumAuthorGitHubContextHours / samples * multiplier
-	extAuthorTotal := extAuthorNewCodeCost + extAuthorAdaptationCost + extAuthorGitHubCost + extAuthorGitHubContextCost
+	extAuthorConflictCost := sumAuthorConflictCost / samples * multiplier
+	extAuthorConflictHours := sumAuthorConflictHours / samples * multiplier
+	extConflictResolutions := int(sumConflictResolutions / samples * multiplier)
+	extAuthorTotal := extAuthorNewCodeCost + extAuthorAdaptationCost + extAuthorGitHubCost + extAuthorGitHubContextCost + extAuthorConflictCost
 	extAuthorHours := sumAuthorHours / samples * multiplier
-	extAuthorEvents := int(float64(sumAuthorEvents) / samples * multiplier)
-	extAuthorSessions := int(float64(sumAuthorSessions) / samples * multiplier)
+	extAuthorEvents := int(sumAuthorEvents / samples * multiplier)
+	extAuthorSessions := int(sumAuthorSessions / samples * multiplier)
 
 	extParticipantReviewCost := sumParticipantReviewCost / samples * multiplier
 	extParticipantGitHubCost := sumParticipantGitHubCost / samples * multiplier
@@ -462,29 +663,24 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 	extParticipantGitHubHours := sumParticipantGitHubHours / samples * multiplier
 	extParticipantContextHours := sumParticipantContextHours / samples * multiplier
 	extParticipantHours := sumParticipantHours / samples * multiplier
-	extParticipantEvents := int(float64(sumParticipantEvents) / samples * multiplier)
-	extParticipantSessions := int(float64(sumParticipantSessions) / samples * multiplier)
-	extParticipantReviews := int(float64(sumParticipantReviews) / samples * multiplier)
+	extParticipantReviewWaitCost := sumParticipantReviewWaitCost / samples * multiplier
+	extParticipantReviewWaitHours := sumParticipantReviewWaitHours / samples * multiplier
+	extParticipantEvents := int(sumParticipantEvents / samples * multiplier)
+	extParticipantSessions := int(sumParticipantSessions / samples * multiplier)
+	extParticipantReviews := int(sumParticipantReviews / samples * multiplier)
 
 	extDeliveryDelayCost := sumDeliveryDelayCost / samples * multiplier
 	extCodeChurnCost := sumCodeChurnCost / samples * multiplier
 	extAutomatedUpdatesCost := sumAutomatedUpdatesCost / samples * multiplier
 	// Calculate Open PR Tracking cost based on actual open PRs (not from samples)
-	// Formula: openPRs × log2(activeContributors + 1) × 0.005 × daysInPeriod × hourlyRate
+	// Formula: openPRs × log2(activeContributors + 1) × PRTrackingMinutesPerDay / 60 × daysInPeriod
 	// This represents planning/coordination overhead ONLY (excludes actual code review)
 	// - Linear with PR count: more PRs = more planning/triage overhead
 	// - Logarithmic with team size: larger teams have specialization/better processes
-	// - Constant 0.005: calibrated to ~20 seconds per PR per week of planning/coordination time
-	//   (excludes actual review time, which is counted separately in FutureReviewCost)
+	// This is the same model Calculate applies to a single open PR (see PRTrackingHours).
 	hourlyRate := cfg.AnnualSalary * cfg.BenefitsMultiplier / cfg.HoursPerYear
 	uniqueUserCount := len(uniqueNonBotUsers)
-	var extPRTrackingHours float64
-	if uniqueUserCount > 0 && actualOpenPRs > 0 {
-		// log2(n+1) to handle log(0) and provide smooth scaling
-		teamScaleFactor := math.Log2(float64(uniqueUserCount) + 1)
-		// 0.005 hours = 0.30 minutes per PR per day (organizational average for planning/coordination only)
-		extPRTrackingHours = float64(actualOpenPRs) * teamScaleFactor * 0.005 * float64(daysInPeriod)
-	}
+	extPRTrackingHours := PRTrackingHours(actualOpenPRs, uniqueUserCount, float64(daysInPeriod), cfg)
 	extPRTrackingCost := extPRTrackingHours * hourlyRate
 	extFutureReviewCost := sumFutureReviewCost / samples * multiplier
 	extFutureMergeCost := sumFutureMergeCost / samples * multiplier
@@ -499,17 +695,37 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 	extDelayHours := sumDelayHours / samples * multiplier
 
 	// Extrapolate future cost counts
-	extCodeChurnPRCount := int(float64(countCodeChurn) / samples * multiplier)
-	extFutureReviewPRCount := int(float64(countFutureReview) / samples * multiplier)
-	extFutureMergePRCount := int(float64(countFutureMerge) / samples * multiplier)
-	extFutureContextSessions := int(float64(sumFutureContextSessions) / samples * multiplier)
+	extCodeChurnPRCount := int(countCodeChurn / samples * multiplier)
+	extFutureReviewPRCount := int(countFutureReview / samples * multiplier)
+	extFutureMergePRCount := int(countFutureMerge / samples * multiplier)
+	extFutureContextSessions := int(sumFutureContextSessions / samples * multiplier)
 	// Use actual open PR count from repository query, not extrapolated from sample
 	extOpenPRs := actualOpenPRs
 
 	// Calculate average rework percentage (only for PRs with code churn)
 	var avgReworkPercentage float64
 	if countCodeChurn > 0 {
-		avgReworkPercentage = sumReworkPercentage / float64(countCodeChurn)
+		avgReworkPercentage = sumReworkPercentage / countCodeChurn
+	}
+
+	// Cap delivery delay at a fraction of what the authors could have worked in the period.
+	// Summed per-PR opportunity cost overlaps when many PRs are open at once.
+	uncappedDeliveryDelayCost := extDeliveryDelayCost
+	capacityCost := DelayCapacityCost(totalAuthors, daysInPeriod, hourlyRate, cfg)
+	deliveryDelayCapped := false
+	if capacityCost > 0 && extDeliveryDelayCost > capacityCost {
+		slog.Warn("Delivery delay exceeds org capacity, capping",
+			"uncapped_cost", extDeliveryDelayCost,
+			"capacity_cap", capacityCost,
+			"capacity_fraction", cfg.DeliveryDelayCapacityFraction,
+			"total_authors", totalAuthors,
+			"days_in_period", daysInPeriod)
+		scale := capacityCost / extDeliveryDelayCost
+		extDelayTotal -= extDeliveryDelayCost - capacityCost
+		extDelayHours -= extDeliveryDelayHours * (1 - scale)
+		extDeliveryDelayCost = capacityCost
+		extDeliveryDelayHours *= scale
+		deliveryDelayCapped = true
 	}
 
 	// Calculate total cost by summing components
@@ -519,42 +735,7 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 		extAutomatedUpdatesCost + extPRTrackingCost + extFutureReviewCost + extFutureMergeCost + extFutureContextCost
 	extTotalHours := extAuthorHours + extParticipantHours + extDelayHours
 
-	// Calculate waste per week metrics
-	var wasteHoursPerWeek, wasteCostPerWeek float64
-	var wasteHoursPerAuthorPerWeek, wasteCostPerAuthorPerWeek float64
 	authorCount := len(uniqueAuthors)
-	if daysInPeriod > 0 {
-		// Preventable hours = code churn + delivery delay + automated updates + PR tracking
-		preventableHours := extCodeChurnHours + extDeliveryDelayHours + extAutomatedUpdatesHours + extPRTrackingHours
-		preventableCost := extCodeChurnCost + extDeliveryDelayCost + extAutomatedUpdatesCost + extPRTrackingCost
-
-		// Calculate weeks in the period
-		weeksInPeriod := float64(daysInPeriod) / 7.0
-
-		// Wasted overhead per week (organizational)
-		wasteHoursPerWeek = preventableHours / weeksInPeriod
-		wasteCostPerWeek = preventableCost / weeksInPeriod
-
-		// Wasted overhead per author per week
-		if totalAuthors > 0 {
-			wasteHoursPerAuthorPerWeek = wasteHoursPerWeek / float64(totalAuthors)
-			wasteCostPerAuthorPerWeek = wasteCostPerWeek / float64(totalAuthors)
-		}
-
-		// Debug logging
-		slog.Info("Waste per week calculation",
-			"total_preventable_hours", preventableHours,
-			"total_preventable_cost", preventableCost,
-			"code_churn_hours", extCodeChurnHours,
-			"delivery_delay_hours", extDeliveryDelayHours,
-			"days_in_period", daysInPeriod,
-			"weeks_in_period", weeksInPeriod,
-			"waste_hours_per_week", wasteHoursPerWeek,
-			"waste_cost_per_week", wasteCostPerWeek,
-			"total_authors", totalAuthors,
-			"waste_hours_per_author_per_week", wasteHoursPerAuthorPerWeek,
-			"waste_cost_per_author_per_week", wasteCostPerAuthorPerWeek)
-	}
 
 	// Calculate average PR durations and human/bot breakdown from ALL PRs (not just samples)
 	// This provides more accurate merge velocity and bot/human metrics
@@ -568,7 +749,7 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 	var totalPRDuration float64
 	var allHumanPRCount, allBotPRCount int
 	var allHumanPRDuration, allBotPRDuration float64
-	createdCutoff := time.Now().AddDate(0, 0, -daysInPeriod*2) // 2x the analysis period
+	createdCutoff := until.AddDate(0, 0, -daysInPeriod*2) // 2x the analysis period
 
 	var skippedOpen, skippedClosed int
 	for i := range prs {
@@ -581,12 +762,12 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 			}
 		}
 
-		// Calculate PR duration from CreatedAt to ClosedAt (or now if still open)
+		// Calculate PR duration from CreatedAt to ClosedAt (or the window's end if still open)
 		var duration float64
 		if prs[i].ClosedAt != nil {
 			duration = prs[i].ClosedAt.Sub(prs[i].CreatedAt).Hours()
 		} else {
-			duration = time.Since(prs[i].CreatedAt).Hours()
+			duration = until.Sub(prs[i].CreatedAt).Hours()
 		}
 		totalPRDuration += duration
 
@@ -636,7 +817,12 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 	// Baseline annual waste: preventable cost extrapolated to 52 weeks
 	// uniqueUserCount already defined above for PR tracking calculation
 	preventableCost := extCodeChurnCost + extDeliveryDelayCost + extAutomatedUpdatesCost + extPRTrackingCost
-	baselineAnnualWaste := preventableCost * (52.0 / (float64(daysInPeriod) / 7.0))
+	// A zero-day period can't be annualized; leave the annual figures at zero rather than NaN
+	var periodsPerYear float64
+	if daysInPeriod > 0 {
+		periodsPerYear = 52.0 / (float64(daysInPeriod) / 7.0)
+	}
+	baselineAnnualWaste := preventableCost * periodsPerYear
 
 	// Re-model with target PR merge time from config
 	// We need to recalculate delivery delay and future costs assuming all PRs take the target merge time
@@ -677,16 +863,14 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 
 	// Calculate re-modeled annual waste
 	remodelPreventablePerPeriod := extRemodelDeliveryDelayCost + extRemodelCodeChurnCost + extRemodelAutomatedUpdatesCost + extRemodelPRTrackingCost
-	remodelAnnualWaste := remodelPreventablePerPeriod * (52.0 / (float64(daysInPeriod) / 7.0))
+	remodelAnnualWaste := remodelPreventablePerPeriod * periodsPerYear
+
+	// Calculate savings, independent of any tooling
+	potentialSavings := max(0, baselineAnnualWaste-remodelAnnualWaste) // Don't show negative savings
 
 	// Subtract R2R subscription cost: $4/mo * 12 months * unique user count
 	r2rAnnualCost := 4.0 * 12.0 * float64(uniqueUserCount)
-
-	// Calculate savings
-	r2rSavings := baselineAnnualWaste - remodelAnnualWaste - r2rAnnualCost
-	if r2rSavings < 0 {
-		r2rSavings = 0 // Don't show negative savings
-	}
+	r2rSavings := max(0, potentialSavings-r2rAnnualCost)
 
 	// Calculate merge rate from all PRs (not just samples)
 	mergedCount := 0
@@ -710,62 +894,131 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 		"unmerged", unmergedCount,
 		"merge_rate_pct", mergeRate)
 
+	// Unit economics: cost per merged PR uses the merge rate of the sampled PRs,
+	// since those are the PRs the costs were actually measured on.
+	var sampledMerged float64
+	for i := range breakdowns {
+		if breakdowns[i].Merged {
+			sampledMerged += weights[i]
+		}
+	}
+	var costPerMergedPR float64
+	if extMergedPRs := sampledMerged / samples * multiplier; extMergedPRs > 0 {
+		costPerMergedPR = extTotalCost / extMergedPRs
+	}
+
+	// Cost per opened PR counts only PRs created within the period
+	// (the population also includes older PRs that were merely modified)
+	openedPRs := totalPRs
+	if len(prs) > 0 {
+		openedPRs = 0
+		openedCutoff := until.AddDate(0, 0, -daysInPeriod)
+		for i := range prs {
+			if !prs[i].CreatedAt.Before(openedCutoff) {
+				openedPRs++
+			}
+		}
+	}
+	var costPerOpenedPR float64
+	if openedPRs > 0 {
+		costPerOpenedPR = extTotalCost / float64(openedPRs)
+	}
+
+	// Cost per line of code normalizes across teams; bot PRs' lines would inflate the denominator
+	var costPerLOC float64
+	if extHumanAddedLines := sumHumanAddedLines / samples * multiplier; extHumanAddedLines > 0 {
+		costPerLOC = extTotalCost / extHumanAddedLines
+	}
+
+	slog.Info("Calculated unit economics",
+		"sampled_merged", sampledMerged,
+		"opened_prs", openedPRs,
+		"cost_per_merged_pr", costPerMergedPR,
+		"cost_per_opened_pr", costPerOpenedPR,
+		"cost_per_loc", costPerLOC)
+
 	// Calculate efficiency percentage and grade
-	productiveCost := extAuthorTotal + extParticipantCost
+	// Abandoned code and reverts are author and participant cost that delivered nothing, and
+	// review waits are participant cost for idle time, so they count against efficiency
+	productiveCost := extAuthorTotal + extParticipantCost - extAbandonedCost - extRevertCost - extParticipantReviewWaitCost
 	efficiencyPct := 0.0
 	if extTotalCost > 0 {
 		efficiencyPct = 100.0 * productiveCost / extTotalCost
 	}
 	efficiencyGrade, efficiencyMessage := EfficiencyGrade(efficiencyPct)
 
-	// Calculate merge velocity grade
-	mergeVelocityGrade, mergeVelocityMessage := MergeVelocityGrade(avgPRDuration)
+	// Calculate merge velocity grade, on the sampled median if configured
+	p50PRDuration, p90PRDuration := durationPercentile(breakdowns, 50), durationPercentile(breakdowns, 90)
+	velocityBasis, velocityHours := "mean", avgPRDuration
+	if cfg.GradeVelocityByMedian {
+		velocityBasis, velocityHours = "p50", p50PRDuration
+	}
+	mergeVelocityGrade, mergeVelocityMessage := MergeVelocityGrade(velocityHours)
 
 	// Calculate merge rate grade
 	mergeRateGrade, mergeRateGradeMessage := MergeRateGrade(mergeRate)
 
-	return ExtrapolatedBreakdown{
-		TotalPRs:                   totalPRs,
-		HumanPRs:                   extHumanPRs,
-		BotPRs:                     extBotPRs,
-		SampledPRs:                 successfulSamples,
-		SuccessfulSamples:          successfulSamples,
-		UniqueAuthors:              authorCount,
-		TotalAuthors:               totalAuthors,
-		WasteHoursPerWeek:          wasteHoursPerWeek,
-		WasteCostPerWeek:           wasteCostPerWeek,
-		WasteHoursPerAuthorPerWeek: wasteHoursPerAuthorPerWeek,
-		WasteCostPerAuthorPerWeek:  wasteCostPerAuthorPerWeek,
-		AvgPRDurationHours:         avgPRDuration,
-		AvgHumanPRDurationHours:    avgHumanPRDuration,
-		AvgBotPRDurationHours:      avgBotPRDuration,
-
-		AuthorNewCodeCost:       extAuthorNewCodeCost,
-		AuthorAdaptationCost:    extAuthorAdaptationCost,
-		AuthorGitHubCost:        extAuthorGitHubCost,
-		AuthorGitHubContextCost: extAuthorGitHubContextCost,
-		AuthorTotalCost:         extAuthorTotal,
-
-		AuthorNewCodeHours:       extAuthorNewCodeHours,
-		AuthorAdaptationHours:    extAuthorAdaptationHours,
-		AuthorGitHubHours:        extAuthorGitHubHours,
-		AuthorGitHubContextHours: extAuthorGitHubContextHours,
-		AuthorTotalHours:         extAuthorHours,
-
-		AuthorEvents:   extAuthorEvents,
-		AuthorSessions: extAuthorSessions,
+	ext := ExtrapolatedBreakdown{
+		Currency:                cfg.reportingCurrency(),
+		TotalPRs:                totalPRs,
+		HumanPRs:                extHumanPRs,
+		BotPRs:                  extBotPRs,
+		SampledPRs:              successfulSamples,
+		SuccessfulSamples:       successfulSamples,
+		UniqueAuthors:           authorCount,
+		TotalAuthors:            totalAuthors,
+		AvgPRDurationHours:      avgPRDuration,
+		P50PRDurationHours:      p50PRDuration,
+		P90PRDurationHours:      p90PRDuration,
+		AvgHumanPRDurationHours: avgHumanPRDuration,
+		AvgBotPRDurationHours:   avgBotPRDuration,
+
+		AuthorNewCodeCost:            extAuthorNewCodeCost,
+		AuthorAdaptationCost:         extAuthorAdaptationCost,
+		AuthorGitHubCost:             extAuthorGitHubCost,
+		AuthorGitHubContextCost:      extAuthorGitHubContextCost,
+		AuthorConflictResolutionCost: extAuthorConflictCost,
+		AuthorTotalCost:              extAuthorTotal,
+
+		AuthorNewCodeHours:            extAuthorNewCodeHours,
+		AuthorAdaptationHours:         extAuthorAdaptationHours,
+		AuthorGitHubHours:             extAuthorGitHubHours,
+		AuthorGitHubContextHours:      extAuthorGitHubContextHours,
+		AuthorConflictResolutionHours: extAuthorConflictHours,
+		AuthorTotalHours:              extAuthorHours,
+
+		AuthorEvents:        extAuthorEvents,
+		AuthorSessions:      extAuthorSessions,
+		ConflictResolutions: extConflictResolutions,
 
 		TotalNewLines:      extTotalNewLines,
 		TotalModifiedLines: extTotalModifiedLines,
+		TotalDeletedLines:  extTotalDeletedLines,
+		NetLinesChanged:    extNetLinesChanged,
 		BotNewLines:        extBotNewLines,
 		BotModifiedLines:   extBotModifiedLines,
 		OpenPRs:            extOpenPRs,
 
+		ZombiePRs:           extZombiePRs,
+		ZombieTrackingCost:  extZombieTrackingCost,
+		ZombieTrackingHours: extZombieTrackingHours,
+
+		AbandonedPRs:   extAbandonedPRs,
+		AbandonedCost:  extAbandonedCost,
+		AbandonedHours: extAbandonedHours,
+
+		RevertPRs:   extRevertPRs,
+		RevertCost:  extRevertCost,
+		RevertHours: extRevertHours,
+
 		ParticipantReviewCost:  extParticipantReviewCost,
 		ParticipantGitHubCost:  extParticipantGitHubCost,
 		ParticipantContextCost: extParticipantContextCost,
 		ParticipantTotalCost:   extParticipantCost,
 
+		ParticipantReviewWaitCost:  extParticipantReviewWaitCost,
+		ParticipantReviewWaitHours: extParticipantReviewWaitHours,
+
 		ParticipantReviewHours:  extParticipantReviewHours,
 		ParticipantGitHubHours:  extParticipantGitHubHours,
 		ParticipantContextHours: extParticipantContextHours,
@@ -775,14 +1028,19 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 		ParticipantSessions: extParticipantSessions,
 		ParticipantReviews:  extParticipantReviews,
 
-		DeliveryDelayCost:    extDeliveryDelayCost,
-		CodeChurnCost:        extCodeChurnCost,
-		AutomatedUpdatesCost: extAutomatedUpdatesCost,
-		PRTrackingCost:       extPRTrackingCost,
-		FutureReviewCost:     extFutureReviewCost,
-		FutureMergeCost:      extFutureMergeCost,
-		FutureContextCost:    extFutureContextCost,
-		DelayTotalCost:       extDelayTotal,
+		DeliveryDelayCost:         extDeliveryDelayCost,
+		AuthorRollups:             authorRollups(breakdowns, weights, multiplier/samples),
+		ChangeTypeRollups:         changeTypeRollups(breakdowns, weights, multiplier/samples),
+		DurationHistogram:         durationHistogram(breakdowns),
+		DeliveryDelayCapped:       deliveryDelayCapped,
+		UncappedDeliveryDelayCost: uncappedDeliveryDelayCost,
+		CodeChurnCost:             extCodeChurnCost,
+		AutomatedUpdatesCost:      extAutomatedUpdatesCost,
+		PRTrackingCost:            extPRTrackingCost,
+		FutureReviewCost:          extFutureReviewCost,
+		FutureMergeCost:           extFutureMergeCost,
+		FutureContextCost:         extFutureContextCost,
+		DelayTotalCost:            extDelayTotal,
 
 		DeliveryDelayHours:    extDeliveryDelayHours,
 		CodeChurnHours:        extCodeChurnHours,
@@ -802,15 +1060,22 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 		TotalCost:  extTotalCost,
 		TotalHours: extTotalHours,
 
+		CostPerMergedPR: costPerMergedPR,
+		CostPerOpenedPR: costPerOpenedPR,
+		OpenedPRs:       openedPRs,
+		CostPerLOC:      costPerLOC,
+
 		MergedPRs:     mergedCount,
 		UnmergedPRs:   unmergedCount,
 		MergeRate:     mergeRate,
 		MergeRateNote: "Recently modified PRs successfully merged",
 
+		EfficiencyPct:         efficiencyPct,
 		EfficiencyGrade:       efficiencyGrade,
 		EfficiencyMessage:     efficiencyMessage,
 		MergeVelocityGrade:    mergeVelocityGrade,
 		MergeVelocityMessage:  mergeVelocityMessage,
+		MergeVelocityBasis:    velocityBasis,
 		MergeRateGrade:        mergeRateGrade,
 		MergeRateGradeMessage: mergeRateGradeMessage,
 
@@ -818,6 +1083,84 @@ func ExtrapolateFromSamples(breakdowns []Breakdown, totalPRs, totalAuthors, actu
 		UniqueRepositories:  len(uniqueRepos),
 		PublicRepositories:  publicCount,
 		PrivateRepositories: privateCount,
+		PotentialSavings:    potentialSavings,
 		R2RSavings:          r2rSavings,
 	}
+	ext.Ranges = componentRanges(breakdowns, weights, totalPRs, &ext)
+	ext.TotalCostStdErr = ext.Ranges.Total.StdErr
+	ext.TotalCostCI95Low = ext.Ranges.Total.Low
+	ext.TotalCostCI95High = ext.Ranges.Total.High
+
+	// Waste per week metrics: preventable waste spread over the period, and over its authors
+	if daysInPeriod > 0 {
+		weeksInPeriod := float64(daysInPeriod) / 7.0
+		ext.WasteHoursPerWeek = ext.PreventableHours() / weeksInPeriod
+		ext.WasteCostPerWeek = ext.PreventableCost() / weeksInPeriod
+		if totalAuthors > 0 {
+			ext.WasteHoursPerAuthorPerWeek = ext.WasteHoursPerWeek / float64(totalAuthors)
+			ext.WasteCostPerAuthorPerWeek = ext.WasteCostPerWeek / float64(totalAuthors)
+		}
+
+		slog.Info("Waste per week calculation",
+			"total_preventable_hours", ext.PreventableHours(),
+			"total_preventable_cost", ext.PreventableCost(),
+			"days_in_period", daysInPeriod,
+			"weeks_in_period", weeksInPeriod,
+			"waste_hours_per_week", ext.WasteHoursPerWeek,
+			"waste_cost_per_week", ext.WasteCostPerWeek,
+			"total_authors", totalAuthors,
+			"waste_hours_per_author_per_week", ext.WasteHoursPerAuthorPerWeek,
+			"waste_cost_per_author_per_week", ext.WasteCostPerAuthorPerWeek)
+	}
+	return ext
+}
+
+// authorRollups groups human-authored sample breakdowns by author. Each PR's cost is
+// multiplied by its sample weight and by scale (total PRs / sampled PRs) to extrapolate
+// to the population.
+func authorRollups(breakdowns []Breakdown, weights []float64, scale float64) []AuthorRollup {
+	byAuthor := make(map[string]*AuthorRollup)
+	for i := range breakdowns {
+		b := &breakdowns[i]
+		if b.AuthorBot {
+			continue
+		}
+		r, ok := byAuthor[b.PRAuthor]
+		if !ok {
+			r = &AuthorRollup{Author: b.PRAuthor}
+			byAuthor[b.PRAuthor] = r
+		}
+		r.SampledPRs++
+		r.TotalCost += b.TotalCost * weights[i] * scale
+		r.AvgEfficiency += BreakdownEfficiency(b)
+	}
+
+	rollups := make([]AuthorRollup, 0, len(byAuthor))
+	for _, r := range byAuthor {
+		r.AvgEfficiency /= float64(r.SampledPRs)
+		rollups = append(rollups, *r)
+	}
+	// Highest cost first; ties broken by login for deterministic output
+	slices.SortFunc(rollups, func(a, b AuthorRollup) int {
+		if c := cmp.Compare(b.TotalCost, a.TotalCost); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Author, b.Author)
+	})
+	return rollups
+}
+
+// DelayCapacityCost returns the most delivery delay the org can plausibly absorb over a period:
+// DeliveryDelayCapacityFraction of authors × HoursPerYear × (days / 365) × hourly rate.
+// Returns 0 (no cap) when the fraction is disabled or inputs are missing.
+func DelayCapacityCost(authors, days int, hourlyRate float64, cfg Config) float64 {
+	if cfg.DeliveryDelayCapacityFraction <= 0 || authors <= 0 || days <= 0 {
+		return 0
+	}
+	hoursPerYear := cfg.HoursPerYear
+	if hoursPerYear <= 0 {
+		hoursPerYear = 2080
+	}
+	capacityHours := float64(authors) * hoursPerYear * float64(days) / 365.0
+	return cfg.DeliveryDelayCapacityFraction * capacityHours * hourlyRate
 }
@@ -0,0 +1,88 @@
+package cost
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// File categories, for Config.ReviewInspectionRates and PRData.LinesByCategory.
+const (
+	FileCategoryCode      = "code"
+	FileCategoryConfig    = "config"
+	FileCategoryDocs      = "docs"
+	FileCategoryGenerated = "generated"
+)
+
+// FileCategories lists the file categories FileCategory returns.
+var FileCategories = []string{FileCategoryCode, FileCategoryConfig, FileCategoryDocs, FileCategoryGenerated}
+
+// configExtensions are extensions of configuration and data files, which review faster than code.
+var configExtensions = []string{
+	".yaml", ".yml", ".json", ".toml", ".ini", ".cfg", ".conf", ".xml", ".properties", ".env", ".mod",
+}
+
+// docsExtensions are extensions of documentation files.
+var docsExtensions = []string{".md", ".markdown", ".rst", ".txt", ".adoc"}
+
+// FileCategory classifies a changed file for review cost: generated (see IsGeneratedPath),
+// docs, config or, for everything else, code.
+func FileCategory(file string) string {
+	if IsGeneratedPath(file) {
+		return FileCategoryGenerated
+	}
+	ext := strings.ToLower(path.Ext(file))
+	if slices.Contains(docsExtensions, ext) || strings.HasPrefix(file, "docs/") {
+		return FileCategoryDocs
+	}
+	if slices.Contains(configExtensions, ext) {
+		return FileCategoryConfig
+	}
+	return FileCategoryCode
+}
+
+// ParseInspectionRate parses a per-category inspection rate given as category=LOC/hour,
+// e.g. "config=1000", for Config.ReviewInspectionRates.
+func ParseInspectionRate(spec string) (category string, rate float64, err error) {
+	name, value, ok := strings.Cut(spec, "=")
+	if !ok {
+		return "", 0, fmt.Errorf("invalid inspection rate %q: expected category=LOC/hour", spec)
+	}
+	category = strings.ToLower(strings.TrimSpace(name))
+	if !slices.Contains(FileCategories, category) {
+		return "", 0, fmt.Errorf("invalid inspection rate %q: category must be one of %s", spec, strings.Join(FileCategories, ", "))
+	}
+	rate, err = strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || rate <= 0 {
+		return "", 0, fmt.Errorf("invalid inspection rate %q: rate must be a positive number", spec)
+	}
+	return category, rate, nil
+}
+
+// inspectionHours returns the LOC-based time for one full review of the PR. Lines in a category
+// with a rate in cfg.ReviewInspectionRates are reviewed at that rate; the rest, including
+// every line of a PR whose files are unknown, at defaultRate.
+func inspectionHours(data PRData, cfg Config, defaultRate float64) float64 {
+	remaining := costedLinesAdded(data, cfg)
+	if len(data.LinesByCategory) == 0 || len(cfg.ReviewInspectionRates) == 0 {
+		return float64(remaining) / defaultRate
+	}
+
+	var hours float64
+	for _, category := range slices.Sorted(maps.Keys(data.LinesByCategory)) {
+		if category == FileCategoryGenerated && cfg.ExcludeGeneratedFromCost {
+			continue // Already left out of the costed lines
+		}
+		rate := cfg.ReviewInspectionRates[category]
+		if rate <= 0 {
+			continue
+		}
+		lines := min(data.LinesByCategory[category], remaining)
+		hours += float64(lines) / rate
+		remaining -= lines
+	}
+	return hours + float64(remaining)/defaultRate
+}
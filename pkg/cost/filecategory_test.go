@@ -0,0 +1,80 @@
+package cost
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFileCategory(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"pkg/cost/cost.go", FileCategoryCode},
+		{"web/src/App.tsx", FileCategoryCode},
+		{"deploy/values.yaml", FileCategoryConfig},
+		{"go.mod", FileCategoryConfig},
+		{"package.json", FileCategoryConfig},
+		{"package-lock.json", FileCategoryGenerated},
+		{"go.sum", FileCategoryGenerated},
+		{"web/yarn.lock", FileCategoryGenerated},
+		{"README.md", FileCategoryDocs},
+		{"docs/architecture.svg", FileCategoryDocs},
+		{"api/v1/service.pb.go", FileCategoryGenerated},
+		{"vendor/github.com/foo/bar/config.yaml", FileCategoryGenerated},
+	}
+
+	for _, tt := range tests {
+		if got := FileCategory(tt.file); got != tt.want {
+			t.Errorf("FileCategory(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestParseInspectionRate(t *testing.T) {
+	category, rate, err := ParseInspectionRate(" Config = 1000 ")
+	if err != nil || category != FileCategoryConfig || rate != 1000 {
+		t.Errorf("ParseInspectionRate() = %q, %v, %v; want config, 1000, nil", category, rate, err)
+	}
+
+	for _, spec := range []string{"config", "tests=500", "docs=fast", "code=0", "code=-10"} {
+		if _, _, err := ParseInspectionRate(spec); err == nil {
+			t.Errorf("ParseInspectionRate(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestInspectionHours(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ReviewInspectionRates = map[string]float64{FileCategoryConfig: 1000}
+	data := PRData{
+		LinesAdded:      600,
+		LinesByCategory: map[string]int{FileCategoryCode: 100, FileCategoryConfig: 500},
+	}
+
+	// 500 config lines at 1000 LOC/hour, and 100 code lines at the default rate.
+	want := 0.5 + 100/cfg.ReviewInspectionRate
+	if got := inspectionHours(data, cfg, cfg.ReviewInspectionRate); math.Abs(got-want) > 1e-9 {
+		t.Errorf("inspectionHours() = %v, want %v", got, want)
+	}
+
+	// Without a file breakdown, every line is reviewed at the default rate.
+	unknown := PRData{LinesAdded: 600}
+	want = 600 / cfg.ReviewInspectionRate
+	if got := inspectionHours(unknown, cfg, cfg.ReviewInspectionRate); math.Abs(got-want) > 1e-9 {
+		t.Errorf("inspectionHours() without LinesByCategory = %v, want %v", got, want)
+	}
+
+	// Excluded generated lines are not reviewed at any rate.
+	cfg.ExcludeGeneratedFromCost = true
+	cfg.ReviewInspectionRates[FileCategoryGenerated] = 5000
+	generated := PRData{
+		LinesAdded:      1100,
+		GeneratedLines:  1000,
+		LinesByCategory: map[string]int{FileCategoryCode: 100, FileCategoryGenerated: 1000},
+	}
+	want = 100 / cfg.ReviewInspectionRate
+	if got := inspectionHours(generated, cfg, cfg.ReviewInspectionRate); math.Abs(got-want) > 1e-9 {
+		t.Errorf("inspectionHours() with excluded generated lines = %v, want %v", got, want)
+	}
+}
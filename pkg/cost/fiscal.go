@@ -0,0 +1,74 @@
+package cost
+
+import (
+	"fmt"
+	"time"
+)
+
+// FiscalPeriod is a fiscal quarter or fiscal year.
+// Fiscal years are named for the calendar year they end in, so with an October start,
+// October 2024 through September 2025 is FY25.
+type FiscalPeriod struct {
+	Start   time.Time // Inclusive
+	End     time.Time // Exclusive
+	Year    int       // Fiscal year, e.g. 2025
+	Quarter int       // 1-4, or 0 for a whole fiscal year
+}
+
+// Label returns a short label such as "FY25" or "FY25 Q2".
+func (p FiscalPeriod) Label() string {
+	if p.Quarter == 0 {
+		return fmt.Sprintf("FY%02d", p.Year%100)
+	}
+	return fmt.Sprintf("FY%02d Q%d", p.Year%100, p.Quarter)
+}
+
+// Days returns the length of the period in days.
+func (p FiscalPeriod) Days() float64 {
+	return p.End.Sub(p.Start).Hours() / 24.0
+}
+
+// FiscalYearOf returns the fiscal year containing t for a fiscal year starting in startMonth.
+// A startMonth outside 1-12 is treated as January (the calendar year).
+func FiscalYearOf(t time.Time, startMonth int) FiscalPeriod {
+	if startMonth < 1 || startMonth > 12 {
+		startMonth = 1
+	}
+	startYear := t.Year()
+	if int(t.Month()) < startMonth {
+		startYear--
+	}
+	start := time.Date(startYear, time.Month(startMonth), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+	return FiscalPeriod{Start: start, End: end, Year: end.Add(-time.Nanosecond).Year()}
+}
+
+// FiscalQuarterOf returns the fiscal quarter containing t for a fiscal year starting in startMonth.
+func FiscalQuarterOf(t time.Time, startMonth int) FiscalPeriod {
+	fy := FiscalYearOf(t, startMonth)
+	monthsIn := (t.Year()-fy.Start.Year())*12 + int(t.Month()) - int(fy.Start.Month())
+	quarter := monthsIn/3 + 1
+	start := fy.Start.AddDate(0, (quarter-1)*3, 0)
+	return FiscalPeriod{Start: start, End: start.AddDate(0, 3, 0), Year: fy.Year, Quarter: quarter}
+}
+
+// FiscalProjection is a cost projected over a fiscal period.
+type FiscalProjection struct {
+	Label string  `json:"label"`
+	Days  float64 `json:"days"`
+	Cost  float64 `json:"cost"`
+}
+
+// ProjectFiscalPeriods scales a cost observed over the last days to the fiscal quarter and
+// fiscal year containing now, for a fiscal year starting in startMonth.
+func ProjectFiscalPeriods(periodCost float64, days int, now time.Time, startMonth int) (quarter, year FiscalProjection) {
+	if days <= 0 {
+		return quarter, year
+	}
+	perDay := periodCost / float64(days)
+	q := FiscalQuarterOf(now, startMonth)
+	y := FiscalYearOf(now, startMonth)
+	quarter = FiscalProjection{Label: q.Label(), Days: q.Days(), Cost: perDay * q.Days()}
+	year = FiscalProjection{Label: y.Label(), Days: y.Days(), Cost: perDay * y.Days()}
+	return quarter, year
+}
@@ -0,0 +1,67 @@
+package cost
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFiscalPeriods(t *testing.T) {
+	tests := []struct {
+		name        string
+		date        time.Time
+		startMonth  int
+		wantYear    string
+		wantQuarter string
+		wantQStart  time.Month
+	}{
+		{"calendar year", time.Date(2025, time.May, 10, 0, 0, 0, 0, time.UTC), 1, "FY25", "FY25 Q2", time.April},
+		{"october start, before start", time.Date(2025, time.February, 3, 0, 0, 0, 0, time.UTC), 10, "FY25", "FY25 Q2", time.January},
+		{"october start, first month", time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC), 10, "FY25", "FY25 Q1", time.October},
+		{"october start, last month", time.Date(2025, time.September, 30, 0, 0, 0, 0, time.UTC), 10, "FY25", "FY25 Q4", time.July},
+		{"july start", time.Date(2025, time.August, 15, 0, 0, 0, 0, time.UTC), 7, "FY26", "FY26 Q1", time.July},
+		{"february start, january", time.Date(2025, time.January, 20, 0, 0, 0, 0, time.UTC), 2, "FY25", "FY25 Q4", time.November},
+		{"invalid start month", time.Date(2025, time.May, 10, 0, 0, 0, 0, time.UTC), 13, "FY25", "FY25 Q2", time.April},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FiscalYearOf(tt.date, tt.startMonth).Label(); got != tt.wantYear {
+				t.Errorf("FiscalYearOf() = %q, want %q", got, tt.wantYear)
+			}
+			q := FiscalQuarterOf(tt.date, tt.startMonth)
+			if q.Label() != tt.wantQuarter {
+				t.Errorf("FiscalQuarterOf() = %q, want %q", q.Label(), tt.wantQuarter)
+			}
+			if q.Start.Month() != tt.wantQStart {
+				t.Errorf("quarter starts in %v, want %v", q.Start.Month(), tt.wantQStart)
+			}
+			if tt.date.Before(q.Start) || !tt.date.Before(q.End) {
+				t.Errorf("quarter %v-%v does not contain %v", q.Start, q.End, tt.date)
+			}
+		})
+	}
+}
+
+func TestProjectFiscalPeriods(t *testing.T) {
+	// $6,000 over 60 days = $100/day; FY25 Q2 with an October start is Jan-Mar 2025 (90 days)
+	now := time.Date(2025, time.February, 3, 0, 0, 0, 0, time.UTC)
+	quarter, year := ProjectFiscalPeriods(6000, 60, now, 10)
+
+	if quarter.Label != "FY25 Q2" || quarter.Days != 90 {
+		t.Errorf("quarter = %+v, want FY25 Q2 over 90 days", quarter)
+	}
+	if math.Abs(quarter.Cost-9000) > 0.01 {
+		t.Errorf("quarter cost = %.2f, want 9000", quarter.Cost)
+	}
+	if year.Label != "FY25" || year.Days != 365 {
+		t.Errorf("year = %+v, want FY25 over 365 days", year)
+	}
+	if math.Abs(year.Cost-36500) > 0.01 {
+		t.Errorf("year cost = %.2f, want 36500", year.Cost)
+	}
+
+	if q, y := ProjectFiscalPeriods(6000, 0, now, 10); q.Cost != 0 || y.Cost != 0 {
+		t.Errorf("zero days: got %+v, %+v; want zero projections", q, y)
+	}
+}
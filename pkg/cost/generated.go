@@ -0,0 +1,46 @@
+package cost
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// generatedSuffixes are file name suffixes of machine-generated code.
+var generatedSuffixes = []string{".pb.go", ".pb.gw.go", "_generated.go", ".gen.go"}
+
+// lockFiles are dependency lock files, which package managers write and nobody reviews line by
+// line. Any other file ending in ".lock", like Cargo.lock or flake.lock, is one too.
+var lockFiles = []string{"go.sum", "go.work.sum", "package-lock.json", "npm-shrinkwrap.json", "pnpm-lock.yaml"}
+
+// vendoredDirs are directory names whose contents are third-party code checked into the repo.
+var vendoredDirs = []string{"vendor", "node_modules", "third_party"}
+
+// IsGeneratedPath reports whether file is generated or vendored code that nobody hand-writes
+// or reviews line by line, such as protobuf output, a lock file or anything under vendor/.
+func IsGeneratedPath(file string) bool {
+	base := path.Base(file)
+	if strings.HasPrefix(base, "zz_generated.") || slices.Contains(lockFiles, base) || strings.HasSuffix(base, ".lock") {
+		return true
+	}
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return true
+		}
+	}
+	for dir := range strings.SplitSeq(path.Dir(file), "/") {
+		if slices.Contains(vendoredDirs, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// costedLinesAdded returns the added lines that carry development and review cost:
+// LinesAdded minus GeneratedLines when cfg.ExcludeGeneratedFromCost is set.
+func costedLinesAdded(data PRData, cfg Config) int {
+	if !cfg.ExcludeGeneratedFromCost {
+		return data.LinesAdded
+	}
+	return max(data.LinesAdded-data.GeneratedLines, 0)
+}
@@ -0,0 +1,74 @@
+package cost
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsGeneratedPath(t *testing.T) {
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{"api/v1/service.pb.go", true},
+		{"api/v1/service.pb.gw.go", true},
+		{"pkg/client/client_generated.go", true},
+		{"pkg/apis/v1/zz_generated.deepcopy.go", true},
+		{"vendor/github.com/foo/bar/bar.go", true},
+		{"tools/vendor/lib.go", true},
+		{"web/node_modules/react/index.js", true},
+		{"go.sum", true},
+		{"web/package-lock.json", true},
+		{"Cargo.lock", true},
+		{"pkg/cost/cost.go", false},
+		{"pkg/vendors/list.go", false},
+		{"docs/generated.md", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsGeneratedPath(tt.file); got != tt.want {
+			t.Errorf("IsGeneratedPath(%q) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateExcludesGeneratedLines(t *testing.T) {
+	created := time.Now().Add(-48 * time.Hour)
+	pr := PRData{
+		LinesAdded:     5000,
+		GeneratedLines: 4800,
+		Author:         "alice",
+		Events: []ParticipantEvent{
+			{Timestamp: created, Actor: "alice", Kind: "commit"},
+			{Timestamp: created.Add(time.Hour), Actor: "bob", Kind: "review"},
+		},
+		CreatedAt: created,
+	}
+	handWritten := pr
+	handWritten.LinesAdded, handWritten.GeneratedLines = 200, 0
+
+	cfg := DefaultConfig()
+	excluded := Calculate(pr, cfg)
+	want := Calculate(handWritten, cfg)
+	if excluded.Author.NewLines != 200 || excluded.Author.GeneratedLines != 4800 {
+		t.Errorf("NewLines/GeneratedLines = %d/%d, want 200/4800", excluded.Author.NewLines, excluded.Author.GeneratedLines)
+	}
+	if excluded.Author.NewCodeCost != want.Author.NewCodeCost {
+		t.Errorf("NewCodeCost = $%.2f, want $%.2f (same as 200 hand-written lines)", excluded.Author.NewCodeCost, want.Author.NewCodeCost)
+	}
+	if excluded.DelayCostDetail.FutureReviewCost != want.DelayCostDetail.FutureReviewCost {
+		t.Errorf("FutureReviewCost = $%.2f, want $%.2f", excluded.DelayCostDetail.FutureReviewCost, want.DelayCostDetail.FutureReviewCost)
+	}
+	if excluded.Participants[0].ReviewCost != want.Participants[0].ReviewCost {
+		t.Errorf("ReviewCost = $%.2f, want $%.2f", excluded.Participants[0].ReviewCost, want.Participants[0].ReviewCost)
+	}
+
+	cfg.ExcludeGeneratedFromCost = false
+	raw := Calculate(pr, cfg)
+	if raw.Author.NewLines != 5000 || raw.Author.GeneratedLines != 0 {
+		t.Errorf("raw NewLines/GeneratedLines = %d/%d, want 5000/0", raw.Author.NewLines, raw.Author.GeneratedLines)
+	}
+	if raw.Author.NewCodeCost <= excluded.Author.NewCodeCost {
+		t.Errorf("raw NewCodeCost $%.2f should exceed excluded $%.2f", raw.Author.NewCodeCost, excluded.Author.NewCodeCost)
+	}
+}
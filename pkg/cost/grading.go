@@ -1,5 +1,54 @@
 package cost
 
+// PreventableCost returns the PR's preventable waste: code churn, delivery delay, automated
+// updates, PR tracking, revert work and participants' review waits. Abandoned code is reported
+// on its own (AbandonedCost), though efficiency counts it as waste too.
+func (b *Breakdown) PreventableCost() float64 {
+	d := b.DelayCostDetail
+	total := d.CodeChurnCost + d.DeliveryDelayCost + d.AutomatedUpdatesCost + d.PRTrackingCost + b.RevertCost
+	for _, p := range b.Participants {
+		total += p.ReviewWaitCost
+	}
+	return total
+}
+
+// PreventableHours returns the hours behind PreventableCost.
+func (b *Breakdown) PreventableHours() float64 {
+	d := b.DelayCostDetail
+	total := d.CodeChurnHours + d.DeliveryDelayHours + d.AutomatedUpdatesHours + d.PRTrackingHours + b.RevertHours
+	for _, p := range b.Participants {
+		total += p.ReviewWaitHours
+	}
+	return total
+}
+
+// PreventableCost returns the extrapolated preventable waste, defined as for Breakdown.PreventableCost.
+func (e *ExtrapolatedBreakdown) PreventableCost() float64 {
+	return e.CodeChurnCost + e.DeliveryDelayCost + e.AutomatedUpdatesCost + e.PRTrackingCost + e.RevertCost +
+		e.ParticipantReviewWaitCost
+}
+
+// PreventableHours returns the hours behind PreventableCost.
+func (e *ExtrapolatedBreakdown) PreventableHours() float64 {
+	return e.CodeChurnHours + e.DeliveryDelayHours + e.AutomatedUpdatesHours + e.PRTrackingHours + e.RevertHours +
+		e.ParticipantReviewWaitHours
+}
+
+// BreakdownEfficiency returns the percentage of a PR's hours that were neither preventable waste
+// (see Breakdown.PreventableHours) nor abandoned code.
+// Returns 100 when there are no hours.
+func BreakdownEfficiency(b *Breakdown) float64 {
+	totalHours := b.Author.TotalHours + b.DelayCostDetail.TotalDelayHours
+	for _, p := range b.Participants {
+		totalHours += p.TotalHours
+	}
+
+	if totalHours > 0 {
+		return 100.0 * (totalHours - b.PreventableHours() - b.AbandonedHours) / totalHours
+	}
+	return 100.0
+}
+
 // EfficiencyGrade returns a letter grade and message based on efficiency percentage (MIT scale).
 // Efficiency is the percentage of total cost that goes to productive work (author + participant)
 // vs overhead/delays.
@@ -0,0 +1,78 @@
+package cost
+
+import (
+	"cmp"
+	"slices"
+)
+
+// DurationBucket counts sampled PRs whose open time falls in one range of a duration histogram.
+type DurationBucket struct {
+	Label    string  `json:"label"`     // e.g. "1-4h"
+	MaxHours float64 `json:"max_hours"` // Exclusive upper bound in hours; 0 for the open-ended last bucket
+	Count    int     `json:"count"`     // Sampled PRs in this range
+	// Weighted is Count with each sample weighted by its SampleWeight, scaled so the buckets sum
+	// to the sample size: the share of the population in this range. It equals Count unless
+	// the PRs were picked by weighted sampling.
+	Weighted float64 `json:"weighted"`
+}
+
+// durationBuckets are the histogram ranges, from under an hour to over a week.
+var durationBuckets = []DurationBucket{
+	{Label: "<1h", MaxHours: 1},
+	{Label: "1-4h", MaxHours: 4},
+	{Label: "4-24h", MaxHours: 24},
+	{Label: "1-3d", MaxHours: 72},
+	{Label: "3-7d", MaxHours: 168},
+	{Label: ">7d"},
+}
+
+// durationHistogram buckets the open time (PRDuration) of each sampled PR.
+// An average hides a long tail; the histogram shows whether most PRs are slow or a few are stuck.
+func durationHistogram(breakdowns []Breakdown) []DurationBucket {
+	buckets := make([]DurationBucket, len(durationBuckets))
+	copy(buckets, durationBuckets)
+	weights := sampleWeights(breakdowns)
+	for i := range breakdowns {
+		last := len(buckets) - 1
+		j := 0
+		for j < last && breakdowns[i].PRDuration >= buckets[j].MaxHours {
+			j++
+		}
+		buckets[j].Count++
+		buckets[j].Weighted += weights[i]
+	}
+	return buckets
+}
+
+// durationPercentile returns the p-th percentile (0-100) of the sampled PRs' open time in hours,
+// or 0 without samples. Samples count by their SampleWeight: each sits at the middle of its
+// share of the cumulative weight, and the percentile interpolates linearly between neighbors,
+// which for equal weights is the usual interpolation between the nearest ranks.
+func durationPercentile(breakdowns []Breakdown, p float64) float64 {
+	if len(breakdowns) == 0 {
+		return 0
+	}
+	weights := sampleWeights(breakdowns)
+	order := make([]int, len(breakdowns))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(breakdowns[a].PRDuration, breakdowns[b].PRDuration)
+	})
+
+	positions := make([]float64, len(order))
+	var cumulative float64
+	for k, i := range order {
+		positions[k] = cumulative + weights[i]/2
+		cumulative += weights[i]
+	}
+	target := positions[0] + p/100*(positions[len(positions)-1]-positions[0])
+	for k := 1; k < len(order); k++ {
+		if target <= positions[k] {
+			lower, upper := breakdowns[order[k-1]].PRDuration, breakdowns[order[k]].PRDuration
+			return lower + (target-positions[k-1])/(positions[k]-positions[k-1])*(upper-lower)
+		}
+	}
+	return breakdowns[order[len(order)-1]].PRDuration
+}
@@ -0,0 +1,92 @@
+package cost
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDurationHistogram(t *testing.T) {
+	hours := []float64{0.5, 0.9, 1, 3, 12, 30, 100, 168, 2000}
+	breakdowns := make([]Breakdown, len(hours))
+	for i, h := range hours {
+		breakdowns[i].PRDuration = h
+	}
+
+	got := durationHistogram(breakdowns)
+	want := map[string]int{"<1h": 2, "1-4h": 2, "4-24h": 1, "1-3d": 1, "3-7d": 1, ">7d": 2}
+	if len(got) != len(want) {
+		t.Fatalf("durationHistogram() has %d buckets, want %d", len(got), len(want))
+	}
+	for _, b := range got {
+		if b.Count != want[b.Label] || b.Weighted != float64(want[b.Label]) {
+			t.Errorf("bucket %s = %d PRs, want %d", b.Label, b.Count, want[b.Label])
+		}
+	}
+
+	// The bucket template is not shared between calls
+	if again := durationHistogram(breakdowns[:1]); again[0].Count != 1 || again[5].Count != 0 {
+		t.Errorf("second durationHistogram() = %+v, want a fresh count", again)
+	}
+
+	ext := ExtrapolateFromSamples(breakdowns, 90, 3, 0, 30, DefaultConfig(), nil, nil)
+	if len(ext.DurationHistogram) != len(want) || ext.DurationHistogram[0].Count != 2 {
+		t.Errorf("ExtrapolateFromSamples() DurationHistogram = %+v", ext.DurationHistogram)
+	}
+}
+
+func TestDurationPercentile(t *testing.T) {
+	hours := []float64{200, 1, 3, 2, 4, 5, 6, 7, 8, 9}
+	breakdowns := make([]Breakdown, len(hours))
+	for i, h := range hours {
+		breakdowns[i].PRDuration = h
+	}
+
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 1},
+		{50, 5.5},
+		{90, 28.1}, // Between 9 and the stuck 200h PR
+		{100, 200},
+	}
+	for _, tt := range tests {
+		if got := durationPercentile(breakdowns, tt.p); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("durationPercentile(p%.0f) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := durationPercentile(nil, 50); got != 0 {
+		t.Errorf("durationPercentile() without samples = %v, want 0", got)
+	}
+
+	// Weighted samples: the two short PRs each stand for four times as many PRs as the long one
+	weighted := []Breakdown{{PRDuration: 1, SampleWeight: 4}, {PRDuration: 2, SampleWeight: 4}, {PRDuration: 100, SampleWeight: 1}}
+	if got := durationPercentile(weighted, 50); math.Abs(got-1.8125) > 1e-9 {
+		t.Errorf("weighted durationPercentile(p50) = %v, want 1.8125", got)
+	}
+	if got := durationHistogram(weighted); got[0].Count != 0 || got[1].Count != 2 || math.Abs(got[1].Weighted-8.0/3) > 1e-9 {
+		t.Errorf("weighted durationHistogram() = %+v, want 2 PRs weighing 8/3 in 1-4h", got)
+	}
+
+	// The stuck PR drags the mean past a day, but most PRs merge within hours
+	cfg := DefaultConfig()
+	prs := make([]PRSummaryInfo, len(hours))
+	now := time.Now()
+	for i, h := range hours {
+		closed := now
+		prs[i] = PRSummaryInfo{CreatedAt: now.Add(-time.Duration(h * float64(time.Hour))), ClosedAt: &closed, Merged: true}
+	}
+	ext := ExtrapolateFromSamples(breakdowns, len(hours), 3, 0, 30, cfg, prs, nil)
+	if ext.P50PRDurationHours != 5.5 || math.Abs(ext.P90PRDurationHours-28.1) > 1e-9 {
+		t.Errorf("ExtrapolateFromSamples() p50/p90 = %v/%v, want 5.5/28.1", ext.P50PRDurationHours, ext.P90PRDurationHours)
+	}
+	if ext.MergeVelocityBasis != "mean" || ext.MergeVelocityGrade != "B" {
+		t.Errorf("mean velocity grade = %s (%s), want B on the mean", ext.MergeVelocityGrade, ext.MergeVelocityBasis)
+	}
+	cfg.GradeVelocityByMedian = true
+	ext = ExtrapolateFromSamples(breakdowns, len(hours), 3, 0, 30, cfg, prs, nil)
+	if ext.MergeVelocityBasis != "p50" || ext.MergeVelocityGrade != "A" {
+		t.Errorf("median velocity grade = %s (%s), want A on the p50", ext.MergeVelocityGrade, ext.MergeVelocityBasis)
+	}
+}
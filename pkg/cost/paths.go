@@ -0,0 +1,48 @@
+package cost
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ValidatePathPatterns checks that each pattern is a valid path.Match glob.
+func ValidatePathPatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(strings.TrimSuffix(p, "/"), ""); err != nil {
+			return fmt.Errorf("invalid path pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// MatchPath reports whether file matches pattern.
+// The pattern is a path.Match glob matched against the full file path and each of its
+// parent directories, so "auth", "auth/" and "services/*/auth" all match every file below
+// those directories, while "*.tf" only matches top-level Terraform files.
+func MatchPath(pattern, file string) bool {
+	pattern = strings.TrimSuffix(pattern, "/")
+	file = strings.TrimPrefix(file, "/")
+	for p := file; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+		if ok, err := path.Match(pattern, p); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// TouchesPaths reports whether any of files matches any of patterns (see MatchPath).
+// An empty pattern list matches every PR, including PRs with no known files.
+func TouchesPaths(files, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, f := range files {
+		for _, p := range patterns {
+			if MatchPath(p, f) {
+				return true
+			}
+		}
+	}
+	return false
+}
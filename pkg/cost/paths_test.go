@@ -0,0 +1,58 @@
+package cost
+
+import "testing"
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"auth", "auth/login.go", true},
+		{"auth/", "auth/oauth/token.go", true},
+		{"auth", "pkg/auth/login.go", false},
+		{"*/auth", "pkg/auth/login.go", true},
+		{"services/*/payments", "services/billing/payments/charge.go", true},
+		{"infra/*.tf", "infra/main.tf", true},
+		{"infra/*.tf", "infra/modules/vpc.tf", false},
+		{"*.tf", "main.tf", true},
+		{"README.md", "README.md", true},
+		{"auth", "authz/policy.go", false},
+		{"[", "auth/login.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchPath(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestTouchesPaths(t *testing.T) {
+	files := []string{"README.md", "payments/charge.go"}
+
+	if !TouchesPaths(files, nil) {
+		t.Error("Expected no patterns to match everything")
+	}
+	if !TouchesPaths(nil, nil) {
+		t.Error("Expected no patterns to match PRs with no known files")
+	}
+	if !TouchesPaths(files, []string{"auth", "payments"}) {
+		t.Error("Expected a PR touching payments/ to match")
+	}
+	if TouchesPaths(files, []string{"auth", "infra"}) {
+		t.Error("Expected a PR not touching auth/ or infra/ not to match")
+	}
+	if TouchesPaths(nil, []string{"payments"}) {
+		t.Error("Expected a PR with no known files not to match a pattern")
+	}
+}
+
+func TestValidatePathPatterns(t *testing.T) {
+	if err := ValidatePathPatterns([]string{"auth/", "infra/*.tf", "services/*/payments"}); err != nil {
+		t.Errorf("ValidatePathPatterns() unexpected error: %v", err)
+	}
+	if err := ValidatePathPatterns([]string{"auth", "[bad"}); err == nil {
+		t.Error("Expected error for malformed pattern")
+	}
+}
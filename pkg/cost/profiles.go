@@ -0,0 +1,78 @@
+package cost
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadConfig reads named configuration profiles from a YAML file such as:
+//
+//	payments:
+//	  annual_salary: 310000
+//	  event_duration: 15m
+//	  weekly_churn_rate: 0.03
+//	  working_calendar: mon-fri 9-17 America/New_York
+//	  ignored_event_kinds: [labeled, subscribed]
+//	  salary_overrides:
+//	    alice: 280000
+//	  cocomo:
+//	    multiplier: 3.2
+//
+// Each top-level key names a profile. Profile keys are the yaml names of Config fields
+// (snake_case); any field may be set, and fields a profile leaves out keep their
+// DefaultConfig value. Durations use Go syntax ("90m", "720h") and WorkingCalendar uses
+// ParseWorkingCalendar's syntax.
+func LoadConfig(path string) (map[string]Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	// Check the whole file strictly first, so typos and bad values are reported with their line
+	var strict map[string]Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&strict); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: no profiles defined", path)
+		}
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(strict) == 0 {
+		return nil, fmt.Errorf("%s: no profiles defined", path)
+	}
+
+	// Then decode each profile over the defaults, so settings it leaves out keep them
+	var nodes map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &nodes); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	profiles := make(map[string]Config, len(nodes))
+	for name, node := range nodes {
+		cfg := DefaultConfig()
+		if err := node.Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("%s: profile %q: %w", path, name, err)
+		}
+		profiles[name] = cfg
+	}
+	return profiles, nil
+}
+
+// UnmarshalYAML decodes a working calendar written in ParseWorkingCalendar's syntax.
+func (c *WorkingCalendar) UnmarshalYAML(node *yaml.Node) error {
+	var spec string
+	if err := node.Decode(&spec); err != nil {
+		return err
+	}
+	cal, err := ParseWorkingCalendar(spec)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	*c = *cal
+	return nil
+}
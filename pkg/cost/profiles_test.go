@@ -0,0 +1,106 @@
+package cost
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeProfiles(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "profiles.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeProfiles(t, `# Team cost assumptions
+payments:
+  annual_salary: 310000   # staff, 2025
+  benefits_multiplier: 1.4
+  event_duration: 15m
+  require_waiting_evidence: true
+  working_calendar: "mon-fri 9-17 America/New_York"
+  ignored_event_kinds: [labeled, 'subscribed']
+  salary_overrides:
+    alice: 280000
+  cocomo:
+    multiplier: 3.2
+
+platform:
+  weekly_churn_rate: 0.03
+  change_types:
+    kind/cleanup: chore
+  ignored_event_kinds:
+  - mentioned
+`)
+	profiles, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	if len(profiles) != 2 {
+		t.Fatalf("LoadConfig() returned %d profiles, want 2", len(profiles))
+	}
+
+	payments := profiles["payments"]
+	if payments.AnnualSalary != 310000 || payments.BenefitsMultiplier != 1.4 || payments.EventDuration != 15*time.Minute {
+		t.Errorf("payments salary/benefits/event = %v/%v/%v, want 310000/1.4/15m",
+			payments.AnnualSalary, payments.BenefitsMultiplier, payments.EventDuration)
+	}
+	if !payments.RequireWaitingEvidence || payments.WorkingCalendar == nil || payments.WorkingCalendar.Timezone != "America/New_York" {
+		t.Errorf("payments waiting evidence/calendar = %v/%v", payments.RequireWaitingEvidence, payments.WorkingCalendar)
+	}
+	if !slices.Equal(payments.IgnoredEventKinds, []string{"labeled", "subscribed"}) || payments.SalaryOverrides["alice"] != 280000 {
+		t.Errorf("payments ignored events/overrides = %v/%v", payments.IgnoredEventKinds, payments.SalaryOverrides)
+	}
+	defaults := DefaultConfig()
+	if payments.COCOMO.Multiplier != 3.2 || payments.COCOMO.Exponent != defaults.COCOMO.Exponent {
+		t.Errorf("payments COCOMO = %+v, want multiplier 3.2 with the default exponent", payments.COCOMO)
+	}
+	if payments.WeeklyChurnRate != defaults.WeeklyChurnRate {
+		t.Errorf("payments WeeklyChurnRate = %v, want the default %v", payments.WeeklyChurnRate, defaults.WeeklyChurnRate)
+	}
+
+	platform := profiles["platform"]
+	if platform.WeeklyChurnRate != 0.03 || platform.ChangeTypes["kind/cleanup"] != "chore" || platform.AnnualSalary != defaults.AnnualSalary {
+		t.Errorf("platform churn/change types/salary = %v/%v/%v", platform.WeeklyChurnRate, platform.ChangeTypes, platform.AnnualSalary)
+	}
+	if !slices.Equal(platform.IgnoredEventKinds, []string{"mentioned"}) {
+		t.Errorf("platform IgnoredEventKinds = %v, want [mentioned]", platform.IgnoredEventKinds)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"empty", "# nothing here\n", "no profiles defined"},
+		{"unknown setting", "payments:\n  anual_salary: 1\n", "line 2: field anual_salary not found"},
+		{"bad number", "payments:\n  annual_salary: lots\n", "line 2: cannot unmarshal !!str `lots` into float64"},
+		{"bad duration", "payments:\n  event_duration: 15\n", "line 2: cannot unmarshal !!int `15` into time.Duration"},
+		{"bad calendar", "payments:\n  working_calendar: weekdays\n", "line 2: invalid working calendar"},
+		{"list for scalar", "payments:\n  annual_salary: [1, 2]\n", "line 2: cannot unmarshal !!seq into float64"},
+		{"bad indentation", "payments:\n  annual_salary: 1\n    benefits_multiplier: 2\n", "line 3"},
+		{"not a mapping", "payments:\n  - annual_salary\n", "line 2: cannot unmarshal !!seq into cost.Config"},
+		{"duplicate profile", "a:\n  annual_salary: 1\na:\n  annual_salary: 2\n", `mapping key "a" already defined`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadConfig(writeProfiles(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadConfig() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("LoadConfig() of a missing file succeeded, want error")
+	}
+}
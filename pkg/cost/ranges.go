@@ -0,0 +1,86 @@
+package cost
+
+import "math"
+
+// confidenceZ is the z-score for a two-sided 95% confidence interval.
+const confidenceZ = 1.96
+
+// CostRange is a 95% confidence interval around an extrapolated cost.
+// A wide range relative to the estimate means that component is noisy in the sample.
+type CostRange struct {
+	StdErr float64 `json:"std_err"` // Standard error of the extrapolated cost
+	Low    float64 `json:"low"`     // Lower bound (never below zero)
+	High   float64 `json:"high"`    // Upper bound
+}
+
+// ComponentRanges holds confidence ranges for the major extrapolated line items.
+type ComponentRanges struct {
+	Author      CostRange `json:"author"`
+	Participant CostRange `json:"participant"`
+	Delay       CostRange `json:"delay"`
+	Total       CostRange `json:"total"`
+}
+
+// componentRanges computes confidence ranges for the extrapolated author, participant,
+// delay, and total costs from the per-PR values in the sample, each scaled by its sample
+// weight. Each range is centered on the reported estimate (which may include org-wide
+// adjustments such as the delay cap).
+func componentRanges(breakdowns []Breakdown, weights []float64, population int, ext *ExtrapolatedBreakdown) ComponentRanges {
+	author := make([]float64, len(breakdowns))
+	participant := make([]float64, len(breakdowns))
+	delay := make([]float64, len(breakdowns))
+	total := make([]float64, len(breakdowns))
+	for i := range breakdowns {
+		b := &breakdowns[i]
+		author[i] = weights[i] * b.Author.TotalCost
+		for _, p := range b.Participants {
+			participant[i] += weights[i] * p.TotalCost
+		}
+		delay[i] = weights[i] * b.DelayCost
+		total[i] = weights[i] * b.TotalCost
+	}
+
+	pop := float64(population)
+	return ComponentRanges{
+		Author:      newCostRange(ext.AuthorTotalCost, extrapolatedStdErr(author, pop)),
+		Participant: newCostRange(ext.ParticipantTotalCost, extrapolatedStdErr(participant, pop)),
+		Delay:       newCostRange(ext.DelayTotalCost, extrapolatedStdErr(delay, pop)),
+		Total:       newCostRange(ext.TotalCost, extrapolatedStdErr(total, pop)),
+	}
+}
+
+// extrapolatedStdErr returns the standard error of mean(values) × population, applying the
+// finite population correction since samples are drawn without replacement.
+// Returns 0 with fewer than two values.
+func extrapolatedStdErr(values []float64, population float64) float64 {
+	n := float64(len(values))
+	if n < 2 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / n
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	stddev := math.Sqrt(sq / (n - 1))
+
+	// No sampling error when the whole population was sampled
+	var fpc float64
+	if population > n {
+		fpc = math.Sqrt((population - n) / (population - 1))
+	}
+	return population * stddev / math.Sqrt(n) * fpc
+}
+
+// newCostRange returns the 95% confidence interval for estimate given its standard error.
+func newCostRange(estimate, stdErr float64) CostRange {
+	return CostRange{
+		StdErr: stdErr,
+		Low:    math.Max(0, estimate-confidenceZ*stdErr),
+		High:   estimate + confidenceZ*stdErr,
+	}
+}
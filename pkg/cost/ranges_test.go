@@ -0,0 +1,96 @@
+package cost
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExtrapolatedStdErr(t *testing.T) {
+	// Sample stddev of {1,2,3,4,5} is sqrt(2.5); SE of the mean is sqrt(2.5/5) = sqrt(0.5)
+	values := []float64{1, 2, 3, 4, 5}
+	population := 1000.0
+	fpc := math.Sqrt((population - 5) / (population - 1))
+	want := population * math.Sqrt(0.5) * fpc
+	if got := extrapolatedStdErr(values, population); math.Abs(got-want) > 1e-9 {
+		t.Errorf("extrapolatedStdErr() = %v, want %v", got, want)
+	}
+
+	if got := extrapolatedStdErr([]float64{7, 7, 7}, population); got != 0 {
+		t.Errorf("constant sample: extrapolatedStdErr() = %v, want 0", got)
+	}
+	if got := extrapolatedStdErr([]float64{42}, population); got != 0 {
+		t.Errorf("single sample: extrapolatedStdErr() = %v, want 0", got)
+	}
+	if got := extrapolatedStdErr(values, 5); got != 0 {
+		t.Errorf("full population: extrapolatedStdErr() = %v, want 0", got)
+	}
+}
+
+func TestExtrapolateFromSamplesRanges(t *testing.T) {
+	cfg := DefaultConfig()
+	breakdowns := []Breakdown{
+		{Author: AuthorCostDetail{TotalCost: 100}, DelayCost: 10, TotalCost: 110},
+		{Author: AuthorCostDetail{TotalCost: 100}, DelayCost: 500, TotalCost: 600},
+		{Author: AuthorCostDetail{TotalCost: 100}, DelayCost: 50, TotalCost: 150},
+		{Author: AuthorCostDetail{TotalCost: 100}, DelayCost: 2000, TotalCost: 2100},
+	}
+
+	ext := ExtrapolateFromSamples(breakdowns, 100, 5, 0, 30, cfg, nil, nil)
+
+	if ext.Ranges.Author.StdErr != 0 {
+		t.Errorf("Author StdErr = %v, want 0 for identical author costs", ext.Ranges.Author.StdErr)
+	}
+	if ext.Ranges.Author.Low != ext.AuthorTotalCost || ext.Ranges.Author.High != ext.AuthorTotalCost {
+		t.Errorf("Author range = %+v, want collapsed on %v", ext.Ranges.Author, ext.AuthorTotalCost)
+	}
+
+	wantDelaySE := extrapolatedStdErr([]float64{10, 500, 50, 2000}, 100)
+	if math.Abs(ext.Ranges.Delay.StdErr-wantDelaySE) > 1e-6 {
+		t.Errorf("Delay StdErr = %v, want %v", ext.Ranges.Delay.StdErr, wantDelaySE)
+	}
+	if ext.Ranges.Delay.Low != 0 {
+		t.Errorf("Delay Low = %v, want clamped to 0 for a noisy component", ext.Ranges.Delay.Low)
+	}
+	if math.Abs(ext.Ranges.Delay.High-(ext.DelayTotalCost+confidenceZ*wantDelaySE)) > 1e-6 {
+		t.Errorf("Delay High = %v, want %v", ext.Ranges.Delay.High, ext.DelayTotalCost+confidenceZ*wantDelaySE)
+	}
+	if ext.Ranges.Total.Low > ext.TotalCost || ext.Ranges.Total.High < ext.TotalCost {
+		t.Errorf("Total range %+v does not contain estimate %v", ext.Ranges.Total, ext.TotalCost)
+	}
+}
+
+func TestExtrapolateFromSamplesTotalCostCI(t *testing.T) {
+	cfg := DefaultConfig()
+	delays := []float64{500, 700, 300, 900, 100}
+	breakdowns := make([]Breakdown, len(delays))
+	for i, delay := range delays {
+		breakdowns[i] = Breakdown{
+			Author:          AuthorCostDetail{NewCodeCost: 500, TotalCost: 500},
+			DelayCostDetail: DelayCostDetail{DeliveryDelayCost: delay},
+			DelayCost:       delay,
+			TotalCost:       500 + delay,
+		}
+	}
+
+	ext := ExtrapolateFromSamples(breakdowns, 50, 5, 0, 30, cfg, nil, nil)
+	if ext.TotalCost <= 0 {
+		t.Fatalf("TotalCost = %v, want positive", ext.TotalCost)
+	}
+
+	// Per-PR totals {1000, 1200, 800, 1400, 600} have sample stddev sqrt(100000); scaled
+	// to 50 PRs with the finite population correction sqrt(45/49)
+	wantSE := 50 * math.Sqrt(100000) / math.Sqrt(5) * math.Sqrt(45.0/49.0)
+	if math.Abs(ext.TotalCostStdErr-wantSE) > 1e-6 {
+		t.Errorf("TotalCostStdErr = %.4f, want %.4f", ext.TotalCostStdErr, wantSE)
+	}
+	if math.Abs(ext.TotalCostCI95Low-(ext.TotalCost-confidenceZ*wantSE)) > 1e-6 ||
+		math.Abs(ext.TotalCostCI95High-(ext.TotalCost+confidenceZ*wantSE)) > 1e-6 {
+		t.Errorf("CI = [%.2f, %.2f], want %.2f ± %.2f", ext.TotalCostCI95Low, ext.TotalCostCI95High, ext.TotalCost, confidenceZ*wantSE)
+	}
+
+	// A single sample has no measurable spread
+	single := ExtrapolateFromSamples(breakdowns[:1], 50, 1, 0, 30, cfg, nil, nil)
+	if single.TotalCostStdErr != 0 || single.TotalCostCI95Low != single.TotalCost || single.TotalCostCI95High != single.TotalCost {
+		t.Errorf("single sample CI = %v ± %v [%v, %v], want collapsed", single.TotalCost, single.TotalCostStdErr, single.TotalCostCI95Low, single.TotalCostCI95High)
+	}
+}
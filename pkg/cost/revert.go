@@ -0,0 +1,16 @@
+package cost
+
+import "regexp"
+
+// revertTitle matches titles of revert PRs, such as GitHub's `Revert "Add caching"`,
+// "Reverts #123" and the conventional-commit "revert: add caching".
+var revertTitle = regexp.MustCompile(`(?i)^\s*revert(s|ed)?\b`)
+
+// revertTypes classifies revert labels such as "revert" and "kind/revert" (see ClassifyChangeType).
+var revertTypes = map[string]string{"revert": "revert"}
+
+// isRevert reports whether a PR undoes earlier work, judging best-effort by its title or a revert label.
+func isRevert(data PRData) bool {
+	return revertTitle.MatchString(data.Title) ||
+		ClassifyChangeType("", data.Labels, revertTypes) == "revert"
+}
@@ -0,0 +1,90 @@
+package cost
+
+import (
+	"cmp"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// reviewLatencies returns, per requested reviewer, the hours the author waited for them: from each
+// review request until the reviewer's first review or comment after it, the request's removal,
+// or endTime if neither happened. Requests the reviewer hasn't answered yet don't restart the
+// wait; requests to the author are ignored.
+func reviewLatencies(data PRData, cfg Config, endTime time.Time) map[string]float64 {
+	events := slices.Clone(data.Events)
+	slices.SortStableFunc(events, func(a, b ParticipantEvent) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	latencies := make(map[string]float64)
+	pending := make(map[string]time.Time)
+	wait := func(reviewer string, until time.Time) {
+		start := pending[reviewer]
+		delete(pending, reviewer)
+		hours := min(max(until.Sub(start).Hours(), 0), cfg.MaxProjectDelay.Hours())
+		latencies[reviewer] += cfg.chargedHours(start, hours)
+	}
+	for i := range events {
+		event := &events[i]
+		switch event.Kind {
+		case "review_requested":
+			if _, ok := pending[event.Target]; !ok && event.Target != "" && event.Target != data.Author {
+				pending[event.Target] = event.Timestamp
+			}
+		case "review_request_removed":
+			if _, ok := pending[event.Target]; ok {
+				wait(event.Target, event.Timestamp)
+			}
+		case "review", "review_comment", "comment":
+			if _, ok := pending[event.Actor]; ok {
+				wait(event.Actor, event.Timestamp)
+			}
+		default:
+		}
+	}
+	for reviewer := range pending {
+		wait(reviewer, endTime)
+	}
+	return latencies
+}
+
+// chargeReviewWaits charges each requested reviewer ReviewWaitFactor of the author's hourly rate
+// for the hours the author waited on them (see reviewLatencies). Reviewers without a participant
+// entry, who never responded, get one.
+func chargeReviewWaits(data PRData, cfg Config, hourlyRate float64, endTime time.Time, participants []ParticipantCostDetail) []ParticipantCostDetail {
+	if cfg.ReviewWaitFactor <= 0 {
+		return participants
+	}
+	latencies := reviewLatencies(data, cfg, endTime)
+	for reviewer, latency := range latencies {
+		if latency <= 0 {
+			continue
+		}
+		hours := latency * cfg.ReviewWaitFactor
+		cost := hours * hourlyRate
+
+		i := slices.IndexFunc(participants, func(p ParticipantCostDetail) bool { return p.Actor == reviewer })
+		if i < 0 {
+			participants = append(participants, ParticipantCostDetail{Actor: reviewer})
+			i = len(participants) - 1
+		}
+		participants[i].ReviewLatencyHours += latency
+		participants[i].ReviewWaitHours += hours
+		participants[i].ReviewWaitCost += cost
+		participants[i].TotalHours += hours
+		participants[i].TotalCost += cost
+
+		slog.Info("Review wait",
+			"reviewer", reviewer,
+			"latency_hours", latency,
+			"hours", hours,
+			"cost", cost)
+	}
+
+	// Keep participants sorted by total cost descending, as calculateParticipantCosts does
+	slices.SortFunc(participants, func(a, b ParticipantCostDetail) int {
+		return cmp.Compare(b.TotalCost, a.TotalCost)
+	})
+	return participants
+}
@@ -0,0 +1,98 @@
+package cost
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReviewLatencies(t *testing.T) {
+	created := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	at := func(hours float64) time.Time { return created.Add(time.Duration(hours * float64(time.Hour))) }
+	data := NewPRData("alice", created, at(10), true, 100, 0, []ParticipantEvent{
+		{Timestamp: at(0), Actor: "alice", Kind: "commit"},
+		{Timestamp: at(0), Actor: "alice", Kind: "review_requested", Target: "bob"},
+		{Timestamp: at(0), Actor: "alice", Kind: "review_requested", Target: "carol"},
+		{Timestamp: at(0), Actor: "alice", Kind: "review_requested", Target: "dave"},
+		{Timestamp: at(0), Actor: "alice", Kind: "review_requested", Target: "alice"},
+		{Timestamp: at(1), Actor: "alice", Kind: "review_requested", Target: "bob"}, // still waiting from 0h
+		{Timestamp: at(2), Actor: "alice", Kind: "review_request_removed", Target: "dave"},
+		{Timestamp: at(4), Actor: "bob", Kind: "review", State: "changes_requested"},
+		{Timestamp: at(5), Actor: "alice", Kind: "commit"},
+		{Timestamp: at(6), Actor: "alice", Kind: "review_requested", Target: "bob"},
+		{Timestamp: at(7), Actor: "bob", Kind: "comment"},
+	})
+
+	got := reviewLatencies(data, DefaultConfig(), data.ClosedAt)
+	// bob: 0-4h and 6-7h; carol never answered, so until close; dave until removed
+	want := map[string]float64{"bob": 5, "carol": 10, "dave": 2}
+	if len(got) != len(want) {
+		t.Errorf("reviewLatencies() = %v, want %v", got, want)
+	}
+	for reviewer, hours := range want {
+		if math.Abs(got[reviewer]-hours) > 1e-9 {
+			t.Errorf("%s latency = %v hours, want %v", reviewer, got[reviewer], hours)
+		}
+	}
+
+	// A working calendar counts only working hours: a request Friday 16:00 answered Monday 10:00
+	friday := time.Date(2025, 1, 10, 16, 0, 0, 0, time.UTC)
+	weekend := NewPRData("alice", friday, friday.Add(72*time.Hour), true, 100, 0, []ParticipantEvent{
+		{Timestamp: friday, Actor: "alice", Kind: "review_requested", Target: "bob"},
+		{Timestamp: friday.Add(66 * time.Hour), Actor: "bob", Kind: "review"},
+	})
+	cfg := DefaultConfig()
+	cfg.WorkingCalendar = &WorkingCalendar{StartHour: 9, EndHour: 17}
+	if got := reviewLatencies(weekend, cfg, weekend.ClosedAt)["bob"]; math.Abs(got-2) > 1e-9 {
+		t.Errorf("weekend latency = %v working hours, want 2", got)
+	}
+}
+
+func TestCalculateReviewWait(t *testing.T) {
+	created := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	data := NewPRData("alice", created, created.Add(10*time.Hour), true, 100, 0, []ParticipantEvent{
+		{Timestamp: created, Actor: "alice", Kind: "commit"},
+		{Timestamp: created, Actor: "alice", Kind: "review_requested", Target: "bob"},
+		{Timestamp: created, Actor: "alice", Kind: "review_requested", Target: "carol"},
+		{Timestamp: created.Add(4 * time.Hour), Actor: "bob", Kind: "review", State: "approved"},
+	})
+	cfg := DefaultConfig()
+	cfg.ReviewWaitFactor = 0.05
+	breakdown := Calculate(data, cfg)
+
+	participants := make(map[string]ParticipantCostDetail)
+	for _, p := range breakdown.Participants {
+		participants[p.Actor] = p
+	}
+	bob, carol := participants["bob"], participants["carol"]
+	if bob.ReviewLatencyHours != 4 || math.Abs(bob.ReviewWaitHours-4*cfg.ReviewWaitFactor) > 1e-9 {
+		t.Errorf("bob latency %v, wait hours %v; want 4 and %v", bob.ReviewLatencyHours, bob.ReviewWaitHours, 4*cfg.ReviewWaitFactor)
+	}
+	if want := bob.ReviewWaitHours * breakdown.HourlyRate; math.Abs(bob.ReviewWaitCost-want) > 1e-9 {
+		t.Errorf("bob ReviewWaitCost = %v, want %v at the author's rate", bob.ReviewWaitCost, want)
+	}
+	// carol never responded: she is charged for the whole wait, and for nothing else
+	if carol.ReviewLatencyHours != 10 || carol.Events != 0 || carol.TotalCost != carol.ReviewWaitCost {
+		t.Errorf("carol = %+v, want only a 10-hour review wait", carol)
+	}
+
+	cfg.ReviewWaitFactor = 0
+	without := Calculate(data, cfg)
+	if len(without.Participants) != 1 || without.Participants[0].ReviewWaitCost != 0 {
+		t.Errorf("participants with ReviewWaitFactor 0 = %+v, want only bob without a wait", without.Participants)
+	}
+	if diff := breakdown.TotalCost - without.TotalCost; math.Abs(diff-bob.ReviewWaitCost-carol.ReviewWaitCost) > 1e-6 {
+		t.Errorf("review waits added %v to TotalCost, want %v", diff, bob.ReviewWaitCost+carol.ReviewWaitCost)
+	}
+	if breakdown.EfficiencyPct >= without.EfficiencyPct {
+		t.Errorf("EfficiencyPct = %v with review waits, want below %v", breakdown.EfficiencyPct, without.EfficiencyPct)
+	}
+
+	// Nobody waits on a bot's PR
+	data.AuthorBot = true
+	for _, p := range Calculate(data, DefaultConfig()).Participants {
+		if p.ReviewWaitCost != 0 {
+			t.Errorf("bot PR charged %s a review wait of %v", p.Actor, p.ReviewWaitCost)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package cost
+
+// potentialSavings re-runs the preventable delay math for a PR as if it had merged once open
+// for TargetMergeTimeHours, and returns how much less that would have cost. A merged PR
+// drifts no further and needs no more tracking, so only delivery delay (or, for bot PRs,
+// automated updates) remains, charged for the target time instead of the actual one. The rest
+// of the PR's preventable cost, such as revert work and review waits, doesn't depend on how
+// long it stayed open, so it is kept as is.
+func potentialSavings(data PRData, cfg Config, b *Breakdown) float64 {
+	target := cfg.TargetMergeTimeHours
+	if target <= 0 || b.PRDuration <= target {
+		return 0
+	}
+
+	d := b.DelayCostDetail
+	current := b.PreventableCost()
+	unaffected := current - d.DeliveryDelayCost - d.CodeChurnCost - d.AutomatedUpdatesCost - d.PRTrackingCost
+
+	targetHrs, _ := capDelayHours(target, 0, cfg)
+	targetHrs = cfg.chargedHours(data.CreatedAt, targetHrs)
+	var remodeled float64
+	if data.AuthorBot {
+		remodeled = min(d.AutomatedUpdatesCost, b.HourlyRate*targetHrs*cfg.AutomatedUpdatesFactor)
+	} else {
+		remodeled = min(d.DeliveryDelayCost, b.HourlyRate*targetHrs*cfg.DeliveryDelayFactor*d.WaitingMultiplier)
+	}
+	return max(0, current-unaffected-remodeled)
+}
@@ -0,0 +1,54 @@
+package cost
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCalculatePotentialSavings(t *testing.T) {
+	cfg := DefaultConfig()
+	now := time.Now()
+	created := now.Add(-72 * time.Hour)
+	merged := PRData{
+		LinesAdded: 200,
+		Author:     "alice",
+		Events: []ParticipantEvent{
+			{Timestamp: created, Actor: "alice", Kind: "commit"},
+			{Timestamp: created.Add(time.Hour), Actor: "bob", Kind: "review"},
+		},
+		CreatedAt: created,
+		ClosedAt:  now,
+		Merged:    true,
+	}
+
+	b := Calculate(merged, cfg)
+	d := b.DelayCostDetail
+	want := d.DeliveryDelayCost - b.HourlyRate*cfg.TargetMergeTimeHours*cfg.DeliveryDelayFactor*d.WaitingMultiplier
+	if want <= 0 || math.Abs(b.PotentialSavings-want) > 0.01 {
+		t.Errorf("PotentialSavings = $%.2f, want $%.2f (delivery delay beyond the target)", b.PotentialSavings, want)
+	}
+
+	// A PR merged within the target has nothing to save
+	fast := merged
+	fast.CreatedAt = now.Add(-time.Hour)
+	fast.Events = []ParticipantEvent{{Timestamp: fast.CreatedAt, Actor: "alice", Kind: "commit"}}
+	if got := Calculate(fast, cfg).PotentialSavings; got != 0 {
+		t.Errorf("PotentialSavings for a PR merged in an hour = $%.2f, want 0", got)
+	}
+
+	// An open PR would also have stopped drifting and needing tracking
+	open := merged
+	open.ClosedAt, open.Merged = time.Time{}, false
+	open.CreatedAt = now.Add(-30 * 24 * time.Hour)
+	open.Events = []ParticipantEvent{{Timestamp: open.CreatedAt, Actor: "alice", Kind: "commit"}}
+	ob := Calculate(open, cfg)
+	od := ob.DelayCostDetail
+	if od.CodeChurnCost <= 0 || od.PRTrackingCost <= 0 {
+		t.Fatalf("open PR has no churn or tracking cost: %+v", od)
+	}
+	if ob.PotentialSavings < od.CodeChurnCost+od.PRTrackingCost || ob.PotentialSavings > ob.DelayCost {
+		t.Errorf("open PR PotentialSavings = $%.2f, want at least churn + tracking ($%.2f) and at most the delay cost ($%.2f)",
+			ob.PotentialSavings, od.CodeChurnCost+od.PRTrackingCost, ob.DelayCost)
+	}
+}
@@ -0,0 +1,37 @@
+package cost
+
+// Scenario is a named "what-if" configuration used to compare extrapolated
+// costs of a proposed process change against the current baseline.
+type Scenario struct {
+	Name   string
+	Config Config
+}
+
+// ScenarioResult holds the extrapolated totals for a single scenario.
+type ScenarioResult struct {
+	Name         string                `json:"name"`
+	Extrapolated ExtrapolatedBreakdown `json:"extrapolated"`
+}
+
+// ExtrapolateScenarios recalculates already-fetched sample PRs under each scenario's
+// configuration and extrapolates the results, so scenarios can be compared side by side
+// without re-fetching any PR data.
+//
+// The remaining parameters have the same meaning as in ExtrapolateFromSamples.
+// Results are returned in the same order as scenarios.
+func ExtrapolateScenarios(samples []PRData, scenarios []Scenario, totalPRs, totalAuthors, actualOpenPRs, daysInPeriod int,
+	prs []PRSummaryInfo, repoVisibility map[string]bool,
+) []ScenarioResult {
+	results := make([]ScenarioResult, 0, len(scenarios))
+	for _, sc := range scenarios {
+		breakdowns := make([]Breakdown, len(samples))
+		for i := range samples {
+			breakdowns[i] = Calculate(samples[i], sc.Config)
+		}
+		results = append(results, ScenarioResult{
+			Name:         sc.Name,
+			Extrapolated: ExtrapolateFromSamples(breakdowns, totalPRs, totalAuthors, actualOpenPRs, daysInPeriod, sc.Config, prs, repoVisibility),
+		})
+	}
+	return results
+}
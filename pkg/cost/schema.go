@@ -0,0 +1,118 @@
+package cost
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the JSON contract for Breakdown, ExtrapolatedBreakdown and the
+// responses built from them, as MAJOR.MINOR. The major version is bumped on breaking changes:
+// a field removed, renamed, or given a different type or meaning. The minor version is bumped
+// when fields are added. Consumers that reject unknown fields should pin the full version;
+// others need only check the major version.
+const SchemaVersion = "1.4"
+
+// JSONSchemaDraft is the JSON Schema dialect JSONSchemaDefs describes types in.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+)
+
+// JSONSchemaDefs returns JSON Schema definitions for how encoding/json encodes each of values'
+// types, keyed by type name, for use as a schema's "$defs". Every struct type reachable from
+// them gets its own definition, referenced as "#/$defs/Name". Fields tagged omitempty are
+// optional and all others required; nil slices, maps and pointers may also encode as null.
+func JSONSchemaDefs(values ...any) map[string]any {
+	defs := make(map[string]any)
+	for _, v := range values {
+		schemaFor(reflect.TypeOf(v), defs)
+	}
+	return defs
+}
+
+// schemaFor returns the schema for values of typ, adding struct definitions to defs.
+func schemaFor(typ reflect.Type, defs map[string]any) map[string]any {
+	switch {
+	case typ == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case typ == durationType:
+		return map[string]any{"type": "integer", "description": "nanoseconds"}
+	default:
+	}
+	switch typ.Kind() { //nolint:exhaustive // other kinds don't appear in JSON output
+	case reflect.Pointer:
+		return nullable(schemaFor(typ.Elem(), defs))
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return nullable(map[string]any{"type": "array", "items": schemaFor(typ.Elem(), defs)})
+	case reflect.Map:
+		return nullable(map[string]any{"type": "object", "additionalProperties": schemaFor(typ.Elem(), defs)})
+	case reflect.Struct:
+		name := typ.Name()
+		if _, ok := defs[name]; !ok {
+			defs[name] = true // Placeholder, so recursive types terminate
+			properties := make(map[string]any)
+			required := []string{}
+			structFields(typ, defs, properties, &required)
+			defs[name] = map[string]any{"type": "object", "properties": properties, "required": required}
+		}
+		return map[string]any{"$ref": "#/$defs/" + name}
+	default:
+		return map[string]any{}
+	}
+}
+
+// structFields adds typ's JSON fields to properties, and the names of those without omitempty
+// to required. Fields of embedded structs are promoted, as encoding/json does.
+func structFields(typ reflect.Type, defs, properties map[string]any, required *[]string) {
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				structFields(embedded, defs, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = schemaFor(field.Type, defs)
+		if !strings.Contains(","+options+",", ",omitempty,") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// nullable allows schema's value to also be null, as nil pointers, slices and maps encode.
+func nullable(schema map[string]any) map[string]any {
+	if ref, ok := schema["$ref"]; ok {
+		return map[string]any{"anyOf": []any{map[string]any{"$ref": ref}, map[string]any{"type": "null"}}}
+	}
+	if typ, ok := schema["type"].(string); ok {
+		schema["type"] = []string{typ, "null"}
+	}
+	return schema
+}
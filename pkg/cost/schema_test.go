@@ -0,0 +1,90 @@
+package cost
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestJSONSchemaDefs(t *testing.T) {
+	type inner struct {
+		Name string `json:"name"`
+	}
+	type embedded struct {
+		Promoted int `json:"promoted"`
+	}
+	type outer struct {
+		*embedded
+
+		Cost     float64           `json:"cost"`
+		Note     string            `json:"note,omitempty"`
+		Skipped  string            `json:"-"`
+		At       time.Time         `json:"at"`
+		Wait     time.Duration     `json:"wait"`
+		Items    []inner           `json:"items"`
+		Counts   map[string]int    `json:"counts,omitempty"`
+		Optional *inner            `json:"optional,omitempty"`
+		ByName   map[string]*inner `json:"by_name"`
+		Untagged bool
+		hidden   bool
+	}
+
+	defs := JSONSchemaDefs(outer{hidden: true})
+	if len(defs) != 2 {
+		t.Fatalf("got %d definitions, want outer and inner: %v", len(defs), defs)
+	}
+	def, ok := defs["outer"].(map[string]any)
+	if !ok {
+		t.Fatalf("outer definition = %v", defs["outer"])
+	}
+	properties, ok := def["properties"].(map[string]any)
+	if !ok {
+		t.Fatalf("outer properties = %v", def["properties"])
+	}
+	want := []string{"promoted", "cost", "note", "at", "wait", "items", "counts", "optional", "by_name", "Untagged"}
+	if len(properties) != len(want) {
+		t.Errorf("properties = %v, want %v", properties, want)
+	}
+	for _, name := range want {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("property %q missing", name)
+		}
+	}
+	required, ok := def["required"].([]string)
+	if !ok || !slices.Equal(required, []string{"promoted", "cost", "at", "wait", "items", "by_name", "Untagged"}) {
+		t.Errorf("required = %v, want every field without omitempty", def["required"])
+	}
+
+	if got := properties["at"].(map[string]any)["format"]; got != "date-time" {
+		t.Errorf("time.Time format = %v, want date-time", got)
+	}
+	if got := properties["wait"].(map[string]any)["type"]; got != "integer" {
+		t.Errorf("time.Duration type = %v, want integer", got)
+	}
+	items := properties["items"].(map[string]any)
+	if got := items["items"].(map[string]any)["$ref"]; got != "#/$defs/inner" {
+		t.Errorf("slice items = %v, want a reference to inner", got)
+	}
+	if got := items["type"].([]string); !slices.Equal(got, []string{"array", "null"}) {
+		t.Errorf("slice type = %v, want array or null", got)
+	}
+	if _, ok := properties["optional"].(map[string]any)["anyOf"]; !ok {
+		t.Errorf("pointer = %v, want anyOf the reference and null", properties["optional"])
+	}
+}
+
+func TestJSONSchemaDefsCoversResults(t *testing.T) {
+	defs := JSONSchemaDefs(Breakdown{}, ExtrapolatedBreakdown{})
+	for _, name := range []string{"Breakdown", "ExtrapolatedBreakdown", "ParticipantCostDetail", "AuthorRollup"} {
+		if _, ok := defs[name]; !ok {
+			t.Errorf("no definition for %s", name)
+		}
+	}
+	properties, ok := defs["Breakdown"].(map[string]any)["properties"].(map[string]any)
+	if !ok {
+		t.Fatalf("Breakdown definition = %v", defs["Breakdown"])
+	}
+	if _, ok := properties["total_cost"]; !ok {
+		t.Errorf("Breakdown properties = %v, want total_cost", properties)
+	}
+}
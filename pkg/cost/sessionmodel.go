@@ -0,0 +1,14 @@
+package cost
+
+// Session cost models, selected by Config.SessionModel.
+const (
+	// SessionModelGapAware caps the context switch between two sessions at the gap between them (the default).
+	SessionModelGapAware = "gap-aware"
+	// SessionModelFlat charges every session a full context switch in and out.
+	SessionModelFlat = "flat"
+)
+
+// ValidSessionModel reports whether model is a known session cost model. Empty is valid and means gap-aware.
+func ValidSessionModel(model string) bool {
+	return model == "" || model == SessionModelGapAware || model == SessionModelFlat
+}
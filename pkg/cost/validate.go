@@ -0,0 +1,73 @@
+package cost
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Errors returned by Validate. Use errors.Is to check for them.
+var (
+	ErrMissingCreatedAt    = errors.New("CreatedAt is zero")
+	ErrClosedBeforeCreated = errors.New("ClosedAt is before CreatedAt")
+	ErrMergedNotClosed     = errors.New("merged PR has zero ClosedAt")
+	ErrNegativeLines       = errors.New("line counts must not be negative")
+	ErrInvalidEvent        = errors.New("invalid event")
+)
+
+// NewPRData returns PRData for a PR built from metadata the caller already has,
+// for embedding the cost model without fetching from GitHub.
+// A zero closedAt means the PR is still open. Events need not be sorted.
+// Set the remaining fields (AuthorBot, State, Files) directly if known, then check the
+// result with Validate before passing it to Calculate.
+func NewPRData(author string, createdAt, closedAt time.Time, merged bool, linesAdded, linesDeleted int, events []ParticipantEvent) PRData {
+	state := "OPEN"
+	switch {
+	case merged:
+		state = "MERGED"
+	case !closedAt.IsZero():
+		state = "CLOSED"
+	default:
+	}
+	return PRData{
+		Author:       author,
+		CreatedAt:    createdAt,
+		ClosedAt:     closedAt,
+		Merged:       merged,
+		State:        state,
+		LinesAdded:   linesAdded,
+		LinesDeleted: linesDeleted,
+		Events:       events,
+	}
+}
+
+// Validate checks the invariants Calculate assumes about its input:
+// a non-zero CreatedAt, a ClosedAt (if set) no earlier than CreatedAt, a close time for
+// merged PRs (ClosedAt, or one Calculate can infer from MergedAt or a merge event),
+// non-negative line counts, and events that each have an actor and timestamp. Events may be
+// in any order. Calculate tolerates invalid data, but silently clamps negative durations to
+// zero, so callers constructing PRData themselves should validate first.
+func Validate(data PRData) error {
+	if data.CreatedAt.IsZero() {
+		return ErrMissingCreatedAt
+	}
+	if !data.ClosedAt.IsZero() && data.ClosedAt.Before(data.CreatedAt) {
+		return fmt.Errorf("%w: closed %s, created %s", ErrClosedBeforeCreated,
+			data.ClosedAt.Format(time.RFC3339), data.CreatedAt.Format(time.RFC3339))
+	}
+	if data.Merged && data.ClosedAt.IsZero() && inferClosedAt(data).IsZero() {
+		return ErrMergedNotClosed
+	}
+	if data.LinesAdded < 0 || data.LinesDeleted < 0 {
+		return fmt.Errorf("%w: +%d/-%d", ErrNegativeLines, data.LinesAdded, data.LinesDeleted)
+	}
+	for i, event := range data.Events {
+		if event.Actor == "" {
+			return fmt.Errorf("%w: event %d has no actor", ErrInvalidEvent, i)
+		}
+		if event.Timestamp.IsZero() {
+			return fmt.Errorf("%w: event %d has zero timestamp", ErrInvalidEvent, i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package cost
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewPRData(t *testing.T) {
+	created := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	closed := created.Add(4 * time.Hour)
+	events := []ParticipantEvent{
+		{Timestamp: created.Add(2 * time.Hour), Actor: "bob", Kind: "review"},
+		{Timestamp: created, Actor: "alice", Kind: "commit"},
+	}
+
+	data := NewPRData("alice", created, closed, true, 120, 30, events)
+	if data.State != "MERGED" || !data.Merged || data.Author != "alice" {
+		t.Errorf("NewPRData() = %+v", data)
+	}
+	if err := Validate(data); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+	if b := Calculate(data, DefaultConfig()); b.TotalCost <= 0 {
+		t.Errorf("Expected positive total cost, got %v", b.TotalCost)
+	}
+
+	if s := NewPRData("alice", created, closed, false, 1, 0, nil).State; s != "CLOSED" {
+		t.Errorf("closed unmerged State = %q, want CLOSED", s)
+	}
+	if s := NewPRData("alice", created, time.Time{}, false, 1, 0, nil).State; s != "OPEN" {
+		t.Errorf("open State = %q, want OPEN", s)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	created := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	valid := func() PRData {
+		return NewPRData("alice", created, created.Add(time.Hour), true, 10, 0,
+			[]ParticipantEvent{{Timestamp: created, Actor: "alice", Kind: "commit"}})
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*PRData)
+		want   error
+	}{
+		{"valid", func(*PRData) {}, nil},
+		{"open PR", func(d *PRData) { d.ClosedAt, d.Merged = time.Time{}, false }, nil},
+		{"missing CreatedAt", func(d *PRData) { d.CreatedAt = time.Time{} }, ErrMissingCreatedAt},
+		{"closed before created", func(d *PRData) { d.ClosedAt = created.Add(-time.Hour) }, ErrClosedBeforeCreated},
+		{"merged without ClosedAt", func(d *PRData) { d.ClosedAt = time.Time{} }, ErrMergedNotClosed},
+		{"merged with only a merge event", func(d *PRData) {
+			d.ClosedAt = time.Time{}
+			d.Events = append(d.Events, ParticipantEvent{Timestamp: created.Add(time.Hour), Actor: "bob", Kind: "merged"})
+		}, nil},
+		{"negative lines", func(d *PRData) { d.LinesDeleted = -1 }, ErrNegativeLines},
+		{"event without actor", func(d *PRData) { d.Events[0].Actor = "" }, ErrInvalidEvent},
+		{"event without timestamp", func(d *PRData) { d.Events[0].Timestamp = time.Time{} }, ErrInvalidEvent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := valid()
+			tt.modify(&data)
+			err := Validate(data)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
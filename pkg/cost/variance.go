@@ -0,0 +1,95 @@
+package cost
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Variance compares a PR's modeled hours with the hours actually tracked against it,
+// for calibrating the model (for example EventDuration or COCOMO) against time-tracking data.
+type Variance struct {
+	ModeledHours float64 `json:"modeled_hours"` // Author and participant hours; delay and future costs are excluded
+	ActualHours  float64 `json:"actual_hours"`  // Tracked hours from PRData.ActualHours
+	ErrorPct     float64 `json:"error_pct"`     // (modeled - actual) / actual × 100; negative when the model underestimates
+}
+
+// ModeledHours returns the hands-on hours b models: the author's and every participant's
+// hours. Delay, churn, tracking and future costs aren't time anyone would log against the PR.
+func ModeledHours(b *Breakdown) float64 {
+	hours := b.Author.TotalHours
+	for _, p := range b.Participants {
+		hours += p.TotalHours
+	}
+	return hours
+}
+
+// newVariance compares b's modeled hours with actualHours, or returns nil if actualHours is unset.
+func newVariance(b *Breakdown, actualHours float64) *Variance {
+	if actualHours <= 0 {
+		return nil
+	}
+	modeled := ModeledHours(b)
+	return &Variance{
+		ModeledHours: modeled,
+		ActualHours:  actualHours,
+		ErrorPct:     100.0 * (modeled - actualHours) / actualHours,
+	}
+}
+
+// NormalizePRURL trims whitespace and any trailing slash from a PR URL, so URLs from
+// time-tracking exports match the URLs prcost fetches.
+func NormalizePRURL(prURL string) string {
+	return strings.TrimSuffix(strings.TrimSpace(prURL), "/")
+}
+
+// ParseActualHoursCSV reads "pr_url,hours" rows, such as time entries exported from a
+// time tracker and summed per PR, into a map of normalized PR URL to hours for
+// PRData.ActualHours. A leading header row starting with "pr_url" is skipped, as are
+// blank lines and lines starting with '#'. Repeated URLs are summed.
+func ParseActualHoursCSV(r io.Reader) (map[string]float64, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	actuals := make(map[string]float64)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("actuals file line %d: malformed CSV", parseErr.Line)
+			}
+			return nil, fmt.Errorf("reading actuals file: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if len(record) != 2 {
+			return nil, fmt.Errorf("actuals file line %d: expected 2 columns (pr_url,hours), got %d", line, len(record))
+		}
+		prURL := NormalizePRURL(record[0])
+		if line == 1 && strings.EqualFold(prURL, "pr_url") {
+			continue // Header
+		}
+		if prURL == "" {
+			return nil, fmt.Errorf("actuals file line %d: empty PR URL", line)
+		}
+		hours, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil || hours <= 0 {
+			return nil, fmt.Errorf("actuals file line %d: hours must be a positive number", line)
+		}
+		actuals[prURL] += hours
+	}
+
+	if len(actuals) == 0 {
+		return nil, errors.New("actuals file has no entries")
+	}
+	return actuals, nil
+}
@@ -0,0 +1,74 @@
+package cost
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalculateVariance(t *testing.T) {
+	created := time.Now().Add(-48 * time.Hour)
+	data := PRData{
+		LinesAdded: 100,
+		Author:     "alice",
+		CreatedAt:  created,
+		ClosedAt:   created.Add(24 * time.Hour),
+		Merged:     true,
+		Events: []ParticipantEvent{
+			{Timestamp: created, Actor: "alice", Kind: "commit"},
+			{Timestamp: created.Add(2 * time.Hour), Actor: "bob", Kind: "review"},
+		},
+	}
+	cfg := DefaultConfig()
+
+	base := Calculate(data, cfg)
+	if base.Variance != nil {
+		t.Errorf("Variance = %+v without actual hours, want nil", base.Variance)
+	}
+
+	modeled := ModeledHours(&base)
+	data.ActualHours = modeled * 2
+	b := Calculate(data, cfg)
+	if b.Variance == nil {
+		t.Fatal("Variance = nil with actual hours")
+	}
+	if b.Variance.ModeledHours != modeled || b.Variance.ActualHours != modeled*2 {
+		t.Errorf("Variance = %+v, want modeled %v, actual %v", b.Variance, modeled, modeled*2)
+	}
+	// Actuals running at twice the estimate mean the model is 50% low
+	if math.Abs(b.Variance.ErrorPct+50) > 0.001 {
+		t.Errorf("ErrorPct = %v, want -50", b.Variance.ErrorPct)
+	}
+	if want := b.Author.TotalHours + b.Participants[0].TotalHours; math.Abs(modeled-want) > 0.001 {
+		t.Errorf("ModeledHours() = %v, want author plus participant hours %v", modeled, want)
+	}
+}
+
+func TestParseActualHoursCSV(t *testing.T) {
+	input := `pr_url,hours
+# exported from the time tracker
+https://github.com/o/r/pull/1, 3.5
+https://github.com/o/r/pull/2/,2
+https://github.com/o/r/pull/1,1.5
+`
+	actuals, err := ParseActualHoursCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseActualHoursCSV() error: %v", err)
+	}
+	want := map[string]float64{"https://github.com/o/r/pull/1": 5, "https://github.com/o/r/pull/2": 2}
+	if len(actuals) != len(want) {
+		t.Fatalf("ParseActualHoursCSV() = %v, want %v", actuals, want)
+	}
+	for prURL, hours := range want {
+		if actuals[prURL] != hours {
+			t.Errorf("actuals[%q] = %v, want %v", prURL, actuals[prURL], hours)
+		}
+	}
+
+	for _, bad := range []string{"", "pr_url,hours\n", "https://github.com/o/r/pull/1\n", "https://github.com/o/r/pull/1,-2\n", ",3\n"} {
+		if _, err := ParseActualHoursCSV(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseActualHoursCSV(%q) succeeded, want error", bad)
+		}
+	}
+}
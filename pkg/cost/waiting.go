@@ -0,0 +1,145 @@
+package cost
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Delivery delay bases, reported in DelayCostDetail.DeliveryDelayBasis.
+const (
+	// DelayBasisOpenTime charges delivery delay for the PR's whole open time (the default).
+	DelayBasisOpenTime = "open_time"
+	// DelayBasisWaiting charges delivery delay only from the first evidence that someone was waiting.
+	DelayBasisWaiting = "waiting"
+	// DelayBasisNoWaitingEvidence means nobody was shown to be waiting, so no delivery delay was charged.
+	DelayBasisNoWaitingEvidence = "no_waiting_evidence"
+	// DelayBasisReady charges delivery delay only from when a PR opened as a draft was first ready for review.
+	DelayBasisReady = "ready_for_review"
+	// DelayBasisDraft means the PR has been a draft since it was opened, so no delivery delay was charged.
+	DelayBasisDraft = "draft"
+)
+
+// readyForReviewAt returns when the PR was first ready for review: when it was created, or,
+// if it was opened as a draft, its first ready_for_review event. It returns false for a PR
+// that is still the draft it was opened as.
+func readyForReviewAt(data PRData) (time.Time, bool) {
+	// The earliest draft transition shows whether the PR was opened as a draft
+	var first *ParticipantEvent
+	for i := range data.Events {
+		event := &data.Events[i]
+		if event.Kind != "ready_for_review" && event.Kind != "convert_to_draft" {
+			continue
+		}
+		if first == nil || event.Timestamp.Before(first.Timestamp) {
+			first = event
+		}
+	}
+	switch {
+	case first != nil && first.Kind == "ready_for_review":
+		return first.Timestamp, true
+	case first == nil && data.Draft:
+		return time.Time{}, false
+	default:
+		return data.CreatedAt, true
+	}
+}
+
+// firstWaitingEvidence returns when someone first showed they were waiting on the PR:
+// the first review request, or the first event by anyone other than the author, such as
+// a review or comment. It returns false for PRs only the author ever touched, which may
+// be speculative work that blocked nobody.
+func firstWaitingEvidence(data PRData) (time.Time, bool) {
+	var first time.Time
+	for _, event := range data.Events {
+		if event.Kind != "review_requested" && event.Actor == data.Author {
+			continue
+		}
+		if first.IsZero() || event.Timestamp.Before(first) {
+			first = event.Timestamp
+		}
+	}
+	return first, !first.IsZero()
+}
+
+// waitingDelayHours limits delivery delay to the time the PR was ready for review, unless
+// cfg.CountDraftTime is set, and to the time someone was waiting on it when
+// cfg.RequireWaitingEvidence is set. cappedHrs is the delay after the usual caps; the result
+// never exceeds it. It returns when the charged time starts, the hours to charge, and the basis used.
+func waitingDelayHours(data PRData, cfg Config, cappedHrs float64, endTime time.Time) (time.Time, float64, string) {
+	start, basis := data.CreatedAt, DelayBasisOpenTime
+	if !cfg.CountDraftTime {
+		ready, ok := readyForReviewAt(data)
+		if !ok {
+			return time.Time{}, 0, DelayBasisDraft
+		}
+		if ready.After(start) {
+			start, basis = ready, DelayBasisReady
+		}
+	}
+	if cfg.RequireWaitingEvidence {
+		waitingSince, ok := firstWaitingEvidence(data)
+		if !ok {
+			return time.Time{}, 0, DelayBasisNoWaitingEvidence
+		}
+		start, basis = later(start, waitingSince), DelayBasisWaiting
+	}
+	if basis == DelayBasisOpenTime {
+		return start, cappedHrs, basis
+	}
+	return start, min(cappedHrs, max(endTime.Sub(start).Hours(), 0)), basis
+}
+
+// later returns the later of a and b.
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// githubLogin matches a GitHub login, as opposed to a git author name like "Jane Doe".
+var githubLogin = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,38})(?:\[bot\])?$`)
+
+// countPeopleWaiting estimates how many people are blocked on the PR: distinct participants
+// other than the author who reviewed or commented and then went idle on it, pushing no commits
+// of their own afterwards. Someone who went on to commit was working on the PR, not waiting for it.
+// People are compared by login, case-insensitively: a commit whose author isn't linked to a GitHub
+// account names them by their git name instead, which can't be matched to anyone's feedback.
+func countPeopleWaiting(data PRData) int {
+	lastFeedback := make(map[string]time.Time)
+	lastCommit := make(map[string]time.Time)
+	for _, event := range data.Events {
+		if strings.EqualFold(event.Actor, data.Author) || !githubLogin.MatchString(event.Actor) {
+			continue
+		}
+		login := strings.ToLower(event.Actor)
+		switch event.Kind {
+		case "review", "review_comment", "comment":
+			if event.Timestamp.After(lastFeedback[login]) {
+				lastFeedback[login] = event.Timestamp
+			}
+		case "commit":
+			if event.Timestamp.After(lastCommit[login]) {
+				lastCommit[login] = event.Timestamp
+			}
+		default:
+		}
+	}
+	waiting := 0
+	for actor, feedback := range lastFeedback {
+		if !lastCommit[actor].After(feedback) {
+			waiting++
+		}
+	}
+	return waiting
+}
+
+// waitingMultiplier returns the factor delivery delay is scaled by for peopleWaiting people:
+// their count, at least 1 and at most MaxWaitingMultiplier.
+func (c *Config) waitingMultiplier(peopleWaiting int) float64 {
+	if c.MaxWaitingMultiplier <= 1 {
+		return 1
+	}
+	return min(max(float64(peopleWaiting), 1), c.MaxWaitingMultiplier)
+}
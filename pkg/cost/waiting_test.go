@@ -0,0 +1,183 @@
+package cost
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRequireWaitingEvidence(t *testing.T) {
+	created := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	closed := created.Add(48 * time.Hour)
+	soloEvents := []ParticipantEvent{
+		{Timestamp: created, Actor: "author", Kind: "commit"},
+		{Timestamp: created.Add(40 * time.Hour), Actor: "author", Kind: "commit"},
+	}
+	solo := NewPRData("author", created, closed, true, 100, 0, soloEvents)
+	// Same PR, but a reviewer engages 24 hours before merge
+	reviewed := NewPRData("author", created, closed, true, 100, 0, append(soloEvents,
+		ParticipantEvent{Timestamp: created.Add(24 * time.Hour), Actor: "reviewer", Kind: "review"}))
+
+	cfg := DefaultConfig()
+	base := Calculate(solo, cfg)
+	if base.DelayCostDetail.DeliveryDelayBasis != DelayBasisOpenTime || base.DelayCostDetail.DeliveryDelayCost <= 0 {
+		t.Fatalf("default: basis %q, delivery delay %v; want open_time with a positive cost",
+			base.DelayCostDetail.DeliveryDelayBasis, base.DelayCostDetail.DeliveryDelayCost)
+	}
+
+	cfg.RequireWaitingEvidence = true
+
+	// No review requests or reviewer activity: nobody was blocked
+	got := Calculate(solo, cfg)
+	if got.DelayCostDetail.DeliveryDelayBasis != DelayBasisNoWaitingEvidence || got.DelayCostDetail.DeliveryDelayCost != 0 {
+		t.Errorf("no waiting evidence: basis %q, delivery delay %v; want %q and 0",
+			got.DelayCostDetail.DeliveryDelayBasis, got.DelayCostDetail.DeliveryDelayCost, DelayBasisNoWaitingEvidence)
+	}
+	if got.DelayCostDetail.CodeChurnCost != base.DelayCostDetail.CodeChurnCost {
+		t.Errorf("code churn = %v, want unchanged %v", got.DelayCostDetail.CodeChurnCost, base.DelayCostDetail.CodeChurnCost)
+	}
+
+	// Active reviewer: delivery delay runs from their first event to merge (24 of 48 hours)
+	got = Calculate(reviewed, cfg)
+	want := 24 * cfg.DeliveryDelayFactor
+	if got.DelayCostDetail.DeliveryDelayBasis != DelayBasisWaiting || math.Abs(got.DelayCostDetail.DeliveryDelayHours-want) > 1e-9 {
+		t.Errorf("active reviewer: basis %q, delivery delay hours %v; want %q and %v",
+			got.DelayCostDetail.DeliveryDelayBasis, got.DelayCostDetail.DeliveryDelayHours, DelayBasisWaiting, want)
+	}
+
+	// A review request by the author counts as someone waiting
+	requested := NewPRData("author", created, closed, true, 100, 0, append(soloEvents,
+		ParticipantEvent{Timestamp: created.Add(36 * time.Hour), Actor: "author", Kind: "review_requested"}))
+	got = Calculate(requested, cfg)
+	if want := 12 * cfg.DeliveryDelayFactor; math.Abs(got.DelayCostDetail.DeliveryDelayHours-want) > 1e-9 {
+		t.Errorf("review requested: delivery delay hours %v, want %v", got.DelayCostDetail.DeliveryDelayHours, want)
+	}
+}
+
+func TestDraftTimeExcludedFromDeliveryDelay(t *testing.T) {
+	created := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	closed := created.Add(48 * time.Hour)
+	events := []ParticipantEvent{
+		{Timestamp: created, Actor: "author", Kind: "commit"},
+		{Timestamp: created.Add(30 * time.Hour), Actor: "author", Kind: "ready_for_review"},
+		{Timestamp: created.Add(40 * time.Hour), Actor: "reviewer", Kind: "review"},
+	}
+	opened := NewPRData("author", created, closed, true, 100, 0, events)
+	cfg := DefaultConfig()
+
+	// Opened as a draft: delivery delay runs from ready for review to merge (18 of 48 hours)
+	got := Calculate(opened, cfg)
+	want := 18 * cfg.DeliveryDelayFactor
+	if got.DelayCostDetail.DeliveryDelayBasis != DelayBasisReady || math.Abs(got.DelayCostDetail.DeliveryDelayHours-want) > 1e-9 {
+		t.Errorf("draft then ready: basis %q, delivery delay hours %v; want %q and %v",
+			got.DelayCostDetail.DeliveryDelayBasis, got.DelayCostDetail.DeliveryDelayHours, DelayBasisReady, want)
+	}
+
+	// Opting out charges the whole open time
+	cfg.CountDraftTime = true
+	full := Calculate(opened, cfg)
+	if full.DelayCostDetail.DeliveryDelayBasis != DelayBasisOpenTime || math.Abs(full.DelayCostDetail.DeliveryDelayHours-48*cfg.DeliveryDelayFactor) > 1e-9 {
+		t.Errorf("CountDraftTime: basis %q, delivery delay hours %v; want %q and %v",
+			full.DelayCostDetail.DeliveryDelayBasis, full.DelayCostDetail.DeliveryDelayHours, DelayBasisOpenTime, 48*cfg.DeliveryDelayFactor)
+	}
+	if full.DelayCostDetail.CodeChurnCost != got.DelayCostDetail.CodeChurnCost {
+		t.Errorf("code churn = %v, want unchanged %v", got.DelayCostDetail.CodeChurnCost, full.DelayCostDetail.CodeChurnCost)
+	}
+	cfg.CountDraftTime = false
+
+	// Waiting evidence during the draft doesn't start the clock before the PR was ready
+	cfg.RequireWaitingEvidence = true
+	early := NewPRData("author", created, closed, true, 100, 0, append(events,
+		ParticipantEvent{Timestamp: created.Add(6 * time.Hour), Actor: "reviewer", Kind: "comment"}))
+	got = Calculate(early, cfg)
+	if got.DelayCostDetail.DeliveryDelayBasis != DelayBasisWaiting || math.Abs(got.DelayCostDetail.DeliveryDelayHours-want) > 1e-9 {
+		t.Errorf("waiting evidence in draft: basis %q, delivery delay hours %v; want %q and %v",
+			got.DelayCostDetail.DeliveryDelayBasis, got.DelayCostDetail.DeliveryDelayHours, DelayBasisWaiting, want)
+	}
+	cfg.RequireWaitingEvidence = false
+
+	// Still a draft: nothing was ready to deliver
+	draft := NewPRData("author", created, time.Time{}, false, 100, 0, events[:1])
+	draft.Draft = true
+	got = Calculate(draft, cfg)
+	if got.DelayCostDetail.DeliveryDelayBasis != DelayBasisDraft || got.DelayCostDetail.DeliveryDelayCost != 0 {
+		t.Errorf("open draft: basis %q, delivery delay %v; want %q and 0",
+			got.DelayCostDetail.DeliveryDelayBasis, got.DelayCostDetail.DeliveryDelayCost, DelayBasisDraft)
+	}
+}
+
+func TestReadyForReviewAt(t *testing.T) {
+	created := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return created.Add(time.Duration(h) * time.Hour) }
+	tests := []struct {
+		name   string
+		events []ParticipantEvent
+		draft  bool
+		want   time.Time
+		ready  bool
+	}{
+		{"opened ready", nil, false, created, true},
+		{"opened as draft", []ParticipantEvent{{Timestamp: at(5), Kind: "ready_for_review"}, {Timestamp: at(9), Kind: "convert_to_draft"}, {Timestamp: at(12), Kind: "ready_for_review"}}, false, at(5), true},
+		{"converted to draft later", []ParticipantEvent{{Timestamp: at(5), Kind: "convert_to_draft"}, {Timestamp: at(8), Kind: "ready_for_review"}}, false, created, true},
+		{"converted to draft and still draft", []ParticipantEvent{{Timestamp: at(5), Kind: "convert_to_draft"}}, true, created, true},
+		{"still the draft it was opened as", nil, true, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := readyForReviewAt(PRData{CreatedAt: created, Events: tt.events, Draft: tt.draft})
+		if !got.Equal(tt.want) || ok != tt.ready {
+			t.Errorf("%s: readyForReviewAt() = %v, %v; want %v, %v", tt.name, got, ok, tt.want, tt.ready)
+		}
+	}
+}
+
+func TestPeopleWaitingScalesDeliveryDelay(t *testing.T) {
+	created := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	closed := created.Add(48 * time.Hour)
+	at := func(h int) time.Time { return created.Add(time.Duration(h) * time.Hour) }
+	events := []ParticipantEvent{
+		{Timestamp: at(0), Actor: "author", Kind: "commit"},
+		{Timestamp: at(2), Actor: "bob", Kind: "review"},
+		{Timestamp: at(3), Actor: "carol", Kind: "comment"},
+		{Timestamp: at(4), Actor: "Carol Jones", Kind: "commit"}, // A git name, not carol's login
+		{Timestamp: at(4), Actor: "dave", Kind: "review_comment"},
+		{Timestamp: at(5), Actor: "erin", Kind: "comment"},
+		{Timestamp: at(6), Actor: "Erin", Kind: "commit"}, // Went on to work on the PR; logins ignore case
+		{Timestamp: at(7), Actor: "frank", Kind: "labeled"},
+		{Timestamp: at(8), Actor: "author", Kind: "comment"},
+	}
+	data := NewPRData("author", created, closed, true, 100, 0, events)
+	if got := countPeopleWaiting(data); got != 3 {
+		t.Errorf("countPeopleWaiting() = %d, want 3 (bob, carol, dave)", got)
+	}
+
+	cfg := DefaultConfig()
+	base := Calculate(data, cfg)
+	if base.DelayCostDetail.PeopleWaiting != 3 || base.DelayCostDetail.WaitingMultiplier != 1 {
+		t.Errorf("default: people waiting %d, multiplier %v; want 3 and 1",
+			base.DelayCostDetail.PeopleWaiting, base.DelayCostDetail.WaitingMultiplier)
+	}
+
+	tests := []struct {
+		cap  float64
+		want float64
+	}{
+		{cap: 5, want: 3},
+		{cap: 2, want: 2},
+		{cap: 0.5, want: 1},
+	}
+	for _, tt := range tests {
+		cfg.MaxWaitingMultiplier = tt.cap
+		got := Calculate(data, cfg).DelayCostDetail
+		if got.WaitingMultiplier != tt.want || math.Abs(got.DeliveryDelayCost-base.DelayCostDetail.DeliveryDelayCost*tt.want) > 1e-6 {
+			t.Errorf("cap %v: multiplier %v, delivery delay %v; want %v and %v",
+				tt.cap, got.WaitingMultiplier, got.DeliveryDelayCost, tt.want, base.DelayCostDetail.DeliveryDelayCost*tt.want)
+		}
+	}
+
+	// Nobody waiting still charges the unscaled delivery delay
+	cfg.MaxWaitingMultiplier = 5
+	solo := NewPRData("author", created, closed, true, 100, 0, events[:1])
+	if got := Calculate(solo, cfg).DelayCostDetail; got.PeopleWaiting != 0 || got.WaitingMultiplier != 1 {
+		t.Errorf("solo PR: people waiting %d, multiplier %v; want 0 and 1", got.PeopleWaiting, got.WaitingMultiplier)
+	}
+}
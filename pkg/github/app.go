@@ -0,0 +1,348 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// appJWTLifetime is how long the App's own JWTs are valid; GitHub accepts at most 10 minutes.
+	appJWTLifetime = 9 * time.Minute
+	// appJWTClockSkew backdates each JWT's issue time, so a server clock running slightly
+	// behind GitHub's doesn't make it "not yet valid".
+	appJWTClockSkew = time.Minute
+	// appTokenRefreshMargin is how long before expiry an installation token is replaced. Tokens
+	// last an hour, so a fetch that starts with a cached token has time to finish with it.
+	appTokenRefreshMargin = 5 * time.Minute
+)
+
+// AppTokenSource mints GitHub App installation tokens, so a server can fetch PR data as its App
+// with the installation's permissions instead of a static personal token. Each owner's
+// installation is looked up once, and each installation's token is cached until
+// appTokenRefreshMargin before it expires, then replaced on the next call to Token. API calls are
+// retried like other GitHub calls (see APIOptions). It is safe for concurrent use.
+type AppTokenSource struct {
+	appID          string
+	key            *rsa.PrivateKey
+	installationID int64 // Configured installation, used for every owner; 0 to look them up
+	client         *http.Client
+	baseURL        string
+	now            func() time.Time
+
+	mintMu        sync.Mutex         // Held while looking up installations and minting, so concurrent callers don't each mint a token
+	mu            sync.Mutex         // Guards installations and tokens
+	installations map[string]int64   // Looked-up installation IDs, by lowercased owner ("" for the App's only installation)
+	tokens        map[int64]appToken // Cached tokens, by installation ID
+}
+
+// appToken is a cached installation token.
+type appToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+// NewAppTokenSource returns a token source for the GitHub App appID, signing with its PEM-encoded
+// private key (PKCS #1, as GitHub issues them, or PKCS #8). Tokens are minted for installationID,
+// or, if it is 0, for the installation on the owner of the repos being fetched (see Token). The
+// App's API calls go to host (see ParseHost), which is github.com if empty, and are retried as api
+// configures (nil for the defaults).
+func NewAppTokenSource(appID string, keyPEM []byte, installationID int64, host string, api *APIOptions) (*AppTokenSource, error) {
+	if strings.TrimSpace(appID) == "" {
+		return nil, errors.New("GitHub App ID is required")
+	}
+	if installationID < 0 {
+		return nil, fmt.Errorf("invalid GitHub App installation ID %d", installationID)
+	}
+	key, err := parseAppKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	host, err = ParseHost(host)
+	if err != nil {
+		return nil, err
+	}
+	return &AppTokenSource{
+		appID:          strings.TrimSpace(appID),
+		key:            key,
+		installationID: installationID,
+		client:         apiHTTPClient(api),
+		baseURL:        hostAPIBaseURL(host),
+		now:            time.Now,
+		installations:  make(map[string]int64),
+		tokens:         make(map[int64]appToken),
+	}, nil
+}
+
+// parseAppKey parses a GitHub App's PEM-encoded RSA private key.
+func parseAppKey(keyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("GitHub App private key is not PEM-encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("GitHub App private key must be an RSA key")
+	}
+	return key, nil
+}
+
+// Token returns a current token for the installation on owner's repos, minting a new one if
+// the cached token is missing or close to expiry. The installation is looked up through repo if
+// given, else as owner's organization or user installation; without an owner, the App must have
+// exactly one installation. A configured installation ID is used for every owner. Callers
+// arriving while another refreshes the token keep using the cached one, and if minting fails
+// while that token is still valid, it is returned, so a brief GitHub outage doesn't fail
+// requests the old token can still serve.
+func (s *AppTokenSource) Token(ctx context.Context, owner, repo string) (string, error) {
+	id, known := s.installation(owner)
+	if known {
+		if token, ok := s.cached(id, appTokenRefreshMargin); ok {
+			return token, nil
+		}
+	}
+	if !s.mintMu.TryLock() {
+		if known {
+			if token, ok := s.cached(id, 0); ok {
+				return token, nil
+			}
+		}
+		s.mintMu.Lock()
+	}
+	defer s.mintMu.Unlock()
+	if !known {
+		var err error
+		if id, err = s.lookUpInstallation(ctx, owner, repo); err != nil {
+			return "", err
+		}
+	}
+	// Another caller may have refreshed the token while this one waited
+	if token, ok := s.cached(id, appTokenRefreshMargin); ok {
+		return token, nil
+	}
+
+	token, expiresAt, err := s.mint(ctx, id)
+	if err != nil {
+		if current, ok := s.cached(id, 0); ok {
+			slog.Warn("Refreshing GitHub App installation token failed; using the current one until it expires",
+				"installation_id", id, "error", err)
+			return current, nil
+		}
+		// The App may have been uninstalled, or installed again under a new ID
+		s.mu.Lock()
+		delete(s.installations, strings.ToLower(owner))
+		s.mu.Unlock()
+		return "", err
+	}
+	s.mu.Lock()
+	s.tokens[id] = appToken{token: token, expiresAt: expiresAt}
+	s.mu.Unlock()
+	slog.Info("Minted GitHub App installation token", "app_id", s.appID, "installation_id", id, "expires_at", expiresAt)
+	return token, nil
+}
+
+// Check verifies the App's ID and private key by fetching the App itself with a JWT. Unlike
+// Token without an owner, it doesn't need the App to have exactly one installation.
+func (s *AppTokenSource) Check(ctx context.Context) error {
+	jwt, err := s.jwt()
+	if err != nil {
+		return err
+	}
+	var app struct {
+		ID int64 `json:"id"`
+	}
+	if err := s.appRequest(ctx, http.MethodGet, "/app", jwt, &app); err != nil {
+		return fmt.Errorf("check GitHub App %s: %w", s.appID, err)
+	}
+	return nil
+}
+
+// installation returns the configured installation, or the one already looked up for owner.
+func (s *AppTokenSource) installation(owner string) (int64, bool) {
+	if s.installationID != 0 {
+		return s.installationID, true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.installations[strings.ToLower(owner)]
+	return id, ok
+}
+
+// cached returns installation id's cached token if it is valid for at least margin longer.
+func (s *AppTokenSource) cached(id int64, margin time.Duration) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cached := s.tokens[id]
+	if cached.token == "" || !s.now().Before(cached.expiresAt.Add(-margin)) {
+		return "", false
+	}
+	return cached.token, true
+}
+
+// Forget drops token from the cache if it is an installation's current one, so the next call to
+// Token for it mints a new one. Call it when GitHub rejects the token, e.g. after the
+// installation's permissions changed.
+func (s *AppTokenSource) Forget(token string) {
+	if token == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, cached := range s.tokens {
+		if cached.token == token {
+			delete(s.tokens, id)
+		}
+	}
+}
+
+// lookUpInstallation finds the installation for owner's repos, as described on Token, and
+// remembers it.
+func (s *AppTokenSource) lookUpInstallation(ctx context.Context, owner, repo string) (int64, error) {
+	// Another caller may have looked it up while this one waited
+	if id, ok := s.installation(owner); ok {
+		return id, nil
+	}
+	jwt, err := s.jwt()
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	if owner == "" {
+		var installations []struct {
+			ID int64 `json:"id"`
+		}
+		if err := s.appRequest(ctx, http.MethodGet, "/app/installations", jwt, &installations); err != nil {
+			return 0, fmt.Errorf("list GitHub App installations: %w", err)
+		}
+		if len(installations) != 1 {
+			return 0, fmt.Errorf("GitHub App %s has %d installations: configure the installation ID to use", s.appID, len(installations))
+		}
+		id = installations[0].ID
+	} else {
+		paths := []string{"/orgs/" + url.PathEscape(owner) + "/installation", "/users/" + url.PathEscape(owner) + "/installation"}
+		if repo != "" {
+			paths = []string{"/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/installation"}
+		}
+		for _, path := range paths {
+			var installation struct {
+				ID int64 `json:"id"`
+			}
+			err = s.appRequest(ctx, http.MethodGet, path, jwt, &installation)
+			if err == nil {
+				id = installation.ID
+				break
+			}
+			if !errors.Is(err, errAppNotFound) {
+				return 0, fmt.Errorf("look up GitHub App installation for %s: %w", owner, err)
+			}
+		}
+		if id == 0 {
+			return 0, fmt.Errorf("GitHub App %s is not installed for %s: %w", s.appID, owner, err)
+		}
+	}
+
+	s.mu.Lock()
+	s.installations[strings.ToLower(owner)] = id
+	s.mu.Unlock()
+	slog.Info("Found GitHub App installation", "app_id", s.appID, "owner", owner, "installation_id", id)
+	return id, nil
+}
+
+// mint creates a token for installation id.
+func (s *AppTokenSource) mint(ctx context.Context, id int64) (string, time.Time, error) {
+	jwt, err := s.jwt()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	var minted struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	path := fmt.Sprintf("/app/installations/%d/access_tokens", id)
+	if err := s.appRequest(ctx, http.MethodPost, path, jwt, &minted); err != nil {
+		return "", time.Time{}, fmt.Errorf("mint GitHub App installation token: %w", err)
+	}
+	if minted.Token == "" {
+		return "", time.Time{}, errors.New("mint GitHub App installation token: empty token in response")
+	}
+	return minted.Token, minted.ExpiresAt, nil
+}
+
+// jwt returns a JWT authenticating as the App itself, signed with RS256.
+func (s *AppTokenSource) jwt() (string, error) {
+	now := s.now()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", fmt.Errorf("encode JWT header: %w", err)
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": s.appID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("encode JWT claims: %w", err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("sign JWT: %w", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// errAppNotFound is returned by appRequest for a 404, such as looking up an installation the App
+// doesn't have.
+var errAppNotFound = errors.New("not found")
+
+// appRequest sends an App-authenticated REST API request and decodes the response into out.
+func (s *AppTokenSource) appRequest(ctx context.Context, method, path, jwt string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // best effort close
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s %s: %w", method, path, errAppNotFound)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxPeekBytes)) //nolint:errcheck // message is best effort
+		return fmt.Errorf("%s %s: unexpected status code %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAppAPI serves the GitHub App endpoints AppTokenSource calls, checking each request's JWT.
+type fakeAppAPI struct {
+	t             *testing.T
+	key           *rsa.PublicKey
+	now           time.Time
+	installations string // JSON body of GET /app/installations
+	fail          bool   // Reject token requests
+
+	mu      sync.Mutex
+	minted  int
+	lookups int
+}
+
+func (f *fakeAppAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.checkJWT(r.Header.Get("Authorization"))
+	// Installation 42 is the only one listed; acme has 7, and the user bob has 8
+	lookups := map[string]string{
+		"/repos/acme/widgets/installation": `{"id":7}`,
+		"/orgs/acme/installation":          `{"id":7}`,
+		"/users/bob/installation":          `{"id":8}`,
+	}
+	switch id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/app/installations/"), "/access_tokens"); {
+	case r.Method == http.MethodGet && r.URL.Path == "/app":
+		fmt.Fprint(w, `{"id":123}`)
+	case r.Method == http.MethodGet && r.URL.Path == "/app/installations":
+		fmt.Fprint(w, f.installations)
+	case r.Method == http.MethodGet && lookups[r.URL.Path] != "":
+		f.mu.Lock()
+		f.lookups++
+		f.mu.Unlock()
+		fmt.Fprint(w, lookups[r.URL.Path])
+	case r.Method == http.MethodPost && (id == "42" || id == "7" || id == "8"):
+		if f.fail {
+			http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+			return
+		}
+		f.mu.Lock()
+		f.minted++
+		n := f.minted
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token":"ghs_%d","expires_at":%q}`, n, f.now.Add(time.Hour).Format(time.RFC3339))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// checkJWT verifies that auth carries a JWT for App 123 signed with the App's key.
+func (f *fakeAppAPI) checkJWT(auth string) {
+	jwt, ok := strings.CutPrefix(auth, "Bearer ")
+	parts := strings.Split(jwt, ".")
+	if !ok || len(parts) != 3 {
+		f.t.Errorf("Authorization = %q, want a bearer JWT", auth)
+		return
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		f.t.Errorf("JWT signature: %v", err)
+		return
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(f.key, crypto.SHA256, digest[:], signature); err != nil {
+		f.t.Errorf("JWT signature doesn't verify: %v", err)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		f.t.Errorf("JWT claims: %v", err)
+		return
+	}
+	var claims struct {
+		Iss string `json:"iss"`
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		f.t.Errorf("JWT claims %s: %v", payload, err)
+	}
+	if claims.Iss != "123" || claims.Iat >= f.now.Unix() || claims.Exp-claims.Iat > 600 {
+		f.t.Errorf("JWT claims = %+v, want App 123, backdated, valid at most 10 minutes", claims)
+	}
+}
+
+func TestAppTokenSource(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	api := &fakeAppAPI{t: t, key: &key.PublicKey, now: now, installations: `[{"id":42}]`}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	source, err := NewAppTokenSource("123", keyPEM, 0, "", nil)
+	if err != nil {
+		t.Fatalf("NewAppTokenSource() error: %v", err)
+	}
+	source.client, source.baseURL = srv.Client(), srv.URL
+	source.now = func() time.Time { return now }
+
+	token := func() string {
+		t.Helper()
+		got, err := source.Token(t.Context(), "", "")
+		if err != nil {
+			t.Fatalf("Token() error: %v", err)
+		}
+		return got
+	}
+
+	// The only installation is looked up, and its token cached
+	if got := token(); got != "ghs_1" || source.installations[""] != 42 {
+		t.Errorf("Token() = %q for installation %d, want ghs_1 for 42", got, source.installations[""])
+	}
+	now = now.Add(50 * time.Minute)
+	if got := token(); got != "ghs_1" {
+		t.Errorf("Token() 50 minutes later = %q, want the cached ghs_1", got)
+	}
+	// Close to expiry, a new token is minted
+	now = now.Add(6 * time.Minute)
+	api.now = now
+	if got := token(); got != "ghs_2" {
+		t.Errorf("Token() near expiry = %q, want a new ghs_2", got)
+	}
+
+	// A rejected token is forgotten, and a new one minted
+	source.Forget("ghs_1") // Not current: no effect
+	if got := token(); got != "ghs_2" {
+		t.Errorf("Token() after forgetting an old token = %q, want ghs_2", got)
+	}
+	source.Forget("ghs_2")
+	if got := token(); got != "ghs_3" {
+		t.Errorf("Token() after Forget = %q, want ghs_3", got)
+	}
+
+	// If refreshing fails, the current token is used until it expires
+	api.fail = true
+	now = now.Add(58 * time.Minute)
+	api.now = now
+	if got := token(); got != "ghs_3" {
+		t.Errorf("Token() when refresh fails = %q, want the still-valid ghs_3", got)
+	}
+	now = now.Add(3 * time.Minute)
+	api.now = now
+	if got, err := source.Token(t.Context(), "", ""); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Token() after expiry with refresh failing = %q, %v; want a 401 error", got, err)
+	}
+	if api.minted != 3 {
+		t.Errorf("minted %d tokens, want 3", api.minted)
+	}
+
+	// Without a configured installation, the App must have exactly one
+	api.fail, api.installations = false, `[{"id":42},{"id":43}]`
+	source.Forget("ghs_3")
+	if _, err := source.Token(t.Context(), "", ""); err == nil || !strings.Contains(err.Error(), "2 installations") {
+		t.Errorf("Token() with two installations error = %v, want one asking for the installation ID", err)
+	}
+	// but its credentials check out
+	if err := source.Check(t.Context()); err != nil {
+		t.Errorf("Check() with two installations error = %v, want nil", err)
+	}
+}
+
+func TestAppTokenSourcePerOwner(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	api := &fakeAppAPI{t: t, key: &key.PublicKey, now: now, installations: `[{"id":42},{"id":7},{"id":8}]`}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	newSource := func(installationID int64) *AppTokenSource {
+		t.Helper()
+		source, err := NewAppTokenSource("123", keyPEM, installationID, "", nil)
+		if err != nil {
+			t.Fatalf("NewAppTokenSource() error: %v", err)
+		}
+		source.client, source.baseURL = srv.Client(), srv.URL
+		source.now = func() time.Time { return now }
+		return source
+	}
+	source := newSource(0)
+
+	for _, tc := range []struct {
+		owner, repo string
+		want        string
+		id          int64
+	}{
+		{owner: "acme", repo: "widgets", want: "ghs_1", id: 7},
+		{owner: "ACME", want: "ghs_1", id: 7}, // Owners are looked up once, case-insensitively
+		{owner: "bob", want: "ghs_2", id: 8},  // A user's installation, after the org lookup 404s
+		{owner: "acme", repo: "gadgets", want: "ghs_1", id: 7},
+	} {
+		got, err := source.Token(t.Context(), tc.owner, tc.repo)
+		if err != nil || got != tc.want || source.installations[strings.ToLower(tc.owner)] != tc.id {
+			t.Errorf("Token(%q, %q) = %q, %v for installation %d; want %s for %d",
+				tc.owner, tc.repo, got, err, source.installations[strings.ToLower(tc.owner)], tc.want, tc.id)
+		}
+	}
+	if api.lookups != 2 || api.minted != 2 {
+		t.Errorf("looked up %d installations and minted %d tokens, want 2 and 2", api.lookups, api.minted)
+	}
+
+	// Forgetting one installation's token leaves the other's cached
+	source.Forget("ghs_1")
+	if got, err := source.Token(t.Context(), "bob", ""); err != nil || got != "ghs_2" {
+		t.Errorf("Token(bob) after forgetting acme's token = %q, %v; want ghs_2", got, err)
+	}
+	if got, err := source.Token(t.Context(), "acme", ""); err != nil || got != "ghs_3" {
+		t.Errorf("Token(acme) after Forget = %q, %v; want ghs_3", got, err)
+	}
+
+	if _, err := source.Token(t.Context(), "nobody", "repo"); err == nil || !strings.Contains(err.Error(), "not installed for nobody") {
+		t.Errorf("Token(nobody) error = %v, want one saying the App isn't installed", err)
+	}
+
+	// A configured installation is used for every owner, without lookups
+	configured := newSource(42)
+	if got, err := configured.Token(t.Context(), "bob", ""); err != nil || got != "ghs_4" || len(configured.installations) != 0 {
+		t.Errorf("Token(bob) with installation 42 configured = %q, %v, looked up %v; want ghs_4", got, err, configured.installations)
+	}
+}
+
+func TestNewAppTokenSourceErrors(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})
+	if _, err := NewAppTokenSource("123", keyPEM, 7, "", nil); err != nil {
+		t.Errorf("NewAppTokenSource() with a PKCS #8 key error: %v", err)
+	}
+	source, err := NewAppTokenSource("123", keyPEM, 7, "GitHub.MyCorp.com", nil)
+	if err != nil || source.baseURL != "https://github.mycorp.com/api/v3" {
+		t.Errorf("NewAppTokenSource() for an Enterprise host = %v, %v; want the host's API", source, err)
+	}
+
+	for name, tc := range map[string]struct {
+		appID          string
+		key            []byte
+		installationID int64
+		host           string
+	}{
+		"no app ID":             {appID: " ", key: keyPEM},
+		"not PEM":               {appID: "123", key: []byte("not a key")},
+		"not a key":             {appID: "123", key: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("junk")})},
+		"negative installation": {appID: "123", key: keyPEM, installationID: -1},
+		"host with a scheme":    {appID: "123", key: keyPEM, host: "https://github.mycorp.com"},
+	} {
+		if _, err := NewAppTokenSource(tc.appID, tc.key, tc.installationID, tc.host, nil); err == nil {
+			t.Errorf("%s: NewAppTokenSource() succeeded, want an error", name)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// commentsPerPage is the page size used when searching a PR's comments for a sticky comment.
+const commentsPerPage = 100
+
+// commentUser is the author of an issue comment.
+type commentUser struct {
+	Login string `json:"login"`
+	Type  string `json:"type"`
+}
+
+// issueComment is the subset of a GitHub issue comment needed to find and update sticky comments.
+type issueComment struct {
+	Body    string      `json:"body"`
+	HTMLURL string      `json:"html_url"`
+	User    commentUser `json:"user"`
+	ID      int64       `json:"id"`
+}
+
+// commentStatusError is a non-2xx response from the comment API.
+type commentStatusError struct {
+	Method     string
+	URL        string
+	Message    string
+	StatusCode int
+}
+
+func (e *commentStatusError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status code %d: %s", e.Method, e.URL, e.StatusCode, e.Message)
+}
+
+// UpsertPRComment creates a comment on a pull request, or updates the existing comment whose
+// body contains marker, so repeated runs keep a single sticky comment. The marker should be
+// unique to the caller, e.g. a hidden HTML comment, and should be included in body. Only
+// comments authored by the token's user are updated, so a marker quoted by someone else is
+// left alone. It returns the comment's web URL and whether an existing comment was updated.
+// API calls are retried as api configures (nil for the defaults).
+func UpsertPRComment(ctx context.Context, prURL, token, marker, body string, api *APIOptions) (commentURL string, updated bool, err error) {
+	owner, repo, number, err := parsePRURL(ctx, prURL)
+	if err != nil {
+		return "", false, err
+	}
+	return upsertComment(ctx, apiHTTPClient(api), APIBaseURL(ctx), owner, repo, number, token, marker, body)
+}
+
+// upsertComment updates the token user's comment containing marker on issue number of
+// owner/repo, using the REST API at apiURL, or creates a new comment if there is none.
+func upsertComment(ctx context.Context, client *http.Client, apiURL, owner, repo string, number int, token, marker, body string) (commentURL string, updated bool, err error) {
+	repoURL := fmt.Sprintf("%s/repos/%s/%s", apiURL, owner, repo)
+	issueURL := fmt.Sprintf("%s/issues/%d", repoURL, number)
+	payload, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to marshal comment: %w", err)
+	}
+
+	login, err := tokenLogin(ctx, client, apiURL, token)
+	if err != nil {
+		return "", false, err
+	}
+	existing, err := findComment(ctx, client, issueURL, token, marker, login)
+	if err != nil {
+		return "", false, err
+	}
+
+	method, target := http.MethodPost, issueURL+"/comments"
+	if existing != nil {
+		// Comments are edited by ID, not through the issue
+		method, target = http.MethodPatch, fmt.Sprintf("%s/issues/comments/%d", repoURL, existing.ID)
+	}
+
+	var comment issueComment
+	if err := doCommentRequest(ctx, client, method, target, token, payload, &comment); err != nil {
+		return "", false, err
+	}
+	slog.Info("Posted PR comment", "method", method, "url", comment.HTMLURL)
+	return comment.HTMLURL, existing != nil, nil
+}
+
+// tokenLogin returns the login of the token's user. GitHub App installation tokens, such as
+// the GITHUB_TOKEN of GitHub Actions, have no user and get a 403; for them it returns "".
+func tokenLogin(ctx context.Context, client *http.Client, apiURL, token string) (string, error) {
+	var user commentUser
+	err := doCommentRequest(ctx, client, http.MethodGet, apiURL+"/user", token, nil, &user)
+	var statusErr *commentStatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusForbidden {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the token's user: %w", err)
+	}
+	return user.Login, nil
+}
+
+// findComment returns the first comment on the issue whose body contains marker and whose
+// author is login, or nil. Without a login (an installation token), the comment must be
+// authored by a bot, as every comment posted with such a token is.
+func findComment(ctx context.Context, client *http.Client, issueURL, token, marker, login string) (*issueComment, error) {
+	for page := 1; ; page++ {
+		var comments []issueComment
+		listURL := fmt.Sprintf("%s/comments?per_page=%d&page=%d", issueURL, commentsPerPage, page)
+		if err := doCommentRequest(ctx, client, http.MethodGet, listURL, token, nil, &comments); err != nil {
+			return nil, err
+		}
+		for i := range comments {
+			author := comments[i].User
+			ours := author.Type == "Bot"
+			if login != "" {
+				ours = strings.EqualFold(author.Login, login)
+			}
+			if ours && strings.Contains(comments[i].Body, marker) {
+				return &comments[i], nil
+			}
+		}
+		if len(comments) < commentsPerPage {
+			return nil, nil
+		}
+	}
+}
+
+// doCommentRequest sends a REST API request with an optional JSON payload and decodes the response into out.
+func doCommentRequest(ctx context.Context, client *http.Client, method, url, token string, payload []byte, out any) error {
+	var body io.Reader = http.NoBody
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // best effort close
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxPeekBytes)) //nolint:errcheck // message is best effort
+		return &commentStatusError{Method: method, URL: url, StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeCommentServer serves the user and issue comment endpoints used by upsertComment, holding
+// comments in memory and recording each request as "METHOD path". Without a login, the token
+// is an installation token: /user is forbidden and comments are posted by a bot.
+type fakeCommentServer struct {
+	login    string
+	requests []string
+	comments []issueComment
+}
+
+// author returns the user that comments posted with the token are attributed to.
+func (f *fakeCommentServer) author() commentUser {
+	if f.login == "" {
+		return commentUser{Login: "github-actions[bot]", Type: "Bot"}
+	}
+	return commentUser{Login: f.login, Type: "User"}
+}
+
+func (f *fakeCommentServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	if r.Header.Get("Authorization") != "Bearer token" {
+		http.Error(w, "bad credentials", http.StatusUnauthorized)
+		return
+	}
+
+	var in struct{ Body string }
+	if r.Method != http.MethodGet {
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/user":
+		if f.login == "" {
+			http.Error(w, `{"message":"Resource not accessible by integration"}`, http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.author()) //nolint:errcheck // test server
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/o/r/issues/7/comments":
+		var page []issueComment
+		if r.URL.Query().Get("page") == "1" {
+			page = f.comments
+		}
+		_ = json.NewEncoder(w).Encode(page) //nolint:errcheck // test server
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/o/r/issues/7/comments":
+		c := issueComment{ID: int64(len(f.comments) + 1), Body: in.Body, User: f.author()}
+		c.HTMLURL = fmt.Sprintf("https://github.com/o/r/pull/7#issuecomment-%d", c.ID)
+		f.comments = append(f.comments, c)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(c) //nolint:errcheck // test server
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/repos/o/r/issues/comments/"):
+		for i := range f.comments {
+			if r.URL.Path == fmt.Sprintf("/repos/o/r/issues/comments/%d", f.comments[i].ID) {
+				f.comments[i].Body = in.Body
+				_ = json.NewEncoder(w).Encode(f.comments[i]) //nolint:errcheck // test server
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestUpsertComment(t *testing.T) {
+	for name, login := range map[string]string{"user token": "prcost-ci", "installation token": ""} {
+		t.Run(name, func(t *testing.T) {
+			// Someone else quoting the marker must not have their comment taken over
+			alice := commentUser{Login: "alice", Type: "User"}
+			fake := &fakeCommentServer{login: login, comments: []issueComment{
+				{ID: 1, Body: "LGTM", User: alice},
+				{ID: 2, Body: "Why does <!-- test-marker --> show up?", User: alice},
+			}}
+			srv := httptest.NewServer(fake)
+			defer srv.Close()
+			ctx := t.Context()
+			const marker = "<!-- test-marker -->"
+
+			// First run creates a comment alongside the others
+			url, updated, err := upsertComment(ctx, srv.Client(), srv.URL, "o", "r", 7, "token", marker, marker+"\ncost: $100")
+			if err != nil {
+				t.Fatalf("first upsert: %v", err)
+			}
+			if updated || len(fake.comments) != 3 || !strings.HasSuffix(url, "#issuecomment-3") {
+				t.Fatalf("first upsert: url=%q updated=%v comments=%+v, want a new comment 3", url, updated, fake.comments)
+			}
+
+			// Second run edits that comment instead of adding another
+			url, updated, err = upsertComment(ctx, srv.Client(), srv.URL, "o", "r", 7, "token", marker, marker+"\ncost: $200")
+			if err != nil {
+				t.Fatalf("second upsert: %v", err)
+			}
+			if !updated || len(fake.comments) != 3 || !strings.HasSuffix(url, "#issuecomment-3") {
+				t.Fatalf("second upsert: url=%q updated=%v comments=%+v, want comment 3 updated", url, updated, fake.comments)
+			}
+			if fake.comments[0].Body != "LGTM" || !strings.HasPrefix(fake.comments[1].Body, "Why") || fake.comments[2].Body != marker+"\ncost: $200" {
+				t.Errorf("comments = %+v, want only our marked comment updated", fake.comments)
+			}
+			if last := fake.requests[len(fake.requests)-1]; last != "PATCH /repos/o/r/issues/comments/3" {
+				t.Errorf("last request = %q, want PATCH of comment 3", last)
+			}
+
+			// API errors are returned, not swallowed
+			if _, _, err := upsertComment(ctx, srv.Client(), srv.URL, "o", "r", 7, "wrong", marker, "x"); err == nil || !strings.Contains(err.Error(), "401") {
+				t.Errorf("bad token error = %v, want a 401 error", err)
+			}
+		})
+	}
+}
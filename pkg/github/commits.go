@@ -0,0 +1,138 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/codeGROOVE-dev/prx/pkg/prx"
+)
+
+// prxMessageLimit is the length prx truncates commit messages (event descriptions) to.
+const prxMessageLimit = 256
+
+// commitMessagesQuery fetches the full messages of a PR's commits.
+const commitMessagesQuery = `query($owner: String!, $name: String!, $number: Int!, $cursor: String) {
+	repository(owner: $owner, name: $name) {
+		pullRequest(number: $number) {
+			commits(first: 100, after: $cursor) {
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+				nodes {
+					commit {
+						oid
+						message
+					}
+				}
+			}
+		}
+	}
+}`
+
+// fullCommitMessages returns the full messages of a PR's commits by SHA when prx truncated any
+// of them, so trailers at the end of long messages (like Co-authored-by) aren't lost. It returns
+// nil when no message was truncated or the messages can't be fetched; callers then fall back to
+// prx's event descriptions. ok is false if the messages couldn't be fetched.
+func fullCommitMessages(ctx context.Context, client *http.Client, owner, repo string, number int, token string, events []prx.Event) (messages map[string]string, ok bool) {
+	truncated := false
+	for i := range events {
+		if events[i].Kind == "commit" && len(events[i].Description) >= prxMessageLimit {
+			truncated = true
+			break
+		}
+	}
+	if !truncated {
+		return nil, true
+	}
+	messages, err := fetchCommitMessages(ctx, client, GraphQLURL(ctx), owner, repo, number, token)
+	if err != nil {
+		slog.Warn("Failed to fetch full commit messages, using truncated ones",
+			"owner", owner, "repo", repo, "pr", number, "error", err)
+		return nil, false
+	}
+	return messages, true
+}
+
+// fetchCommitMessages fetches the messages of a PR's commits by SHA from the GraphQL API at graphqlURL.
+func fetchCommitMessages(ctx context.Context, client *http.Client, graphqlURL, owner, repo string, number int, token string) (map[string]string, error) {
+	messages := make(map[string]string)
+	var cursor *string
+	for {
+		variables := map[string]any{"owner": owner, "name": repo, "number": number}
+		if cursor != nil {
+			variables["cursor"] = *cursor
+		}
+		body, err := json.Marshal(map[string]any{"query": commitMessagesQuery, "variables": variables})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal query: %w", err)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, graphqlURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+
+		//nolint:govet // fieldalignment: anonymous GraphQL response struct
+		var result struct {
+			Errors []struct {
+				Message string
+			}
+			Data struct {
+				Repository struct {
+					PullRequest struct {
+						Commits struct {
+							PageInfo struct {
+								HasNextPage bool
+								EndCursor   string
+							}
+							Nodes []struct {
+								Commit struct {
+									OID     string `json:"oid"`
+									Message string
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+		if err := doGraphQL(client, req, &result); err != nil {
+			return nil, err
+		}
+		if len(result.Errors) > 0 {
+			return nil, fmt.Errorf("GraphQL error: %s", result.Errors[0].Message)
+		}
+
+		commits := result.Data.Repository.PullRequest.Commits
+		for _, node := range commits.Nodes {
+			messages[node.Commit.OID] = node.Commit.Message
+		}
+		if !commits.PageInfo.HasNextPage {
+			return messages, nil
+		}
+		cursor = &commits.PageInfo.EndCursor
+	}
+}
+
+// doGraphQL sends a GraphQL request and decodes its response into out.
+func doGraphQL(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // best effort close
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
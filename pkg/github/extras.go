@@ -0,0 +1,70 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+// prExtras is the PR data fetched alongside prx's with separate GraphQL requests: the changed
+// files and the full messages of truncated commits. It's cached next to prx's cache, so a PR
+// served from prx's cache needs no API calls at all.
+type prExtras struct {
+	UpdatedAt       time.Time         `json:"updated_at"` // The PR's updatedAt when fetched
+	Files           []string          `json:"files"`
+	GeneratedLines  int               `json:"generated_lines"`
+	LinesByCategory map[string]int    `json:"lines_by_category,omitempty"`
+	CommitMessages  map[string]string `json:"commit_messages,omitempty"`
+}
+
+// prExtrasPath returns where a PR's extras are cached under cacheDir.
+func prExtrasPath(ctx context.Context, cacheDir, owner, repo string, number int) string {
+	return filepath.Join(cacheDir, "extras", fmt.Sprintf("%s_%s_%s_%d.json", Host(ctx), owner, repo, number))
+}
+
+// loadPRExtras loads the extras cached at path for the PR as of updatedAt. It reports false if
+// there are none or they were fetched for a different version of the PR.
+func loadPRExtras(path string, updatedAt time.Time) (prExtras, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return prExtras{}, false
+	}
+	var extras prExtras
+	if err := json.Unmarshal(b, &extras); err != nil {
+		slog.Debug("Ignoring unreadable cached PR extras", "path", path, "error", err)
+		return prExtras{}, false
+	}
+	if !extras.UpdatedAt.Equal(updatedAt) {
+		return prExtras{}, false
+	}
+	return extras, true
+}
+
+// savePRExtras caches extras at path. Failures are logged; the extras are then fetched again.
+func savePRExtras(path string, extras prExtras) {
+	b, err := json.Marshal(extras)
+	if err != nil {
+		slog.Warn("Failed to encode PR extras", "error", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		slog.Warn("Failed to create PR extras cache directory", "error", err)
+		return
+	}
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		slog.Warn("Failed to cache PR extras", "path", path, "error", err)
+	}
+}
+
+// apply sets data's files and the additions derived from them.
+func (e *prExtras) apply(data *cost.PRData) {
+	data.Files = e.Files
+	data.GeneratedLines = e.GeneratedLines
+	data.LinesByCategory = e.LinesByCategory
+}
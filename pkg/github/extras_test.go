@@ -0,0 +1,49 @@
+package github
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+func TestPRExtrasCache(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	path := prExtrasPath(ctx, dir, "o", "r", 7)
+	if other := prExtrasPath(WithHost(ctx, "github.mycorp.com"), dir, "o", "r", 7); other == path {
+		t.Errorf("prExtrasPath() = %q for both hosts, want them kept apart", path)
+	}
+	if filepath.Dir(filepath.Dir(path)) != dir {
+		t.Errorf("prExtrasPath() = %q, want it under %q", path, dir)
+	}
+
+	updatedAt := time.Date(2025, 3, 4, 15, 0, 0, 0, time.UTC)
+	if _, ok := loadPRExtras(path, updatedAt); ok {
+		t.Fatal("loadPRExtras() found extras before any were saved")
+	}
+	savePRExtras(path, prExtras{
+		UpdatedAt: updatedAt, Files: []string{"cache.go", "api.pb.go"}, GeneratedLines: 1,
+		LinesByCategory: map[string]int{"code": 2}, CommitMessages: map[string]string{"abc": "full message"},
+	})
+
+	extras, ok := loadPRExtras(path, updatedAt)
+	if !ok {
+		t.Fatal("loadPRExtras() found no extras after saving them")
+	}
+	var data cost.PRData
+	extras.apply(&data)
+	if !slices.Equal(data.Files, []string{"cache.go", "api.pb.go"}) || data.GeneratedLines != 1 || data.LinesByCategory["code"] != 2 {
+		t.Errorf("apply() set files %v, generated lines %d, categories %v", data.Files, data.GeneratedLines, data.LinesByCategory)
+	}
+	if extras.CommitMessages["abc"] != "full message" {
+		t.Errorf("CommitMessages = %v, want the saved messages", extras.CommitMessages)
+	}
+
+	// Extras fetched for an older version of the PR are stale
+	if _, ok := loadPRExtras(path, updatedAt.Add(time.Hour)); ok {
+		t.Error("loadPRExtras() returned extras saved for a different updatedAt")
+	}
+}
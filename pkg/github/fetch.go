@@ -3,13 +3,18 @@ package github
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/codeGROOVE-dev/prcost/pkg/cost"
@@ -25,15 +30,31 @@ import (
 // Returns:
 //   - cost.PRData with all information needed for cost calculation
 func PRDataFromPRX(prData *prx.PullRequestData) cost.PRData {
+	return prDataFromPRX(prData, nil)
+}
+
+// prDataFromPRX is PRDataFromPRX, reading commit messages from messages (by SHA) where they
+// were fetched in full, and from prx's truncated event descriptions otherwise.
+func prDataFromPRX(prData *prx.PullRequestData, messages map[string]string) cost.PRData {
 	pr := prData.PullRequest
 
 	// Extract all human events with timestamps (exclude bots)
-	events := extractParticipantEvents(prData.Events)
+	events := extractParticipantEvents(prData.Events, messages)
 
-	// Handle ClosedAt pointer - use zero time if nil
+	var mergedAt time.Time
+	if pr.MergedAt != nil {
+		mergedAt = *pr.MergedAt
+	}
+	// prx omits ClosedAt for some PRs; without it a long-merged PR would look open until now.
+	// Infer it from all events, before bots are filtered out, since merge bots often close PRs.
 	var closedAt time.Time
-	if pr.ClosedAt != nil {
+	switch {
+	case pr.ClosedAt != nil:
 		closedAt = *pr.ClosedAt
+	case !mergedAt.IsZero():
+		closedAt = mergedAt
+	default:
+		closedAt = closedAtFromEvents(prData.Events)
 	}
 
 	// Fallback bot detection: if prx didn't mark it as a bot, check common bot names
@@ -51,12 +72,18 @@ func PRDataFromPRX(prData *prx.PullRequestData) cost.PRData {
 		LinesAdded:   pr.Additions,
 		LinesDeleted: pr.Deletions,
 		Author:       pr.Author,
+		Title:        pr.Title,
+		Labels:       pr.Labels,
 		AuthorBot:    authorBot,
 		Events:       events,
 		CreatedAt:    pr.CreatedAt,
 		ClosedAt:     closedAt,
 		Merged:       pr.Merged,
+		MergedAt:     mergedAt,
+		Draft:        pr.Draft,
 		State:        pr.State,
+
+		AuthorWriteAccess: pr.AuthorWriteAccess,
 	}
 
 	slog.Debug("Converted PRX data to cost.PRData",
@@ -71,6 +98,58 @@ func PRDataFromPRX(prData *prx.PullRequestData) cost.PRData {
 	return data
 }
 
+// closedAtFromEvents returns the time of the latest merge or close event (prx emits both the
+// PR's own pr_merged or pr_closed and the timeline's merged or closed), or the zero time if
+// there is none or the PR was reopened after it.
+func closedAtFromEvents(events []prx.Event) time.Time {
+	var closedAt, reopenedAt time.Time
+	for i := range events {
+		switch events[i].Kind {
+		case prx.EventKindPRMerged, "pr_closed", "merged", prx.EventKindClosed:
+			if events[i].Timestamp.After(closedAt) {
+				closedAt = events[i].Timestamp
+			}
+		case prx.EventKindReopened:
+			if events[i].Timestamp.After(reopenedAt) {
+				reopenedAt = events[i].Timestamp
+			}
+		default:
+		}
+	}
+	if !closedAt.After(reopenedAt) {
+		return time.Time{}
+	}
+	return closedAt
+}
+
+// ParsePRXJSON reads a prx-format JSON dump of a pull request (as written by prx or saved
+// from FetchPRData's cache) and converts it to cost.PRData, without any network access.
+// It returns an error naming the missing fields if the dump lacks the PR author or creation
+// time, or if the resulting data fails cost.Validate.
+func ParsePRXJSON(r io.Reader) (cost.PRData, error) {
+	var prData prx.PullRequestData
+	if err := json.NewDecoder(r).Decode(&prData); err != nil {
+		return cost.PRData{}, fmt.Errorf("invalid prx JSON: %w", err)
+	}
+
+	var missing []string
+	if prData.PullRequest.Author == "" {
+		missing = append(missing, "pull_request.author")
+	}
+	if prData.PullRequest.CreatedAt.IsZero() {
+		missing = append(missing, "pull_request.created_at")
+	}
+	if len(missing) > 0 {
+		return cost.PRData{}, fmt.Errorf("prx JSON is missing required fields: %s", strings.Join(missing, ", "))
+	}
+
+	data := PRDataFromPRX(&prData)
+	if err := cost.Validate(data); err != nil {
+		return cost.PRData{}, fmt.Errorf("invalid PR data in prx JSON: %w", err)
+	}
+	return data, nil
+}
+
 // FetchPRData retrieves pull request information from GitHub and converts it
 // to the format needed for cost calculation.
 //
@@ -88,11 +167,18 @@ func PRDataFromPRX(prData *prx.PullRequestData) cost.PRData {
 // Returns:
 //   - cost.PRData with all information needed for cost calculation
 func FetchPRData(ctx context.Context, prURL string, token string, updatedAt time.Time) (cost.PRData, error) {
+	data, _, err := fetchPRData(ctx, prURL, token, updatedAt, nil)
+	return data, err
+}
+
+// fetchPRData is FetchPRData with API calls made as api configures, also reporting whether the
+// PR was served from the disk cache without any GitHub API requests.
+func fetchPRData(ctx context.Context, prURL string, token string, updatedAt time.Time, api *APIOptions) (data cost.PRData, cached bool, err error) {
 	// Parse the PR URL to extract owner, repo, and PR number
-	owner, repo, number, err := parsePRURL(prURL)
+	owner, repo, number, err := parsePRURL(ctx, prURL)
 	if err != nil {
 		slog.Error("Failed to parse PR URL", "url", prURL, "error", err)
-		return cost.PRData{}, fmt.Errorf("invalid PR URL: %w", err)
+		return cost.PRData{}, false, fmt.Errorf("invalid PR URL: %w", err)
 	}
 
 	slog.Debug("Parsed PR URL", "owner", owner, "repo", repo, "number", number)
@@ -102,35 +188,41 @@ func FetchPRData(ctx context.Context, prURL string, token string, updatedAt time
 	if err != nil {
 		slog.Warn("Failed to get cache directory, using non-cached client", "error", err)
 		// Fallback to non-cached client
-		client := prx.NewClient(token)
+		client := prx.NewClient(token, prxOptions(api)...)
 		prData, err := client.PullRequest(ctx, owner, repo, number)
 		if err != nil {
 			slog.Error("GitHub API call failed", "owner", owner, "repo", repo, "pr", number, "error", err)
-			return cost.PRData{}, fmt.Errorf("failed to fetch PR data: %w", err)
+			return cost.PRData{}, false, fmt.Errorf("failed to fetch PR data: %w", err)
 		}
 		result := PRDataFromPRX(prData)
-		return result, nil
+		setFiles(ctx, apiHTTPClient(api), &result, owner, repo, number, token)
+		return result, false, nil
 	}
 
 	cacheDir := filepath.Join(userCacheDir, "prcost")
 	if err := os.MkdirAll(cacheDir, 0o700); err != nil {
 		slog.Warn("Failed to create cache directory, using non-cached client", "error", err)
 		// Fallback to non-cached client
-		client := prx.NewClient(token)
+		client := prx.NewClient(token, prxOptions(api)...)
 		prData, err := client.PullRequest(ctx, owner, repo, number)
 		if err != nil {
 			slog.Error("GitHub API call failed", "owner", owner, "repo", repo, "pr", number, "error", err)
-			return cost.PRData{}, fmt.Errorf("failed to fetch PR data: %w", err)
+			return cost.PRData{}, false, fmt.Errorf("failed to fetch PR data: %w", err)
 		}
 		result := PRDataFromPRX(prData)
-		return result, nil
+		setFiles(ctx, apiHTTPClient(api), &result, owner, repo, number, token)
+		return result, false, nil
 	}
 
-	// Create prx cache client for disk-based caching
-	client, err := prx.NewCacheClient(token, cacheDir)
+	// Create prx cache client for disk-based caching. Requests are counted to tell cache hits,
+	// which make none, from misses.
+	httpClient := apiHTTPClient(api)
+	counter := &requestCounter{base: httpClient.Transport}
+	httpClient.Transport = counter
+	client, err := prx.NewCacheClient(token, cacheDir, prx.WithHTTPClient(httpClient))
 	if err != nil {
 		slog.Error("Failed to create cache client", "error", err)
-		return cost.PRData{}, fmt.Errorf("failed to create cache client: %w", err)
+		return cost.PRData{}, false, fmt.Errorf("failed to create cache client: %w", err)
 	}
 
 	// Fetch PR data using prx (prx has built-in retry logic and caching)
@@ -139,7 +231,7 @@ func FetchPRData(ctx context.Context, prURL string, token string, updatedAt time
 	prData, err := client.PullRequest(ctx, owner, repo, number, updatedAt)
 	if err != nil {
 		slog.Error("GitHub API call failed", "owner", owner, "repo", repo, "pr", number, "error", err)
-		return cost.PRData{}, fmt.Errorf("failed to fetch PR data: %w", err)
+		return cost.PRData{}, false, fmt.Errorf("failed to fetch PR data: %w", err)
 	}
 
 	slog.Debug("GitHub API call successful",
@@ -148,31 +240,68 @@ func FetchPRData(ctx context.Context, prURL string, token string, updatedAt time
 		"author", prData.PullRequest.Author,
 		"total_events", len(prData.Events))
 
+	// The files and full commit messages take separate requests, so they're cached alongside
+	// prx's data: only a PR served entirely from the cache counts as a hit
+	extrasPath := prExtrasPath(ctx, cacheDir, owner, repo, number)
+	if counter.requests.Load() == 0 {
+		if extras, ok := loadPRExtras(extrasPath, prData.PullRequest.UpdatedAt); ok {
+			result := prDataFromPRX(prData, extras.CommitMessages)
+			extras.apply(&result)
+			return result, true, nil
+		}
+	}
+
 	// Convert to cost.PRData
-	result := PRDataFromPRX(prData)
+	extrasClient := apiHTTPClient(api)
+	messages, messagesOK := fullCommitMessages(ctx, extrasClient, owner, repo, number, token, prData.Events)
+	result := prDataFromPRX(prData, messages)
+	if setFiles(ctx, extrasClient, &result, owner, repo, number, token) && messagesOK {
+		savePRExtras(extrasPath, prExtras{
+			UpdatedAt: prData.PullRequest.UpdatedAt, Files: result.Files, GeneratedLines: result.GeneratedLines,
+			LinesByCategory: result.LinesByCategory, CommitMessages: messages,
+		})
+	}
 	slog.Debug("Converted PR data", "human_events", len(result.Events))
-	return result, nil
+	return result, false, nil
+}
+
+// requestCounter counts the requests made through it.
+type requestCounter struct {
+	base     http.RoundTripper
+	requests atomic.Int64
+}
+
+func (c *requestCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests.Add(1)
+	return c.base.RoundTrip(req)
+}
+
+// prxOptions returns prx client options using the retrying API client (see apiHTTPClient).
+// prx always targets api.github.com, so Enterprise Server calls are rewritten by the HTTP client.
+func prxOptions(api *APIOptions) []prx.Option {
+	return []prx.Option{prx.WithHTTPClient(apiHTTPClient(api))}
 }
 
 // parsePRURL extracts owner, repo, and PR number from a GitHub PR URL.
-// Expected format: https://github.com/owner/repo/pull/123
+// Expected format: https://github.com/owner/repo/pull/123, on ctx's host (see WithHost).
 //
 //nolint:revive // Four return values is simpler than creating a struct wrapper
-func parsePRURL(prURL string) (owner, repo string, number int, err error) {
+func parsePRURL(ctx context.Context, prURL string) (owner, repo string, number int, err error) {
 	// Remove protocol prefix
 	prURL = strings.TrimPrefix(prURL, "https://")
 	prURL = strings.TrimPrefix(prURL, "http://")
 
-	// Remove github.com prefix
-	if !strings.HasPrefix(prURL, "github.com/") {
-		return "", "", 0, errors.New("URL must be from github.com")
+	// Remove host prefix
+	h := Host(ctx)
+	if !strings.HasPrefix(prURL, h+"/") {
+		return "", "", 0, fmt.Errorf("URL must be from %s", h)
 	}
-	prURL = strings.TrimPrefix(prURL, "github.com/")
+	prURL = strings.TrimPrefix(prURL, h+"/")
 
 	// Split by /
 	parts := strings.Split(prURL, "/")
 	if len(parts) < 4 || parts[2] != "pull" {
-		return "", "", 0, errors.New("expected format: https://github.com/owner/repo/pull/123")
+		return "", "", 0, fmt.Errorf("expected format: https://%s/owner/repo/pull/123", h)
 	}
 
 	number, err = strconv.Atoi(parts[3])
@@ -192,7 +321,9 @@ func parsePRURL(prURL string) (owner, repo string, number int, err error) {
 // - Commits
 // - Force pushes
 // - etc.
-func extractParticipantEvents(events []prx.Event) []cost.ParticipantEvent {
+//
+// Commit messages are read from messages by SHA when present (see fullCommitMessages).
+func extractParticipantEvents(events []prx.Event, messages map[string]string) []cost.ParticipantEvent {
 	var participantEvents []cost.ParticipantEvent
 
 	for i := range events {
@@ -216,12 +347,106 @@ func extractParticipantEvents(events []prx.Event) []cost.ParticipantEvent {
 		}
 
 		// Only include human events
-		participantEvents = append(participantEvents, cost.ParticipantEvent{
+		participantEvent := cost.ParticipantEvent{
 			Timestamp: event.Timestamp,
 			Actor:     event.Actor,
 			Kind:      event.Kind,
-		})
+			State:     reviewState(event),
+		}
+		if event.Kind == "commit" {
+			// prx puts the commit SHA in Body and its (truncated) message in Description
+			message, ok := messages[event.Body]
+			if !ok {
+				message = event.Description
+			}
+			participantEvent.MergesBase = isBaseMergeMessage(message)
+			participantEvent.CoAuthors = parseCoAuthors(message, event.Actor)
+		}
+		if event.Kind == "review_requested" || event.Kind == "review_request_removed" {
+			participantEvent.Target = requestedReviewer(event)
+		}
+		participantEvents = append(participantEvents, participantEvent)
 	}
 
 	return participantEvents
 }
+
+// requestedReviewer returns the human a review request event names, or "" for bots and teams.
+// prx names teams by their display name, which is usually not a valid login; a team never
+// responds itself, so it can't be charged for keeping the author waiting.
+func requestedReviewer(event *prx.Event) string {
+	if event.TargetIsBot || IsBot("", event.Target) || ValidateAuthor(event.Target) != nil {
+		return ""
+	}
+	return event.Target
+}
+
+// reviewState returns a review event's outcome ("approved", "changes_requested", "commented"
+// or "dismissed"), or "" for other events.
+func reviewState(event *prx.Event) string {
+	if event.Kind != "review" {
+		return ""
+	}
+	return event.Outcome
+}
+
+// isBaseMergeMessage reports whether a commit message is a merge of another branch into
+// the PR branch, as written by "git merge" or GitHub's "Update branch" button:
+//
+//	Merge branch 'main' into feature
+//	Merge remote-tracking branch 'origin/main'
+func isBaseMergeMessage(message string) bool {
+	subject, _, _ := strings.Cut(message, "\n")
+	return strings.HasPrefix(subject, "Merge branch '") ||
+		strings.HasPrefix(subject, "Merge remote-tracking branch '")
+}
+
+// coAuthorTrailer matches a "Co-authored-by: Name <email>" commit message trailer.
+var coAuthorTrailer = regexp.MustCompile(`(?im)^co-authored-by:[ \t]*(.*?)[ \t]*<([^>]*)>[ \t]*$`)
+
+// parseCoAuthors returns the co-authors named in a commit message's Co-authored-by trailers,
+// excluding the committer and bots. Co-authors are identified by GitHub login when the email
+// is a GitHub noreply address (as GitHub writes for pairing), and by name otherwise; a name
+// that another trailer ties to a login is identified by that login. Logins and names match
+// case-insensitively, and each co-author is listed once.
+func parseCoAuthors(message, committer string) []string {
+	matches := coAuthorTrailer.FindAllStringSubmatch(message, -1)
+	logins := make(map[string]string) // By lowercased name
+	for _, match := range matches {
+		if login := noreplyLogin(match[2]); login != "" && match[1] != "" {
+			logins[strings.ToLower(match[1])] = login
+		}
+	}
+
+	var coAuthors []string
+	for _, match := range matches {
+		name, email := match[1], match[2]
+		login := noreplyLogin(email)
+		if login == "" {
+			login = logins[strings.ToLower(name)]
+		}
+		if login == "" {
+			login = name
+		}
+		if login == "" || strings.EqualFold(login, committer) || strings.EqualFold(name, committer) || IsBot("", login) ||
+			slices.ContainsFunc(coAuthors, func(c string) bool { return strings.EqualFold(c, login) }) {
+			continue
+		}
+		coAuthors = append(coAuthors, login)
+	}
+	return coAuthors
+}
+
+// noreplyLogin extracts the login from a GitHub noreply email address,
+// e.g. "12345+alice@users.noreply.github.com" or "alice@users.noreply.github.com".
+// It returns "" for any other address.
+func noreplyLogin(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || !strings.EqualFold(domain, "users.noreply.github.com") {
+		return ""
+	}
+	if _, login, found := strings.Cut(local, "+"); found {
+		return login
+	}
+	return local
+}
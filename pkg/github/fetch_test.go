@@ -1,8 +1,15 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -66,7 +73,7 @@ func TestParsePRURL(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			owner, repo, number, err := parsePRURL(tt.url)
+			owner, repo, number, err := parsePRURL(t.Context(), tt.url)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("parsePRURL() error = %v, wantErr %v", err, tt.wantErr)
@@ -147,7 +154,7 @@ func TestExtractParticipantEvents(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := extractParticipantEvents(tt.events)
+			result := extractParticipantEvents(tt.events, nil)
 
 			if len(result) != tt.expectedCount {
 				t.Errorf("Expected %d events, got %d", tt.expectedCount, len(result))
@@ -166,6 +173,138 @@ func TestExtractParticipantEvents(t *testing.T) {
 	}
 }
 
+func TestExtractParticipantEventsReviewState(t *testing.T) {
+	now := time.Now()
+	events := []prx.Event{
+		{Timestamp: now, Actor: "bob", Kind: "review", Outcome: "approved"},
+		{Timestamp: now.Add(time.Hour), Actor: "bob", Kind: "check_run", Outcome: "success"},
+	}
+
+	result := extractParticipantEvents(events, nil)
+	if result[0].State != "approved" || result[1].State != "" {
+		t.Errorf("event states = %q, %q, want approved and none for a non-review", result[0].State, result[1].State)
+	}
+}
+
+func TestExtractParticipantEventsReviewRequestTarget(t *testing.T) {
+	now := time.Now()
+	events := []prx.Event{
+		{Timestamp: now, Actor: "alice", Kind: "review_requested", Target: "bob"},
+		{Timestamp: now, Actor: "alice", Kind: "review_requested", Target: "renovate[bot]"},
+		{Timestamp: now, Actor: "alice", Kind: "review_requested", Target: "copilot", TargetIsBot: true},
+		{Timestamp: now, Actor: "alice", Kind: "review_requested", Target: "Platform Team"},
+		{Timestamp: now, Actor: "alice", Kind: "review_request_removed", Target: "bob"},
+		{Timestamp: now, Actor: "alice", Kind: "milestoned", Target: "v1"},
+	}
+
+	result := extractParticipantEvents(events, nil)
+	want := []string{"bob", "", "", "", "bob", ""}
+	for i, w := range want {
+		if result[i].Target != w {
+			t.Errorf("event %d (%s %s) Target = %q, want %q", i, events[i].Kind, events[i].Target, result[i].Target, w)
+		}
+	}
+}
+
+func TestExtractParticipantEventsMergesBase(t *testing.T) {
+	now := time.Now()
+	events := []prx.Event{
+		// prx puts a commit's SHA in Body and its message in Description
+		{Timestamp: now, Actor: "alice", Kind: "commit", Body: "3f2a9c1", Description: "Add payment retries"},
+		{Timestamp: now.Add(time.Hour), Actor: "alice", Kind: "commit", Body: "8d41e07", Description: "Merge branch 'main' into retries\n\nConflicts:\n\tpay.go"},
+		{Timestamp: now.Add(2 * time.Hour), Actor: "alice", Kind: "commit", Body: "c05b6e2", Description: "Merge remote-tracking branch 'origin/main'"},
+		{Timestamp: now.Add(3 * time.Hour), Actor: "bob", Kind: "comment", Body: "Merge branch 'main' first?"},
+	}
+
+	result := extractParticipantEvents(events, nil)
+	want := []bool{false, true, true, false}
+	for i, w := range want {
+		if result[i].MergesBase != w {
+			t.Errorf("event %d MergesBase = %v, want %v", i, result[i].MergesBase, w)
+		}
+	}
+}
+
+func TestParseCoAuthors(t *testing.T) {
+	message := "Pair on payment retries\n\n" +
+		"Co-authored-by: Bob Smith <12345+bob@users.noreply.github.com>\n" +
+		"co-authored-by: Carol <carol@example.com>\n" +
+		"Co-authored-by: Alice <alice@users.noreply.github.com>\n" +
+		"Co-authored-by: dependabot[bot] <49699333+dependabot[bot]@users.noreply.github.com>\n" +
+		"Co-authored-by: Bob Smith <bob@users.noreply.github.com>\n" +
+		"Co-authored-by: bob smith <bob.smith@example.com>\n" +
+		"Co-authored-by: CAROL <carol@work.example.com>\n" +
+		"Co-authored-by: Dave <12+Dave@users.noreply.github.com>\n" +
+		"Co-authored-by: Dave <12+dave@users.noreply.github.com>"
+
+	got := parseCoAuthors(message, "Alice")
+	if want := []string{"bob", "Carol", "Dave"}; !slices.Equal(got, want) {
+		t.Errorf("parseCoAuthors() = %v, want %v", got, want)
+	}
+
+	if got := parseCoAuthors("Fix typo\n\nSigned-off-by: Bob <bob@example.com>", "alice"); got != nil {
+		t.Errorf("parseCoAuthors() without trailers = %v, want nil", got)
+	}
+}
+
+func TestExtractParticipantEventsCoAuthors(t *testing.T) {
+	trailer := "\n\nCo-authored-by: Bob <bob@users.noreply.github.com>"
+	long := "Rework payment retries\n\n" + strings.Repeat("x", prxMessageLimit) + trailer
+
+	// prx puts a commit's SHA in Body and its message, truncated, in Description
+	events := []prx.Event{
+		{Timestamp: time.Now(), Actor: "alice", Kind: "commit", Body: "3f2a9c1", Description: "Pair on retries" + trailer},
+		{Timestamp: time.Now(), Actor: "alice", Kind: "commit", Body: "8d41e07", Description: long[:prxMessageLimit]},
+	}
+
+	result := extractParticipantEvents(events, nil)
+	if !slices.Equal(result[0].CoAuthors, []string{"bob"}) || result[1].CoAuthors != nil {
+		t.Errorf("CoAuthors from descriptions = %v, %v, want [bob], [] (trailer truncated)", result[0].CoAuthors, result[1].CoAuthors)
+	}
+
+	result = extractParticipantEvents(events, map[string]string{"8d41e07": long})
+	if !slices.Equal(result[0].CoAuthors, []string{"bob"}) || !slices.Equal(result[1].CoAuthors, []string{"bob"}) {
+		t.Errorf("CoAuthors with full messages = %v, %v, want [bob], [bob]", result[0].CoAuthors, result[1].CoAuthors)
+	}
+}
+
+func TestFetchCommitMessages(t *testing.T) {
+	var requests []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]any
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		requests = append(requests, body.Variables)
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("Authorization = %q, want the token", r.Header.Get("Authorization"))
+		}
+		page := `{"hasNextPage": true, "endCursor": "c1"}`
+		oid, message := "3f2a9c1", "First"
+		if body.Variables["cursor"] == "c1" {
+			page = `{"hasNextPage": false, "endCursor": ""}`
+			oid, message = "8d41e07", "Second\n\nCo-authored-by: Bob <bob@example.com>"
+		}
+		fmt.Fprintf(w, `{"data": {"repository": {"pullRequest": {"commits": {"pageInfo": %s, "nodes": [{"commit": {"oid": %q, "message": %q}}]}}}}}`,
+			page, oid, message)
+	}))
+	defer srv.Close()
+
+	messages, err := fetchCommitMessages(context.Background(), srv.Client(), srv.URL, "acme", "pay", 7, "tok")
+	if err != nil {
+		t.Fatalf("fetchCommitMessages() error: %v", err)
+	}
+	want := map[string]string{"3f2a9c1": "First", "8d41e07": "Second\n\nCo-authored-by: Bob <bob@example.com>"}
+	if !maps.Equal(messages, want) {
+		t.Errorf("fetchCommitMessages() = %v, want %v", messages, want)
+	}
+	if len(requests) != 2 || requests[0]["number"] != float64(7) || requests[0]["owner"] != "acme" {
+		t.Errorf("requests = %v, want two pages for acme/pay#7", requests)
+	}
+}
+
 func TestPRDataFromPRX(t *testing.T) {
 	now := time.Now()
 	created := now.Add(-24 * time.Hour)
@@ -177,6 +316,8 @@ func TestPRDataFromPRX(t *testing.T) {
 			Deletions:         50,
 			CreatedAt:         created,
 			AuthorWriteAccess: 1, // Has write access
+			Draft:             true,
+			Labels:            []string{"kind/bug", "size/M"},
 		},
 		Events: []prx.Event{
 			{Timestamp: created, Actor: "test-author", Kind: "commit", Bot: false},
@@ -196,10 +337,26 @@ func TestPRDataFromPRX(t *testing.T) {
 		t.Errorf("Expected 100 lines added, got %d", costData.LinesAdded)
 	}
 
+	if costData.LinesDeleted != 50 {
+		t.Errorf("Expected 50 lines deleted, got %d", costData.LinesDeleted)
+	}
+
 	if !costData.CreatedAt.Equal(created) {
 		t.Errorf("Expected created at %v, got %v", created, costData.CreatedAt)
 	}
 
+	if !costData.Draft {
+		t.Error("Expected draft PR to be marked as draft")
+	}
+
+	if costData.AuthorWriteAccess != 1 {
+		t.Errorf("Expected author write access 1, got %d", costData.AuthorWriteAccess)
+	}
+
+	if !slices.Equal(costData.Labels, []string{"kind/bug", "size/M"}) {
+		t.Errorf("Expected labels [kind/bug size/M], got %v", costData.Labels)
+	}
+
 	// Should have 2 events (bot event filtered out)
 	if len(costData.Events) != 2 {
 		t.Errorf("Expected 2 human events, got %d", len(costData.Events))
@@ -214,6 +371,38 @@ func TestPRDataFromPRX(t *testing.T) {
 	}
 }
 
+func TestPRDataFromPRXInfersClosedAt(t *testing.T) {
+	created := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	merged := created.Add(48 * time.Hour)
+	closed := created.Add(24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		pr     prx.PullRequest
+		events []prx.Event
+		want   time.Time
+	}{
+		{"ClosedAt given", prx.PullRequest{ClosedAt: &closed, MergedAt: &merged}, nil, closed},
+		{"MergedAt only", prx.PullRequest{MergedAt: &merged}, nil, merged},
+		{"merged by a bot", prx.PullRequest{}, []prx.Event{
+			{Timestamp: created, Actor: "alice", Kind: prx.EventKindCommit},
+			{Timestamp: merged, Actor: "merge-bot[bot]", Kind: prx.EventKindPRMerged, Bot: true},
+		}, merged},
+		{"reopened", prx.PullRequest{}, []prx.Event{
+			{Timestamp: closed, Actor: "alice", Kind: prx.EventKindClosed},
+			{Timestamp: merged, Actor: "alice", Kind: prx.EventKindReopened},
+		}, time.Time{}},
+		{"open", prx.PullRequest{}, []prx.Event{{Timestamp: created, Actor: "alice", Kind: prx.EventKindCommit}}, time.Time{}},
+	}
+	for _, tt := range tests {
+		tt.pr.Author, tt.pr.CreatedAt = "alice", created
+		got := PRDataFromPRX(&prx.PullRequestData{PullRequest: tt.pr, Events: tt.events})
+		if !got.ClosedAt.Equal(tt.want) {
+			t.Errorf("%s: ClosedAt = %v, want %v", tt.name, got.ClosedAt, tt.want)
+		}
+	}
+}
+
 func TestPRDataFromPRXExternalContributor(t *testing.T) {
 	now := time.Now()
 
@@ -235,22 +424,24 @@ func TestPRDataFromPRXExternalContributor(t *testing.T) {
 	if costData.Author != "external-contributor" {
 		t.Errorf("Expected author 'external-contributor', got '%s'", costData.Author)
 	}
+	if costData.AuthorWriteAccess != -1 {
+		t.Errorf("Expected author write access -1, got %d", costData.AuthorWriteAccess)
+	}
 }
 
 func TestPRDataFromPRXWithRealData(t *testing.T) {
 	// Test with real PR 1891 data
-	data, err := os.ReadFile("../../testdata/pr_1891.json")
+	f, err := os.Open("../../testdata/pr_1891.json")
 	if err != nil {
 		t.Skipf("Skipping real data test: %v", err)
 	}
+	defer f.Close() //nolint:errcheck // read-only test fixture
 
-	var prxData prx.PullRequestData
-	if err := json.Unmarshal(data, &prxData); err != nil {
+	costData, err := ParsePRXJSON(f)
+	if err != nil {
 		t.Fatalf("Failed to parse PR data: %v", err)
 	}
 
-	costData := PRDataFromPRX(&prxData)
-
 	// PR 1891 specific validations
 	if costData.Author != "markusthoemmes" {
 		t.Errorf("Expected author 'markusthoemmes', got '%s'", costData.Author)
@@ -260,6 +451,10 @@ func TestPRDataFromPRXWithRealData(t *testing.T) {
 		t.Errorf("Expected 26 lines added, got %d", costData.LinesAdded)
 	}
 
+	if costData.LinesDeleted != 2 {
+		t.Errorf("Expected 2 lines deleted, got %d", costData.LinesDeleted)
+	}
+
 	// Should have filtered out all bot events
 	for _, event := range costData.Events {
 		if event.Actor == "github" {
@@ -273,7 +468,29 @@ func TestPRDataFromPRXWithRealData(t *testing.T) {
 		t.Errorf("Expected at least 2 human events, got %d", len(costData.Events))
 	}
 
-	t.Logf("PR 1891: %d human events out of %d total events", len(costData.Events), len(prxData.Events))
+	t.Logf("PR 1891: %d human events", len(costData.Events))
+}
+
+func TestParsePRXJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"malformed", `{"pull_request":`, "invalid prx JSON"},
+		{"missing author and created_at", `{"pull_request":{"additions":10}}`, "pull_request.author, pull_request.created_at"},
+		{"missing created_at", `{"pull_request":{"author":"alice"}}`, "pull_request.created_at"},
+		{"closed before created", `{"pull_request":{"author":"alice","created_at":"2025-01-02T00:00:00Z","closed_at":"2025-01-01T00:00:00Z"}}`, "invalid PR data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePRXJSON(strings.NewReader(tt.json))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParsePRXJSON() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
 }
 
 func TestExtractParticipantEventsEdgeCases(t *testing.T) {
@@ -314,7 +531,7 @@ func TestExtractParticipantEventsEdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := extractParticipantEvents(tt.events)
+			result := extractParticipantEvents(tt.events, nil)
 
 			if len(result) != tt.expectedCount {
 				t.Errorf("Expected %d events, got %d", tt.expectedCount, len(result))
@@ -329,18 +546,17 @@ func TestExtractParticipantEventsEdgeCases(t *testing.T) {
 
 func TestPRDataFromPRXWithRealSprinklerData(t *testing.T) {
 	// Load real prx output from sprinkler PR #37
-	data, err := os.ReadFile("../../testdata/sprinkler_pr_37_clean.json")
+	f, err := os.Open("../../testdata/sprinkler_pr_37_clean.json")
 	if err != nil {
 		t.Skipf("Skipping real data test: %v", err)
 	}
+	defer f.Close() //nolint:errcheck // read-only test fixture
 
-	var prxData prx.PullRequestData
-	if err := json.Unmarshal(data, &prxData); err != nil {
+	costData, err := ParsePRXJSON(f)
+	if err != nil {
 		t.Fatalf("Failed to parse PR data: %v", err)
 	}
 
-	costData := PRDataFromPRX(&prxData)
-
 	// Validate sprinkler PR #37 specific data
 	if costData.Author != "tstromberg" {
 		t.Errorf("Expected author 'tstromberg', got '%s'", costData.Author)
@@ -364,5 +580,5 @@ func TestPRDataFromPRXWithRealSprinklerData(t *testing.T) {
 		t.Errorf("Expected 5 human events, got %d", len(costData.Events))
 	}
 
-	t.Logf("Sprinkler PR 37: %d human events out of %d total events", len(costData.Events), len(prxData.Events))
+	t.Logf("Sprinkler PR 37: %d human events", len(costData.Events))
 }
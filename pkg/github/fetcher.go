@@ -2,16 +2,21 @@ package github
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/codeGROOVE-dev/prcost/pkg/cost"
 )
 
-// SimpleFetcher is a PRFetcher that fetches PR data without caching.
+// SimpleFetcher is a PRFetcher that fetches PR data without an in-memory cache.
 // It uses either prx or turnserver based on configuration.
 type SimpleFetcher struct {
 	Token      string
-	DataSource string // "prx" or "turnserver"
+	DataSource string      // "prx" or "turnserver"
+	API        *APIOptions // How GitHub API calls are retried (nil for the defaults)
+
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
 }
 
 // FetchPRData implements the PRFetcher interface from pkg/cost.
@@ -19,5 +24,20 @@ func (f *SimpleFetcher) FetchPRData(ctx context.Context, prURL string, updatedAt
 	if f.DataSource == "turnserver" {
 		return FetchPRDataViaTurnserver(ctx, prURL, f.Token, updatedAt)
 	}
-	return FetchPRData(ctx, prURL, f.Token, updatedAt)
+	data, cached, err := fetchPRData(ctx, prURL, f.Token, updatedAt, f.API)
+	if err != nil {
+		return data, err
+	}
+	if cached {
+		f.cacheHits.Add(1)
+	} else {
+		f.cacheMisses.Add(1)
+	}
+	return data, nil
+}
+
+// CacheStats returns how many successful fetches were served entirely from the disk cache and
+// how many called the GitHub API. Turnserver caches on its side, so its fetches aren't counted.
+func (f *SimpleFetcher) CacheStats() (hits, misses int) {
+	return int(f.cacheHits.Load()), int(f.cacheMisses.Load())
 }
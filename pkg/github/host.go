@@ -0,0 +1,96 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/codeGROOVE-dev/prcost/internal/hostname"
+)
+
+// DefaultHost is the public GitHub host.
+const DefaultHost = "github.com"
+
+// hostKey is the context key for the GitHub host.
+type hostKey struct{}
+
+// ParseHost normalizes a GitHub host, for example "github.mycorp.com" for GitHub Enterprise
+// Server. An empty host is the default. Only bare hostnames are accepted, so PR URLs and
+// tokens can't be redirected to arbitrary servers.
+func ParseHost(h string) (string, error) {
+	host, ok := hostname.Parse(h, DefaultHost)
+	if !ok {
+		return "", errors.New("GitHub host must be a bare hostname like github.mycorp.com")
+	}
+	return host, nil
+}
+
+// WithHost returns a context whose GitHub API calls and PR URLs use host, which should come from
+// ParseHost. Only PR URLs on that host are accepted. Without it, github.com is used, so one
+// process can serve several hosts.
+func WithHost(ctx context.Context, host string) context.Context {
+	return context.WithValue(ctx, hostKey{}, host)
+}
+
+// Host returns the GitHub host configured in ctx by WithHost, or DefaultHost.
+func Host(ctx context.Context) string {
+	if h, ok := ctx.Value(hostKey{}).(string); ok && h != "" {
+		return h
+	}
+	return DefaultHost
+}
+
+// IsEnterprise reports whether ctx has a GitHub Enterprise Server host.
+func IsEnterprise(ctx context.Context) bool {
+	return Host(ctx) != DefaultHost
+}
+
+// APIBaseURL returns the REST API base URL for ctx's host:
+// https://api.github.com for github.com, https://HOST/api/v3 for Enterprise Server.
+func APIBaseURL(ctx context.Context) string {
+	return hostAPIBaseURL(Host(ctx))
+}
+
+// hostAPIBaseURL returns the REST API base URL for host.
+func hostAPIBaseURL(host string) string {
+	if host == DefaultHost {
+		return "https://api.github.com"
+	}
+	return "https://" + host + "/api/v3"
+}
+
+// GraphQLURL returns the GraphQL endpoint for ctx's host.
+func GraphQLURL(ctx context.Context) string {
+	if !IsEnterprise(ctx) {
+		return "https://api.github.com/graphql"
+	}
+	return "https://" + Host(ctx) + "/api/graphql"
+}
+
+// PRURL returns the web URL of a pull request on ctx's host.
+func PRURL(ctx context.Context, owner, repo string, number int) string {
+	return fmt.Sprintf("https://%s/%s/%s/pull/%d", Host(ctx), owner, repo, number)
+}
+
+// enterpriseTransport rewrites requests for api.github.com, which prx always
+// targets, to the Enterprise Server API on the host in the request's context.
+type enterpriseTransport struct {
+	base http.RoundTripper
+}
+
+func (t *enterpriseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := Host(req.Context())
+	if req.URL.Host != "api.github.com" || host == DefaultHost {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.URL.Host = host
+	req.Host = host
+	if req.URL.Path == "/graphql" {
+		req.URL.Path = "/api/graphql"
+	} else {
+		req.URL.Path = "/api/v3" + req.URL.Path
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,109 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestHost(t *testing.T) {
+	ctx := t.Context()
+	if Host(ctx) != DefaultHost || IsEnterprise(ctx) {
+		t.Fatalf("Expected default host %s, got %s", DefaultHost, Host(ctx))
+	}
+	if got := APIBaseURL(ctx); got != "https://api.github.com" {
+		t.Errorf("APIBaseURL() = %q, want https://api.github.com", got)
+	}
+	if got := GraphQLURL(ctx); got != "https://api.github.com/graphql" {
+		t.Errorf("GraphQLURL() = %q, want https://api.github.com/graphql", got)
+	}
+
+	h, err := ParseHost(" GitHub.MyCorp.com ")
+	if err != nil || h != "github.mycorp.com" {
+		t.Fatalf("ParseHost() = %q, %v; want the normalized host", h, err)
+	}
+	ghes := WithHost(ctx, h)
+	if Host(ghes) != "github.mycorp.com" || !IsEnterprise(ghes) {
+		t.Errorf("Expected enterprise host, got %q", Host(ghes))
+	}
+	if got := APIBaseURL(ghes); got != "https://github.mycorp.com/api/v3" {
+		t.Errorf("APIBaseURL() = %q, want https://github.mycorp.com/api/v3", got)
+	}
+	if got := GraphQLURL(ghes); got != "https://github.mycorp.com/api/graphql" {
+		t.Errorf("GraphQLURL() = %q, want https://github.mycorp.com/api/graphql", got)
+	}
+	if got := PRURL(ghes, "o", "r", 7); got != "https://github.mycorp.com/o/r/pull/7" {
+		t.Errorf("PRURL() = %q", got)
+	}
+	// Contexts without a host are unaffected
+	if Host(ctx) != DefaultHost {
+		t.Errorf("WithHost() changed the parent context's host to %q", Host(ctx))
+	}
+
+	if h, err := ParseHost(""); err != nil || h != DefaultHost {
+		t.Errorf("ParseHost(\"\") = %q, %v; want %s", h, err, DefaultHost)
+	}
+	for _, bad := range []string{"https://github.mycorp.com", "github.mycorp.com/path", "host:8443", "user@host"} {
+		if _, err := ParseHost(bad); err == nil {
+			t.Errorf("ParseHost(%q) expected error", bad)
+		}
+	}
+}
+
+func TestParsePRURLEnterprise(t *testing.T) {
+	ctx := WithHost(t.Context(), "github.mycorp.com")
+
+	owner, repo, number, err := parsePRURL(ctx, "https://github.mycorp.com/team/svc/pull/12")
+	if err != nil || owner != "team" || repo != "svc" || number != 12 {
+		t.Errorf("parsePRURL() = %q, %q, %d, %v", owner, repo, number, err)
+	}
+
+	// Hosts other than the configured one are rejected
+	for _, u := range []string{"https://github.com/o/r/pull/1", "https://evil.example.com/o/r/pull/1"} {
+		if _, _, _, err := parsePRURL(ctx, u); err == nil {
+			t.Errorf("parsePRURL(%q) expected error", u)
+		}
+	}
+}
+
+type recordingTransport struct {
+	req *http.Request
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.req = req
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestEnterpriseTransport(t *testing.T) {
+	ghes := WithHost(t.Context(), "github.mycorp.com")
+	tests := []struct {
+		ctx      context.Context
+		url      string
+		wantHost string
+		wantPath string
+	}{
+		{ghes, "https://api.github.com/repos/o/r/pulls/1", "github.mycorp.com", "/api/v3/repos/o/r/pulls/1"},
+		{ghes, "https://api.github.com/graphql", "github.mycorp.com", "/api/graphql"},
+		{ghes, "https://other.example.com/x", "other.example.com", "/x"},
+		// Requests without an Enterprise host in their context go to github.com
+		{t.Context(), "https://api.github.com/repos/o/r/pulls/1", "api.github.com", "/repos/o/r/pulls/1"},
+	}
+
+	for _, tt := range tests {
+		rec := &recordingTransport{}
+		transport := &enterpriseTransport{base: rec}
+		req, err := http.NewRequestWithContext(tt.ctx, http.MethodGet, tt.url, http.NoBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("RoundTrip(%s) error: %v", tt.url, err)
+		}
+		_ = resp.Body.Close() //nolint:errcheck // test response
+		if rec.req.URL.Host != tt.wantHost || rec.req.URL.Path != tt.wantPath {
+			t.Errorf("RoundTrip(%s) sent to %s%s, want %s%s", tt.url, rec.req.URL.Host, rec.req.URL.Path, tt.wantHost, tt.wantPath)
+		}
+	}
+}
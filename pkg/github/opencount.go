@@ -0,0 +1,160 @@
+package github
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// OpenPRCount is the number of open PRs counted across an organization.
+type OpenPRCount struct {
+	Count       int  // Open PRs created more than 24 hours ago
+	FailedRepos int  // Repositories whose count failed; if non-zero, Count is a lower bound
+	Partial     bool // Only repositories with PRs in the analysis window were counted, so Count is a lower bound
+}
+
+// CountOpenPRsAcrossOrg counts open PRs in org with a single CountOpenPRsInOrg query. If that
+// query fails, it falls back to counting each repository seen in prs with CountOpenPRsInRepo,
+// at most concurrency at a time, and reports how many of those counts failed. The fallback
+// misses repositories whose open PRs weren't updated in the window, so its count is Partial.
+// With a non-zero q.Until, the PRs open at the end of a past window are counted.
+//
+// With q.Author, only the author's open PRs count, and a failed query falls back to
+// CountOpenPRs over prs, the author's PRs in the window, so the count is Partial.
+func CountOpenPRsAcrossOrg(ctx context.Context, org string, prs []PRSummary, concurrency int, q PRQuery) OpenPRCount {
+	countOrg := func(ctx context.Context) (int, error) {
+		return CountOpenPRsInOrg(ctx, org, q)
+	}
+	if q.Author != "" {
+		return countAuthorOpenPRs(ctx, org, q.Author, prs, q.Until, countOrg)
+	}
+	return countOpenPRsAcrossOrg(ctx, org, prs, concurrency, countOrg,
+		func(ctx context.Context, r repoRef) (int, error) {
+			return CountOpenPRsInRepo(ctx, r.owner, r.repo, q)
+		})
+}
+
+// countOpenPRsAcrossOrg is CountOpenPRsAcrossOrg with the organization and repository counts
+// done by countOrg and countRepo.
+func countOpenPRsAcrossOrg(ctx context.Context, org string, prs []PRSummary, concurrency int,
+	countOrg func(context.Context) (int, error), countRepo func(context.Context, repoRef) (int, error),
+) OpenPRCount {
+	count, err := countOrg(ctx)
+	if err == nil {
+		return OpenPRCount{Count: count}
+	}
+	repos := uniqueRepos(prs)
+	slog.Warn("Failed to count open PRs in organization, counting per repository",
+		"org", org, "repos", len(repos), "error", err)
+
+	result := countOpenPRsInRepos(ctx, repos, concurrency, countRepo)
+	result.Partial = true
+	return result
+}
+
+// countAuthorOpenPRs is CountOpenPRsAcrossOrg for one author, with the search done by count.
+func countAuthorOpenPRs(ctx context.Context, org, author string, prs []PRSummary, until time.Time,
+	count func(context.Context) (int, error),
+) OpenPRCount {
+	n, err := count(ctx)
+	if err == nil {
+		return OpenPRCount{Count: n}
+	}
+	slog.Warn("Failed to count author's open PRs in organization, counting PRs in the window",
+		"org", org, "author", author, "error", err)
+	return OpenPRCount{Count: CountOpenPRs(prs, until), Partial: true}
+}
+
+// CountOpenPRs counts PRs in prs that are open at until (or now, if it is zero) and were created
+// more than 24 hours before, matching CountOpenPRsInOrg and CountOpenPRsInRepo. Use it when only
+// a filtered pool's open PRs are relevant, such as those touching some paths.
+func CountOpenPRs(prs []PRSummary, until time.Time) int {
+	at := time.Now()
+	if !until.IsZero() && until.Before(at) {
+		at = until
+	}
+	cutoff := at.Add(-24 * time.Hour)
+	count := 0
+	for i := range prs {
+		// PRs closed after until were still open then
+		open := prs[i].State == "OPEN" || prs[i].ClosedAt != nil && prs[i].ClosedAt.After(at)
+		if open && prs[i].CreatedAt.Before(cutoff) {
+			count++
+		}
+	}
+	return count
+}
+
+// repoRef identifies a repository by owner and name.
+type repoRef struct {
+	owner string
+	repo  string
+}
+
+// uniqueRepos returns the distinct repositories of prs, sorted by owner and name.
+func uniqueRepos(prs []PRSummary) []repoRef {
+	seen := make(map[repoRef]bool)
+	var repos []repoRef
+	for i := range prs {
+		r := repoRef{owner: prs[i].Owner, repo: prs[i].Repo}
+		if r.repo == "" || seen[r] {
+			continue
+		}
+		seen[r] = true
+		repos = append(repos, r)
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		if repos[i].owner != repos[j].owner {
+			return repos[i].owner < repos[j].owner
+		}
+		return repos[i].repo < repos[j].repo
+	})
+	return repos
+}
+
+// countOpenPRsInRepos sums count over repos using at most concurrency workers. Failed counts
+// are logged and skipped rather than aborting, so the total degrades to a lower bound.
+func countOpenPRsInRepos(ctx context.Context, repos []repoRef, concurrency int, count func(context.Context, repoRef) (int, error)) OpenPRCount {
+	concurrency = max(concurrency, 1)
+	work := make(chan repoRef)
+	var mu sync.Mutex
+	var result OpenPRCount
+	var wg sync.WaitGroup
+	for range min(concurrency, len(repos)) {
+		wg.Go(func() {
+			for r := range work {
+				n, err := count(ctx, r)
+				mu.Lock()
+				if err != nil {
+					result.FailedRepos++
+				} else {
+					result.Count += n
+				}
+				mu.Unlock()
+				if err != nil {
+					slog.Warn("Failed to count open PRs in repository", "owner", r.owner, "repo", r.repo, "error", err)
+				}
+			}
+		})
+	}
+
+	for i, r := range repos {
+		if ctx.Err() != nil {
+			// Repositories never counted are failures too
+			mu.Lock()
+			result.FailedRepos += len(repos) - i
+			mu.Unlock()
+			break
+		}
+		work <- r
+	}
+	close(work)
+	wg.Wait()
+
+	if result.FailedRepos > 0 {
+		slog.Warn("Open PR count is a lower bound", "open_prs", result.Count, "failed_repos", result.FailedRepos, "repos", len(repos))
+	}
+	return result
+}
@@ -0,0 +1,176 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUniqueRepos(t *testing.T) {
+	prs := []PRSummary{
+		{Owner: "o", Repo: "b"},
+		{Owner: "o", Repo: "a"},
+		{Owner: "o", Repo: "b"},
+		{Owner: "p", Repo: "a"},
+		{Owner: "o"},
+	}
+	got := uniqueRepos(prs)
+	want := []repoRef{{"o", "a"}, {"o", "b"}, {"p", "a"}}
+	if len(got) != len(want) {
+		t.Fatalf("uniqueRepos() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("uniqueRepos()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCountOpenPRsInRepos(t *testing.T) {
+	var repos []repoRef
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		repos = append(repos, repoRef{owner: "o", repo: name})
+	}
+
+	var inFlight, peak atomic.Int32
+	const limit = 3
+	got := countOpenPRsInRepos(t.Context(), repos, limit, func(_ context.Context, r repoRef) (int, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		if r.repo == "c" || r.repo == "h" {
+			return 0, errors.New("rate limited")
+		}
+		return 2, nil
+	})
+
+	if got.Count != 16 || got.FailedRepos != 2 {
+		t.Errorf("countOpenPRsInRepos() = %+v, want 16 open PRs with 2 failed repos", got)
+	}
+	if p := peak.Load(); p > limit {
+		t.Errorf("peak concurrent counts = %d, want <= %d", p, limit)
+	}
+}
+
+func TestCountOpenPRsInReposCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	repos := []repoRef{{"o", "a"}, {"o", "b"}}
+	got := countOpenPRsInRepos(ctx, repos, 4, func(context.Context, repoRef) (int, error) {
+		t.Error("count called after cancellation")
+		return 1, nil
+	})
+	if got.Count != 0 || got.FailedRepos != 2 {
+		t.Errorf("countOpenPRsInRepos(canceled) = %+v, want 0 open PRs with 2 failed repos", got)
+	}
+}
+
+func TestCountOpenPRsAcrossOrg(t *testing.T) {
+	prs := []PRSummary{{Owner: "o", Repo: "a"}, {Owner: "o", Repo: "b"}}
+	countRepo := func(context.Context, repoRef) (int, error) { return 3, nil }
+
+	got := countOpenPRsAcrossOrg(t.Context(), "o", prs, 2, func(context.Context) (int, error) { return 40, nil }, countRepo)
+	if got != (OpenPRCount{Count: 40}) {
+		t.Errorf("countOpenPRsAcrossOrg() = %+v, want the organization's 40, complete", got)
+	}
+
+	// Counting only the repositories seen in the window may miss some, so the count is partial
+	got = countOpenPRsAcrossOrg(t.Context(), "o", prs, 2, func(context.Context) (int, error) { return 0, errors.New("timeout") }, countRepo)
+	if got != (OpenPRCount{Count: 6, Partial: true}) {
+		t.Errorf("countOpenPRsAcrossOrg() fallback = %+v, want 6 open PRs marked partial", got)
+	}
+}
+
+func TestCountAuthorOpenPRs(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	prs := []PRSummary{{State: "OPEN", CreatedAt: old}, {State: "MERGED", CreatedAt: old}}
+
+	// The search also finds the author's open PRs that weren't updated in the window
+	got := countAuthorOpenPRs(t.Context(), "o", "alice", prs, time.Time{}, func(context.Context) (int, error) { return 5, nil })
+	if got != (OpenPRCount{Count: 5}) {
+		t.Errorf("countAuthorOpenPRs() = %+v, want the search's 5, complete", got)
+	}
+
+	got = countAuthorOpenPRs(t.Context(), "o", "alice", prs, time.Time{}, func(context.Context) (int, error) { return 0, errors.New("timeout") })
+	if got != (OpenPRCount{Count: 1, Partial: true}) {
+		t.Errorf("countAuthorOpenPRs() fallback = %+v, want the window's 1 open PR marked partial", got)
+	}
+}
+
+func TestCountOpenPRsMatching(t *testing.T) {
+	var searches []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables struct {
+				SearchQuery string `json:"searchQuery"`
+			} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		searches = append(searches, body.Variables.SearchQuery)
+		_, _ = w.Write([]byte(`{"data":{"search":{"issueCount":7}}}`)) //nolint:errcheck // test
+	}))
+	defer srv.Close()
+
+	got, err := countOpenPRsMatching(t.Context(), srv.Client(), srv.URL, "org:o"+authorQualifier("dependabot[bot]"), time.Time{}, "token")
+	if err != nil || got != 7 {
+		t.Fatalf("countOpenPRsMatching() = %d, %v, want 7", got, err)
+	}
+	if len(searches) != 1 || !strings.HasPrefix(searches[0], "is:pr is:open org:o author:app/dependabot created:<") {
+		t.Errorf("searches = %q, want open PRs in org o by the dependabot app", searches)
+	}
+
+	// PRs open at the end of a past window include those closed since
+	searches = nil
+	until := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	got, err = countOpenPRsMatching(t.Context(), srv.Client(), srv.URL, "org:o", until, "token")
+	if err != nil || got != 14 {
+		t.Fatalf("countOpenPRsMatching(until) = %d, %v, want 14", got, err)
+	}
+	want := []string{
+		"is:pr is:open org:o created:<2025-03-30T00:00:00Z",
+		"is:pr is:closed org:o created:<2025-03-30T00:00:00Z closed:>2025-03-31T00:00:00Z",
+	}
+	if strings.Join(searches, "\n") != strings.Join(want, "\n") {
+		t.Errorf("searches = %q, want %q", searches, want)
+	}
+}
+
+func TestCountOpenPRs(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	prs := []PRSummary{
+		{State: "OPEN", CreatedAt: old},
+		{State: "OPEN", CreatedAt: time.Now()}, // Too new, like the GitHub count queries
+		{State: "MERGED", CreatedAt: old},
+		{State: "OPEN", CreatedAt: old},
+	}
+	if got := CountOpenPRs(prs, time.Time{}); got != 2 {
+		t.Errorf("CountOpenPRs() = %d, want 2", got)
+	}
+
+	// At the end of a past window, PRs closed since were still open, and those opened since weren't
+	until := time.Now().Add(-72 * time.Hour)
+	closed := time.Now().Add(-48 * time.Hour)
+	prs = []PRSummary{
+		{State: "OPEN", CreatedAt: until.Add(-48 * time.Hour)},
+		{State: "MERGED", CreatedAt: until.Add(-48 * time.Hour), ClosedAt: &closed},
+		{State: "OPEN", CreatedAt: old},
+		{State: "CLOSED", CreatedAt: until.Add(-96 * time.Hour), ClosedAt: &until},
+	}
+	if got := CountOpenPRs(prs, until); got != 2 {
+		t.Errorf("CountOpenPRs(until) = %d, want 2", got)
+	}
+}
@@ -0,0 +1,160 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+)
+
+// pageInfo is the paging state of a GraphQL connection.
+type pageInfo struct {
+	EndCursor   string
+	HasNextPage bool
+}
+
+// filesConnection is the first page of a PR's changed files in a PR list query.
+type filesConnection struct {
+	Nodes    []fileNode
+	PageInfo pageInfo
+}
+
+// labelsConnection is the first page of a PR's labels in a PR list query.
+type labelsConnection struct {
+	Nodes    []labelNode
+	PageInfo pageInfo
+}
+
+// prListsQuery fetches the pages of a PR's changed files and labels after the ones a PR list
+// query returned, skipping whichever is already complete.
+const prListsQuery = `query($owner: String!, $name: String!, $number: Int!,
+	$filesCursor: String, $labelsCursor: String, $moreFiles: Boolean!, $moreLabels: Boolean!) {
+	repository(owner: $owner, name: $name) {
+		pullRequest(number: $number) {
+			files(first: 100, after: $filesCursor) @include(if: $moreFiles) {
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+				nodes {
+					path
+					additions
+				}
+			}
+			labels(first: 100, after: $labelsCursor) @include(if: $moreLabels) {
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+				nodes {
+					name
+				}
+			}
+		}
+	}
+}`
+
+// setFilesAndLabels sets pr's files, labels and the additions derived from its files. PR list
+// queries return only the first page of each, so the rest are fetched when there are more;
+// if that fails, pr keeps the first pages and a warning is logged.
+func setFilesAndLabels(ctx context.Context, client *http.Client, pr *PRSummary, files filesConnection, labels labelsConnection, token string) {
+	fileNodes, labelNodes := files.Nodes, labels.Nodes
+	if files.PageInfo.HasNextPage || labels.PageInfo.HasNextPage {
+		moreFiles, moreLabels, err := fetchRemainingLists(ctx, client, GraphQLURL(ctx), pr.Owner, pr.Repo, pr.Number, token, files.PageInfo, labels.PageInfo)
+		if err != nil {
+			slog.Warn("Failed to fetch all files and labels of a PR, using the first pages",
+				"owner", pr.Owner, "repo", pr.Repo, "pr", pr.Number, "files", len(fileNodes), "labels", len(labelNodes), "error", err)
+		} else {
+			fileNodes = append(fileNodes, moreFiles...)
+			labelNodes = append(labelNodes, moreLabels...)
+		}
+	}
+	pr.Files = filePaths(fileNodes)
+	pr.Labels = labelNames(labelNodes)
+	pr.GeneratedAdditions = generatedAdditions(fileNodes)
+	pr.CategoryAdditions = categoryAdditions(fileNodes)
+}
+
+// setFiles sets data's changed files and the lines added to generated files and to each file
+// category, which prx doesn't report, and reports whether they were fetched. If they can't be,
+// data is left without them and a warning is logged.
+func setFiles(ctx context.Context, client *http.Client, data *cost.PRData, owner, repo string, number int, token string) bool {
+	files, _, err := fetchRemainingLists(ctx, client, GraphQLURL(ctx), owner, repo, number, token, pageInfo{HasNextPage: true}, pageInfo{})
+	if err != nil {
+		slog.Warn("Failed to fetch the files of a PR", "owner", owner, "repo", repo, "pr", number, "error", err)
+		return false
+	}
+	data.Files = filePaths(files)
+	data.GeneratedLines = generatedAdditions(files)
+	data.LinesByCategory = categoryAdditions(files)
+	return true
+}
+
+// fetchRemainingLists fetches a PR's changed files and labels after the pages described by
+// files and labels from the GraphQL API at graphqlURL. A page with no end cursor but a next page
+// fetches the list from its start.
+func fetchRemainingLists(ctx context.Context, client *http.Client, graphqlURL, owner, repo string, number int, token string,
+	files, labels pageInfo,
+) ([]fileNode, []labelNode, error) {
+	var fileNodes []fileNode
+	var labelNodes []labelNode
+	for files.HasNextPage || labels.HasNextPage {
+		variables := map[string]any{
+			"owner": owner, "name": repo, "number": number,
+			"moreFiles": files.HasNextPage, "moreLabels": labels.HasNextPage,
+		}
+		// An empty cursor starts from the first page
+		if files.EndCursor != "" {
+			variables["filesCursor"] = files.EndCursor
+		}
+		if labels.EndCursor != "" {
+			variables["labelsCursor"] = labels.EndCursor
+		}
+		body, err := json.Marshal(map[string]any{"query": prListsQuery, "variables": variables})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal query: %w", err)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, graphqlURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+
+		//nolint:govet // fieldalignment: anonymous GraphQL response struct
+		var result struct {
+			Errors []struct {
+				Message string
+			}
+			Data struct {
+				Repository struct {
+					PullRequest struct {
+						Files  filesConnection
+						Labels labelsConnection
+					}
+				}
+			}
+		}
+		if err := doGraphQL(client, req, &result); err != nil {
+			return nil, nil, err
+		}
+		if len(result.Errors) > 0 {
+			return nil, nil, fmt.Errorf("GraphQL error: %s", result.Errors[0].Message)
+		}
+
+		pr := result.Data.Repository.PullRequest
+		if files.HasNextPage {
+			fileNodes = append(fileNodes, pr.Files.Nodes...)
+			files = pr.Files.PageInfo
+		}
+		if labels.HasNextPage {
+			labelNodes = append(labelNodes, pr.Labels.Nodes...)
+			labels = pr.Labels.PageInfo
+		}
+	}
+	return fileNodes, labelNodes, nil
+}
@@ -0,0 +1,56 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestFetchRemainingLists(t *testing.T) {
+	var requests []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]any
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		requests = append(requests, body.Variables)
+		// Files take two more pages; labels one
+		switch body.Variables["filesCursor"] {
+		case "f1":
+			fmt.Fprint(w, `{"data": {"repository": {"pullRequest": {
+				"files": {"pageInfo": {"hasNextPage": true, "endCursor": "f2"}, "nodes": [{"path": "b.go", "additions": 5}]},
+				"labels": {"pageInfo": {"hasNextPage": false}, "nodes": [{"name": "team/pay"}]}}}}}`)
+		case "f2":
+			fmt.Fprint(w, `{"data": {"repository": {"pullRequest": {
+				"files": {"pageInfo": {"hasNextPage": false}, "nodes": [{"path": "vendor/c.go", "additions": 7}]}}}}}`)
+		default:
+			t.Errorf("unexpected files cursor %v", body.Variables["filesCursor"])
+		}
+	}))
+	defer srv.Close()
+
+	files, labels, err := fetchRemainingLists(context.Background(), srv.Client(), srv.URL, "acme", "pay", 7, "tok",
+		pageInfo{HasNextPage: true, EndCursor: "f1"}, pageInfo{HasNextPage: true, EndCursor: "l1"})
+	if err != nil {
+		t.Fatalf("fetchRemainingLists() error: %v", err)
+	}
+	if !slices.Equal(filePaths(files), []string{"b.go", "vendor/c.go"}) || !slices.Equal(labelNames(labels), []string{"team/pay"}) {
+		t.Errorf("fetchRemainingLists() = %v, %v; want the later files and labels", files, labels)
+	}
+	if len(requests) != 2 || requests[0]["moreLabels"] != true || requests[1]["moreLabels"] != false {
+		t.Errorf("requests = %v, want two, asking for labels only until they were complete", requests)
+	}
+
+	// Complete lists need no requests
+	requests = nil
+	files, labels, err = fetchRemainingLists(context.Background(), srv.Client(), srv.URL, "acme", "pay", 7, "tok", pageInfo{}, pageInfo{})
+	if err != nil || files != nil || labels != nil || len(requests) != 0 {
+		t.Errorf("fetchRemainingLists() of complete lists = %v, %v, %v with %d requests", files, labels, err, len(requests))
+	}
+}
@@ -6,24 +6,60 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
 )
 
 // PRSummary holds minimal information about a PR for sampling and fetching.
 type PRSummary struct {
-	UpdatedAt  time.Time
-	CreatedAt  time.Time
-	ClosedAt   *time.Time // Nil if still open
-	Owner      string
-	Repo       string
-	Author     string
-	AuthorType string // "Bot", "User", or empty if unknown
-	State      string // "OPEN", "CLOSED", "MERGED"
-	Number     int
-	Merged     bool // Whether the PR was merged
+	UpdatedAt          time.Time
+	CreatedAt          time.Time
+	ClosedAt           *time.Time // Nil if still open
+	Owner              string
+	Repo               string
+	Author             string
+	AuthorType         string   // "Bot", "User", or empty if unknown
+	State              string   // "OPEN", "CLOSED", "MERGED"
+	Title              string   // PR title
+	Files              []string // Paths of changed files
+	Labels             []string // Label names
+	GeneratedAdditions int      // Lines added to generated or vendored files
+	// CategoryAdditions is the lines added by file category (see cost.FileCategory)
+	CategoryAdditions map[string]int
+	Number            int
+	Merged            bool // Whether the PR was merged
+	// SampleWeight is the number of PRs this sample stands for, set by SamplePRsWeighted;
+	// zero when every sampled PR stands for an equal share
+	SampleWeight float64
+}
+
+// Info returns pr as the cost package's summary of a PR, for analysis and extrapolation.
+func (pr *PRSummary) Info() cost.PRSummaryInfo {
+	return cost.PRSummaryInfo{
+		UpdatedAt:       pr.UpdatedAt,
+		CreatedAt:       pr.CreatedAt,
+		ClosedAt:        pr.ClosedAt,
+		Owner:           pr.Owner,
+		Repo:            pr.Repo,
+		Author:          pr.Author,
+		AuthorType:      pr.AuthorType,
+		State:           pr.State,
+		Title:           pr.Title,
+		Labels:          pr.Labels,
+		Files:           pr.Files,
+		GeneratedLines:  pr.GeneratedAdditions,
+		LinesByCategory: pr.CategoryAdditions,
+		Number:          pr.Number,
+		Merged:          pr.Merged,
+		SampleWeight:    pr.SampleWeight,
+	}
 }
 
 // ProgressCallback is called during PR fetching to report progress.
@@ -73,8 +109,20 @@ func IsBot(authorType, authorLogin string) bool {
 	return false
 }
 
+// PRQuery selects the PRs that FetchPRsFromRepo and FetchPRsFromOrg list and the open PRs
+// that the CountOpenPRs functions count.
+type PRQuery struct {
+	Since    time.Time        // Only include PRs updated after this time
+	Until    time.Time        // Only include PRs active before this time, and count PRs open then (zero for now; see PRState.inWindow)
+	Progress ProgressCallback // Optional callback for progress updates (can be nil)
+	State    PRState          // Only include PRs in this state, filtered by GitHub (PRStateAll for every PR)
+	Author   string           // Only include PRs by this login in organization queries ("" for every author; see ValidateAuthor)
+	Token    string           // GitHub authentication token
+	API      *APIOptions      // How API calls are retried (nil for the defaults)
+}
+
 // FetchPRsFromRepo queries GitHub GraphQL API for all PRs in a repository
-// modified since the specified date.
+// modified since the specified date, optionally up to an end date.
 //
 // Uses an adaptive multi-query strategy for comprehensive time coverage:
 //  1. Query recent activity (updated DESC) - get up to 1000 PRs
@@ -86,17 +134,15 @@ func IsBot(authorType, authorLogin string) bool {
 //   - ctx: Context for the API call
 //   - owner: GitHub repository owner
 //   - repo: GitHub repository name
-//   - since: Only include PRs updated after this time
-//   - token: GitHub authentication token
-//   - progress: Optional callback for progress updates (can be nil)
+//   - q: The PRs to include; its Author is ignored
 //
 // Returns:
 //   - Slice of PRSummary for all matching PRs (deduplicated)
-func FetchPRsFromRepo(ctx context.Context, owner, repo string, since time.Time, token string, progress ProgressCallback) ([]PRSummary, error) {
+func FetchPRsFromRepo(ctx context.Context, owner, repo string, q PRQuery) ([]PRSummary, error) {
 	// Query 1: Recent activity (updated DESC) - get up to 1000 PRs
 	recent, hitLimit, err := fetchPRsFromRepoWithSort(ctx, repoSortParams{
-		owner: owner, repo: repo, since: since, token: token,
-		field: "UPDATED_AT", direction: "DESC", maxPRs: 1000, queryName: "recent", progress: progress,
+		owner: owner, repo: repo, since: q.Since, until: q.Until, state: q.State, token: q.Token, api: q.API,
+		field: "UPDATED_AT", direction: "DESC", maxPRs: 1000, queryName: "recent", progress: q.Progress,
 	})
 	if err != nil {
 		return nil, err
@@ -110,8 +156,8 @@ func FetchPRsFromRepo(ctx context.Context, owner, repo string, since time.Time,
 	// Hit limit - need more coverage for earlier periods
 	// Query 2: Old activity (updated ASC) - get ~500 more
 	old, _, err := fetchPRsFromRepoWithSort(ctx, repoSortParams{
-		owner: owner, repo: repo, since: since, token: token,
-		field: "UPDATED_AT", direction: "ASC", maxPRs: 500, queryName: "old", progress: progress,
+		owner: owner, repo: repo, since: q.Since, until: q.Until, state: q.State, token: q.Token, api: q.API,
+		field: "UPDATED_AT", direction: "ASC", maxPRs: 500, queryName: "old", progress: q.Progress,
 	})
 	if err != nil {
 		slog.Warn("Failed to fetch old PRs, falling back to recent only", "error", err)
@@ -139,8 +185,8 @@ func FetchPRsFromRepo(ctx context.Context, owner, repo string, since time.Time,
 
 			// Query 3: Early period (created ASC) - get ~250 more
 			early, _, err := fetchPRsFromRepoWithSort(ctx, repoSortParams{
-				owner: owner, repo: repo, since: since, token: token,
-				field: "CREATED_AT", direction: "ASC", maxPRs: 250, queryName: "early", progress: progress,
+				owner: owner, repo: repo, since: q.Since, until: q.Until, state: q.State, token: q.Token, api: q.API,
+				field: "CREATED_AT", direction: "ASC", maxPRs: 250, queryName: "early", progress: q.Progress,
 			})
 			if err != nil {
 				slog.Warn("Failed to fetch early PRs, proceeding with recent+old", "error", err)
@@ -161,10 +207,13 @@ func FetchPRsFromRepo(ctx context.Context, owner, repo string, since time.Time,
 // repoSortParams contains parameters for sorted PR queries.
 type repoSortParams struct {
 	since     time.Time
+	until     time.Time
 	progress  ProgressCallback
+	state     PRState
 	owner     string
 	repo      string
 	token     string
+	api       *APIOptions
 	field     string
 	direction string
 	queryName string
@@ -178,11 +227,12 @@ func fetchPRsFromRepoWithSort(ctx context.Context, params repoSortParams) ([]PRS
 	since, token := params.since, params.token
 	field, direction := params.field, params.direction
 	maxPRs, queryName := params.maxPRs, params.queryName
-	progress := params.progress
+	progress, state, until := params.progress, params.state, params.until
+	client := apiHTTPClient(params.api)
 	query := fmt.Sprintf(`
 	query($owner: String!, $name: String!, $cursor: String) {
 		repository(owner: $owner, name: $name) {
-			pullRequests(first: 100, after: $cursor, orderBy: {field: %s, direction: %s}) {
+			pullRequests(first: 100, after: $cursor, %sorderBy: {field: %s, direction: %s}) {
 				totalCount
 				pageInfo {
 					hasNextPage
@@ -190,6 +240,7 @@ func fetchPRsFromRepoWithSort(ctx context.Context, params repoSortParams) ([]PRS
 				}
 				nodes {
 					number
+					title
 					createdAt
 					updatedAt
 					closedAt
@@ -199,10 +250,29 @@ func fetchPRsFromRepoWithSort(ctx context.Context, params repoSortParams) ([]PRS
 						login
 						__typename
 					}
+					files(first: 100) {
+						pageInfo {
+							hasNextPage
+							endCursor
+						}
+						nodes {
+							path
+							additions
+						}
+					}
+					labels(first: 20) {
+						pageInfo {
+							hasNextPage
+							endCursor
+						}
+						nodes {
+							name
+						}
+					}
 				}
 			}
 		}
-	}`, field, direction)
+	}`, state.graphQLStates(), field, direction)
 
 	var allPRs []PRSummary
 	var cursor *string
@@ -231,7 +301,7 @@ func fetchPRsFromRepoWithSort(ctx context.Context, params repoSortParams) ([]PRS
 		}
 
 		// Make GraphQL request
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.github.com/graphql", bytes.NewReader(bodyBytes))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, GraphQLURL(ctx), bytes.NewReader(bodyBytes))
 		if err != nil {
 			return nil, false, fmt.Errorf("failed to create request: %w", err)
 		}
@@ -239,7 +309,7 @@ func fetchPRsFromRepoWithSort(ctx context.Context, params repoSortParams) ([]PRS
 		req.Header.Set("Authorization", "Bearer "+token)
 		req.Header.Set("Content-Type", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := client.Do(req)
 		if err != nil {
 			return nil, false, fmt.Errorf("failed to execute request: %w", err)
 		}
@@ -266,6 +336,7 @@ func fetchPRsFromRepoWithSort(ctx context.Context, params repoSortParams) ([]PRS
 						}
 						Nodes []struct {
 							Number    int
+							Title     string
 							CreatedAt time.Time
 							UpdatedAt time.Time
 							ClosedAt  *time.Time
@@ -275,6 +346,8 @@ func fetchPRsFromRepoWithSort(ctx context.Context, params repoSortParams) ([]PRS
 								Login    string
 								TypeName string `json:"__typename"`
 							}
+							Files  filesConnection
+							Labels labelsConnection
 						}
 						TotalCount int
 					}
@@ -320,7 +393,7 @@ func fetchPRsFromRepoWithSort(ctx context.Context, params repoSortParams) ([]PRS
 				// For ASC queries, skip and continue (older PRs come first)
 				continue
 			}
-			allPRs = append(allPRs, PRSummary{
+			pr := PRSummary{
 				Owner:      owner,
 				Repo:       repo,
 				Number:     node.Number,
@@ -330,8 +403,14 @@ func fetchPRsFromRepoWithSort(ctx context.Context, params repoSortParams) ([]PRS
 				UpdatedAt:  node.UpdatedAt,
 				ClosedAt:   node.ClosedAt,
 				State:      node.State,
+				Title:      node.Title,
 				Merged:     node.Merged,
-			})
+			}
+			if !state.inWindow(&pr, since, until) {
+				continue
+			}
+			setFilesAndLabels(ctx, client, &pr, node.Files, node.Labels, token)
+			allPRs = append(allPRs, pr)
 
 			// Check if we've hit the maxPRs limit
 			if len(allPRs) >= maxPRs {
@@ -380,7 +459,7 @@ func deduplicatePRs(prs []PRSummary) []PRSummary {
 }
 
 // FetchPRsFromOrg queries GitHub GraphQL Search API for all PRs across
-// an organization modified since the specified date.
+// an organization modified since the specified date, optionally up to an end date.
 //
 // Uses an adaptive multi-query strategy for comprehensive time coverage:
 //  1. Query recent activity (updated desc) - get up to 1000 PRs
@@ -391,19 +470,17 @@ func deduplicatePRs(prs []PRSummary) []PRSummary {
 // Parameters:
 //   - ctx: Context for the API call
 //   - org: GitHub organization name
-//   - since: Only include PRs updated after this time
-//   - token: GitHub authentication token
-//   - progress: Optional callback for progress updates (can be nil)
+//   - q: The PRs to include
 //
 // Returns:
 //   - Slice of PRSummary for all matching PRs (deduplicated)
-func FetchPRsFromOrg(ctx context.Context, org string, since time.Time, token string, progress ProgressCallback) ([]PRSummary, error) {
-	sinceStr := since.Format("2006-01-02")
+func FetchPRsFromOrg(ctx context.Context, org string, q PRQuery) ([]PRSummary, error) {
+	sinceStr := q.Since.Format("2006-01-02")
 
 	// Query 1: Recent activity (updated desc) - get up to 1000 PRs
 	recent, hitLimit, err := fetchPRsFromOrgWithSort(ctx, orgSortParams{
-		org: org, sinceStr: sinceStr, token: token,
-		field: "updated", direction: "desc", maxPRs: 1000, queryName: "recent", progress: progress,
+		org: org, since: q.Since, until: q.Until, sinceStr: sinceStr, state: q.State, author: q.Author, token: q.Token, api: q.API,
+		field: "updated", direction: "desc", maxPRs: 1000, queryName: "recent", progress: q.Progress,
 	})
 	if err != nil {
 		return nil, err
@@ -421,8 +498,8 @@ func FetchPRsFromOrg(ctx context.Context, org string, since time.Time, token str
 	// Hit limit - need more coverage for earlier periods
 	// Query 2: Old activity (updated asc) - get ~500 more
 	old, _, err := fetchPRsFromOrgWithSort(ctx, orgSortParams{
-		org: org, sinceStr: sinceStr, token: token,
-		field: "updated", direction: "asc", maxPRs: 500, queryName: "old", progress: progress,
+		org: org, since: q.Since, until: q.Until, sinceStr: sinceStr, state: q.State, author: q.Author, token: q.Token, api: q.API,
+		field: "updated", direction: "asc", maxPRs: 500, queryName: "old", progress: q.Progress,
 	})
 	if err != nil {
 		slog.Warn("Failed to fetch old PRs from org, falling back to recent only", "error", err)
@@ -450,8 +527,8 @@ func FetchPRsFromOrg(ctx context.Context, org string, since time.Time, token str
 
 			// Query 3: Early period (created asc) - get ~250 more
 			early, _, err := fetchPRsFromOrgWithSort(ctx, orgSortParams{
-				org: org, sinceStr: sinceStr, token: token,
-				field: "created", direction: "asc", maxPRs: 250, queryName: "early", progress: progress,
+				org: org, since: q.Since, until: q.Until, sinceStr: sinceStr, state: q.State, author: q.Author, token: q.Token, api: q.API,
+				field: "created", direction: "asc", maxPRs: 250, queryName: "early", progress: q.Progress,
 			})
 			if err != nil {
 				slog.Warn("Failed to fetch early PRs from org, proceeding with recent+old", "error", err)
@@ -471,27 +548,71 @@ func FetchPRsFromOrg(ctx context.Context, org string, since time.Time, token str
 
 // orgSortParams contains parameters for sorted org PR queries.
 type orgSortParams struct {
+	since     time.Time
+	until     time.Time
 	progress  ProgressCallback
+	state     PRState
 	org       string
+	author    string
 	sinceStr  string
 	token     string
+	api       *APIOptions
 	field     string
 	direction string
 	queryName string
 	maxPRs    int
 }
 
+// orgSearchQuery builds the search query for an org PR query, for example:
+// org:myorg is:pr is:merged author:alice updated:>2025-07-25 sort:updated-desc
+// With an until, PRs created after it are excluded; inWindow applies the exact window.
+func orgSearchQuery(params orgSortParams) string {
+	dateRange := fmt.Sprintf("%s:>%s", params.field, params.sinceStr)
+	if !params.until.IsZero() {
+		untilStr := params.until.Format("2006-01-02")
+		if params.field == "created" {
+			dateRange = fmt.Sprintf("created:%s..%s", params.sinceStr, untilStr)
+		} else {
+			dateRange += " created:<=" + untilStr
+		}
+	}
+	return fmt.Sprintf("org:%s is:pr%s%s %s sort:%s-%s",
+		params.org, params.state.searchQualifier(), authorQualifier(params.author), dateRange, params.field, params.direction)
+}
+
+// authorLogin matches GitHub logins, and GitHub App bot accounts such as dependabot[bot].
+var authorLogin = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,38})(?:\[bot\])?$`)
+
+// ValidateAuthor returns an error unless login is a valid GitHub login, so it can be
+// placed in a search query without adding qualifiers of its own.
+func ValidateAuthor(login string) error {
+	if !authorLogin.MatchString(login) {
+		return fmt.Errorf("invalid author %q: must be a GitHub login", login)
+	}
+	return nil
+}
+
+// authorQualifier returns the search qualifier restricting results to PRs by login, or ""
+// without one. GitHub searches for bot authors as app/NAME rather than NAME[bot].
+func authorQualifier(login string) string {
+	if login == "" {
+		return ""
+	}
+	if name, ok := strings.CutSuffix(login, "[bot]"); ok {
+		return " author:app/" + name
+	}
+	return " author:" + login
+}
+
 // fetchPRsFromOrgWithSort queries GitHub Search API with configurable sort order.
 // Returns PRs and a boolean indicating if the API limit (1000) was hit.
 func fetchPRsFromOrgWithSort(ctx context.Context, params orgSortParams) ([]PRSummary, bool, error) {
-	org, sinceStr := params.org, params.sinceStr
 	token := params.token
 	field, direction := params.field, params.direction
 	maxPRs, queryName := params.maxPRs, params.queryName
-	progress := params.progress
-	// Build search query with sort
-	// Query format: org:myorg is:pr updated:>2025-07-25 sort:updated-desc
-	searchQuery := fmt.Sprintf("org:%s is:pr %s:>%s sort:%s-%s", org, field, sinceStr, field, direction)
+	progress, state, since, until := params.progress, params.state, params.since, params.until
+	client := apiHTTPClient(params.api)
+	searchQuery := orgSearchQuery(params)
 
 	const query = `
 	query($searchQuery: String!, $cursor: String) {
@@ -504,6 +625,7 @@ func fetchPRsFromOrgWithSort(ctx context.Context, params orgSortParams) ([]PRSum
 			nodes {
 				... on PullRequest {
 					number
+					title
 					createdAt
 					updatedAt
 					closedAt
@@ -513,6 +635,25 @@ func fetchPRsFromOrgWithSort(ctx context.Context, params orgSortParams) ([]PRSum
 						login
 						__typename
 					}
+					files(first: 100) {
+						pageInfo {
+							hasNextPage
+							endCursor
+						}
+						nodes {
+							path
+							additions
+						}
+					}
+					labels(first: 20) {
+						pageInfo {
+							hasNextPage
+							endCursor
+						}
+						nodes {
+							name
+						}
+					}
 					repository {
 						owner {
 							login
@@ -550,7 +691,7 @@ func fetchPRsFromOrgWithSort(ctx context.Context, params orgSortParams) ([]PRSum
 		}
 
 		// Make GraphQL request
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.github.com/graphql", bytes.NewReader(bodyBytes))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, GraphQLURL(ctx), bytes.NewReader(bodyBytes))
 		if err != nil {
 			return nil, false, fmt.Errorf("failed to create request: %w", err)
 		}
@@ -558,7 +699,7 @@ func fetchPRsFromOrgWithSort(ctx context.Context, params orgSortParams) ([]PRSum
 		req.Header.Set("Authorization", "Bearer "+token)
 		req.Header.Set("Content-Type", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := client.Do(req)
 		if err != nil {
 			return nil, false, fmt.Errorf("failed to execute request: %w", err)
 		}
@@ -584,6 +725,7 @@ func fetchPRsFromOrgWithSort(ctx context.Context, params orgSortParams) ([]PRSum
 					}
 					Nodes []struct {
 						Number    int
+						Title     string
 						CreatedAt time.Time
 						UpdatedAt time.Time
 						ClosedAt  *time.Time
@@ -593,6 +735,8 @@ func fetchPRsFromOrgWithSort(ctx context.Context, params orgSortParams) ([]PRSum
 							Login    string
 							TypeName string `json:"__typename"`
 						}
+						Files      filesConnection
+						Labels     labelsConnection
 						Repository struct {
 							Owner struct{ Login string }
 							Name  string
@@ -628,7 +772,7 @@ func fetchPRsFromOrgWithSort(ctx context.Context, params orgSortParams) ([]PRSum
 
 		// Collect PRs from this page
 		for _, node := range result.Data.Search.Nodes {
-			allPRs = append(allPRs, PRSummary{
+			pr := PRSummary{
 				Owner:      node.Repository.Owner.Login,
 				Repo:       node.Repository.Name,
 				Number:     node.Number,
@@ -638,8 +782,14 @@ func fetchPRsFromOrgWithSort(ctx context.Context, params orgSortParams) ([]PRSum
 				UpdatedAt:  node.UpdatedAt,
 				ClosedAt:   node.ClosedAt,
 				State:      node.State,
+				Title:      node.Title,
 				Merged:     node.Merged,
-			})
+			}
+			if !state.inWindow(&pr, since, until) {
+				continue
+			}
+			setFilesAndLabels(ctx, client, &pr, node.Files, node.Labels, token)
+			allPRs = append(allPRs, pr)
 
 			// Check if we've hit the maxPRs limit
 			if len(allPRs) >= maxPRs {
@@ -667,6 +817,64 @@ func fetchPRsFromOrgWithSort(ctx context.Context, params orgSortParams) ([]PRSum
 	return allPRs, hitLimit, nil
 }
 
+// fileNode is a changed file in a GraphQL files connection.
+type fileNode struct {
+	Path      string
+	Additions int
+}
+
+// filePaths extracts paths from a GraphQL files connection.
+func filePaths(nodes []fileNode) []string {
+	if len(nodes) == 0 {
+		return nil
+	}
+	paths := make([]string, len(nodes))
+	for i, n := range nodes {
+		paths[i] = n.Path
+	}
+	return paths
+}
+
+// labelNode is a label in a GraphQL labels connection.
+type labelNode struct {
+	Name string
+}
+
+// labelNames extracts names from a GraphQL labels connection.
+func labelNames(nodes []labelNode) []string {
+	if len(nodes) == 0 {
+		return nil
+	}
+	names := make([]string, len(nodes))
+	for i, n := range nodes {
+		names[i] = n.Name
+	}
+	return names
+}
+
+// generatedAdditions sums the lines added to generated or vendored files (see cost.IsGeneratedPath).
+func generatedAdditions(nodes []fileNode) int {
+	var total int
+	for _, n := range nodes {
+		if cost.IsGeneratedPath(n.Path) {
+			total += n.Additions
+		}
+	}
+	return total
+}
+
+// categoryAdditions sums the lines added to each file category (see cost.FileCategory).
+func categoryAdditions(nodes []fileNode) map[string]int {
+	if len(nodes) == 0 {
+		return nil
+	}
+	additions := make(map[string]int)
+	for _, n := range nodes {
+		additions[cost.FileCategory(n.Path)] += n.Additions
+	}
+	return additions
+}
+
 // deduplicatePRsByOwnerRepoNumber removes duplicate PRs from a slice using owner+repo+number as key.
 func deduplicatePRsByOwnerRepoNumber(prs []PRSummary) []PRSummary {
 	type key struct {
@@ -704,6 +912,70 @@ func CountBotPRs(prs []PRSummary) int {
 	return count
 }
 
+// PRFilter selects which PRs form the sampling population. The zero value matches every PR.
+//
+//nolint:govet // fieldalignment: grouped by purpose for readability
+type PRFilter struct {
+	Labels      []string // PRs must carry every one of these labels (AND, case-insensitive)
+	Author      string   // Only PRs by this login (case-insensitive)
+	State       PRState  // Only PRs in this state; pass it to the fetch too so GitHub filters server-side
+	ExcludeBots bool     // Drop bot-authored PRs (see IsBot)
+	// PRs must modify a file matching one of these globs (see cost.MatchPath); PRs whose files
+	// are unknown never match
+	Paths []string
+}
+
+// IsZero reports whether the filter matches every PR.
+func (f PRFilter) IsZero() bool {
+	return len(f.Labels) == 0 && f.Author == "" && f.State.IsAll() && !f.ExcludeBots && len(f.Paths) == 0
+}
+
+// Matches reports whether pr passes every condition of the filter.
+func (f PRFilter) Matches(pr *PRSummary) bool {
+	if !f.State.Matches(pr) {
+		return false
+	}
+	if f.Author != "" && !strings.EqualFold(pr.Author, f.Author) {
+		return false
+	}
+	if f.ExcludeBots && IsBot(pr.AuthorType, pr.Author) {
+		return false
+	}
+	for _, want := range f.Labels {
+		if !slices.ContainsFunc(pr.Labels, func(label string) bool { return strings.EqualFold(label, want) }) {
+			return false
+		}
+	}
+	return len(f.Paths) == 0 || cost.TouchesPaths(pr.Files, f.Paths)
+}
+
+// FilterPRs returns the PRs matching filter. Filter before SamplePRs so that both the sample
+// and the extrapolation base (the number of PRs) reflect the filtered population.
+// With a zero filter, prs is returned unchanged.
+func FilterPRs(prs []PRSummary, filter PRFilter) []PRSummary {
+	if filter.IsZero() {
+		return prs
+	}
+
+	var matched []PRSummary
+	for i := range prs {
+		if filter.Matches(&prs[i]) {
+			matched = append(matched, prs[i])
+		}
+	}
+
+	slog.Info("Filtered PRs",
+		"labels", filter.Labels,
+		"author", filter.Author,
+		"state", filter.State,
+		"exclude_bots", filter.ExcludeBots,
+		"paths", filter.Paths,
+		"total", len(prs),
+		"matched", len(matched))
+
+	return matched
+}
+
 // SamplePRs uses a time-bucket strategy to evenly sample PRs across the time range.
 // This ensures samples are distributed throughout the period rather than clustered.
 // Bot-authored PRs are excluded from sampling.
@@ -818,28 +1090,43 @@ func CountUniqueAuthors(prs []PRSummary) int {
 	return len(uniqueAuthors)
 }
 
-// CalculateActualTimeWindow validates time coverage for the fetched PRs.
-// With the multi-query approach, we fetch PRs to cover the full requested period.
-// This function logs coverage statistics but always returns the requested period.
+// apiResultLimit is the number of PRs at which a fetch is assumed to have been cut short
+// by GitHub's result limits (1000 results per search or connection).
+const apiResultLimit = 1000
+
+// CalculateActualTimeWindow determines how many days the fetched PRs actually cover.
+// The multi-query approach usually covers the full requested period, but very busy
+// repositories and organizations can exhaust GitHub's result limits before reaching it.
 //
 // Parameters:
-//   - prs: List of PRs fetched (may be from multiple queries)
+//   - prs: List of PRs fetched (may be from multiple queries, in any order)
 //   - requestedDays: Number of days originally requested
+//   - until: End of the requested window, or zero if it ends now
 //
 // Returns:
-//   - actualDays: Always returns requestedDays (multi-query ensures coverage)
-//   - hitLimit: Always returns false (no period adjustment needed)
-func CalculateActualTimeWindow(prs []PRSummary, requestedDays int) (actualDays int, hitLimit bool) {
+//   - actualDays: Days covered by the fetched PRs, or requestedDays if coverage is complete
+//   - hitLimit: True if API limits truncated the window (actualDays < requestedDays)
+func CalculateActualTimeWindow(prs []PRSummary, requestedDays int, until time.Time) (actualDays int, hitLimit bool) {
 	// If no PRs, return requested days
 	if len(prs) == 0 {
 		return requestedDays, false
 	}
 
+	newestTime, oldestTime := prs[0].UpdatedAt, prs[0].UpdatedAt
+	for i := range prs {
+		if prs[i].UpdatedAt.After(newestTime) {
+			newestTime = prs[i].UpdatedAt
+		}
+		if prs[i].UpdatedAt.Before(oldestTime) {
+			oldestTime = prs[i].UpdatedAt
+		}
+	}
+
 	// Calculate coverage statistics for logging
-	requestedSince := time.Now().AddDate(0, 0, -requestedDays)
-	oldestTime := prs[len(prs)-1].UpdatedAt
-	timeSinceOldestPR := time.Since(oldestTime)
-	requestedDuration := time.Since(requestedSince)
+	end := windowEnd(until)
+	requestedSince := end.AddDate(0, 0, -requestedDays)
+	timeSinceOldestPR := end.Sub(oldestTime)
+	requestedDuration := end.Sub(requestedSince)
 	coverageGap := requestedDuration - timeSinceOldestPR
 
 	slog.Info("Time coverage analysis",
@@ -847,153 +1134,194 @@ func CalculateActualTimeWindow(prs []PRSummary, requestedDays int) (actualDays i
 		"total_prs", len(prs),
 		"oldest_pr_age_days", int(timeSinceOldestPR.Hours()/24.0),
 		"coverage_gap_days", int(coverageGap.Hours()/24.0),
-		"newest_pr", prs[0].UpdatedAt.Format(time.RFC3339),
+		"newest_pr", newestTime.Format(time.RFC3339),
 		"oldest_pr", oldestTime.Format(time.RFC3339))
 
-	// Always return requested period - multi-query approach ensures best possible coverage
-	return requestedDays, false
-}
-
-// CountOpenPRsInRepo queries GitHub GraphQL API to get the total count of open PRs in a repository
-// that were created more than 24 hours ago (PRs open <24 hours don't count as tracking overhead yet).
-//
-// Parameters:
-//   - ctx: Context for the API call
-//   - owner: GitHub repository owner
-//   - repo: GitHub repository name
-//   - token: GitHub authentication token
-//
-// Returns:
-//   - count: Number of open PRs created >24 hours ago
-func CountOpenPRsInRepo(ctx context.Context, owner, repo, token string) (int, error) {
-	// Only count PRs created more than 24 hours ago
-	// Use search API which supports created date filtering
-	twentyFourHoursAgo := time.Now().Add(-24 * time.Hour).Format("2006-01-02T15:04:05Z")
-
-	query := `query($searchQuery: String!) {
-		search(query: $searchQuery, type: ISSUE, first: 0) {
-			issueCount
-		}
-	}`
-
-	// Search query: is:pr is:open repo:owner/repo created:<date
-	searchQuery := fmt.Sprintf("is:pr is:open repo:%s/%s created:<%s", owner, repo, twentyFourHoursAgo)
-
-	variables := map[string]any{
-		"searchQuery": searchQuery,
+	// A quiet repository legitimately has no PRs in part of the window; only a fetch
+	// that reached the API limit and still fell short by over a day is truncated.
+	if len(prs) < apiResultLimit || coverageGap <= 24*time.Hour {
+		return requestedDays, false
 	}
 
-	queryJSON, err := json.Marshal(map[string]any{
-		"query":     query,
-		"variables": variables,
-	})
-	if err != nil {
-		return 0, fmt.Errorf("failed to marshal query: %w", err)
+	actualDays = max(int(math.Ceil(timeSinceOldestPR.Hours()/24.0)), 1)
+	if actualDays >= requestedDays {
+		return requestedDays, false
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.github.com/graphql", bytes.NewBuffer(queryJSON))
-	if err != nil {
-		return 0, fmt.Errorf("failed to create request: %w", err)
-	}
+	slog.Warn("Time window truncated by GitHub API limits",
+		"requested_days", requestedDays,
+		"actual_days", actualDays,
+		"total_prs", len(prs))
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
+	return actualDays, true
+}
 
-	slog.Info("HTTP request starting",
-		"method", "POST",
-		"url", "https://api.github.com/graphql",
-		"host", "api.github.com")
+// SampledPR identifies a PR selected for sampling.
+type SampledPR struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	Owner     string    `json:"owner"`
+	Repo      string    `json:"repo"`
+	Author    string    `json:"author"`
+	Number    int       `json:"number"`
+	Bot       bool      `json:"bot"`
+	Weight    float64   `json:"weight,omitempty"` // PRs this sample stands for under weighted sampling
+}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return 0, fmt.Errorf("request failed: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // best effort close
+// SamplePlan describes which PRs a sampled analysis would fetch, without fetching them.
+// It lets callers check sampling coverage and API cost before a full run.
+type SamplePlan struct {
+	Samples       []SampledPR `json:"samples"`
+	TotalPRs      int         `json:"total_prs"`
+	HumanPRs      int         `json:"human_prs"`
+	BotPRs        int         `json:"bot_prs"`
+	RequestedDays int         `json:"requested_days"`
+	ActualDays    int         `json:"actual_days"` // Less than RequestedDays if API limits truncated the window
+	Truncated     bool        `json:"truncated"`
+	PRFetches     int         `json:"pr_fetches"` // PR data fetches a full run would make (one per sample, before caching)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+// PlanSample samples prs using mode, runs CalculateActualTimeWindow over them, and reports
+// the result. until is the end of the requested window, or zero if it ends now.
+func PlanSample(prs []PRSummary, sampleSize, requestedDays int, until time.Time, mode SamplingMode) SamplePlan {
+	actualDays, truncated := CalculateActualTimeWindow(prs, requestedDays, until)
+	botPRs := CountBotPRs(prs)
+	samples := mode.Sample(prs, sampleSize)
+
+	plan := SamplePlan{
+		Samples:       make([]SampledPR, len(samples)),
+		TotalPRs:      len(prs),
+		HumanPRs:      len(prs) - botPRs,
+		BotPRs:        botPRs,
+		RequestedDays: requestedDays,
+		ActualDays:    actualDays,
+		Truncated:     truncated,
+		PRFetches:     len(samples),
 	}
-
-	var result struct {
-		Errors []struct {
-			Message string
+	for i := range samples {
+		plan.Samples[i] = SampledPR{
+			UpdatedAt: samples[i].UpdatedAt,
+			Owner:     samples[i].Owner,
+			Repo:      samples[i].Repo,
+			Author:    samples[i].Author,
+			Number:    samples[i].Number,
+			Bot:       IsBot(samples[i].AuthorType, samples[i].Author),
+			Weight:    samples[i].SampleWeight,
 		}
-		Data struct {
-			Search struct {
-				IssueCount int `json:"issueCount"`
-			} `json:"search"`
-		} `json:"data"`
 	}
+	return plan
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return 0, fmt.Errorf("failed to decode response: %w", err)
+// Anonymized returns a copy of the plan with human authors' logins replaced by pseudonyms
+// such as author-1 (see cost.Anonymizer). Bot authors are kept.
+func (p SamplePlan) Anonymized() SamplePlan {
+	authors := make([]cost.Breakdown, len(p.Samples))
+	for i, pr := range p.Samples {
+		authors[i] = cost.Breakdown{PRAuthor: pr.Author, AuthorBot: pr.Bot}
 	}
-
-	if len(result.Errors) > 0 {
-		return 0, fmt.Errorf("GraphQL error: %s", result.Errors[0].Message)
+	names := cost.NewAnonymizer(authors)
+	p.Samples = slices.Clone(p.Samples)
+	for i := range p.Samples {
+		if !p.Samples[i].Bot {
+			p.Samples[i].Author = names.Name(p.Samples[i].Author)
+		}
 	}
+	return p
+}
 
-	count := result.Data.Search.IssueCount
-
+// CountOpenPRsInRepo queries GitHub GraphQL API to get the total count of open PRs in a repository
+// that were created more than 24 hours ago (PRs open <24 hours don't count as tracking overhead yet).
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - owner: GitHub repository owner
+//   - repo: GitHub repository name
+//   - q: Counts the PRs open at q.Until, for a past analysis window (zero means now); other
+//     fields but Token are ignored
+//
+// Returns:
+//   - count: Number of open PRs created >24 hours ago
+func CountOpenPRsInRepo(ctx context.Context, owner, repo string, q PRQuery) (int, error) {
+	count, err := countOpenPRsMatching(ctx, apiHTTPClient(q.API), GraphQLURL(ctx), "repo:"+owner+"/"+repo, q.Until, q.Token)
+	if err != nil {
+		return 0, err
+	}
 	slog.Info("Counted PRs open >24 hours in repository",
 		"owner", owner,
 		"repo", repo,
 		"open_prs", count,
 		"filter", "created >24h ago")
-
 	return count, nil
 }
 
 // CountOpenPRsInOrg counts all open PRs across an entire GitHub organization with a single GraphQL query.
 // This is much more efficient than counting PRs repo-by-repo for organizations with many repositories.
-// Only counts PRs created more than 24 hours ago to exclude brand-new PRs.
-func CountOpenPRsInOrg(ctx context.Context, org, token string) (int, error) {
-	// Only count PRs created more than 24 hours ago
-	twentyFourHoursAgo := time.Now().Add(-24 * time.Hour).Format("2006-01-02T15:04:05Z")
+// Only counts PRs created more than 24 hours ago to exclude brand-new PRs. With a non-zero q.Until,
+// it counts the PRs that were open then, as CountOpenPRsInRepo does. With q.Author, it counts only
+// that author's open PRs, including those that weren't updated in the analysis window.
+func CountOpenPRsInOrg(ctx context.Context, org string, q PRQuery) (int, error) {
+	count, err := countOpenPRsMatching(ctx, apiHTTPClient(q.API), GraphQLURL(ctx), "org:"+org+authorQualifier(q.Author), q.Until, q.Token)
+	if err != nil {
+		return 0, err
+	}
+	slog.Info("Counted PRs open >24 hours in organization",
+		"org", org,
+		"author", q.Author,
+		"open_prs", count,
+		"filter", "created >24h ago")
+	return count, nil
+}
+
+// countOpenPRsMatching counts the open PRs created more than 24 hours ago (PRs open less than a
+// day don't count as tracking overhead yet) that match the search qualifiers, with search
+// queries to the GraphQL API at graphqlURL. With a past until, PRs that were open then are
+// counted: those still open plus those closed since.
+func countOpenPRsMatching(ctx context.Context, client *http.Client, graphqlURL, qualifiers string, until time.Time, token string) (int, error) {
+	at := time.Now()
+	past := !until.IsZero() && until.Before(at)
+	if past {
+		at = until
+	}
+	created := at.Add(-24 * time.Hour).UTC().Format(time.RFC3339)
 
+	count, err := countSearch(ctx, client, graphqlURL, fmt.Sprintf("is:pr is:open %s created:<%s", qualifiers, created), token)
+	if err != nil || !past {
+		return count, err
+	}
+	closed, err := countSearch(ctx, client, graphqlURL,
+		fmt.Sprintf("is:pr is:closed %s created:<%s closed:>%s", qualifiers, created, at.UTC().Format(time.RFC3339)), token)
+	if err != nil {
+		return 0, err
+	}
+	return count + closed, nil
+}
+
+// countSearch returns the number of issues and PRs matching searchQuery.
+func countSearch(ctx context.Context, client *http.Client, graphqlURL, searchQuery, token string) (int, error) {
 	query := `query($searchQuery: String!) {
 		search(query: $searchQuery, type: ISSUE, first: 0) {
 			issueCount
 		}
 	}`
 
-	// Search query: is:pr is:open org:orgname created:<date
-	searchQuery := fmt.Sprintf("is:pr is:open org:%s created:<%s", org, twentyFourHoursAgo)
-
-	variables := map[string]any{
-		"searchQuery": searchQuery,
-	}
-
 	queryJSON, err := json.Marshal(map[string]any{
 		"query":     query,
-		"variables": variables,
+		"variables": map[string]any{"searchQuery": searchQuery},
 	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to marshal query: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.github.com/graphql", bytes.NewBuffer(queryJSON))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, graphqlURL, bytes.NewBuffer(queryJSON))
 	if err != nil {
 		return 0, fmt.Errorf("failed to create request: %w", err)
 	}
-
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 
 	slog.Info("HTTP request starting",
 		"method", "POST",
-		"url", "https://api.github.com/graphql",
-		"host", "api.github.com")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return 0, fmt.Errorf("request failed: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // best effort close
-
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+		"url", graphqlURL,
+		"search", searchQuery)
 
 	var result struct {
 		Errors []struct {
@@ -1005,23 +1333,13 @@ func CountOpenPRsInOrg(ctx context.Context, org, token string) (int, error) {
 			} `json:"search"`
 		} `json:"data"`
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return 0, fmt.Errorf("failed to decode response: %w", err)
+	if err := doGraphQL(client, req, &result); err != nil {
+		return 0, err
 	}
-
 	if len(result.Errors) > 0 {
 		return 0, fmt.Errorf("GraphQL error: %s", result.Errors[0].Message)
 	}
-
-	count := result.Data.Search.IssueCount
-
-	slog.Info("Counted PRs open >24 hours in organization",
-		"org", org,
-		"open_prs", count,
-		"filter", "created >24h ago")
-
-	return count, nil
+	return result.Data.Search.IssueCount, nil
 }
 
 // RepoVisibility contains repository name and privacy status.
@@ -1037,12 +1355,13 @@ type RepoVisibility struct {
 // Parameters:
 //   - ctx: Context for the API call
 //   - org: GitHub organization name
-//   - since: Only include repos with activity after this time
-//   - token: GitHub authentication token
+//   - q: Only includes repos with activity after q.Since; other fields but Token and API are ignored
 //
 // Returns:
 //   - Map of repository name to RepoVisibility struct
-func FetchOrgRepositoriesWithActivity(ctx context.Context, org string, since time.Time, token string) (map[string]RepoVisibility, error) {
+func FetchOrgRepositoriesWithActivity(ctx context.Context, org string, q PRQuery) (map[string]RepoVisibility, error) {
+	since, token := q.Since, q.Token
+	client := apiHTTPClient(q.API)
 	query := `
 		query($org: String!, $cursor: String) {
 			organization(login: $org) {
@@ -1080,7 +1399,7 @@ func FetchOrgRepositoriesWithActivity(ctx context.Context, org string, since tim
 			return nil, fmt.Errorf("failed to marshal request: %w", err)
 		}
 
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.github.com/graphql", bytes.NewReader(bodyBytes))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, GraphQLURL(ctx), bytes.NewReader(bodyBytes))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
@@ -1088,7 +1407,6 @@ func FetchOrgRepositoriesWithActivity(ctx context.Context, org string, since tim
 		req.Header.Set("Authorization", "Bearer "+token)
 		req.Header.Set("Content-Type", "application/json")
 
-		client := &http.Client{Timeout: 30 * time.Second}
 		resp, err := client.Do(req)
 		if err != nil {
 			return nil, fmt.Errorf("failed to make request: %w", err)
@@ -1,8 +1,13 @@
 package github
 
 import (
+	"maps"
+	"slices"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
 )
 
 func TestIsBot(t *testing.T) {
@@ -63,6 +68,83 @@ func TestCountBotPRs(t *testing.T) {
 	}
 }
 
+func TestFilterPRsPaths(t *testing.T) {
+	prs := []PRSummary{
+		{Number: 1, Files: []string{"payments/charge.go", "README.md"}},
+		{Number: 2, Files: []string{"docs/index.md"}},
+		{Number: 3, Files: []string{"infra/main.tf"}},
+		{Number: 4},
+		{Number: 5, Files: []string{"services/api/payments/refund.go"}},
+	}
+
+	got := FilterPRs(prs, PRFilter{Paths: []string{"payments/", "services/*/payments"}})
+	if len(got) != 2 || got[0].Number != 1 || got[1].Number != 5 {
+		t.Errorf("FilterPRs(paths) = %v, want PRs 1 and 5", got)
+	}
+
+	if got := FilterPRs(prs, PRFilter{Paths: []string{"infra"}}); len(got) != 1 || got[0].Number != 3 {
+		t.Errorf("FilterPRs(paths infra) = %v, want PR 3", got)
+	}
+
+	if got := FilterPRs(prs, PRFilter{Paths: []string{"auth"}}); len(got) != 0 {
+		t.Errorf("FilterPRs(paths auth) = %v, want none", got)
+	}
+
+	if got := FilterPRs(prs, PRFilter{}); len(got) != len(prs) {
+		t.Errorf("FilterPRs(no paths) returned %d PRs, want %d", len(got), len(prs))
+	}
+}
+
+func TestFilterPRs(t *testing.T) {
+	prs := []PRSummary{
+		{Number: 1, Author: "alice", Labels: []string{"team/payments", "bug"}},
+		{Number: 2, Author: "bob", Labels: []string{"team/payments"}},
+		{Number: 3, Author: "dependabot[bot]", AuthorType: "Bot", Labels: []string{"Team/Payments", "bug"}},
+		{Number: 4, Author: "Alice"},
+	}
+
+	tests := []struct {
+		name   string
+		filter PRFilter
+		want   []int
+	}{
+		{"zero filter", PRFilter{}, []int{1, 2, 3, 4}},
+		{"label", PRFilter{Labels: []string{"team/payments"}}, []int{1, 2, 3}},
+		{"labels are AND-ed", PRFilter{Labels: []string{"team/payments", "BUG"}}, []int{1, 3}},
+		{"author", PRFilter{Author: "alice"}, []int{1, 4}},
+		{"exclude bots", PRFilter{ExcludeBots: true, Labels: []string{"bug"}}, []int{1}},
+		{"no match", PRFilter{Labels: []string{"team/search"}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, pr := range FilterPRs(prs, tt.filter) {
+				got = append(got, pr.Number)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("FilterPRs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAuthor(t *testing.T) {
+	for _, login := range []string{"alice", "Alice-B", "dependabot[bot]", "a1"} {
+		if err := ValidateAuthor(login); err != nil {
+			t.Errorf("ValidateAuthor(%q) = %v, want nil", login, err)
+		}
+	}
+	for _, login := range []string{"", "-alice", "alice org:other", "alice\tb", "al[bot]ice", strings.Repeat("a", 40)} {
+		if err := ValidateAuthor(login); err == nil {
+			t.Errorf("ValidateAuthor(%q) succeeded, want error", login)
+		}
+	}
+	if got := authorQualifier("dependabot[bot]"); got != " author:app/dependabot" {
+		t.Errorf("authorQualifier(bot) = %q, want the app/ form", got)
+	}
+}
+
 func TestSamplePRs(t *testing.T) {
 	// Create sample PRs
 	prs := make([]PRSummary, 100)
@@ -136,7 +218,7 @@ func TestCalculateActualTimeWindow(t *testing.T) {
 	}
 
 	// When PRs don't cover full requested period, function returns requested days
-	days, hitLimit := CalculateActualTimeWindow(prs, 30)
+	days, hitLimit := CalculateActualTimeWindow(prs, 30, time.Time{})
 	if days != 30 {
 		t.Errorf("CalculateActualTimeWindow() = %d days, want 30 days (requested)", days)
 	}
@@ -145,7 +227,7 @@ func TestCalculateActualTimeWindow(t *testing.T) {
 	}
 
 	// Test with empty PRs
-	days2, hitLimit2 := CalculateActualTimeWindow([]PRSummary{}, 30)
+	days2, hitLimit2 := CalculateActualTimeWindow([]PRSummary{}, 30, time.Time{})
 	if days2 != 30 {
 		t.Errorf("CalculateActualTimeWindow(empty) = %d days, want 30", days2)
 	}
@@ -154,6 +236,72 @@ func TestCalculateActualTimeWindow(t *testing.T) {
 	}
 }
 
+func TestCalculateActualTimeWindowTruncated(t *testing.T) {
+	now := time.Now()
+
+	// 1000 PRs (the API limit) covering only the last ~20 days of a 90-day request
+	prs := make([]PRSummary, 1000)
+	for i := range prs {
+		prs[i] = PRSummary{Number: i + 1, UpdatedAt: now.Add(-time.Duration(i) * 28 * time.Minute)}
+	}
+
+	days, hitLimit := CalculateActualTimeWindow(prs, 90, time.Time{})
+	if !hitLimit {
+		t.Error("CalculateActualTimeWindow() hitLimit = false, want true")
+	}
+	if days != 20 {
+		t.Errorf("CalculateActualTimeWindow() = %d days, want 20", days)
+	}
+
+	// The same pool fully covers a 14-day request
+	days, hitLimit = CalculateActualTimeWindow(prs, 14, time.Time{})
+	if hitLimit || days != 14 {
+		t.Errorf("CalculateActualTimeWindow(14) = %d, %v; want 14, false", days, hitLimit)
+	}
+
+	// Coverage of a past window is measured from its end, not from now
+	until := now.AddDate(-1, 0, 0)
+	for i := range prs {
+		prs[i].UpdatedAt = until.Add(-time.Duration(i) * 28 * time.Minute)
+	}
+	days, hitLimit = CalculateActualTimeWindow(prs, 90, until)
+	if !hitLimit || days != 20 {
+		t.Errorf("CalculateActualTimeWindow(90, a year ago) = %d, %v; want 20, true", days, hitLimit)
+	}
+}
+
+func TestPlanSample(t *testing.T) {
+	now := time.Now()
+	prs := make([]PRSummary, 40)
+	for i := range prs {
+		prs[i] = PRSummary{Number: i + 1, Owner: "o", Repo: "r", Author: "alice", UpdatedAt: now.Add(-time.Duration(i) * time.Hour)}
+	}
+	prs[3].Author = "dependabot[bot]"
+
+	plan := PlanSample(prs, 10, 30, time.Time{}, SamplingTime)
+	if plan.TotalPRs != 40 || plan.HumanPRs != 39 || plan.BotPRs != 1 {
+		t.Errorf("plan counts = %d total, %d human, %d bot; want 40, 39, 1", plan.TotalPRs, plan.HumanPRs, plan.BotPRs)
+	}
+	if plan.ActualDays != 30 || plan.Truncated {
+		t.Errorf("plan window = %d days (truncated %v), want 30", plan.ActualDays, plan.Truncated)
+	}
+	if len(plan.Samples) != 10 || plan.PRFetches != 10 {
+		t.Fatalf("plan has %d samples and %d fetches, want 10", len(plan.Samples), plan.PRFetches)
+	}
+
+	// The plan lists exactly what SamplePRs selects
+	samples := SamplePRs(prs, 10)
+	for i, s := range plan.Samples {
+		if s.Number != samples[i].Number || s.Author != samples[i].Author || !s.UpdatedAt.Equal(samples[i].UpdatedAt) {
+			t.Errorf("plan sample %d = %+v, want PR #%d", i, s, samples[i].Number)
+		}
+	}
+
+	if plan := PlanSample(nil, 10, 30, time.Time{}, SamplingTime); len(plan.Samples) != 0 || plan.TotalPRs != 0 {
+		t.Errorf("PlanSample(nil) = %+v, want empty plan", plan)
+	}
+}
+
 func TestDeduplicatePRs(t *testing.T) {
 	now := time.Now()
 	earlier := now.Add(-1 * time.Hour)
@@ -222,3 +370,58 @@ func TestIsBotEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestGeneratedAdditions(t *testing.T) {
+	nodes := []fileNode{
+		{Path: "api/service.pb.go", Additions: 1200},
+		{Path: "vendor/github.com/foo/bar.go", Additions: 300},
+		{Path: "pkg/server/handler.go", Additions: 45},
+	}
+	if got := generatedAdditions(nodes); got != 1500 {
+		t.Errorf("generatedAdditions() = %d, want 1500", got)
+	}
+	if got := generatedAdditions(nil); got != 0 {
+		t.Errorf("generatedAdditions(nil) = %d, want 0", got)
+	}
+}
+
+func TestCategoryAdditions(t *testing.T) {
+	nodes := []fileNode{
+		{Path: "api/service.pb.go", Additions: 1200},
+		{Path: "deploy/values.yaml", Additions: 80},
+		{Path: "README.md", Additions: 20},
+		{Path: "pkg/server/handler.go", Additions: 45},
+		{Path: "pkg/server/routes.go", Additions: 5},
+	}
+	got := categoryAdditions(nodes)
+	want := map[string]int{
+		cost.FileCategoryGenerated: 1200,
+		cost.FileCategoryConfig:    80,
+		cost.FileCategoryDocs:      20,
+		cost.FileCategoryCode:      50,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("categoryAdditions() = %v, want %v", got, want)
+	}
+	if got := categoryAdditions(nil); got != nil {
+		t.Errorf("categoryAdditions(nil) = %v, want nil", got)
+	}
+}
+
+func TestSamplePlanAnonymized(t *testing.T) {
+	plan := SamplePlan{Samples: []SampledPR{
+		{Number: 1, Author: "alice"},
+		{Number: 2, Author: "dependabot[bot]", Bot: true},
+		{Number: 3, Author: "alice"},
+	}}
+	got := plan.Anonymized()
+	if got.Samples[0].Author != "author-1" || got.Samples[2].Author != "author-1" {
+		t.Errorf("anonymized authors = %q and %q, want author-1 for both", got.Samples[0].Author, got.Samples[2].Author)
+	}
+	if got.Samples[1].Author != "dependabot[bot]" {
+		t.Errorf("bot author = %q, want it unchanged", got.Samples[1].Author)
+	}
+	if plan.Samples[0].Author != "alice" {
+		t.Error("Anonymized() changed the original plan")
+	}
+}
@@ -0,0 +1,13 @@
+package github
+
+import (
+	"math"
+
+	"golang.org/x/time/rate"
+)
+
+// NewRateBudget returns a token bucket allowing requestsPerSecond GitHub API requests per second
+// on average, with bursts of up to one second's worth. Share it through APIOptions.RateBudget.
+func NewRateBudget(requestsPerSecond float64) *rate.Limiter {
+	return rate.NewLimiter(rate.Limit(requestsPerSecond), max(int(math.Ceil(requestsPerSecond)), 1))
+}
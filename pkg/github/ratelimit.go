@@ -0,0 +1,124 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit is a GitHub API rate limit as of a response's X-RateLimit-* headers.
+type RateLimit struct {
+	Resource  string    `json:"resource"` // "core", "graphql", "search", ...
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"` // When Remaining is restored to Limit
+}
+
+// RateLimitError is returned for a request rejected because its rate limit is exhausted and
+// doesn't reset soon enough to wait for.
+type RateLimitError struct {
+	RateLimit
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("out of GitHub API quota (%s limit of %d), resets at %s",
+		e.Resource, e.Limit, e.Reset.Local().Format("15:04"))
+}
+
+// RateLimitTracker records the rate limits reported by the GitHub API responses to requests made
+// with its context (see WithRateLimitTracker). It is safe for concurrent use.
+type RateLimitTracker struct {
+	mu     sync.Mutex
+	limits map[string]RateLimit // By resource
+}
+
+// NewRateLimitTracker returns an empty tracker.
+func NewRateLimitTracker() *RateLimitTracker {
+	return &RateLimitTracker{limits: make(map[string]RateLimit)}
+}
+
+// rateLimitTrackerKey is the context key for a RateLimitTracker.
+type rateLimitTrackerKey struct{}
+
+// WithRateLimitTracker returns a copy of ctx whose GitHub API responses are recorded in tracker,
+// so a caller can report how much quota a scan left.
+func WithRateLimitTracker(ctx context.Context, tracker *RateLimitTracker) context.Context {
+	return context.WithValue(ctx, rateLimitTrackerKey{}, tracker)
+}
+
+// Lowest returns the rate limit with the smallest fraction of its quota left, or nil if no
+// response reported one (e.g. all data came from caches or another data source).
+func (t *RateLimitTracker) Lowest() *RateLimit {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var lowest *RateLimit
+	for _, limit := range t.limits {
+		if lowest == nil || limit.Remaining*lowest.Limit < lowest.Remaining*limit.Limit {
+			lowest = &limit
+		}
+	}
+	return lowest
+}
+
+// observe records the limit resp reports. Responses to concurrent requests can arrive out of
+// order, so within one reset window the lowest remaining count wins.
+func (t *RateLimitTracker) observe(resp *http.Response) {
+	limit, ok := parseRateLimit(resp.Header)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if prev, ok := t.limits[limit.Resource]; ok &&
+		(prev.Reset.After(limit.Reset) || prev.Reset.Equal(limit.Reset) && prev.Remaining <= limit.Remaining) {
+		return
+	}
+	t.limits[limit.Resource] = limit
+}
+
+// observeRateLimit records resp's rate limit in ctx's tracker, if any.
+func observeRateLimit(ctx context.Context, resp *http.Response) {
+	if tracker, ok := ctx.Value(rateLimitTrackerKey{}).(*RateLimitTracker); ok && tracker != nil {
+		tracker.observe(resp)
+	}
+}
+
+// parseRateLimit reads the X-RateLimit-* headers of a GitHub API response.
+func parseRateLimit(h http.Header) (RateLimit, bool) {
+	limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return RateLimit{}, false
+	}
+	resource := h.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = "core"
+	}
+	return RateLimit{Resource: resource, Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0).UTC()}, true
+}
+
+// primaryRateLimit returns the exhausted rate limit that caused resp to be rejected, if any.
+// Unlike secondary rate limits, these responses report no quota remaining.
+func primaryRateLimit(resp *http.Response) (RateLimit, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return RateLimit{}, false
+	}
+	limit, ok := parseRateLimit(resp.Header)
+	if !ok || limit.Remaining > 0 {
+		return RateLimit{}, false
+	}
+	return limit, true
+}
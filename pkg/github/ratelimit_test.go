@@ -0,0 +1,117 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitTracker(t *testing.T) {
+	reset := time.Now().Add(30 * time.Minute).Truncate(time.Second).UTC()
+	var remaining atomic.Int32
+	remaining.Store(4000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resource, limit := "core", "5000"
+		if r.URL.Path == "/graphql" {
+			resource = "graphql"
+		}
+		if r.URL.Path == "/search" {
+			resource, limit = "search", "30"
+		}
+		w.Header().Set("X-RateLimit-Resource", resource)
+		w.Header().Set("X-RateLimit-Limit", limit)
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(int(remaining.Load())))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	}))
+	defer srv.Close()
+
+	tracker := NewRateLimitTracker()
+	if got := tracker.Lowest(); got != nil {
+		t.Errorf("Lowest() before any response = %+v, want nil", got)
+	}
+	ctx := WithRateLimitTracker(context.Background(), tracker)
+	get := func(path string) {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+path, http.NoBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := newTestRetryClient().Do(req)
+		if err != nil {
+			t.Fatalf("Do() error: %v", err)
+		}
+		_ = resp.Body.Close() //nolint:errcheck // test
+	}
+
+	get("/graphql")
+	remaining.Store(4500) // A response to an earlier request, arriving late
+	get("/graphql")
+	want := RateLimit{Resource: "graphql", Limit: 5000, Remaining: 4000, Reset: reset}
+	if got := tracker.Lowest(); got == nil || *got != want {
+		t.Errorf("Lowest() = %+v, want %+v", got, want)
+	}
+
+	// A search limit of 30 with 20 left is a smaller fraction than graphql's 4000 of 5000
+	remaining.Store(20)
+	get("/search")
+	if got := tracker.Lowest(); got == nil || got.Resource != "search" || got.Remaining != 20 {
+		t.Errorf("Lowest() = %+v, want the search limit", got)
+	}
+
+	// Requests without a tracker are fine
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, http.NoBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := newTestRetryClient().Do(req)
+	if err != nil {
+		t.Fatalf("Do() without a tracker error: %v", err)
+	}
+	_ = resp.Body.Close() //nolint:errcheck // test
+}
+
+func TestRetryTransportExhaustedQuota(t *testing.T) {
+	var calls atomic.Int32
+	var reset atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Load(), 10))
+		http.Error(w, `{"message":"API rate limit exceeded"}`, http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	do := func() error {
+		t.Helper()
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, http.NoBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := newTestRetryClient().Do(req)
+		if err == nil {
+			_ = resp.Body.Close() //nolint:errcheck // test
+		}
+		return err
+	}
+
+	// Resetting in an hour is too long to wait: fail at once, saying when
+	reset.Store(time.Now().Add(time.Hour).Unix())
+	err := do()
+	var limitErr *RateLimitError
+	if !errors.As(err, &limitErr) || limitErr.Resource != "core" || limitErr.Remaining != 0 {
+		t.Fatalf("Do() error = %v, want a RateLimitError", err)
+	}
+	if !strings.Contains(err.Error(), "out of GitHub API quota") || !strings.Contains(err.Error(), "resets at") {
+		t.Errorf("error = %q, want it to say the quota is out and when it resets", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1 (no retries before the reset)", calls.Load())
+	}
+}
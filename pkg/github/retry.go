@@ -0,0 +1,220 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultMaxRetries is the default number of retries for a rate-limited or failed GitHub API call.
+const DefaultMaxRetries = 5
+
+const (
+	// retryBaseDelay is the backoff before the first retry; it doubles on each attempt.
+	retryBaseDelay = time.Second
+	// retryMaxDelay caps the exponential backoff between attempts.
+	retryMaxDelay = 60 * time.Second
+	// retryMaxRetryAfter is the longest Retry-After we wait for; longer waits fail fast instead.
+	retryMaxRetryAfter = 5 * time.Minute
+	// requestTimeout bounds each attempt, including reading the response body. It is applied per
+	// attempt because an http.Client Timeout would also cover the backoff waits between attempts.
+	requestTimeout = 30 * time.Second
+	// maxPeekBytes is how much of a 403 body is read to detect secondary rate limits.
+	maxPeekBytes = 4096
+)
+
+// baseTransport is shared by all API clients so connections are pooled.
+var baseTransport = http.DefaultTransport
+
+// APIOptions configures the GitHub API calls of a query or fetcher. A nil *APIOptions retries
+// DefaultMaxRetries times, with no rate budget.
+type APIOptions struct {
+	// Retries after a secondary rate limit (403), 429, or 5xx response; zero disables them
+	MaxRetries int
+	// Optional budget each request, including retries, waits for a token from (see NewRateBudget).
+	// Queries and fetchers sharing a budget share its rate, so concurrent fetches, searches and
+	// open PR counts can't burst past GitHub's secondary rate limits together.
+	RateBudget *rate.Limiter
+}
+
+// apiHTTPClient returns an HTTP client for GitHub API calls that retries transient failures as
+// api configures and, for Enterprise Server, rewrites api.github.com requests to the host in
+// each request's context (see WithHost).
+func apiHTTPClient(api *APIOptions) *http.Client {
+	retries := DefaultMaxRetries
+	var budget *rate.Limiter
+	if api != nil {
+		retries, budget = max(api.MaxRetries, 0), api.RateBudget
+	}
+	return &http.Client{
+		Transport: &retryTransport{
+			base:       &enterpriseTransport{base: baseTransport},
+			baseDelay:  retryBaseDelay,
+			maxDelay:   retryMaxDelay,
+			timeout:    requestTimeout,
+			maxRetries: retries,
+			budget:     budget,
+		},
+	}
+}
+
+// retryTransport retries GitHub API requests that failed with a secondary rate limit, 429,
+// or 5xx, using exponential backoff with jitter and honoring Retry-After. Requests rejected
+// for an exhausted quota wait for it to reset if that is soon, and otherwise fail with a
+// RateLimitError. Each response's rate limit is recorded in the context's RateLimitTracker. With
+// a budget, every attempt first waits for a token from it.
+// A non-zero timeout bounds each attempt, from sending the request to closing the response body.
+type retryTransport struct {
+	base       http.RoundTripper
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	timeout    time.Duration
+	maxRetries int
+	budget     *rate.Limiter
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	retries := t.maxRetries
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return nil, errors.New("cannot retry request with a non-replayable body")
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+
+		if t.budget != nil {
+			if err := t.budget.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		cancel := context.CancelFunc(func() {})
+		if t.timeout > 0 {
+			var attemptCtx context.Context
+			attemptCtx, cancel = context.WithTimeout(ctx, t.timeout)
+			r = r.WithContext(attemptCtx)
+		}
+		resp, err := t.base.RoundTrip(r)
+		if err != nil {
+			cancel()
+			return resp, err
+		}
+		observeRateLimit(ctx, resp)
+		delay, retry := t.retryDelay(resp, attempt)
+		if !retry || attempt >= retries {
+			// An exhausted quota gets an error saying when it resets, instead of a bare 403 or 429
+			if limit, ok := primaryRateLimit(resp); ok {
+				_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // draining for connection reuse
+				_ = resp.Body.Close()                 //nolint:errcheck // best effort close
+				cancel()
+				return nil, &RateLimitError{RateLimit: limit}
+			}
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // draining for connection reuse
+		_ = resp.Body.Close()                 //nolint:errcheck // best effort close before retry
+		cancel()
+
+		slog.Warn("GitHub API request failed, retrying",
+			"url", req.URL.String(),
+			"status", resp.StatusCode,
+			"attempt", attempt+1,
+			"max_retries", retries,
+			"delay", delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// cancelOnClose releases an attempt's timeout once its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// retryDelay reports whether resp should be retried, and how long to wait first.
+func (t *retryTransport) retryDelay(resp *http.Response, attempt int) (time.Duration, bool) {
+	limit, exhausted := primaryRateLimit(resp)
+	switch {
+	case exhausted, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+	case resp.StatusCode == http.StatusForbidden && isSecondaryRateLimit(resp):
+	default:
+		return 0, false
+	}
+
+	if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		return d, d <= retryMaxRetryAfter
+	}
+	// An exhausted quota is only worth waiting for if it resets soon
+	if exhausted {
+		d := max(time.Until(limit.Reset), 0) + time.Second
+		return d, d <= retryMaxRetryAfter
+	}
+
+	// Exponential backoff with jitter in [d/2, d) so concurrent workers spread out
+	d := min(t.baseDelay<<attempt, t.maxDelay)
+	if d <= 0 { // Overflow from a large attempt count
+		d = t.maxDelay
+	}
+	return d/2 + rand.N(d/2+1), true //nolint:gosec // jitter does not need a secure source
+}
+
+// isSecondaryRateLimit reports whether a 403 response is a secondary rate limit rather
+// than a permission error. The peeked body is restored so callers can still read it.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	if resp.Header.Get("Retry-After") != "" {
+		return true
+	}
+	peek, err := io.ReadAll(io.LimitReader(resp.Body, maxPeekBytes))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peek), resp.Body), resp.Body}
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(peek)), "secondary rate limit")
+}
+
+// parseRetryAfter parses a Retry-After header given as seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(secs)*time.Second, 0), true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return max(when.Sub(now), 0), true
+	}
+	return 0, false
+}
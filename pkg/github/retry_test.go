@@ -0,0 +1,270 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestRetryClient() *http.Client {
+	return &http.Client{
+		Transport: &retryTransport{
+			base: http.DefaultTransport, baseDelay: time.Millisecond, maxDelay: 5 * time.Millisecond, maxRetries: DefaultMaxRetries,
+		},
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []int
+		body      string
+		wantCalls int32
+		want      int
+	}{
+		{"success", []int{200}, "", 1, 200},
+		{"server errors then success", []int{503, 502, 200}, "", 3, 200},
+		{"too many requests", []int{429, 200}, "", 2, 200},
+		{"secondary rate limit", []int{403, 200}, `{"message":"You have exceeded a secondary rate limit"}`, 2, 200},
+		{"permission denied is not retried", []int{403, 200}, `{"message":"Resource not accessible by integration"}`, 1, 403},
+		{"not found is not retried", []int{404, 200}, "", 1, 404},
+		{"gives up after max retries", []int{500, 500, 500, 500, 500, 500, 500, 500}, "", 6, 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := calls.Add(1)
+				if body, err := io.ReadAll(r.Body); err != nil || string(body) != `{"query":"q"}` {
+					t.Errorf("attempt %d body = %q, %v; want replayed request body", n, body, err)
+				}
+				w.WriteHeader(tt.responses[n-1])
+				_, _ = w.Write([]byte(tt.body)) //nolint:errcheck // test server
+			}))
+			defer srv.Close()
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, strings.NewReader(`{"query":"q"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := newTestRetryClient().Do(req)
+			if err != nil {
+				t.Fatalf("Do() error: %v", err)
+			}
+			body, err := io.ReadAll(resp.Body)
+			_ = resp.Body.Close() //nolint:errcheck // test
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+			if string(body) != tt.body {
+				t.Errorf("body = %q, want %q (peeked 403 body must be preserved)", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestRetryTransportMaxRetries(t *testing.T) {
+	for _, tt := range []struct {
+		api  *APIOptions
+		want int
+	}{
+		{nil, DefaultMaxRetries},
+		{&APIOptions{MaxRetries: 2}, 2},
+		{&APIOptions{MaxRetries: -1}, 0},
+	} {
+		if got := apiHTTPClient(tt.api).Transport.(*retryTransport).maxRetries; got != tt.want { //nolint:forcetypeassert // always a retryTransport
+			t.Errorf("apiHTTPClient(%+v) retries %d times, want %d", tt.api, got, tt.want)
+		}
+	}
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, http.NoBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := apiHTTPClient(&APIOptions{MaxRetries: 0}).Do(req)
+	if err != nil {
+		t.Fatalf("Do() error: %v", err)
+	}
+	_ = resp.Body.Close() //nolint:errcheck // test
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls with retries disabled = %d, want 1", got)
+	}
+}
+
+func TestRetryTransportContextCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, http.NoBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	resp, err := newTestRetryClient().Do(req)
+	if resp != nil {
+		_ = resp.Body.Close() //nolint:errcheck // test
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancelled request took %v; should not wait out Retry-After", elapsed)
+	}
+}
+
+func TestRetryTransportAttemptTimeout(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		// Send headers, then stall the body past the attempt timeout
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	// The backoff before the retry is longer than the timeout, which must only bound each attempt
+	client := &http.Client{Transport: &retryTransport{
+		base: http.DefaultTransport, baseDelay: 200 * time.Millisecond, maxDelay: 200 * time.Millisecond, timeout: 100 * time.Millisecond,
+		maxRetries: DefaultMaxRetries,
+	}}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, http.NoBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error: %v (timeout must not include the backoff wait)", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // test
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Fatalf("status = %d after %d calls, want 200 after 2", resp.StatusCode, calls.Load())
+	}
+	if _, err := io.ReadAll(resp.Body); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("reading a stalled body: error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"30", 30 * time.Second, true},
+		{"0", 0, true},
+		{"Sun, 01 Jun 2025 12:00:45 GMT", 45 * time.Second, true},
+		{"Sun, 01 Jun 2025 11:59:00 GMT", 0, true}, // In the past
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRetryDelayBackoff(t *testing.T) {
+	rt := &retryTransport{baseDelay: time.Second, maxDelay: 8 * time.Second}
+	resp := &http.Response{StatusCode: http.StatusBadGateway, Header: http.Header{}}
+	for attempt, ceiling := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second} {
+		d, ok := rt.retryDelay(resp, attempt)
+		if !ok || d < ceiling/2 || d > ceiling {
+			t.Errorf("attempt %d delay = %v, %v; want within [%v, %v]", attempt, d, ok, ceiling/2, ceiling)
+		}
+	}
+
+	resp.Header.Set("Retry-After", "3600")
+	if _, ok := rt.retryDelay(resp, 0); ok {
+		t.Error("Retry-After beyond the maximum wait should not be retried")
+	}
+}
+
+func TestRetryTransportRateBudget(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// 20 requests/second with a burst of 20: the first 20 requests, retries included, go
+	// through at once and the rest are paced at 50ms each
+	client := &http.Client{Transport: &retryTransport{
+		base: http.DefaultTransport, baseDelay: time.Millisecond, maxDelay: 5 * time.Millisecond,
+		maxRetries: DefaultMaxRetries, budget: NewRateBudget(20),
+	}}
+	start := time.Now()
+	for range 22 {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, http.NoBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Do() error: %v", err)
+		}
+		_ = resp.Body.Close() //nolint:errcheck // test
+	}
+	if got := calls.Load(); got != 23 {
+		t.Errorf("calls = %d, want 23 (22 requests and one retry)", got)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("23 requests took %v under a 20/s budget with burst 20, want at least 100ms", elapsed)
+	}
+
+	// A cancelled context stops waiting for the budget, here one set up through APIOptions
+	client = apiHTTPClient(&APIOptions{RateBudget: NewRateBudget(0.001)})
+	cancelled, cancel := context.WithCancel(context.Background())
+	budgetReq, err := http.NewRequestWithContext(cancelled, http.MethodGet, srv.URL, http.NoBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := client.Do(budgetReq); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("first request under a fresh budget = %v, %v; want it to use the burst", resp, err)
+	} else {
+		_ = resp.Body.Close() //nolint:errcheck // test
+	}
+	cancel()
+	if _, err := client.Do(budgetReq); err == nil {
+		t.Error("request with an exhausted budget and cancelled context succeeded, want an error")
+	}
+}
@@ -0,0 +1,170 @@
+package github
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SamplingMode selects how PRs are picked for a sampled analysis. The zero value is SamplingTime.
+type SamplingMode string
+
+// Sampling modes accepted by ParseSamplingMode.
+const (
+	// SamplingTime spreads the sample evenly over the period (see SamplePRs).
+	SamplingTime SamplingMode = "time"
+	// SamplingWeighted over-samples long-lived PRs and weights each sample by the number of
+	// PRs it stands for (see SamplePRsWeighted).
+	SamplingWeighted SamplingMode = "weighted"
+)
+
+// ParseSamplingMode parses a sampling mode name (time or weighted; case-insensitive).
+// An empty string is SamplingTime.
+func ParseSamplingMode(s string) (SamplingMode, error) {
+	switch mode := SamplingMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "", SamplingTime:
+		return SamplingTime, nil
+	case SamplingWeighted:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid sampling mode %q: must be time or weighted", s)
+	}
+}
+
+// Sample picks up to sampleSize PRs from prs using the mode's strategy.
+func (m SamplingMode) Sample(prs []PRSummary, sampleSize int) []PRSummary {
+	if m == SamplingWeighted {
+		return SamplePRsWeighted(prs, sampleSize)
+	}
+	return SamplePRs(prs, sampleSize)
+}
+
+// maxSamplingAgeDays caps the age weighted sampling gives a PR, matching the default
+// MaxProjectDelay: delay costs stop growing there, so older PRs aren't favored further.
+const maxSamplingAgeDays = 90
+
+// samplingSize is a PR's relative chance of being picked by weighted sampling: one plus the
+// days it was open (up to maxSamplingAgeDays), so a PR open for a week is eight times as likely
+// to be picked as one merged within the hour.
+func samplingSize(pr *PRSummary, now time.Time) float64 {
+	if pr.CreatedAt.IsZero() {
+		return 1
+	}
+	end := now
+	if pr.ClosedAt != nil {
+		end = *pr.ClosedAt
+	}
+	days := end.Sub(pr.CreatedAt).Hours() / 24
+	return 1 + min(max(days, 0), maxSamplingAgeDays)
+}
+
+// SamplePRsWeighted samples PRs with probability proportional to how long they were open
+// (see samplingSize), since long-lived PRs carry most of the delay cost. Each sampled PR's
+// SampleWeight is the number of PRs it stands for (the inverse of its chance of being
+// picked), so cost.ExtrapolateFromSamples can weight it back down. For populations where a
+// few long-lived PRs dominate the cost, this gives a much tighter estimate than SamplePRs for
+// the same sample size.
+//
+// PRs are picked by systematic sampling over the PRs ordered by updatedAt, which also keeps
+// the sample spread across the period. The starting point is derived from the PRs themselves,
+// so the same population always yields the same sample. PRs large enough to be certain of
+// selection are always included, with a SampleWeight of 1.
+//
+// If there are no more PRs than sampleSize, prs is returned unchanged.
+func SamplePRsWeighted(prs []PRSummary, sampleSize int) []PRSummary {
+	if len(prs) == 0 || sampleSize <= 0 {
+		return nil
+	}
+	if len(prs) <= sampleSize {
+		return prs
+	}
+
+	sorted := slices.Clone(prs)
+	slices.SortStableFunc(sorted, func(a, b PRSummary) int {
+		return b.UpdatedAt.Compare(a.UpdatedAt)
+	})
+	now := time.Now()
+	sizes := make([]float64, len(sorted))
+	for i := range sorted {
+		sizes[i] = samplingSize(&sorted[i], now)
+	}
+
+	samples := systematicSample(sorted, sizes, sampleSize, samplingStart(sorted))
+	slog.Info("Weighted sampling",
+		"population", len(prs),
+		"num_samples", len(samples))
+	return samples
+}
+
+// samplingStart returns a starting point in [0, 1) for systematic sampling, derived from the
+// PRs' identities so it is reproducible but unrelated to their sizes.
+func samplingStart(prs []PRSummary) float64 {
+	h := fnv.New64a()
+	for i := range prs {
+		fmt.Fprintf(h, "%s/%s#%d\n", prs[i].Owner, prs[i].Repo, prs[i].Number)
+	}
+	return float64(h.Sum64()>>11) / (1 << 53)
+}
+
+// systematicSample picks n of prs with probability proportional to sizes, by laying the PRs
+// end to end and taking the PR under each of n evenly spaced points, the first at start
+// (0 to 1) of the spacing. PRs whose size is at least the spacing are taken with certainty
+// first. Each pick's SampleWeight is set to the inverse of its inclusion probability.
+func systematicSample(prs []PRSummary, sizes []float64, n int, start float64) []PRSummary {
+	certain := make([]bool, len(prs))
+	remaining := n
+	var total float64
+	for {
+		total = 0
+		for i := range prs {
+			if !certain[i] {
+				total += sizes[i]
+			}
+		}
+		added := false
+		for i := range prs {
+			// Taking a certain PR out only raises the others' inclusion probabilities,
+			// so every PR found in one pass is certain
+			if !certain[i] && remaining > 0 && float64(remaining)*sizes[i] >= total {
+				certain[i] = true
+				remaining--
+				added = true
+			}
+		}
+		if !added || remaining == 0 {
+			break
+		}
+	}
+
+	samples := make([]PRSummary, 0, n)
+	for i := range prs {
+		if certain[i] {
+			pr := prs[i]
+			pr.SampleWeight = 1
+			samples = append(samples, pr)
+		}
+	}
+	if remaining == 0 {
+		return samples
+	}
+
+	step := total / float64(remaining)
+	next := start * step
+	var cumulative float64
+	for i := range prs {
+		if certain[i] {
+			continue
+		}
+		cumulative += sizes[i]
+		if cumulative > next && len(samples) < n {
+			pr := prs[i]
+			pr.SampleWeight = step / sizes[i]
+			samples = append(samples, pr)
+			next += step
+		}
+	}
+	return samples
+}
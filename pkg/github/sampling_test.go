@@ -0,0 +1,138 @@
+package github
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseSamplingMode(t *testing.T) {
+	for value, want := range map[string]SamplingMode{"": SamplingTime, "time": SamplingTime, " Weighted ": SamplingWeighted} {
+		got, err := ParseSamplingMode(value)
+		if err != nil || got != want {
+			t.Errorf("ParseSamplingMode(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseSamplingMode("random"); err == nil {
+		t.Error("ParseSamplingMode(random) succeeded, want an error")
+	}
+}
+
+// heavyTailedPRs returns n PRs whose open times follow a Pareto distribution, so most merge
+// within hours and a few stay open for months, and each PR's cost, which grows with its open time.
+// The same seed always gives the same PRs.
+func heavyTailedPRs(n int, now time.Time, seed uint64) (prs []PRSummary, costs map[int]float64) {
+	rng := rand.New(rand.NewPCG(seed, 2)) //nolint:gosec // deterministic test data
+	costs = make(map[int]float64, n)
+	for i := range n {
+		days := min(0.05/math.Pow(1-rng.Float64(), 1/1.1), 300)
+		closed := now
+		prs = append(prs, PRSummary{
+			Owner:     "o",
+			Repo:      "r",
+			Number:    i + 1,
+			CreatedAt: now.Add(-time.Duration(days * 24 * float64(time.Hour))),
+			ClosedAt:  &closed,
+			UpdatedAt: now.Add(-time.Duration(rng.Float64() * 60 * 24 * float64(time.Hour))),
+		})
+		costs[i+1] = 800 + 250*min(days, 90)*(0.5+rng.Float64())
+	}
+	return prs, costs
+}
+
+func TestSamplePRsWeighted(t *testing.T) {
+	now := time.Now()
+	prs, _ := heavyTailedPRs(500, now, 1)
+
+	samples := SamplePRsWeighted(prs, 40)
+	if len(samples) != 40 {
+		t.Fatalf("SamplePRsWeighted() returned %d PRs, want 40", len(samples))
+	}
+	seen := make(map[int]bool)
+	for _, pr := range samples {
+		if seen[pr.Number] {
+			t.Errorf("PR %d sampled twice", pr.Number)
+		}
+		seen[pr.Number] = true
+		if pr.SampleWeight < 1 {
+			t.Errorf("PR %d SampleWeight = %v, want at least 1", pr.Number, pr.SampleWeight)
+		}
+	}
+	if again := SamplePRsWeighted(prs, 40); !slices.EqualFunc(samples, again, func(a, b PRSummary) bool { return a.Number == b.Number }) {
+		t.Error("SamplePRsWeighted() picked different PRs from the same population")
+	}
+
+	if got := SamplePRsWeighted(prs[:10], 40); len(got) != 10 || got[0].SampleWeight != 0 {
+		t.Errorf("SamplePRsWeighted() of a small population = %d PRs (weight %v), want all 10 unweighted",
+			len(got), got[0].SampleWeight)
+	}
+	if got := SamplePRsWeighted(nil, 40); got != nil {
+		t.Errorf("SamplePRsWeighted(nil) = %v, want nil", got)
+	}
+}
+
+func TestSystematicSampleCertainty(t *testing.T) {
+	prs := make([]PRSummary, 6)
+	for i := range prs {
+		prs[i].Number = i + 1
+	}
+	// PR 1 is larger than the spacing for 3 samples, so it is always picked
+	sizes := []float64{100, 1, 1, 1, 1, 1}
+	for _, start := range []float64{0, 0.5, 0.99} {
+		samples := systematicSample(prs, sizes, 3, start)
+		if len(samples) != 3 || samples[0].Number != 1 || samples[0].SampleWeight != 1 {
+			t.Fatalf("systematicSample(start %v) = %+v, want PR 1 with weight 1 and 2 others", start, samples)
+		}
+		for _, pr := range samples[1:] {
+			if pr.SampleWeight != 2.5 {
+				t.Errorf("PR %d SampleWeight = %v, want 2.5 (2 picks from 5 equal PRs)", pr.Number, pr.SampleWeight)
+			}
+		}
+	}
+}
+
+// TestWeightedSamplingConvergesFaster checks that on heavy-tailed populations, where a few
+// long-lived PRs carry much of the cost, SamplePRsWeighted estimates the total cost with a
+// much smaller error than SamplePRs, the default time-bucket sampling, at the same size.
+func TestWeightedSamplingConvergesFaster(t *testing.T) {
+	now := time.Now()
+	const trials = 100
+	rmse := func(n int) (timeBuckets, weighted float64) {
+		for trial := range trials {
+			prs, costs := heavyTailedPRs(2000, now, uint64(trial)+1)
+			var truth float64
+			for _, c := range costs {
+				truth += c
+			}
+			population := float64(len(prs))
+
+			// Time buckets: N × the sample mean
+			var sum float64
+			samples := SamplePRs(prs, n)
+			for _, pr := range samples {
+				sum += costs[pr.Number]
+			}
+			timeBuckets += math.Pow((population*sum/float64(len(samples))-truth)/truth, 2)
+
+			// Weighted: N × the weighted sample mean, as ExtrapolateFromSamples computes it
+			var weightedSum, weights float64
+			for _, pr := range SamplePRsWeighted(prs, n) {
+				weightedSum += pr.SampleWeight * costs[pr.Number]
+				weights += pr.SampleWeight
+			}
+			weighted += math.Pow((population*weightedSum/weights-truth)/truth, 2)
+		}
+		return math.Sqrt(timeBuckets / trials), math.Sqrt(weighted / trials)
+	}
+
+	for _, n := range []int{25, 100} {
+		timeBuckets, weighted := rmse(n)
+		t.Logf("n=%d: relative RMSE time buckets %.1f%%, weighted %.1f%%", n, 100*timeBuckets, 100*weighted)
+		if weighted > timeBuckets/2 {
+			t.Errorf("n=%d: weighted sampling error %.1f%% is not well below time-bucket sampling's %.1f%%",
+				n, 100*weighted, 100*timeBuckets)
+		}
+	}
+}
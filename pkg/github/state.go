@@ -0,0 +1,98 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PRState selects PRs by lifecycle state. The zero value is PRStateAll.
+type PRState string
+
+// PR states accepted by ParsePRState.
+const (
+	// PRStateAll includes every PR modified in the window, whatever its state.
+	PRStateAll PRState = "all"
+	// PRStateOpen includes only PRs that are still open.
+	PRStateOpen PRState = "open"
+	// PRStateMerged includes only PRs merged within the window.
+	PRStateMerged PRState = "merged"
+	// PRStateClosed includes only PRs closed without merging within the window.
+	PRStateClosed PRState = "closed"
+)
+
+// ParsePRState parses a state name (all, open, merged or closed; case-insensitive).
+// An empty string is PRStateAll.
+func ParsePRState(s string) (PRState, error) {
+	switch state := PRState(strings.ToLower(strings.TrimSpace(s))); state {
+	case "", PRStateAll:
+		return PRStateAll, nil
+	case PRStateOpen, PRStateMerged, PRStateClosed:
+		return state, nil
+	default:
+		return "", fmt.Errorf("invalid PR state %q: must be all, open, merged or closed", s)
+	}
+}
+
+// IsAll reports whether s includes PRs in every state.
+func (s PRState) IsAll() bool {
+	return s == "" || s == PRStateAll
+}
+
+// Matches reports whether pr is in state s.
+func (s PRState) Matches(pr *PRSummary) bool {
+	switch s {
+	case PRStateOpen:
+		return strings.EqualFold(pr.State, "OPEN")
+	case PRStateMerged:
+		return pr.Merged
+	case PRStateClosed:
+		return strings.EqualFold(pr.State, "CLOSED") && !pr.Merged
+	default:
+		return true
+	}
+}
+
+// inWindow reports whether pr belongs in a query for state s over PRs modified between since
+// and until (now if zero). Merged and closed PRs only count if they were merged or closed within
+// the window, so a PR merged long ago but commented on recently is left out. With an until,
+// other PRs count if they were created, updated or closed within the window; a PR last updated
+// after until is still included when it was created or closed inside the window.
+func (s PRState) inWindow(pr *PRSummary, since, until time.Time) bool {
+	if !s.Matches(pr) {
+		return false
+	}
+	closedInWindow := pr.ClosedAt != nil && withinWindow(*pr.ClosedAt, since, until)
+	if s == PRStateMerged || s == PRStateClosed {
+		return closedInWindow
+	}
+	return withinWindow(pr.UpdatedAt, since, until) || withinWindow(pr.CreatedAt, since, until) || closedInWindow
+}
+
+// graphQLStates returns the pullRequests "states" argument for s, or "" for every state.
+func (s PRState) graphQLStates() string {
+	switch s {
+	case PRStateOpen:
+		return "states: [OPEN], "
+	case PRStateMerged:
+		return "states: [MERGED], "
+	case PRStateClosed:
+		return "states: [CLOSED], "
+	default:
+		return ""
+	}
+}
+
+// searchQualifier returns the search qualifier for s, or "" for every state.
+func (s PRState) searchQualifier() string {
+	switch s {
+	case PRStateOpen:
+		return " is:open"
+	case PRStateMerged:
+		return " is:merged"
+	case PRStateClosed:
+		return " is:closed is:unmerged"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,129 @@
+package github
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePRState(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    PRState
+		wantErr bool
+	}{
+		{in: "", want: PRStateAll},
+		{in: "all", want: PRStateAll},
+		{in: "Merged", want: PRStateMerged},
+		{in: " open ", want: PRStateOpen},
+		{in: "closed", want: PRStateClosed},
+		{in: "draft", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePRState(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParsePRState(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestPRStateInWindow(t *testing.T) {
+	since := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	recent := since.Add(48 * time.Hour)
+	old := since.Add(-48 * time.Hour)
+	open := PRSummary{Number: 1, State: "OPEN", UpdatedAt: recent}
+	mergedRecently := PRSummary{Number: 2, State: "MERGED", Merged: true, ClosedAt: &recent, UpdatedAt: recent}
+	mergedLongAgo := PRSummary{Number: 3, State: "MERGED", Merged: true, ClosedAt: &old, UpdatedAt: recent}
+	closed := PRSummary{Number: 4, State: "CLOSED", ClosedAt: &recent, UpdatedAt: recent}
+	prs := []PRSummary{open, mergedRecently, mergedLongAgo, closed}
+
+	tests := []struct {
+		state PRState
+		want  []int
+	}{
+		{PRStateAll, []int{1, 2, 3, 4}},
+		{"", []int{1, 2, 3, 4}},
+		{PRStateOpen, []int{1}},
+		{PRStateMerged, []int{2}}, // PR 3 was only commented on within the window
+		{PRStateClosed, []int{4}},
+	}
+	for _, tt := range tests {
+		var got []int
+		for i := range prs {
+			if tt.state.inWindow(&prs[i], since, time.Time{}) {
+				got = append(got, prs[i].Number)
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("state %q: PRs %v, want %v", tt.state, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("state %q: PRs %v, want %v", tt.state, got, tt.want)
+				break
+			}
+		}
+	}
+
+	// FilterPRs applies the state without the window
+	if got := FilterPRs(prs, PRFilter{State: PRStateMerged}); len(got) != 2 {
+		t.Errorf("FilterPRs(merged) returned %d PRs, want 2", len(got))
+	}
+}
+
+func TestPRStateInBoundedWindow(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 3, 31, 23, 59, 59, 0, time.UTC)
+	inside := time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC)
+	after := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		state PRState
+		pr    PRSummary
+		want  bool
+	}{
+		{"updated inside", PRStateAll, PRSummary{State: "OPEN", CreatedAt: since.AddDate(-1, 0, 0), UpdatedAt: inside}, true},
+		{"created inside, updated after", PRStateOpen, PRSummary{State: "OPEN", CreatedAt: inside, UpdatedAt: after}, true},
+		{"merged inside, updated after", PRStateAll, PRSummary{State: "MERGED", Merged: true, CreatedAt: since.AddDate(0, -1, 0), ClosedAt: &inside, UpdatedAt: after}, true},
+		{"only active after", PRStateAll, PRSummary{State: "OPEN", CreatedAt: since.AddDate(-1, 0, 0), UpdatedAt: after}, false},
+		{"merged after", PRStateMerged, PRSummary{State: "MERGED", Merged: true, CreatedAt: inside, ClosedAt: &after, UpdatedAt: after}, false},
+		{"merged inside", PRStateMerged, PRSummary{State: "MERGED", Merged: true, CreatedAt: inside, ClosedAt: &inside, UpdatedAt: after}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.state.inWindow(&tt.pr, since, until); got != tt.want {
+			t.Errorf("%s: inWindow() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOrgSearchQuery(t *testing.T) {
+	params := orgSortParams{org: "o", sinceStr: "2025-01-01", state: PRStateMerged, field: "updated", direction: "desc"}
+	if got, want := orgSearchQuery(params), "org:o is:pr is:merged updated:>2025-01-01 sort:updated-desc"; got != want {
+		t.Errorf("orgSearchQuery() = %q, want %q", got, want)
+	}
+	params.author = "alice"
+	if got, want := orgSearchQuery(params), "org:o is:pr is:merged author:alice updated:>2025-01-01 sort:updated-desc"; got != want {
+		t.Errorf("orgSearchQuery(author) = %q, want %q", got, want)
+	}
+	params.author = ""
+	params.until = time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	if got, want := orgSearchQuery(params), "org:o is:pr is:merged updated:>2025-01-01 created:<=2025-03-31 sort:updated-desc"; got != want {
+		t.Errorf("orgSearchQuery(until) = %q, want %q", got, want)
+	}
+	params.field, params.direction = "created", "asc"
+	if got, want := orgSearchQuery(params), "org:o is:pr is:merged created:2025-01-01..2025-03-31 sort:created-asc"; got != want {
+		t.Errorf("orgSearchQuery(created, until) = %q, want %q", got, want)
+	}
+}
+
+func TestPRStateQueryFilters(t *testing.T) {
+	if PRStateAll.graphQLStates() != "" || PRStateAll.searchQualifier() != "" {
+		t.Error("PRStateAll should not filter queries")
+	}
+	if got := PRStateMerged.graphQLStates(); got != "states: [MERGED], " {
+		t.Errorf("merged graphQLStates() = %q", got)
+	}
+	if got := PRStateClosed.searchQualifier(); got != " is:closed is:unmerged" {
+		t.Errorf("closed searchQualifier() = %q", got)
+	}
+}
@@ -36,6 +36,11 @@ type PRDataWithAnalysis struct {
 // Returns:
 //   - cost.PRData with all information needed for cost calculation
 func FetchPRDataViaTurnserver(ctx context.Context, prURL string, token string, updatedAt time.Time) (cost.PRData, error) {
+	// Only PRs on the configured host are accepted
+	if _, _, _, err := parsePRURL(ctx, prURL); err != nil {
+		return cost.PRData{}, fmt.Errorf("invalid PR URL: %w", err)
+	}
+
 	slog.Debug("Creating turnserver client", "url", prURL, "updated_at", updatedAt.Format(time.RFC3339))
 
 	// Create turnserver client using default endpoint
@@ -94,6 +99,11 @@ func FetchPRDataViaTurnserver(ctx context.Context, prURL string, token string, u
 // Returns:
 //   - PRDataWithAnalysis containing both cost.PRData and turn.Analysis
 func FetchPRDataWithAnalysisViaTurnserver(ctx context.Context, prURL string, token string, updatedAt time.Time) (PRDataWithAnalysis, error) {
+	// Only PRs on the configured host are accepted
+	if _, _, _, err := parsePRURL(ctx, prURL); err != nil {
+		return PRDataWithAnalysis{}, fmt.Errorf("invalid PR URL: %w", err)
+	}
+
 	slog.Debug("Creating turnserver client", "url", prURL, "updated_at", updatedAt.Format(time.RFC3339))
 
 	// Create turnserver client using default endpoint
@@ -0,0 +1,85 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// windowDateLayout is the date-only form accepted by ParseWindow.
+const windowDateLayout = "2006-01-02"
+
+// ParseWindow parses an absolute analysis window from since and until strings, each an
+// RFC 3339 timestamp or a YYYY-MM-DD date (UTC). A date-only until includes that whole day.
+// An empty until leaves the returned until zero, meaning "now"; until requires since.
+// Both bounds must be in the past relative to now, and since must be before until.
+func ParseWindow(since, until string, now time.Time) (start, end time.Time, err error) {
+	since, until = strings.TrimSpace(since), strings.TrimSpace(until)
+	if since == "" {
+		if until != "" {
+			return time.Time{}, time.Time{}, errors.New("until requires since")
+		}
+		return time.Time{}, time.Time{}, nil
+	}
+
+	start, _, err = parseWindowTime(since)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid since: %w", err)
+	}
+	if start.After(now) {
+		return time.Time{}, time.Time{}, fmt.Errorf("since %s is in the future", since)
+	}
+	if until == "" {
+		return start, time.Time{}, nil
+	}
+
+	end, dateOnly, err := parseWindowTime(until)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid until: %w", err)
+	}
+	if dateOnly {
+		end = end.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		if end.After(now) && !end.AddDate(0, 0, -1).After(now) {
+			end = now // today: the day so far
+		}
+	}
+	if end.After(now) {
+		return time.Time{}, time.Time{}, fmt.Errorf("until %s is in the future", until)
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("since %s must be before until %s", since, until)
+	}
+	return start, end, nil
+}
+
+// parseWindowTime parses an RFC 3339 timestamp or a YYYY-MM-DD date, reporting which it was.
+func parseWindowTime(s string) (t time.Time, dateOnly bool, err error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	t, err = time.Parse(windowDateLayout, s)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%q is not an RFC 3339 timestamp or YYYY-MM-DD date", s)
+	}
+	return t, true, nil
+}
+
+// WindowDays returns the number of days from since to until (now if zero), rounded up.
+func WindowDays(since, until time.Time) int {
+	return max(int(math.Ceil(windowEnd(until).Sub(since).Hours()/24.0)), 1)
+}
+
+// windowEnd returns until, or the current time if until is zero.
+func windowEnd(until time.Time) time.Time {
+	if until.IsZero() {
+		return time.Now()
+	}
+	return until
+}
+
+// withinWindow reports whether t falls within [since, until], where a zero until means now.
+func withinWindow(t, since, until time.Time) bool {
+	return !t.Before(since) && (until.IsZero() || !t.After(until))
+}
@@ -0,0 +1,55 @@
+package github
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name, since, until string
+		wantSince          time.Time
+		wantUntil          time.Time
+		wantErr            bool
+	}{
+		{name: "empty"},
+		{
+			name: "dates", since: "2025-01-01", until: "2025-03-31",
+			wantSince: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantUntil: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond),
+		},
+		{
+			name: "rfc3339", since: "2025-01-01T09:00:00Z", until: "2025-01-02T17:30:00-05:00",
+			wantSince: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC),
+			wantUntil: time.Date(2025, 1, 2, 22, 30, 0, 0, time.UTC),
+		},
+		{name: "since only", since: "2025-06-01", wantSince: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "until today", since: "2025-06-01", until: "2025-06-15", wantSince: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), wantUntil: now},
+		{name: "until without since", until: "2025-03-31", wantErr: true},
+		{name: "bad date", since: "01/02/2025", wantErr: true},
+		{name: "since in future", since: "2025-07-01", wantErr: true},
+		{name: "until in future", since: "2025-06-01", until: "2025-06-16", wantErr: true},
+		{name: "reversed", since: "2025-03-01", until: "2025-02-01", wantErr: true},
+	}
+	for _, tt := range tests {
+		since, until, err := ParseWindow(tt.since, tt.until, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ParseWindow() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !since.Equal(tt.wantSince) || !until.Equal(tt.wantUntil) {
+			t.Errorf("%s: ParseWindow() = %v, %v; want %v, %v", tt.name, since, until, tt.wantSince, tt.wantUntil)
+		}
+	}
+}
+
+func TestWindowDays(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := WindowDays(since, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)); got != 90 {
+		t.Errorf("WindowDays(Q1) = %d, want 90", got)
+	}
+	if got := WindowDays(since, since.Add(time.Hour)); got != 1 {
+		t.Errorf("WindowDays(1h) = %d, want 1", got)
+	}
+}
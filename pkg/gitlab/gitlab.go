@@ -0,0 +1,362 @@
+// Package gitlab fetches GitLab merge request data for the cost model.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codeGROOVE-dev/prcost/internal/hostname"
+	"github.com/codeGROOVE-dev/prcost/pkg/cost"
+	"github.com/codeGROOVE-dev/prcost/pkg/github"
+)
+
+// DefaultHost is the public GitLab host.
+const DefaultHost = "gitlab.com"
+
+const (
+	// perPage is the page size requested from paginated GitLab API endpoints (GitLab's maximum).
+	perPage = 100
+	// maxPages bounds how many pages of notes, commits, or diffs are fetched per merge request.
+	maxPages = 50
+)
+
+// httpClient is used for all GitLab API calls; tests replace it.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// mrPathPattern matches the path of a merge request URL: /group[/subgroup...]/project/-/merge_requests/123.
+var mrPathPattern = regexp.MustCompile(`^/((?:[A-Za-z0-9_.-]+/)+[A-Za-z0-9_.-]+)/-/merge_requests/(\d{1,10})/?$`)
+
+// APIError is a non-2xx response from the GitLab API.
+type APIError struct {
+	Path       string
+	StatusCode int
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("GitLab API %s: status %d %s", e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// ParseHost normalizes a GitLab host, for example "gitlab.mycorp.com" for a self-managed
+// instance. An empty host is the default. Only bare hostnames are accepted.
+func ParseHost(h string) (string, error) {
+	host, ok := hostname.Parse(h, DefaultHost)
+	if !ok {
+		return "", errors.New("GitLab host must be a bare hostname like gitlab.mycorp.com")
+	}
+	return host, nil
+}
+
+// ParseMRURL splits a merge request URL such as https://gitlab.com/group/project/-/merge_requests/42
+// into its host, project path (including any subgroups), and merge request IID.
+func ParseMRURL(mrURL string) (host, project string, iid int, err error) {
+	u, err := url.Parse(mrURL)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid merge request URL %q: %w", mrURL, err)
+	}
+	m := mrPathPattern.FindStringSubmatch(u.Path)
+	if u.Scheme != "https" || u.Host == "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" || m == nil {
+		return "", "", 0, fmt.Errorf("invalid merge request URL %q. Expected format: https://gitlab.com/group/project/-/merge_requests/123", mrURL)
+	}
+	iid, err = strconv.Atoi(m[2])
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid merge request number in %q: %w", mrURL, err)
+	}
+	return u.Host, m[1], iid, nil
+}
+
+// user is a GitLab user as embedded in API responses.
+type user struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// mergeRequest is the subset of a GitLab merge request the cost model uses.
+type mergeRequest struct {
+	CreatedAt time.Time  `json:"created_at"`
+	MergedAt  *time.Time `json:"merged_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+	Author    user       `json:"author"`
+	Title     string     `json:"title"`
+	State     string     `json:"state"` // opened, closed, merged or locked
+	Labels    []string   `json:"labels"`
+	Draft     bool       `json:"draft"`
+}
+
+// note is a comment or system note on a merge request.
+type note struct {
+	CreatedAt time.Time `json:"created_at"`
+	Author    user      `json:"author"`
+	Body      string    `json:"body"`
+	Type      string    `json:"type"` // DiffNote for comments on the diff
+	System    bool      `json:"system"`
+}
+
+// commit is a commit on a merge request. GitLab only reports the git author, not a username.
+type commit struct {
+	AuthoredDate time.Time `json:"authored_date"`
+	AuthorName   string    `json:"author_name"`
+}
+
+// diff is one changed file of a merge request.
+type diff struct {
+	NewPath string `json:"new_path"`
+	Diff    string `json:"diff"`
+}
+
+// FetchMRData fetches a GitLab merge request and converts it to cost.PRData. Comments become
+// "comment" events (or "review_comment" on the diff), approvals become "review" events, and
+// commits become "commit" events. The token is sent as a GitLab personal or project access token;
+// it may be empty for public projects.
+func FetchMRData(ctx context.Context, mrURL, token string) (cost.PRData, error) {
+	host, project, iid, err := ParseMRURL(mrURL)
+	if err != nil {
+		return cost.PRData{}, err
+	}
+	c := &client{base: "https://" + host + "/api/v4/projects/" + url.PathEscape(project) + "/merge_requests/" + strconv.Itoa(iid), token: token}
+
+	var mr mergeRequest
+	if err := c.get(ctx, "", &mr); err != nil {
+		return cost.PRData{}, err
+	}
+	var notes []note
+	if err := c.getAll(ctx, "/notes?sort=asc&order_by=created_at", func(page json.RawMessage) error {
+		return appendPage(page, &notes)
+	}); err != nil {
+		return cost.PRData{}, err
+	}
+	var commits []commit
+	if err := c.getAll(ctx, "/commits", func(page json.RawMessage) error {
+		return appendPage(page, &commits)
+	}); err != nil {
+		return cost.PRData{}, err
+	}
+	var diffs []diff
+	if err := c.getAll(ctx, "/diffs", func(page json.RawMessage) error {
+		return appendPage(page, &diffs)
+	}); err != nil {
+		return cost.PRData{}, err
+	}
+
+	data := mrToPRData(&mr, notes, commits, diffs)
+	slog.Info("Fetched GitLab merge request",
+		"url", mrURL,
+		"author", data.Author,
+		"events", len(data.Events),
+		"lines_added", data.LinesAdded,
+		"lines_deleted", data.LinesDeleted)
+	return data, nil
+}
+
+// mrToPRData maps a merge request and its notes, commits and diffs to cost.PRData.
+func mrToPRData(mr *mergeRequest, notes []note, commits []commit, diffs []diff) cost.PRData {
+	data := cost.PRData{
+		Author:    mr.Author.Username,
+		Title:     mr.Title,
+		Labels:    mr.Labels,
+		CreatedAt: mr.CreatedAt,
+		Draft:     mr.Draft,
+		AuthorBot: github.IsBot("", mr.Author.Username),
+	}
+	switch {
+	case mr.State == "merged":
+		data.Merged, data.State = true, "MERGED"
+		if mr.MergedAt != nil {
+			data.MergedAt, data.ClosedAt = *mr.MergedAt, *mr.MergedAt
+		}
+	case mr.ClosedAt != nil:
+		data.ClosedAt, data.State = *mr.ClosedAt, "CLOSED"
+	default:
+		data.State = "OPEN"
+	}
+
+	for i := range notes {
+		n := &notes[i]
+		if kind := noteKind(n); kind != "" && !github.IsBot("", n.Author.Username) {
+			event := cost.ParticipantEvent{Timestamp: n.CreatedAt, Actor: n.Author.Username, Kind: kind}
+			switch kind {
+			case "review":
+				event.State = "approved" // GitLab only records approvals as system notes
+			case "review_requested":
+				// One note can request several reviewers; GitHub records one event per reviewer
+				for _, reviewer := range requestedReviewers(n.Body) {
+					event.Target = reviewer
+					data.Events = append(data.Events, event)
+				}
+				if event.Target != "" {
+					continue
+				}
+			default:
+			}
+			data.Events = append(data.Events, event)
+		}
+	}
+	for _, c := range commits {
+		// Commits by the MR author's git identity are theirs; others keep the git author name
+		actor := c.AuthorName
+		if c.AuthorName == mr.Author.Name || c.AuthorName == mr.Author.Username {
+			actor = mr.Author.Username
+		}
+		if actor == "" || github.IsBot("", actor) {
+			continue
+		}
+		data.Events = append(data.Events, cost.ParticipantEvent{Timestamp: c.AuthoredDate, Actor: actor, Kind: "commit"})
+	}
+
+	for _, d := range diffs {
+		data.Files = append(data.Files, d.NewPath)
+		added, deleted := countDiffLines(d.Diff)
+		data.LinesAdded += added
+		data.LinesDeleted += deleted
+		if cost.IsGeneratedPath(d.NewPath) {
+			data.GeneratedLines += added
+		}
+		if data.LinesByCategory == nil {
+			data.LinesByCategory = make(map[string]int)
+		}
+		data.LinesByCategory[cost.FileCategory(d.NewPath)] += added
+	}
+	return data
+}
+
+// noteKind returns the event kind for a merge request note, or "" for system notes that aren't
+// participant activity. Approvals are only recorded, with their time, as system notes.
+func noteKind(n *note) string {
+	if !n.System {
+		if n.Type == "DiffNote" {
+			return "review_comment"
+		}
+		return "comment"
+	}
+	body := strings.ToLower(n.Body)
+	switch {
+	case strings.HasPrefix(body, "approved this merge request"):
+		return "review"
+	case strings.HasPrefix(body, "requested review from"):
+		return "review_requested"
+	case strings.HasPrefix(body, "marked this merge request as **ready**"):
+		return "ready_for_review"
+	case strings.HasPrefix(body, "marked this merge request as **draft**"):
+		return "convert_to_draft"
+	default:
+		return ""
+	}
+}
+
+// requestedReviewers returns the reviewers named in a "requested review from @bob and @carol"
+// system note, excluding bots.
+func requestedReviewers(body string) []string {
+	var reviewers []string
+	for word := range strings.FieldsSeq(body) {
+		login, ok := strings.CutPrefix(word, "@")
+		login = strings.TrimRight(login, ",.")
+		if !ok || login == "" || github.IsBot("", login) || slices.Contains(reviewers, login) {
+			continue
+		}
+		reviewers = append(reviewers, login)
+	}
+	return reviewers
+}
+
+// countDiffLines counts added and deleted lines in a unified diff hunk body. GitLab's diffs
+// start at the first hunk, without ---/+++ file headers, so a line such as "+++i" is an added "++i".
+func countDiffLines(diff string) (added, deleted int) {
+	for line := range strings.SplitSeq(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			deleted++
+		default:
+		}
+	}
+	return added, deleted
+}
+
+// appendPage decodes a page of a paginated list and appends it to items.
+func appendPage[T any](page json.RawMessage, items *[]T) error {
+	var batch []T
+	if err := json.Unmarshal(page, &batch); err != nil {
+		return fmt.Errorf("decoding GitLab API response: %w", err)
+	}
+	*items = append(*items, batch...)
+	return nil
+}
+
+// client issues GitLab API requests for a single merge request.
+type client struct {
+	base  string // Merge request API URL
+	token string
+}
+
+// get fetches base+path and decodes the JSON response into v.
+func (c *client) get(ctx context.Context, path string, v any) error {
+	body, _, err := c.fetch(ctx, c.base+path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("decoding GitLab API response: %w", err)
+	}
+	return nil
+}
+
+// getAll fetches every page of the list at base+path, passing each page to handle.
+func (c *client) getAll(ctx context.Context, path string, handle func(json.RawMessage) error) error {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	page := "1"
+	for range maxPages {
+		body, next, err := c.fetch(ctx, fmt.Sprintf("%s%s%sper_page=%d&page=%s", c.base, path, sep, perPage, page))
+		if err != nil {
+			return err
+		}
+		if err := handle(body); err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		page = next
+	}
+	slog.Warn("GitLab list truncated", "path", path, "max_pages", maxPages)
+	return nil
+}
+
+// fetch GETs apiURL and returns the body and the X-Next-Page header.
+func (c *client) fetch(ctx context.Context, apiURL string) (body []byte, nextPage string, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
+	if err != nil {
+		return nil, "", fmt.Errorf("creating GitLab API request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("GitLab API request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only response body
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // draining for connection reuse
+		return nil, "", &APIError{Path: req.URL.Path, StatusCode: resp.StatusCode}
+	}
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("reading GitLab API response: %w", err)
+	}
+	return body, resp.Header.Get("X-Next-Page"), nil
+}
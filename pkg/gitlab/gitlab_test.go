@@ -0,0 +1,105 @@
+package gitlab
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMRURL(t *testing.T) {
+	host, project, iid, err := ParseMRURL("https://gitlab.com/group/sub/project/-/merge_requests/42")
+	if err != nil {
+		t.Fatalf("ParseMRURL() error: %v", err)
+	}
+	if host != "gitlab.com" || project != "group/sub/project" || iid != 42 {
+		t.Errorf("ParseMRURL() = %q, %q, %d; want gitlab.com, group/sub/project, 42", host, project, iid)
+	}
+
+	for _, bad := range []string{
+		"http://gitlab.com/group/project/-/merge_requests/1",
+		"https://gitlab.com/project/-/merge_requests/1",
+		"https://gitlab.com/group/project/merge_requests/1",
+		"https://gitlab.com/group/project/-/merge_requests/1?tab=diffs",
+		"https://github.com/owner/repo/pull/1",
+	} {
+		if _, _, _, err := ParseMRURL(bad); err == nil {
+			t.Errorf("ParseMRURL(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestFetchMRData(t *testing.T) {
+	const base = "/api/v4/projects/group%2Fproject/merge_requests/7"
+	responses := map[string]string{
+		base: `{"created_at": "2025-03-03T09:00:00Z", "merged_at": "2025-03-04T15:00:00Z", "closed_at": null,
+			"author": {"username": "alice", "name": "Alice A"}, "title": "feat: add caching",
+			"state": "merged", "labels": ["backend"], "draft": false}`,
+		base + "/notes": `[
+			{"created_at": "2025-03-03T09:30:00Z", "author": {"username": "alice"}, "body": "requested review from @carol and @bob", "system": true},
+			{"created_at": "2025-03-03T10:00:00Z", "author": {"username": "bob"}, "body": "Looks close", "type": "DiffNote", "system": false},
+			{"created_at": "2025-03-03T11:00:00Z", "author": {"username": "carol"}, "body": "LGTM", "type": null, "system": false},
+			{"created_at": "2025-03-04T14:00:00Z", "author": {"username": "bob"}, "body": "approved this merge request", "system": true},
+			{"created_at": "2025-03-04T14:30:00Z", "author": {"username": "bob"}, "body": "added 1 commit", "system": true},
+			{"created_at": "2025-03-04T14:40:00Z", "author": {"username": "renovate-bot"}, "body": "Rebased", "system": false}]`,
+		base + "/commits": `[{"authored_date": "2025-03-03T09:00:00Z", "author_name": "Alice A"},
+			{"authored_date": "2025-03-04T12:00:00Z", "author_name": "Dave D"}]`,
+		base + "/diffs": `[{"new_path": "cache.go", "diff": "@@ -1,3 +1,4 @@\n-old\n---j\n+new\n+newer\n+++i\n context"},
+			{"new_path": "api.pb.go", "diff": "@@ -0,0 +1 @@\n+generated"}]`,
+	}
+	var gotToken string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotToken = r.Header.Get("PRIVATE-TOKEN")
+		body, ok := responses[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body)) //nolint:errcheck // test server
+	}))
+	defer srv.Close()
+	httpClient = srv.Client()
+	defer func() { httpClient = &http.Client{Timeout: 30 * time.Second} }()
+
+	mrURL := srv.URL + "/group/project/-/merge_requests/7"
+	data, err := FetchMRData(t.Context(), mrURL, "glpat-test")
+	if err != nil {
+		t.Fatalf("FetchMRData() error: %v", err)
+	}
+	if gotToken != "glpat-test" {
+		t.Errorf("PRIVATE-TOKEN = %q, want the access token", gotToken)
+	}
+	if data.Author != "alice" || !data.Merged || data.State != "MERGED" || data.Title != "feat: add caching" {
+		t.Errorf("FetchMRData() = author %q, merged %v, state %q, title %q", data.Author, data.Merged, data.State, data.Title)
+	}
+	if want := time.Date(2025, 3, 4, 15, 0, 0, 0, time.UTC); !data.ClosedAt.Equal(want) {
+		t.Errorf("ClosedAt = %v, want merge time %v", data.ClosedAt, want)
+	}
+	if data.LinesAdded != 4 || data.LinesDeleted != 2 || data.GeneratedLines != 1 || len(data.Files) != 2 {
+		t.Errorf("lines = +%d/-%d (%d generated) in %d files; want +3/-1 (1 generated) in 2",
+			data.LinesAdded, data.LinesDeleted, data.GeneratedLines, len(data.Files))
+	}
+
+	var kinds []string
+	for _, e := range data.Events {
+		kinds = append(kinds, e.Actor+":"+e.Kind)
+		if (e.State == "approved") != (e.Kind == "review") {
+			t.Errorf("%s event state = %q, want approved only for the approval", e.Kind, e.State)
+		}
+	}
+	if data.Events[0].Target != "carol" || data.Events[1].Target != "bob" {
+		t.Errorf("review request Targets = %q, %q; want carol, bob", data.Events[0].Target, data.Events[1].Target)
+	}
+	want := "alice:review_requested alice:review_requested bob:review_comment carol:comment bob:review alice:commit Dave D:commit"
+	if got := strings.Join(kinds, " "); got != want {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+
+	_, err = FetchMRData(t.Context(), strings.Replace(mrURL, "/7", "/8", 1), "")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("FetchMRData() for a missing MR error = %v, want a 404 APIError", err)
+	}
+}